@@ -72,6 +72,19 @@ func addToArchive(tarWriter *tar.Writer, pathInArchive string, path string, info
 	return err
 }
 
+// TarGzStream compresses a tar stream, such as the archives returned by the Docker API, and writes the
+// resulting .tar.gz archive to the output
+func TarGzStream(output io.Writer, tarStream io.Reader) error {
+	zipWriter := gzip.NewWriter(output)
+
+	if _, err := io.Copy(zipWriter, tarStream); err != nil {
+		zipWriter.Close()
+		return err
+	}
+
+	return zipWriter.Close()
+}
+
 // ExtractTarGz reads a .tar.gz archive from the reader and extracts it into outputDirPath directory
 func ExtractTarGz(r io.Reader, outputDirPath string) error {
 	zipReader, err := gzip.NewReader(r)
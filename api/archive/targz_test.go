@@ -1,6 +1,7 @@
 package archive
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
@@ -87,3 +88,21 @@ func Test_shouldCreateArhiveXXXXX(t *testing.T) {
 	wasExtracted("dir/inner")
 	wasExtracted("dir/.dotfile")
 }
+
+func Test_TarGzStream(t *testing.T) {
+	content := []byte("content")
+	tarball, err := TarFileInBuffer(content, "volume/data", 0600)
+	assert.NoError(t, err)
+
+	var archive bytes.Buffer
+	err = TarGzStream(&archive, bytes.NewReader(tarball))
+	assert.NoError(t, err)
+
+	extractionDir := t.TempDir()
+	err = ExtractTarGz(&archive, extractionDir)
+	assert.NoError(t, err)
+
+	extracted, err := os.ReadFile(path.Join(extractionDir, "volume", "data"))
+	assert.NoError(t, err)
+	assert.Equal(t, content, extracted)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/portainer/portainer/api/chisel"
 	"github.com/portainer/portainer/api/cli"
 	"github.com/portainer/portainer/api/crypto"
+	"github.com/portainer/portainer/api/customtemplates/repository"
 	"github.com/portainer/portainer/api/database"
 	"github.com/portainer/portainer/api/database/boltdb"
 	"github.com/portainer/portainer/api/database/models"
@@ -43,8 +44,13 @@ import (
 	kubecli "github.com/portainer/portainer/api/kubernetes/cli"
 	"github.com/portainer/portainer/api/ldap"
 	"github.com/portainer/portainer/api/oauth"
+	"github.com/portainer/portainer/api/policy"
+	"github.com/portainer/portainer/api/resourceusage"
 	"github.com/portainer/portainer/api/scheduler"
+	"github.com/portainer/portainer/api/sessionrecording"
 	"github.com/portainer/portainer/api/stacks/deployments"
+	"github.com/portainer/portainer/api/volumebackup"
+	"github.com/portainer/portainer/api/vulnerability"
 	"github.com/portainer/portainer/pkg/featureflags"
 	"github.com/portainer/portainer/pkg/libhelm"
 	"github.com/portainer/portainer/pkg/libstack"
@@ -220,6 +226,20 @@ func initCryptoService() portainer.CryptoService {
 	return &crypto.Service{}
 }
 
+func initSecretService(fileService portainer.FileService) portainer.SecretService {
+	key, err := fileService.LoadOrCreateSecretKey()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed loading the secret key")
+	}
+
+	secretService, err := crypto.NewSecretService(key)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed creating secret service")
+	}
+
+	return secretService
+}
+
 func initLDAPService() portainer.LDAPService {
 	return &ldap.Service{}
 }
@@ -228,8 +248,8 @@ func initOAuthService() portainer.OAuthService {
 	return oauth.NewService()
 }
 
-func initGitService(ctx context.Context) portainer.GitService {
-	return git.NewService(ctx)
+func initGitService(ctx context.Context, dataStore dataservices.DataStore) portainer.GitService {
+	return git.NewService(ctx, dataStore.GitSSHKey(), dataStore.GitCredential())
 }
 
 func initSSLService(addr, certPath, keyPath string, fileService portainer.FileService, dataStore dataservices.DataStore, shutdownTrigger context.CancelFunc) (*ssl.Service, error) {
@@ -262,12 +282,13 @@ func initSnapshotService(
 	dataStore dataservices.DataStore,
 	dockerClientFactory *dockerclient.ClientFactory,
 	kubernetesClientFactory *kubecli.ClientFactory,
+	resourceUsageRecorder portainer.ResourceUsageRecorder,
 	shutdownCtx context.Context,
 ) (portainer.SnapshotService, error) {
 	dockerSnapshotter := docker.NewSnapshotter(dockerClientFactory)
 	kubernetesSnapshotter := kubernetes.NewSnapshotter(kubernetesClientFactory)
 
-	snapshotService, err := snapshot.NewService(snapshotIntervalFromFlag, dataStore, dockerSnapshotter, kubernetesSnapshotter, shutdownCtx)
+	snapshotService, err := snapshot.NewService(snapshotIntervalFromFlag, dataStore, dockerSnapshotter, kubernetesSnapshotter, resourceUsageRecorder, shutdownCtx)
 	if err != nil {
 		return nil, err
 	}
@@ -424,12 +445,14 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 
 	oauthService := initOAuthService()
 
-	gitService := initGitService(shutdownCtx)
+	gitService := initGitService(shutdownCtx, dataStore)
 
 	openAMTService := openamt.NewService()
 
 	cryptoService := initCryptoService()
 
+	secretService := initSecretService(fileService)
+
 	digitalSignatureService := initDigitalSignatureService()
 
 	edgeStacksService := edgestacks.NewService(dataStore)
@@ -454,7 +477,9 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 	dockerClientFactory := initDockerClientFactory(digitalSignatureService, reverseTunnelService)
 	kubernetesClientFactory, err := initKubernetesClientFactory(digitalSignatureService, reverseTunnelService, dataStore, instanceID, *flags.AddrHTTPS, settings.UserSessionTimeout)
 
-	snapshotService, err := initSnapshotService(*flags.SnapshotInterval, dataStore, dockerClientFactory, kubernetesClientFactory, shutdownCtx)
+	resourceUsageService := resourceusage.NewService(dataStore, docker.NewResourceUsageCollector(dockerClientFactory))
+
+	snapshotService, err := initSnapshotService(*flags.SnapshotInterval, dataStore, dockerClientFactory, kubernetesClientFactory, resourceUsageService, shutdownCtx)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed initializing snapshot service")
 	}
@@ -559,9 +584,28 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 	}
 
 	scheduler := scheduler.NewScheduler(shutdownCtx)
-	stackDeployer := deployments.NewStackDeployer(swarmStackManager, composeStackManager, kubernetesDeployer, dockerClientFactory, dataStore)
+	vulnerabilityService := vulnerability.NewService(dataStore, fileService, composeStackManager, kubernetesDeployer, kubernetesClientFactory)
+	policyService := policy.NewService(dataStore, composeStackManager)
+	stackDeployer := deployments.NewStackDeployer(swarmStackManager, composeStackManager, kubernetesDeployer, dockerClientFactory, dataStore, secretService, policyService, vulnerabilityService)
 	deployments.StartStackSchedules(scheduler, stackDeployer, dataStore, gitService)
 
+	templateRepositoryService := repository.NewService(dataStore, fileService, gitService, scheduler)
+	if err := templateRepositoryService.StartSchedules(); err != nil {
+		log.Error().Err(err).Msg("failed to schedule the template repositories synchronization")
+	}
+
+	volumeBackupService := volumebackup.NewService(dataStore, fileService, docker.NewVolumeArchiver(dockerClientFactory), scheduler, secretService)
+	if err := volumeBackupService.StartSchedules(); err != nil {
+		log.Error().Err(err).Msg("failed to schedule the volume backups")
+	}
+
+	sessionRecordingService := sessionrecording.NewService(dataStore, fileService)
+	scheduler.StartJobEvery(time.Hour, sessionRecordingService.Purge)
+	scheduler.StartJobEvery(time.Hour, resourceUsageService.Purge)
+	scheduler.StartJobEvery(time.Hour, func() error {
+		return kubernetes.PurgeKubeconfigTokens(dataStore)
+	})
+
 	sslDBSettings, err := dataStore.SSLSettings().Settings()
 	if err != nil {
 		log.Fatal().Msg("failed to fetch SSL settings from DB")
@@ -619,6 +663,11 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 		ShutdownCtx:                 shutdownCtx,
 		ShutdownTrigger:             shutdownTrigger,
 		StackDeployer:               stackDeployer,
+		TemplateRepositoryService:   templateRepositoryService,
+		SessionRecordingService:     sessionRecordingService,
+		ResourceUsageService:        resourceUsageService,
+		VolumeBackupService:         volumeBackupService,
+		VulnerabilityService:        vulnerabilityService,
 		DemoService:                 demoService,
 		UpgradeService:              upgradeService,
 		AdminCreationDone:           adminCreationDone,
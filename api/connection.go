@@ -9,6 +9,7 @@ type ReadTransaction interface {
 	GetAll(bucketName string, obj interface{}, append func(o interface{}) (interface{}, error)) error
 	GetAllWithJsoniter(bucketName string, obj interface{}, append func(o interface{}) (interface{}, error)) error
 	GetAllWithKeyPrefix(bucketName string, keyPrefix []byte, obj interface{}, append func(o interface{}) (interface{}, error)) error
+	GetAllWithKeyRange(bucketName string, start, end []byte, obj interface{}, append func(o interface{}) (interface{}, error)) error
 }
 
 type Transaction interface {
@@ -21,6 +22,7 @@ type Transaction interface {
 	CreateObjectWithId(bucketName string, id int, obj interface{}) error
 	CreateObjectWithStringId(bucketName string, id []byte, obj interface{}) error
 	DeleteAllObjects(bucketName string, obj interface{}, matching func(o interface{}) (id int, ok bool)) error
+	DeleteObjectsWithKeyRange(bucketName string, start, end []byte) error
 	GetNextIdentifier(bucketName string) int
 }
 
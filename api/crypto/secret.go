@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// SecretKeySize is the size of the key of a SecretService, it corresponds to AES-256
+const SecretKeySize = 32
+
+// SecretService encrypts the secrets stored in the database with AES-256-GCM, the key is kept outside of the database
+// so that the secrets cannot be read from the database exports
+type SecretService struct {
+	aead cipher.AEAD
+}
+
+// NewSecretService creates a SecretService encrypting the secrets with the key
+func NewSecretService(key []byte) (*SecretService, error) {
+	if len(key) != SecretKeySize {
+		return nil, errors.Errorf("the secret key must be %d bytes long", SecretKeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretService{aead: aead}, nil
+}
+
+// Encrypt returns the base64 encoded nonce and ciphertext of the secret, an empty secret stays empty
+func (service *SecretService) Encrypt(secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+
+	nonce := make([]byte, service.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "unable to generate the nonce")
+	}
+
+	return base64.StdEncoding.EncodeToString(service.aead.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+// Decrypt returns the secret encrypted by Encrypt
+func (service *SecretService) Decrypt(encrypted string) (string, error) {
+	if encrypted == "" {
+		return "", nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid encrypted secret")
+	}
+
+	nonceSize := service.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", errors.New("invalid encrypted secret")
+	}
+
+	secret, err := service.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to decrypt the secret")
+	}
+
+	return string(secret), nil
+}
@@ -0,0 +1,40 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SecretService(t *testing.T) {
+	is := assert.New(t)
+
+	_, err := NewSecretService([]byte("short"))
+	is.Error(err)
+
+	service, err := NewSecretService(bytes.Repeat([]byte{1}, SecretKeySize))
+	is.NoError(err)
+
+	encrypted, err := service.Encrypt("passwd")
+	is.NoError(err)
+	is.NotContains(encrypted, "passwd")
+
+	other, err := service.Encrypt("passwd")
+	is.NoError(err)
+	is.NotEqual(encrypted, other, "a new nonce is used for every secret")
+
+	secret, err := service.Decrypt(encrypted)
+	is.NoError(err)
+	is.Equal("passwd", secret)
+
+	empty, err := service.Encrypt("")
+	is.NoError(err)
+	is.Empty(empty)
+
+	otherService, err := NewSecretService(bytes.Repeat([]byte{2}, SecretKeySize))
+	is.NoError(err)
+
+	_, err = otherService.Decrypt(encrypted)
+	is.Error(err, "the secret cannot be decrypted with another key")
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/customtemplates/variables"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultManifestPath is the path of the manifest inside a template repository when none is specified
+const DefaultManifestPath = "portainer-templates.json"
+
+// Manifest describes the custom templates hosted in a template repository
+type Manifest struct {
+	Templates []ManifestTemplate `json:"templates"`
+}
+
+// ManifestTemplate describes a single custom template of a template repository
+type ManifestTemplate struct {
+	// Identifier of the template, must be unique inside the manifest and stable across commits
+	Key         string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Note        string `json:"note"`
+	Logo        string `json:"logo"`
+	// Type of the stack created from the template (1 - swarm, 2 - compose, 3 - kubernetes)
+	Type     portainer.StackType              `json:"type"`
+	Platform portainer.CustomTemplatePlatform `json:"platform"`
+	// Path to the stack file, relative to the root of the repository
+	EntryPoint      string                                       `json:"entrypoint"`
+	Variables       []portainer.CustomTemplateVariableDefinition `json:"variables"`
+	IsComposeFormat bool                                         `json:"isComposeFormat"`
+}
+
+// ParseManifest decodes and validates a template repository manifest
+func ParseManifest(data []byte) (*Manifest, error) {
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, errors.Wrap(err, "unable to parse the template repository manifest")
+	}
+
+	keys := make(map[string]struct{}, len(manifest.Templates))
+	for i, template := range manifest.Templates {
+		if template.Key == "" {
+			return nil, fmt.Errorf("template #%d has no id", i)
+		}
+
+		if _, ok := keys[template.Key]; ok {
+			return nil, fmt.Errorf("template %s is defined more than once", template.Key)
+		}
+		keys[template.Key] = struct{}{}
+
+		if template.Title == "" {
+			return nil, fmt.Errorf("template %s has no title", template.Key)
+		}
+
+		if template.EntryPoint == "" {
+			return nil, fmt.Errorf("template %s has no entrypoint", template.Key)
+		}
+
+		switch template.Type {
+		case portainer.DockerSwarmStack, portainer.DockerComposeStack, portainer.KubernetesStack:
+		default:
+			return nil, fmt.Errorf("template %s has an invalid type: %d", template.Key, template.Type)
+		}
+
+		if template.Platform == 0 {
+			manifest.Templates[i].Platform = portainer.CustomTemplatePlatformLinux
+		} else if template.Platform != portainer.CustomTemplatePlatformLinux && template.Platform != portainer.CustomTemplatePlatformWindows {
+			return nil, fmt.Errorf("template %s has an invalid platform: %d", template.Key, template.Platform)
+		}
+
+		if err := variables.ValidateDefinitions(template.Variables); err != nil {
+			return nil, errors.WithMessagef(err, "template %s has invalid variables", template.Key)
+		}
+	}
+
+	return &manifest, nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseManifest(t *testing.T) {
+	manifest, err := ParseManifest([]byte(`{"templates":[
+		{"id":"nginx","title":"Nginx","type":2,"entrypoint":"nginx/docker-compose.yml","variables":[{"name":"PORT","label":"Port","type":"port"}]},
+		{"id":"redis","title":"Redis","type":3,"platform":1,"entrypoint":"redis/deployment.yml"}
+	]}`))
+	assert.NoError(t, err)
+	assert.Len(t, manifest.Templates, 2)
+	assert.Equal(t, portainer.CustomTemplatePlatformLinux, manifest.Templates[0].Platform, "platform should default to linux")
+
+	invalid := []struct {
+		name     string
+		manifest string
+	}{
+		{"malformed json", `{"templates":`},
+		{"missing id", `{"templates":[{"title":"Nginx","type":2,"entrypoint":"a.yml"}]}`},
+		{"duplicated id", `{"templates":[{"id":"a","title":"A","type":2,"entrypoint":"a.yml"},{"id":"a","title":"B","type":2,"entrypoint":"b.yml"}]}`},
+		{"missing title", `{"templates":[{"id":"a","type":2,"entrypoint":"a.yml"}]}`},
+		{"missing entrypoint", `{"templates":[{"id":"a","title":"A","type":2}]}`},
+		{"invalid type", `{"templates":[{"id":"a","title":"A","type":4,"entrypoint":"a.yml"}]}`},
+		{"invalid platform", `{"templates":[{"id":"a","title":"A","type":2,"platform":3,"entrypoint":"a.yml"}]}`},
+		{"invalid variables", `{"templates":[{"id":"a","title":"A","type":2,"entrypoint":"a.yml","variables":[{"name":"A"}]}]}`},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(tt.manifest))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_applyManifestTemplate(t *testing.T) {
+	entry := ManifestTemplate{
+		Key:        "nginx",
+		Title:      "Nginx",
+		Type:       portainer.DockerComposeStack,
+		Platform:   portainer.CustomTemplatePlatformLinux,
+		EntryPoint: "docker-compose.yml",
+		Variables:  []portainer.CustomTemplateVariableDefinition{{Name: "PORT", Label: "Port"}},
+	}
+
+	template := &portainer.CustomTemplate{}
+	assert.True(t, applyManifestTemplate(template, entry))
+	assert.Equal(t, "Nginx", template.Title)
+	assert.False(t, applyManifestTemplate(template, entry), "applying the same entry twice should not report a change")
+
+	entry.Variables = []portainer.CustomTemplateVariableDefinition{{Name: "PORT", Label: "Port", DefaultValue: "80"}}
+	assert.True(t, applyManifestTemplate(template, entry), "variable changes should be reported")
+}
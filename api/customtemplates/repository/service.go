@@ -0,0 +1,409 @@
+package repository
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/scheduler"
+	"github.com/portainer/portainer/api/stacks/stackutils"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Service synchronizes the custom templates of template repositories with their git remote
+type Service struct {
+	dataStore   dataservices.DataStore
+	fileService portainer.FileService
+	gitService  portainer.GitService
+	scheduler   *scheduler.Scheduler
+	mu          sync.Mutex
+	locks       map[portainer.CustomTemplateRepositoryID]*sync.Mutex
+}
+
+// NewService creates a new template repository service
+func NewService(dataStore dataservices.DataStore, fileService portainer.FileService, gitService portainer.GitService, scheduler *scheduler.Scheduler) *Service {
+	return &Service{
+		dataStore:   dataStore,
+		fileService: fileService,
+		gitService:  gitService,
+		scheduler:   scheduler,
+		locks:       make(map[portainer.CustomTemplateRepositoryID]*sync.Mutex),
+	}
+}
+
+// lock serializes the operations on a template repository, it returns the function releasing the lock
+func (service *Service) lock(repositoryID portainer.CustomTemplateRepositoryID) func() {
+	service.mu.Lock()
+	lock, ok := service.locks[repositoryID]
+	if !ok {
+		lock = &sync.Mutex{}
+		service.locks[repositoryID] = lock
+	}
+	service.mu.Unlock()
+
+	lock.Lock()
+
+	return lock.Unlock
+}
+
+// ProjectPath returns the path on disk of the clone of a template repository
+func (service *Service) ProjectPath(repositoryID portainer.CustomTemplateRepositoryID) string {
+	return service.fileService.GetCustomTemplateProjectPath(filepath.Join("repositories", strconv.Itoa(int(repositoryID))))
+}
+
+// StartSchedules schedules the synchronization of every template repository with a sync interval
+func (service *Service) StartSchedules() error {
+	repositories, err := service.dataStore.CustomTemplateRepository().ReadAll()
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch template repositories")
+	}
+
+	for _, repository := range repositories {
+		if repository.SyncInterval == "" {
+			continue
+		}
+
+		if _, err := service.Update(repository.ID, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Update applies the changes to a template repository, reschedules its synchronization and persists it.
+// It is serialized with the synchronizations of the repository so that neither overwrites the changes of the other
+func (service *Service) Update(repositoryID portainer.CustomTemplateRepositoryID, apply func(repository *portainer.CustomTemplateRepository) error) (*portainer.CustomTemplateRepository, error) {
+	defer service.lock(repositoryID)()
+
+	repository, err := service.dataStore.CustomTemplateRepository().Read(repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if apply != nil {
+		if err := apply(repository); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := service.Schedule(repository); err != nil {
+		return nil, err
+	}
+
+	if err := service.dataStore.CustomTemplateRepository().Update(repository.ID, repository); err != nil {
+		return nil, errors.Wrap(err, "unable to persist the template repository changes")
+	}
+
+	return repository, nil
+}
+
+// Schedule starts the periodic synchronization of a template repository and stores the job identifier
+// in the repository, the caller is responsible for persisting it
+func (service *Service) Schedule(repository *portainer.CustomTemplateRepository) error {
+	if repository.SyncInterval == "" {
+		service.Unschedule(repository)
+		return nil
+	}
+
+	repositoryID := repository.ID
+	jobID, err := service.scheduler.RescheduleJob(repository.JobID, repository.SyncInterval, func() error {
+		return service.Sync(repositoryID)
+	}, service.isRemoved)
+
+	repository.JobID = jobID
+
+	return errors.Wrap(err, "unable to parse the template repository sync interval")
+}
+
+// isRemoved returns true when the synchronization failed because the template repository was removed
+func (service *Service) isRemoved(err error) bool {
+	return service.dataStore.IsErrObjectNotFound(errors.Cause(err))
+}
+
+// Unschedule stops the periodic synchronization of a template repository
+func (service *Service) Unschedule(repository *portainer.CustomTemplateRepository) {
+	if repository.JobID == "" {
+		return
+	}
+
+	if err := service.scheduler.StopJob(repository.JobID); err != nil {
+		log.Warn().Int("repository_id", int(repository.ID)).Msg("could not stop the job for the template repository")
+	}
+
+	repository.JobID = ""
+}
+
+// Sync fetches the latest commit of a template repository and creates, updates or removes its custom templates
+// to match the manifest. The version of a template only changes when its metadata or stack file changed.
+func (service *Service) Sync(repositoryID portainer.CustomTemplateRepositoryID) error {
+	defer service.lock(repositoryID)()
+
+	repository, err := service.dataStore.CustomTemplateRepository().Read(repositoryID)
+	if err != nil {
+		return errors.Wrap(err, "unable to find the template repository")
+	}
+
+	commitHash, syncErr := service.sync(repository)
+
+	repository.LastSyncDate = time.Now().Unix()
+	repository.LastSyncError = ""
+	if syncErr != nil {
+		repository.LastSyncError = syncErr.Error()
+	} else {
+		repository.GitConfig.ConfigHash = commitHash
+	}
+
+	if err := service.dataStore.CustomTemplateRepository().Update(repository.ID, repository); err != nil {
+		return errors.Wrap(err, "unable to persist the template repository changes")
+	}
+
+	return syncErr
+}
+
+// Remove deletes the custom templates and the files of a template repository
+func (service *Service) Remove(repository *portainer.CustomTemplateRepository) error {
+	defer service.lock(repository.ID)()
+
+	service.Unschedule(repository)
+
+	templates, err := service.repositoryTemplates(repository.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, template := range templates {
+		if err := service.deleteTemplate(template); err != nil {
+			return err
+		}
+	}
+
+	if err := service.fileService.RemoveDirectory(service.ProjectPath(repository.ID)); err != nil {
+		log.Warn().Err(err).Int("repository_id", int(repository.ID)).Msg("unable to remove template repository files from disk")
+	}
+
+	service.mu.Lock()
+	delete(service.locks, repository.ID)
+	service.mu.Unlock()
+
+	return nil
+}
+
+func (service *Service) sync(repository *portainer.CustomTemplateRepository) (string, error) {
+	if repository.GitConfig == nil {
+		return "", errors.New("the template repository has no git configuration")
+	}
+
+	projectPath := service.ProjectPath(repository.ID)
+	clonePath := projectPath + "-sync"
+
+	os.RemoveAll(clonePath)
+	defer os.RemoveAll(clonePath)
+
+	commitHash, err := stackutils.DownloadGitRepository(*repository.GitConfig, service.gitService, func() string {
+		return clonePath
+	})
+	if err != nil {
+		return "", err
+	}
+
+	manifestPath := repository.GitConfig.ConfigFilePath
+	if manifestPath == "" {
+		manifestPath = DefaultManifestPath
+	}
+
+	manifestContent, err := readRepositoryFile(clonePath, manifestPath)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read the template repository manifest")
+	}
+
+	manifest, err := ParseManifest(manifestContent)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := service.repositoryTemplates(repository.ID)
+	if err != nil {
+		return "", err
+	}
+
+	existingByKey := make(map[string]*portainer.CustomTemplate, len(existing))
+	for _, template := range existing {
+		existingByKey[template.RepositoryKey] = template
+	}
+
+	updated := make([]*portainer.CustomTemplate, 0, len(manifest.Templates))
+	for _, entry := range manifest.Templates {
+		if !filepath.IsLocal(entry.EntryPoint) {
+			return "", fmt.Errorf("template %s has an entrypoint outside of the repository", entry.Key)
+		}
+
+		content, err := readRepositoryFile(clonePath, entry.EntryPoint)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to read the entrypoint of template %s", entry.Key)
+		}
+
+		template, ok := existingByKey[entry.Key]
+		if !ok {
+			template = &portainer.CustomTemplate{
+				RepositoryID:    repository.ID,
+				RepositoryKey:   entry.Key,
+				CreatedByUserID: repository.CreatedByUserID,
+			}
+		}
+		delete(existingByKey, entry.Key)
+
+		changed := applyManifestTemplate(template, entry)
+		if !changed && template.Version != "" {
+			previous, err := service.fileService.GetFileContent(projectPath, entry.EntryPoint)
+			changed = err != nil || !bytes.Equal(previous, content)
+		}
+
+		if changed || template.Version == "" {
+			template.Version = commitHash
+		}
+
+		template.ProjectPath = projectPath
+		updated = append(updated, template)
+	}
+
+	if err := os.RemoveAll(projectPath); err != nil {
+		return "", errors.Wrap(err, "unable to remove the previous clone of the template repository")
+	}
+
+	if err := os.Rename(clonePath, projectPath); err != nil {
+		return "", errors.Wrap(err, "unable to move the template repository clone")
+	}
+
+	for _, template := range updated {
+		if err := service.saveTemplate(template); err != nil {
+			return "", err
+		}
+	}
+
+	for _, template := range existingByKey {
+		if err := service.deleteTemplate(template); err != nil {
+			return "", err
+		}
+	}
+
+	return commitHash, nil
+}
+
+// readRepositoryFile reads a file of the clone of a template repository. The path must stay inside the clone
+// and neither the file nor its parent directories can be symbolic links, so that no file outside of the
+// repository is ever read
+func readRepositoryFile(clonePath, filePath string) ([]byte, error) {
+	if !filepath.IsLocal(filePath) {
+		return nil, fmt.Errorf("the path %s is outside of the repository", filePath)
+	}
+
+	path := clonePath
+	var info os.FileInfo
+	for _, part := range strings.Split(filepath.Clean(filePath), string(filepath.Separator)) {
+		path = filepath.Join(path, part)
+
+		var err error
+		info, err = os.Lstat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil, fmt.Errorf("the path %s contains a symbolic link", filePath)
+		}
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("the path %s is not a regular file", filePath)
+	}
+
+	return os.ReadFile(path)
+}
+
+func (service *Service) repositoryTemplates(repositoryID portainer.CustomTemplateRepositoryID) ([]*portainer.CustomTemplate, error) {
+	templates, err := service.dataStore.CustomTemplate().ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve custom templates")
+	}
+
+	result := make([]*portainer.CustomTemplate, 0)
+	for i := range templates {
+		if templates[i].RepositoryID == repositoryID {
+			result = append(result, &templates[i])
+		}
+	}
+
+	return result, nil
+}
+
+func (service *Service) saveTemplate(template *portainer.CustomTemplate) error {
+	if template.ID != 0 {
+		return errors.Wrapf(service.dataStore.CustomTemplate().Update(template.ID, template), "unable to update custom template %s", template.RepositoryKey)
+	}
+
+	template.ID = portainer.CustomTemplateID(service.dataStore.CustomTemplate().GetNextIdentifier())
+
+	resourceControl := authorization.NewAdministratorsOnlyResourceControl(strconv.Itoa(int(template.ID)), portainer.CustomTemplateResourceControl)
+	if err := service.dataStore.ResourceControl().Create(resourceControl); err != nil {
+		return errors.Wrapf(err, "unable to create the resource control of custom template %s", template.RepositoryKey)
+	}
+	template.ResourceControl = resourceControl
+
+	return errors.Wrapf(service.dataStore.CustomTemplate().Create(template), "unable to create custom template %s", template.RepositoryKey)
+}
+
+func (service *Service) deleteTemplate(template *portainer.CustomTemplate) error {
+	if err := service.dataStore.CustomTemplate().Delete(template.ID); err != nil {
+		return errors.Wrapf(err, "unable to remove custom template %s", template.RepositoryKey)
+	}
+
+	resourceControl, err := service.dataStore.ResourceControl().ResourceControlByResourceIDAndType(strconv.Itoa(int(template.ID)), portainer.CustomTemplateResourceControl)
+	if err != nil {
+		return errors.Wrapf(err, "unable to retrieve the resource control of custom template %s", template.RepositoryKey)
+	}
+
+	if resourceControl != nil {
+		if err := service.dataStore.ResourceControl().Delete(resourceControl.ID); err != nil {
+			return errors.Wrapf(err, "unable to remove the resource control of custom template %s", template.RepositoryKey)
+		}
+	}
+
+	return nil
+}
+
+// applyManifestTemplate copies the manifest entry into the custom template and reports whether any field changed
+func applyManifestTemplate(template *portainer.CustomTemplate, entry ManifestTemplate) bool {
+	changed := template.Title != entry.Title ||
+		template.Description != entry.Description ||
+		template.Note != entry.Note ||
+		template.Logo != entry.Logo ||
+		template.Type != entry.Type ||
+		template.Platform != entry.Platform ||
+		template.EntryPoint != entry.EntryPoint ||
+		template.IsComposeFormat != entry.IsComposeFormat ||
+		!reflect.DeepEqual(template.Variables, entry.Variables)
+
+	template.Title = entry.Title
+	template.Description = entry.Description
+	template.Note = entry.Note
+	template.Logo = entry.Logo
+	template.Type = entry.Type
+	template.Platform = entry.Platform
+	template.EntryPoint = entry.EntryPoint
+	template.IsComposeFormat = entry.IsComposeFormat
+	template.Variables = entry.Variables
+
+	return changed
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_readRepositoryFile(t *testing.T) {
+	is := assert.New(t)
+
+	outside := t.TempDir()
+	is.NoError(os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0600))
+
+	clonePath := t.TempDir()
+	is.NoError(os.MkdirAll(filepath.Join(clonePath, "nginx"), 0700))
+	is.NoError(os.WriteFile(filepath.Join(clonePath, "nginx", "docker-compose.yml"), []byte("services: {}"), 0600))
+	is.NoError(os.Symlink(filepath.Join(outside, "secret"), filepath.Join(clonePath, "link.yml")))
+	is.NoError(os.Symlink(outside, filepath.Join(clonePath, "linkdir")))
+
+	content, err := readRepositoryFile(clonePath, "nginx/docker-compose.yml")
+	is.NoError(err)
+	is.Equal("services: {}", string(content))
+
+	for _, filePath := range []string{"link.yml", "linkdir/secret", "nginx", "../secret", "/etc/passwd", "missing.yml"} {
+		_, err := readRepositoryFile(clonePath, filePath)
+		is.Error(err, "reading %s should fail", filePath)
+	}
+}
@@ -0,0 +1,260 @@
+package variables
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/pkg/errors"
+)
+
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateDefinitions ensures the variable definitions of a custom template are consistent
+func ValidateDefinitions(definitions []portainer.CustomTemplateVariableDefinition) error {
+	names := make(map[string]struct{}, len(definitions))
+
+	for _, definition := range definitions {
+		if definition.Name == "" {
+			return errors.New("variable name is required")
+		}
+
+		if definition.Label == "" {
+			return errors.New("variable label is required")
+		}
+
+		if _, ok := names[definition.Name]; ok {
+			return fmt.Errorf("variable %s is defined more than once", definition.Name)
+		}
+		names[definition.Name] = struct{}{}
+
+		switch definition.Type {
+		case "", portainer.CustomTemplateVariableTypeString, portainer.CustomTemplateVariableTypeNumber,
+			portainer.CustomTemplateVariableTypeBoolean, portainer.CustomTemplateVariableTypePort,
+			portainer.CustomTemplateVariableTypeMultiline:
+		case portainer.CustomTemplateVariableTypeSelect:
+			if len(definition.Options) == 0 {
+				return fmt.Errorf("variable %s must define at least one option", definition.Name)
+			}
+		case portainer.CustomTemplateVariableTypeSecret:
+			if definition.DefaultValue != "" {
+				return fmt.Errorf("secret variable %s cannot have a default value", definition.Name)
+			}
+
+			if !envNameRegex.MatchString(definition.Name) {
+				return fmt.Errorf("secret variable %s must be a valid environment variable name", definition.Name)
+			}
+		default:
+			return fmt.Errorf("variable %s has an invalid type: %s", definition.Name, definition.Type)
+		}
+
+		if definition.Pattern != "" {
+			if _, err := regexp.Compile(definition.Pattern); err != nil {
+				return errors.WithMessagef(err, "variable %s has an invalid pattern", definition.Name)
+			}
+		}
+
+		if definition.Min != nil && definition.Max != nil && *definition.Min > *definition.Max {
+			return fmt.Errorf("variable %s has a minimum greater than its maximum", definition.Name)
+		}
+
+		if definition.DefaultValue != "" {
+			if err := validateValue(definition, definition.DefaultValue); err != nil {
+				return errors.WithMessage(err, "invalid default value")
+			}
+		}
+	}
+
+	return nil
+}
+
+// ResolveValues validates the provided values against the variable definitions and returns
+// the value of every defined variable, using the default value when none was provided.
+// Values of variables that are not defined by the template are ignored.
+func ResolveValues(definitions []portainer.CustomTemplateVariableDefinition, values []portainer.Pair) (map[string]string, error) {
+	provided := make(map[string]string, len(values))
+	for _, pair := range values {
+		provided[pair.Name] = pair.Value
+	}
+
+	resolved := make(map[string]string, len(definitions))
+	for _, definition := range definitions {
+		value, ok := provided[definition.Name]
+		if !ok || value == "" {
+			value = definition.DefaultValue
+		}
+
+		if value == "" {
+			if definition.Required {
+				return nil, fmt.Errorf("variable %s is required", definition.Name)
+			}
+
+			resolved[definition.Name] = ""
+
+			continue
+		}
+
+		if err := validateValue(definition, value); err != nil {
+			return nil, err
+		}
+
+		resolved[definition.Name] = value
+	}
+
+	return resolved, nil
+}
+
+// Render replaces the {{ NAME }} placeholders of the template content with the resolved values.
+// Placeholders of variables without a value are left untouched.
+func Render(content string, values map[string]string) string {
+	for name, value := range values {
+		if value == "" {
+			continue
+		}
+
+		placeholder := regexp.MustCompile(`\{\{\s*` + regexp.QuoteMeta(name) + `\s*\}\}`)
+		content = placeholder.ReplaceAllLiteralString(content, value)
+	}
+
+	return content
+}
+
+// RenderStackFile renders the template content of a stack. The values of the secret variables are never written
+// into the stack file: their placeholders are replaced by ${NAME} references and the secret values are returned
+// as environment variables, to be stored encrypted with the stack and passed to every deployment
+func RenderStackFile(content string, definitions []portainer.CustomTemplateVariableDefinition, values map[string]string) (string, []portainer.Pair) {
+	public := make(map[string]string, len(values))
+	secretEnv := []portainer.Pair{}
+
+	for _, definition := range definitions {
+		value := values[definition.Name]
+
+		if definition.Type != portainer.CustomTemplateVariableTypeSecret {
+			public[definition.Name] = value
+			continue
+		}
+
+		public[definition.Name] = "${" + definition.Name + "}"
+		if value != "" {
+			secretEnv = append(secretEnv, portainer.Pair{Name: definition.Name, Value: value})
+		}
+	}
+
+	return Render(content, public), secretEnv
+}
+
+// ResolveReferences replaces the ${NAME} references of the environment variables in the content, it is used
+// for the manifests of the Kubernetes stacks which are not interpolated by the deployment tools
+func ResolveReferences(content string, env []portainer.Pair) string {
+	for _, pair := range env {
+		content = strings.ReplaceAll(content, "${"+pair.Name+"}", pair.Value)
+	}
+
+	return content
+}
+
+// FilterSecrets returns the environment variables without the ones matching a secret variable,
+// so that secret values never end up in the stack environment
+func FilterSecrets(definitions []portainer.CustomTemplateVariableDefinition, env []portainer.Pair) []portainer.Pair {
+	secrets := make(map[string]struct{})
+	for _, definition := range definitions {
+		if definition.Type == portainer.CustomTemplateVariableTypeSecret {
+			secrets[definition.Name] = struct{}{}
+		}
+	}
+
+	if len(secrets) == 0 {
+		return env
+	}
+
+	filtered := make([]portainer.Pair, 0, len(env))
+	for _, pair := range env {
+		if _, ok := secrets[pair.Name]; ok {
+			continue
+		}
+
+		filtered = append(filtered, pair)
+	}
+
+	return filtered
+}
+
+func validateValue(definition portainer.CustomTemplateVariableDefinition, value string) error {
+	switch definition.Type {
+	case portainer.CustomTemplateVariableTypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("variable %s must be a number", definition.Name)
+		}
+
+		if err := checkBounds(definition, number, "be"); err != nil {
+			return err
+		}
+
+	case portainer.CustomTemplateVariableTypePort:
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("variable %s must be a port number between 1 and 65535", definition.Name)
+		}
+
+		if err := checkBounds(definition, float64(port), "be"); err != nil {
+			return err
+		}
+
+	case portainer.CustomTemplateVariableTypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("variable %s must be true or false", definition.Name)
+		}
+
+	case portainer.CustomTemplateVariableTypeSelect:
+		found := false
+		for _, option := range definition.Options {
+			if option.Value == value {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("variable %s must be one of the defined options", definition.Name)
+		}
+
+	default:
+		if definition.Type != portainer.CustomTemplateVariableTypeMultiline && strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("variable %s must be a single line", definition.Name)
+		}
+
+		if err := checkBounds(definition, float64(utf8.RuneCountInString(value)), "have a length"); err != nil {
+			return err
+		}
+	}
+
+	if definition.Pattern != "" {
+		pattern, err := regexp.Compile(definition.Pattern)
+		if err != nil {
+			return errors.WithMessagef(err, "variable %s has an invalid pattern", definition.Name)
+		}
+
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("variable %s does not match the pattern %s", definition.Name, definition.Pattern)
+		}
+	}
+
+	return nil
+}
+
+func checkBounds(definition portainer.CustomTemplateVariableDefinition, value float64, verb string) error {
+	if definition.Min != nil && value < *definition.Min {
+		return fmt.Errorf("variable %s must %s greater than or equal to %v", definition.Name, verb, *definition.Min)
+	}
+
+	if definition.Max != nil && value > *definition.Max {
+		return fmt.Errorf("variable %s must %s lower than or equal to %v", definition.Name, verb, *definition.Max)
+	}
+
+	return nil
+}
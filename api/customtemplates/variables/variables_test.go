@@ -0,0 +1,136 @@
+package variables
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func Test_ValidateDefinitions(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition portainer.CustomTemplateVariableDefinition
+		wantErr    bool
+	}{
+		{"untyped variable", portainer.CustomTemplateVariableDefinition{Name: "A", Label: "A"}, false},
+		{"missing label", portainer.CustomTemplateVariableDefinition{Name: "A"}, true},
+		{"unknown type", portainer.CustomTemplateVariableDefinition{Name: "A", Label: "A", Type: "date"}, true},
+		{"select without options", portainer.CustomTemplateVariableDefinition{Name: "A", Label: "A", Type: portainer.CustomTemplateVariableTypeSelect}, true},
+		{"secret with default", portainer.CustomTemplateVariableDefinition{Name: "A", Label: "A", Type: portainer.CustomTemplateVariableTypeSecret, DefaultValue: "s3cr3t"}, true},
+		{"invalid pattern", portainer.CustomTemplateVariableDefinition{Name: "A", Label: "A", Pattern: "("}, true},
+		{"min greater than max", portainer.CustomTemplateVariableDefinition{Name: "A", Label: "A", Type: portainer.CustomTemplateVariableTypeNumber, Min: float(5), Max: float(1)}, true},
+		{"invalid default", portainer.CustomTemplateVariableDefinition{Name: "A", Label: "A", Type: portainer.CustomTemplateVariableTypePort, DefaultValue: "http"}, true},
+		{"valid port", portainer.CustomTemplateVariableDefinition{Name: "A", Label: "A", Type: portainer.CustomTemplateVariableTypePort, DefaultValue: "8080"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDefinitions([]portainer.CustomTemplateVariableDefinition{tt.definition})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+
+	err := ValidateDefinitions([]portainer.CustomTemplateVariableDefinition{{Name: "A", Label: "A"}, {Name: "A", Label: "B"}})
+	assert.Error(t, err, "duplicated names should be rejected")
+}
+
+func Test_ResolveValues(t *testing.T) {
+	definitions := []portainer.CustomTemplateVariableDefinition{
+		{Name: "IMAGE", Label: "Image", DefaultValue: "nginx:latest"},
+		{Name: "REPLICAS", Label: "Replicas", Type: portainer.CustomTemplateVariableTypeNumber, Min: float(1), Max: float(5)},
+		{Name: "PORT", Label: "Port", Type: portainer.CustomTemplateVariableTypePort, Required: true},
+		{Name: "DEBUG", Label: "Debug", Type: portainer.CustomTemplateVariableTypeBoolean},
+		{Name: "ENV", Label: "Environment", Type: portainer.CustomTemplateVariableTypeSelect, Options: []portainer.CustomTemplateVariableOption{{Label: "Dev", Value: "dev"}, {Label: "Prod", Value: "prod"}}},
+		{Name: "NAME", Label: "Name", Pattern: "^[a-z]+$", Max: float(8)},
+		{Name: "PASSWORD", Label: "Password", Type: portainer.CustomTemplateVariableTypeSecret},
+	}
+
+	values, err := ResolveValues(definitions, []portainer.Pair{
+		{Name: "REPLICAS", Value: "3"},
+		{Name: "PORT", Value: "8080"},
+		{Name: "DEBUG", Value: "true"},
+		{Name: "ENV", Value: "prod"},
+		{Name: "NAME", Value: "web"},
+		{Name: "PASSWORD", Value: "s3cr3t"},
+		{Name: "UNKNOWN", Value: "ignored"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx:latest", values["IMAGE"])
+	assert.Equal(t, "s3cr3t", values["PASSWORD"])
+	assert.NotContains(t, values, "UNKNOWN")
+
+	invalid := []struct {
+		name  string
+		value portainer.Pair
+	}{
+		{"number out of bounds", portainer.Pair{Name: "REPLICAS", Value: "10"}},
+		{"not a number", portainer.Pair{Name: "REPLICAS", Value: "many"}},
+		{"port out of range", portainer.Pair{Name: "PORT", Value: "70000"}},
+		{"not a boolean", portainer.Pair{Name: "DEBUG", Value: "maybe"}},
+		{"unknown option", portainer.Pair{Name: "ENV", Value: "staging"}},
+		{"pattern mismatch", portainer.Pair{Name: "NAME", Value: "Web"}},
+		{"too long", portainer.Pair{Name: "NAME", Value: "webserver"}},
+		{"multiline string", portainer.Pair{Name: "IMAGE", Value: "nginx\nlatest"}},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResolveValues(definitions, []portainer.Pair{{Name: "PORT", Value: "80"}, tt.value})
+			assert.Error(t, err)
+		})
+	}
+
+	_, err = ResolveValues(definitions, nil)
+	assert.Error(t, err, "missing required variable should be rejected")
+}
+
+func Test_Render(t *testing.T) {
+	content := "image: {{ IMAGE }}\nports:\n  - {{PORT}}:80\nuser: {{ USER }}"
+
+	rendered := Render(content, map[string]string{"IMAGE": "nginx:latest", "PORT": "8080", "USER": ""})
+
+	assert.Equal(t, "image: nginx:latest\nports:\n  - 8080:80\nuser: {{ USER }}", rendered)
+}
+
+func Test_FilterSecrets(t *testing.T) {
+	definitions := []portainer.CustomTemplateVariableDefinition{
+		{Name: "IMAGE", Label: "Image"},
+		{Name: "PASSWORD", Label: "Password", Type: portainer.CustomTemplateVariableTypeSecret},
+	}
+
+	env := FilterSecrets(definitions, []portainer.Pair{{Name: "IMAGE", Value: "nginx"}, {Name: "PASSWORD", Value: "s3cr3t"}})
+
+	assert.Equal(t, []portainer.Pair{{Name: "IMAGE", Value: "nginx"}}, env)
+}
+
+func Test_RenderStackFile(t *testing.T) {
+	is := assert.New(t)
+
+	definitions := []portainer.CustomTemplateVariableDefinition{
+		{Name: "IMAGE", Label: "Image"},
+		{Name: "DB_PASSWORD", Label: "Password", Type: portainer.CustomTemplateVariableTypeSecret},
+	}
+
+	content := "image: {{ IMAGE }}\nenvironment:\n  - PASSWORD={{ DB_PASSWORD }}"
+
+	rendered, secretEnv := RenderStackFile(content, definitions, map[string]string{"IMAGE": "postgres", "DB_PASSWORD": "s3cr3t"})
+	is.Equal("image: postgres\nenvironment:\n  - PASSWORD=${DB_PASSWORD}", rendered)
+	is.NotContains(rendered, "s3cr3t", "the secret values are not written into the stack file")
+	is.Equal([]portainer.Pair{{Name: "DB_PASSWORD", Value: "s3cr3t"}}, secretEnv)
+
+	is.Equal("image: postgres\nenvironment:\n  - PASSWORD=s3cr3t", ResolveReferences(rendered, secretEnv))
+}
+
+func Test_ValidateDefinitions_SecretName(t *testing.T) {
+	err := ValidateDefinitions([]portainer.CustomTemplateVariableDefinition{
+		{Name: "db-password", Label: "Password", Type: portainer.CustomTemplateVariableTypeSecret},
+	})
+
+	assert.Error(t, err, "the secret variables are passed as environment variables")
+}
@@ -286,6 +286,13 @@ func (connection *DbConnection) DeleteAllObjects(bucketName string, obj interfac
 	})
 }
 
+// DeleteObjectsWithKeyRange removes the objects with a key between start, inclusive, and end, exclusive
+func (connection *DbConnection) DeleteObjectsWithKeyRange(bucketName string, start, end []byte) error {
+	return connection.UpdateTx(func(tx portainer.Transaction) error {
+		return tx.DeleteObjectsWithKeyRange(bucketName, start, end)
+	})
+}
+
 // GetNextIdentifier is a generic function that returns the specified bucket identifier incremented by 1.
 func (connection *DbConnection) GetNextIdentifier(bucketName string) int {
 	var identifier int
@@ -338,6 +345,13 @@ func (connection *DbConnection) GetAllWithKeyPrefix(bucketName string, keyPrefix
 	})
 }
 
+// GetAllWithKeyRange reads the objects with a key between start, inclusive, and end, exclusive
+func (connection *DbConnection) GetAllWithKeyRange(bucketName string, start, end []byte, obj interface{}, append func(o interface{}) (interface{}, error)) error {
+	return connection.ViewTx(func(tx portainer.Transaction) error {
+		return tx.GetAllWithKeyRange(bucketName, start, end, obj, append)
+	})
+}
+
 // BackupMetadata will return a copy of the boltdb sequence numbers for all buckets.
 func (connection *DbConnection) BackupMetadata() (map[string]interface{}, error) {
 	buckets := map[string]interface{}{}
@@ -72,6 +72,25 @@ func (tx *DbTransaction) DeleteAllObjects(bucketName string, obj interface{}, ma
 	return nil
 }
 
+// DeleteObjectsWithKeyRange removes the objects with a key between start, inclusive, and end, exclusive
+func (tx *DbTransaction) DeleteObjectsWithKeyRange(bucketName string, start, end []byte) error {
+	var keys [][]byte
+
+	cursor := tx.tx.Bucket([]byte(bucketName)).Cursor()
+	for k, _ := cursor.Seek(start); k != nil && bytes.Compare(k, end) < 0; k, _ = cursor.Next() {
+		keys = append(keys, k)
+	}
+
+	bucket := tx.tx.Bucket([]byte(bucketName))
+	for _, key := range keys {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (tx *DbTransaction) GetNextIdentifier(bucketName string) int {
 	bucket := tx.tx.Bucket([]byte(bucketName))
 	id, err := bucket.NextSequence()
@@ -160,3 +179,22 @@ func (tx *DbTransaction) GetAllWithKeyPrefix(bucketName string, keyPrefix []byte
 
 	return nil
 }
+
+// GetAllWithKeyRange reads the objects with a key between start, inclusive, and end, exclusive, in the order of their keys
+func (tx *DbTransaction) GetAllWithKeyRange(bucketName string, start, end []byte, obj interface{}, appendFn func(o interface{}) (interface{}, error)) error {
+	cursor := tx.tx.Bucket([]byte(bucketName)).Cursor()
+
+	for k, v := cursor.Seek(start); k != nil && bytes.Compare(k, end) < 0; k, v = cursor.Next() {
+		err := tx.conn.UnmarshalObjectWithJsoniter(v, obj)
+		if err != nil {
+			return err
+		}
+
+		obj, err = appendFn(obj)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,8 @@ package boltdb
 
 import (
 	"errors"
+	"slices"
+	"strconv"
 	"testing"
 
 	portainer "github.com/portainer/portainer/api"
@@ -124,3 +126,62 @@ func TestTxs(t *testing.T) {
 		t.Fatal("an error was expected, got nil instead")
 	}
 }
+
+func TestKeyRange(t *testing.T) {
+	conn := DbConnection{
+		Path: t.TempDir(),
+	}
+
+	err := conn.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	err = conn.SetServiceName(testBucketName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id := 1; id <= 5; id++ {
+		err = conn.CreateObjectWithId(testBucketName, id, testStruct{Key: strconv.Itoa(id)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	readRange := func() []string {
+		var objs []testStruct
+		err := conn.GetAllWithKeyRange(testBucketName, conn.ConvertToKey(2), conn.ConvertToKey(5), &testStruct{}, dataservices.AppendFn(&objs))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var keys []string
+		for _, obj := range objs {
+			keys = append(keys, obj.Key)
+		}
+
+		return keys
+	}
+
+	// The start of the range is included, its end is excluded
+	if keys := readRange(); !slices.Equal(keys, []string{"2", "3", "4"}) {
+		t.Fatalf("expected the objects 2, 3 and 4, got %v instead", keys)
+	}
+
+	err = conn.DeleteObjectsWithKeyRange(testBucketName, conn.ConvertToKey(3), conn.ConvertToKey(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if keys := readRange(); !slices.Equal(keys, []string{"2"}) {
+		t.Fatalf("expected the object 2, got %v instead", keys)
+	}
+
+	var obj testStruct
+	err = conn.GetObject(testBucketName, conn.ConvertToKey(5), &obj)
+	if err != nil {
+		t.Fatal("the object after the range should not be removed:", err)
+	}
+}
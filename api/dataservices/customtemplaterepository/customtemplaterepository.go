@@ -0,0 +1,40 @@
+package customtemplaterepository
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "custom_template_repositories"
+
+// Service represents a service for managing custom template repository data.
+type Service struct {
+	dataservices.BaseDataService[portainer.CustomTemplateRepository, portainer.CustomTemplateRepositoryID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.CustomTemplateRepository, portainer.CustomTemplateRepositoryID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+// Create assigns an ID to a new custom template repository and saves it.
+func (service *Service) Create(repository *portainer.CustomTemplateRepository) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			repository.ID = portainer.CustomTemplateRepositoryID(id)
+			return int(repository.ID), repository
+		},
+	)
+}
@@ -0,0 +1,40 @@
+package gitsigningkey
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "git_signing_keys"
+
+// Service represents a service for managing git signing key data.
+type Service struct {
+	dataservices.BaseDataService[portainer.GitSigningKey, portainer.GitSigningKeyID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.GitSigningKey, portainer.GitSigningKeyID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+// Create assigns an ID to a new git signing key and saves it.
+func (service *Service) Create(key *portainer.GitSigningKey) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			key.ID = portainer.GitSigningKeyID(id)
+			return int(key.ID), key
+		},
+	)
+}
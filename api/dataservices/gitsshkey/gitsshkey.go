@@ -0,0 +1,40 @@
+package gitsshkey
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "git_ssh_keys"
+
+// Service represents a service for managing git SSH key data.
+type Service struct {
+	dataservices.BaseDataService[portainer.GitSSHKey, portainer.GitSSHKeyID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.GitSSHKey, portainer.GitSSHKeyID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+// Create assigns an ID to a new git SSH key and saves it.
+func (service *Service) Create(key *portainer.GitSSHKey) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			key.ID = portainer.GitSSHKeyID(id)
+			return int(key.ID), key
+		},
+	)
+}
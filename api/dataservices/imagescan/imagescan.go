@@ -0,0 +1,40 @@
+package imagescan
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "image_scans"
+
+// Service represents a service for managing image scan data.
+type Service struct {
+	dataservices.BaseDataService[portainer.ImageScan, portainer.ImageScanID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.ImageScan, portainer.ImageScanID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+// Create assigns an ID to a new image scan and saves it.
+func (service *Service) Create(scan *portainer.ImageScan) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			scan.ID = portainer.ImageScanID(id)
+			return int(scan.ID), scan
+		},
+	)
+}
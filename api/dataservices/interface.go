@@ -12,6 +12,7 @@ type (
 	DataStoreTx interface {
 		IsErrObjectNotFound(err error) bool
 		CustomTemplate() CustomTemplateService
+		CustomTemplateRepository() CustomTemplateRepositoryService
 		EdgeGroup() EdgeGroupService
 		EdgeJob() EdgeJobService
 		EdgeStack() EdgeStackService
@@ -19,21 +20,33 @@ type (
 		EndpointGroup() EndpointGroupService
 		EndpointRelation() EndpointRelationService
 		FDOProfile() FDOProfileService
+		GitCredential() GitCredentialService
+		GitSigningKey() GitSigningKeyService
+		GitSSHKey() GitSSHKeyService
 		HelmUserRepository() HelmUserRepositoryService
+		ImageScan() ImageScanService
+		KubeconfigToken() KubeconfigTokenService
+		PendingOperation() PendingOperationService
+		Policy() PolicyService
 		Registry() RegistryService
 		ResourceControl() ResourceControlService
+		ResourceUsageSample() ResourceUsageSampleService
 		Role() RoleService
 		APIKeyRepository() APIKeyRepository
+		SessionRecording() SessionRecordingService
 		Settings() SettingsService
 		Snapshot() SnapshotService
 		SSLSettings() SSLSettingsService
 		Stack() StackService
+		StackPromotion() StackPromotionService
 		Tag() TagService
 		TeamMembership() TeamMembershipService
 		Team() TeamService
 		TunnelServer() TunnelServerService
 		User() UserService
 		Version() VersionService
+		VolumeBackup() VolumeBackupService
+		VolumeBackupSchedule() VolumeBackupScheduleService
 		Webhook() WebhookService
 	}
 
@@ -58,6 +71,11 @@ type (
 		GetNextIdentifier() int
 	}
 
+	// CustomTemplateRepositoryService represents a service to manage custom template repositories
+	CustomTemplateRepositoryService interface {
+		BaseCRUD[portainer.CustomTemplateRepository, portainer.CustomTemplateRepositoryID]
+	}
+
 	// EdgeGroupService represents a service to manage Edge groups
 	EdgeGroupService interface {
 		BaseCRUD[portainer.EdgeGroup, portainer.EdgeGroupID]
@@ -120,6 +138,11 @@ type (
 		GetNextIdentifier() int
 	}
 
+	// ImageScanService represents a service for managing image scan data
+	ImageScanService interface {
+		BaseCRUD[portainer.ImageScan, portainer.ImageScanID]
+	}
+
 	// HelmUserRepositoryService represents a service to manage HelmUserRepositories
 	HelmUserRepositoryService interface {
 		BaseCRUD[portainer.HelmUserRepository, portainer.HelmUserRepositoryID]
@@ -130,16 +153,54 @@ type (
 	JWTService interface {
 		GenerateToken(data *portainer.TokenData) (string, error)
 		GenerateTokenForOAuth(data *portainer.TokenData, expiryTime *time.Time) (string, error)
-		GenerateTokenForKubeconfig(data *portainer.TokenData) (string, error)
+		GenerateTokenForKubeconfig(data *portainer.TokenData, tokenID portainer.KubeconfigTokenID, expiresAt int64) (string, error)
 		ParseAndVerifyToken(token string) (*portainer.TokenData, error)
 		SetUserSessionDuration(userSessionDuration time.Duration)
 	}
 
+	// GitCredentialService represents a service for managing git credential data
+	GitCredentialService interface {
+		BaseCRUD[portainer.GitCredential, portainer.GitCredentialID]
+	}
+
+	// GitSigningKeyService represents a service for managing git signing key data
+	GitSigningKeyService interface {
+		BaseCRUD[portainer.GitSigningKey, portainer.GitSigningKeyID]
+	}
+
+	// GitSSHKeyService represents a service for managing git SSH key data
+	GitSSHKeyService interface {
+		BaseCRUD[portainer.GitSSHKey, portainer.GitSSHKeyID]
+	}
+
+	// KubeconfigTokenService represents a service for managing kubeconfig token data
+	KubeconfigTokenService interface {
+		BaseCRUD[portainer.KubeconfigToken, portainer.KubeconfigTokenID]
+	}
+
+	// PendingOperationService represents a service for managing pending operation data
+	PendingOperationService interface {
+		BaseCRUD[portainer.PendingOperation, portainer.PendingOperationID]
+	}
+
+	// PolicyService represents a service for managing policy data
+	PolicyService interface {
+		BaseCRUD[portainer.Policy, portainer.PolicyID]
+	}
+
 	// RegistryService represents a service for managing registry data
 	RegistryService interface {
 		BaseCRUD[portainer.Registry, portainer.RegistryID]
 	}
 
+	// ResourceUsageSampleService represents a service for managing resource usage sample data
+	ResourceUsageSampleService interface {
+		Create(sample *portainer.ResourceUsageSample) error
+		ReadRange(endpointID portainer.EndpointID, since, until int64) ([]portainer.ResourceUsageSample, error)
+		DeleteBefore(endpointID portainer.EndpointID, before int64) error
+		DeleteByEndpointID(endpointID portainer.EndpointID) error
+	}
+
 	// ResourceControlService represents a service for managing resource control data
 	ResourceControlService interface {
 		BaseCRUD[portainer.ResourceControl, portainer.ResourceControlID]
@@ -158,6 +219,11 @@ type (
 		GetAPIKeyByDigest(digest []byte) (*portainer.APIKey, error)
 	}
 
+	// SessionRecordingService represents a service for managing session recording data
+	SessionRecordingService interface {
+		BaseCRUD[portainer.SessionRecording, portainer.SessionRecordingID]
+	}
+
 	// SettingsService represents a service for managing application settings
 	SettingsService interface {
 		Settings() (*portainer.Settings, error)
@@ -186,6 +252,11 @@ type (
 		RefreshableStacks() ([]portainer.Stack, error)
 	}
 
+	// StackPromotionService represents a service for managing stack promotion data
+	StackPromotionService interface {
+		BaseCRUD[portainer.StackPromotion, portainer.StackPromotionID]
+	}
+
 	// TagService represents a service for managing tag data
 	TagService interface {
 		BaseCRUD[portainer.Tag, portainer.TagID]
@@ -231,6 +302,16 @@ type (
 		UpdateVersion(*models.Version) error
 	}
 
+	// VolumeBackupService represents a service for managing volume backup data
+	VolumeBackupService interface {
+		BaseCRUD[portainer.VolumeBackup, portainer.VolumeBackupID]
+	}
+
+	// VolumeBackupScheduleService represents a service for managing volume backup schedule data
+	VolumeBackupScheduleService interface {
+		BaseCRUD[portainer.VolumeBackupSchedule, portainer.VolumeBackupScheduleID]
+	}
+
 	// WebhookService represents a service for managing webhook data.
 	WebhookService interface {
 		BaseCRUD[portainer.Webhook, portainer.WebhookID]
@@ -0,0 +1,40 @@
+package kubeconfigtoken
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "kubeconfig_tokens"
+
+// Service represents a service for managing kubeconfig token data.
+type Service struct {
+	dataservices.BaseDataService[portainer.KubeconfigToken, portainer.KubeconfigTokenID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.KubeconfigToken, portainer.KubeconfigTokenID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+// Create assigns an ID to a new kubeconfig token and saves it.
+func (service *Service) Create(token *portainer.KubeconfigToken) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			token.ID = portainer.KubeconfigTokenID(id)
+			return int(token.ID), token
+		},
+	)
+}
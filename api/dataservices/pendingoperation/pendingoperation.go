@@ -0,0 +1,40 @@
+package pendingoperation
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "pending_operations"
+
+// Service represents a service for managing pending operation data.
+type Service struct {
+	dataservices.BaseDataService[portainer.PendingOperation, portainer.PendingOperationID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.PendingOperation, portainer.PendingOperationID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+// Create assigns an ID to a new pending operation and saves it.
+func (service *Service) Create(operation *portainer.PendingOperation) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			operation.ID = portainer.PendingOperationID(id)
+			return int(operation.ID), operation
+		},
+	)
+}
@@ -0,0 +1,40 @@
+package policy
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "policies"
+
+// Service represents a service for managing policy data.
+type Service struct {
+	dataservices.BaseDataService[portainer.Policy, portainer.PolicyID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.Policy, portainer.PolicyID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+// Create assigns an ID to a new policy and saves it.
+func (service *Service) Create(policy *portainer.Policy) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			policy.ID = portainer.PolicyID(id)
+			return int(policy.ID), policy
+		},
+	)
+}
@@ -0,0 +1,78 @@
+package resourceusagesample
+
+import (
+	"math"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "resource_usage_samples"
+
+// Service represents a service for managing resource usage sample data.
+// The samples are keyed by environment, time and identifier so that the samples of a time window are read and
+// removed by key range.
+type Service struct {
+	connection portainer.Connection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+func (service *Service) Tx(tx portainer.Transaction) ServiceTx {
+	return ServiceTx{
+		service: service,
+		tx:      tx,
+	}
+}
+
+// Create assigns an ID to a new resource usage sample and saves it.
+func (service *Service) Create(sample *portainer.ResourceUsageSample) error {
+	return service.connection.UpdateTx(func(tx portainer.Transaction) error {
+		return service.Tx(tx).Create(sample)
+	})
+}
+
+// ReadRange returns the samples of the environment collected between since and until, inclusive, sorted by time.
+func (service *Service) ReadRange(endpointID portainer.EndpointID, since, until int64) ([]portainer.ResourceUsageSample, error) {
+	var samples []portainer.ResourceUsageSample
+
+	err := service.connection.ViewTx(func(tx portainer.Transaction) error {
+		var err error
+		samples, err = service.Tx(tx).ReadRange(endpointID, since, until)
+
+		return err
+	})
+
+	return samples, err
+}
+
+// DeleteBefore removes the samples of the environment collected before the time.
+func (service *Service) DeleteBefore(endpointID portainer.EndpointID, before int64) error {
+	return service.connection.UpdateTx(func(tx portainer.Transaction) error {
+		return service.Tx(tx).DeleteBefore(endpointID, before)
+	})
+}
+
+// DeleteByEndpointID removes all the samples of the environment.
+func (service *Service) DeleteByEndpointID(endpointID portainer.EndpointID) error {
+	return service.DeleteBefore(endpointID, math.MaxInt64)
+}
+
+// sampleKey returns the key of a sample, the environment identifier followed by the time and the identifier of the
+// sample in big endian so that the keys are sorted by environment then by time
+func (service *Service) sampleKey(endpointID portainer.EndpointID, at int64, id portainer.ResourceUsageSampleID) []byte {
+	key := service.connection.ConvertToKey(int(endpointID))
+	key = append(key, service.connection.ConvertToKey(int(at))...)
+
+	return append(key, service.connection.ConvertToKey(int(id))...)
+}
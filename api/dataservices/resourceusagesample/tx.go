@@ -0,0 +1,54 @@
+package resourceusagesample
+
+import (
+	"math"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+type ServiceTx struct {
+	service *Service
+	tx      portainer.Transaction
+}
+
+// Create assigns an ID to a new resource usage sample and saves it.
+func (service ServiceTx) Create(sample *portainer.ResourceUsageSample) error {
+	sample.ID = portainer.ResourceUsageSampleID(service.tx.GetNextIdentifier(BucketName))
+
+	return service.tx.CreateObjectWithStringId(BucketName, service.service.sampleKey(sample.EndpointID, sample.Time, sample.ID), sample)
+}
+
+// ReadRange returns the samples of the environment collected between since and until, inclusive, sorted by time.
+func (service ServiceTx) ReadRange(endpointID portainer.EndpointID, since, until int64) ([]portainer.ResourceUsageSample, error) {
+	samples := make([]portainer.ResourceUsageSample, 0)
+
+	if since > until {
+		return samples, nil
+	}
+
+	end := service.service.sampleKey(endpointID, until, math.MaxInt64)
+	if until < math.MaxInt64 {
+		end = service.service.sampleKey(endpointID, until+1, 0)
+	}
+
+	err := service.tx.GetAllWithKeyRange(
+		BucketName,
+		service.service.sampleKey(endpointID, since, 0),
+		end,
+		&portainer.ResourceUsageSample{},
+		dataservices.AppendFn(&samples),
+	)
+
+	return samples, err
+}
+
+// DeleteBefore removes the samples of the environment collected before the time.
+func (service ServiceTx) DeleteBefore(endpointID portainer.EndpointID, before int64) error {
+	return service.tx.DeleteObjectsWithKeyRange(BucketName, service.service.sampleKey(endpointID, 0, 0), service.service.sampleKey(endpointID, before, 0))
+}
+
+// DeleteByEndpointID removes all the samples of the environment.
+func (service ServiceTx) DeleteByEndpointID(endpointID portainer.EndpointID) error {
+	return service.DeleteBefore(endpointID, math.MaxInt64)
+}
@@ -0,0 +1,40 @@
+package sessionrecording
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "session_recordings"
+
+// Service represents a service for managing session recording data.
+type Service struct {
+	dataservices.BaseDataService[portainer.SessionRecording, portainer.SessionRecordingID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.SessionRecording, portainer.SessionRecordingID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+// Create assigns an ID to a new session recording and saves it.
+func (service *Service) Create(recording *portainer.SessionRecording) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			recording.ID = portainer.SessionRecordingID(id)
+			return int(recording.ID), recording
+		},
+	)
+}
@@ -0,0 +1,40 @@
+package stackpromotion
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "stack_promotions"
+
+// Service represents a service for managing stack promotion data.
+type Service struct {
+	dataservices.BaseDataService[portainer.StackPromotion, portainer.StackPromotionID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.StackPromotion, portainer.StackPromotionID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+// Create assigns an ID to a new stack promotion and saves it.
+func (service *Service) Create(promotion *portainer.StackPromotion) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			promotion.ID = portainer.StackPromotionID(id)
+			return int(promotion.ID), promotion
+		},
+	)
+}
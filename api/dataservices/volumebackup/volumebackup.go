@@ -0,0 +1,40 @@
+package volumebackup
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "volume_backups"
+
+// Service represents a service for managing volume backup data.
+type Service struct {
+	dataservices.BaseDataService[portainer.VolumeBackup, portainer.VolumeBackupID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.VolumeBackup, portainer.VolumeBackupID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+// Create assigns an ID to a new volume backup and saves it.
+func (service *Service) Create(backup *portainer.VolumeBackup) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			backup.ID = portainer.VolumeBackupID(id)
+			return int(backup.ID), backup
+		},
+	)
+}
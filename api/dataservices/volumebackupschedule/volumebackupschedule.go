@@ -0,0 +1,40 @@
+package volumebackupschedule
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "volume_backup_schedules"
+
+// Service represents a service for managing volume backup schedule data.
+type Service struct {
+	dataservices.BaseDataService[portainer.VolumeBackupSchedule, portainer.VolumeBackupScheduleID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.VolumeBackupSchedule, portainer.VolumeBackupScheduleID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+// Create assigns an ID to a new volume backup schedule and saves it.
+func (service *Service) Create(schedule *portainer.VolumeBackupSchedule) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			schedule.ID = portainer.VolumeBackupScheduleID(id)
+			return int(schedule.ID), schedule
+		},
+	)
+}
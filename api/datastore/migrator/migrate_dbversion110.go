@@ -0,0 +1,36 @@
+package migrator
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (m *Migrator) updateEndpointAdministratorRoleForDB110() error {
+	log.Info().Msg("adding the custom resources authorization to the environment administrator role")
+
+	role, err := m.roleService.Read(portainer.RoleID(1))
+	if dataservices.IsErrObjectNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if role.Authorizations == nil {
+		role.Authorizations = portainer.Authorizations{}
+	}
+
+	if role.Authorizations[portainer.OperationK8sCustomResourcesW] {
+		return nil
+	}
+
+	role.Authorizations[portainer.OperationK8sCustomResourcesW] = true
+
+	err = m.roleService.Update(role.ID, role)
+	if err != nil {
+		return err
+	}
+
+	return m.authorizationService.UpdateUsersAuthorizations()
+}
@@ -229,6 +229,8 @@ func (m *Migrator) initMigrations() {
 		m.updateEdgeStackStatusForDB100,
 	)
 
+	m.addMigrations("2.20", m.updateEndpointAdministratorRoleForDB110)
+
 	// Add new migrations below...
 	// One function per migration, each versions migration funcs in the same file.
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/dataservices/apikeyrepository"
 	"github.com/portainer/portainer/api/dataservices/customtemplate"
+	"github.com/portainer/portainer/api/dataservices/customtemplaterepository"
 	"github.com/portainer/portainer/api/dataservices/dockerhub"
 	"github.com/portainer/portainer/api/dataservices/edgegroup"
 	"github.com/portainer/portainer/api/dataservices/edgejob"
@@ -19,21 +20,33 @@ import (
 	"github.com/portainer/portainer/api/dataservices/endpointrelation"
 	"github.com/portainer/portainer/api/dataservices/extension"
 	"github.com/portainer/portainer/api/dataservices/fdoprofile"
+	"github.com/portainer/portainer/api/dataservices/gitcredential"
+	"github.com/portainer/portainer/api/dataservices/gitsigningkey"
+	"github.com/portainer/portainer/api/dataservices/gitsshkey"
 	"github.com/portainer/portainer/api/dataservices/helmuserrepository"
+	"github.com/portainer/portainer/api/dataservices/imagescan"
+	"github.com/portainer/portainer/api/dataservices/kubeconfigtoken"
+	"github.com/portainer/portainer/api/dataservices/pendingoperation"
+	"github.com/portainer/portainer/api/dataservices/policy"
 	"github.com/portainer/portainer/api/dataservices/registry"
 	"github.com/portainer/portainer/api/dataservices/resourcecontrol"
+	"github.com/portainer/portainer/api/dataservices/resourceusagesample"
 	"github.com/portainer/portainer/api/dataservices/role"
 	"github.com/portainer/portainer/api/dataservices/schedule"
+	"github.com/portainer/portainer/api/dataservices/sessionrecording"
 	"github.com/portainer/portainer/api/dataservices/settings"
 	"github.com/portainer/portainer/api/dataservices/snapshot"
 	"github.com/portainer/portainer/api/dataservices/ssl"
 	"github.com/portainer/portainer/api/dataservices/stack"
+	"github.com/portainer/portainer/api/dataservices/stackpromotion"
 	"github.com/portainer/portainer/api/dataservices/tag"
 	"github.com/portainer/portainer/api/dataservices/team"
 	"github.com/portainer/portainer/api/dataservices/teammembership"
 	"github.com/portainer/portainer/api/dataservices/tunnelserver"
 	"github.com/portainer/portainer/api/dataservices/user"
 	"github.com/portainer/portainer/api/dataservices/version"
+	"github.com/portainer/portainer/api/dataservices/volumebackup"
+	"github.com/portainer/portainer/api/dataservices/volumebackupschedule"
 	"github.com/portainer/portainer/api/dataservices/webhook"
 
 	"github.com/rs/zerolog/log"
@@ -44,34 +57,47 @@ import (
 type Store struct {
 	connection portainer.Connection
 
-	fileService               portainer.FileService
-	CustomTemplateService     *customtemplate.Service
-	DockerHubService          *dockerhub.Service
-	EdgeGroupService          *edgegroup.Service
-	EdgeJobService            *edgejob.Service
-	EdgeStackService          *edgestack.Service
-	EndpointGroupService      *endpointgroup.Service
-	EndpointService           *endpoint.Service
-	EndpointRelationService   *endpointrelation.Service
-	ExtensionService          *extension.Service
-	FDOProfilesService        *fdoprofile.Service
-	HelmUserRepositoryService *helmuserrepository.Service
-	RegistryService           *registry.Service
-	ResourceControlService    *resourcecontrol.Service
-	RoleService               *role.Service
-	APIKeyRepositoryService   *apikeyrepository.Service
-	ScheduleService           *schedule.Service
-	SettingsService           *settings.Service
-	SnapshotService           *snapshot.Service
-	SSLSettingsService        *ssl.Service
-	StackService              *stack.Service
-	TagService                *tag.Service
-	TeamMembershipService     *teammembership.Service
-	TeamService               *team.Service
-	TunnelServerService       *tunnelserver.Service
-	UserService               *user.Service
-	VersionService            *version.Service
-	WebhookService            *webhook.Service
+	fileService                     portainer.FileService
+	CustomTemplateService           *customtemplate.Service
+	CustomTemplateRepositoryService *customtemplaterepository.Service
+	DockerHubService                *dockerhub.Service
+	EdgeGroupService                *edgegroup.Service
+	EdgeJobService                  *edgejob.Service
+	EdgeStackService                *edgestack.Service
+	EndpointGroupService            *endpointgroup.Service
+	EndpointService                 *endpoint.Service
+	EndpointRelationService         *endpointrelation.Service
+	ExtensionService                *extension.Service
+	FDOProfilesService              *fdoprofile.Service
+	GitCredentialService            *gitcredential.Service
+	GitSigningKeyService            *gitsigningkey.Service
+	GitSSHKeyService                *gitsshkey.Service
+	HelmUserRepositoryService       *helmuserrepository.Service
+	ImageScanService                *imagescan.Service
+	RegistryService                 *registry.Service
+	KubeconfigTokenService          *kubeconfigtoken.Service
+	PendingOperationService         *pendingoperation.Service
+	PolicyService                   *policy.Service
+	ResourceControlService          *resourcecontrol.Service
+	ResourceUsageSampleService      *resourceusagesample.Service
+	RoleService                     *role.Service
+	APIKeyRepositoryService         *apikeyrepository.Service
+	ScheduleService                 *schedule.Service
+	SessionRecordingService         *sessionrecording.Service
+	SettingsService                 *settings.Service
+	SnapshotService                 *snapshot.Service
+	SSLSettingsService              *ssl.Service
+	StackService                    *stack.Service
+	StackPromotionService           *stackpromotion.Service
+	TagService                      *tag.Service
+	TeamMembershipService           *teammembership.Service
+	TeamService                     *team.Service
+	TunnelServerService             *tunnelserver.Service
+	UserService                     *user.Service
+	VersionService                  *version.Service
+	VolumeBackupService             *volumebackup.Service
+	VolumeBackupScheduleService     *volumebackupschedule.Service
+	WebhookService                  *webhook.Service
 }
 
 func (store *Store) initServices() error {
@@ -87,6 +113,12 @@ func (store *Store) initServices() error {
 	}
 	store.CustomTemplateService = customTemplateService
 
+	customTemplateRepositoryService, err := customtemplaterepository.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.CustomTemplateRepositoryService = customTemplateRepositoryService
+
 	dockerhubService, err := dockerhub.NewService(store.connection)
 	if err != nil {
 		return err
@@ -148,6 +180,48 @@ func (store *Store) initServices() error {
 	}
 	store.HelmUserRepositoryService = helmUserRepositoryService
 
+	imageScanService, err := imagescan.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.ImageScanService = imageScanService
+
+	gitCredentialService, err := gitcredential.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.GitCredentialService = gitCredentialService
+
+	gitSigningKeyService, err := gitsigningkey.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.GitSigningKeyService = gitSigningKeyService
+
+	gitSSHKeyService, err := gitsshkey.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.GitSSHKeyService = gitSSHKeyService
+
+	kubeconfigTokenService, err := kubeconfigtoken.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.KubeconfigTokenService = kubeconfigTokenService
+
+	pendingOperationService, err := pendingoperation.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.PendingOperationService = pendingOperationService
+
+	policyService, err := policy.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.PolicyService = policyService
+
 	registryService, err := registry.NewService(store.connection)
 	if err != nil {
 		return err
@@ -160,6 +234,18 @@ func (store *Store) initServices() error {
 	}
 	store.ResourceControlService = resourcecontrolService
 
+	resourceUsageSampleService, err := resourceusagesample.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.ResourceUsageSampleService = resourceUsageSampleService
+
+	sessionRecordingService, err := sessionrecording.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.SessionRecordingService = sessionRecordingService
+
 	settingsService, err := settings.NewService(store.connection)
 	if err != nil {
 		return err
@@ -184,6 +270,12 @@ func (store *Store) initServices() error {
 	}
 	store.StackService = stackService
 
+	stackPromotionService, err := stackpromotion.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.StackPromotionService = stackPromotionService
+
 	tagService, err := tag.NewService(store.connection)
 	if err != nil {
 		return err
@@ -226,6 +318,18 @@ func (store *Store) initServices() error {
 	}
 	store.VersionService = versionService
 
+	volumeBackupService, err := volumebackup.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.VolumeBackupService = volumeBackupService
+
+	volumeBackupScheduleService, err := volumebackupschedule.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.VolumeBackupScheduleService = volumeBackupScheduleService
+
 	webhookService, err := webhook.NewService(store.connection)
 	if err != nil {
 		return err
@@ -246,6 +350,11 @@ func (store *Store) CustomTemplate() dataservices.CustomTemplateService {
 	return store.CustomTemplateService
 }
 
+// CustomTemplateRepository gives access to the CustomTemplateRepository data management layer
+func (store *Store) CustomTemplateRepository() dataservices.CustomTemplateRepositoryService {
+	return store.CustomTemplateRepositoryService
+}
+
 // EdgeGroup gives access to the EdgeGroup data management layer
 func (store *Store) EdgeGroup() dataservices.EdgeGroupService {
 	return store.EdgeGroupService
@@ -286,6 +395,41 @@ func (store *Store) HelmUserRepository() dataservices.HelmUserRepositoryService
 	return store.HelmUserRepositoryService
 }
 
+// ImageScan gives access to the ImageScan data management layer
+func (store *Store) ImageScan() dataservices.ImageScanService {
+	return store.ImageScanService
+}
+
+// GitCredential gives access to the GitCredential data management layer
+func (store *Store) GitCredential() dataservices.GitCredentialService {
+	return store.GitCredentialService
+}
+
+// GitSigningKey gives access to the GitSigningKey data management layer
+func (store *Store) GitSigningKey() dataservices.GitSigningKeyService {
+	return store.GitSigningKeyService
+}
+
+// GitSSHKey gives access to the GitSSHKey data management layer
+func (store *Store) GitSSHKey() dataservices.GitSSHKeyService {
+	return store.GitSSHKeyService
+}
+
+// KubeconfigToken gives access to the KubeconfigToken data management layer
+func (store *Store) KubeconfigToken() dataservices.KubeconfigTokenService {
+	return store.KubeconfigTokenService
+}
+
+// PendingOperation gives access to the PendingOperation data management layer
+func (store *Store) PendingOperation() dataservices.PendingOperationService {
+	return store.PendingOperationService
+}
+
+// Policy gives access to the Policy data management layer
+func (store *Store) Policy() dataservices.PolicyService {
+	return store.PolicyService
+}
+
 // Registry gives access to the Registry data management layer
 func (store *Store) Registry() dataservices.RegistryService {
 	return store.RegistryService
@@ -296,6 +440,11 @@ func (store *Store) ResourceControl() dataservices.ResourceControlService {
 	return store.ResourceControlService
 }
 
+// ResourceUsageSample gives access to the ResourceUsageSample data management layer
+func (store *Store) ResourceUsageSample() dataservices.ResourceUsageSampleService {
+	return store.ResourceUsageSampleService
+}
+
 // Role gives access to the Role data management layer
 func (store *Store) Role() dataservices.RoleService {
 	return store.RoleService
@@ -306,6 +455,11 @@ func (store *Store) APIKeyRepository() dataservices.APIKeyRepository {
 	return store.APIKeyRepositoryService
 }
 
+// SessionRecording gives access to the SessionRecording data management layer
+func (store *Store) SessionRecording() dataservices.SessionRecordingService {
+	return store.SessionRecordingService
+}
+
 // Settings gives access to the Settings data management layer
 func (store *Store) Settings() dataservices.SettingsService {
 	return store.SettingsService
@@ -325,6 +479,11 @@ func (store *Store) Stack() dataservices.StackService {
 	return store.StackService
 }
 
+// StackPromotion gives access to the StackPromotion data management layer
+func (store *Store) StackPromotion() dataservices.StackPromotionService {
+	return store.StackPromotionService
+}
+
 // Tag gives access to the Tag data management layer
 func (store *Store) Tag() dataservices.TagService {
 	return store.TagService
@@ -355,37 +514,59 @@ func (store *Store) Version() dataservices.VersionService {
 	return store.VersionService
 }
 
+// VolumeBackup gives access to the VolumeBackup data management layer
+func (store *Store) VolumeBackup() dataservices.VolumeBackupService {
+	return store.VolumeBackupService
+}
+
+// VolumeBackupSchedule gives access to the VolumeBackupSchedule data management layer
+func (store *Store) VolumeBackupSchedule() dataservices.VolumeBackupScheduleService {
+	return store.VolumeBackupScheduleService
+}
+
 // Webhook gives access to the Webhook data management layer
 func (store *Store) Webhook() dataservices.WebhookService {
 	return store.WebhookService
 }
 
 type storeExport struct {
-	CustomTemplate     []portainer.CustomTemplate     `json:"customtemplates,omitempty"`
-	EdgeGroup          []portainer.EdgeGroup          `json:"edgegroups,omitempty"`
-	EdgeJob            []portainer.EdgeJob            `json:"edgejobs,omitempty"`
-	EdgeStack          []portainer.EdgeStack          `json:"edge_stack,omitempty"`
-	Endpoint           []portainer.Endpoint           `json:"endpoints,omitempty"`
-	EndpointGroup      []portainer.EndpointGroup      `json:"endpoint_groups,omitempty"`
-	EndpointRelation   []portainer.EndpointRelation   `json:"endpoint_relations,omitempty"`
-	Extensions         []portainer.Extension          `json:"extension,omitempty"`
-	HelmUserRepository []portainer.HelmUserRepository `json:"helm_user_repository,omitempty"`
-	Registry           []portainer.Registry           `json:"registries,omitempty"`
-	ResourceControl    []portainer.ResourceControl    `json:"resource_control,omitempty"`
-	Role               []portainer.Role               `json:"roles,omitempty"`
-	Schedules          []portainer.Schedule           `json:"schedules,omitempty"`
-	Settings           portainer.Settings             `json:"settings,omitempty"`
-	Snapshot           []portainer.Snapshot           `json:"snapshots,omitempty"`
-	SSLSettings        portainer.SSLSettings          `json:"ssl,omitempty"`
-	Stack              []portainer.Stack              `json:"stacks,omitempty"`
-	Tag                []portainer.Tag                `json:"tags,omitempty"`
-	TeamMembership     []portainer.TeamMembership     `json:"team_membership,omitempty"`
-	Team               []portainer.Team               `json:"teams,omitempty"`
-	TunnelServer       portainer.TunnelServerInfo     `json:"tunnel_server,omitempty"`
-	User               []portainer.User               `json:"users,omitempty"`
-	Version            models.Version                 `json:"version,omitempty"`
-	Webhook            []portainer.Webhook            `json:"webhooks,omitempty"`
-	Metadata           map[string]interface{}         `json:"metadata,omitempty"`
+	CustomTemplate           []portainer.CustomTemplate           `json:"customtemplates,omitempty"`
+	CustomTemplateRepository []portainer.CustomTemplateRepository `json:"custom_template_repositories,omitempty"`
+	EdgeGroup                []portainer.EdgeGroup                `json:"edgegroups,omitempty"`
+	EdgeJob                  []portainer.EdgeJob                  `json:"edgejobs,omitempty"`
+	EdgeStack                []portainer.EdgeStack                `json:"edge_stack,omitempty"`
+	Endpoint                 []portainer.Endpoint                 `json:"endpoints,omitempty"`
+	EndpointGroup            []portainer.EndpointGroup            `json:"endpoint_groups,omitempty"`
+	EndpointRelation         []portainer.EndpointRelation         `json:"endpoint_relations,omitempty"`
+	Extensions               []portainer.Extension                `json:"extension,omitempty"`
+	GitCredential            []portainer.GitCredential            `json:"git_credentials,omitempty"`
+	GitSigningKey            []portainer.GitSigningKey            `json:"git_signing_keys,omitempty"`
+	GitSSHKey                []portainer.GitSSHKey                `json:"git_ssh_keys,omitempty"`
+	HelmUserRepository       []portainer.HelmUserRepository       `json:"helm_user_repository,omitempty"`
+	ImageScan                []portainer.ImageScan                `json:"image_scans,omitempty"`
+	KubeconfigToken          []portainer.KubeconfigToken          `json:"kubeconfig_tokens,omitempty"`
+	PendingOperation         []portainer.PendingOperation         `json:"pending_operations,omitempty"`
+	Policy                   []portainer.Policy                   `json:"policies,omitempty"`
+	Registry                 []portainer.Registry                 `json:"registries,omitempty"`
+	ResourceControl          []portainer.ResourceControl          `json:"resource_control,omitempty"`
+	Role                     []portainer.Role                     `json:"roles,omitempty"`
+	Schedules                []portainer.Schedule                 `json:"schedules,omitempty"`
+	SessionRecording         []portainer.SessionRecording         `json:"session_recordings,omitempty"`
+	Settings                 portainer.Settings                   `json:"settings,omitempty"`
+	Snapshot                 []portainer.Snapshot                 `json:"snapshots,omitempty"`
+	SSLSettings              portainer.SSLSettings                `json:"ssl,omitempty"`
+	Stack                    []portainer.Stack                    `json:"stacks,omitempty"`
+	StackPromotion           []portainer.StackPromotion           `json:"stack_promotions,omitempty"`
+	Tag                      []portainer.Tag                      `json:"tags,omitempty"`
+	TeamMembership           []portainer.TeamMembership           `json:"team_membership,omitempty"`
+	Team                     []portainer.Team                     `json:"teams,omitempty"`
+	TunnelServer             portainer.TunnelServerInfo           `json:"tunnel_server,omitempty"`
+	User                     []portainer.User                     `json:"users,omitempty"`
+	Version                  models.Version                       `json:"version,omitempty"`
+	VolumeBackup             []portainer.VolumeBackup             `json:"volume_backups,omitempty"`
+	VolumeBackupSchedule     []portainer.VolumeBackupSchedule     `json:"volume_backup_schedules,omitempty"`
+	Webhook                  []portainer.Webhook                  `json:"webhooks,omitempty"`
+	Metadata                 map[string]interface{}               `json:"metadata,omitempty"`
 }
 
 func (store *Store) Export(filename string) (err error) {
@@ -400,6 +581,14 @@ func (store *Store) Export(filename string) (err error) {
 		backup.CustomTemplate = c
 	}
 
+	if c, err := store.CustomTemplateRepository().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Custom Template Repositories")
+		}
+	} else {
+		backup.CustomTemplateRepository = c
+	}
+
 	if e, err := store.EdgeGroup().ReadAll(); err != nil {
 		if !store.IsErrObjectNotFound(err) {
 			log.Error().Err(err).Msg("exporting Edge Groups")
@@ -464,6 +653,14 @@ func (store *Store) Export(filename string) (err error) {
 		backup.HelmUserRepository = r
 	}
 
+	if s, err := store.ImageScan().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Image Scans")
+		}
+	} else {
+		backup.ImageScan = s
+	}
+
 	if r, err := store.Registry().ReadAll(); err != nil {
 		if !store.IsErrObjectNotFound(err) {
 			log.Error().Err(err).Msg("exporting Registries")
@@ -472,6 +669,54 @@ func (store *Store) Export(filename string) (err error) {
 		backup.Registry = r
 	}
 
+	if c, err := store.GitCredential().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Git Credentials")
+		}
+	} else {
+		backup.GitCredential = c
+	}
+
+	if k, err := store.GitSigningKey().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Git Signing Keys")
+		}
+	} else {
+		backup.GitSigningKey = k
+	}
+
+	if k, err := store.GitSSHKey().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Git SSH Keys")
+		}
+	} else {
+		backup.GitSSHKey = k
+	}
+
+	if t, err := store.KubeconfigToken().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Kubeconfig Tokens")
+		}
+	} else {
+		backup.KubeconfigToken = t
+	}
+
+	if o, err := store.PendingOperation().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Pending Operations")
+		}
+	} else {
+		backup.PendingOperation = o
+	}
+
+	if p, err := store.Policy().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Policies")
+		}
+	} else {
+		backup.Policy = p
+	}
+
 	if c, err := store.ResourceControl().ReadAll(); err != nil {
 		if !store.IsErrObjectNotFound(err) {
 			log.Error().Err(err).Msg("exporting Resource Controls")
@@ -496,6 +741,14 @@ func (store *Store) Export(filename string) (err error) {
 		backup.Schedules = r
 	}
 
+	if s, err := store.SessionRecording().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Session Recordings")
+		}
+	} else {
+		backup.SessionRecording = s
+	}
+
 	if settings, err := store.Settings().Settings(); err != nil {
 		if !store.IsErrObjectNotFound(err) {
 			log.Error().Err(err).Msg("exporting Settings")
@@ -528,6 +781,14 @@ func (store *Store) Export(filename string) (err error) {
 		backup.Stack = t
 	}
 
+	if p, err := store.StackPromotion().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Stack Promotions")
+		}
+	} else {
+		backup.StackPromotion = p
+	}
+
 	if t, err := store.Tag().ReadAll(); err != nil {
 		if !store.IsErrObjectNotFound(err) {
 			log.Error().Err(err).Msg("exporting Tags")
@@ -568,6 +829,22 @@ func (store *Store) Export(filename string) (err error) {
 		backup.User = users
 	}
 
+	if b, err := store.VolumeBackup().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Volume Backups")
+		}
+	} else {
+		backup.VolumeBackup = b
+	}
+
+	if s, err := store.VolumeBackupSchedule().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Volume Backup Schedules")
+		}
+	} else {
+		backup.VolumeBackupSchedule = s
+	}
+
 	if webhooks, err := store.Webhook().ReadAll(); err != nil {
 		if !store.IsErrObjectNotFound(err) {
 			log.Error().Err(err).Msg("exporting Webhooks")
@@ -614,6 +891,10 @@ func (store *Store) Import(filename string) (err error) {
 		store.CustomTemplate().Update(v.ID, &v)
 	}
 
+	for _, v := range backup.CustomTemplateRepository {
+		store.CustomTemplateRepository().Update(v.ID, &v)
+	}
+
 	for _, v := range backup.EdgeGroup {
 		store.EdgeGroup().Update(v.ID, &v)
 	}
@@ -642,10 +923,38 @@ func (store *Store) Import(filename string) (err error) {
 		store.HelmUserRepository().Update(v.ID, &v)
 	}
 
+	for _, v := range backup.ImageScan {
+		store.ImageScan().Update(v.ID, &v)
+	}
+
 	for _, v := range backup.Registry {
 		store.Registry().Update(v.ID, &v)
 	}
 
+	for _, v := range backup.GitCredential {
+		store.GitCredential().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.GitSigningKey {
+		store.GitSigningKey().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.GitSSHKey {
+		store.GitSSHKey().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.KubeconfigToken {
+		store.KubeconfigToken().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.PendingOperation {
+		store.PendingOperation().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.Policy {
+		store.Policy().Update(v.ID, &v)
+	}
+
 	for _, v := range backup.ResourceControl {
 		store.ResourceControl().Update(v.ID, &v)
 	}
@@ -654,6 +963,10 @@ func (store *Store) Import(filename string) (err error) {
 		store.Role().Update(v.ID, &v)
 	}
 
+	for _, v := range backup.SessionRecording {
+		store.SessionRecording().Update(v.ID, &v)
+	}
+
 	store.Settings().UpdateSettings(&backup.Settings)
 	store.SSLSettings().UpdateSettings(&backup.SSLSettings)
 
@@ -665,6 +978,10 @@ func (store *Store) Import(filename string) (err error) {
 		store.Stack().Update(v.ID, &v)
 	}
 
+	for _, v := range backup.StackPromotion {
+		store.StackPromotion().Update(v.ID, &v)
+	}
+
 	for _, v := range backup.Tag {
 		store.Tag().Update(v.ID, &v)
 	}
@@ -685,6 +1002,14 @@ func (store *Store) Import(filename string) (err error) {
 		}
 	}
 
+	for _, v := range backup.VolumeBackup {
+		store.VolumeBackup().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.VolumeBackupSchedule {
+		store.VolumeBackupSchedule().Update(v.ID, &v)
+	}
+
 	for _, v := range backup.Webhook {
 		store.Webhook().Update(v.ID, &v)
 	}
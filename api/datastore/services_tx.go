@@ -15,6 +15,9 @@ func (tx *StoreTx) IsErrObjectNotFound(err error) bool {
 }
 
 func (tx *StoreTx) CustomTemplate() dataservices.CustomTemplateService { return nil }
+func (tx *StoreTx) CustomTemplateRepository() dataservices.CustomTemplateRepositoryService {
+	return nil
+}
 
 func (tx *StoreTx) EdgeGroup() dataservices.EdgeGroupService {
 	return tx.store.EdgeGroupService.Tx(tx.tx)
@@ -42,6 +45,31 @@ func (tx *StoreTx) EndpointRelation() dataservices.EndpointRelationService {
 
 func (tx *StoreTx) FDOProfile() dataservices.FDOProfileService                 { return nil }
 func (tx *StoreTx) HelmUserRepository() dataservices.HelmUserRepositoryService { return nil }
+func (tx *StoreTx) ImageScan() dataservices.ImageScanService                   { return nil }
+
+func (tx *StoreTx) GitCredential() dataservices.GitCredentialService {
+	return nil
+}
+
+func (tx *StoreTx) GitSigningKey() dataservices.GitSigningKeyService {
+	return nil
+}
+
+func (tx *StoreTx) GitSSHKey() dataservices.GitSSHKeyService {
+	return nil
+}
+
+func (tx *StoreTx) KubeconfigToken() dataservices.KubeconfigTokenService {
+	return nil
+}
+
+func (tx *StoreTx) PendingOperation() dataservices.PendingOperationService {
+	return nil
+}
+
+func (tx *StoreTx) Policy() dataservices.PolicyService {
+	return nil
+}
 
 func (tx *StoreTx) Registry() dataservices.RegistryService {
 	return tx.store.RegistryService.Tx(tx.tx)
@@ -51,12 +79,20 @@ func (tx *StoreTx) ResourceControl() dataservices.ResourceControlService {
 	return tx.store.ResourceControlService.Tx(tx.tx)
 }
 
+func (tx *StoreTx) ResourceUsageSample() dataservices.ResourceUsageSampleService {
+	return tx.store.ResourceUsageSampleService.Tx(tx.tx)
+}
+
 func (tx *StoreTx) Role() dataservices.RoleService {
 	return tx.store.RoleService.Tx(tx.tx)
 }
 
 func (tx *StoreTx) APIKeyRepository() dataservices.APIKeyRepository { return nil }
 
+func (tx *StoreTx) SessionRecording() dataservices.SessionRecordingService {
+	return nil
+}
+
 func (tx *StoreTx) Settings() dataservices.SettingsService {
 	return tx.store.SettingsService.Tx(tx.tx)
 }
@@ -67,6 +103,9 @@ func (tx *StoreTx) Snapshot() dataservices.SnapshotService {
 
 func (tx *StoreTx) SSLSettings() dataservices.SSLSettingsService { return nil }
 func (tx *StoreTx) Stack() dataservices.StackService             { return nil }
+func (tx *StoreTx) StackPromotion() dataservices.StackPromotionService {
+	return nil
+}
 
 func (tx *StoreTx) Tag() dataservices.TagService {
 	return tx.store.TagService.Tx(tx.tx)
@@ -84,4 +123,13 @@ func (tx *StoreTx) User() dataservices.UserService {
 }
 
 func (tx *StoreTx) Version() dataservices.VersionService { return nil }
+
+func (tx *StoreTx) VolumeBackup() dataservices.VolumeBackupService {
+	return nil
+}
+
+func (tx *StoreTx) VolumeBackupSchedule() dataservices.VolumeBackupScheduleService {
+	return nil
+}
+
 func (tx *StoreTx) Webhook() dataservices.WebhookService { return nil }
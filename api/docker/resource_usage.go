@@ -0,0 +1,136 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	dockerclient "github.com/portainer/portainer/api/docker/client"
+	"github.com/portainer/portainer/api/docker/consts"
+
+	"github.com/docker/docker/api/types"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	resourceUsageTimeout         = time.Minute
+	resourceUsageConcurrentStats = 8
+)
+
+// ResourceUsageCollector samples the resource usage of the running containers of the Docker environments(endpoints)
+type ResourceUsageCollector struct {
+	clientFactory *dockerclient.ClientFactory
+}
+
+// NewResourceUsageCollector returns a new ResourceUsageCollector instance
+func NewResourceUsageCollector(clientFactory *dockerclient.ClientFactory) *ResourceUsageCollector {
+	return &ResourceUsageCollector{
+		clientFactory: clientFactory,
+	}
+}
+
+// Collect reads the one-shot stats of the running containers of the environment(endpoint). The one-shot stats do not
+// include the previous CPU usage, so only the memory use and the cumulated counters are set, the rates are computed
+// against the previous sample
+func (collector *ResourceUsageCollector) Collect(endpoint *portainer.Endpoint) ([]portainer.ContainerResourceUsage, error) {
+	cli, err := collector.clientFactory.CreateClient(endpoint, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), resourceUsageTimeout)
+	defer cancel()
+
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]*portainer.ContainerResourceUsage, len(containers))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, resourceUsageConcurrentStats)
+
+	for i, container := range containers {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int, container types.Container) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			response, err := cli.ContainerStatsOneShot(ctx, container.ID)
+			if err != nil {
+				// the container may have stopped since it was listed
+				log.Debug().Str("container", container.ID).Err(err).Msg("unable to retrieve the container stats")
+				return
+			}
+			defer response.Body.Close()
+
+			var stats types.StatsJSON
+			if err := json.NewDecoder(response.Body).Decode(&stats); err != nil {
+				log.Debug().Str("container", container.ID).Err(err).Msg("unable to decode the container stats")
+				return
+			}
+
+			usage := containerResourceUsage(container, stats)
+			usages[i] = &usage
+		}(i, container)
+	}
+
+	wg.Wait()
+
+	result := make([]portainer.ContainerResourceUsage, 0, len(usages))
+	for _, usage := range usages {
+		if usage != nil {
+			result = append(result, *usage)
+		}
+	}
+
+	return result, nil
+}
+
+func containerResourceUsage(container types.Container, stats types.StatsJSON) portainer.ContainerResourceUsage {
+	usage := portainer.ContainerResourceUsage{
+		ID:             container.ID,
+		Stack:          container.Labels[consts.ComposeStackNameLabel],
+		CPUTotalUsage:  stats.CPUStats.CPUUsage.TotalUsage,
+		SystemCPUUsage: stats.CPUStats.SystemUsage,
+		OnlineCPUs:     stats.CPUStats.OnlineCPUs,
+	}
+
+	if usage.Stack == "" {
+		usage.Stack = container.Labels[consts.SwarmStackNameLabel]
+	}
+
+	if len(container.Names) > 0 {
+		usage.Name = strings.TrimPrefix(container.Names[0], "/")
+	}
+
+	if usage.OnlineCPUs == 0 {
+		usage.OnlineCPUs = uint32(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+
+	// the page cache is excluded the same way the docker CLI does, inactive_file is reported
+	// by cgroup v2 and total_inactive_file by cgroup v1
+	usage.MemoryUsage = stats.MemoryStats.Usage
+	for _, key := range []string{"inactive_file", "total_inactive_file"} {
+		if inactive, ok := stats.MemoryStats.Stats[key]; ok {
+			if inactive < usage.MemoryUsage {
+				usage.MemoryUsage -= inactive
+			}
+			break
+		}
+	}
+
+	for _, network := range stats.Networks {
+		usage.NetworkRxBytes += network.RxBytes
+		usage.NetworkTxBytes += network.TxBytes
+	}
+
+	return usage
+}
@@ -0,0 +1,208 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"os"
+	"sort"
+
+	portainer "github.com/portainer/portainer/api"
+	dockerclient "github.com/portainer/portainer/api/docker/client"
+	"github.com/portainer/portainer/api/docker/consts"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	volumeBackupImageEnvVar  = "VOLUME_BACKUP_IMAGE"
+	defaultVolumeBackupImage = "busybox:latest"
+	volumeBackupHelperLabel  = "io.portainer.volume-backup"
+	// volumeBackupMountPath is the path of the volume in the helper container, the archives contain the content of
+	// the volume under the volume/ directory
+	volumeBackupMountPath = "/volume"
+)
+
+// VolumeArchiver copies the content of the Docker volumes through short-lived helper containers. The content is
+// copied with the archive endpoints of the Docker API, the helper containers are only started to empty a volume
+type VolumeArchiver struct {
+	clientFactory *dockerclient.ClientFactory
+}
+
+// NewVolumeArchiver returns a new VolumeArchiver instance
+func NewVolumeArchiver(clientFactory *dockerclient.ClientFactory) *VolumeArchiver {
+	return &VolumeArchiver{
+		clientFactory: clientFactory,
+	}
+}
+
+// ExportVolume writes the content of the volume as a tar stream to the output
+func (archiver *VolumeArchiver) ExportVolume(endpoint *portainer.Endpoint, nodeName, volumeName string, output io.Writer) error {
+	cli, err := archiver.clientFactory.CreateClient(endpoint, nodeName, nil)
+	if err != nil {
+		return errors.WithMessage(err, "unable to create docker client")
+	}
+	defer cli.Close()
+
+	ctx := context.Background()
+
+	if _, err := cli.VolumeInspect(ctx, volumeName); err != nil {
+		return errors.Wrapf(err, "unable to find the volume %s", volumeName)
+	}
+
+	helperID, err := createVolumeHelper(ctx, cli, volumeName, nil)
+	if err != nil {
+		return err
+	}
+	defer removeVolumeHelper(cli, helperID)
+
+	content, _, err := cli.CopyFromContainer(ctx, helperID, volumeBackupMountPath)
+	if err != nil {
+		return errors.Wrapf(err, "unable to copy the content of the volume %s", volumeName)
+	}
+	defer content.Close()
+
+	if _, err := io.Copy(output, content); err != nil {
+		return errors.Wrapf(err, "unable to copy the content of the volume %s", volumeName)
+	}
+
+	return nil
+}
+
+// ImportVolume replaces the content of the volume by a tar stream written by ExportVolume. The volume is created when
+// it does not exist, an existing volume is emptied before the archive is extracted
+func (archiver *VolumeArchiver) ImportVolume(endpoint *portainer.Endpoint, nodeName, volumeName string, input io.Reader) error {
+	cli, err := archiver.clientFactory.CreateClient(endpoint, nodeName, nil)
+	if err != nil {
+		return errors.WithMessage(err, "unable to create docker client")
+	}
+	defer cli.Close()
+
+	ctx := context.Background()
+
+	if _, err := cli.VolumeInspect(ctx, volumeName); client.IsErrNotFound(err) {
+		if _, err := cli.VolumeCreate(ctx, volume.CreateOptions{Name: volumeName}); err != nil {
+			return errors.Wrapf(err, "unable to create the volume %s", volumeName)
+		}
+	} else if err != nil {
+		return errors.Wrapf(err, "unable to inspect the volume %s", volumeName)
+	} else if err := emptyVolume(ctx, cli, volumeName); err != nil {
+		return err
+	}
+
+	helperID, err := createVolumeHelper(ctx, cli, volumeName, nil)
+	if err != nil {
+		return err
+	}
+	defer removeVolumeHelper(cli, helperID)
+
+	if err := cli.CopyToContainer(ctx, helperID, "/", input, types.CopyToContainerOptions{}); err != nil {
+		return errors.Wrapf(err, "unable to copy the archive to the volume %s", volumeName)
+	}
+
+	return nil
+}
+
+// StackVolumes returns the names of the volumes of a compose or swarm stack
+func (archiver *VolumeArchiver) StackVolumes(endpoint *portainer.Endpoint, nodeName, stackName string) ([]string, error) {
+	cli, err := archiver.clientFactory.CreateClient(endpoint, nodeName, nil)
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to create docker client")
+	}
+	defer cli.Close()
+
+	volumes, err := cli.VolumeList(context.Background(), filters.NewArgs())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list the volumes")
+	}
+
+	names := []string{}
+	for _, volume := range volumes.Volumes {
+		if volume.Labels[consts.ComposeStackNameLabel] == stackName || volume.Labels[consts.SwarmStackNameLabel] == stackName {
+			names = append(names, volume.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// emptyVolume removes the content of the volume, it is the only operation running a helper container
+func emptyVolume(ctx context.Context, cli *client.Client, volumeName string) error {
+	helperID, err := createVolumeHelper(ctx, cli, volumeName, []string{"find", volumeBackupMountPath, "-mindepth", "1", "-delete"})
+	if err != nil {
+		return err
+	}
+	defer removeVolumeHelper(cli, helperID)
+
+	statusCh, errCh := cli.ContainerWait(ctx, helperID, container.WaitConditionNextExit)
+
+	if err := cli.ContainerStart(ctx, helperID, types.ContainerStartOptions{}); err != nil {
+		return errors.Wrapf(err, "unable to empty the volume %s", volumeName)
+	}
+
+	select {
+	case err := <-errCh:
+		return errors.Wrapf(err, "unable to empty the volume %s", volumeName)
+	case status := <-statusCh:
+		if status.StatusCode != 0 {
+			return errors.Errorf("unable to empty the volume %s, the helper container exited with the code %d", volumeName, status.StatusCode)
+		}
+	}
+
+	return nil
+}
+
+// createVolumeHelper creates a container mounting the volume, the command is only run when the container is started
+func createVolumeHelper(ctx context.Context, cli *client.Client, volumeName string, cmd []string) (string, error) {
+	image := getVolumeBackupImage()
+
+	if _, _, err := cli.ImageInspectWithRaw(ctx, image); client.IsErrNotFound(err) {
+		reader, err := cli.ImagePull(ctx, image, types.ImagePullOptions{})
+		if err != nil {
+			return "", errors.Wrap(err, "unable to pull the volume backup image")
+		}
+		defer reader.Close()
+		io.Copy(io.Discard, reader)
+	} else if err != nil {
+		return "", errors.Wrap(err, "unable to inspect the volume backup image")
+	}
+
+	helper, err := cli.ContainerCreate(ctx, &container.Config{
+		Image:  image,
+		Cmd:    cmd,
+		Labels: map[string]string{volumeBackupHelperLabel: volumeName},
+	}, &container.HostConfig{
+		Mounts: []mount.Mount{{
+			Type:   mount.TypeVolume,
+			Source: volumeName,
+			Target: volumeBackupMountPath,
+		}},
+	}, nil, nil, "")
+	if err != nil {
+		return "", errors.Wrap(err, "unable to create the volume backup container")
+	}
+
+	return helper.ID, nil
+}
+
+func removeVolumeHelper(cli *client.Client, helperID string) {
+	if err := cli.ContainerRemove(context.Background(), helperID, types.ContainerRemoveOptions{Force: true}); err != nil {
+		log.Warn().Err(err).Str("container_id", helperID).Msg("unable to remove the volume backup container")
+	}
+}
+
+func getVolumeBackupImage() string {
+	image := os.Getenv(volumeBackupImageEnvVar)
+	if image == "" {
+		image = defaultVolumeBackupImage
+	}
+
+	return image
+}
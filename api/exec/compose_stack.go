@@ -15,6 +15,7 @@ import (
 	"github.com/portainer/portainer/pkg/libstack"
 
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 )
 
 // ComposeStackManager is a wrapper for docker-compose binary
@@ -52,6 +53,7 @@ func (manager *ComposeStackManager) Up(ctx context.Context, stack *portainer.Sta
 	if err != nil {
 		return errors.Wrap(err, "failed to create env file")
 	}
+	defer removeEnvFile(stack, envFilePath)
 
 	filePaths := stackutils.GetStackFilePaths(stack, true)
 	err = manager.deployer.Deploy(ctx, filePaths, libstack.DeployOptions{
@@ -80,6 +82,7 @@ func (manager *ComposeStackManager) Down(ctx context.Context, stack *portainer.S
 	if err != nil {
 		return errors.Wrap(err, "failed to create env file")
 	}
+	defer removeEnvFile(stack, envFilePath)
 
 	err = manager.deployer.Remove(ctx, stack.Name, nil, libstack.Options{
 		WorkingDir:  stack.ProjectPath,
@@ -105,6 +108,7 @@ func (manager *ComposeStackManager) Pull(ctx context.Context, stack *portainer.S
 	if err != nil {
 		return errors.Wrap(err, "failed to create env file")
 	}
+	defer removeEnvFile(stack, envFilePath)
 
 	filePaths := stackutils.GetStackFilePaths(stack, true)
 	err = manager.deployer.Pull(ctx, filePaths, libstack.Options{
@@ -116,6 +120,31 @@ func (manager *ComposeStackManager) Pull(ctx context.Context, stack *portainer.S
 	return errors.Wrap(err, "failed to pull images of the stack")
 }
 
+// Validate checks the stack files without deploying them. Wraps `docker compose config --quiet` command
+func (manager *ComposeStackManager) Validate(ctx context.Context, stack *portainer.Stack) error {
+	options, err := projectOptions(stack)
+	if err != nil {
+		return err
+	}
+	defer removeEnvFile(stack, options.EnvFilePath)
+
+	err = manager.deployer.Validate(ctx, stackutils.GetStackFilePaths(stack, true), options)
+	return errors.Wrap(err, "failed to validate the stack files")
+}
+
+// Config returns the project of the stack in the JSON format, with the stack files merged and the variables interpolated.
+// Wraps `docker compose config --format json` command
+func (manager *ComposeStackManager) Config(ctx context.Context, stack *portainer.Stack) ([]byte, error) {
+	options, err := projectOptions(stack)
+	if err != nil {
+		return nil, err
+	}
+	defer removeEnvFile(stack, options.EnvFilePath)
+
+	config, err := manager.deployer.Config(ctx, stackutils.GetStackFilePaths(stack, true), options)
+	return config, errors.Wrap(err, "failed to resolve the stack files")
+}
+
 // NormalizeStackName returns a new stack name with unsupported characters replaced
 func (manager *ComposeStackManager) NormalizeStackName(name string) string {
 	return stackNameNormalizeRegex.ReplaceAllString(strings.ToLower(name), "")
@@ -134,6 +163,21 @@ func (manager *ComposeStackManager) fetchEndpointProxy(endpoint *portainer.Endpo
 	return fmt.Sprintf("tcp://127.0.0.1:%d", proxy.Port), proxy, nil
 }
 
+// projectOptions returns the options of the commands that only read the stack files,
+// the env file of the options must be removed once the command returns
+func projectOptions(stack *portainer.Stack) (libstack.Options, error) {
+	envFilePath, err := createEnvFile(stack)
+	if err != nil {
+		return libstack.Options{}, errors.Wrap(err, "failed to create env file")
+	}
+
+	return libstack.Options{
+		WorkingDir:  stack.ProjectPath,
+		EnvFilePath: envFilePath,
+		ProjectName: stack.Name,
+	}, nil
+}
+
 // createEnvFile creates a file that would hold both "in-place" and default environment variables.
 // It will return the name of the file if the stack has "in-place" env vars, otherwise empty string.
 func createEnvFile(stack *portainer.Stack) (string, error) {
@@ -157,6 +201,18 @@ func createEnvFile(stack *portainer.Stack) (string, error) {
 	return "stack.env", nil
 }
 
+// removeEnvFile removes the env file created for a command once it returns, the file holds the values
+// of the secret variables of the stack in clear text
+func removeEnvFile(stack *portainer.Stack, envFilePath string) {
+	if envFilePath == "" {
+		return
+	}
+
+	if err := os.Remove(path.Join(stack.ProjectPath, envFilePath)); err != nil && !os.IsNotExist(err) {
+		log.Warn().Err(err).Str("project_path", stack.ProjectPath).Msg("unable to remove the stack env file")
+	}
+}
+
 // copyDefaultEnvFile copies the default .env file if it exists to the provided writer
 func copyDefaultEnvFile(stack *portainer.Stack, w io.Writer) {
 	defaultEnvFile, err := os.Open(path.Join(path.Join(stack.ProjectPath, path.Dir(stack.EntryPoint)), ".env"))
@@ -84,3 +84,23 @@ func Test_createEnvFile_mergesDefultAndInplaceEnvVars(t *testing.T) {
 
 	assert.Equal(t, []byte("VAR1=VAL1\nVAR2=VAL2\n\nVAR1=NEW_VAL1\nVAR3=VAL3\n"), content)
 }
+
+func Test_removeEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	stack := &portainer.Stack{
+		ProjectPath: dir,
+		Env:         []portainer.Pair{{Name: "SECRET", Value: "value"}},
+	}
+
+	result, err := createEnvFile(stack)
+	assert.NoError(t, err)
+	assert.FileExists(t, path.Join(dir, "stack.env"))
+
+	removeEnvFile(stack, result)
+	assert.NoFileExists(t, path.Join(dir, "stack.env"))
+
+	// nothing is removed when no env file was created
+	os.WriteFile(path.Join(dir, "stack.env"), []byte("VAR=VAL\n"), 0600)
+	removeEnvFile(stack, "")
+	assert.FileExists(t, path.Join(dir, "stack.env"))
+}
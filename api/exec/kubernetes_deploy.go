@@ -82,12 +82,23 @@ func (deployer *KubernetesDeployer) Deploy(userID portainer.UserID, endpoint *po
 	return deployer.command("apply", userID, endpoint, manifestFiles, namespace)
 }
 
+// DryRun submits the manifest(s) to a server-side dry-run apply, the resources are returned in the JSON format as
+// they would be persisted once applied
+func (deployer *KubernetesDeployer) DryRun(userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string) (string, error) {
+	return deployer.command("apply", userID, endpoint, manifestFiles, namespace, "--dry-run=server", "--output=json")
+}
+
+// Inspect returns the live resources defined in the manifest(s) in the JSON format, the missing resources are ignored
+func (deployer *KubernetesDeployer) Inspect(userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string) (string, error) {
+	return deployer.command("get", userID, endpoint, manifestFiles, namespace, "--ignore-not-found=true", "--output=json")
+}
+
 // Remove deletes Kubernetes resources defined in manifest(s)
 func (deployer *KubernetesDeployer) Remove(userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string) (string, error) {
 	return deployer.command("delete", userID, endpoint, manifestFiles, namespace)
 }
 
-func (deployer *KubernetesDeployer) command(operation string, userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string) (string, error) {
+func (deployer *KubernetesDeployer) command(operation string, userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string, operationArgs ...string) (string, error) {
 	token, err := deployer.getToken(userID, endpoint, endpoint.Type == portainer.KubernetesLocalEnvironment)
 	if err != nil {
 		return "", errors.Wrap(err, "failed generating a user token")
@@ -119,6 +130,7 @@ func (deployer *KubernetesDeployer) command(operation string, userID portainer.U
 	}
 
 	args = append(args, operation)
+	args = append(args, operationArgs...)
 	for _, path := range manifestFiles {
 		args = append(args, "-f", strings.TrimSpace(path))
 	}
@@ -2,6 +2,7 @@ package filesystem
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/json"
 	"encoding/pem"
 	"errors"
@@ -42,6 +43,8 @@ const (
 	PrivateKeyFile = "portainer.key"
 	// PublicKeyFile represents the name on disk of the file containing the public key.
 	PublicKeyFile = "portainer.pub"
+	// SecretKeyFile represents the name on disk of the file containing the key encrypting the secrets stored in the database.
+	SecretKeyFile = "secret.key"
 	// BinaryStorePath represents the subfolder where binaries are stored in the file store folder.
 	BinaryStorePath = "bin"
 	// EdgeJobStorePath represents the subfolder where schedule files are stored.
@@ -53,6 +56,12 @@ const (
 	ExtensionRegistryManagementStorePath = "extensions"
 	// CustomTemplateStorePath represents the subfolder where custom template files are stored in the file store folder.
 	CustomTemplateStorePath = "custom_templates"
+	// StackPromotionStorePath represents the subfolder where the stack revisions captured by promotions are stored.
+	StackPromotionStorePath = "stack_promotions"
+	// SessionRecordingStorePath represents the subfolder where the terminal session recordings are stored.
+	SessionRecordingStorePath = "session_recordings"
+	// VolumeBackupStorePath represents the subfolder where the archives of the Docker volume backups are stored.
+	VolumeBackupStorePath = "volume_backups"
 	// TempPath represent the subfolder where temporary files are saved
 	TempPath = "tmp"
 	// SSLCertPath represents the default ssl certificates path
@@ -625,6 +634,30 @@ func (service *Service) LoadKeyPair() ([]byte, []byte, error) {
 	return privateKey, publicKey, nil
 }
 
+// LoadOrCreateSecretKey returns the key encrypting the secrets stored in the database, a random 256-bit key is
+// created on the first call. The key is kept out of the database so that the database exports do not reveal the secrets.
+func (service *Service) LoadOrCreateSecretKey() ([]byte, error) {
+	keyPath := JoinPaths(service.dataStorePath, SecretKeyFile)
+
+	key, err := os.ReadFile(keyPath)
+	if err == nil {
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read the secret key: %w", err)
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("unable to generate the secret key: %w", err)
+	}
+
+	if err := os.WriteFile(keyPath, key, 0o600); err != nil {
+		return nil, fmt.Errorf("unable to store the secret key: %w", err)
+	}
+
+	return key, nil
+}
+
 // createDirectoryInStore creates a new directory in the file store
 func (service *Service) createDirectoryInStore(name string) error {
 	path := service.wrapFileStore(name)
@@ -688,6 +721,24 @@ func (service *Service) getContentFromPEMFile(filePath string) ([]byte, error) {
 	return block.Bytes, nil
 }
 
+// GetStackPromotionPath returns the absolute path on the FS of the stack files captured by a promotion
+// based on its identifier.
+func (service *Service) GetStackPromotionPath(identifier string) string {
+	return JoinPaths(service.wrapFileStore(StackPromotionStorePath), identifier)
+}
+
+// GetSessionRecordingPath returns the absolute path on the FS of a terminal session recording
+// based on its identifier.
+func (service *Service) GetSessionRecordingPath(identifier string) string {
+	return JoinPaths(service.wrapFileStore(SessionRecordingStorePath), identifier+".cast")
+}
+
+// GetVolumeBackupPath returns the absolute path on the FS of the archive of a volume backup
+// based on its file name.
+func (service *Service) GetVolumeBackupPath(fileName string) string {
+	return JoinPaths(service.wrapFileStore(VolumeBackupStorePath), fileName)
+}
+
 // GetCustomTemplateProjectPath returns the absolute path on the FS for a custom template based
 // on its identifier.
 func (service *Service) GetCustomTemplateProjectPath(identifier string) string {
@@ -20,3 +20,19 @@ func createService(t *testing.T) *Service {
 
 	return service
 }
+
+func Test_LoadOrCreateSecretKey(t *testing.T) {
+	service := createService(t)
+
+	key, err := service.LoadOrCreateSecretKey()
+	assert.NoError(t, err)
+	assert.Len(t, key, 32)
+
+	info, err := os.Stat(path.Join(service.dataStorePath, SecretKeyFile))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := service.LoadOrCreateSecretKey()
+	assert.NoError(t, err)
+	assert.Equal(t, key, loaded, "the key is created once")
+}
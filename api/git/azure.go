@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/portainer/portainer/api/archive"
 	"github.com/portainer/portainer/api/crypto"
@@ -49,6 +52,13 @@ type azureItem struct {
 	Path     string `json:"path"`
 }
 
+// azureTreeEntry abstracts from the entries of https://docs.microsoft.com/en-us/rest/api/azure/devops/git/trees/get?view=azure-devops-rest-6.0
+type azureTreeEntry struct {
+	RelativePath string `json:"relativePath"`
+	Mode         string `json:"mode"`
+	ObjectID     string `json:"objectId"`
+}
+
 type azureClient struct {
 	baseUrl string
 }
@@ -77,16 +87,98 @@ func newHttpClientForAzure(insecureSkipVerify bool) *http.Client {
 	return httpsCli
 }
 
-func (a *azureClient) download(ctx context.Context, destination string, opt cloneOption) error {
+// download extracts the repository in destination and returns the identifier of the extracted commit,
+// the reference is resolved first so that the archive matches the returned commit
+func (a *azureClient) download(ctx context.Context, destination string, opt cloneOption) (string, error) {
+	commitID, err := a.latestCommitID(ctx, opt.fetchOption)
+	if err != nil {
+		return "", err
+	}
+	opt.referenceName = commitID
+
 	zipFilepath, err := a.downloadZipFromAzureDevOps(ctx, opt)
 	if err != nil {
-		return errors.Wrap(err, "failed to download a zip file from Azure DevOps")
+		return "", errors.Wrap(err, "failed to download a zip file from Azure DevOps")
 	}
 	defer os.Remove(zipFilepath)
 
 	err = archive.UnzipFile(zipFilepath, destination)
 	if err != nil {
-		return errors.Wrap(err, "failed to unzip file")
+		return "", errors.Wrap(err, "failed to unzip file")
+	}
+
+	if opt.content.submodules {
+		if err := a.downloadSubmodules(ctx, destination, opt.fetchOption, 0); err != nil {
+			return "", err
+		}
+	}
+
+	return commitID, nil
+}
+
+// downloadSubmodules downloads the submodules of a repository extracted in the destination, the zip archives of
+// Azure DevOps do not contain them. The submodules hosted outside of Azure DevOps are cloned.
+func (a *azureClient) downloadSubmodules(ctx context.Context, destination string, opt fetchOption, depth int) error {
+	if depth >= maxSubmoduleDepth {
+		return errors.Errorf("the submodules are nested more than %d times", maxSubmoduleDepth)
+	}
+
+	content, err := os.ReadFile(filepath.Join(destination, gitModulesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	submodules, err := parseGitModules(content)
+	if err != nil {
+		return err
+	}
+
+	_, entries, err := a.listTree(ctx, opt)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		submodule, ok := submodules[entry.RelativePath]
+		if mode, _ := filemode.New(entry.Mode); !ok || mode != filemode.Submodule {
+			continue
+		}
+
+		submoduleURL := resolveSubmoduleURL(opt.repositoryUrl, submodule.URL)
+		submoduleOpt, err := opt.submoduleOption(submoduleURL, entry.ObjectID)
+		if err != nil {
+			return err
+		}
+		submoduleDst := filepath.Join(destination, filepath.FromSlash(entry.RelativePath))
+
+		if !submoduleOpt.useAzureClient() {
+			if err := cloneCommit(ctx, submoduleDst, submoduleOpt, entry.ObjectID); err != nil {
+				return err
+			}
+
+			if err := removeGitMetadata(submoduleDst); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		zipFilepath, err := a.downloadZipFromAzureDevOps(ctx, cloneOption{fetchOption: submoduleOpt})
+		if err != nil {
+			return errors.WithMessagef(err, "failed to download the submodule %s", entry.RelativePath)
+		}
+
+		err = archive.UnzipFile(zipFilepath, submoduleDst)
+		os.Remove(zipFilepath)
+		if err != nil {
+			return errors.Wrap(err, "failed to unzip file")
+		}
+
+		if err := a.downloadSubmodules(ctx, submoduleDst, submoduleOpt, depth+1); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -98,7 +190,7 @@ func (a *azureClient) downloadZipFromAzureDevOps(ctx context.Context, opt cloneO
 		return "", errors.WithMessage(err, "failed to parse url")
 	}
 
-	downloadUrl, err := a.buildDownloadUrl(config, opt.referenceName)
+	downloadUrl, err := a.buildDownloadUrl(config, opt.referenceName, opt.content.lfs)
 	if err != nil {
 		return "", errors.WithMessage(err, "failed to build download url")
 	}
@@ -152,6 +244,11 @@ func (a *azureClient) latestCommitID(ctx context.Context, opt fetchOption) (stri
 	return rootItem.CommitId, nil
 }
 
+// commitSignature is not supported since the Azure DevOps API does not expose the commit signatures
+func (a *azureClient) commitSignature(ctx context.Context, opt fetchOption) (*gittypes.CommitSignature, error) {
+	return nil, errors.New("commit signature verification is not supported for Azure DevOps repositories")
+}
+
 func (a *azureClient) getRootItem(ctx context.Context, opt fetchOption) (*azureItem, error) {
 	config, err := parseUrl(opt.repositoryUrl)
 	if err != nil {
@@ -270,7 +367,8 @@ func parseHttpUrl(rawUrl string) (*azureOptions, error) {
 	return &opt, nil
 }
 
-func (a *azureClient) buildDownloadUrl(config *azureOptions, referenceName string) (string, error) {
+// buildDownloadUrl builds the url of the zip archive of the repository, the LFS pointers are replaced by their objects when resolveLfs is set
+func (a *azureClient) buildDownloadUrl(config *azureOptions, referenceName string, resolveLfs bool) (string, error) {
 	rawUrl := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/items",
 		a.baseUrl,
 		url.PathEscape(config.organisation),
@@ -291,6 +389,9 @@ func (a *azureClient) buildDownloadUrl(config *azureOptions, referenceName strin
 	}
 	q.Set("$format", "zip")
 	q.Set("recursionLevel", "full")
+	if resolveLfs {
+		q.Set("resolveLfs", "true")
+	}
 	q.Set("api-version", "6.0")
 	u.RawQuery = q.Encode()
 
@@ -321,6 +422,29 @@ func (a *azureClient) buildRootItemUrl(config *azureOptions, referenceName strin
 	return u.String(), nil
 }
 
+func (a *azureClient) buildFileUrl(config *azureOptions, commitID, filePath string) (string, error) {
+	rawUrl := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/items",
+		a.baseUrl,
+		url.PathEscape(config.organisation),
+		url.PathEscape(config.project),
+		url.PathEscape(config.repository))
+	u, err := url.Parse(rawUrl)
+
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse file url path %s", rawUrl)
+	}
+
+	q := u.Query()
+	q.Set("path", "/"+filePath)
+	q.Set("versionDescriptor.versionType", "commit")
+	q.Set("versionDescriptor.version", commitID)
+	q.Set("$format", "octetStream")
+	q.Set("api-version", "6.0")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
 func (a *azureClient) buildRefsUrl(config *azureOptions) (string, error) {
 	// ref@https://docs.microsoft.com/en-us/rest/api/azure/devops/git/refs/list?view=azure-devops-rest-6.0#gitref
 	rawUrl := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/refs",
@@ -450,19 +574,99 @@ func (a *azureClient) listRefs(ctx context.Context, opt baseOption) ([]string, e
 
 // listFiles list all filenames under the specific repository
 func (a *azureClient) listFiles(ctx context.Context, opt fetchOption) ([]string, error) {
-	rootItem, err := a.getRootItem(ctx, opt)
+	return a.listFilesAt(ctx, opt, 0)
+}
+
+// listFilesAt lists the files of a repository, the files of its submodules are listed when the submodules are fetched
+func (a *azureClient) listFilesAt(ctx context.Context, opt fetchOption, depth int) ([]string, error) {
+	rootItem, entries, err := a.listTree(ctx, opt)
 	if err != nil {
 		return nil, err
 	}
 
+	var submodules map[string]*config.Submodule
+	if opt.content.submodules {
+		content, err := a.getFileContent(ctx, opt, rootItem.CommitId, gitModulesFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if content != nil {
+			if submodules, err = parseGitModules(content); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var allPaths []string
+	for _, treeEntry := range entries {
+		mode, _ := filemode.New(treeEntry.Mode)
+
+		if submodule, ok := submodules[treeEntry.RelativePath]; ok && mode == filemode.Submodule {
+			paths, err := a.listSubmoduleFiles(ctx, opt, submodule, treeEntry, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			if opt.dirOnly {
+				allPaths = append(allPaths, treeEntry.RelativePath)
+			}
+			allPaths = append(allPaths, paths...)
+
+			continue
+		}
+
+		isDir := filemode.Dir == mode
+		if opt.dirOnly == isDir {
+			allPaths = append(allPaths, treeEntry.RelativePath)
+		}
+	}
+
+	return allPaths, nil
+}
+
+func (a *azureClient) listSubmoduleFiles(ctx context.Context, opt fetchOption, submodule *config.Submodule, entry azureTreeEntry, depth int) ([]string, error) {
+	submoduleURL := resolveSubmoduleURL(opt.repositoryUrl, submodule.URL)
+	submoduleOpt, err := opt.submoduleOption(submoduleURL, entry.ObjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !submoduleOpt.useAzureClient() {
+		return listSubmoduleFiles(ctx, submoduleOpt, entry.RelativePath, entry.ObjectID, depth)
+	}
+
+	if depth > maxSubmoduleDepth {
+		return nil, errors.Errorf("the submodules are nested more than %d times", maxSubmoduleDepth)
+	}
+
+	paths, err := a.listFilesAt(ctx, submoduleOpt, depth)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to list the files of the submodule %s", entry.RelativePath)
+	}
+
+	for i := range paths {
+		paths[i] = path.Join(entry.RelativePath, paths[i])
+	}
+
+	return paths, nil
+}
+
+// listTree returns the root item of the reference with the entries of its tree
+func (a *azureClient) listTree(ctx context.Context, opt fetchOption) (*azureItem, []azureTreeEntry, error) {
+	rootItem, err := a.getRootItem(ctx, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	config, err := parseUrl(opt.repositoryUrl)
 	if err != nil {
-		return nil, errors.WithMessage(err, "failed to parse url")
+		return nil, nil, errors.WithMessage(err, "failed to parse url")
 	}
 
 	listTreeUrl, err := a.buildTreeUrl(config, rootItem.ObjectID)
 	if err != nil {
-		return nil, errors.WithMessage(err, "failed to build list tree url")
+		return nil, nil, errors.WithMessage(err, "failed to build list tree url")
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", listTreeUrl, nil)
@@ -473,7 +677,7 @@ func (a *azureClient) listFiles(ctx context.Context, opt fetchOption) ([]string,
 	}
 
 	if err != nil {
-		return nil, errors.WithMessage(err, "failed to create a new HTTP request")
+		return nil, nil, errors.WithMessage(err, "failed to create a new HTTP request")
 	}
 
 	client := newHttpClientForAzure(opt.tlsSkipVerify)
@@ -481,35 +685,64 @@ func (a *azureClient) listFiles(ctx context.Context, opt fetchOption) ([]string,
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, errors.WithMessage(err, "failed to make an HTTP request")
+		return nil, nil, errors.WithMessage(err, "failed to make an HTTP request")
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to list tree url with a status \"%v\"", resp.Status)
+		return nil, nil, fmt.Errorf("failed to list tree url with a status \"%v\"", resp.Status)
 	}
 
 	var tree struct {
-		TreeEntries []struct {
-			RelativePath string `json:"relativePath"`
-			Mode         string `json:"mode"`
-		} `json:"treeEntries"`
+		TreeEntries []azureTreeEntry `json:"treeEntries"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&tree); err != nil {
-		return nil, errors.Wrap(err, "could not parse Azure tree response")
+		return nil, nil, errors.Wrap(err, "could not parse Azure tree response")
 	}
 
-	var allPaths []string
-	for _, treeEntry := range tree.TreeEntries {
-		mode, _ := filemode.New(treeEntry.Mode)
-		isDir := filemode.Dir == mode
-		if opt.dirOnly == isDir {
-			allPaths = append(allPaths, treeEntry.RelativePath)
-		}
+	return rootItem, tree.TreeEntries, nil
+}
+
+// getFileContent returns the content of a file at a commit, nil is returned when the file does not exist
+func (a *azureClient) getFileContent(ctx context.Context, opt fetchOption, commitID, filePath string) ([]byte, error) {
+	config, err := parseUrl(opt.repositoryUrl)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to parse url")
 	}
 
-	return allPaths, nil
+	fileUrl, err := a.buildFileUrl(config, commitID, filePath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to build file url")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fileUrl, nil)
+	if opt.username != "" || opt.password != "" {
+		req.SetBasicAuth(opt.username, opt.password)
+	} else if config.username != "" || config.password != "" {
+		req.SetBasicAuth(config.username, config.password)
+	}
+
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create a new HTTP request")
+	}
+
+	client := newHttpClientForAzure(opt.tlsSkipVerify)
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to make an HTTP request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get file %s with a status \"%v\"", filePath, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
 }
 
 func checkAzureStatusCode(err error, code int) error {
@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	gittypes "github.com/portainer/portainer/api/git/types"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,7 +19,7 @@ func Test_buildDownloadUrl(t *testing.T) {
 		organisation: "organisation",
 		project:      "project",
 		repository:   "repository",
-	}, "refs/heads/main")
+	}, "refs/heads/main", false)
 
 	expectedUrl, _ := url.Parse("https://dev.azure.com/organisation/project/_apis/git/repositories/repository/items?scopePath=/&download=true&versionDescriptor.version=main&$format=zip&recursionLevel=full&api-version=6.0&versionDescriptor.versionType=branch")
 	actualUrl, _ := url.Parse(u)
@@ -366,9 +368,9 @@ type testRepoManager struct {
 	called bool
 }
 
-func (t *testRepoManager) download(_ context.Context, _ string, _ cloneOption) error {
+func (t *testRepoManager) download(_ context.Context, _ string, _ cloneOption) (string, error) {
 	t.called = true
-	return nil
+	return "", nil
 }
 
 func (t *testRepoManager) latestCommitID(_ context.Context, _ fetchOption) (string, error) {
@@ -382,6 +384,11 @@ func (t *testRepoManager) listRefs(_ context.Context, _ baseOption) ([]string, e
 func (t *testRepoManager) listFiles(_ context.Context, _ fetchOption) ([]string, error) {
 	return nil, nil
 }
+
+func (t *testRepoManager) commitSignature(_ context.Context, _ fetchOption) (*gittypes.CommitSignature, error) {
+	return nil, nil
+}
+
 func Test_cloneRepository_azure(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -621,3 +628,62 @@ func Test_listFiles_azure(t *testing.T) {
 		})
 	}
 }
+
+func Test_buildDownloadUrl_ResolveLfs(t *testing.T) {
+	a := NewAzureClient()
+	u, err := a.buildDownloadUrl(&azureOptions{
+		organisation: "organisation",
+		project:      "project",
+		repository:   "repository",
+	}, "refs/heads/main", true)
+
+	actualUrl, _ := url.Parse(u)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "true", actualUrl.Query().Get("resolveLfs"))
+	}
+}
+
+func Test_azureClient_listFiles_Submodules(t *testing.T) {
+	is := assert.New(t)
+
+	submoduleURL := "https://dev.azure.com/Organisation/Project/_git/Common"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isSubmodule := strings.Contains(r.URL.Path, "/repositories/Common/")
+
+		switch {
+		case isSubmodule && strings.HasSuffix(r.URL.Path, "/trees/tree-id"):
+			w.Write([]byte(`{"treeEntries": [{"relativePath": "config/common.env", "mode": "100644", "objectId": "blob-id"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/trees/tree-id"):
+			w.Write([]byte(`{"treeEntries": [
+				{"relativePath": ".gitmodules", "mode": "100644", "objectId": "blob-id"},
+				{"relativePath": "docker-compose.yml", "mode": "100644", "objectId": "blob-id"},
+				{"relativePath": "common", "mode": "160000", "objectId": "submodule-commit-id"}
+			]}`))
+		case r.URL.Query().Get("path") == "/.gitmodules" && isSubmodule:
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Query().Get("path") == "/.gitmodules":
+			is.Equal("commit-id", r.URL.Query().Get("versionDescriptor.version"))
+			w.Write([]byte("[submodule \"common\"]\n\tpath = common\n\turl = " + submoduleURL + "\n"))
+		default:
+			w.Write([]byte(`{"value": [{"objectId": "tree-id", "commitId": "commit-id", "path": "/"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	a := &azureClient{baseUrl: server.URL}
+	opt := fetchOption{baseOption: baseOption{repositoryUrl: "https://dev.azure.com/Organisation/Project/_git/Repository"}}
+
+	paths, err := a.listFiles(context.TODO(), opt)
+	is.NoError(err)
+	is.ElementsMatch([]string{".gitmodules", "docker-compose.yml", "common"}, paths)
+
+	opt.content.submodules = true
+	paths, err = a.listFiles(context.TODO(), opt)
+	is.NoError(err)
+	is.ElementsMatch([]string{".gitmodules", "docker-compose.yml", "common/config/common.env"}, paths)
+
+	submoduleURL = "/srv/git/common"
+	_, err = a.listFiles(context.TODO(), opt)
+	is.Error(err, "the submodules of a remote repository cannot be local")
+}
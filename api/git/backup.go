@@ -2,6 +2,7 @@ package git
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	portainer "github.com/portainer/portainer/api"
@@ -18,10 +19,14 @@ type CloneOptions struct {
 	ProjectPath   string
 	URL           string
 	ReferenceName string
-	Username      string
-	Password      string
+	// Authentication of the repository, either credentials or a stored SSH key
+	Authentication *gittypes.GitAuthentication
 	// TLSSkipVerify skips SSL verification when cloning the Git repository
 	TLSSkipVerify bool `example:"false"`
+	// Content options of the repository, like the submodules and the LFS objects
+	Content *gittypes.ContentOptions
+	// CommitID is the commit expected at the reference, the backup is restored when another commit is cloned
+	CommitID string
 }
 
 func CloneWithBackup(gitService portainer.GitService, fileService portainer.FileService, options CloneOptions) (clean func(), err error) {
@@ -45,7 +50,14 @@ func CloneWithBackup(gitService portainer.GitService, fileService portainer.File
 
 	cleanUp = true
 
-	err = gitService.CloneRepository(options.ProjectPath, options.URL, options.ReferenceName, options.Username, options.Password, options.TLSSkipVerify)
+	commitID, err := gitService.CloneRepositoryCommit(options.ProjectPath, options.URL, options.ReferenceName, options.Authentication, options.TLSSkipVerify, options.Content)
+	if err == nil && options.CommitID != "" && !strings.EqualFold(commitID, options.CommitID) {
+		err = errors.Errorf("the reference moved from the commit %s to %s during the clone", options.CommitID, commitID)
+		if removeErr := fileService.RemoveDirectory(options.ProjectPath); removeErr != nil {
+			log.Warn().Err(removeErr).Msg("unable to remove git repository directory")
+		}
+	}
+
 	if err != nil {
 		cleanUp = false
 		restoreError := filesystem.MoveDirectory(backupProjectPath, options.ProjectPath)
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"slices"
+
+	"github.com/pkg/errors"
+	portainer "github.com/portainer/portainer/api"
+	gittypes "github.com/portainer/portainer/api/git/types"
+)
+
+var ErrGitCredentialAccessDenied = errors.New("Access denied to the git credential")
+
+// CredentialReader is the subset of the git credential data service used to resolve the shared credential of a repository authentication
+type CredentialReader interface {
+	Read(ID portainer.GitCredentialID) (*portainer.GitCredential, error)
+}
+
+// CanUseCredential returns true when the credential can be referenced by the repositories of the user,
+// which is allowed to the administrators, to the owner of the credential and to the members of its teams
+func CanUseCredential(credential *portainer.GitCredential, userID portainer.UserID, memberships []portainer.TeamMembership, isAdmin bool) bool {
+	if isAdmin || credential.UserID == userID {
+		return true
+	}
+
+	for _, membership := range memberships {
+		if membership.UserID == userID && slices.Contains(credential.TeamIDs, membership.TeamID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateCredentialAccess makes sure that the credential exists and can be used by the user, see CanUseCredential.
+// ErrGitCredentialAccessDenied is returned as well when the credential cannot be read so that the existence of the
+// credentials of the other users is not disclosed
+func ValidateCredentialAccess(credentials CredentialReader, credentialID int, userID portainer.UserID, memberships []portainer.TeamMembership, isAdmin bool) error {
+	if credentialID == 0 {
+		return nil
+	}
+
+	credential, err := credentials.Read(portainer.GitCredentialID(credentialID))
+	if err != nil || !CanUseCredential(credential, userID, memberships, isAdmin) {
+		return ErrGitCredentialAccessDenied
+	}
+
+	return nil
+}
+
+// ValidateContentCredentialAccess makes sure that the user can use the git credential of the submodules, see ValidateCredentialAccess
+func ValidateContentCredentialAccess(credentials CredentialReader, content *gittypes.ContentOptions, userID portainer.UserID, memberships []portainer.TeamMembership, isAdmin bool) error {
+	if content == nil || content.SubmoduleAuthentication == nil {
+		return nil
+	}
+
+	return ValidateCredentialAccess(credentials, content.SubmoduleAuthentication.GitCredentialID, userID, memberships, isAdmin)
+}
@@ -0,0 +1,83 @@
+package git
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	gittypes "github.com/portainer/portainer/api/git/types"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCredentialReader map[portainer.GitCredentialID]portainer.GitCredential
+
+func (reader testCredentialReader) Read(ID portainer.GitCredentialID) (*portainer.GitCredential, error) {
+	credential, ok := reader[ID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return &credential, nil
+}
+
+func Test_ValidateCredentialAccess(t *testing.T) {
+	is := assert.New(t)
+
+	credentials := testCredentialReader{3: {ID: 3, UserID: 2, TeamIDs: []portainer.TeamID{7}}}
+	memberships := []portainer.TeamMembership{{UserID: 4, TeamID: 7}, {UserID: 5, TeamID: 8}}
+
+	is.NoError(ValidateCredentialAccess(credentials, 0, 1, nil, false))
+	is.NoError(ValidateCredentialAccess(credentials, 3, 2, nil, false), "the owner can use the credential")
+	is.NoError(ValidateCredentialAccess(credentials, 3, 1, nil, true), "the administrators can use the credential")
+	is.NoError(ValidateCredentialAccess(credentials, 3, 4, memberships, false), "the members of the teams can use the credential")
+	is.ErrorIs(ValidateCredentialAccess(credentials, 3, 5, memberships, false), ErrGitCredentialAccessDenied)
+	is.ErrorIs(ValidateCredentialAccess(credentials, 4, 2, nil, true), ErrGitCredentialAccessDenied)
+
+	content := &gittypes.ContentOptions{Submodules: true, SubmoduleAuthentication: &gittypes.GitAuthentication{GitCredentialID: 3}}
+	is.NoError(ValidateContentCredentialAccess(credentials, nil, 5, nil, false))
+	is.NoError(ValidateContentCredentialAccess(credentials, content, 4, memberships, false))
+	is.ErrorIs(ValidateContentCredentialAccess(credentials, content, 5, memberships, false), ErrGitCredentialAccessDenied)
+}
+
+func Test_newBaseOption_Credential(t *testing.T) {
+	is := assert.New(t)
+
+	privateKey, err := GenerateSSHKey()
+	is.NoError(err)
+
+	credentials := testCredentialReader{
+		1: {ID: 1, Type: portainer.GitCredentialTypeBasic, Username: "user", Password: "password", RotationDate: 10},
+		2: {ID: 2, Type: portainer.GitCredentialTypeSSH, PrivateKey: privateKey, KnownHosts: testKnownHosts},
+	}
+	service := &Service{credentials: credentials}
+
+	options, err := service.newBaseOption("https://github.com/portainer/portainer.git", &gittypes.GitAuthentication{Username: "ignored", Password: "ignored", GitCredentialID: 1}, false)
+	is.NoError(err)
+	is.Equal("user", options.username)
+	is.Equal("password", options.password)
+	is.Nil(options.sshKey)
+
+	key := options.authCacheKey()
+
+	credential := credentials[1]
+	credential.Password = "rotated"
+	credential.RotationDate = 20
+	credentials[1] = credential
+
+	options, err = service.newBaseOption("https://github.com/portainer/portainer.git", &gittypes.GitAuthentication{GitCredentialID: 1}, false)
+	is.NoError(err)
+	is.Equal("rotated", options.password)
+	is.NotEqual(key, options.authCacheKey(), "the cached references are not reused once the credential is rotated")
+
+	options, err = service.newBaseOption("git@github.com:portainer/portainer.git", &gittypes.GitAuthentication{GitCredentialID: 2}, false)
+	is.NoError(err)
+	if is.NotNil(options.sshKey) {
+		is.Equal(privateKey, options.sshKey.PrivateKey)
+		is.Equal(testKnownHosts, options.sshKey.KnownHosts)
+	}
+	is.Empty(options.password)
+
+	_, err = service.newBaseOption("https://github.com/portainer/portainer.git", &gittypes.GitAuthentication{GitCredentialID: 3}, false)
+	is.Error(err)
+}
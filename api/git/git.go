@@ -2,6 +2,7 @@ package git
 
 import (
 	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/pkg/errors"
@@ -27,42 +29,66 @@ func NewGitClient(preserveGitDir bool) *gitClient {
 	}
 }
 
-func (c *gitClient) download(ctx context.Context, dst string, opt cloneOption) error {
+// download clones the repository in dst and returns the identifier of the cloned commit
+func (c *gitClient) download(ctx context.Context, dst string, opt cloneOption) (string, error) {
+	auth, err := getAuth(opt.baseOption)
+	if err != nil {
+		return "", err
+	}
+
 	gitOptions := git.CloneOptions{
 		URL:             opt.repositoryUrl,
 		Depth:           opt.depth,
 		InsecureSkipTLS: opt.tlsSkipVerify,
-		Auth:            getAuth(opt.username, opt.password),
+		Auth:            auth,
 	}
 
 	if opt.referenceName != "" {
 		gitOptions.ReferenceName = plumbing.ReferenceName(opt.referenceName)
 	}
 
-	_, err := git.PlainCloneContext(ctx, dst, false, &gitOptions)
+	repo, err := git.PlainCloneContext(ctx, dst, false, &gitOptions)
 
 	if err != nil {
 		if err.Error() == "authentication required" {
-			return gittypes.ErrAuthenticationFailure
+			return "", gittypes.ErrAuthenticationFailure
 		}
-		return errors.Wrap(err, "failed to clone git repository")
+		return "", errors.Wrap(err, "failed to clone git repository")
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to resolve the cloned commit")
+	}
+
+	if err := resolveRepositoryContent(ctx, repo, dst, opt.fetchOption, 0); err != nil {
+		return "", err
 	}
 
 	if !c.preserveGitDirectory {
-		os.RemoveAll(filepath.Join(dst, ".git"))
+		if opt.content.submodules {
+			removeGitMetadata(dst)
+		} else {
+			os.RemoveAll(filepath.Join(dst, ".git"))
+		}
 	}
 
-	return nil
+	return head.Hash().String(), nil
 }
 
 func (c *gitClient) latestCommitID(ctx context.Context, opt fetchOption) (string, error) {
+	auth, err := getAuth(opt.baseOption)
+	if err != nil {
+		return "", err
+	}
+
 	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
 		Name: "origin",
 		URLs: []string{opt.repositoryUrl},
 	})
 
 	listOptions := &git.ListOptions{
-		Auth:            getAuth(opt.username, opt.password),
+		Auth:            auth,
 		InsecureSkipTLS: opt.tlsSkipVerify,
 	}
 
@@ -92,28 +118,40 @@ func (c *gitClient) latestCommitID(ctx context.Context, opt fetchOption) (string
 	return "", errors.Errorf("could not find ref %q in the repository", opt.referenceName)
 }
 
-func getAuth(username, password string) *githttp.BasicAuth {
-	if password != "" {
+// getAuth returns the authentication method of a repository, the SSH key takes precedence over the credentials
+func getAuth(opt baseOption) (transport.AuthMethod, error) {
+	if opt.sshKey != nil {
+		return newSSHAuth(opt.repositoryUrl, opt.sshKey)
+	}
+
+	if opt.password != "" {
+		username := opt.username
 		if username == "" {
 			username = "token"
 		}
 
 		return &githttp.BasicAuth{
 			Username: username,
-			Password: password,
-		}
+			Password: opt.password,
+		}, nil
 	}
-	return nil
+
+	return nil, nil
 }
 
 func (c *gitClient) listRefs(ctx context.Context, opt baseOption) ([]string, error) {
+	auth, err := getAuth(opt)
+	if err != nil {
+		return nil, err
+	}
+
 	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
 		Name: "origin",
 		URLs: []string{opt.repositoryUrl},
 	})
 
 	listOptions := &git.ListOptions{
-		Auth:            getAuth(opt.username, opt.password),
+		Auth:            auth,
 		InsecureSkipTLS: opt.tlsSkipVerify,
 	}
 
@@ -135,13 +173,18 @@ func (c *gitClient) listRefs(ctx context.Context, opt baseOption) ([]string, err
 
 // listFiles list all filenames under the specific repository
 func (c *gitClient) listFiles(ctx context.Context, opt fetchOption) ([]string, error) {
+	auth, err := getAuth(opt.baseOption)
+	if err != nil {
+		return nil, err
+	}
+
 	cloneOption := &git.CloneOptions{
 		URL:             opt.repositoryUrl,
 		NoCheckout:      true,
 		Depth:           1,
 		SingleBranch:    true,
 		ReferenceName:   plumbing.ReferenceName(opt.referenceName),
-		Auth:            getAuth(opt.username, opt.password),
+		Auth:            auth,
 		InsecureSkipTLS: opt.tlsSkipVerify,
 	}
 
@@ -160,19 +203,55 @@ func (c *gitClient) listFiles(ctx context.Context, opt fetchOption) ([]string, e
 		return nil, err
 	}
 
+	return listCommitFiles(ctx, commit, opt, 0)
+}
+
+// listCommitFiles lists the files of a commit, the files of the submodules are listed when the submodules are fetched
+func listCommitFiles(ctx context.Context, commit *object.Commit, opt fetchOption, depth int) ([]string, error) {
 	tree, err := commit.Tree()
 	if err != nil {
 		return nil, err
 	}
 
+	var submodules map[string]*config.Submodule
+	if opt.content.submodules {
+		submodules, err = readGitModules(commit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var allPaths []string
 	w := object.NewTreeWalker(tree, true, nil)
+	defer w.Close()
+
 	for {
 		name, entry, err := w.Next()
 		if err != nil {
 			break
 		}
 
+		if submodule, ok := submodules[name]; ok && entry.Mode == filemode.Submodule {
+			submoduleURL := resolveSubmoduleURL(opt.repositoryUrl, submodule.URL)
+
+			submoduleOpt, err := opt.submoduleOption(submoduleURL, "")
+			if err != nil {
+				return nil, err
+			}
+
+			paths, err := listSubmoduleFiles(ctx, submoduleOpt, name, entry.Hash.String(), depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			if opt.dirOnly {
+				allPaths = append(allPaths, name)
+			}
+			allPaths = append(allPaths, paths...)
+
+			continue
+		}
+
 		isDir := entry.Mode == filemode.Dir
 		if opt.dirOnly == isDir {
 			allPaths = append(allPaths, name)
@@ -182,6 +261,63 @@ func (c *gitClient) listFiles(ctx context.Context, opt fetchOption) ([]string, e
 	return allPaths, nil
 }
 
+// commitSignature fetches the latest commit of the reference and returns its signature with the signed content
+func (c *gitClient) commitSignature(ctx context.Context, opt fetchOption) (*gittypes.CommitSignature, error) {
+	auth, err := getAuth(opt.baseOption)
+	if err != nil {
+		return nil, err
+	}
+
+	cloneOption := &git.CloneOptions{
+		URL:             opt.repositoryUrl,
+		NoCheckout:      true,
+		Depth:           1,
+		SingleBranch:    true,
+		Auth:            auth,
+		InsecureSkipTLS: opt.tlsSkipVerify,
+	}
+
+	if opt.referenceName != "" {
+		cloneOption.ReferenceName = plumbing.ReferenceName(opt.referenceName)
+	}
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, cloneOption)
+	if err != nil {
+		return nil, checkGitError(err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return nil, errors.Wrap(err, "failed to encode the commit")
+	}
+
+	reader, err := encoded.Reader()
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the encoded commit")
+	}
+
+	return &gittypes.CommitSignature{
+		CommitID:  commit.Hash.String(),
+		Signature: commit.PGPSignature,
+		Payload:   string(payload),
+	}, nil
+}
+
 func checkGitError(err error) error {
 	errMsg := err.Error()
 	if errMsg == "repository not found" {
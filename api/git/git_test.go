@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/portainer/portainer/api/archive"
@@ -39,7 +40,7 @@ func Test_ClonePublicRepository_Shallow(t *testing.T) {
 
 	dir := t.TempDir()
 	t.Logf("Cloning into %s", dir)
-	err := service.CloneRepository(dir, repositoryURL, referenceName, "", "", false)
+	err := service.CloneRepository(dir, repositoryURL, referenceName, nil, false, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, getCommitHistoryLength(t, err, dir), "cloned repo has incorrect depth")
 }
@@ -51,7 +52,7 @@ func Test_ClonePublicRepository_NoGitDirectory(t *testing.T) {
 
 	dir := t.TempDir()
 	t.Logf("Cloning into %s", dir)
-	err := service.CloneRepository(dir, repositoryURL, referenceName, "", "", false)
+	err := service.CloneRepository(dir, repositoryURL, referenceName, nil, false, nil)
 	assert.NoError(t, err)
 	assert.NoDirExists(t, filepath.Join(dir, ".git"))
 }
@@ -65,7 +66,7 @@ func Test_cloneRepository(t *testing.T) {
 	dir := t.TempDir()
 	t.Logf("Cloning into %s", dir)
 
-	err := service.cloneRepository(dir, cloneOption{
+	commitID, err := service.cloneRepository(dir, cloneOption{
 		fetchOption: fetchOption{
 			baseOption: baseOption{
 				repositoryUrl: repositoryURL,
@@ -76,6 +77,10 @@ func Test_cloneRepository(t *testing.T) {
 	})
 
 	assert.NoError(t, err)
+
+	latestCommitID, err := service.LatestCommitID(repositoryURL, referenceName, nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, latestCommitID, commitID, "the cloned commit is returned")
 	assert.Equal(t, 4, getCommitHistoryLength(t, err, dir), "cloned repo has incorrect depth")
 }
 
@@ -85,12 +90,27 @@ func Test_latestCommitID(t *testing.T) {
 	repositoryURL := setup(t)
 	referenceName := "refs/heads/main"
 
-	id, err := service.LatestCommitID(repositoryURL, referenceName, "", "", false)
+	id, err := service.LatestCommitID(repositoryURL, referenceName, nil, false)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "68dcaa7bd452494043c64252ab90db0f98ecf8d2", id)
 }
 
+func Test_commitSignature(t *testing.T) {
+	client := NewGitClient(false)
+	repositoryURL := setup(t)
+
+	signature, err := client.commitSignature(context.TODO(), fetchOption{
+		baseOption:    baseOption{repositoryUrl: repositoryURL},
+		referenceName: "refs/heads/main",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "68dcaa7bd452494043c64252ab90db0f98ecf8d2", signature.CommitID)
+	assert.Empty(t, signature.Signature, "the commits of the test repository are not signed")
+	assert.True(t, strings.HasPrefix(signature.Payload, "tree "), signature.Payload)
+}
+
 func getCommitHistoryLength(t *testing.T, err error, dir string) int {
 	repo, err := git.PlainOpen(dir)
 	if err != nil {
@@ -0,0 +1,576 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/pkg/errors"
+	"github.com/portainer/portainer/api/crypto"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+	// lfsPointerMaxSize is the size above which a file is never considered as a pointer, like git-lfs does
+	lfsPointerMaxSize = 1024
+	lfsMediaType      = "application/vnd.git-lfs+json"
+	lfsBatchSize      = 100
+	lfsConfigFile     = ".lfsconfig"
+	// lfsObjectMaxSize is the maximum size of an LFS object
+	lfsObjectMaxSize = 512 << 20
+	// lfsCloneMaxSize is the maximum size of all the LFS objects of a clone, including the objects of its submodules
+	lfsCloneMaxSize = 2 << 30
+)
+
+// lfsPointer is a file of the repository which references an LFS object
+type lfsPointer struct {
+	path string
+	oid  string
+	size int64
+}
+
+// lfsEndpoint is the URL of the LFS API of a repository with the headers authenticating the requests
+type lfsEndpoint struct {
+	url    string
+	header map[string]string
+	// basicAuth authenticates the requests when the endpoint does not provide an authorization header
+	basicAuth *githttp.BasicAuth
+}
+
+// lfsQuota is the size of the LFS objects which can still be downloaded for a clone, it is shared with the submodules
+type lfsQuota struct {
+	remaining int64
+}
+
+func newLFSQuota() *lfsQuota {
+	return &lfsQuota{remaining: lfsCloneMaxSize}
+}
+
+// reserve refuses the LFS objects which exceed the maximum sizes before any of them is downloaded
+func (quota *lfsQuota) reserve(pointers []lfsPointer) error {
+	var size int64
+	for _, pointer := range pointers {
+		if pointer.size > lfsObjectMaxSize {
+			return errors.Errorf("the LFS object of %s exceeds the maximum size of %d bytes", pointer.path, int64(lfsObjectMaxSize))
+		}
+
+		size += pointer.size
+	}
+
+	if size > quota.remaining {
+		return errors.Errorf("the LFS objects of the repository exceed the maximum size of %d bytes", int64(lfsCloneMaxSize))
+	}
+
+	quota.remaining -= size
+
+	return nil
+}
+
+type lfsBatchObject struct {
+	OID     string `json:"oid"`
+	Size    int64  `json:"size"`
+	Actions struct {
+		Download *struct {
+			Href   string            `json:"href"`
+			Header map[string]string `json:"header"`
+		} `json:"download"`
+	} `json:"actions"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// resolveLFSObjects replaces the LFS pointers of a checked out repository by the content of their objects,
+// the directories of the submodules are skipped since they are resolved with the endpoint of their own repository
+func resolveLFSObjects(ctx context.Context, dir string, opt baseOption, skipDirs []string, quota *lfsQuota) error {
+	pointers, err := findLFSPointers(dir, skipDirs)
+	if err != nil {
+		return errors.Wrap(err, "failed to find the LFS pointers")
+	}
+
+	if len(pointers) == 0 {
+		return nil
+	}
+
+	if quota == nil {
+		quota = newLFSQuota()
+	}
+
+	if err := quota.reserve(pointers); err != nil {
+		return err
+	}
+
+	endpoint, err := newLFSEndpoint(ctx, dir, opt)
+	if err != nil {
+		return errors.WithMessage(err, "failed to resolve the LFS endpoint")
+	}
+
+	client := newLFSHTTPClient(opt.tlsSkipVerify)
+	defer client.CloseIdleConnections()
+
+	externalClient := newExternalLFSHTTPClient(opt.tlsSkipVerify)
+	defer externalClient.CloseIdleConnections()
+
+	for start := 0; start < len(pointers); start += lfsBatchSize {
+		end := min(start+lfsBatchSize, len(pointers))
+
+		if err := downloadLFSObjects(ctx, client, externalClient, endpoint, pointers[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func findLFSPointers(dir string, skipDirs []string) ([]lfsPointer, error) {
+	skipped := map[string]bool{".git": true}
+	for _, skipDir := range skipDirs {
+		skipped[filepath.Clean(skipDir)] = true
+	}
+
+	var pointers []lfsPointer
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if skipped[relativePath] {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.Size() > lfsPointerMaxSize {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if pointer, ok := parseLFSPointer(content); ok {
+			pointer.path = path
+			pointers = append(pointers, pointer)
+		}
+
+		return nil
+	})
+
+	return pointers, err
+}
+
+// parseLFSPointer parses the content of a pointer file, following https://github.com/git-lfs/git-lfs/blob/main/docs/spec.md
+func parseLFSPointer(content []byte) (lfsPointer, bool) {
+	var pointer lfsPointer
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	if !scanner.Scan() || scanner.Text() != lfsPointerVersion {
+		return pointer, false
+	}
+
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), " ")
+
+		switch key {
+		case "oid":
+			oid, found := strings.CutPrefix(value, "sha256:")
+			if !found || len(oid) != sha256.Size*2 {
+				return pointer, false
+			}
+			pointer.oid = oid
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return pointer, false
+			}
+			pointer.size = size
+		}
+	}
+
+	return pointer, pointer.oid != ""
+}
+
+// newLFSEndpoint resolves the LFS API of the repository, from the lfs.url option of the .lfsconfig file or from the repository URL.
+// The repositories accessed with an SSH key are authenticated with git-lfs-authenticate.
+func newLFSEndpoint(ctx context.Context, dir string, opt baseOption) (*lfsEndpoint, error) {
+	endpoint := &lfsEndpoint{}
+
+	auth, err := getAuth(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	if basicAuth, ok := auth.(*githttp.BasicAuth); ok {
+		endpoint.basicAuth = basicAuth
+	}
+
+	if lfsURL, err := readLFSConfigURL(dir); err != nil {
+		return nil, err
+	} else if lfsURL != "" {
+		// the repository cannot make Portainer send requests to another server
+		if !sameHost(lfsURL, opt.repositoryUrl) {
+			return nil, errors.Errorf("the LFS URL %s of the .lfsconfig file is not on the repository host", lfsURL)
+		}
+
+		endpoint.url = lfsURL
+		return endpoint, nil
+	}
+
+	remote, err := transport.NewEndpoint(opt.repositoryUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid repository URL")
+	}
+
+	switch remote.Protocol {
+	case "http", "https":
+		if endpoint.basicAuth == nil && remote.User != "" {
+			endpoint.basicAuth = &githttp.BasicAuth{Username: remote.User, Password: remote.Password}
+		}
+		remote.User, remote.Password = "", ""
+	case "ssh":
+		if opt.sshKey != nil {
+			return sshLFSEndpoint(ctx, remote, opt)
+		}
+
+		remote.Protocol, remote.User, remote.Port = "https", "", 0
+	default:
+		return nil, errors.Errorf("LFS objects are not supported for the %s protocol", remote.Protocol)
+	}
+
+	remote.Path = strings.TrimSuffix(remote.Path, "/")
+	if !strings.HasSuffix(remote.Path, ".git") {
+		remote.Path += ".git"
+	}
+
+	endpoint.url = strings.TrimSuffix(remote.String(), "/") + "/info/lfs"
+
+	return endpoint, nil
+}
+
+func readLFSConfigURL(dir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, lfsConfigFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	cfg := config.New()
+	if err := config.NewDecoder(bytes.NewReader(content)).Decode(cfg); err != nil {
+		return "", errors.Wrap(err, "invalid .lfsconfig file")
+	}
+
+	return cfg.Section("lfs").Option("url"), nil
+}
+
+// sshLFSEndpoint runs git-lfs-authenticate on the git server to get the URL of the LFS API with a temporary authorization
+func sshLFSEndpoint(ctx context.Context, remote *transport.Endpoint, opt baseOption) (*lfsEndpoint, error) {
+	auth, err := newSSHAuth(opt.repositoryUrl, opt.sshKey)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeys, ok := auth.(*gitssh.PublicKeys)
+	if !ok {
+		return nil, errors.New("unexpected SSH authentication")
+	}
+
+	clientConfig, err := publicKeys.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientConfig.Timeout = 30 * time.Second
+
+	port := remote.Port
+	if port == 0 {
+		port = 22
+	}
+
+	client, err := ssh.Dial("tcp", net.JoinHostPort(remote.Host, strconv.Itoa(port)), clientConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the git server")
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	output, err := session.Output(fmt.Sprintf("git-lfs-authenticate '%s' download", strings.ReplaceAll(strings.TrimPrefix(remote.Path, "/"), "'", "")))
+	if err != nil {
+		return nil, errors.Wrap(err, "git-lfs-authenticate failed")
+	}
+
+	var response struct {
+		Href   string            `json:"href"`
+		Header map[string]string `json:"header"`
+	}
+
+	if err := json.Unmarshal(output, &response); err != nil || response.Href == "" {
+		return nil, errors.New("invalid git-lfs-authenticate response")
+	}
+
+	return &lfsEndpoint{url: response.Href, header: response.Header}, nil
+}
+
+func downloadLFSObjects(ctx context.Context, client, externalClient *http.Client, endpoint *lfsEndpoint, pointers []lfsPointer) error {
+	objects := make([]map[string]any, 0, len(pointers))
+	for _, pointer := range pointers {
+		objects = append(objects, map[string]any{"oid": pointer.oid, "size": pointer.size})
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"operation": "download",
+		"transfers": []string{"basic"},
+		"objects":   objects,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint.url, "/")+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create the LFS batch request")
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	endpoint.authenticate(req, endpoint.header)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to request the LFS objects")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to request the LFS objects with a status %q", resp.Status)
+	}
+
+	var batch struct {
+		Objects []lfsBatchObject `json:"objects"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return errors.Wrap(err, "invalid LFS batch response")
+	}
+
+	objectsByOID := make(map[string]lfsBatchObject, len(batch.Objects))
+	for _, object := range batch.Objects {
+		objectsByOID[object.OID] = object
+	}
+
+	for _, pointer := range pointers {
+		object, ok := objectsByOID[pointer.oid]
+		if !ok {
+			return errors.Errorf("the LFS object %s was not returned by the server", pointer.oid)
+		} else if object.Error != nil {
+			return errors.Errorf("failed to download the LFS object %s: %s", pointer.oid, object.Error.Message)
+		} else if object.Actions.Download == nil {
+			return errors.Errorf("the LFS object %s cannot be downloaded", pointer.oid)
+		}
+
+		err := downloadLFSObject(ctx, client, externalClient, endpoint, pointer, object.Actions.Download.Href, object.Actions.Download.Header)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to download the LFS object of %s", pointer.path)
+		}
+	}
+
+	return nil
+}
+
+// downloadLFSObject replaces the pointer file by the content of the object, once its hash is verified.
+// The objects stored outside of the LFS host, like in the storage services of the git providers, are downloaded
+// without the credentials of the repository and with the external client, which only connects to public addresses.
+func downloadLFSObject(ctx context.Context, client, externalClient *http.Client, endpoint *lfsEndpoint, pointer lfsPointer, href string, header map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, href, nil)
+	if err != nil {
+		return err
+	}
+
+	if sameHost(href, endpoint.url) {
+		endpoint.authenticate(req, header)
+	} else {
+		if err := checkExternalLFSURL(req.URL); err != nil {
+			return err
+		}
+
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+
+		client = externalClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status %q", resp.Status)
+	}
+
+	info, err := os.Stat(pointer.path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(pointer.path), ".lfs-object-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(resp.Body, pointer.size+1))
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	if size != pointer.size || hex.EncodeToString(hash.Sum(nil)) != pointer.oid {
+		return errors.New("the content does not match the LFS pointer")
+	}
+
+	if err := os.Chmod(file.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), pointer.path)
+}
+
+// authenticate sets the headers of the endpoint on the request, the basic authentication is used when they do not authorize the request
+func (endpoint *lfsEndpoint) authenticate(req *http.Request, header map[string]string) {
+	for key, value := range endpoint.header {
+		req.Header.Set(key, value)
+	}
+
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	if req.Header.Get("Authorization") == "" && endpoint.basicAuth != nil {
+		endpoint.basicAuth.SetAuth(req)
+	}
+}
+
+func sameHost(rawURL, otherURL string) bool {
+	endpoint, err := transport.NewEndpoint(rawURL)
+	if err != nil {
+		return false
+	}
+
+	other, err := transport.NewEndpoint(otherURL)
+	if err != nil {
+		return false
+	}
+
+	return endpoint.Protocol != "file" && strings.EqualFold(endpoint.Host, other.Host)
+}
+
+// checkExternalLFSURL makes sure that an LFS object stored outside of the LFS host is downloaded over HTTPS
+func checkExternalLFSURL(u *url.URL) error {
+	if u.Scheme != "https" {
+		return errors.Errorf("the LFS object URL %s is not on the LFS host and does not use HTTPS", u.Redacted())
+	}
+
+	return nil
+}
+
+// checkExternalLFSAddress refuses the connections to the non public addresses, it is checked on the address which is dialled
+// so that a repository cannot make Portainer send requests to the internal network, even with a host resolving to several addresses
+func checkExternalLFSAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return errors.Errorf("invalid LFS object address %s", address)
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return errors.Errorf("the LFS object host resolves to the non public address %s", ip)
+	}
+
+	return nil
+}
+
+func newLFSHTTPClient(insecureSkipVerify bool) *http.Client {
+	tlsConfig := crypto.CreateTLSConfiguration()
+	tlsConfig.InsecureSkipVerify = insecureSkipVerify
+
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		Timeout: 300 * time.Second,
+	}
+}
+
+// newExternalLFSHTTPClient returns the client of the LFS objects stored outside of the LFS host. It does not use the proxy of
+// the environment since the proxy would be the only address checked.
+func newExternalLFSHTTPClient(insecureSkipVerify bool) *http.Client {
+	tlsConfig := crypto.CreateTLSConfiguration()
+	tlsConfig.InsecureSkipVerify = insecureSkipVerify
+
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: checkExternalLFSAddress,
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			DialContext:     dialer.DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+
+			return checkExternalLFSURL(req.URL)
+		},
+		Timeout: 300 * time.Second,
+	}
+}
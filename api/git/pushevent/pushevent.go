@@ -0,0 +1,257 @@
+package pushevent
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Provider represents a git provider sending push events
+type Provider string
+
+const (
+	ProviderGitHub Provider = "github"
+	ProviderGitLab Provider = "gitlab"
+	ProviderGitea  Provider = "gitea"
+)
+
+const (
+	// gitHubMaxCommits is the maximum number of commits listed in a GitHub push event
+	gitHubMaxCommits = 2048
+	zeroCommitID     = "0000000000000000000000000000000000000000"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrUnsupportedEvent = errors.New("unsupported webhook event")
+)
+
+// PushEvent represents the push of commits to a reference of a git repository
+type PushEvent struct {
+	Provider Provider
+	// Full name of the pushed reference, like refs/heads/main
+	Ref string
+	// Deleted is true when the reference was deleted by the push
+	Deleted bool
+	// Default branch of the repository, empty when the provider does not send it
+	DefaultBranch string
+	// Paths added, modified or removed by the pushed commits
+	ChangedPaths []string
+	// Complete is false when the provider truncated the list of commits, the changed paths are then unknown
+	Complete bool
+}
+
+type pushPayload struct {
+	Ref     string `json:"ref"`
+	After   string `json:"after"`
+	Deleted bool   `json:"deleted"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+	// GitLab count of pushed commits
+	TotalCommitsCount int `json:"total_commits_count"`
+	// Gitea count of pushed commits
+	TotalCommits int `json:"total_commits"`
+	// GitHub and Gitea repository
+	Repository struct {
+		DefaultBranch string `json:"default_branch"`
+	} `json:"repository"`
+	// GitLab repository
+	Project struct {
+		DefaultBranch string `json:"default_branch"`
+	} `json:"project"`
+}
+
+// Parse parses the push event sent by a git provider. It returns nil when the request was not sent by a supported provider,
+// which is only allowed when no secret is set. ErrUnsupportedEvent is returned for the other events of the providers, like pings.
+func Parse(header http.Header, body []byte, secret string) (*PushEvent, error) {
+	provider, eventType := detectProvider(header)
+	if provider == "" {
+		if secret != "" {
+			return nil, errors.Wrap(ErrInvalidSignature, "the request was not sent by a supported git provider")
+		}
+
+		return nil, nil
+	}
+
+	if secret != "" {
+		if err := verifySignature(provider, header, body, secret); err != nil {
+			return nil, err
+		}
+	}
+
+	if !isPushEvent(provider, eventType) {
+		return nil, errors.Wrapf(ErrUnsupportedEvent, "%s event %q", provider, eventType)
+	}
+
+	payload, err := decodePayload(header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &PushEvent{
+		Provider:      provider,
+		Ref:           payload.Ref,
+		Deleted:       payload.Deleted || payload.After == zeroCommitID,
+		DefaultBranch: payload.Repository.DefaultBranch,
+		Complete:      len(payload.Commits) > 0,
+	}
+
+	switch provider {
+	case ProviderGitHub:
+		event.Complete = event.Complete && len(payload.Commits) < gitHubMaxCommits
+	case ProviderGitLab:
+		event.DefaultBranch = payload.Project.DefaultBranch
+		event.Complete = event.Complete && payload.TotalCommitsCount <= len(payload.Commits)
+	case ProviderGitea:
+		event.Complete = event.Complete && payload.TotalCommits <= len(payload.Commits)
+	}
+
+	for _, commit := range payload.Commits {
+		event.ChangedPaths = append(event.ChangedPaths, commit.Added...)
+		event.ChangedPaths = append(event.ChangedPaths, commit.Modified...)
+		event.ChangedPaths = append(event.ChangedPaths, commit.Removed...)
+	}
+
+	return event, nil
+}
+
+// MatchesReference returns true when the push targets the reference, an empty reference name is the default branch of the repository
+func (event *PushEvent) MatchesReference(referenceName string) bool {
+	if referenceName == "" {
+		return event.DefaultBranch == "" || event.Ref == "refs/heads/"+event.DefaultBranch
+	}
+
+	if !strings.HasPrefix(referenceName, "refs/") {
+		referenceName = "refs/heads/" + referenceName
+	}
+
+	return event.Ref == referenceName
+}
+
+// Touches returns true when the push changed one of the files, or one of the directories or a file inside them.
+// A directory only changes itself when it is a submodule whose commit was moved.
+// The paths are relative to the root of the repository
+func (event *PushEvent) Touches(files []string, dirs []string) bool {
+	if !event.Complete {
+		return true
+	}
+
+	for _, changedPath := range event.ChangedPaths {
+		changedPath = cleanPath(changedPath)
+
+		for _, file := range files {
+			if changedPath == cleanPath(file) {
+				return true
+			}
+		}
+
+		for _, dir := range dirs {
+			dir = cleanPath(dir)
+			if dir == "." || changedPath == dir || strings.HasPrefix(changedPath, dir+"/") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func cleanPath(p string) string {
+	return path.Clean(strings.TrimPrefix(p, "/"))
+}
+
+// detectProvider returns the provider and the event type of the request, Gitea is looked up first since it also sends the GitHub headers
+func detectProvider(header http.Header) (Provider, string) {
+	if event := header.Get("X-Gitea-Event"); event != "" {
+		return ProviderGitea, event
+	}
+
+	if event := header.Get("X-Gitlab-Event"); event != "" {
+		return ProviderGitLab, event
+	}
+
+	if event := header.Get("X-GitHub-Event"); event != "" {
+		return ProviderGitHub, event
+	}
+
+	return "", ""
+}
+
+func isPushEvent(provider Provider, eventType string) bool {
+	if provider == ProviderGitLab {
+		return eventType == "Push Hook" || eventType == "Tag Push Hook"
+	}
+
+	return eventType == "push"
+}
+
+func verifySignature(provider Provider, header http.Header, body []byte, secret string) error {
+	switch provider {
+	case ProviderGitLab:
+		token := header.Get("X-Gitlab-Token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return errors.Wrap(ErrInvalidSignature, "the GitLab secret token does not match")
+		}
+
+		return nil
+	case ProviderGitea:
+		return verifyHMAC(header.Get("X-Gitea-Signature"), body, secret)
+	}
+
+	signature, found := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	if !found {
+		return errors.Wrap(ErrInvalidSignature, "the X-Hub-Signature-256 header is missing")
+	}
+
+	return verifyHMAC(signature, body, secret)
+}
+
+func verifyHMAC(signature string, body []byte, secret string) error {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(expected) == 0 {
+		return errors.Wrap(ErrInvalidSignature, "the signature is not a valid hexadecimal HMAC")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// decodePayload decodes the JSON payload, GitHub can also send it as the payload field of a form
+func decodePayload(header http.Header, body []byte) (*pushPayload, error) {
+	if strings.HasPrefix(header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid form payload")
+		}
+
+		body = []byte(values.Get("payload"))
+	}
+
+	var payload pushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.Wrap(err, "invalid push event payload")
+	}
+
+	if payload.Ref == "" {
+		return nil, errors.New("invalid push event payload, the ref is missing")
+	}
+
+	return &payload, nil
+}
@@ -0,0 +1,175 @@
+package pushevent
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPayload = `{
+	"ref": "refs/heads/main",
+	"after": "68dcaa7bd452494043c64252ab90db0f98ecf8d2",
+	"total_commits_count": 2,
+	"total_commits": 2,
+	"commits": [
+		{"added": ["README.md"], "modified": [], "removed": []},
+		{"added": [], "modified": ["deploy/docker-compose.yml"], "removed": ["old.env"]}
+	],
+	"repository": {"default_branch": "main"},
+	"project": {"default_branch": "main"}
+}`
+
+func sign(body, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func Test_Parse_Providers(t *testing.T) {
+	is := assert.New(t)
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		provider Provider
+	}{
+		{
+			name:     "github",
+			header:   http.Header{"X-Github-Event": {"push"}, "X-Hub-Signature-256": {"sha256=" + sign(testPayload, "secret")}},
+			provider: ProviderGitHub,
+		},
+		{
+			name:     "gitlab",
+			header:   http.Header{"X-Gitlab-Event": {"Push Hook"}, "X-Gitlab-Token": {"secret"}},
+			provider: ProviderGitLab,
+		},
+		{
+			name:     "gitea also sending the github headers",
+			header:   http.Header{"X-Gitea-Event": {"push"}, "X-Github-Event": {"push"}, "X-Gitea-Signature": {sign(testPayload, "secret")}},
+			provider: ProviderGitea,
+		},
+	}
+
+	for _, tt := range tests {
+		event, err := Parse(tt.header, []byte(testPayload), "secret")
+		is.NoError(err, tt.name)
+		if !is.NotNil(event, tt.name) {
+			continue
+		}
+
+		is.Equal(tt.provider, event.Provider, tt.name)
+		is.Equal("refs/heads/main", event.Ref, tt.name)
+		is.Equal("main", event.DefaultBranch, tt.name)
+		is.False(event.Deleted, tt.name)
+		is.True(event.Complete, tt.name)
+		is.ElementsMatch([]string{"README.md", "deploy/docker-compose.yml", "old.env"}, event.ChangedPaths, tt.name)
+
+		_, err = Parse(tt.header, []byte(testPayload), "other-secret")
+		is.ErrorIs(err, ErrInvalidSignature, tt.name)
+	}
+}
+
+func Test_Parse_Signature(t *testing.T) {
+	is := assert.New(t)
+
+	event, err := Parse(http.Header{}, []byte("{}"), "")
+	is.NoError(err)
+	is.Nil(event, "the requests of other clients are accepted without a secret")
+
+	_, err = Parse(http.Header{}, []byte("{}"), "secret")
+	is.ErrorIs(err, ErrInvalidSignature, "the requests of other clients are refused with a secret")
+
+	_, err = Parse(http.Header{"X-Github-Event": {"push"}}, []byte(testPayload), "secret")
+	is.ErrorIs(err, ErrInvalidSignature)
+
+	_, err = Parse(http.Header{"X-Github-Event": {"push"}, "X-Hub-Signature-256": {"sha256=zz"}}, []byte(testPayload), "secret")
+	is.ErrorIs(err, ErrInvalidSignature)
+
+	_, err = Parse(http.Header{"X-Github-Event": {"ping"}, "X-Hub-Signature-256": {"sha256=" + sign("{}", "secret")}}, []byte("{}"), "secret")
+	is.ErrorIs(err, ErrUnsupportedEvent)
+
+	_, err = Parse(http.Header{"X-Github-Event": {"ping"}}, []byte("{}"), "secret")
+	is.ErrorIs(err, ErrInvalidSignature, "the signature is verified before the event type")
+
+	body := url.Values{"payload": {testPayload}}.Encode()
+	event, err = Parse(http.Header{
+		"X-Github-Event":      {"push"},
+		"Content-Type":        {"application/x-www-form-urlencoded"},
+		"X-Hub-Signature-256": {"sha256=" + sign(body, "secret")},
+	}, []byte(body), "secret")
+	is.NoError(err)
+	if is.NotNil(event) {
+		is.Equal("refs/heads/main", event.Ref)
+	}
+
+	_, err = Parse(http.Header{"X-Gitlab-Event": {"Push Hook"}}, []byte(`{"commits": []}`), "")
+	is.Error(err)
+}
+
+func Test_Parse_Incomplete(t *testing.T) {
+	is := assert.New(t)
+
+	event, err := Parse(http.Header{"X-Gitlab-Event": {"Push Hook"}}, []byte(`{
+		"ref": "refs/heads/main",
+		"after": "0000000000000000000000000000000000000000",
+		"total_commits_count": 0,
+		"commits": []
+	}`), "")
+	is.NoError(err)
+	if is.NotNil(event) {
+		is.True(event.Deleted)
+		is.False(event.Complete)
+	}
+
+	event, err = Parse(http.Header{"X-Gitlab-Event": {"Push Hook"}}, []byte(`{
+		"ref": "refs/heads/main",
+		"total_commits_count": 21,
+		"commits": [{"modified": ["README.md"]}]
+	}`), "")
+	is.NoError(err)
+	if is.NotNil(event) {
+		is.False(event.Complete)
+		is.True(event.Touches([]string{"docker-compose.yml"}, nil), "the truncated pushes touch all the files")
+	}
+}
+
+func Test_PushEvent_MatchesReference(t *testing.T) {
+	is := assert.New(t)
+
+	event := &PushEvent{Ref: "refs/heads/main", DefaultBranch: "main"}
+	is.True(event.MatchesReference("refs/heads/main"))
+	is.True(event.MatchesReference("main"))
+	is.True(event.MatchesReference(""))
+	is.False(event.MatchesReference("refs/heads/develop"))
+	is.False(event.MatchesReference("refs/tags/main"))
+
+	event = &PushEvent{Ref: "refs/heads/develop", DefaultBranch: "main"}
+	is.False(event.MatchesReference(""))
+
+	event = &PushEvent{Ref: "refs/heads/develop"}
+	is.True(event.MatchesReference(""), "the push is accepted when the default branch is unknown")
+}
+
+func Test_PushEvent_Touches(t *testing.T) {
+	is := assert.New(t)
+
+	event := &PushEvent{Complete: true, ChangedPaths: []string{"README.md", "deploy/overlays/prod/patch.yaml"}}
+
+	is.False(event.Touches([]string{"docker-compose.yml"}, nil))
+	is.True(event.Touches([]string{"docker-compose.yml", "./README.md"}, nil))
+	is.True(event.Touches([]string{"/README.md"}, nil))
+	is.True(event.Touches(nil, []string{"deploy"}))
+	is.True(event.Touches(nil, []string{""}), "the root directory contains all the files")
+	is.False(event.Touches(nil, []string{"dep"}))
+	is.False(event.Touches(nil, []string{"deploy/overlays/staging"}))
+
+	event = &PushEvent{Complete: true, ChangedPaths: []string{"stacks"}}
+	is.False(event.Touches([]string{"stacks/docker-compose.yml"}, nil))
+	is.True(event.Touches([]string{"stacks/docker-compose.yml"}, []string{"stacks"}), "moving the commit of a submodule touches its files")
+}
@@ -8,6 +8,9 @@ import (
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+	portainer "github.com/portainer/portainer/api"
+	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/rs/zerolog/log"
 )
 
@@ -21,7 +24,10 @@ type baseOption struct {
 	repositoryUrl string
 	username      string
 	password      string
+	sshKey        *portainer.GitSSHKey
 	tlsSkipVerify bool
+	// credential is the shared credential resolved in the authentication, nil when the secret is set on the repository
+	credential *portainer.GitCredential
 }
 
 // fetchOption allows to specify the reference name of the target repository
@@ -29,6 +35,17 @@ type fetchOption struct {
 	baseOption
 	referenceName string
 	dirOnly       bool
+	content       contentOption
+}
+
+// contentOption allows to fetch the submodules and the LFS objects of the repository
+type contentOption struct {
+	submodules bool
+	// submoduleAuth is the authentication of the submodules, the authentication of the repository is reused when nil
+	submoduleAuth *baseOption
+	lfs           bool
+	// lfsQuota limits the size of the LFS objects downloaded by the repository and its submodules, a quota is created for each request
+	lfsQuota *lfsQuota
 }
 
 // cloneOption allows to add a history truncated to the specified number of commits
@@ -38,10 +55,11 @@ type cloneOption struct {
 }
 
 type repoManager interface {
-	download(ctx context.Context, dst string, opt cloneOption) error
+	download(ctx context.Context, dst string, opt cloneOption) (string, error)
 	latestCommitID(ctx context.Context, opt fetchOption) (string, error)
 	listRefs(ctx context.Context, opt baseOption) ([]string, error)
 	listFiles(ctx context.Context, opt fetchOption) ([]string, error)
+	commitSignature(ctx context.Context, opt fetchOption) (*gittypes.CommitSignature, error)
 }
 
 // Service represents a service for managing Git.
@@ -49,6 +67,8 @@ type Service struct {
 	shutdownCtx  context.Context
 	azure        repoManager
 	git          repoManager
+	sshKeys      SSHKeyReader
+	credentials  CredentialReader
 	timerStopped bool
 	mut          sync.Mutex
 
@@ -59,16 +79,19 @@ type Service struct {
 	repoFileCache *lru.Cache
 }
 
-// NewService initializes a new service.
-func NewService(ctx context.Context) *Service {
-	return newService(ctx, repositoryCacheSize, repositoryCacheTTL)
+// NewService initializes a new service, the SSH keys and the shared credentials of the repository authentications
+// are read from sshKeys and credentials.
+func NewService(ctx context.Context, sshKeys SSHKeyReader, credentials CredentialReader) *Service {
+	return newService(ctx, sshKeys, credentials, repositoryCacheSize, repositoryCacheTTL)
 }
 
-func newService(ctx context.Context, cacheSize int, cacheTTL time.Duration) *Service {
+func newService(ctx context.Context, sshKeys SSHKeyReader, credentials CredentialReader, cacheSize int, cacheTTL time.Duration) *Service {
 	service := &Service{
 		shutdownCtx:  ctx,
 		azure:        NewAzureClient(),
 		git:          NewGitClient(false),
+		sshKeys:      sshKeys,
+		credentials:  credentials,
 		timerStopped: false,
 		cacheEnabled: cacheSize > 0,
 	}
@@ -122,16 +145,28 @@ func (service *Service) timerHasStopped() bool {
 
 // CloneRepository clones a git repository using the specified URL in the specified
 // destination folder.
-func (service *Service) CloneRepository(destination, repositoryURL, referenceName, username, password string, tlsSkipVerify bool) error {
+func (service *Service) CloneRepository(destination, repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool, content *gittypes.ContentOptions) error {
+	_, err := service.CloneRepositoryCommit(destination, repositoryURL, referenceName, auth, tlsSkipVerify, content)
+	return err
+}
+
+// CloneRepositoryCommit clones a git repository like CloneRepository and returns the identifier of the cloned commit
+func (service *Service) CloneRepositoryCommit(destination, repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool, content *gittypes.ContentOptions) (string, error) {
+	base, err := service.newBaseOption(repositoryURL, auth, tlsSkipVerify)
+	if err != nil {
+		return "", err
+	}
+
+	contentOpt, err := service.newContentOption(repositoryURL, content, tlsSkipVerify)
+	if err != nil {
+		return "", err
+	}
+
 	options := cloneOption{
 		fetchOption: fetchOption{
-			baseOption: baseOption{
-				repositoryUrl: repositoryURL,
-				username:      username,
-				password:      password,
-				tlsSkipVerify: tlsSkipVerify,
-			},
+			baseOption:    base,
 			referenceName: referenceName,
+			content:       contentOpt,
 		},
 		depth: 1,
 	}
@@ -139,8 +174,8 @@ func (service *Service) CloneRepository(destination, repositoryURL, referenceNam
 	return service.cloneRepository(destination, options)
 }
 
-func (service *Service) cloneRepository(destination string, options cloneOption) error {
-	if isAzureUrl(options.repositoryUrl) {
+func (service *Service) cloneRepository(destination string, options cloneOption) (string, error) {
+	if options.useAzureClient() {
 		return service.azure.download(context.TODO(), destination, options)
 	}
 
@@ -148,18 +183,18 @@ func (service *Service) cloneRepository(destination string, options cloneOption)
 }
 
 // LatestCommitID returns SHA1 of the latest commit of the specified reference
-func (service *Service) LatestCommitID(repositoryURL, referenceName, username, password string, tlsSkipVerify bool) (string, error) {
+func (service *Service) LatestCommitID(repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool) (string, error) {
+	base, err := service.newBaseOption(repositoryURL, auth, tlsSkipVerify)
+	if err != nil {
+		return "", err
+	}
+
 	options := fetchOption{
-		baseOption: baseOption{
-			repositoryUrl: repositoryURL,
-			username:      username,
-			password:      password,
-			tlsSkipVerify: tlsSkipVerify,
-		},
+		baseOption:    base,
 		referenceName: referenceName,
 	}
 
-	if isAzureUrl(options.repositoryUrl) {
+	if options.useAzureClient() {
 		return service.azure.latestCommitID(context.TODO(), options)
 	}
 
@@ -167,8 +202,13 @@ func (service *Service) LatestCommitID(repositoryURL, referenceName, username, p
 }
 
 // ListRefs will list target repository's references without cloning the repository
-func (service *Service) ListRefs(repositoryURL, username, password string, hardRefresh bool, tlsSkipVerify bool) ([]string, error) {
-	refCacheKey := generateCacheKey(repositoryURL, username, password, strconv.FormatBool(tlsSkipVerify))
+func (service *Service) ListRefs(repositoryURL string, auth *gittypes.GitAuthentication, hardRefresh bool, tlsSkipVerify bool) ([]string, error) {
+	options, err := service.newBaseOption(repositoryURL, auth, tlsSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	refCacheKey := generateCacheKey(repositoryURL, options.authCacheKey(), strconv.FormatBool(tlsSkipVerify))
 	if service.cacheEnabled && hardRefresh {
 		// Should remove the cache explicitly, so that the following normal list can show the correct result
 		service.repoRefCache.Remove(refCacheKey)
@@ -194,18 +234,8 @@ func (service *Service) ListRefs(repositoryURL, username, password string, hardR
 		}
 	}
 
-	options := baseOption{
-		repositoryUrl: repositoryURL,
-		username:      username,
-		password:      password,
-		tlsSkipVerify: tlsSkipVerify,
-	}
-
-	var (
-		refs []string
-		err  error
-	)
-	if isAzureUrl(options.repositoryUrl) {
+	var refs []string
+	if options.useAzureClient() {
 		refs, err = service.azure.listRefs(context.TODO(), options)
 		if err != nil {
 			return nil, err
@@ -225,8 +255,19 @@ func (service *Service) ListRefs(repositoryURL, username, password string, hardR
 
 // ListFiles will list all the files of the target repository with specific extensions.
 // If extension is not provided, it will list all the files under the target repository
-func (service *Service) ListFiles(repositoryURL, referenceName, username, password string, dirOnly, hardRefresh bool, includedExts []string, tlsSkipVerify bool) ([]string, error) {
-	repoKey := generateCacheKey(repositoryURL, referenceName, username, password, strconv.FormatBool(tlsSkipVerify), strconv.FormatBool(dirOnly))
+// The files of the submodules are listed under the path of their submodule when the submodules are fetched.
+func (service *Service) ListFiles(repositoryURL, referenceName string, auth *gittypes.GitAuthentication, dirOnly, hardRefresh bool, includedExts []string, tlsSkipVerify bool, content *gittypes.ContentOptions) ([]string, error) {
+	base, err := service.newBaseOption(repositoryURL, auth, tlsSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	contentOpt, err := service.newContentOption(repositoryURL, content, tlsSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	repoKey := generateCacheKey(repositoryURL, referenceName, base.authCacheKey(), strconv.FormatBool(tlsSkipVerify), strconv.FormatBool(dirOnly), contentOpt.cacheKey())
 
 	if service.cacheEnabled && hardRefresh {
 		// Should remove the cache explicitly, so that the following normal list can show the correct result
@@ -248,21 +289,14 @@ func (service *Service) ListFiles(repositoryURL, referenceName, username, passwo
 	}
 
 	options := fetchOption{
-		baseOption: baseOption{
-			repositoryUrl: repositoryURL,
-			username:      username,
-			password:      password,
-			tlsSkipVerify: tlsSkipVerify,
-		},
+		baseOption:    base,
 		referenceName: referenceName,
 		dirOnly:       dirOnly,
+		content:       contentOpt,
 	}
 
-	var (
-		files []string
-		err   error
-	)
-	if isAzureUrl(options.repositoryUrl) {
+	var files []string
+	if options.useAzureClient() {
 		files, err = service.azure.listFiles(context.TODO(), options)
 		if err != nil {
 			return nil, err
@@ -282,6 +316,148 @@ func (service *Service) ListFiles(repositoryURL, referenceName, username, passwo
 	return includedFiles, nil
 }
 
+// CommitSignature returns the signature of the latest commit of the specified reference with the signed content of the commit
+func (service *Service) CommitSignature(repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool) (*gittypes.CommitSignature, error) {
+	base, err := service.newBaseOption(repositoryURL, auth, tlsSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	options := fetchOption{
+		baseOption:    base,
+		referenceName: referenceName,
+	}
+
+	if options.useAzureClient() {
+		return service.azure.commitSignature(context.TODO(), options)
+	}
+
+	return service.git.commitSignature(context.TODO(), options)
+}
+
+// newBaseOption resolves the authentication of a repository, the stored SSH key or the shared credential is read
+// when the authentication references one
+func (service *Service) newBaseOption(repositoryURL string, auth *gittypes.GitAuthentication, tlsSkipVerify bool) (baseOption, error) {
+	options := baseOption{
+		repositoryUrl: repositoryURL,
+		tlsSkipVerify: tlsSkipVerify,
+	}
+
+	if auth == nil {
+		return options, nil
+	}
+
+	if auth.GitCredentialID != 0 {
+		return service.newCredentialOption(options, portainer.GitCredentialID(auth.GitCredentialID))
+	}
+
+	if auth.SSHKeyID == 0 {
+		options.username = auth.Username
+		options.password = auth.Password
+		return options, nil
+	}
+
+	if service.sshKeys == nil {
+		return options, errors.New("SSH key authentication is not supported")
+	}
+
+	sshKey, err := service.sshKeys.Read(portainer.GitSSHKeyID(auth.SSHKeyID))
+	if err != nil {
+		return options, errors.Wrap(err, "unable to read the SSH key of the repository")
+	}
+	options.sshKey = sshKey
+
+	return options, nil
+}
+
+// newCredentialOption sets the secret of a shared credential on the options
+func (service *Service) newCredentialOption(options baseOption, credentialID portainer.GitCredentialID) (baseOption, error) {
+	if service.credentials == nil {
+		return options, errors.New("git credentials are not supported")
+	}
+
+	credential, err := service.credentials.Read(credentialID)
+	if err != nil {
+		return options, errors.Wrap(err, "unable to read the git credential of the repository")
+	}
+	options.credential = credential
+
+	if credential.Type != portainer.GitCredentialTypeSSH {
+		options.username = credential.Username
+		options.password = credential.Password
+		return options, nil
+	}
+
+	options.sshKey = &portainer.GitSSHKey{
+		Name:        credential.Name,
+		PublicKey:   credential.PublicKey,
+		Fingerprint: credential.Fingerprint,
+		PrivateKey:  credential.PrivateKey,
+		Passphrase:  credential.Passphrase,
+		KnownHosts:  credential.KnownHosts,
+	}
+
+	return options, nil
+}
+
+// newContentOption resolves the authentication of the submodules, the stored SSH key is read when the authentication references one
+func (service *Service) newContentOption(repositoryURL string, content *gittypes.ContentOptions, tlsSkipVerify bool) (contentOption, error) {
+	if content == nil {
+		return contentOption{}, nil
+	}
+
+	options := contentOption{
+		submodules: content.Submodules,
+		lfs:        content.LFS,
+	}
+
+	if content.LFS {
+		options.lfsQuota = newLFSQuota()
+	}
+
+	if content.Submodules && content.SubmoduleAuthentication != nil {
+		submoduleAuth, err := service.newBaseOption(repositoryURL, content.SubmoduleAuthentication, tlsSkipVerify)
+		if err != nil {
+			return options, errors.WithMessage(err, "invalid submodule authentication")
+		}
+		options.submoduleAuth = &submoduleAuth
+	}
+
+	return options, nil
+}
+
+// cacheKey identifies the content options in the cache keys, the LFS objects do not change the listed files
+func (opt contentOption) cacheKey() string {
+	if !opt.submodules {
+		return ""
+	}
+
+	if opt.submoduleAuth != nil {
+		return "submodules-" + opt.submoduleAuth.authCacheKey()
+	}
+
+	return "submodules"
+}
+
+// useAzureClient returns true when the repository is hosted on Azure DevOps and is accessed over HTTPS,
+// the repositories accessed with an SSH key are cloned with the git protocol
+func (opt baseOption) useAzureClient() bool {
+	return isAzureUrl(opt.repositoryUrl) && opt.sshKey == nil
+}
+
+// authCacheKey identifies the authentication of a repository in the cache keys
+func (opt baseOption) authCacheKey() string {
+	if opt.credential != nil {
+		return generateCacheKey("credential", strconv.Itoa(int(opt.credential.ID)), strconv.FormatInt(opt.credential.RotationDate, 10))
+	}
+
+	if opt.sshKey != nil {
+		return "ssh-" + strconv.Itoa(int(opt.sshKey.ID))
+	}
+
+	return generateCacheKey(opt.username, opt.password)
+}
+
 func (service *Service) purgeCache() {
 	if service.repoRefCache != nil {
 		service.repoRefCache.Purge()
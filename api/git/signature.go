@@ -0,0 +1,203 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/pem"
+	"hash"
+	"strings"
+
+	"github.com/pkg/errors"
+	portainer "github.com/portainer/portainer/api"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck // go-git verifies the commit signatures with the same package
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshSignatureMagic     = "SSHSIG"
+	sshSignatureNamespace = "git"
+	sshSignaturePEMType   = "SSH SIGNATURE"
+	gpgSignaturePrefix    = "-----BEGIN PGP SIGNATURE-----"
+)
+
+var (
+	// ErrCommitSignatureVerification is the parent of all the errors returned when a commit does not satisfy a signature policy
+	ErrCommitSignatureVerification = errors.New("commit signature verification failed")
+	ErrCommitNotSigned             = errors.Wrap(ErrCommitSignatureVerification, "the commit is not signed")
+	ErrCommitSignatureUntrusted    = errors.Wrap(ErrCommitSignatureVerification, "the commit is not signed by a trusted key")
+)
+
+// SigningKeyReader is the subset of the git signing key data service used to resolve the trusted keys of a signature policy
+type SigningKeyReader interface {
+	Read(ID portainer.GitSigningKeyID) (*portainer.GitSigningKey, error)
+	ReadAll() ([]portainer.GitSigningKey, error)
+}
+
+// sshSignature is the content of an armored SSH signature, following the format of the OpenSSH PROTOCOL.sshsig file
+type sshSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshSignedData is the blob signed by the key, the message is replaced by its hash
+type sshSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// ParseSigningKey validates a public key of the given type and returns its fingerprint
+func ParseSigningKey(keyType portainer.GitSigningKeyType, publicKey string) (string, error) {
+	switch keyType {
+	case portainer.GitSigningKeyTypeGPG:
+		entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey))
+		if err != nil {
+			return "", errors.Wrap(err, "invalid GPG public key")
+		}
+
+		if len(entities) != 1 {
+			return "", errors.New("the GPG public key must contain exactly one key")
+		}
+
+		return strings.ToUpper(hex.EncodeToString(entities[0].PrimaryKey.Fingerprint[:])), nil
+	case portainer.GitSigningKeyTypeSSH:
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+		if err != nil {
+			return "", errors.Wrap(err, "invalid SSH public key, the key must be in the authorized_keys format")
+		}
+
+		return ssh.FingerprintSHA256(key), nil
+	}
+
+	return "", errors.Errorf("unsupported signing key type %q", keyType)
+}
+
+// ResolveTrustedKeys returns the keys trusted by a signature policy, the whole keyring is trusted when the policy does not list any key
+func ResolveTrustedKeys(signingKeys SigningKeyReader, policy *gittypes.SignaturePolicy) ([]portainer.GitSigningKey, error) {
+	if len(policy.TrustedKeyIDs) == 0 {
+		return signingKeys.ReadAll()
+	}
+
+	keys := make([]portainer.GitSigningKey, 0, len(policy.TrustedKeyIDs))
+	for _, keyID := range policy.TrustedKeyIDs {
+		key, err := signingKeys.Read(portainer.GitSigningKeyID(keyID))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to read the trusted signing key %d", keyID)
+		}
+
+		keys = append(keys, *key)
+	}
+
+	return keys, nil
+}
+
+// VerifyCommitSignature makes sure that the commit is signed by one of the trusted keys and returns the key of the signature
+func VerifyCommitSignature(signature *gittypes.CommitSignature, trustedKeys []portainer.GitSigningKey) (*portainer.GitSigningKey, error) {
+	if strings.TrimSpace(signature.Signature) == "" {
+		return nil, ErrCommitNotSigned
+	}
+
+	isGPG := strings.HasPrefix(strings.TrimSpace(signature.Signature), gpgSignaturePrefix)
+
+	for i := range trustedKeys {
+		key := &trustedKeys[i]
+
+		var err error
+		switch {
+		case isGPG && key.Type == portainer.GitSigningKeyTypeGPG:
+			err = verifyGPGSignature(signature, key.PublicKey)
+		case !isGPG && key.Type == portainer.GitSigningKeyTypeSSH:
+			err = verifySSHSignature(signature, key.PublicKey)
+		default:
+			continue
+		}
+
+		if err == nil {
+			return key, nil
+		}
+	}
+
+	return nil, ErrCommitSignatureUntrusted
+}
+
+func verifyGPGSignature(signature *gittypes.CommitSignature, publicKey string) error {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey))
+	if err != nil {
+		return err
+	}
+
+	_, err = openpgp.CheckArmoredDetachedSignature(keyring, strings.NewReader(signature.Payload), strings.NewReader(signature.Signature))
+
+	return err
+}
+
+func verifySSHSignature(signature *gittypes.CommitSignature, publicKey string) error {
+	trustedKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode([]byte(signature.Signature))
+	if block == nil || block.Type != sshSignaturePEMType {
+		return errors.New("invalid SSH signature")
+	}
+
+	if !bytes.HasPrefix(block.Bytes, []byte(sshSignatureMagic)) {
+		return errors.New("invalid SSH signature preamble")
+	}
+
+	var sig sshSignature
+	if err := ssh.Unmarshal(block.Bytes[len(sshSignatureMagic):], &sig); err != nil {
+		return errors.Wrap(err, "invalid SSH signature")
+	}
+
+	if sig.Version != 1 {
+		return errors.Errorf("unsupported SSH signature version %d", sig.Version)
+	}
+
+	if sig.Namespace != sshSignatureNamespace {
+		return errors.Errorf("unexpected SSH signature namespace %q", sig.Namespace)
+	}
+
+	signingKey, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "invalid public key in the SSH signature")
+	}
+
+	if !bytes.Equal(signingKey.Marshal(), trustedKey.Marshal()) {
+		return errors.New("the SSH signature was made by another key")
+	}
+
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return errors.Errorf("unsupported SSH signature hash algorithm %q", sig.HashAlgorithm)
+	}
+	h.Write([]byte(signature.Payload))
+
+	signedData := append([]byte(sshSignatureMagic), ssh.Marshal(sshSignedData{
+		Namespace:     sig.Namespace,
+		Reserved:      sig.Reserved,
+		HashAlgorithm: sig.HashAlgorithm,
+		Hash:          h.Sum(nil),
+	})...)
+
+	var sshSig ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &sshSig); err != nil {
+		return errors.Wrap(err, "invalid SSH signature blob")
+	}
+
+	return signingKey.Verify(signedData, &sshSig)
+}
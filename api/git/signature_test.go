@@ -0,0 +1,212 @@
+package git
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	gittypes "github.com/portainer/portainer/api/git/types"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/openpgp"       //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/armor" //nolint:staticcheck
+	"golang.org/x/crypto/ssh"
+)
+
+// signature of "payload\n" made with ssh-keygen -Y sign -n git
+const (
+	testSSHSigningPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIL/ordepsi4bJsxTYor+2HQJH1MasWbe5Y3S9o3EtuDf test"
+	testSSHSignature        = `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAgv+it16myLhsmzFNiiv7YdAkfUx
+qxZt7ljdL2jcS24N8AAAADZ2l0AAAAAAAAAAZzaGE1MTIAAABTAAAAC3NzaC1lZDI1NTE5
+AAAAQEkp/lxIHsoZm2S6IIemNynMuO8S4kaDXrCkbZXnDqNMziL41c76WgVJydePI5FUYy
+RSGzFuB9u0OTKDm9m9ew4=
+-----END SSH SIGNATURE-----
+`
+)
+
+type testSigningKeyReader map[portainer.GitSigningKeyID]portainer.GitSigningKey
+
+func (reader testSigningKeyReader) Read(ID portainer.GitSigningKeyID) (*portainer.GitSigningKey, error) {
+	key, ok := reader[ID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return &key, nil
+}
+
+func (reader testSigningKeyReader) ReadAll() ([]portainer.GitSigningKey, error) {
+	keys := make([]portainer.GitSigningKey, 0, len(reader))
+	for _, key := range reader {
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func newSSHSigningKey(t *testing.T) (ssh.Signer, string) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signer, string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+}
+
+func sshSign(t *testing.T, signer ssh.Signer, namespace, payload string) string {
+	hash := sha512.Sum512([]byte(payload))
+	signedData := append([]byte(sshSignatureMagic), ssh.Marshal(sshSignedData{Namespace: namespace, HashAlgorithm: "sha512", Hash: hash[:]})...)
+
+	signature, err := signer.Sign(rand.Reader, signedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blob := append([]byte(sshSignatureMagic), ssh.Marshal(sshSignature{
+		Version:       1,
+		PublicKey:     signer.PublicKey().Marshal(),
+		Namespace:     namespace,
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(signature),
+	})...)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: sshSignaturePEMType, Bytes: blob}))
+}
+
+func newGPGSigningKey(t *testing.T) (*openpgp.Entity, string) {
+	entity, err := openpgp.NewEntity("Portainer", "", "test@portainer.io", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	return entity, buf.String()
+}
+
+func gpgSign(t *testing.T, entity *openpgp.Entity, payload string) string {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, entity, strings.NewReader(payload), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+func Test_ParseSigningKey(t *testing.T) {
+	is := assert.New(t)
+
+	fingerprint, err := ParseSigningKey(portainer.GitSigningKeyTypeSSH, testSSHSigningPublicKey)
+	is.NoError(err)
+	is.True(strings.HasPrefix(fingerprint, "SHA256:"), fingerprint)
+
+	entity, gpgKey := newGPGSigningKey(t)
+	fingerprint, err = ParseSigningKey(portainer.GitSigningKeyTypeGPG, gpgKey)
+	is.NoError(err)
+	is.Len(fingerprint, 40)
+	is.Equal(strings.ToUpper(entity.PrimaryKey.KeyIdString()), fingerprint[24:])
+
+	_, err = ParseSigningKey(portainer.GitSigningKeyTypeGPG, testSSHSigningPublicKey)
+	is.Error(err)
+
+	_, err = ParseSigningKey(portainer.GitSigningKeyTypeSSH, gpgKey)
+	is.Error(err)
+
+	_, err = ParseSigningKey("x509", testSSHSigningPublicKey)
+	is.Error(err)
+}
+
+func Test_VerifyCommitSignature_SSH(t *testing.T) {
+	is := assert.New(t)
+
+	trusted := portainer.GitSigningKey{ID: 1, Name: "ssh-keygen", Type: portainer.GitSigningKeyTypeSSH, PublicKey: testSSHSigningPublicKey}
+
+	key, err := VerifyCommitSignature(&gittypes.CommitSignature{Signature: testSSHSignature, Payload: "payload\n"}, []portainer.GitSigningKey{trusted})
+	is.NoError(err)
+	if is.NotNil(key) {
+		is.Equal(trusted.ID, key.ID)
+	}
+
+	_, err = VerifyCommitSignature(&gittypes.CommitSignature{Signature: testSSHSignature, Payload: "tampered\n"}, []portainer.GitSigningKey{trusted})
+	is.ErrorIs(err, ErrCommitSignatureUntrusted)
+
+	signer, publicKey := newSSHSigningKey(t)
+	other := portainer.GitSigningKey{ID: 2, Type: portainer.GitSigningKeyTypeSSH, PublicKey: publicKey}
+
+	_, err = VerifyCommitSignature(&gittypes.CommitSignature{Signature: testSSHSignature, Payload: "payload\n"}, []portainer.GitSigningKey{other})
+	is.ErrorIs(err, ErrCommitSignatureUntrusted, "the signature must be made by a trusted key")
+
+	key, err = VerifyCommitSignature(&gittypes.CommitSignature{Signature: sshSign(t, signer, "git", "commit"), Payload: "commit"}, []portainer.GitSigningKey{trusted, other})
+	is.NoError(err)
+	if is.NotNil(key) {
+		is.Equal(other.ID, key.ID)
+	}
+
+	_, err = VerifyCommitSignature(&gittypes.CommitSignature{Signature: sshSign(t, signer, "file", "commit"), Payload: "commit"}, []portainer.GitSigningKey{other})
+	is.ErrorIs(err, ErrCommitSignatureUntrusted, "the signatures of other namespaces must be refused")
+}
+
+func Test_VerifyCommitSignature_GPG(t *testing.T) {
+	is := assert.New(t)
+
+	entity, publicKey := newGPGSigningKey(t)
+	trusted := portainer.GitSigningKey{ID: 1, Type: portainer.GitSigningKeyTypeGPG, PublicKey: publicKey}
+	signature := gpgSign(t, entity, "commit")
+
+	key, err := VerifyCommitSignature(&gittypes.CommitSignature{Signature: signature, Payload: "commit"}, []portainer.GitSigningKey{trusted})
+	is.NoError(err)
+	if is.NotNil(key) {
+		is.Equal(trusted.ID, key.ID)
+	}
+
+	_, err = VerifyCommitSignature(&gittypes.CommitSignature{Signature: signature, Payload: "tampered"}, []portainer.GitSigningKey{trusted})
+	is.ErrorIs(err, ErrCommitSignatureUntrusted)
+
+	_, otherKey := newGPGSigningKey(t)
+	_, err = VerifyCommitSignature(&gittypes.CommitSignature{Signature: signature, Payload: "commit"}, []portainer.GitSigningKey{{Type: portainer.GitSigningKeyTypeGPG, PublicKey: otherKey}})
+	is.ErrorIs(err, ErrCommitSignatureUntrusted)
+
+	_, err = VerifyCommitSignature(&gittypes.CommitSignature{Payload: "commit"}, []portainer.GitSigningKey{trusted})
+	is.ErrorIs(err, ErrCommitNotSigned)
+	is.ErrorIs(err, ErrCommitSignatureVerification)
+}
+
+func Test_ResolveTrustedKeys(t *testing.T) {
+	is := assert.New(t)
+
+	signingKeys := testSigningKeyReader{1: {ID: 1}, 2: {ID: 2}}
+
+	keys, err := ResolveTrustedKeys(signingKeys, &gittypes.SignaturePolicy{})
+	is.NoError(err)
+	is.Len(keys, 2)
+
+	keys, err = ResolveTrustedKeys(signingKeys, &gittypes.SignaturePolicy{TrustedKeyIDs: []int{2}})
+	is.NoError(err)
+	if is.Len(keys, 1) {
+		is.Equal(portainer.GitSigningKeyID(2), keys[0].ID)
+	}
+
+	_, err = ResolveTrustedKeys(signingKeys, &gittypes.SignaturePolicy{TrustedKeyIDs: []int{3}})
+	is.Error(err)
+}
@@ -0,0 +1,169 @@
+package git
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/pkg/errors"
+	portainer "github.com/portainer/portainer/api"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const defaultSSHUser = "git"
+
+var (
+	ErrSSHKnownHostsMissing = errors.New("known hosts must be specified to authenticate with an SSH key")
+
+	// scpLikeURLRegExp matches the scp-like syntax of the SSH URLs, like git@github.com:portainer/portainer.git
+	scpLikeURLRegExp = regexp.MustCompile(`^(?:[^@/\s]+@)?[^:/\s]+:[^\\\s]\S*$`)
+)
+
+// SSHKeyReader is the subset of the git SSH key data service used to resolve the key of a repository authentication
+type SSHKeyReader interface {
+	Read(ID portainer.GitSSHKeyID) (*portainer.GitSSHKey, error)
+}
+
+// IsSSHURL returns true when the repository URL uses the SSH transport, either with the ssh:// scheme or the scp-like syntax
+func IsSSHURL(url string) bool {
+	if strings.HasPrefix(url, "ssh://") {
+		return true
+	}
+
+	return !strings.Contains(url, "://") && scpLikeURLRegExp.MatchString(url)
+}
+
+// GenerateSSHKey generates a new ed25519 private key, encoded in PEM
+func GenerateSSHKey() (string, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate the private key")
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode the private key")
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParseSSHKey parses a PEM encoded private key, optionally protected by a passphrase,
+// and returns its public key in the authorized_keys format with the SHA256 fingerprint of the public key
+func ParseSSHKey(privateKey, passphrase string) (publicKey string, fingerprint string, err error) {
+	signer, err := parseSigner(privateKey, passphrase)
+	if err != nil {
+		return "", "", err
+	}
+
+	publicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+
+	return publicKey, ssh.FingerprintSHA256(signer.PublicKey()), nil
+}
+
+// ValidateKnownHosts makes sure that the known hosts are in the known_hosts format and contain at least one host key
+func ValidateKnownHosts(knownHosts string) error {
+	rest := []byte(knownHosts)
+	count := 0
+
+	for {
+		_, _, _, _, remaining, err := ssh.ParseKnownHosts(rest)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrap(err, "invalid known hosts, the entries must be in the known_hosts format")
+		}
+
+		count++
+		rest = remaining
+	}
+
+	if count == 0 {
+		return ErrSSHKnownHostsMissing
+	}
+
+	return nil
+}
+
+func parseSigner(privateKey, passphrase string) (ssh.Signer, error) {
+	var (
+		signer ssh.Signer
+		err    error
+	)
+
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(privateKey))
+	}
+
+	var missingPassphraseErr *ssh.PassphraseMissingError
+	if errors.As(err, &missingPassphraseErr) {
+		return nil, errors.New("the private key is protected by a passphrase")
+	} else if err != nil {
+		return nil, errors.Wrap(err, "invalid private key")
+	}
+
+	return signer, nil
+}
+
+// newSSHAuth builds the SSH authentication of a repository, the host key of the server must match the known hosts of the key
+func newSSHAuth(repositoryURL string, key *portainer.GitSSHKey) (transport.AuthMethod, error) {
+	if strings.TrimSpace(key.KnownHosts) == "" {
+		return nil, ErrSSHKnownHostsMissing
+	}
+
+	signer, err := parseSigner(key.PrivateKey, key.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	hostKeyCallback, err := newKnownHostsCallback(key.KnownHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	user := defaultSSHUser
+	if endpoint, err := transport.NewEndpoint(repositoryURL); err == nil && endpoint.User != "" {
+		user = endpoint.User
+	}
+
+	return &gitssh.PublicKeys{
+		User:   user,
+		Signer: signer,
+		HostKeyCallbackHelper: gitssh.HostKeyCallbackHelper{
+			HostKeyCallback: hostKeyCallback,
+		},
+	}, nil
+}
+
+// newKnownHostsCallback builds a host key callback from the content of a known_hosts file,
+// the knownhosts package only reads files so the content is written to a temporary file which is loaded in memory
+func newKnownHostsCallback(knownHosts string) (ssh.HostKeyCallback, error) {
+	file, err := os.CreateTemp("", "portainer-known-hosts-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the known hosts file")
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(knownHosts)
+	file.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write the known hosts file")
+	}
+
+	callback, err := knownhosts.New(file.Name())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid known hosts")
+	}
+
+	return callback, nil
+}
@@ -0,0 +1,286 @@
+package git
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxSubmoduleDepth is the maximum number of nested submodules, like the default recursion depth of go-git
+	maxSubmoduleDepth = 10
+	gitModulesFile    = ".gitmodules"
+)
+
+// resolveSubmoduleURL resolves the URL of a submodule relative to the URL of its parent repository, like git does for the URLs
+// starting with ./ or ../, the scp-like URLs are converted to the ssh:// scheme since go-git only supports the URLs it can parse
+func resolveSubmoduleURL(parentURL, submoduleURL string) string {
+	if strings.HasPrefix(submoduleURL, "./") || strings.HasPrefix(submoduleURL, "../") {
+		if endpoint, err := transport.NewEndpoint(parentURL); err == nil && endpoint.Protocol != "file" {
+			endpoint.Path = path.Join("/", endpoint.Path, submoduleURL)
+			submoduleURL = endpoint.String()
+		} else {
+			submoduleURL = filepath.Join(parentURL, submoduleURL)
+		}
+	}
+
+	if IsSSHURL(submoduleURL) && !strings.HasPrefix(submoduleURL, "ssh://") {
+		if endpoint, err := transport.NewEndpoint(submoduleURL); err == nil {
+			endpoint.Path = path.Join("/", endpoint.Path)
+			submoduleURL = endpoint.String()
+		}
+	}
+
+	return submoduleURL
+}
+
+// submoduleOption returns the options used to fetch a submodule. The submodules of a remote repository cannot be hosted
+// on the local file system, and the authentication of the parent repository is only reused for the submodules hosted on
+// the same host, so that a repository cannot send the credentials to another server, unless the submodules have their
+// own authentication
+func (opt fetchOption) submoduleOption(submoduleURL, referenceName string) (fetchOption, error) {
+	parentEndpoint, err := transport.NewEndpoint(opt.repositoryUrl)
+	if err != nil {
+		return fetchOption{}, errors.Wrap(err, "failed to parse the repository URL")
+	}
+
+	submoduleEndpoint, err := transport.NewEndpoint(submoduleURL)
+	if err != nil {
+		return fetchOption{}, errors.Wrapf(err, "failed to parse the submodule URL %s", submoduleURL)
+	}
+
+	if submoduleEndpoint.Protocol == "file" && parentEndpoint.Protocol != "file" {
+		return fetchOption{}, errors.Errorf("the submodule URL %s refers to the local file system", submoduleURL)
+	}
+
+	base := opt.baseOption
+	if opt.content.submoduleAuth != nil {
+		base = *opt.content.submoduleAuth
+	} else if !strings.EqualFold(parentEndpoint.Host, submoduleEndpoint.Host) {
+		base = baseOption{}
+	}
+	base.repositoryUrl = submoduleURL
+	base.tlsSkipVerify = opt.tlsSkipVerify
+
+	return fetchOption{
+		baseOption:    base,
+		referenceName: referenceName,
+		dirOnly:       opt.dirOnly,
+		content:       opt.content,
+	}, nil
+}
+
+// resolveRepositoryContent checks out the submodules of a cloned repository recursively and replaces the LFS pointers of the
+// repository and of its submodules by the content of the LFS objects
+func resolveRepositoryContent(ctx context.Context, repo *git.Repository, dir string, opt fetchOption, depth int) error {
+	if depth > maxSubmoduleDepth {
+		return errors.Errorf("the submodules are nested more than %d times", maxSubmoduleDepth)
+	}
+
+	var submodulePaths []string
+
+	if opt.content.submodules {
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return err
+		}
+
+		submodules, err := worktree.Submodules()
+		if err != nil {
+			return errors.Wrap(err, "failed to read the submodules")
+		}
+
+		for _, submodule := range submodules {
+			submoduleConfig := submodule.Config()
+			submoduleConfig.URL = resolveSubmoduleURL(opt.repositoryUrl, submoduleConfig.URL)
+			submoduleOpt, err := opt.submoduleOption(submoduleConfig.URL, "")
+			if err != nil {
+				return err
+			}
+
+			auth, err := getAuth(submoduleOpt.baseOption)
+			if err != nil {
+				return err
+			}
+
+			err = submodule.UpdateContext(ctx, &git.SubmoduleUpdateOptions{Init: true, Auth: auth})
+			if err != nil {
+				return errors.Wrapf(checkGitError(err), "failed to fetch the submodule %s", submoduleConfig.Path)
+			}
+
+			submoduleRepo, err := submodule.Repository()
+			if err != nil {
+				return err
+			}
+
+			err = resolveRepositoryContent(ctx, submoduleRepo, filepath.Join(dir, submoduleConfig.Path), submoduleOpt, depth+1)
+			if err != nil {
+				return err
+			}
+
+			submodulePaths = append(submodulePaths, submoduleConfig.Path)
+		}
+	}
+
+	if opt.content.lfs {
+		return resolveLFSObjects(ctx, dir, opt.baseOption, submodulePaths, opt.content.lfsQuota)
+	}
+
+	return nil
+}
+
+// cloneCommit clones a repository and checks out a commit which is not the head of a reference, like the commit of a submodule.
+// go-git cannot fetch a single commit, the whole history of the repository is fetched.
+func cloneCommit(ctx context.Context, dst string, opt fetchOption, commit string) error {
+	auth, err := getAuth(opt.baseOption)
+	if err != nil {
+		return err
+	}
+
+	repo, err := git.PlainCloneContext(ctx, dst, false, &git.CloneOptions{
+		URL:             opt.repositoryUrl,
+		Auth:            auth,
+		InsecureSkipTLS: opt.tlsSkipVerify,
+		NoCheckout:      true,
+	})
+	if err != nil {
+		return errors.Wrapf(checkGitError(err), "failed to clone %s", opt.repositoryUrl)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	err = worktree.Checkout(&git.CheckoutOptions{Hash: plumbing.NewHash(commit), Force: true})
+	if err != nil {
+		return errors.Wrapf(err, "failed to checkout the commit %s of %s", commit, opt.repositoryUrl)
+	}
+
+	return resolveRepositoryContent(ctx, repo, dst, opt, 1)
+}
+
+// removeGitMetadata removes the .git directory of a repository and the .git files of its submodules
+func removeGitMetadata(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.Name() != ".git" {
+			return nil
+		}
+
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+}
+
+// readGitModules reads the submodules declared in the .gitmodules file of a commit, keyed by their path
+func readGitModules(commit *object.Commit) (map[string]*config.Submodule, error) {
+	file, err := commit.File(gitModulesFile)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseGitModules([]byte(content))
+}
+
+// SubmodulePaths returns the paths of the submodules declared in the .gitmodules file of a cloned repository
+func SubmodulePaths(dir string) ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, gitModulesFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	submodules, err := parseGitModules(content)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(submodules))
+	for submodulePath := range submodules {
+		paths = append(paths, submodulePath)
+	}
+
+	return paths, nil
+}
+
+func parseGitModules(content []byte) (map[string]*config.Submodule, error) {
+	modules := config.NewModules()
+	if err := modules.Unmarshal(content); err != nil {
+		return nil, errors.Wrap(err, "invalid .gitmodules file")
+	}
+
+	submodules := make(map[string]*config.Submodule, len(modules.Submodules))
+	for _, submodule := range modules.Submodules {
+		submodules[submodule.Path] = submodule
+	}
+
+	return submodules, nil
+}
+
+// listSubmoduleFiles lists the files of a submodule at a commit, the paths are prefixed by the path of the submodule
+func listSubmoduleFiles(ctx context.Context, opt fetchOption, submodulePath, commit string, depth int) ([]string, error) {
+	if depth > maxSubmoduleDepth {
+		return nil, errors.Errorf("the submodules are nested more than %d times", maxSubmoduleDepth)
+	}
+
+	auth, err := getAuth(opt.baseOption)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
+		URL:             opt.repositoryUrl,
+		Auth:            auth,
+		InsecureSkipTLS: opt.tlsSkipVerify,
+		NoCheckout:      true,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(checkGitError(err), "failed to fetch the submodule %s", submodulePath)
+	}
+
+	submoduleCommit, err := repo.CommitObject(plumbing.NewHash(commit))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the commit %s of the submodule %s", commit, submodulePath)
+	}
+
+	paths, err := listCommitFiles(ctx, submoduleCommit, opt, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range paths {
+		paths[i] = path.Join(submodulePath, paths[i])
+	}
+
+	return paths, nil
+}
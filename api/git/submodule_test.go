@@ -0,0 +1,172 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gittypes "github.com/portainer/portainer/api/git/types"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func commitFiles(t *testing.T, repo *git.Repository, dir string, files map[string]string) plumbing.Hash {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hash, err := worktree.Commit("commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Portainer", Email: "test@portainer.io", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hash
+}
+
+// setupSubmoduleRepository creates a repository with the submodule "common" referenced with a relative URL
+func setupSubmoduleRepository(t *testing.T) string {
+	dir := t.TempDir()
+
+	commonDir := filepath.Join(dir, "common")
+	common, err := git.PlainInit(commonDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commonHash := commitFiles(t, common, commonDir, map[string]string{"config/common.env": "COMMON=1"})
+
+	stackDir := filepath.Join(dir, "stack")
+	stack, err := git.PlainInit(stackDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := stack.Storer.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx.Entries = append(idx.Entries, &index.Entry{Name: "common", Hash: commonHash, Mode: filemode.Submodule})
+	if err := stack.Storer.SetIndex(idx); err != nil {
+		t.Fatal(err)
+	}
+
+	commitFiles(t, stack, stackDir, map[string]string{
+		"docker-compose.yml": "services: {}",
+		".gitmodules":        "[submodule \"common\"]\n\tpath = common\n\turl = ../common\n",
+	})
+
+	return stackDir
+}
+
+func Test_resolveSubmoduleURL(t *testing.T) {
+	is := assert.New(t)
+
+	tests := []struct {
+		parent, submodule, expected string
+	}{
+		{"https://github.com/portainer/stack.git", "../common.git", "https://github.com/portainer/common.git"},
+		{"https://github.com/portainer/stack", "./common", "https://github.com/portainer/stack/common"},
+		{"https://github.com/portainer/stack.git", "https://gitlab.com/portainer/common.git", "https://gitlab.com/portainer/common.git"},
+		{"ssh://git@github.com/portainer/stack.git", "../common.git", "ssh://git@github.com/portainer/common.git"},
+		{"git@github.com:portainer/stack.git", "../common.git", "ssh://git@github.com/portainer/common.git"},
+		{"https://github.com/portainer/stack.git", "git@github.com:portainer/common.git", "ssh://git@github.com/portainer/common.git"},
+		{"/srv/git/stack", "../common", "/srv/git/common"},
+	}
+
+	for _, tt := range tests {
+		is.Equal(tt.expected, resolveSubmoduleURL(tt.parent, tt.submodule), "%s relative to %s", tt.submodule, tt.parent)
+	}
+}
+
+func Test_submoduleOption(t *testing.T) {
+	is := assert.New(t)
+
+	opt := fetchOption{baseOption: baseOption{repositoryUrl: "https://github.com/portainer/stack.git", username: "user", password: "secret"}}
+
+	submoduleOpt, err := opt.submoduleOption("https://github.com/portainer/common.git", "")
+	is.NoError(err)
+	is.Equal("secret", submoduleOpt.password, "the credentials are reused on the same host")
+
+	submoduleOpt, err = opt.submoduleOption("https://attacker.example.com/common.git", "")
+	is.NoError(err)
+	is.Empty(submoduleOpt.username, "the credentials are not sent to another host")
+	is.Empty(submoduleOpt.password)
+
+	opt.content.submoduleAuth = &baseOption{username: "submodule", password: "token"}
+	submoduleOpt, err = opt.submoduleOption("https://gitlab.com/portainer/common.git", "")
+	is.NoError(err)
+	is.Equal("token", submoduleOpt.password, "the authentication of the submodules is used on any host")
+
+	_, err = opt.submoduleOption("file:///etc/portainer", "")
+	is.Error(err, "the remote repositories cannot use local submodules")
+
+	_, err = opt.submoduleOption("/srv/git/common", "")
+	is.Error(err)
+
+	local := fetchOption{baseOption: baseOption{repositoryUrl: "/srv/git/stack"}}
+	_, err = local.submoduleOption("/srv/git/common", "")
+	is.NoError(err, "the local repositories can use local submodules")
+}
+
+func Test_CloneRepository_Submodules(t *testing.T) {
+	is := assert.New(t)
+
+	service := Service{git: NewGitClient(false)}
+	repositoryURL := setupSubmoduleRepository(t)
+
+	dir := t.TempDir()
+	err := service.CloneRepository(dir, repositoryURL, "", nil, false, nil)
+	is.NoError(err)
+	is.NoFileExists(filepath.Join(dir, "common", "config", "common.env"), "the submodules are not fetched by default")
+
+	dir = t.TempDir()
+	err = service.CloneRepository(dir, repositoryURL, "", nil, false, &gittypes.ContentOptions{Submodules: true})
+	is.NoError(err)
+	is.FileExists(filepath.Join(dir, "common", "config", "common.env"))
+	is.NoFileExists(filepath.Join(dir, "common", ".git"))
+	is.NoDirExists(filepath.Join(dir, ".git"))
+}
+
+func Test_listFiles_Submodules(t *testing.T) {
+	is := assert.New(t)
+
+	client := NewGitClient(false)
+	repositoryURL := setupSubmoduleRepository(t)
+
+	paths, err := client.listFiles(context.TODO(), fetchOption{baseOption: baseOption{repositoryUrl: repositoryURL}})
+	is.NoError(err)
+	is.ElementsMatch([]string{".gitmodules", "common", "docker-compose.yml"}, paths)
+
+	opt := fetchOption{baseOption: baseOption{repositoryUrl: repositoryURL}, content: contentOption{submodules: true}}
+	paths, err = client.listFiles(context.TODO(), opt)
+	is.NoError(err)
+	is.ElementsMatch([]string{".gitmodules", "common/config/common.env", "docker-compose.yml"}, paths)
+
+	opt.dirOnly = true
+	paths, err = client.listFiles(context.TODO(), opt)
+	is.NoError(err)
+	is.ElementsMatch([]string{"common", "common/config"}, paths)
+}
@@ -21,6 +21,64 @@ type RepoConfig struct {
 	ConfigHash string `example:"bc4c183d756879ea4d173315338110b31004b8e0"`
 	// TLSSkipVerify skips SSL verification when cloning the Git repository
 	TLSSkipVerify bool `example:"false"`
+	// Signature policy enforced before redeploying a new commit, the commits are not verified when nil
+	SignaturePolicy *SignaturePolicy `json:",omitempty"`
+	// Fetch the submodules of the repository recursively
+	Submodules bool `example:"false"`
+	// Credentials of the submodules, the authentication of the repository is reused when nil
+	SubmoduleAuthentication *GitAuthentication `json:",omitempty"`
+	// Replace the Git LFS pointers by the content of the LFS objects
+	LFS bool `example:"false"`
+}
+
+// ContentOptions returns how the content of the repository is resolved, nil when only the files of the repository are fetched
+func (config *RepoConfig) ContentOptions() *ContentOptions {
+	if !config.Submodules && !config.LFS {
+		return nil
+	}
+
+	return &ContentOptions{
+		Submodules:              config.Submodules,
+		SubmoduleAuthentication: config.SubmoduleAuthentication,
+		LFS:                     config.LFS,
+	}
+}
+
+// SetContentOptions sets how the content of the repository is resolved, only the files of the repository are fetched when nil
+func (config *RepoConfig) SetContentOptions(content *ContentOptions) {
+	if content == nil {
+		content = &ContentOptions{}
+	}
+
+	config.Submodules = content.Submodules
+	config.SubmoduleAuthentication = content.SubmoduleAuthentication
+	config.LFS = content.LFS
+}
+
+// ContentOptions represents the content fetched in addition to the files of a repository
+type ContentOptions struct {
+	// Fetch the submodules of the repository recursively
+	Submodules bool
+	// Credentials of the submodules, the authentication of the repository is reused when nil
+	SubmoduleAuthentication *GitAuthentication
+	// Replace the Git LFS pointers by the content of the LFS objects
+	LFS bool
+}
+
+// SignaturePolicy requires the commits to be signed by a key of the trusted keyring stored in Portainer
+type SignaturePolicy struct {
+	// Identifiers of the trusted signing keys, all the keys of the keyring are trusted when empty
+	TrustedKeyIDs []int `example:"1"`
+}
+
+// CommitSignature represents the signature of a commit with the signed content
+type CommitSignature struct {
+	// Commit hash
+	CommitID string
+	// Armored GPG or SSH signature, empty when the commit is not signed
+	Signature string
+	// Encoded commit without its signature, which is the content signed by the author
+	Payload string
 }
 
 type GitAuthentication struct {
@@ -30,4 +88,7 @@ type GitAuthentication struct {
 	// When the value is 0, Username and Password are set without using saved credential
 	// This is introduced since 2.15.0
 	GitCredentialID int `example:"0"`
+	// Identifier of the stored SSH key used to authenticate when the value is not 0,
+	// Username and Password are then ignored
+	SSHKeyID int `example:"0"`
 }
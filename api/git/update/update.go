@@ -1,6 +1,7 @@
 package update
 
 import (
+	"os"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -12,8 +13,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// UpdateGitObject updates a git object based on its config
-func UpdateGitObject(gitService portainer.GitService, objId string, gitConfig *gittypes.RepoConfig, forceUpdate, enableVersionFolder bool, projectPath string) (bool, string, error) {
+// UpdateGitObject updates a git object based on its config,
+// the commit must satisfy the signature policy of the config before being cloned
+func UpdateGitObject(gitService portainer.GitService, signingKeys git.SigningKeyReader, objId string, gitConfig *gittypes.RepoConfig, forceUpdate, enableVersionFolder bool, projectPath string) (bool, string, error) {
 	if gitConfig == nil {
 		return false, "", nil
 	}
@@ -29,7 +31,17 @@ func UpdateGitObject(gitService portainer.GitService, objId string, gitConfig *g
 		return false, "", errors.WithMessagef(err, "failed to get credentials for %v", objId)
 	}
 
-	newHash, err := gitService.LatestCommitID(gitConfig.URL, gitConfig.ReferenceName, username, password, gitConfig.TLSSkipVerify)
+	var auth *gittypes.GitAuthentication
+	if gitConfig.Authentication != nil {
+		auth = &gittypes.GitAuthentication{
+			Username:        username,
+			Password:        password,
+			GitCredentialID: gitConfig.Authentication.GitCredentialID,
+			SSHKeyID:        gitConfig.Authentication.SSHKeyID,
+		}
+	}
+
+	newHash, err := gitService.LatestCommitID(gitConfig.URL, gitConfig.ReferenceName, auth, gitConfig.TLSSkipVerify)
 	if err != nil {
 		return false, "", errors.WithMessagef(err, "failed to fetch latest commit id of %v", objId)
 	}
@@ -47,28 +59,60 @@ func UpdateGitObject(gitService portainer.GitService, objId string, gitConfig *g
 		return false, newHash, nil
 	}
 
+	if gitConfig.SignaturePolicy != nil {
+		if err := VerifyCommitSignature(gitService, signingKeys, gitConfig, auth, newHash); err != nil {
+			return false, "", errors.WithMessagef(err, "refused to update %v to the commit %s", objId, newHash)
+		}
+	}
+
 	toDir := projectPath
 	if enableVersionFolder {
 		toDir = filesystem.JoinPaths(projectPath, newHash)
 	}
 
+	// the reference is fetched again by the clone, so the clone of a verified commit is done aside
+	// and only replaces the files of the object once the cloned commit is known to be the verified one
+	cloneDir := toDir
+	if gitConfig.SignaturePolicy != nil {
+		cloneDir = toDir + "-unverified"
+		os.RemoveAll(cloneDir)
+	}
+
 	cloneParams := &cloneRepositoryParameters{
 		url:           gitConfig.URL,
 		ref:           gitConfig.ReferenceName,
-		toDir:         toDir,
+		toDir:         cloneDir,
+		auth:          auth,
 		tlsSkipVerify: gitConfig.TLSSkipVerify,
+		content:       gitConfig.ContentOptions(),
 	}
-	if gitConfig.Authentication != nil {
-		cloneParams.auth = &gitAuth{
-			username: username,
-			password: password,
+
+	clonedHash, err := cloneGitRepository(gitService, cloneParams)
+	if err != nil {
+		if gitConfig.SignaturePolicy != nil {
+			os.RemoveAll(cloneDir)
 		}
-	}
 
-	if err := cloneGitRepository(gitService, cloneParams); err != nil {
 		return false, "", errors.WithMessagef(err, "failed to do a fresh clone of %v", objId)
 	}
 
+	if gitConfig.SignaturePolicy != nil {
+		if !strings.EqualFold(clonedHash, newHash) {
+			os.RemoveAll(cloneDir)
+
+			return false, "", errors.Wrapf(git.ErrCommitSignatureVerification, "refused to update %v, the reference moved from the verified commit %s to %s during the clone", objId, newHash, clonedHash)
+		}
+
+		if err := os.RemoveAll(toDir); err != nil {
+			return false, "", errors.WithMessagef(err, "failed to remove the previous files of %v", objId)
+		}
+
+		if err := filesystem.MoveDirectory(cloneDir, toDir); err != nil {
+			return false, "", errors.WithMessagef(err, "failed to move the verified clone of %v", objId)
+		}
+	}
+	newHash = clonedHash
+
 	log.Debug().
 		Str("hash", newHash).
 		Str("url", gitConfig.URL).
@@ -83,20 +127,43 @@ type cloneRepositoryParameters struct {
 	url   string
 	ref   string
 	toDir string
-	auth  *gitAuth
+	auth  *gittypes.GitAuthentication
 	// tlsSkipVerify skips SSL verification when cloning the Git repository
 	tlsSkipVerify bool `example:"false"`
+	content       *gittypes.ContentOptions
 }
 
-type gitAuth struct {
-	username string
-	password string
+func cloneGitRepository(gitService portainer.GitService, cloneParams *cloneRepositoryParameters) (string, error) {
+	return gitService.CloneRepositoryCommit(cloneParams.toDir, cloneParams.url, cloneParams.ref, cloneParams.auth, cloneParams.tlsSkipVerify, cloneParams.content)
 }
 
-func cloneGitRepository(gitService portainer.GitService, cloneParams *cloneRepositoryParameters) error {
-	if cloneParams.auth != nil {
-		return gitService.CloneRepository(cloneParams.toDir, cloneParams.url, cloneParams.ref, cloneParams.auth.username, cloneParams.auth.password, cloneParams.tlsSkipVerify)
+// VerifyCommitSignature makes sure that the latest commit of the reference is the expected commit and is signed
+// by a key trusted by the signature policy of the config
+func VerifyCommitSignature(gitService portainer.GitService, signingKeys git.SigningKeyReader, gitConfig *gittypes.RepoConfig, auth *gittypes.GitAuthentication, commitID string) error {
+	signature, err := gitService.CommitSignature(gitConfig.URL, gitConfig.ReferenceName, auth, gitConfig.TLSSkipVerify)
+	if err != nil {
+		return errors.WithMessage(err, "failed to fetch the commit signature")
+	}
+
+	if !strings.EqualFold(signature.CommitID, commitID) {
+		return errors.Errorf("the reference moved to the commit %s during the verification", signature.CommitID)
+	}
+
+	trustedKeys, err := git.ResolveTrustedKeys(signingKeys, gitConfig.SignaturePolicy)
+	if err != nil {
+		return err
+	}
+
+	key, err := git.VerifyCommitSignature(signature, trustedKeys)
+	if err != nil {
+		return err
 	}
 
-	return gitService.CloneRepository(cloneParams.toDir, cloneParams.url, cloneParams.ref, "", "", cloneParams.tlsSkipVerify)
+	log.Debug().
+		Str("hash", commitID).
+		Str("url", gitConfig.URL).
+		Str("signing_key", key.Name).
+		Msg("commit signature verified")
+
+	return nil
 }
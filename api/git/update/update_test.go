@@ -0,0 +1,45 @@
+package update
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type testGitService struct {
+	portainer.GitService
+	latestCommitID string
+	clonedCommitID string
+	cloneAuth      *gittypes.GitAuthentication
+}
+
+func (g *testGitService) LatestCommitID(repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool) (string, error) {
+	return g.latestCommitID, nil
+}
+
+func (g *testGitService) CloneRepositoryCommit(destination, repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool, content *gittypes.ContentOptions) (string, error) {
+	g.cloneAuth = auth
+	return g.clonedCommitID, nil
+}
+
+func Test_UpdateGitObject(t *testing.T) {
+	is := assert.New(t)
+
+	gitService := &testGitService{latestCommitID: "new", clonedCommitID: "newer"}
+	gitConfig := &gittypes.RepoConfig{
+		URL:            "https://github.com/portainer/stack.git",
+		ReferenceName:  "refs/heads/main",
+		ConfigHash:     "old",
+		Authentication: &gittypes.GitAuthentication{GitCredentialID: 3},
+	}
+
+	updated, hash, err := UpdateGitObject(gitService, nil, "stack:1", gitConfig, false, false, t.TempDir())
+	is.NoError(err)
+	is.True(updated)
+	is.Equal("newer", hash, "the cloned commit is returned")
+	if is.NotNil(gitService.cloneAuth) {
+		is.Equal(3, gitService.cloneAuth.GitCredentialID, "the shared credential is used to clone")
+	}
+}
@@ -21,6 +21,10 @@ func ValidateAutoUpdateSettings(autoUpdate *portainer.AutoUpdateSettings) error
 		return httperrors.NewInvalidPayloadError("invalid Webhook format")
 	}
 
+	if autoUpdate.WebhookSecret != "" && autoUpdate.Webhook == "" {
+		return httperrors.NewInvalidPayloadError("WebhookSecret can only be provided with a Webhook")
+	}
+
 	if autoUpdate.Interval != "" {
 		if _, err := time.ParseDuration(autoUpdate.Interval); err != nil {
 			return httperrors.NewInvalidPayloadError("invalid Interval format")
@@ -2,13 +2,22 @@ package git
 
 import (
 	"github.com/asaskevich/govalidator"
+	"github.com/pkg/errors"
 
+	portainer "github.com/portainer/portainer/api"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	httperrors "github.com/portainer/portainer/api/http/errors"
 )
 
+var ErrSSHKeyAccessDenied = errors.New("Access denied to the SSH key")
+
+// IsValidRepositoryURL returns true when the repository URL is either an HTTP(S) URL or an SSH URL
+func IsValidRepositoryURL(url string) bool {
+	return !govalidator.IsNull(url) && (govalidator.IsURL(url) || IsSSHURL(url))
+}
+
 func ValidateRepoConfig(repoConfig *gittypes.RepoConfig) error {
-	if govalidator.IsNull(repoConfig.URL) || !govalidator.IsURL(repoConfig.URL) {
+	if !IsValidRepositoryURL(repoConfig.URL) {
 		return httperrors.NewInvalidPayloadError("Invalid repository URL. Must correspond to a valid URL format")
 	}
 
@@ -17,9 +26,46 @@ func ValidateRepoConfig(repoConfig *gittypes.RepoConfig) error {
 }
 
 func ValidateRepoAuthentication(auth *gittypes.GitAuthentication) error {
-	if auth != nil && govalidator.IsNull(auth.Password) && auth.GitCredentialID == 0 {
-		return httperrors.NewInvalidPayloadError("Invalid repository credentials. Password or GitCredentialID must be specified when authentication is enabled")
+	if auth != nil && govalidator.IsNull(auth.Password) && auth.GitCredentialID == 0 && auth.SSHKeyID == 0 {
+		return httperrors.NewInvalidPayloadError("Invalid repository credentials. Password, GitCredentialID or SSHKeyID must be specified when authentication is enabled")
+	}
+
+	return nil
+}
+
+// ValidateContentOptions validates the credentials of the submodules, they can only be set when the submodules are fetched
+func ValidateContentOptions(content *gittypes.ContentOptions) error {
+	if content == nil || content.SubmoduleAuthentication == nil {
+		return nil
+	}
+
+	if !content.Submodules {
+		return httperrors.NewInvalidPayloadError("Invalid submodule credentials. The submodules must be fetched to use them")
+	}
+
+	return ValidateRepoAuthentication(content.SubmoduleAuthentication)
+}
+
+// ValidateSSHKeyAccess makes sure that the SSH key exists and belongs to the user, only the owner of a key can use it in a repository.
+// ErrSSHKeyAccessDenied is returned as well when the key cannot be read so that the existence of the keys of the other users is not disclosed
+func ValidateSSHKeyAccess(sshKeys SSHKeyReader, keyID int, userID portainer.UserID) error {
+	if keyID == 0 {
+		return nil
+	}
+
+	sshKey, err := sshKeys.Read(portainer.GitSSHKeyID(keyID))
+	if err != nil || sshKey.UserID != userID {
+		return ErrSSHKeyAccessDenied
 	}
 
 	return nil
 }
+
+// ValidateContentSSHKeyAccess makes sure that the user can use the SSH key of the submodules, see ValidateSSHKeyAccess
+func ValidateContentSSHKeyAccess(sshKeys SSHKeyReader, content *gittypes.ContentOptions, userID portainer.UserID) error {
+	if content == nil || content.SubmoduleAuthentication == nil {
+		return nil
+	}
+
+	return ValidateSSHKeyAccess(sshKeys, content.SubmoduleAuthentication.SSHKeyID, userID)
+}
@@ -9,7 +9,9 @@ import (
 	"strconv"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/customtemplates/variables"
 	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/git"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
@@ -34,7 +36,11 @@ func (handler *Handler) customTemplateCreate(w http.ResponseWriter, r *http.Requ
 	}
 
 	customTemplate, err := handler.createCustomTemplate(method, r)
-	if err != nil {
+	if errors.Is(err, git.ErrSSHKeyAccessDenied) {
+		return httperror.Forbidden("Unable to use the SSH key", err)
+	} else if errors.Is(err, git.ErrGitCredentialAccessDenied) {
+		return httperror.Forbidden("Unable to use the git credential", err)
+	} else if err != nil {
 		return httperror.InternalServerError("Unable to create custom template", err)
 	}
 
@@ -125,7 +131,7 @@ func (payload *customTemplateFromFileContentPayload) Validate(r *http.Request) e
 		return errors.New("Invalid note. <img> tag is not supported")
 	}
 
-	return validateVariablesDefinitions(payload.Variables)
+	return variables.ValidateDefinitions(payload.Variables)
 }
 
 func isValidNote(note string) bool {
@@ -209,6 +215,10 @@ type customTemplateFromGitRepositoryPayload struct {
 	RepositoryUsername string `example:"myGitUsername"`
 	// Password used in basic authentication. Required when RepositoryAuthentication is true.
 	RepositoryPassword string `example:"myGitPassword"`
+	// Identifier of the SSH key used to authenticate. Used instead of RepositoryUsername and RepositoryPassword when not 0
+	RepositorySSHKeyID int `example:"0"`
+	// Identifier of the shared git credential used to authenticate. Used instead of RepositoryUsername and RepositoryPassword when not 0
+	RepositoryGitCredentialID int `example:"0"`
 	// Path to the Stack file inside the Git repository
 	ComposeFilePathInRepository string `example:"docker-compose.yml" default:"docker-compose.yml"`
 	// Definitions of variables in the stack file
@@ -226,11 +236,11 @@ func (payload *customTemplateFromGitRepositoryPayload) Validate(r *http.Request)
 	if govalidator.IsNull(payload.Description) {
 		return errors.New("Invalid custom template description")
 	}
-	if govalidator.IsNull(payload.RepositoryURL) || !govalidator.IsURL(payload.RepositoryURL) {
+	if !git.IsValidRepositoryURL(payload.RepositoryURL) {
 		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
 	}
-	if payload.RepositoryAuthentication && (govalidator.IsNull(payload.RepositoryUsername) || govalidator.IsNull(payload.RepositoryPassword)) {
-		return errors.New("Invalid repository credentials. Username and password must be specified when authentication is enabled")
+	if payload.RepositoryAuthentication && payload.RepositorySSHKeyID == 0 && payload.RepositoryGitCredentialID == 0 && (govalidator.IsNull(payload.RepositoryUsername) || govalidator.IsNull(payload.RepositoryPassword)) {
+		return errors.New("Invalid repository credentials. Username and password, SSH key or git credential must be specified when authentication is enabled")
 	}
 	if govalidator.IsNull(payload.ComposeFilePathInRepository) {
 		payload.ComposeFilePathInRepository = filesystem.ComposeFileDefaultName
@@ -247,7 +257,7 @@ func (payload *customTemplateFromGitRepositoryPayload) Validate(r *http.Request)
 		return errors.New("Invalid note. <img> tag is not supported")
 	}
 
-	return validateVariablesDefinitions(payload.Variables)
+	return variables.ValidateDefinitions(payload.Variables)
 }
 
 // @id CustomTemplateCreateRepository
@@ -298,9 +308,26 @@ func (handler *Handler) createCustomTemplateFromGitRepository(r *http.Request) (
 	}
 
 	if payload.RepositoryAuthentication {
+		securityContext, err := security.RetrieveRestrictedRequestContext(r)
+		if err != nil {
+			return nil, err
+		}
+
+		err = git.ValidateSSHKeyAccess(handler.DataStore.GitSSHKey(), payload.RepositorySSHKeyID, securityContext.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = git.ValidateCredentialAccess(handler.DataStore.GitCredential(), payload.RepositoryGitCredentialID, securityContext.UserID, securityContext.UserMemberships, securityContext.IsAdmin)
+		if err != nil {
+			return nil, err
+		}
+
 		gitConfig.Authentication = &gittypes.GitAuthentication{
-			Username: payload.RepositoryUsername,
-			Password: payload.RepositoryPassword,
+			Username:        payload.RepositoryUsername,
+			Password:        payload.RepositoryPassword,
+			SSHKeyID:        payload.RepositorySSHKeyID,
+			GitCredentialID: payload.RepositoryGitCredentialID,
 		}
 	}
 
@@ -418,7 +445,7 @@ func (payload *customTemplateFromFileUploadPayload) Validate(r *http.Request) er
 		if err != nil {
 			return errors.New("Invalid variables. Ensure that the variables are valid JSON")
 		}
-		return validateVariablesDefinitions(payload.Variables)
+		return variables.ValidateDefinitions(payload.Variables)
 	}
 	return nil
 }
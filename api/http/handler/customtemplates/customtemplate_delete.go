@@ -56,6 +56,10 @@ func (handler *Handler) customTemplateDelete(w http.ResponseWriter, r *http.Requ
 		return httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
 	}
 
+	if customTemplate.RepositoryID != 0 {
+		return httperror.BadRequest("Custom templates managed by a template repository cannot be removed, update the repository instead", errTemplateManagedByRepository)
+	}
+
 	err = handler.DataStore.CustomTemplate().Delete(portainer.CustomTemplateID(customTemplateID))
 	if err != nil {
 		return httperror.InternalServerError("Unable to remove the custom template from the database", err)
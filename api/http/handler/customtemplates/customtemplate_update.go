@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/customtemplates/variables"
 	"github.com/portainer/portainer/api/filesystem"
 	"github.com/portainer/portainer/api/git"
 	gittypes "github.com/portainer/portainer/api/git/types"
@@ -49,6 +50,8 @@ type customTemplateUpdatePayload struct {
 	// GitCredentialID used to identify the bound git credential. Required when RepositoryAuthentication
 	// is true and RepositoryUsername/RepositoryPassword are not provided
 	RepositoryGitCredentialID int `example:"0"`
+	// Identifier of the SSH key used to authenticate. Used instead of RepositoryUsername and RepositoryPassword when not 0
+	RepositorySSHKeyID int `example:"0"`
 	// Path to the Stack file inside the Git repository
 	ComposeFilePathInRepository string `example:"docker-compose.yml" default:"docker-compose.yml"`
 	// Content of stack file
@@ -81,14 +84,14 @@ func (payload *customTemplateUpdatePayload) Validate(r *http.Request) error {
 		return errors.New("Invalid note. <img> tag is not supported")
 	}
 
-	if payload.RepositoryAuthentication && (govalidator.IsNull(payload.RepositoryUsername) || govalidator.IsNull(payload.RepositoryPassword)) {
-		return errors.New("Invalid repository credentials. Username and password must be specified when authentication is enabled")
+	if payload.RepositoryAuthentication && payload.RepositorySSHKeyID == 0 && payload.RepositoryGitCredentialID == 0 && (govalidator.IsNull(payload.RepositoryUsername) || govalidator.IsNull(payload.RepositoryPassword)) {
+		return errors.New("Invalid repository credentials. Username and password, SSH key or git credential must be specified when authentication is enabled")
 	}
 	if govalidator.IsNull(payload.ComposeFilePathInRepository) {
 		payload.ComposeFilePathInRepository = filesystem.ComposeFileDefaultName
 	}
 
-	err := validateVariablesDefinitions(payload.Variables)
+	err := variables.ValidateDefinitions(payload.Variables)
 	if err != nil {
 		return err
 	}
@@ -153,6 +156,10 @@ func (handler *Handler) customTemplateUpdate(w http.ResponseWriter, r *http.Requ
 		return httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
 	}
 
+	if customTemplate.RepositoryID != 0 {
+		return httperror.BadRequest("Custom templates managed by a template repository cannot be updated, update the repository instead", errTemplateManagedByRepository)
+	}
+
 	customTemplate.Title = payload.Title
 	customTemplate.Logo = payload.Logo
 	customTemplate.Description = payload.Description
@@ -163,7 +170,7 @@ func (handler *Handler) customTemplateUpdate(w http.ResponseWriter, r *http.Requ
 	customTemplate.IsComposeFormat = payload.IsComposeFormat
 
 	if payload.RepositoryURL != "" {
-		if !govalidator.IsURL(payload.RepositoryURL) {
+		if !git.IsValidRepositoryURL(payload.RepositoryURL) {
 			return httperror.BadRequest("Invalid repository URL. Must correspond to a valid URL format", err)
 		}
 
@@ -174,24 +181,38 @@ func (handler *Handler) customTemplateUpdate(w http.ResponseWriter, r *http.Requ
 			TLSSkipVerify:  payload.TLSSkipVerify,
 		}
 
-		repositoryUsername := ""
-		repositoryPassword := ""
 		if payload.RepositoryAuthentication {
-			repositoryUsername = payload.RepositoryUsername
-			repositoryPassword = payload.RepositoryPassword
+			// The SSH key already saved with the template can be kept by all the users allowed to edit the template
+			if customTemplate.GitConfig == nil || customTemplate.GitConfig.Authentication == nil || customTemplate.GitConfig.Authentication.SSHKeyID != payload.RepositorySSHKeyID {
+				err = git.ValidateSSHKeyAccess(handler.DataStore.GitSSHKey(), payload.RepositorySSHKeyID, securityContext.UserID)
+				if err != nil {
+					return httperror.Forbidden("Unable to use the SSH key", err)
+				}
+			}
+
+			// The same goes for the git credential
+			if customTemplate.GitConfig == nil || customTemplate.GitConfig.Authentication == nil || customTemplate.GitConfig.Authentication.GitCredentialID != payload.RepositoryGitCredentialID {
+				err = git.ValidateCredentialAccess(handler.DataStore.GitCredential(), payload.RepositoryGitCredentialID, securityContext.UserID, securityContext.UserMemberships, securityContext.IsAdmin)
+				if err != nil {
+					return httperror.Forbidden("Unable to use the git credential", err)
+				}
+			}
+
 			gitConfig.Authentication = &gittypes.GitAuthentication{
-				Username: payload.RepositoryUsername,
-				Password: payload.RepositoryPassword,
+				Username:        payload.RepositoryUsername,
+				Password:        payload.RepositoryPassword,
+				SSHKeyID:        payload.RepositorySSHKeyID,
+				GitCredentialID: payload.RepositoryGitCredentialID,
 			}
 		}
 
 		cleanBackup, err := git.CloneWithBackup(handler.GitService, handler.FileService, git.CloneOptions{
-			ProjectPath:   customTemplate.ProjectPath,
-			URL:           gitConfig.URL,
-			ReferenceName: gitConfig.ReferenceName,
-			Username:      repositoryUsername,
-			Password:      repositoryPassword,
-			TLSSkipVerify: gitConfig.TLSSkipVerify,
+			ProjectPath:    customTemplate.ProjectPath,
+			URL:            gitConfig.URL,
+			ReferenceName:  gitConfig.ReferenceName,
+			Authentication: gitConfig.Authentication,
+			TLSSkipVerify:  gitConfig.TLSSkipVerify,
+			Content:        gitConfig.ContentOptions(),
 		})
 		if err != nil {
 			return httperror.InternalServerError("Unable to clone git repository directory", err)
@@ -199,7 +220,7 @@ func (handler *Handler) customTemplateUpdate(w http.ResponseWriter, r *http.Requ
 
 		defer cleanBackup()
 
-		commitHash, err := handler.GitService.LatestCommitID(gitConfig.URL, gitConfig.ReferenceName, repositoryUsername, repositoryPassword, gitConfig.TLSSkipVerify)
+		commitHash, err := handler.GitService.LatestCommitID(gitConfig.URL, gitConfig.ReferenceName, gitConfig.Authentication, gitConfig.TLSSkipVerify)
 		if err != nil {
 			return httperror.InternalServerError("Unable get latest commit id", fmt.Errorf("failed to fetch latest commit id of the template %v: %w", customTemplate.ID, err))
 		}
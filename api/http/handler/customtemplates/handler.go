@@ -1,16 +1,20 @@
 package customtemplates
 
 import (
+	"errors"
 	"net/http"
 	"sync"
 
 	"github.com/gorilla/mux"
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/customtemplates/repository"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/http/security"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 )
 
+var errTemplateManagedByRepository = errors.New("custom template is managed by a template repository")
+
 // Handler is the HTTP handler used to handle environment(endpoint) group operations.
 type Handler struct {
 	*mux.Router
@@ -18,6 +22,8 @@ type Handler struct {
 	FileService    portainer.FileService
 	GitService     portainer.GitService
 	gitFetchMutexs map[portainer.TemplateID]*sync.Mutex
+
+	TemplateRepositoryService *repository.Service
 }
 
 // NewHandler creates a handler to manage environment(endpoint) group operations.
@@ -34,6 +40,18 @@ func NewHandler(bouncer security.BouncerService, dataStore dataservices.DataStor
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.customTemplateCreate))).Methods(http.MethodPost)
 	h.Handle("/custom_templates",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.customTemplateList))).Methods(http.MethodGet)
+	h.Handle("/custom_templates/repositories",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.templateRepositoryList))).Methods(http.MethodGet)
+	h.Handle("/custom_templates/repositories",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.templateRepositoryCreate))).Methods(http.MethodPost)
+	h.Handle("/custom_templates/repositories/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.templateRepositoryInspect))).Methods(http.MethodGet)
+	h.Handle("/custom_templates/repositories/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.templateRepositoryUpdate))).Methods(http.MethodPut)
+	h.Handle("/custom_templates/repositories/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.templateRepositoryDelete))).Methods(http.MethodDelete)
+	h.Handle("/custom_templates/repositories/{id}/sync",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.templateRepositorySync))).Methods(http.MethodPost)
 	h.Handle("/custom_templates/{id}",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.customTemplateInspect))).Methods(http.MethodGet)
 	h.Handle("/custom_templates/{id}/file",
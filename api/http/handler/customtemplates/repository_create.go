@@ -0,0 +1,148 @@
+package customtemplates
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/pkg/errors"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/customtemplates/repository"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+	"github.com/rs/zerolog/log"
+)
+
+type templateRepositoryCreatePayload struct {
+	// Name of the repository
+	Name string `example:"company-templates" validate:"required"`
+	// URL of the Git repository hosting the templates
+	RepositoryURL string `example:"https://github.com/portainer/templates" validate:"required"`
+	// Reference name of the Git repository
+	RepositoryReferenceName string `example:"refs/heads/main"`
+	// Use basic authentication to clone the Git repository
+	RepositoryAuthentication bool `example:"true"`
+	// Username used in basic authentication. Required when RepositoryAuthentication is true.
+	RepositoryUsername string `example:"myGitUsername"`
+	// Password used in basic authentication. Required when RepositoryAuthentication is true.
+	RepositoryPassword string `example:"myGitPassword"`
+	// Path to the manifest inside the Git repository
+	ManifestPath string `example:"portainer-templates.json" default:"portainer-templates.json"`
+	// TLSSkipVerify skips SSL verification when cloning the Git repository
+	TLSSkipVerify bool `example:"false"`
+	// Interval between two synchronizations, scheduled synchronizations are disabled when empty
+	SyncInterval string `example:"1h"`
+}
+
+func (payload *templateRepositoryCreatePayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.Name) {
+		return errors.New("Invalid repository name")
+	}
+	if govalidator.IsNull(payload.RepositoryURL) || !govalidator.IsURL(payload.RepositoryURL) {
+		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
+	}
+	if payload.RepositoryAuthentication && (govalidator.IsNull(payload.RepositoryUsername) || govalidator.IsNull(payload.RepositoryPassword)) {
+		return errors.New("Invalid repository credentials. Username and password must be specified when authentication is enabled")
+	}
+	if govalidator.IsNull(payload.ManifestPath) {
+		payload.ManifestPath = repository.DefaultManifestPath
+	}
+
+	return validateSyncInterval(payload.SyncInterval)
+}
+
+func validateSyncInterval(interval string) error {
+	if interval == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(interval)
+	if err != nil || d < time.Minute {
+		return errors.New("Invalid sync interval. Must be a duration of at least one minute")
+	}
+
+	return nil
+}
+
+// @id CustomTemplateRepositoryCreate
+// @summary Create a template repository
+// @description Create a template repository and synchronize its custom templates.
+// @description **Access policy**: administrator
+// @tags custom_templates
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param body body templateRepositoryCreatePayload true "Template repository details"
+// @success 200 {object} portainer.CustomTemplateRepository
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /custom_templates/repositories [post]
+func (handler *Handler) templateRepositoryCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload templateRepositoryCreatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user details from authentication token", err)
+	}
+
+	templateRepository := &portainer.CustomTemplateRepository{
+		Name: payload.Name,
+		GitConfig: &gittypes.RepoConfig{
+			URL:            payload.RepositoryURL,
+			ReferenceName:  payload.RepositoryReferenceName,
+			ConfigFilePath: payload.ManifestPath,
+			TLSSkipVerify:  payload.TLSSkipVerify,
+		},
+		SyncInterval:    payload.SyncInterval,
+		CreatedByUserID: tokenData.ID,
+	}
+
+	if payload.RepositoryAuthentication {
+		templateRepository.GitConfig.Authentication = &gittypes.GitAuthentication{
+			Username: payload.RepositoryUsername,
+			Password: payload.RepositoryPassword,
+		}
+	}
+
+	err = handler.DataStore.CustomTemplateRepository().Create(templateRepository)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the template repository inside the database", err)
+	}
+
+	if err := handler.TemplateRepositoryService.Sync(templateRepository.ID); err != nil {
+		log.Warn().Err(err).Int("repository_id", int(templateRepository.ID)).Msg("unable to synchronize the template repository")
+	}
+
+	return handler.scheduleTemplateRepository(w, templateRepository.ID)
+}
+
+// scheduleTemplateRepository (re)schedules the synchronization of a template repository and writes it to the response
+func (handler *Handler) scheduleTemplateRepository(w http.ResponseWriter, repositoryID portainer.CustomTemplateRepositoryID) *httperror.HandlerError {
+	templateRepository, err := handler.TemplateRepositoryService.Update(repositoryID, nil)
+	if err != nil {
+		return httperror.InternalServerError("Unable to schedule the template repository synchronization", err)
+	}
+
+	return response.JSON(w, hideTemplateRepositoryPassword(templateRepository))
+}
+
+func hideTemplateRepositoryPassword(templateRepository *portainer.CustomTemplateRepository) *portainer.CustomTemplateRepository {
+	if templateRepository.GitConfig != nil && templateRepository.GitConfig.Authentication != nil {
+		authentication := *templateRepository.GitConfig.Authentication
+		authentication.Password = ""
+
+		gitConfig := *templateRepository.GitConfig
+		gitConfig.Authentication = &authentication
+		templateRepository.GitConfig = &gitConfig
+	}
+
+	return templateRepository
+}
@@ -0,0 +1,50 @@
+package customtemplates
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id CustomTemplateRepositoryDelete
+// @summary Remove a template repository
+// @description Remove a template repository and the custom templates it manages.
+// @description Stacks created from these templates are left untouched.
+// @description **Access policy**: administrator
+// @tags custom_templates
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Template repository identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Template repository not found"
+// @failure 500 "Server error"
+// @router /custom_templates/repositories/{id} [delete]
+func (handler *Handler) templateRepositoryDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	repositoryID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid template repository identifier route variable", err)
+	}
+
+	templateRepository, err := handler.DataStore.CustomTemplateRepository().Read(portainer.CustomTemplateRepositoryID(repositoryID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a template repository with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a template repository with the specified identifier inside the database", err)
+	}
+
+	err = handler.TemplateRepositoryService.Remove(templateRepository)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the custom templates of the template repository", err)
+	}
+
+	err = handler.DataStore.CustomTemplateRepository().Delete(templateRepository.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the template repository from the database", err)
+	}
+
+	return response.Empty(w)
+}
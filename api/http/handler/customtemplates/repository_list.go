@@ -0,0 +1,64 @@
+package customtemplates
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id CustomTemplateRepositoryList
+// @summary List template repositories
+// @description List the git repositories hosting custom templates.
+// @description **Access policy**: administrator
+// @tags custom_templates
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {array} portainer.CustomTemplateRepository "Success"
+// @failure 500 "Server error"
+// @router /custom_templates/repositories [get]
+func (handler *Handler) templateRepositoryList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	templateRepositories, err := handler.DataStore.CustomTemplateRepository().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve template repositories from the database", err)
+	}
+
+	for i := range templateRepositories {
+		hideTemplateRepositoryPassword(&templateRepositories[i])
+	}
+
+	return response.JSON(w, templateRepositories)
+}
+
+// @id CustomTemplateRepositoryInspect
+// @summary Inspect a template repository
+// @description Retrieve details about a template repository.
+// @description **Access policy**: administrator
+// @tags custom_templates
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Template repository identifier"
+// @success 200 {object} portainer.CustomTemplateRepository "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Template repository not found"
+// @failure 500 "Server error"
+// @router /custom_templates/repositories/{id} [get]
+func (handler *Handler) templateRepositoryInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	repositoryID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid template repository identifier route variable", err)
+	}
+
+	templateRepository, err := handler.DataStore.CustomTemplateRepository().Read(portainer.CustomTemplateRepositoryID(repositoryID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a template repository with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a template repository with the specified identifier inside the database", err)
+	}
+
+	return response.JSON(w, hideTemplateRepositoryPassword(templateRepository))
+}
@@ -0,0 +1,50 @@
+package customtemplates
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id CustomTemplateRepositorySync
+// @summary Synchronize a template repository
+// @description Fetch the latest commit of a template repository and update its custom templates.
+// @description **Access policy**: administrator
+// @tags custom_templates
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Template repository identifier"
+// @success 200 {object} portainer.CustomTemplateRepository "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Template repository not found"
+// @failure 500 "Server error"
+// @router /custom_templates/repositories/{id}/sync [post]
+func (handler *Handler) templateRepositorySync(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	repositoryID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid template repository identifier route variable", err)
+	}
+
+	_, err = handler.DataStore.CustomTemplateRepository().Read(portainer.CustomTemplateRepositoryID(repositoryID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a template repository with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a template repository with the specified identifier inside the database", err)
+	}
+
+	err = handler.TemplateRepositoryService.Sync(portainer.CustomTemplateRepositoryID(repositoryID))
+	if err != nil {
+		return httperror.InternalServerError("Unable to synchronize the template repository", err)
+	}
+
+	templateRepository, err := handler.DataStore.CustomTemplateRepository().Read(portainer.CustomTemplateRepositoryID(repositoryID))
+	if err != nil {
+		return httperror.InternalServerError("Unable to find a template repository with the specified identifier inside the database", err)
+	}
+
+	return response.JSON(w, hideTemplateRepositoryPassword(templateRepository))
+}
@@ -0,0 +1,125 @@
+package customtemplates
+
+import (
+	"net/http"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/pkg/errors"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/customtemplates/repository"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+var errMissingRepositoryPassword = errors.New("missing password")
+
+type templateRepositoryUpdatePayload struct {
+	// Name of the repository
+	Name string `example:"company-templates" validate:"required"`
+	// URL of the Git repository hosting the templates
+	RepositoryURL string `example:"https://github.com/portainer/templates" validate:"required"`
+	// Reference name of the Git repository
+	RepositoryReferenceName string `example:"refs/heads/main"`
+	// Use basic authentication to clone the Git repository
+	RepositoryAuthentication bool `example:"true"`
+	// Username used in basic authentication. Required when RepositoryAuthentication is true.
+	RepositoryUsername string `example:"myGitUsername"`
+	// Password used in basic authentication, the current password is kept when empty
+	RepositoryPassword string `example:"myGitPassword"`
+	// Path to the manifest inside the Git repository
+	ManifestPath string `example:"portainer-templates.json" default:"portainer-templates.json"`
+	// TLSSkipVerify skips SSL verification when cloning the Git repository
+	TLSSkipVerify bool `example:"false"`
+	// Interval between two synchronizations, scheduled synchronizations are disabled when empty
+	SyncInterval string `example:"1h"`
+}
+
+func (payload *templateRepositoryUpdatePayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.Name) {
+		return errors.New("Invalid repository name")
+	}
+	if govalidator.IsNull(payload.RepositoryURL) || !govalidator.IsURL(payload.RepositoryURL) {
+		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
+	}
+	if payload.RepositoryAuthentication && govalidator.IsNull(payload.RepositoryUsername) {
+		return errors.New("Invalid repository credentials. Username must be specified when authentication is enabled")
+	}
+	if govalidator.IsNull(payload.ManifestPath) {
+		payload.ManifestPath = repository.DefaultManifestPath
+	}
+
+	return validateSyncInterval(payload.SyncInterval)
+}
+
+// @id CustomTemplateRepositoryUpdate
+// @summary Update a template repository
+// @description Update a template repository. The changes are applied to the templates on the next synchronization.
+// @description **Access policy**: administrator
+// @tags custom_templates
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Template repository identifier"
+// @param body body templateRepositoryUpdatePayload true "Template repository details"
+// @success 200 {object} portainer.CustomTemplateRepository
+// @failure 400 "Invalid request"
+// @failure 404 "Template repository not found"
+// @failure 500 "Server error"
+// @router /custom_templates/repositories/{id} [put]
+func (handler *Handler) templateRepositoryUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	repositoryID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid template repository identifier route variable", err)
+	}
+
+	var payload templateRepositoryUpdatePayload
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	// the changes are applied by the template repository service so that a running synchronization does not overwrite them
+	templateRepository, err := handler.TemplateRepositoryService.Update(portainer.CustomTemplateRepositoryID(repositoryID), func(templateRepository *portainer.CustomTemplateRepository) error {
+		var authentication *gittypes.GitAuthentication
+		if payload.RepositoryAuthentication {
+			password := payload.RepositoryPassword
+			if password == "" && templateRepository.GitConfig.Authentication != nil {
+				password = templateRepository.GitConfig.Authentication.Password
+			}
+
+			if password == "" {
+				return errMissingRepositoryPassword
+			}
+
+			authentication = &gittypes.GitAuthentication{
+				Username: payload.RepositoryUsername,
+				Password: password,
+			}
+		}
+
+		templateRepository.Name = payload.Name
+		templateRepository.SyncInterval = payload.SyncInterval
+		templateRepository.GitConfig = &gittypes.RepoConfig{
+			URL:            payload.RepositoryURL,
+			ReferenceName:  payload.RepositoryReferenceName,
+			ConfigFilePath: payload.ManifestPath,
+			ConfigHash:     templateRepository.GitConfig.ConfigHash,
+			TLSSkipVerify:  payload.TLSSkipVerify,
+			Authentication: authentication,
+		}
+
+		return nil
+	})
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a template repository with the specified identifier inside the database", err)
+	} else if errors.Is(err, errMissingRepositoryPassword) {
+		return httperror.BadRequest("Invalid repository credentials. Password must be specified when authentication is enabled", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to update the template repository", err)
+	}
+
+	return response.JSON(w, hideTemplateRepositoryPassword(templateRepository))
+}
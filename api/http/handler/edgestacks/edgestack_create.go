@@ -1,10 +1,12 @@
 package edgestacks
 
 import (
+	"errors"
 	"net/http"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/git"
 	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/api/http/security"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
@@ -35,6 +37,10 @@ func (handler *Handler) edgeStackCreate(w http.ResponseWriter, r *http.Request)
 			return httperror.BadRequest("Invalid payload", err)
 		case httperrors.IsConflictError(err):
 			return httperror.NewError(http.StatusConflict, err.Error(), err)
+		case errors.Is(err, git.ErrSSHKeyAccessDenied):
+			return httperror.Forbidden("Unable to use the SSH key", err)
+		case errors.Is(err, git.ErrGitCredentialAccessDenied):
+			return httperror.Forbidden("Unable to use the git credential", err)
 		default:
 			return httperror.InternalServerError("Unable to create Edge stack", err)
 		}
@@ -7,8 +7,10 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/git"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 
 	"github.com/asaskevich/govalidator"
@@ -28,6 +30,10 @@ type edgeStackFromGitRepositoryPayload struct {
 	RepositoryUsername string `example:"myGitUsername"`
 	// Password used in basic authentication. Required when RepositoryAuthentication is true.
 	RepositoryPassword string `example:"myGitPassword"`
+	// Identifier of the SSH key used to authenticate. Used instead of RepositoryPassword when not 0
+	RepositorySSHKeyID int `example:"0"`
+	// Identifier of the shared git credential used to authenticate. Used instead of RepositoryUsername and RepositoryPassword when not 0
+	RepositoryGitCredentialID int `example:"0"`
 	// Path to the Stack file inside the Git repository
 	FilePathInRepository string `example:"docker-compose.yml" default:"docker-compose.yml"`
 	// List of identifiers of EdgeGroups
@@ -43,6 +49,8 @@ type edgeStackFromGitRepositoryPayload struct {
 	UseManifestNamespaces bool
 	// TLSSkipVerify skips SSL verification when cloning the Git repository
 	TLSSkipVerify bool `example:"false"`
+	// Submodules and LFS objects fetched with the repository, only the files of the repository are fetched when not specified
+	RepositoryContent *gittypes.ContentOptions
 }
 
 func (payload *edgeStackFromGitRepositoryPayload) Validate(r *http.Request) error {
@@ -50,12 +58,16 @@ func (payload *edgeStackFromGitRepositoryPayload) Validate(r *http.Request) erro
 		return httperrors.NewInvalidPayloadError("Invalid stack name")
 	}
 
-	if govalidator.IsNull(payload.RepositoryURL) || !govalidator.IsURL(payload.RepositoryURL) {
+	if !git.IsValidRepositoryURL(payload.RepositoryURL) {
 		return httperrors.NewInvalidPayloadError("Invalid repository URL. Must correspond to a valid URL format")
 	}
 
-	if payload.RepositoryAuthentication && govalidator.IsNull(payload.RepositoryPassword) {
-		return httperrors.NewInvalidPayloadError("Invalid repository credentials. Password must be specified when authentication is enabled")
+	if payload.RepositoryAuthentication && govalidator.IsNull(payload.RepositoryPassword) && payload.RepositorySSHKeyID == 0 && payload.RepositoryGitCredentialID == 0 {
+		return httperrors.NewInvalidPayloadError("Invalid repository credentials. Password, SSH key or git credential must be specified when authentication is enabled")
+	}
+
+	if err := git.ValidateContentOptions(payload.RepositoryContent); err != nil {
+		return err
 	}
 
 	if payload.DeploymentType != portainer.EdgeStackDeploymentCompose && payload.DeploymentType != portainer.EdgeStackDeploymentKubernetes {
@@ -115,11 +127,39 @@ func (handler *Handler) createEdgeStackFromGitRepository(r *http.Request, tx dat
 		ConfigFilePath: payload.FilePathInRepository,
 		TLSSkipVerify:  payload.TLSSkipVerify,
 	}
+	repoConfig.SetContentOptions(payload.RepositoryContent)
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve info from request context")
+	}
+
+	err = git.ValidateContentSSHKeyAccess(handler.DataStore.GitSSHKey(), payload.RepositoryContent, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = git.ValidateContentCredentialAccess(handler.DataStore.GitCredential(), payload.RepositoryContent, userID, securityContext.UserMemberships, securityContext.IsAdmin)
+	if err != nil {
+		return nil, err
+	}
 
 	if payload.RepositoryAuthentication {
+		err = git.ValidateSSHKeyAccess(handler.DataStore.GitSSHKey(), payload.RepositorySSHKeyID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = git.ValidateCredentialAccess(handler.DataStore.GitCredential(), payload.RepositoryGitCredentialID, userID, securityContext.UserMemberships, securityContext.IsAdmin)
+		if err != nil {
+			return nil, err
+		}
+
 		repoConfig.Authentication = &gittypes.GitAuthentication{
-			Username: payload.RepositoryUsername,
-			Password: payload.RepositoryPassword,
+			Username:        payload.RepositoryUsername,
+			Password:        payload.RepositoryPassword,
+			SSHKeyID:        payload.RepositorySSHKeyID,
+			GitCredentialID: payload.RepositoryGitCredentialID,
 		}
 	}
 
@@ -138,14 +178,7 @@ func (handler *Handler) storeManifestFromGitRepository(tx dataservices.DataStore
 	}
 
 	projectPath = handler.FileService.GetEdgeStackProjectPath(stackFolder)
-	repositoryUsername := ""
-	repositoryPassword := ""
-	if repositoryConfig.Authentication != nil && repositoryConfig.Authentication.Password != "" {
-		repositoryUsername = repositoryConfig.Authentication.Username
-		repositoryPassword = repositoryConfig.Authentication.Password
-	}
-
-	err = handler.GitService.CloneRepository(projectPath, repositoryConfig.URL, repositoryConfig.ReferenceName, repositoryUsername, repositoryPassword, repositoryConfig.TLSSkipVerify)
+	err = handler.GitService.CloneRepository(projectPath, repositoryConfig.URL, repositoryConfig.ReferenceName, repositoryConfig.Authentication, repositoryConfig.TLSSkipVerify, repositoryConfig.ContentOptions())
 	if err != nil {
 		return "", "", "", err
 	}
@@ -22,6 +22,8 @@ type endpointGroupCreatePayload struct {
 	AssociatedEndpoints []portainer.EndpointID `example:"1,3"`
 	// List of tag identifiers to which this environment(endpoint) group is associated
 	TagIDs []portainer.TagID `example:"1,2"`
+	// Whether the terminal sessions opened on the environments of this group are recorded
+	RecordSessions bool `example:"false"`
 }
 
 func (payload *endpointGroupCreatePayload) Validate(r *http.Request) error {
@@ -80,6 +82,7 @@ func (handler *Handler) createEndpointGroup(tx dataservices.DataStoreTx, payload
 		UserAccessPolicies: portainer.UserAccessPolicies{},
 		TeamAccessPolicies: portainer.TeamAccessPolicies{},
 		TagIDs:             payload.TagIDs,
+		RecordSessions:     payload.RecordSessions,
 	}
 
 	err := tx.EndpointGroup().Create(endpointGroup)
@@ -3,6 +3,7 @@ package endpointgroups
 import (
 	"errors"
 	"net/http"
+	"slices"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
@@ -46,9 +47,39 @@ func (handler *Handler) endpointGroupDelete(w http.ResponseWriter, r *http.Reque
 		return httperror.InternalServerError("Unexpected error", err)
 	}
 
+	err = handler.removeEndpointGroupFromPolicies(portainer.EndpointGroupID(endpointGroupID))
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist policy changes inside the database", err)
+	}
+
 	return response.Empty(w)
 }
 
+// removeEndpointGroupFromPolicies detaches the policies from a removed environment group
+func (handler *Handler) removeEndpointGroupFromPolicies(endpointGroupID portainer.EndpointGroupID) error {
+	policies, err := handler.DataStore.Policy().ReadAll()
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		if !slices.Contains(policy.EndpointGroupIDs, endpointGroupID) {
+			continue
+		}
+
+		policy.EndpointGroupIDs = slices.DeleteFunc(policy.EndpointGroupIDs, func(id portainer.EndpointGroupID) bool {
+			return id == endpointGroupID
+		})
+
+		err = handler.DataStore.Policy().Update(policy.ID, &policy)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (handler *Handler) deleteEndpointGroup(tx dataservices.DataStoreTx, endpointGroupID portainer.EndpointGroupID) error {
 	endpointGroup, err := tx.EndpointGroup().Read(endpointGroupID)
 	if tx.IsErrObjectNotFound(err) {
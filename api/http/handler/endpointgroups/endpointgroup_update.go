@@ -24,6 +24,8 @@ type endpointGroupUpdatePayload struct {
 	TagIDs             []portainer.TagID `example:"3,4"`
 	UserAccessPolicies portainer.UserAccessPolicies
 	TeamAccessPolicies portainer.TeamAccessPolicies
+	// Whether the terminal sessions opened on the environments of this group are recorded
+	RecordSessions *bool `example:"false"`
 }
 
 func (payload *endpointGroupUpdatePayload) Validate(r *http.Request) error {
@@ -91,6 +93,10 @@ func (handler *Handler) updateEndpointGroup(tx dataservices.DataStoreTx, endpoin
 		endpointGroup.Description = payload.Description
 	}
 
+	if payload.RecordSessions != nil {
+		endpointGroup.RecordSessions = *payload.RecordSessions
+	}
+
 	tagsChanged := false
 	if payload.TagIDs != nil {
 		payloadTagSet := tag.Set(payload.TagIDs)
@@ -83,6 +83,11 @@ func (handler *Handler) deleteEndpoint(tx dataservices.DataStoreTx, endpointID p
 		log.Warn().Err(err).Msgf("Unable to remove the snapshot from the database")
 	}
 
+	err = tx.ResourceUsageSample().DeleteByEndpointID(endpointID)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Unable to remove the resource usage samples from the database")
+	}
+
 	handler.ProxyManager.DeleteEndpointProxy(endpoint.ID)
 
 	if len(endpoint.UserAccessPolicies) > 0 || len(endpoint.TeamAccessPolicies) > 0 {
@@ -0,0 +1,176 @@
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/endpointutils"
+	"github.com/portainer/portainer/api/resourceusage"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+const (
+	defaultResourceUsageWindow = time.Hour
+	defaultTopConsumersLimit   = 10
+)
+
+// @id EndpointResourceUsage
+// @summary Retrieve the resource usage history of an environment(endpoint)
+// @description Retrieve the CPU, memory and network usage of a Docker environment, of one of its stacks or of one of its containers over time.
+// @description The total of the environment is returned when neither stack nor container is specified.
+// @description **Access policy**: administrator
+// @tags endpoints
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment(Endpoint) identifier"
+// @param stack query string false "Only return the usage of this compose or Swarm stack"
+// @param container query string false "Only return the usage of this container, identified by name or identifier"
+// @param since query int false "Unix timestamp of the start of the window, defaults to one hour ago"
+// @param until query int false "Unix timestamp of the end of the window, defaults to now"
+// @success 200 {array} resourceusage.Point "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Environment(Endpoint) not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/resource_usage [get]
+func (handler *Handler) endpointResourceUsage(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, httpErr := handler.resourceUsageEndpoint(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	since, until, httpErr := resourceUsageWindow(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	stack, _ := request.RetrieveQueryParameter(r, "stack", true)
+	container, _ := request.RetrieveQueryParameter(r, "container", true)
+
+	if stack != "" && container != "" {
+		return httperror.BadRequest("Invalid query parameters", errors.New("stack and container cannot be used together"))
+	}
+
+	points, err := handler.ResourceUsageService.TimeSeries(endpoint.ID, resourceusage.TimeSeriesFilter{
+		Stack:     stack,
+		Container: container,
+		Since:     since,
+		Until:     until,
+	})
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the resource usage of the environment", err)
+	}
+
+	return response.JSON(w, points)
+}
+
+// @id EndpointResourceUsageTop
+// @summary Retrieve the top resource consumers of an environment(endpoint)
+// @description Retrieve the stacks or the containers of a Docker environment with the highest average usage of a metric since the given time.
+// @description **Access policy**: administrator
+// @tags endpoints
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment(Endpoint) identifier"
+// @param type query string false "Type of consumer, defaults to container" Enums(stack, container)
+// @param metric query string false "Metric used to sort the consumers, defaults to cpu" Enums(cpu, memory, network)
+// @param limit query int false "Maximum number of consumers, defaults to 10"
+// @param since query int false "Unix timestamp of the start of the window, defaults to one hour ago"
+// @success 200 {array} resourceusage.Consumer "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Environment(Endpoint) not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/resource_usage/top [get]
+func (handler *Handler) endpointResourceUsageTop(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, httpErr := handler.resourceUsageEndpoint(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	since, _, httpErr := resourceUsageWindow(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	consumerType, _ := request.RetrieveQueryParameter(r, "type", true)
+	if consumerType == "" {
+		consumerType = resourceusage.ConsumerTypeContainer
+	}
+
+	metric, _ := request.RetrieveQueryParameter(r, "metric", true)
+	if metric == "" {
+		metric = resourceusage.MetricCPU
+	}
+
+	limit, err := request.RetrieveNumericQueryParameter(r, "limit", true)
+	if err != nil || limit < 0 {
+		return httperror.BadRequest("Invalid query parameter: limit", err)
+	}
+
+	if limit == 0 {
+		limit = defaultTopConsumersLimit
+	}
+
+	consumers, err := handler.ResourceUsageService.TopConsumers(endpoint.ID, consumerType, metric, limit, since)
+	if errors.Is(err, resourceusage.ErrInvalidConsumerType) || errors.Is(err, resourceusage.ErrInvalidMetric) {
+		return httperror.BadRequest("Invalid query parameters", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the top resource consumers of the environment", err)
+	}
+
+	return response.JSON(w, consumers)
+}
+
+func (handler *Handler) resourceUsageEndpoint(r *http.Request) (*portainer.Endpoint, *httperror.HandlerError) {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid environment identifier route variable", err)
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find an environment with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find an environment with the specified identifier inside the database", err)
+	}
+
+	if !endpointutils.IsDockerEndpoint(endpoint) {
+		return nil, httperror.BadRequest("Resource usage is only recorded for Docker environments", errors.New("resource usage is only recorded for Docker environments"))
+	}
+
+	return endpoint, nil
+}
+
+func resourceUsageWindow(r *http.Request) (time.Time, time.Time, *httperror.HandlerError) {
+	until := time.Now()
+	since := until.Add(-defaultResourceUsageWindow)
+
+	sinceParam, err := request.RetrieveNumericQueryParameter(r, "since", true)
+	if err != nil {
+		return since, until, httperror.BadRequest("Invalid query parameter: since", err)
+	}
+
+	untilParam, err := request.RetrieveNumericQueryParameter(r, "until", true)
+	if err != nil {
+		return since, until, httperror.BadRequest("Invalid query parameter: until", err)
+	}
+
+	if sinceParam != 0 {
+		since = time.Unix(int64(sinceParam), 0)
+	}
+
+	if untilParam != 0 {
+		until = time.Unix(int64(untilParam), 0)
+	}
+
+	if since.After(until) {
+		return since, until, httperror.BadRequest("Invalid query parameters", errors.New("since must be before until"))
+	}
+
+	return since, until, nil
+}
@@ -0,0 +1,153 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+	"github.com/portainer/portainer/api/resourceusage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const resourceUsageTestTime = 1700000000
+
+func setupResourceUsageHandler(t *testing.T) *Handler {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	is.NoError(store.Endpoint().Create(&portainer.Endpoint{ID: 1, Name: "docker", Type: portainer.DockerEnvironment}))
+	is.NoError(store.Endpoint().Create(&portainer.Endpoint{ID: 2, Name: "kubernetes", Type: portainer.KubernetesLocalEnvironment}))
+
+	containers := []portainer.ContainerResourceUsage{
+		{ResourceUsage: portainer.ResourceUsage{CPUPercent: 10, MemoryUsage: 100}, ID: "a", Name: "web", Stack: "app"},
+		{ResourceUsage: portainer.ResourceUsage{CPUPercent: 30, MemoryUsage: 300}, ID: "b", Name: "db", Stack: "data"},
+	}
+
+	for _, at := range []int64{resourceUsageTestTime - 120, resourceUsageTestTime - 60, resourceUsageTestTime} {
+		is.NoError(store.ResourceUsageSample().Create(&portainer.ResourceUsageSample{
+			EndpointID: 1,
+			Time:       at,
+			Stacks: []portainer.StackResourceUsage{
+				{ResourceUsage: containers[0].ResourceUsage, Name: "app", ContainerCount: 1},
+				{ResourceUsage: containers[1].ResourceUsage, Name: "data", ContainerCount: 1},
+			},
+			Containers: containers,
+			Total:      portainer.ResourceUsage{CPUPercent: 40, MemoryUsage: 400},
+		}))
+	}
+
+	handler := NewHandler(testhelpers.NewTestRequestBouncer(), nil)
+	handler.DataStore = store
+	handler.ResourceUsageService = resourceusage.NewService(store, nil)
+
+	return handler
+}
+
+func doResourceUsageRequest(handler *Handler, url string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func Test_endpointResourceUsage(t *testing.T) {
+	handler := setupResourceUsageHandler(t)
+
+	t.Run("returns the total of the environment in the window", func(t *testing.T) {
+		is := assert.New(t)
+
+		rr := doResourceUsageRequest(handler, fmt.Sprintf("/endpoints/1/resource_usage?since=%d&until=%d", resourceUsageTestTime-60, resourceUsageTestTime))
+		is.Equal(http.StatusOK, rr.Code)
+
+		var points []resourceusage.Point
+		is.NoError(json.NewDecoder(rr.Body).Decode(&points))
+		if is.Len(points, 2) {
+			is.Equal(int64(resourceUsageTestTime-60), points[0].Time)
+			is.Equal(uint64(400), points[0].MemoryUsage)
+		}
+	})
+
+	t.Run("returns the usage of a stack", func(t *testing.T) {
+		is := assert.New(t)
+
+		rr := doResourceUsageRequest(handler, fmt.Sprintf("/endpoints/1/resource_usage?stack=data&since=%d&until=%d", resourceUsageTestTime-120, resourceUsageTestTime))
+		is.Equal(http.StatusOK, rr.Code)
+
+		var points []resourceusage.Point
+		is.NoError(json.NewDecoder(rr.Body).Decode(&points))
+		if is.Len(points, 3) {
+			is.Equal(uint64(300), points[0].MemoryUsage)
+		}
+	})
+
+	t.Run("refuses the invalid requests", func(t *testing.T) {
+		is := assert.New(t)
+
+		rr := doResourceUsageRequest(handler, "/endpoints/1/resource_usage?stack=app&container=web")
+		is.Equal(http.StatusBadRequest, rr.Code, "stack and container cannot be used together")
+
+		rr = doResourceUsageRequest(handler, fmt.Sprintf("/endpoints/1/resource_usage?since=%d&until=%d", resourceUsageTestTime, resourceUsageTestTime-60))
+		is.Equal(http.StatusBadRequest, rr.Code, "since must be before until")
+
+		rr = doResourceUsageRequest(handler, "/endpoints/2/resource_usage")
+		is.Equal(http.StatusBadRequest, rr.Code, "the usage is only recorded for the Docker environments")
+
+		rr = doResourceUsageRequest(handler, "/endpoints/3/resource_usage")
+		is.Equal(http.StatusNotFound, rr.Code)
+	})
+}
+
+func Test_endpointResourceUsageTop(t *testing.T) {
+	handler := setupResourceUsageHandler(t)
+
+	t.Run("returns the containers with the highest usage", func(t *testing.T) {
+		is := assert.New(t)
+
+		rr := doResourceUsageRequest(handler, fmt.Sprintf("/endpoints/1/resource_usage/top?metric=memory&limit=1&since=%d", resourceUsageTestTime-120))
+		is.Equal(http.StatusOK, rr.Code)
+
+		var consumers []resourceusage.Consumer
+		is.NoError(json.NewDecoder(rr.Body).Decode(&consumers))
+		if is.Len(consumers, 1) {
+			is.Equal("db", consumers[0].Name)
+			is.Equal("data", consumers[0].Stack)
+			is.Equal(3, consumers[0].Samples)
+		}
+	})
+
+	t.Run("returns the stacks with the highest usage", func(t *testing.T) {
+		is := assert.New(t)
+
+		rr := doResourceUsageRequest(handler, fmt.Sprintf("/endpoints/1/resource_usage/top?type=stack&since=%d", resourceUsageTestTime-120))
+		is.Equal(http.StatusOK, rr.Code)
+
+		var consumers []resourceusage.Consumer
+		is.NoError(json.NewDecoder(rr.Body).Decode(&consumers))
+		if is.Len(consumers, 2) {
+			is.Equal("data", consumers[0].Name)
+			is.Equal("app", consumers[1].Name)
+		}
+	})
+
+	t.Run("refuses the invalid requests", func(t *testing.T) {
+		is := assert.New(t)
+
+		rr := doResourceUsageRequest(handler, "/endpoints/1/resource_usage/top?type=volume")
+		is.Equal(http.StatusBadRequest, rr.Code)
+
+		rr = doResourceUsageRequest(handler, "/endpoints/1/resource_usage/top?metric=disk")
+		is.Equal(http.StatusBadRequest, rr.Code)
+
+		rr = doResourceUsageRequest(handler, "/endpoints/1/resource_usage/top?limit=-1")
+		is.Equal(http.StatusBadRequest, rr.Code)
+	})
+}
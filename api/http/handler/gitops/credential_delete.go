@@ -0,0 +1,58 @@
+package gitops
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id GitOpsCredentialDelete
+// @summary Remove a shared git credential
+// @description Remove a git credential, the credentials still referenced by a stack or a custom template cannot be removed.
+// @description Only the owner of the credential or an administrator can remove it.
+// @description **Access policy**: restricted
+// @tags gitops
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Git credential identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 409 "The credential is in use"
+// @failure 500 "Server error"
+// @router /gitops/credentials/{id} [delete]
+func (handler *Handler) credentialDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	credential, httpErr := handler.readManagedGitCredential(r, securityContext)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	consumers, err := handler.gitCredentialConsumers()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the resources referencing the git credentials", err)
+	}
+
+	if count := len(consumers[credential.ID]); count > 0 {
+		return &httperror.HandlerError{
+			StatusCode: http.StatusConflict,
+			Message:    "The git credential is in use",
+			Err:        fmt.Errorf("the git credential is referenced by %d stacks or templates", count),
+		}
+	}
+
+	err = handler.dataStore.GitCredential().Delete(credential.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the git credential from the database", err)
+	}
+
+	return response.Empty(w)
+}
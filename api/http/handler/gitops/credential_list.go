@@ -0,0 +1,133 @@
+package gitops
+
+import (
+	"net/http"
+	"slices"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+const (
+	gitCredentialConsumerStack          = "stack"
+	gitCredentialConsumerCustomTemplate = "custom_template"
+)
+
+type gitCredentialConsumer struct {
+	// Type of the resource, stack or custom_template
+	Type string `json:"Type" example:"stack" enums:"stack,custom_template"`
+	// Identifier of the resource
+	ID int `json:"Id" example:"1"`
+	// Name of the stack or title of the custom template
+	Name string `json:"Name" example:"nginx"`
+}
+
+type gitCredentialResponse struct {
+	portainer.GitCredential
+	// Resources whose git repository references the credential
+	Consumers []gitCredentialConsumer `json:"Consumers"`
+}
+
+// @id GitOpsCredentialList
+// @summary List the shared git credentials
+// @description List the git credentials that can be used by the calling user, with the stacks and custom templates referencing them.
+// @description The secrets of the credentials are never returned.
+// @description **Access policy**: restricted
+// @tags gitops
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {array} gitCredentialResponse "Success"
+// @failure 500 "Server error"
+// @router /gitops/credentials [get]
+func (handler *Handler) credentialList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	credentials, err := handler.dataStore.GitCredential().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the git credentials from the database", err)
+	}
+
+	consumers, err := handler.gitCredentialConsumers()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the resources referencing the git credentials", err)
+	}
+
+	responses := make([]gitCredentialResponse, 0, len(credentials))
+	for _, credential := range credentials {
+		if !git.CanUseCredential(&credential, securityContext.UserID, securityContext.UserMemberships, securityContext.IsAdmin) {
+			continue
+		}
+
+		hideGitCredentialFields(&credential)
+
+		responses = append(responses, gitCredentialResponse{
+			GitCredential: credential,
+			Consumers:     append([]gitCredentialConsumer{}, consumers[credential.ID]...),
+		})
+	}
+
+	return response.JSON(w, responses)
+}
+
+// gitCredentialConsumers returns the stacks and custom templates referencing each git credential.
+// The edge stacks are not listed as their repository is only cloned when they are created
+func (handler *Handler) gitCredentialConsumers() (map[portainer.GitCredentialID][]gitCredentialConsumer, error) {
+	consumers := make(map[portainer.GitCredentialID][]gitCredentialConsumer)
+
+	add := func(config *gittypes.RepoConfig, consumer gitCredentialConsumer) {
+		for _, credentialID := range repositoryCredentialIDs(config) {
+			consumers[credentialID] = append(consumers[credentialID], consumer)
+		}
+	}
+
+	stacks, err := handler.dataStore.Stack().ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stack := range stacks {
+		add(stack.GitConfig, gitCredentialConsumer{Type: gitCredentialConsumerStack, ID: int(stack.ID), Name: stack.Name})
+	}
+
+	customTemplates, err := handler.dataStore.CustomTemplate().ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, customTemplate := range customTemplates {
+		add(customTemplate.GitConfig, gitCredentialConsumer{Type: gitCredentialConsumerCustomTemplate, ID: int(customTemplate.ID), Name: customTemplate.Title})
+	}
+
+	return consumers, nil
+}
+
+// repositoryCredentialIDs returns the git credentials referenced by the authentications of a repository and of its submodules
+func repositoryCredentialIDs(config *gittypes.RepoConfig) []portainer.GitCredentialID {
+	if config == nil {
+		return nil
+	}
+
+	var credentialIDs []portainer.GitCredentialID
+	for _, auth := range []*gittypes.GitAuthentication{config.Authentication, config.SubmoduleAuthentication} {
+		if auth != nil && auth.GitCredentialID != 0 && !slices.Contains(credentialIDs, portainer.GitCredentialID(auth.GitCredentialID)) {
+			credentialIDs = append(credentialIDs, portainer.GitCredentialID(auth.GitCredentialID))
+		}
+	}
+
+	return credentialIDs
+}
+
+// hideGitCredentialFields removes the secrets of the credential before it is returned by the API
+func hideGitCredentialFields(credential *portainer.GitCredential) {
+	credential.Password = ""
+	credential.PrivateKey = ""
+	credential.Passphrase = ""
+}
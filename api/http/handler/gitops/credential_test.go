@@ -0,0 +1,196 @@
+package gitops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/apikey"
+	"github.com/portainer/portainer/api/datastore"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/jwt"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_gitCredentials(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, true)
+
+	adminUser := &portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole}
+	is.NoError(store.User().Create(adminUser))
+
+	owner := &portainer.User{ID: 2, Username: "owner", Role: portainer.StandardUserRole}
+	is.NoError(store.User().Create(owner))
+
+	member := &portainer.User{ID: 3, Username: "member", Role: portainer.StandardUserRole}
+	is.NoError(store.User().Create(member))
+
+	outsider := &portainer.User{ID: 4, Username: "outsider", Role: portainer.StandardUserRole}
+	is.NoError(store.User().Create(outsider))
+
+	team := &portainer.Team{ID: 1, Name: "developers"}
+	is.NoError(store.Team().Create(team))
+
+	otherTeam := &portainer.Team{ID: 2, Name: "operators"}
+	is.NoError(store.Team().Create(otherTeam))
+
+	for _, userID := range []portainer.UserID{owner.ID, member.ID} {
+		is.NoError(store.TeamMembership().Create(&portainer.TeamMembership{UserID: userID, TeamID: team.ID, Role: portainer.TeamMember}))
+	}
+
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+
+	h := NewHandler(requestBouncer, store, nil, nil)
+
+	tokens := make(map[portainer.UserID]string)
+	for _, user := range []*portainer.User{adminUser, owner, member, outsider} {
+		tokens[user.ID], _ = jwtService.GenerateToken(&portainer.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+	}
+
+	doRequest := func(method, url string, userID portainer.UserID, payload any) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if payload != nil {
+			is.NoError(json.NewEncoder(&body).Encode(payload))
+		}
+
+		req := httptest.NewRequest(method, url, &body)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", tokens[userID]))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	listCredentials := func(userID portainer.UserID) []gitCredentialResponse {
+		rr := doRequest(http.MethodGet, "/gitops/credentials", userID, nil)
+		is.Equal(http.StatusOK, rr.Code)
+
+		var credentials []gitCredentialResponse
+		is.NoError(json.NewDecoder(rr.Body).Decode(&credentials))
+
+		return credentials
+	}
+
+	var created portainer.GitCredential
+
+	t.Run("standard user can create a credential shared with their team", func(t *testing.T) {
+		rr := doRequest(http.MethodPost, "/gitops/credentials", owner.ID, credentialCreatePayload{
+			Name:     "github-ci",
+			Type:     portainer.GitCredentialTypeBasic,
+			TeamIDs:  []portainer.TeamID{team.ID},
+			Username: "ci",
+			Password: "secret",
+		})
+
+		is.Equal(http.StatusCreated, rr.Code)
+		is.NotContains(rr.Body.String(), "secret")
+
+		is.NoError(json.NewDecoder(rr.Body).Decode(&created))
+		is.Equal(owner.ID, created.UserID)
+		is.NotZero(created.RotationDate)
+
+		stored, err := store.GitCredential().Read(created.ID)
+		is.NoError(err)
+		is.Equal("secret", stored.Password)
+	})
+
+	t.Run("standard user cannot share a credential with another team", func(t *testing.T) {
+		rr := doRequest(http.MethodPost, "/gitops/credentials", owner.ID, credentialCreatePayload{
+			Name:     "shared",
+			Type:     portainer.GitCredentialTypeToken,
+			TeamIDs:  []portainer.TeamID{otherTeam.ID},
+			Password: "token",
+		})
+
+		is.Equal(http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("invalid credentials are refused", func(t *testing.T) {
+		for _, payload := range []credentialCreatePayload{
+			{Name: "no-password", Type: portainer.GitCredentialTypeBasic, Username: "ci"},
+			{Name: "no-token", Type: portainer.GitCredentialTypeToken},
+			{Name: "no-known-hosts", Type: portainer.GitCredentialTypeSSH},
+			{Name: "unknown", Type: "oauth", Password: "token"},
+		} {
+			rr := doRequest(http.MethodPost, "/gitops/credentials", owner.ID, payload)
+			is.Equal(http.StatusBadRequest, rr.Code, payload.Name)
+		}
+	})
+
+	t.Run("ssh credential key pair is generated", func(t *testing.T) {
+		rr := doRequest(http.MethodPost, "/gitops/credentials", adminUser.ID, credentialCreatePayload{
+			Name:       "deploy-key",
+			Type:       portainer.GitCredentialTypeSSH,
+			KnownHosts: "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl",
+		})
+
+		is.Equal(http.StatusCreated, rr.Code)
+		is.NotContains(rr.Body.String(), "PrivateKey")
+
+		var credential portainer.GitCredential
+		is.NoError(json.NewDecoder(rr.Body).Decode(&credential))
+		is.Contains(credential.PublicKey, "ssh-ed25519 ")
+		is.NotEmpty(credential.Fingerprint)
+	})
+
+	t.Run("credentials are listed with the resources referencing them", func(t *testing.T) {
+		is.NoError(store.Stack().Create(&portainer.Stack{
+			ID:        1,
+			Name:      "nginx",
+			GitConfig: &gittypes.RepoConfig{URL: "https://github.com/portainer/portainer.git", Authentication: &gittypes.GitAuthentication{GitCredentialID: int(created.ID)}},
+		}))
+
+		credentials := listCredentials(member.ID)
+		if is.Len(credentials, 1) {
+			is.Equal(created.ID, credentials[0].ID)
+			is.Empty(credentials[0].Password)
+			is.Equal([]gitCredentialConsumer{{Type: gitCredentialConsumerStack, ID: 1, Name: "nginx"}}, credentials[0].Consumers)
+		}
+
+		is.Empty(listCredentials(outsider.ID))
+		is.Len(listCredentials(adminUser.ID), 2)
+	})
+
+	t.Run("only the owner and the administrators can rotate the credential", func(t *testing.T) {
+		rr := doRequest(http.MethodPut, fmt.Sprintf("/gitops/credentials/%d", created.ID), member.ID, credentialUpdatePayload{Password: "rotated"})
+		is.Equal(http.StatusForbidden, rr.Code)
+
+		rr = doRequest(http.MethodPut, fmt.Sprintf("/gitops/credentials/%d", created.ID), owner.ID, credentialUpdatePayload{Password: "rotated"})
+		is.Equal(http.StatusOK, rr.Code)
+
+		stored, err := store.GitCredential().Read(created.ID)
+		is.NoError(err)
+		is.Equal("rotated", stored.Password)
+		is.Equal("ci", stored.Username)
+
+		rr = doRequest(http.MethodPut, fmt.Sprintf("/gitops/credentials/%d", created.ID), owner.ID, credentialUpdatePayload{RegenerateKey: true})
+		is.Equal(http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("credentials in use cannot be removed", func(t *testing.T) {
+		rr := doRequest(http.MethodDelete, fmt.Sprintf("/gitops/credentials/%d", created.ID), owner.ID, nil)
+		is.Equal(http.StatusConflict, rr.Code)
+
+		is.NoError(store.Stack().Delete(1))
+
+		rr = doRequest(http.MethodDelete, fmt.Sprintf("/gitops/credentials/%d", created.ID), outsider.ID, nil)
+		is.Equal(http.StatusForbidden, rr.Code)
+
+		rr = doRequest(http.MethodDelete, fmt.Sprintf("/gitops/credentials/%d", created.ID), owner.ID, nil)
+		is.Equal(http.StatusNoContent, rr.Code)
+
+		_, err := store.GitCredential().Read(created.ID)
+		is.True(store.IsErrObjectNotFound(err))
+	})
+}
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"net/http"
 
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
 	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/kubernetes/kustomize"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
@@ -22,14 +26,20 @@ type repositoryFilePreviewPayload struct {
 	Reference  string `json:"reference" example:"refs/heads/master"`
 	Username   string `json:"username" example:"myGitUsername"`
 	Password   string `json:"password" example:"myGitPassword"`
+	// Identifier of the SSH key used to authenticate, used instead of the username and password when not 0
+	SSHKeyID int `json:"sshKeyID" example:"0"`
 	// Path to file whose content will be read
 	TargetFile string `json:"targetFile" example:"docker-compose.yml"`
 	// TLSSkipVerify skips SSL verification when cloning the Git repository
 	TLSSkipVerify bool `example:"false"`
+	// Path of a kustomization directory. When set, the rendered kustomization is previewed instead of the target file
+	KustomizationPath string `json:"kustomizationPath" example:"deploy"`
+	// Overlay to render, relative to the kustomization directory
+	KustomizationOverlay string `json:"kustomizationOverlay" example:"overlays/production"`
 }
 
 func (payload *repositoryFilePreviewPayload) Validate(r *http.Request) error {
-	if govalidator.IsNull(payload.Repository) || !govalidator.IsURL(payload.Repository) {
+	if !git.IsValidRepositoryURL(payload.Repository) {
 		return errors.New("invalid repository URL. Must correspond to a valid URL format")
 	}
 
@@ -37,16 +47,21 @@ func (payload *repositoryFilePreviewPayload) Validate(r *http.Request) error {
 		payload.Reference = "refs/heads/main"
 	}
 
-	if govalidator.IsNull(payload.TargetFile) {
+	if govalidator.IsNull(payload.TargetFile) && !payload.isKustomization() {
 		return errors.New("invalid target filename")
 	}
 
 	return nil
 }
 
+func (payload *repositoryFilePreviewPayload) isKustomization() bool {
+	return payload.KustomizationPath != "" || payload.KustomizationOverlay != ""
+}
+
 // @id GitOperationRepoFilePreview
 // @summary preview the content of target file in the git repository
 // @description Retrieve the compose file content based on git repository configuration
+// @description When a kustomization directory is specified, the manifest rendered by kustomize is retrieved instead
 // @description **Access policy**: authenticated
 // @tags gitops
 // @security ApiKeyAuth
@@ -55,6 +70,7 @@ func (payload *repositoryFilePreviewPayload) Validate(r *http.Request) error {
 // @param body body repositoryFilePreviewPayload true "Template details"
 // @success 200 {object} fileResponse "Success"
 // @failure 400 "Invalid request"
+// @failure 403 "Permission denied to use the SSH key"
 // @failure 500 "Server error"
 // @router /gitops/repo/file/preview [post]
 func (handler *Handler) gitOperationRepoFilePreview(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
@@ -64,12 +80,28 @@ func (handler *Handler) gitOperationRepoFilePreview(w http.ResponseWriter, r *ht
 		return httperror.BadRequest("Invalid request payload", err)
 	}
 
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user details from authentication token", err)
+	}
+
+	err = git.ValidateSSHKeyAccess(handler.dataStore.GitSSHKey(), payload.SSHKeyID, tokenData.ID)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the SSH key", err)
+	}
+
 	projectPath, err := handler.fileService.GetTemporaryPath()
 	if err != nil {
 		return httperror.InternalServerError("Unable to create temporary folder", err)
 	}
 
-	err = handler.gitService.CloneRepository(projectPath, payload.Repository, payload.Reference, payload.Username, payload.Password, payload.TLSSkipVerify)
+	auth := &gittypes.GitAuthentication{
+		Username: payload.Username,
+		Password: payload.Password,
+		SSHKeyID: payload.SSHKeyID,
+	}
+
+	err = handler.gitService.CloneRepository(projectPath, payload.Repository, payload.Reference, auth, payload.TLSSkipVerify, nil)
 	if err != nil {
 		if errors.Is(err, gittypes.ErrAuthenticationFailure) {
 			return httperror.BadRequest("Invalid git credential", err)
@@ -81,6 +113,18 @@ func (handler *Handler) gitOperationRepoFilePreview(w http.ResponseWriter, r *ht
 
 	defer handler.fileService.RemoveDirectory(projectPath)
 
+	if payload.isKustomization() {
+		fileContent, err := kustomize.Render(projectPath, portainer.KubernetesKustomization{
+			Path:    payload.KustomizationPath,
+			Overlay: payload.KustomizationOverlay,
+		})
+		if err != nil {
+			return httperror.BadRequest("Unable to render the kustomization", err)
+		}
+
+		return response.JSON(w, &fileResponse{FileContent: string(fileContent)})
+	}
+
 	fileContent, err := handler.fileService.GetFileContent(projectPath, payload.TargetFile)
 	if err != nil {
 		return httperror.InternalServerError("Unable to retrieve custom template file from disk", err)
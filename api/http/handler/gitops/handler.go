@@ -29,6 +29,20 @@ func NewHandler(bouncer security.BouncerService, dataStore dataservices.DataStor
 
 	h.Handle("/gitops/repo/file/preview",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.gitOperationRepoFilePreview))).Methods(http.MethodPost)
+	h.Handle("/gitops/signing_keys",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.signingKeyList))).Methods(http.MethodGet)
+	h.Handle("/gitops/signing_keys",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.signingKeyCreate))).Methods(http.MethodPost)
+	h.Handle("/gitops/signing_keys/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.signingKeyDelete))).Methods(http.MethodDelete)
+	h.Handle("/gitops/credentials",
+		bouncer.RestrictedAccess(httperror.LoggerHandler(h.credentialList))).Methods(http.MethodGet)
+	h.Handle("/gitops/credentials",
+		bouncer.RestrictedAccess(httperror.LoggerHandler(h.credentialCreate))).Methods(http.MethodPost)
+	h.Handle("/gitops/credentials/{id}",
+		bouncer.RestrictedAccess(httperror.LoggerHandler(h.credentialUpdate))).Methods(http.MethodPut)
+	h.Handle("/gitops/credentials/{id}",
+		bouncer.RestrictedAccess(httperror.LoggerHandler(h.credentialDelete))).Methods(http.MethodDelete)
 
 	return h
 }
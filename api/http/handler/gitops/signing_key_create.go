@@ -0,0 +1,93 @@
+package gitops
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/asaskevich/govalidator"
+)
+
+type signingKeyCreatePayload struct {
+	// Name of the key
+	Name string `validate:"required" example:"release-team"`
+	// Type of the key, gpg or ssh
+	Type portainer.GitSigningKeyType `validate:"required" example:"ssh" enums:"gpg,ssh"`
+	// Armored GPG public key or SSH public key in the authorized_keys format
+	PublicKey string `validate:"required" example:"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIPw..."`
+}
+
+func (payload *signingKeyCreatePayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.Name) {
+		return errors.New("invalid name. cannot be empty")
+	}
+	if payload.Type != portainer.GitSigningKeyTypeGPG && payload.Type != portainer.GitSigningKeyTypeSSH {
+		return errors.New("invalid type. must be gpg or ssh")
+	}
+	if strings.TrimSpace(payload.PublicKey) == "" {
+		return errors.New("invalid public key. cannot be empty")
+	}
+	return nil
+}
+
+// @id GitOpsSigningKeyCreate
+// @summary Add a key to the trusted signing keyring
+// @description Add a GPG or SSH public key to the keyring used to verify the commit signatures of the git repositories with a signature policy.
+// @description **Access policy**: administrator
+// @tags gitops
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param body body signingKeyCreatePayload true "details"
+// @success 201 {object} portainer.GitSigningKey "Created"
+// @failure 400 "Invalid request"
+// @failure 409 "The key is already in the keyring"
+// @failure 500 "Server error"
+// @router /gitops/signing_keys [post]
+func (handler *Handler) signingKeyCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload signingKeyCreatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	fingerprint, err := git.ParseSigningKey(payload.Type, payload.PublicKey)
+	if err != nil {
+		return httperror.BadRequest("Invalid signing key", err)
+	}
+
+	keys, err := handler.dataStore.GitSigningKey().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the signing keys from the database", err)
+	}
+
+	for _, key := range keys {
+		if key.Fingerprint == fingerprint {
+			return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: "The key is already in the keyring", Err: errors.New("duplicate signing key")}
+		}
+	}
+
+	key := &portainer.GitSigningKey{
+		Name:         payload.Name,
+		Type:         payload.Type,
+		PublicKey:    strings.TrimSpace(payload.PublicKey),
+		Fingerprint:  fingerprint,
+		CreationDate: time.Now().Unix(),
+	}
+
+	err = handler.dataStore.GitSigningKey().Create(key)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the signing key inside the database", err)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	return response.JSON(w, key)
+}
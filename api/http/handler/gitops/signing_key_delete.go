@@ -0,0 +1,44 @@
+package gitops
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id GitOpsSigningKeyDelete
+// @summary Remove a key from the trusted signing keyring
+// @description Remove a signing key, the commits signed by the key are refused by the signature policies from now on.
+// @description **Access policy**: administrator
+// @tags gitops
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Signing key identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Not found"
+// @failure 500 "Server error"
+// @router /gitops/signing_keys/{id} [delete]
+func (handler *Handler) signingKeyDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	keyID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid signing key identifier route variable", err)
+	}
+
+	_, err = handler.dataStore.GitSigningKey().Read(portainer.GitSigningKeyID(keyID))
+	if handler.dataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a signing key with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a signing key with the specified identifier inside the database", err)
+	}
+
+	err = handler.dataStore.GitSigningKey().Delete(portainer.GitSigningKeyID(keyID))
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the signing key from the database", err)
+	}
+
+	return response.Empty(w)
+}
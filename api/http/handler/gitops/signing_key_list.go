@@ -0,0 +1,28 @@
+package gitops
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id GitOpsSigningKeyList
+// @summary List the trusted signing keys
+// @description List the keys of the keyring used to verify the commit signatures.
+// @description **Access policy**: authenticated
+// @tags gitops
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {array} portainer.GitSigningKey "Success"
+// @failure 500 "Server error"
+// @router /gitops/signing_keys [get]
+func (handler *Handler) signingKeyList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	keys, err := handler.dataStore.GitSigningKey().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the signing keys from the database", err)
+	}
+
+	return response.JSON(w, keys)
+}
@@ -24,9 +24,12 @@ import (
 	"github.com/portainer/portainer/api/http/handler/kubernetes"
 	"github.com/portainer/portainer/api/http/handler/ldap"
 	"github.com/portainer/portainer/api/http/handler/motd"
+	"github.com/portainer/portainer/api/http/handler/pendingoperations"
+	"github.com/portainer/portainer/api/http/handler/policies"
 	"github.com/portainer/portainer/api/http/handler/registries"
 	"github.com/portainer/portainer/api/http/handler/resourcecontrols"
 	"github.com/portainer/portainer/api/http/handler/roles"
+	"github.com/portainer/portainer/api/http/handler/sessionrecordings"
 	"github.com/portainer/portainer/api/http/handler/settings"
 	"github.com/portainer/portainer/api/http/handler/ssl"
 	"github.com/portainer/portainer/api/http/handler/stacks"
@@ -38,49 +41,56 @@ import (
 	"github.com/portainer/portainer/api/http/handler/templates"
 	"github.com/portainer/portainer/api/http/handler/upload"
 	"github.com/portainer/portainer/api/http/handler/users"
+	"github.com/portainer/portainer/api/http/handler/volumebackups"
+	"github.com/portainer/portainer/api/http/handler/vulnerabilities"
 	"github.com/portainer/portainer/api/http/handler/webhooks"
 	"github.com/portainer/portainer/api/http/handler/websocket"
 )
 
 // Handler is a collection of all the service handlers.
 type Handler struct {
-	AuthHandler            *auth.Handler
-	BackupHandler          *backup.Handler
-	CustomTemplatesHandler *customtemplates.Handler
-	DockerHandler          *docker.Handler
-	EdgeGroupsHandler      *edgegroups.Handler
-	EdgeJobsHandler        *edgejobs.Handler
-	EdgeStacksHandler      *edgestacks.Handler
-	EdgeTemplatesHandler   *edgetemplates.Handler
-	EndpointEdgeHandler    *endpointedge.Handler
-	EndpointGroupHandler   *endpointgroups.Handler
-	EndpointHandler        *endpoints.Handler
-	EndpointHelmHandler    *helm.Handler
-	EndpointProxyHandler   *endpointproxy.Handler
-	GitOperationHandler    *gitops.Handler
-	HelmTemplatesHandler   *helm.Handler
-	KubernetesHandler      *kubernetes.Handler
-	FileHandler            *file.Handler
-	LDAPHandler            *ldap.Handler
-	MOTDHandler            *motd.Handler
-	RegistryHandler        *registries.Handler
-	ResourceControlHandler *resourcecontrols.Handler
-	RoleHandler            *roles.Handler
-	SettingsHandler        *settings.Handler
-	SSLHandler             *ssl.Handler
-	OpenAMTHandler         *openamt.Handler
-	FDOHandler             *fdo.Handler
-	StackHandler           *stacks.Handler
-	StorybookHandler       *storybook.Handler
-	SystemHandler          *system.Handler
-	TagHandler             *tags.Handler
-	TeamMembershipHandler  *teammemberships.Handler
-	TeamHandler            *teams.Handler
-	TemplatesHandler       *templates.Handler
-	UploadHandler          *upload.Handler
-	UserHandler            *users.Handler
-	WebSocketHandler       *websocket.Handler
-	WebhookHandler         *webhooks.Handler
+	AuthHandler             *auth.Handler
+	BackupHandler           *backup.Handler
+	CustomTemplatesHandler  *customtemplates.Handler
+	DockerHandler           *docker.Handler
+	EdgeGroupsHandler       *edgegroups.Handler
+	EdgeJobsHandler         *edgejobs.Handler
+	EdgeStacksHandler       *edgestacks.Handler
+	EdgeTemplatesHandler    *edgetemplates.Handler
+	EndpointEdgeHandler     *endpointedge.Handler
+	EndpointGroupHandler    *endpointgroups.Handler
+	EndpointHandler         *endpoints.Handler
+	EndpointHelmHandler     *helm.Handler
+	EndpointProxyHandler    *endpointproxy.Handler
+	GitOperationHandler     *gitops.Handler
+	HelmTemplatesHandler    *helm.Handler
+	KubernetesHandler       *kubernetes.Handler
+	FileHandler             *file.Handler
+	LDAPHandler             *ldap.Handler
+	MOTDHandler             *motd.Handler
+	PendingOperationHandler *pendingoperations.Handler
+	PolicyHandler           *policies.Handler
+	RegistryHandler         *registries.Handler
+	ResourceControlHandler  *resourcecontrols.Handler
+	RoleHandler             *roles.Handler
+	SessionRecordingHandler *sessionrecordings.Handler
+	SettingsHandler         *settings.Handler
+	SSLHandler              *ssl.Handler
+	OpenAMTHandler          *openamt.Handler
+	FDOHandler              *fdo.Handler
+	StackHandler            *stacks.Handler
+	StorybookHandler        *storybook.Handler
+	SystemHandler           *system.Handler
+	TagHandler              *tags.Handler
+	TeamMembershipHandler   *teammemberships.Handler
+	TeamHandler             *teams.Handler
+	TemplatesHandler        *templates.Handler
+	UploadHandler           *upload.Handler
+	UserHandler             *users.Handler
+	VolumeBackupHandler     *volumebackups.Handler
+	VulnerabilityHandler    *vulnerabilities.Handler
+	WebSocketHandler        *websocket.Handler
+	WebhookHandler          *webhooks.Handler
 }
 
 // @title PortainerCE API
@@ -223,12 +233,18 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.StripPrefix("/api", h.LDAPHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/motd"):
 		http.StripPrefix("/api", h.MOTDHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/pending_operations"):
+		http.StripPrefix("/api", h.PendingOperationHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/policies"):
+		http.StripPrefix("/api", h.PolicyHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/registries"):
 		http.StripPrefix("/api", h.RegistryHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/resource_controls"):
 		http.StripPrefix("/api", h.ResourceControlHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/roles"):
 		http.StripPrefix("/api", h.RoleHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/session_recordings"):
+		http.StripPrefix("/api", h.SessionRecordingHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/settings"):
 		http.StripPrefix("/api", h.SettingsHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/stacks"):
@@ -257,6 +273,10 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.StripPrefix("/api", h.TeamHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/team_memberships"):
 		http.StripPrefix("/api", h.TeamMembershipHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/volume_backups"):
+		http.StripPrefix("/api", h.VolumeBackupHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/vulnerabilities"):
+		http.StripPrefix("/api", h.VulnerabilityHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/websocket"):
 		http.StripPrefix("/api", h.WebSocketHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/webhooks"):
@@ -18,7 +18,9 @@ import (
 
 // @id GetKubernetesConfig
 // @summary Generate a kubeconfig file enabling client communication with k8s api server
-// @description Generate a kubeconfig file enabling client communication with k8s api server
+// @description Generate a kubeconfig file enabling client communication with k8s api server.
+// @description Each generated file is bound to a kubeconfig token which can be listed and revoked from the user kubeconfig tokens.
+// @description In exec mode, the file embeds a secret which is only used by the exec-credential plugin (curl) to request short-lived tokens.
 // @description **Access policy**: authenticated
 // @tags kubernetes
 // @security ApiKeyAuth
@@ -27,6 +29,8 @@ import (
 // @produce json
 // @param ids query []int false "will include only these environments(endpoints)"
 // @param excludeIds query []int false "will exclude these environments(endpoints)"
+// @param mode query string false "Authentication of the kubeconfig file, an embedded bearer token (token) or an exec-credential plugin requesting short-lived tokens (exec)" Enums(token, exec)
+// @param insecure query bool false "In exec mode, skip the verification of the certificate of Portainer by the exec-credential plugin. Only applied when the certificate is self-signed"
 // @success 200 "Success"
 // @failure 400 "Invalid request"
 // @failure 401 "Unauthorized"
@@ -40,11 +44,19 @@ func (handler *Handler) getKubernetesConfig(w http.ResponseWriter, r *http.Reque
 		return httperror.Forbidden("Permission denied to access environment", err)
 	}
 
-	bearerToken, err := handler.JwtService.GenerateTokenForKubeconfig(tokenData)
-	if err != nil {
-		return httperror.InternalServerError("Unable to generate JWT token", err)
+	mode, _ := request.RetrieveQueryParameter(r, "mode", true)
+	if mode == "" {
+		mode = string(portainer.KubeconfigTokenModeBearer)
+	}
+
+	if mode != string(portainer.KubeconfigTokenModeBearer) && mode != string(portainer.KubeconfigTokenModeExec) {
+		return httperror.BadRequest("Invalid query parameter: mode", errors.New("mode must be either token or exec"))
 	}
 
+	// the plugin verifies the certificate of Portainer, unless it is self-signed and the user opted out
+	insecure, _ := request.RetrieveBooleanQueryParameter(r, "insecure", true)
+	insecure = insecure && handler.kubeClusterAccessService.IsSelfSigned()
+
 	endpoints, handlerErr := handler.filterUserKubeEndpoints(r)
 	if handlerErr != nil {
 		return handlerErr
@@ -54,7 +66,12 @@ func (handler *Handler) getKubernetesConfig(w http.ResponseWriter, r *http.Reque
 		return httperror.BadRequest("empty endpoints list", errors.New("empty endpoints list"))
 	}
 
-	config := handler.buildConfig(r, tokenData, bearerToken, endpoints, false)
+	authInfo, handlerErr := handler.createKubeconfigToken(r, tokenData, portainer.KubeconfigTokenMode(mode), endpoints[0], insecure)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	config := handler.buildConfig(r, tokenData, authInfo, endpoints, false)
 
 	return writeFileContent(w, r, endpoints, tokenData, config)
 }
@@ -120,7 +137,7 @@ func (handler *Handler) filterUserKubeEndpoints(r *http.Request) ([]portainer.En
 	return filteredEndpoints, nil
 }
 
-func (handler *Handler) buildConfig(r *http.Request, tokenData *portainer.TokenData, bearerToken string, endpoints []portainer.Endpoint, isInternal bool) *clientV1.Config {
+func (handler *Handler) buildConfig(r *http.Request, tokenData *portainer.TokenData, authInfo clientV1.AuthInfo, endpoints []portainer.Endpoint, isInternal bool) *clientV1.Config {
 	var configAuthInfos []clientV1.NamedAuthInfo
 
 	configClusters := make([]clientV1.NamedCluster, len(endpoints))
@@ -135,7 +152,7 @@ func (handler *Handler) buildConfig(r *http.Request, tokenData *portainer.TokenD
 		configContexts[idx] = buildContext(serviceAccountName, endpoint)
 
 		if !authInfosSet[serviceAccountName] {
-			configAuthInfos = append(configAuthInfos, buildAuthInfo(serviceAccountName, bearerToken))
+			configAuthInfos = append(configAuthInfos, clientV1.NamedAuthInfo{Name: serviceAccountName, AuthInfo: authInfo})
 			authInfosSet[serviceAccountName] = true
 		}
 	}
@@ -178,15 +195,6 @@ func buildContext(serviceAccountName string, endpoint portainer.Endpoint) client
 	}
 }
 
-func buildAuthInfo(serviceAccountName string, bearerToken string) clientV1.NamedAuthInfo {
-	return clientV1.NamedAuthInfo{
-		Name: serviceAccountName,
-		AuthInfo: clientV1.AuthInfo{
-			Token: bearerToken,
-		},
-	}
-}
-
 func writeFileContent(w http.ResponseWriter, r *http.Request, endpoints []portainer.Endpoint, tokenData *portainer.TokenData, config *clientV1.Config) *httperror.HandlerError {
 	filenameSuffix := "kubeconfig"
 	if len(endpoints) == 1 {
@@ -0,0 +1,270 @@
+package kubernetes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/portainer/portainer/api/http/middlewares"
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/kubernetes/cli"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/rs/zerolog/log"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// customResourceWriteAccess verifies that the user is allowed to edit and delete the custom resources of the environment
+func (handler *Handler) customResourceWriteAccess(next http.Handler) http.Handler {
+	return httperror.LoggerHandler(func(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+		endpoint, err := middlewares.FetchEndpoint(r)
+		if err != nil {
+			return httperror.InternalServerError("Unable to find an environment on request context", err)
+		}
+
+		tokenData, err := security.RetrieveTokenData(r)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve user authentication token", err)
+		}
+
+		user, err := handler.DataStore.User().Read(tokenData.ID)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve the user from the database", err)
+		}
+
+		if !cli.CanEditCustomResources(user, endpoint.ID) {
+			return httperror.Forbidden("Permission denied to edit custom resources", errors.New("user is not authorized to edit custom resources"))
+		}
+
+		next.ServeHTTP(w, r)
+		return nil
+	})
+}
+
+// @id getKubernetesCustomResourceDefinitions
+// @summary Get a list of kubernetes custom resource definitions
+// @description Get the custom resource definitions installed in the cluster
+// @description **Access policy**: authenticated
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @success 200 {array} models.K8sCustomResourceDefinition "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/custom_resource_definitions [get]
+func (handler *Handler) getKubernetesCustomResourceDefinitions(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	kcl, httpErr := handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	definitions, err := kcl.GetCustomResourceDefinitions()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve custom resource definitions", err)
+	}
+
+	return response.JSON(w, definitions)
+}
+
+// @id getKubernetesCustomResources
+// @summary Get a list of kubernetes custom resources
+// @description Get the custom resources of a definition with their status conditions.
+// @description The namespaced resources are listed per namespace, the cluster scoped resources are only available to administrators.
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param definition path string true "Custom resource definition name"
+// @success 200 {array} models.K8sCustomResource "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Custom resource definition not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/custom_resources/{definition} [get]
+// @router /kubernetes/{id}/custom_resources/{definition} [get]
+func (handler *Handler) getKubernetesCustomResources(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, _ := request.RetrieveRouteVariableValue(r, "namespace")
+
+	definition, err := request.RetrieveRouteVariableValue(r, "definition")
+	if err != nil {
+		return httperror.BadRequest("Invalid custom resource definition route variable", err)
+	}
+
+	kcl, httpErr := handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	resources, err := kcl.GetCustomResources(definition, namespace)
+	if err != nil {
+		return customResourceError("Unable to retrieve custom resources", err)
+	}
+
+	return response.JSON(w, resources)
+}
+
+// @id getKubernetesCustomResource
+// @summary Get a kubernetes custom resource
+// @description Get a custom resource with its YAML manifest and status conditions
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param definition path string true "Custom resource definition name"
+// @param name path string true "Custom resource name"
+// @success 200 {object} models.K8sCustomResourceDetails "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Custom resource not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/custom_resources/{definition}/{name} [get]
+// @router /kubernetes/{id}/custom_resources/{definition}/{name} [get]
+func (handler *Handler) getKubernetesCustomResource(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	kcl, definition, namespace, name, httpErr := handler.customResourceRequest(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	resource, err := kcl.GetCustomResource(definition, namespace, name)
+	if err != nil {
+		return customResourceError("Unable to retrieve the custom resource", err)
+	}
+
+	return response.JSON(w, resource)
+}
+
+// @id updateKubernetesCustomResource
+// @summary Update a kubernetes custom resource
+// @description Replace a custom resource with a YAML manifest, the kind, name and namespace of the resource cannot be changed
+// @description **Access policy**: authenticated, with access to the namespace and the custom resources authorization
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param definition path string true "Custom resource definition name"
+// @param name path string true "Custom resource name"
+// @param body body models.K8sCustomResourceUpdatePayload true "New manifest of the resource"
+// @success 200 {object} models.K8sCustomResourceDetails "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Custom resource not found"
+// @failure 409 "The resource was modified since it was read"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/custom_resources/{definition}/{name} [put]
+// @router /kubernetes/{id}/custom_resources/{definition}/{name} [put]
+func (handler *Handler) updateKubernetesCustomResource(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload models.K8sCustomResourceUpdatePayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	kcl, definition, namespace, name, httpErr := handler.customResourceRequest(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	resource, err := kcl.UpdateCustomResource(definition, namespace, name, payload.YAML)
+	if err != nil {
+		return customResourceError("Unable to update the custom resource", err)
+	}
+
+	logCustomResourceOperation(r, "update", definition, namespace, name)
+
+	return response.JSON(w, resource)
+}
+
+// @id deleteKubernetesCustomResource
+// @summary Delete a kubernetes custom resource
+// @description Delete a custom resource
+// @description **Access policy**: authenticated, with access to the namespace and the custom resources authorization
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param definition path string true "Custom resource definition name"
+// @param name path string true "Custom resource name"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Custom resource not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/custom_resources/{definition}/{name} [delete]
+// @router /kubernetes/{id}/custom_resources/{definition}/{name} [delete]
+func (handler *Handler) deleteKubernetesCustomResource(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	kcl, definition, namespace, name, httpErr := handler.customResourceRequest(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if err := kcl.DeleteCustomResource(definition, namespace, name); err != nil {
+		return customResourceError("Unable to delete the custom resource", err)
+	}
+
+	logCustomResourceOperation(r, "delete", definition, namespace, name)
+
+	return response.Empty(w)
+}
+
+// customResourceRequest retrieves the custom resource targeted by the request and the admin Kubernetes client
+func (handler *Handler) customResourceRequest(r *http.Request) (kcl *cli.KubeClient, definition, namespace, name string, httpErr *httperror.HandlerError) {
+	namespace, _ = request.RetrieveRouteVariableValue(r, "namespace")
+
+	definition, err := request.RetrieveRouteVariableValue(r, "definition")
+	if err != nil {
+		return nil, "", "", "", httperror.BadRequest("Invalid custom resource definition route variable", err)
+	}
+
+	name, err = request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		return nil, "", "", "", httperror.BadRequest("Invalid custom resource name route variable", err)
+	}
+
+	kcl, httpErr = handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return nil, "", "", "", httpErr
+	}
+
+	return kcl, definition, namespace, name, nil
+}
+
+func customResourceError(message string, err error) *httperror.HandlerError {
+	switch {
+	case k8serrors.IsNotFound(err):
+		return httperror.NotFound(message, err)
+	case k8serrors.IsConflict(err):
+		return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: message, Err: err}
+	case errors.Is(err, cli.ErrCustomResourceScope), errors.Is(err, cli.ErrCustomResourceMismatch), k8serrors.IsInvalid(err), k8serrors.IsBadRequest(err):
+		return httperror.BadRequest(message, err)
+	}
+
+	return httperror.InternalServerError(message, err)
+}
+
+func logCustomResourceOperation(r *http.Request, operation, definition, namespace, name string) {
+	username := ""
+	if tokenData, err := security.RetrieveTokenData(r); err == nil {
+		username = tokenData.Username
+	}
+
+	log.Info().
+		Str("operation", operation).
+		Str("definition", definition).
+		Str("namespace", namespace).
+		Str("name", name).
+		Str("user", username).
+		Msg("kubernetes custom resource operation")
+}
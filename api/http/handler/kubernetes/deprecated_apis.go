@@ -0,0 +1,181 @@
+package kubernetes
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/middlewares"
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/portainer/portainer/api/kubernetes/cli"
+	"github.com/portainer/portainer/api/kubernetes/deprecation"
+	"github.com/portainer/portainer/api/kubernetes/kustomize"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/rs/zerolog/log"
+)
+
+const maxDeprecatedAPIReleases = 10
+
+// @id getKubernetesDeprecatedAPIs
+// @summary Scan a kubernetes cluster for deprecated APIs
+// @description Report the resources using an API which is deprecated or removed in the next minor releases of Kubernetes.
+// @description Both the live objects of the cluster and the manifests of the Kubernetes stacks of the environment are scanned,
+// @description the results are grouped per namespace and per stack.
+// @description **Access policy**: administrator
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param releases query int false "Number of minor releases after the current version of the cluster to check the APIs against, defaults to 1"
+// @success 200 {object} models.K8sDeprecatedAPIReport "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/deprecated_apis [get]
+func (handler *Handler) getKubernetesDeprecatedAPIs(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	releases := 1
+	if value, _ := request.RetrieveQueryParameter(r, "releases", true); value != "" {
+		var err error
+		releases, err = strconv.Atoi(value)
+		if err != nil || releases < 0 || releases > maxDeprecatedAPIReleases {
+			return httperror.BadRequest("Invalid query parameter: releases", errors.New("releases must be a number between 0 and 10"))
+		}
+	}
+
+	endpoint, err := middlewares.FetchEndpoint(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to find an environment on request context", err)
+	}
+
+	kcl, httpErr := handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	kubernetesVersion, err := kcl.GetKubernetesVersion()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the Kubernetes version of the cluster", err)
+	}
+
+	current, err := deprecation.ParseVersion(kubernetesVersion)
+	if err != nil {
+		return httperror.InternalServerError("Unable to parse the Kubernetes version of the cluster", err)
+	}
+	target := current.AddMinor(releases)
+
+	findings, err := kcl.GetDeprecatedAPIFindings(target)
+	if err != nil {
+		return httperror.InternalServerError("Unable to scan the cluster for deprecated APIs", err)
+	}
+
+	stacks, err := handler.DataStore.Stack().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve stacks from the database", err)
+	}
+
+	for _, stack := range stacks {
+		if stack.EndpointID != endpoint.ID || stack.Type != portainer.KubernetesStack || stack.IsComposeFormat {
+			continue
+		}
+
+		findings = append(findings, handler.scanStackManifests(stack, target)...)
+	}
+
+	return response.JSON(w, newDeprecatedAPIReport(kubernetesVersion, target, findings))
+}
+
+// scanStackManifests returns the resources of the manifests of a stack using a deprecated API,
+// the manifests which cannot be read are skipped
+func (handler *Handler) scanStackManifests(stack portainer.Stack, target deprecation.Version) []models.K8sDeprecatedAPIFinding {
+	findings := []models.K8sDeprecatedAPIFinding{}
+
+	for _, file := range append([]string{stack.EntryPoint}, stack.AdditionalFiles...) {
+		manifest, err := handler.readStackManifest(stack, file)
+		if err != nil {
+			log.Warn().Err(err).Int("stack_id", int(stack.ID)).Str("file", file).Msg("unable to read the stack manifest")
+			continue
+		}
+
+		objects, err := deprecation.ParseManifest(manifest)
+		if err != nil {
+			log.Warn().Err(err).Int("stack_id", int(stack.ID)).Str("file", file).Msg("unable to parse the stack manifest")
+			continue
+		}
+
+		for _, object := range objects {
+			finding, ok := cli.NewDeprecatedAPIFinding(object, target)
+			if !ok {
+				continue
+			}
+
+			if finding.Namespace == "" {
+				finding.Namespace = stack.Namespace
+			}
+			finding.StackID = int(stack.ID)
+			finding.StackName = stack.Name
+			finding.File = file
+
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+// readStackManifest reads a manifest file of a stack, the kustomization of the stack is rendered instead of its kustomization file
+func (handler *Handler) readStackManifest(stack portainer.Stack, file string) ([]byte, error) {
+	if stack.Kustomization != nil && file == stack.EntryPoint {
+		return kustomize.Render(stack.ProjectPath, *stack.Kustomization)
+	}
+
+	return handler.FileService.GetFileContent(stack.ProjectPath, file)
+}
+
+func newDeprecatedAPIReport(kubernetesVersion string, target deprecation.Version, findings []models.K8sDeprecatedAPIFinding) models.K8sDeprecatedAPIReport {
+	report := models.K8sDeprecatedAPIReport{
+		KubernetesVersion: kubernetesVersion,
+		TargetVersion:     target.String(),
+		Namespaces:        []models.K8sDeprecatedAPINamespaceReport{},
+		Stacks:            []models.K8sDeprecatedAPIStackReport{},
+	}
+
+	namespaces := map[string]int{}
+	stacks := map[int]int{}
+	for _, finding := range findings {
+		i, ok := namespaces[finding.Namespace]
+		if !ok {
+			i = len(report.Namespaces)
+			namespaces[finding.Namespace] = i
+			report.Namespaces = append(report.Namespaces, models.K8sDeprecatedAPINamespaceReport{Namespace: finding.Namespace})
+		}
+		report.Namespaces[i].Findings = append(report.Namespaces[i].Findings, finding)
+
+		if finding.StackID == 0 {
+			continue
+		}
+
+		i, ok = stacks[finding.StackID]
+		if !ok {
+			i = len(report.Stacks)
+			stacks[finding.StackID] = i
+			report.Stacks = append(report.Stacks, models.K8sDeprecatedAPIStackReport{StackID: finding.StackID, StackName: finding.StackName})
+		}
+		report.Stacks[i].Findings = append(report.Stacks[i].Findings, finding)
+	}
+
+	sort.Slice(report.Namespaces, func(i, j int) bool {
+		return report.Namespaces[i].Namespace < report.Namespaces[j].Namespace
+	})
+
+	sort.Slice(report.Stacks, func(i, j int) bool {
+		return report.Stacks[i].StackName < report.Stacks[j].StackName
+	})
+
+	return report
+}
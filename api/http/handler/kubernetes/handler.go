@@ -18,6 +18,7 @@ import (
 	"github.com/portainer/portainer/pkg/libhttp/request"
 
 	"github.com/gorilla/mux"
+	clientV1 "k8s.io/client-go/tools/clientcmd/api/v1"
 )
 
 // Handler is the HTTP handler which will natively deal with to external environments(endpoints).
@@ -25,6 +26,7 @@ type Handler struct {
 	*mux.Router
 	authorizationService     *authorization.Service
 	DataStore                dataservices.DataStore
+	FileService              portainer.FileService
 	KubernetesClientFactory  *cli.ClientFactory
 	JwtService               dataservices.JWTService
 	kubeClusterAccessService kubernetes.KubeClusterAccessService
@@ -41,6 +43,9 @@ func NewHandler(bouncer security.BouncerService, authorizationService *authoriza
 		KubernetesClientFactory:  kubernetesClientFactory,
 	}
 
+	h.Handle("/kubernetes/config/credential",
+		bouncer.PublicAccess(httperror.LoggerHandler(h.getKubernetesExecCredential))).Methods(http.MethodPost)
+
 	kubeRouter := h.PathPrefix("/kubernetes").Subrouter()
 	kubeRouter.Use(bouncer.AuthenticatedAccess)
 	kubeRouter.PathPrefix("/config").Handler(
@@ -61,6 +66,16 @@ func NewHandler(bouncer security.BouncerService, authorizationService *authoriza
 	endpointRouter.Handle("/ingresscontrollers", httperror.LoggerHandler(h.updateKubernetesIngressControllers)).Methods(http.MethodPut)
 	endpointRouter.Handle("/ingresses/delete", httperror.LoggerHandler(h.deleteKubernetesIngresses)).Methods(http.MethodPost)
 	endpointRouter.Handle("/services/delete", httperror.LoggerHandler(h.deleteKubernetesServices)).Methods(http.MethodPost)
+	endpointRouter.Path("/events").Handler(httperror.LoggerHandler(h.getKubernetesEvents)).Methods(http.MethodGet)
+	endpointRouter.Path("/logs").Handler(httperror.LoggerHandler(h.getKubernetesLogs)).Methods(http.MethodGet)
+	endpointRouter.Path("/storage_classes").Handler(httperror.LoggerHandler(h.getKubernetesStorageClasses)).Methods(http.MethodGet)
+	endpointRouter.Path("/persistent_volumes").Handler(bouncer.AdminAccess(httperror.LoggerHandler(h.getKubernetesPersistentVolumes))).Methods(http.MethodGet)
+	endpointRouter.Path("/custom_resource_definitions").Handler(httperror.LoggerHandler(h.getKubernetesCustomResourceDefinitions)).Methods(http.MethodGet)
+	endpointRouter.Path("/custom_resources/{definition}").Handler(bouncer.AdminAccess(httperror.LoggerHandler(h.getKubernetesCustomResources))).Methods(http.MethodGet)
+	endpointRouter.Path("/custom_resources/{definition}/{name}").Handler(bouncer.AdminAccess(httperror.LoggerHandler(h.getKubernetesCustomResource))).Methods(http.MethodGet)
+	endpointRouter.Path("/custom_resources/{definition}/{name}").Handler(bouncer.AdminAccess(httperror.LoggerHandler(h.updateKubernetesCustomResource))).Methods(http.MethodPut)
+	endpointRouter.Path("/custom_resources/{definition}/{name}").Handler(bouncer.AdminAccess(httperror.LoggerHandler(h.deleteKubernetesCustomResource))).Methods(http.MethodDelete)
+	endpointRouter.Path("/deprecated_apis").Handler(bouncer.AdminAccess(httperror.LoggerHandler(h.getKubernetesDeprecatedAPIs))).Methods(http.MethodGet)
 	endpointRouter.Path("/rbac_enabled").Handler(httperror.LoggerHandler(h.isRBACEnabled)).Methods(http.MethodGet)
 	endpointRouter.Path("/namespaces").Handler(httperror.LoggerHandler(h.createKubernetesNamespace)).Methods(http.MethodPost)
 	endpointRouter.Path("/namespaces").Handler(httperror.LoggerHandler(h.updateKubernetesNamespace)).Methods(http.MethodPut)
@@ -73,8 +88,13 @@ func NewHandler(bouncer security.BouncerService, authorizationService *authoriza
 	// to keep it simple, we've decided to leave it like this.
 	namespaceRouter := endpointRouter.PathPrefix("/namespaces/{namespace}").Subrouter()
 	namespaceRouter.Handle("/system", bouncer.RestrictedAccess(httperror.LoggerHandler(h.namespacesToggleSystem))).Methods(http.MethodPut)
+	namespaceRouter.Handle("/isolation", bouncer.RestrictedAccess(httperror.LoggerHandler(h.namespacesToggleIsolation))).Methods(http.MethodPut)
+	namespaceRouter.Handle("/network_policies", httperror.LoggerHandler(h.getKubernetesNetworkPolicies)).Methods(http.MethodGet)
+	namespaceRouter.Handle("/network_policies/{name}", httperror.LoggerHandler(h.getKubernetesNetworkPolicy)).Methods(http.MethodGet)
+	namespaceRouter.Handle("/network_policies/{name}", httperror.LoggerHandler(h.deleteKubernetesNetworkPolicy)).Methods(http.MethodDelete)
 	namespaceRouter.Handle("/ingresscontrollers", httperror.LoggerHandler(h.getKubernetesIngressControllersByNamespace)).Methods(http.MethodGet)
 	namespaceRouter.Handle("/ingresscontrollers", httperror.LoggerHandler(h.updateKubernetesIngressControllersByNamespace)).Methods(http.MethodPut)
+	namespaceRouter.Handle("/resource_quotas", httperror.LoggerHandler(h.getKubernetesNamespaceResourceQuotas)).Methods(http.MethodGet)
 	namespaceRouter.Handle("/configuration", httperror.LoggerHandler(h.getKubernetesConfigMapsAndSecrets)).Methods(http.MethodGet)
 	namespaceRouter.Handle("/ingresses", httperror.LoggerHandler(h.createKubernetesIngress)).Methods(http.MethodPost)
 	namespaceRouter.Handle("/ingresses", httperror.LoggerHandler(h.updateKubernetesIngress)).Methods(http.MethodPut)
@@ -83,6 +103,30 @@ func NewHandler(bouncer security.BouncerService, authorizationService *authoriza
 	namespaceRouter.Handle("/services", httperror.LoggerHandler(h.updateKubernetesService)).Methods(http.MethodPut)
 	namespaceRouter.Handle("/services", httperror.LoggerHandler(h.getKubernetesServices)).Methods(http.MethodGet)
 
+	// volumes
+	namespaceRouter.Handle("/persistent_volume_claims", h.namespaceAccess(httperror.LoggerHandler(h.getKubernetesPersistentVolumeClaims))).Methods(http.MethodGet)
+	namespaceRouter.Handle("/persistent_volume_claims/{name}/expand", h.namespaceAccess(httperror.LoggerHandler(h.expandKubernetesPersistentVolumeClaim))).Methods(http.MethodPut)
+	namespaceRouter.Handle("/persistent_volume_claims/{name}/snapshots", h.namespaceAccess(httperror.LoggerHandler(h.createKubernetesVolumeSnapshot))).Methods(http.MethodPost)
+	namespaceRouter.Handle("/volume_snapshots", h.namespaceAccess(httperror.LoggerHandler(h.getKubernetesVolumeSnapshots))).Methods(http.MethodGet)
+	namespaceRouter.Handle("/volume_snapshots/{name}", h.namespaceAccess(httperror.LoggerHandler(h.deleteKubernetesVolumeSnapshot))).Methods(http.MethodDelete)
+	namespaceRouter.Handle("/volume_snapshots/{name}/restore", h.namespaceAccess(httperror.LoggerHandler(h.restoreKubernetesVolumeSnapshot))).Methods(http.MethodPost)
+
+	// custom resources
+	namespaceRouter.Handle("/custom_resources/{definition}", h.namespaceAccess(httperror.LoggerHandler(h.getKubernetesCustomResources))).Methods(http.MethodGet)
+	namespaceRouter.Handle("/custom_resources/{definition}/{name}", h.namespaceAccess(httperror.LoggerHandler(h.getKubernetesCustomResource))).Methods(http.MethodGet)
+	namespaceRouter.Handle("/custom_resources/{definition}/{name}", h.namespaceAccess(h.customResourceWriteAccess(httperror.LoggerHandler(h.updateKubernetesCustomResource)))).Methods(http.MethodPut)
+	namespaceRouter.Handle("/custom_resources/{definition}/{name}", h.namespaceAccess(h.customResourceWriteAccess(httperror.LoggerHandler(h.deleteKubernetesCustomResource)))).Methods(http.MethodDelete)
+
+	// workloads rollout
+	workloadRouter := namespaceRouter.PathPrefix("/workloads/{kind}/{name}").Subrouter()
+	workloadRouter.Use(h.workloadAccess)
+	workloadRouter.Handle("/restart", httperror.LoggerHandler(h.restartKubernetesWorkload)).Methods(http.MethodPost)
+	workloadRouter.Handle("/scale", httperror.LoggerHandler(h.scaleKubernetesWorkload)).Methods(http.MethodPut)
+	workloadRouter.Handle("/pause", httperror.LoggerHandler(h.pauseKubernetesWorkloadRollout)).Methods(http.MethodPost)
+	workloadRouter.Handle("/resume", httperror.LoggerHandler(h.resumeKubernetesWorkloadRollout)).Methods(http.MethodPost)
+	workloadRouter.Handle("/revisions", httperror.LoggerHandler(h.getKubernetesWorkloadRevisions)).Methods(http.MethodGet)
+	workloadRouter.Handle("/rollback", httperror.LoggerHandler(h.rollbackKubernetesWorkload)).Methods(http.MethodPost)
+
 	return h
 }
 
@@ -157,7 +201,7 @@ func (handler *Handler) kubeClient(next http.Handler) http.Handler {
 			)
 			return
 		}
-		bearerToken, err := handler.JwtService.GenerateTokenForKubeconfig(tokenData)
+		bearerToken, err := handler.generateInternalKubeconfigToken(tokenData)
 		if err != nil {
 			httperror.WriteError(
 				w,
@@ -173,7 +217,7 @@ func (handler *Handler) kubeClient(next http.Handler) http.Handler {
 		config := handler.buildConfig(
 			r,
 			tokenData,
-			bearerToken,
+			clientV1.AuthInfo{Token: bearerToken},
 			singleEndpointList,
 			true,
 		)
@@ -0,0 +1,210 @@
+package kubernetes
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/internal/securecookie"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+	clientV1 "k8s.io/client-go/tools/clientcmd/api/v1"
+)
+
+const kubeconfigExecSecretPrefix = "ptk_"
+
+var errInvalidKubeconfigSecret = errors.New("invalid kubeconfig secret")
+
+// kubeconfigExpiryDate returns the expiry date of the bearer token embedded in a kubeconfig file, 0 when it never expires
+func kubeconfigExpiryDate(settings *portainer.Settings) (int64, error) {
+	expiry, err := time.ParseDuration(settings.KubeconfigExpiry)
+	if err != nil {
+		return 0, err
+	}
+
+	if expiry == 0 {
+		return 0, nil
+	}
+
+	return time.Now().Add(expiry).Unix(), nil
+}
+
+// generateInternalKubeconfigToken generates a token used by Portainer to query the Kubernetes API on behalf of the user,
+// the token is not exposed to the user and it is not bound to any kubeconfig token
+func (handler *Handler) generateInternalKubeconfigToken(tokenData *portainer.TokenData) (string, error) {
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return "", err
+	}
+
+	expiresAt, err := kubeconfigExpiryDate(settings)
+	if err != nil {
+		return "", err
+	}
+
+	return handler.JwtService.GenerateTokenForKubeconfig(tokenData, 0, expiresAt)
+}
+
+// createKubeconfigToken creates the kubeconfig token a generated kubeconfig file is bound to and
+// returns the authentication of the file, insecure disables the certificate verification of the exec-credential plugin
+func (handler *Handler) createKubeconfigToken(r *http.Request, tokenData *portainer.TokenData, mode portainer.KubeconfigTokenMode, endpoint portainer.Endpoint, insecure bool) (clientV1.AuthInfo, *httperror.HandlerError) {
+	token := &portainer.KubeconfigToken{
+		UserID:       tokenData.ID,
+		Mode:         mode,
+		CreationDate: time.Now().Unix(),
+	}
+
+	var secret string
+	if mode == portainer.KubeconfigTokenModeExec {
+		secret = kubeconfigExecSecretPrefix + base64.RawURLEncoding.EncodeToString(securecookie.GenerateRandomKey(32))
+		digest := sha256.Sum256([]byte(secret))
+		token.Digest = digest[:]
+	} else {
+		settings, err := handler.DataStore.Settings().Settings()
+		if err != nil {
+			return clientV1.AuthInfo{}, httperror.InternalServerError("Unable to retrieve the settings from the database", err)
+		}
+
+		token.ExpiryDate, err = kubeconfigExpiryDate(settings)
+		if err != nil {
+			return clientV1.AuthInfo{}, httperror.InternalServerError("Invalid kubeconfig expiry in the settings", err)
+		}
+	}
+
+	if err := handler.DataStore.KubeconfigToken().Create(token); err != nil {
+		return clientV1.AuthInfo{}, httperror.InternalServerError("Unable to persist the kubeconfig token inside the database", err)
+	}
+
+	if mode == portainer.KubeconfigTokenModeExec {
+		return buildExecAuthInfo(handler.buildCredentialURL(r, endpoint), secret, insecure), nil
+	}
+
+	bearerToken, err := handler.JwtService.GenerateTokenForKubeconfig(tokenData, token.ID, token.ExpiryDate)
+	if err != nil {
+		return clientV1.AuthInfo{}, httperror.InternalServerError("Unable to generate JWT token", err)
+	}
+
+	return clientV1.AuthInfo{Token: bearerToken}, nil
+}
+
+// buildCredentialURL builds the URL of the exec-credential endpoint from the public URL of the environment
+func (handler *Handler) buildCredentialURL(r *http.Request, endpoint portainer.Endpoint) string {
+	clusterURL := handler.kubeClusterAccessService.GetClusterDetails(r.Host, endpoint.ID, false).ClusterServerURL
+	baseURL, _, _ := strings.Cut(clusterURL, "/api/endpoints/")
+
+	return baseURL + "/api/kubernetes/config/credential"
+}
+
+// buildExecAuthInfo builds an authentication relying on curl to request short-lived tokens with the kubeconfig secret,
+// the certificate of Portainer is verified by curl unless insecure is set
+func buildExecAuthInfo(credentialURL, secret string, insecure bool) clientV1.AuthInfo {
+	args := []string{"--silent", "--show-error", "--fail"}
+	if insecure {
+		args = append(args, "--insecure")
+	}
+
+	return clientV1.AuthInfo{
+		Exec: &clientV1.ExecConfig{
+			APIVersion: clientauthv1.SchemeGroupVersion.String(),
+			Command:    "curl",
+			Args: append(args,
+				"--request", "POST",
+				"--header", "Authorization: Bearer "+secret,
+				credentialURL,
+			),
+			InstallHint:     "curl is required to request the Portainer tokens of this kubeconfig file",
+			InteractiveMode: clientV1.NeverExecInteractiveMode,
+		},
+	}
+}
+
+// findExecKubeconfigToken returns the exec kubeconfig token matching a kubeconfig secret
+func findExecKubeconfigToken(dataStore dataservices.DataStore, secret string) (*portainer.KubeconfigToken, error) {
+	if !strings.HasPrefix(secret, kubeconfigExecSecretPrefix) {
+		return nil, errInvalidKubeconfigSecret
+	}
+
+	digest := sha256.Sum256([]byte(secret))
+
+	tokens, err := dataStore.KubeconfigToken().ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range tokens {
+		if tokens[i].Mode == portainer.KubeconfigTokenModeExec && subtle.ConstantTimeCompare(tokens[i].Digest, digest[:]) == 1 {
+			return &tokens[i], nil
+		}
+	}
+
+	return nil, errInvalidKubeconfigSecret
+}
+
+// @id GetKubernetesExecCredential
+// @summary Request a short-lived token for a kubeconfig file
+// @description Exchange the secret of a kubeconfig file generated in exec mode for a short-lived token.
+// @description The response is an ExecCredential object which is used by kubectl through the exec-credential plugin of the file.
+// @description **Access policy**: public, the kubeconfig secret must be provided as a bearer token
+// @tags kubernetes
+// @produce json
+// @success 200 "Success"
+// @failure 401 "Invalid or revoked kubeconfig secret"
+// @failure 500 "Server error"
+// @router /kubernetes/config/credential [post]
+func (handler *Handler) getKubernetesExecCredential(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	secret, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return httperror.Unauthorized("Missing kubeconfig secret", errInvalidKubeconfigSecret)
+	}
+
+	token, err := findExecKubeconfigToken(handler.DataStore, secret)
+	if errors.Is(err, errInvalidKubeconfigSecret) {
+		return httperror.Unauthorized("Invalid kubeconfig secret", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the kubeconfig tokens from the database", err)
+	}
+
+	user, err := handler.DataStore.User().Read(token.UserID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.Unauthorized("Invalid kubeconfig secret", errInvalidKubeconfigSecret)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the user from the database", err)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(portainer.KubeconfigExecTokenExpiry)
+
+	bearerToken, err := handler.JwtService.GenerateTokenForKubeconfig(&portainer.TokenData{
+		ID:       user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+	}, token.ID, expiresAt.Unix())
+	if err != nil {
+		return httperror.InternalServerError("Unable to generate JWT token", err)
+	}
+
+	token.LastUsed = now.Unix()
+	if err := handler.DataStore.KubeconfigToken().Update(token.ID, token); err != nil {
+		return httperror.InternalServerError("Unable to persist the kubeconfig token inside the database", err)
+	}
+
+	return response.JSON(w, clientauthv1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clientauthv1.SchemeGroupVersion.String(),
+			Kind:       "ExecCredential",
+		},
+		Status: &clientauthv1.ExecCredentialStatus{
+			Token:               bearerToken,
+			ExpirationTimestamp: &metav1.Time{Time: expiresAt},
+		},
+	})
+}
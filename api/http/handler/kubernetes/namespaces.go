@@ -260,3 +260,55 @@ func (handler *Handler) updateKubernetesNamespace(w http.ResponseWriter, r *http
 	}
 	return nil
 }
+
+// @id getKubernetesNamespaceResourceQuotas
+// @summary Get the resource quotas usage of a kubernetes namespace
+// @description Get the current usage of the resource quotas of a namespace compared with their hard limits
+// @description **Access policy**: authenticated
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @success 200 {array} models.K8sResourceQuotaUsage "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/resource_quotas [get]
+func (handler *Handler) getKubernetesNamespaceResourceQuotas(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest(
+			"Invalid environment identifier route variable",
+			err,
+		)
+	}
+
+	cli, ok := handler.KubernetesClientFactory.GetProxyKubeClient(
+		strconv.Itoa(endpointID), r.Header.Get("Authorization"),
+	)
+	if !ok {
+		return httperror.InternalServerError(
+			"Failed to lookup KubeClient",
+			nil,
+		)
+	}
+
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		return httperror.BadRequest(
+			"Invalid namespace identifier route variable",
+			err,
+		)
+	}
+
+	quotas, err := cli.GetNamespaceResourceQuotas(namespace)
+	if err != nil {
+		return httperror.InternalServerError(
+			"Unable to retrieve namespace resource quotas",
+			err,
+		)
+	}
+
+	return response.JSON(w, quotas)
+}
@@ -0,0 +1,194 @@
+package kubernetes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/portainer/portainer/api/http/middlewares"
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/portainer/portainer/api/kubernetes/cli"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// @id KubernetesNamespacesToggleIsolation
+// @summary Toggle the isolation of a namespace
+// @description Isolate a namespace with a default-deny network policy which only allows the traffic between the pods of the namespace
+// @description and the traffic coming from the sources of the allow rules, e.g. other namespaces or ingress controllers.
+// @description The policy is removed when the namespace is not isolated anymore.
+// @description **Access policy**: administrator or environment(endpoint) admin
+// @security ApiKeyAuth
+// @security jwt
+// @tags kubernetes
+// @accept json
+// @param id path int true "Environment(Endpoint) identifier"
+// @param namespace path string true "Namespace name"
+// @param body body models.K8sNamespaceIsolation true "Isolation details"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Environment(Endpoint) not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/isolation [put]
+func (handler *Handler) namespacesToggleIsolation(rw http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, err := middlewares.FetchEndpoint(r)
+	if err != nil {
+		return httperror.NotFound("Unable to find an environment on request context", err)
+	}
+
+	namespaceName, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		return httperror.BadRequest("Invalid namespace identifier route variable", err)
+	}
+
+	var payload models.K8sNamespaceIsolation
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	kubeClient, err := handler.KubernetesClientFactory.GetKubeClient(endpoint)
+	if err != nil {
+		return httperror.InternalServerError("Unable to create kubernetes client", err)
+	}
+
+	err = kubeClient.ToggleNamespaceIsolation(namespaceName, payload)
+	if err != nil {
+		return httperror.InternalServerError("Unable to toggle namespace isolation", err)
+	}
+
+	return response.Empty(rw)
+}
+
+// @id getKubernetesNetworkPolicies
+// @summary Get a list of kubernetes network policies
+// @description Get a list of the network policies of a namespace, including the ones which are not managed by Portainer
+// @description **Access policy**: authenticated
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace name"
+// @success 200 {array} models.K8sNetworkPolicy "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/network_policies [get]
+func (handler *Handler) getKubernetesNetworkPolicies(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		return httperror.BadRequest("Invalid namespace identifier route variable", err)
+	}
+
+	kubeClient, handlerErr := handler.getProxyKubeClient(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	policies, err := kubeClient.GetNetworkPolicies(namespace)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve network policies", err)
+	}
+
+	return response.JSON(w, policies)
+}
+
+// @id getKubernetesNetworkPolicy
+// @summary Get a kubernetes network policy
+// @description Get the details of a network policy
+// @description **Access policy**: authenticated
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace name"
+// @param name path string true "Network policy name"
+// @success 200 {object} models.K8sNetworkPolicy "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Network policy not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/network_policies/{name} [get]
+func (handler *Handler) getKubernetesNetworkPolicy(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		return httperror.BadRequest("Invalid namespace identifier route variable", err)
+	}
+
+	name, err := request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		return httperror.BadRequest("Invalid network policy name route variable", err)
+	}
+
+	kubeClient, handlerErr := handler.getProxyKubeClient(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	policy, err := kubeClient.GetNetworkPolicy(namespace, name)
+	if k8serrors.IsNotFound(err) {
+		return httperror.NotFound("Unable to find the network policy", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the network policy", err)
+	}
+
+	return response.JSON(w, policy)
+}
+
+// @id deleteKubernetesNetworkPolicy
+// @summary Delete a kubernetes network policy
+// @description Delete a network policy of a namespace
+// @description **Access policy**: authenticated
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace name"
+// @param name path string true "Network policy name"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Network policy not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/network_policies/{name} [delete]
+func (handler *Handler) deleteKubernetesNetworkPolicy(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		return httperror.BadRequest("Invalid namespace identifier route variable", err)
+	}
+
+	name, err := request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		return httperror.BadRequest("Invalid network policy name route variable", err)
+	}
+
+	kubeClient, handlerErr := handler.getProxyKubeClient(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	err = kubeClient.DeleteNetworkPolicy(namespace, name)
+	if k8serrors.IsNotFound(err) {
+		return httperror.NotFound("Unable to find the network policy", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to delete the network policy", err)
+	}
+
+	return response.Empty(w)
+}
+
+// getProxyKubeClient returns the Kubernetes client of the environment using the token of the current user
+func (handler *Handler) getProxyKubeClient(r *http.Request) (*cli.KubeClient, *httperror.HandlerError) {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid environment identifier route variable", err)
+	}
+
+	kubeClient, ok := handler.KubernetesClientFactory.GetProxyKubeClient(strconv.Itoa(endpointID), r.Header.Get("Authorization"))
+	if !ok {
+		return nil, httperror.InternalServerError("Failed to lookup KubeClient", nil)
+	}
+
+	return kubeClient, nil
+}
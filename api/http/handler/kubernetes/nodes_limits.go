@@ -1,9 +1,11 @@
 package kubernetes
 
 import (
+	"errors"
 	"net/http"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/security"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
@@ -12,13 +14,15 @@ import (
 // @id getKubernetesNodesLimits
 // @summary Get CPU and memory limits of all nodes within k8s cluster
 // @description Get CPU and memory limits of all nodes within k8s cluster
-// @description **Access policy**: authenticated
+// @description When a namespace is provided, the limits take the resource quotas of the namespace into account.
+// @description **Access policy**: authenticated, the user must have access to the namespace when one is provided
 // @tags kubernetes
 // @security ApiKeyAuth
 // @security jwt
 // @accept json
 // @produce json
 // @param id path int true "Environment(Endpoint) identifier"
+// @param namespace query string false "When provided, the limits are capped by the CPU and memory still available within the resource quotas of this namespace"
 // @success 200 {object} portainer.K8sNodesLimits "Success"
 // @failure 400 "Invalid request"
 // @failure 401 "Unauthorized"
@@ -44,7 +48,32 @@ func (handler *Handler) getKubernetesNodesLimits(w http.ResponseWriter, r *http.
 		return httperror.InternalServerError("Unable to create Kubernetes client", err)
 	}
 
-	nodesLimits, err := cli.GetNodesLimits()
+	namespace, _ := request.RetrieveQueryParameter(r, "namespace", true)
+
+	if namespace != "" {
+		securityContext, err := security.RetrieveRestrictedRequestContext(r)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve info from request context", err)
+		}
+
+		if !securityContext.IsAdmin {
+			authorized, err := cli.HasNamespaceAccess(namespace, int(securityContext.UserID), userTeamIDs(securityContext), endpoint.Kubernetes.Configuration.RestrictDefaultNamespace)
+			if err != nil {
+				return httperror.InternalServerError("Unable to retrieve the namespace access policies", err)
+			}
+
+			if !authorized {
+				return httperror.Forbidden("Permission denied to access the namespace", errors.New("user is not authorized to use namespace"))
+			}
+		}
+	}
+
+	var nodesLimits portainer.K8sNodesLimits
+	if namespace != "" {
+		nodesLimits, err = cli.GetNamespaceNodesLimits(namespace)
+	} else {
+		nodesLimits, err = cli.GetNodesLimits()
+	}
 	if err != nil {
 		return httperror.InternalServerError("Unable to retrieve nodes limits", err)
 	}
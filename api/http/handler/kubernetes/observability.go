@@ -0,0 +1,241 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/middlewares"
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/kubernetes/cli"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/rs/zerolog/log"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// parseObservabilityFilter builds the events and logs filter from the query parameters of the request.
+// The namespaces of the filter are restricted to the ones the user is granted access to.
+// It returns a nil filter when the user cannot access any namespace.
+func (handler *Handler) parseObservabilityFilter(r *http.Request) (portainer.KubeClient, *models.K8sObservabilityFilter, *httperror.HandlerError) {
+	namespace, _ := request.RetrieveQueryParameter(r, "namespace", true)
+	application, _ := request.RetrieveQueryParameter(r, "application", true)
+	selector, _ := request.RetrieveQueryParameter(r, "selector", true)
+
+	if application != "" && namespace == "" {
+		return nil, nil, httperror.BadRequest("Invalid query parameter: namespace", errors.New("a namespace is required to observe an application"))
+	}
+
+	filter := &models.K8sObservabilityFilter{
+		Application:   application,
+		LabelSelector: selector,
+	}
+
+	since, err := request.RetrieveNumericQueryParameter(r, "since", true)
+	if err != nil {
+		return nil, nil, httperror.BadRequest("Invalid query parameter: since", err)
+	}
+	if since > 0 {
+		filter.Since = time.Unix(int64(since), 0)
+	}
+
+	until, err := request.RetrieveNumericQueryParameter(r, "until", true)
+	if err != nil {
+		return nil, nil, httperror.BadRequest("Invalid query parameter: until", err)
+	}
+	if until > 0 {
+		filter.Until = time.Unix(int64(until), 0)
+	}
+
+	tail, err := request.RetrieveNumericQueryParameter(r, "tail", true)
+	if err != nil {
+		return nil, nil, httperror.BadRequest("Invalid query parameter: tail", err)
+	}
+	filter.TailLines = int64(tail)
+
+	filter.Follow, _ = request.RetrieveBooleanQueryParameter(r, "follow", true)
+
+	endpoint, err := middlewares.FetchEndpoint(r)
+	if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to find an environment on request context", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	kcl, err := handler.KubernetesClientFactory.GetKubeClient(endpoint)
+	if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to create Kubernetes client", err)
+	}
+
+	restrictDefaultNamespace := endpoint.Kubernetes.Configuration.RestrictDefaultNamespace
+
+	switch {
+	case namespace != "":
+		if !securityContext.IsAdmin {
+			authorized, err := kcl.HasNamespaceAccess(namespace, int(securityContext.UserID), userTeamIDs(securityContext), restrictDefaultNamespace)
+			if err != nil {
+				return nil, nil, httperror.InternalServerError("Unable to retrieve the namespace access policies", err)
+			}
+
+			if !authorized {
+				return nil, nil, httperror.Forbidden("Permission denied to access the namespace", errors.New("user is not authorized to use namespace"))
+			}
+		}
+
+		filter.Namespaces = []string{namespace}
+	case !securityContext.IsAdmin:
+		namespaces, err := kcl.GetUserNamespaces(int(securityContext.UserID), userTeamIDs(securityContext), restrictDefaultNamespace)
+		if err != nil {
+			return nil, nil, httperror.InternalServerError("Unable to retrieve the namespace access policies", err)
+		}
+
+		if len(namespaces) == 0 {
+			return kcl, nil, nil
+		}
+
+		filter.Namespaces = namespaces
+	}
+
+	return kcl, filter, nil
+}
+
+func userTeamIDs(securityContext *security.RestrictedRequestContext) []int {
+	teamIDs := []int{}
+	for _, membership := range securityContext.UserMemberships {
+		teamIDs = append(teamIDs, int(membership.TeamID))
+	}
+
+	return teamIDs
+}
+
+func observabilityError(message string, err error) *httperror.HandlerError {
+	if k8serrors.IsNotFound(err) {
+		return httperror.NotFound(message, err)
+	}
+
+	if errors.Is(err, cli.ErrTooManyLogStreams) {
+		return httperror.BadRequest(message, err)
+	}
+
+	return httperror.InternalServerError(message, err)
+}
+
+// @id getKubernetesEvents
+// @summary Get the events of an application or of namespaces
+// @description Get the Kubernetes events of an application (the deployment, its replicasets and its pods) or of namespaces,
+// @description merged and ordered by their last occurrence. Only the namespaces the user can access are used.
+// @description **Access policy**: authenticated
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace query string false "Namespace, all the accessible namespaces are used when not provided"
+// @param application query string false "Name of a deployment, requires a namespace"
+// @param selector query string false "Label selector of the pods"
+// @param since query int false "Unix timestamp of the start of the time range"
+// @param until query int false "Unix timestamp of the end of the time range"
+// @success 200 {array} models.K8sEvent "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Application not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/events [get]
+func (handler *Handler) getKubernetesEvents(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	kcl, filter, httpErr := handler.parseObservabilityFilter(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if filter == nil {
+		return response.JSON(w, []models.K8sEvent{})
+	}
+
+	events, err := kcl.GetEvents(r.Context(), *filter)
+	if err != nil {
+		return observabilityError("Unable to retrieve the events", err)
+	}
+
+	return response.JSON(w, events)
+}
+
+// @id getKubernetesLogs
+// @summary Stream the logs of an application or of namespaces
+// @description Stream, as server-sent events, the logs of all the containers of an application or of namespaces.
+// @description Each `log` event holds a log line, the lines are interleaved according to their timestamp unless they are followed.
+// @description An `error` event is sent when the stream fails and a `end` event is sent once all the lines have been sent.
+// @description Only the namespaces the user can access are used.
+// @description **Access policy**: authenticated
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce text/event-stream
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace query string false "Namespace, all the accessible namespaces are used when not provided"
+// @param application query string false "Name of a deployment, requires a namespace"
+// @param selector query string false "Label selector of the pods"
+// @param since query int false "Unix timestamp of the start of the time range"
+// @param until query int false "Unix timestamp of the end of the time range"
+// @param tail query int false "Number of lines to return from the end of the logs of each container"
+// @param follow query bool false "Keep streaming the new log lines"
+// @success 200 {object} models.K8sLogLine "Stream of log lines"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/logs [get]
+func (handler *Handler) getKubernetesLogs(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	kcl, filter, httpErr := handler.parseObservabilityFilter(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return httperror.InternalServerError("Streaming is not supported", errors.New("response writer does not support flushing"))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	sendEvent := func(event string, data interface{}) error {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+			return err
+		}
+
+		flusher.Flush()
+		return nil
+	}
+
+	if filter != nil {
+		err := kcl.StreamLogs(r.Context(), *filter, func(line models.K8sLogLine) error {
+			return sendEvent("log", line)
+		})
+		if err != nil && r.Context().Err() == nil {
+			log.Warn().Err(err).Msg("unable to stream the kubernetes logs")
+			sendEvent("error", map[string]string{"message": err.Error()})
+			return nil
+		}
+	}
+
+	sendEvent("end", struct{}{})
+
+	return nil
+}
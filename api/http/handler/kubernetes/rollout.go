@@ -0,0 +1,328 @@
+package kubernetes
+
+import (
+	"errors"
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/middlewares"
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/kubernetes/cli"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/rs/zerolog/log"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// workloadAccess verifies that the workload kind supports rollout operations and that
+// the user is granted access to the namespace of the workload by the namespace access policies
+func (handler *Handler) workloadAccess(next http.Handler) http.Handler {
+	return httperror.LoggerHandler(func(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+		kind, err := request.RetrieveRouteVariableValue(r, "kind")
+		if err != nil || !cli.IsValidWorkloadKind(kind) {
+			return httperror.BadRequest("Invalid workload kind route variable, must be one of deployments, statefulsets or daemonsets", cli.ErrUnsupportedWorkloadKind)
+		}
+
+		handler.namespaceAccess(next).ServeHTTP(w, r)
+		return nil
+	})
+}
+
+// namespaceAccess verifies that the user is granted access to the namespace of the request by the namespace access policies,
+// it is used by the routes relying on the admin Kubernetes client
+func (handler *Handler) namespaceAccess(next http.Handler) http.Handler {
+	return httperror.LoggerHandler(func(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+		namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+		if err != nil {
+			return httperror.BadRequest("Invalid namespace identifier route variable", err)
+		}
+
+		endpoint, err := middlewares.FetchEndpoint(r)
+		if err != nil {
+			return httperror.InternalServerError("Unable to find an environment on request context", err)
+		}
+
+		securityContext, err := security.RetrieveRestrictedRequestContext(r)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve info from request context", err)
+		}
+
+		if !securityContext.IsAdmin {
+			kcl, err := handler.KubernetesClientFactory.GetKubeClient(endpoint)
+			if err != nil {
+				return httperror.InternalServerError("Unable to create Kubernetes client", err)
+			}
+
+			authorized, err := kcl.HasNamespaceAccess(namespace, int(securityContext.UserID), userTeamIDs(securityContext), endpoint.Kubernetes.Configuration.RestrictDefaultNamespace)
+			if err != nil {
+				return httperror.InternalServerError("Unable to retrieve the namespace access policies", err)
+			}
+
+			if !authorized {
+				return httperror.Forbidden("Permission denied to access the namespace", errors.New("user is not authorized to use namespace"))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+		return nil
+	})
+}
+
+// workloadOperation retrieves the workload targeted by the request, the admin Kubernetes client and the name of the current user
+func (handler *Handler) workloadOperation(r *http.Request) (kcl portainer.KubeClient, namespace, kind, name, username string, httpErr *httperror.HandlerError) {
+	namespace, _ = request.RetrieveRouteVariableValue(r, "namespace")
+	kind, _ = request.RetrieveRouteVariableValue(r, "kind")
+
+	name, err := request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		return nil, "", "", "", "", httperror.BadRequest("Invalid workload name route variable", err)
+	}
+
+	endpoint, err := middlewares.FetchEndpoint(r)
+	if err != nil {
+		return nil, "", "", "", "", httperror.InternalServerError("Unable to find an environment on request context", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return nil, "", "", "", "", httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	kcl, err = handler.KubernetesClientFactory.GetKubeClient(endpoint)
+	if err != nil {
+		return nil, "", "", "", "", httperror.InternalServerError("Unable to create Kubernetes client", err)
+	}
+
+	return kcl, namespace, kind, name, tokenData.Username, nil
+}
+
+func workloadOperationError(message string, err error) *httperror.HandlerError {
+	switch {
+	case k8serrors.IsNotFound(err), errors.Is(err, cli.ErrRevisionNotFound):
+		return httperror.NotFound(message, err)
+	case errors.Is(err, cli.ErrWorkloadNotScalable), errors.Is(err, cli.ErrWorkloadNotPausable), errors.Is(err, cli.ErrUnsupportedWorkloadKind):
+		return httperror.BadRequest(message, err)
+	case k8serrors.IsConflict(err):
+		return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: message, Err: err}
+	}
+
+	return httperror.InternalServerError(message, err)
+}
+
+func logWorkloadOperation(operation, namespace, kind, name, username string) {
+	log.Info().
+		Str("operation", operation).
+		Str("namespace", namespace).
+		Str("kind", kind).
+		Str("name", name).
+		Str("user", username).
+		Msg("kubernetes workload rollout operation")
+}
+
+// @id restartKubernetesWorkload
+// @summary Restart a kubernetes workload
+// @description Trigger a rolling restart of the pods of a deployment, statefulset or daemonset.
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param kind path string true "Workload kind" Enums(deployments, statefulsets, daemonsets)
+// @param name path string true "Workload name"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Workload not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/workloads/{kind}/{name}/restart [post]
+func (handler *Handler) restartKubernetesWorkload(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	kcl, namespace, kind, name, username, httpErr := handler.workloadOperation(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if err := kcl.RestartWorkload(namespace, kind, name, username); err != nil {
+		return workloadOperationError("Unable to restart the workload", err)
+	}
+
+	logWorkloadOperation("restart", namespace, kind, name, username)
+
+	return response.Empty(w)
+}
+
+// @id scaleKubernetesWorkload
+// @summary Scale a kubernetes workload
+// @description Update the number of replicas of a deployment or statefulset.
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param kind path string true "Workload kind" Enums(deployments, statefulsets)
+// @param name path string true "Workload name"
+// @param body body models.K8sWorkloadScalePayload true "Number of replicas"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Workload not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/workloads/{kind}/{name}/scale [put]
+func (handler *Handler) scaleKubernetesWorkload(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload models.K8sWorkloadScalePayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	kcl, namespace, kind, name, username, httpErr := handler.workloadOperation(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if err := kcl.ScaleWorkload(namespace, kind, name, payload.Replicas, username); err != nil {
+		return workloadOperationError("Unable to scale the workload", err)
+	}
+
+	logWorkloadOperation("scale", namespace, kind, name, username)
+
+	return response.Empty(w)
+}
+
+// @id pauseKubernetesWorkloadRollout
+// @summary Pause the rollout of a kubernetes workload
+// @description Pause the rollout of a deployment, statefulset or daemonset.
+// @description Statefulsets are paused with their rolling update partition and daemonsets with the OnDelete update strategy.
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param kind path string true "Workload kind" Enums(deployments, statefulsets, daemonsets)
+// @param name path string true "Workload name"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Workload not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/workloads/{kind}/{name}/pause [post]
+func (handler *Handler) pauseKubernetesWorkloadRollout(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	return handler.setKubernetesWorkloadRolloutPaused(w, r, true)
+}
+
+// @id resumeKubernetesWorkloadRollout
+// @summary Resume the rollout of a kubernetes workload
+// @description Resume the paused rollout of a deployment, statefulset or daemonset.
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param kind path string true "Workload kind" Enums(deployments, statefulsets, daemonsets)
+// @param name path string true "Workload name"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Workload not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/workloads/{kind}/{name}/resume [post]
+func (handler *Handler) resumeKubernetesWorkloadRollout(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	return handler.setKubernetesWorkloadRolloutPaused(w, r, false)
+}
+
+func (handler *Handler) setKubernetesWorkloadRolloutPaused(w http.ResponseWriter, r *http.Request, paused bool) *httperror.HandlerError {
+	kcl, namespace, kind, name, username, httpErr := handler.workloadOperation(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	operation := "resume"
+	if paused {
+		operation = "pause"
+	}
+
+	if err := kcl.PauseWorkloadRollout(namespace, kind, name, paused, username); err != nil {
+		return workloadOperationError("Unable to "+operation+" the workload rollout", err)
+	}
+
+	logWorkloadOperation(operation, namespace, kind, name, username)
+
+	return response.Empty(w)
+}
+
+// @id getKubernetesWorkloadRevisions
+// @summary List the revisions of a kubernetes workload
+// @description List the rollout history of a deployment (from its ReplicaSets), statefulset or daemonset (from their ControllerRevisions).
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param kind path string true "Workload kind" Enums(deployments, statefulsets, daemonsets)
+// @param name path string true "Workload name"
+// @success 200 {array} models.K8sRolloutRevision "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Workload not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/workloads/{kind}/{name}/revisions [get]
+func (handler *Handler) getKubernetesWorkloadRevisions(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	kcl, namespace, kind, name, _, httpErr := handler.workloadOperation(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	revisions, err := kcl.GetWorkloadRolloutHistory(namespace, kind, name)
+	if err != nil {
+		return workloadOperationError("Unable to retrieve the workload revisions", err)
+	}
+
+	return response.JSON(w, revisions)
+}
+
+// @id rollbackKubernetesWorkload
+// @summary Roll back a kubernetes workload
+// @description Roll back a deployment, statefulset or daemonset to the pod template of one of its revisions.
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param kind path string true "Workload kind" Enums(deployments, statefulsets, daemonsets)
+// @param name path string true "Workload name"
+// @param body body models.K8sWorkloadRollbackPayload true "Revision to roll back to"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Workload or revision not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/workloads/{kind}/{name}/rollback [post]
+func (handler *Handler) rollbackKubernetesWorkload(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload models.K8sWorkloadRollbackPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	kcl, namespace, kind, name, username, httpErr := handler.workloadOperation(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if err := kcl.RollbackWorkload(namespace, kind, name, payload.Revision, username); err != nil {
+		return workloadOperationError("Unable to roll back the workload", err)
+	}
+
+	logWorkloadOperation("rollback", namespace, kind, name, username)
+
+	return response.Empty(w)
+}
@@ -0,0 +1,341 @@
+package kubernetes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/portainer/portainer/api/http/middlewares"
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/portainer/portainer/api/kubernetes/cli"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/rs/zerolog/log"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// @id getKubernetesStorageClasses
+// @summary Get a list of kubernetes storage classes
+// @description Get the storage classes of the cluster
+// @description **Access policy**: authenticated
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @success 200 {array} models.K8sStorageClass "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/storage_classes [get]
+func (handler *Handler) getKubernetesStorageClasses(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	kcl, httpErr := handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	storageClasses, err := kcl.GetStorageClasses()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve storage classes", err)
+	}
+
+	return response.JSON(w, storageClasses)
+}
+
+// @id getKubernetesPersistentVolumes
+// @summary Get a list of kubernetes persistent volumes
+// @description Get the persistent volumes of the cluster
+// @description **Access policy**: administrator
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @success 200 {array} models.K8sPersistentVolume "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/persistent_volumes [get]
+func (handler *Handler) getKubernetesPersistentVolumes(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	kcl, httpErr := handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	volumes, err := kcl.GetPersistentVolumes()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve persistent volumes", err)
+	}
+
+	return response.JSON(w, volumes)
+}
+
+// @id getKubernetesPersistentVolumeClaims
+// @summary Get a list of kubernetes persistent volume claims
+// @description Get the persistent volume claims of a namespace with the applications mounting them.
+// @description The usage of the volumes is reported by the kubelet of the nodes running the applications when withUsage is set.
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param withUsage query boolean false "Retrieve the usage of the volumes"
+// @success 200 {array} models.K8sPersistentVolumeClaim "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/persistent_volume_claims [get]
+func (handler *Handler) getKubernetesPersistentVolumeClaims(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, _ := request.RetrieveRouteVariableValue(r, "namespace")
+	withUsage, _ := request.RetrieveBooleanQueryParameter(r, "withUsage", true)
+
+	kcl, httpErr := handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	claims, err := kcl.GetPersistentVolumeClaims(namespace, withUsage)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve persistent volume claims", err)
+	}
+
+	return response.JSON(w, claims)
+}
+
+// @id expandKubernetesPersistentVolumeClaim
+// @summary Expand a kubernetes persistent volume claim
+// @description Increase the storage request of a persistent volume claim, the storage class of the claim must allow volume expansion
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param name path string true "Persistent volume claim name"
+// @param body body models.K8sVolumeExpansionPayload true "New size of the volume"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Persistent volume claim not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/persistent_volume_claims/{name}/expand [put]
+func (handler *Handler) expandKubernetesPersistentVolumeClaim(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, _ := request.RetrieveRouteVariableValue(r, "namespace")
+
+	name, err := request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		return httperror.BadRequest("Invalid persistent volume claim name route variable", err)
+	}
+
+	var payload models.K8sVolumeExpansionPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	kcl, httpErr := handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if err := kcl.ExpandPersistentVolumeClaim(namespace, name, payload.Size); err != nil {
+		return volumeOperationError("Unable to expand the persistent volume claim", err)
+	}
+
+	return response.Empty(w)
+}
+
+// @id getKubernetesVolumeSnapshots
+// @summary Get a list of kubernetes volume snapshots
+// @description Get the volume snapshots of a namespace, the VolumeSnapshot CRDs must be installed in the cluster
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @success 200 {array} models.K8sVolumeSnapshot "Success"
+// @failure 400 "Invalid request or volume snapshots not available"
+// @failure 403 "Permission denied"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/volume_snapshots [get]
+func (handler *Handler) getKubernetesVolumeSnapshots(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, _ := request.RetrieveRouteVariableValue(r, "namespace")
+
+	kcl, httpErr := handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	snapshots, err := kcl.GetVolumeSnapshots(namespace)
+	if err != nil {
+		return volumeOperationError("Unable to retrieve volume snapshots", err)
+	}
+
+	return response.JSON(w, snapshots)
+}
+
+// @id createKubernetesVolumeSnapshot
+// @summary Create a kubernetes volume snapshot
+// @description Create a snapshot of a persistent volume claim, the VolumeSnapshot CRDs must be installed in the cluster
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param name path string true "Persistent volume claim name"
+// @param body body models.K8sVolumeSnapshotPayload true "Snapshot details"
+// @success 200 {object} models.K8sVolumeSnapshot "Success"
+// @failure 400 "Invalid request or volume snapshots not available"
+// @failure 403 "Permission denied"
+// @failure 404 "Persistent volume claim not found"
+// @failure 409 "A snapshot with the same name already exists"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/persistent_volume_claims/{name}/snapshots [post]
+func (handler *Handler) createKubernetesVolumeSnapshot(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, _ := request.RetrieveRouteVariableValue(r, "namespace")
+
+	name, err := request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		return httperror.BadRequest("Invalid persistent volume claim name route variable", err)
+	}
+
+	var payload models.K8sVolumeSnapshotPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	kcl, httpErr := handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	snapshot, err := kcl.CreateVolumeSnapshot(namespace, name, payload)
+	if err != nil {
+		return volumeOperationError("Unable to create the volume snapshot", err)
+	}
+
+	return response.JSON(w, snapshot)
+}
+
+// @id deleteKubernetesVolumeSnapshot
+// @summary Delete a kubernetes volume snapshot
+// @description Delete a volume snapshot, the VolumeSnapshot CRDs must be installed in the cluster
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param name path string true "Volume snapshot name"
+// @success 204 "Success"
+// @failure 400 "Invalid request or volume snapshots not available"
+// @failure 403 "Permission denied"
+// @failure 404 "Volume snapshot not found"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/volume_snapshots/{name} [delete]
+func (handler *Handler) deleteKubernetesVolumeSnapshot(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, _ := request.RetrieveRouteVariableValue(r, "namespace")
+
+	name, err := request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		return httperror.BadRequest("Invalid volume snapshot name route variable", err)
+	}
+
+	kcl, httpErr := handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if err := kcl.DeleteVolumeSnapshot(namespace, name); err != nil {
+		return volumeOperationError("Unable to delete the volume snapshot", err)
+	}
+
+	return response.Empty(w)
+}
+
+// @id restoreKubernetesVolumeSnapshot
+// @summary Restore a kubernetes volume snapshot
+// @description Create a new persistent volume claim populated with the content of a volume snapshot
+// @description **Access policy**: authenticated, with access to the namespace
+// @tags kubernetes
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Environment (Endpoint) identifier"
+// @param namespace path string true "Namespace"
+// @param name path string true "Volume snapshot name"
+// @param body body models.K8sVolumeSnapshotRestorePayload true "Restored persistent volume claim details"
+// @success 200 {object} models.K8sPersistentVolumeClaim "Success"
+// @failure 400 "Invalid request or volume snapshots not available"
+// @failure 403 "Permission denied"
+// @failure 404 "Volume snapshot not found"
+// @failure 409 "A persistent volume claim with the same name already exists"
+// @failure 500 "Server error"
+// @router /kubernetes/{id}/namespaces/{namespace}/volume_snapshots/{name}/restore [post]
+func (handler *Handler) restoreKubernetesVolumeSnapshot(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, _ := request.RetrieveRouteVariableValue(r, "namespace")
+
+	name, err := request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		return httperror.BadRequest("Invalid volume snapshot name route variable", err)
+	}
+
+	var payload models.K8sVolumeSnapshotRestorePayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	kcl, httpErr := handler.getAdminKubeClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	claim, err := kcl.RestoreVolumeSnapshot(namespace, name, payload)
+	if err != nil {
+		return volumeOperationError("Unable to restore the volume snapshot", err)
+	}
+
+	log.Info().
+		Str("namespace", namespace).
+		Str("snapshot", name).
+		Str("claim", claim.Name).
+		Msg("kubernetes volume snapshot restored")
+
+	return response.JSON(w, claim)
+}
+
+// getAdminKubeClient returns the Kubernetes client of the environment using the Portainer service account,
+// the access to the namespace must be verified beforehand
+func (handler *Handler) getAdminKubeClient(r *http.Request) (*cli.KubeClient, *httperror.HandlerError) {
+	endpoint, err := middlewares.FetchEndpoint(r)
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to find an environment on request context", err)
+	}
+
+	kcl, err := handler.KubernetesClientFactory.GetKubeClient(endpoint)
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to create Kubernetes client", err)
+	}
+
+	return kcl, nil
+}
+
+func volumeOperationError(message string, err error) *httperror.HandlerError {
+	switch {
+	case k8serrors.IsNotFound(err):
+		return httperror.NotFound(message, err)
+	case k8serrors.IsAlreadyExists(err), k8serrors.IsConflict(err):
+		return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: message, Err: err}
+	case errors.Is(err, cli.ErrVolumeSnapshotsUnavailable), errors.Is(err, cli.ErrVolumeNotExpandable), errors.Is(err, cli.ErrVolumeShrink), k8serrors.IsInvalid(err):
+		return httperror.BadRequest(message, err)
+	}
+
+	return httperror.InternalServerError(message, err)
+}
@@ -0,0 +1,125 @@
+package pendingoperations
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+
+	"github.com/gorilla/mux"
+)
+
+// redactedValue replaces the sensitive values of the request bodies returned to other users than the requester
+const redactedValue = "********"
+
+// sensitiveKeys are the parts of the names of the body fields whose values are redacted
+var sensitiveKeys = []string{"password", "secret", "token", "credential"}
+
+// Handler is the HTTP handler used to handle pending operation operations.
+type Handler struct {
+	*mux.Router
+	DataStore dataservices.DataStore
+	// OperationHandler is the API handler executing the approved operations
+	OperationHandler http.Handler
+	reviewMutex      *sync.Mutex
+}
+
+// NewHandler creates a handler to manage pending operation operations.
+func NewHandler(bouncer security.BouncerService) *Handler {
+	h := &Handler{
+		Router:      mux.NewRouter(),
+		reviewMutex: &sync.Mutex{},
+	}
+
+	h.Handle("/pending_operations",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.pendingOperationList))).Methods(http.MethodGet)
+	h.Handle("/pending_operations/{id}",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.pendingOperationInspect))).Methods(http.MethodGet)
+	h.Handle("/pending_operations/{id}",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.pendingOperationDelete))).Methods(http.MethodDelete)
+	h.Handle("/pending_operations/{id}/approve",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.pendingOperationApprove))).Methods(http.MethodPost)
+	h.Handle("/pending_operations/{id}/reject",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.pendingOperationReject))).Methods(http.MethodPost)
+
+	return h
+}
+
+// expirePendingOperation marks a pending operation as expired once its expiry date has passed
+// and reports whether its status changed
+func expirePendingOperation(operation *portainer.PendingOperation, now time.Time) bool {
+	if operation.Status != portainer.PendingOperationPending || now.Unix() < operation.ExpiryDate {
+		return false
+	}
+
+	operation.Status = portainer.PendingOperationExpired
+
+	return true
+}
+
+// redactPendingOperation hides the sensitive values of the stored request body when the operation is returned to
+// a user who neither requested it nor is an administrator, such as the members of an approver team.
+// The stored operation is left untouched.
+func redactPendingOperation(operation *portainer.PendingOperation, context *security.RestrictedRequestContext) *portainer.PendingOperation {
+	if context.IsAdmin || operation.RequestedBy == context.UserID || len(operation.Body) == 0 {
+		return operation
+	}
+
+	redacted := *operation
+	redacted.Body = redactBody(operation.Body)
+
+	return &redacted
+}
+
+// redactBody replaces the values of the sensitive fields and of the environment variables of a JSON body,
+// other bodies are removed as their content cannot be inspected
+func redactBody(body []byte) []byte {
+	var content any
+	if err := json.Unmarshal(body, &content); err != nil {
+		return nil
+	}
+
+	redacted, err := json.Marshal(redactValue(content, false))
+	if err != nil {
+		return nil
+	}
+
+	return redacted
+}
+
+func redactValue(value any, inEnv bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			name := strings.ToLower(key)
+			if isSensitiveKey(name) || (inEnv && name == "value") {
+				v[key] = redactedValue
+				continue
+			}
+
+			v[key] = redactValue(child, inEnv || name == "env")
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i], inEnv)
+		}
+	}
+
+	return value
+}
+
+func isSensitiveKey(name string) bool {
+	for _, key := range sensitiveKeys {
+		if strings.Contains(name, key) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,29 @@
+package pendingoperations
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/security"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_redactPendingOperation(t *testing.T) {
+	is := assert.New(t)
+
+	operation := &portainer.PendingOperation{
+		RequestedBy: 3,
+		Body:        []byte(`{"LDAPSettings":{"Password":"ldap"},"OAuthSettings":{"ClientSecret":"oauth"},"env":[{"name":"DB_PASSWORD","value":"db"}],"prune":true}`),
+	}
+
+	redacted := redactPendingOperation(operation, &security.RestrictedRequestContext{UserID: 2})
+	is.JSONEq(`{"LDAPSettings":{"Password":"********"},"OAuthSettings":{"ClientSecret":"********"},"env":[{"name":"DB_PASSWORD","value":"********"}],"prune":true}`, string(redacted.Body))
+	is.Contains(string(operation.Body), `"ldap"`, "the stored operation should not be changed")
+
+	is.Equal(operation, redactPendingOperation(operation, &security.RestrictedRequestContext{UserID: 3}), "the requester should see the body")
+	is.Equal(operation, redactPendingOperation(operation, &security.RestrictedRequestContext{UserID: 1, IsAdmin: true}), "administrators should see the body")
+
+	multipart := &portainer.PendingOperation{RequestedBy: 3, Body: []byte("--boundary\r\nContent-Disposition: form-data")}
+	is.Nil(redactPendingOperation(multipart, &security.RestrictedRequestContext{UserID: 2}).Body, "bodies which cannot be inspected should be removed")
+}
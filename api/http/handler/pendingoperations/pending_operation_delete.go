@@ -0,0 +1,46 @@
+package pendingoperations
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id PendingOperationDelete
+// @summary Remove a pending operation
+// @description Cancel a pending operation or remove a reviewed one from the history.
+// @description **Access policy**: administrator or user who requested a pending operation
+// @tags pending_operations
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Pending operation identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Pending operation not found"
+// @failure 500 "Server error"
+// @router /pending_operations/{id} [delete]
+func (handler *Handler) pendingOperationDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	operation, securityContext, _, httpErr := handler.retrievePendingOperation(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	canCancel := operation.RequestedBy == securityContext.UserID && operation.Status == portainer.PendingOperationPending
+	if !securityContext.IsAdmin && !canCancel {
+		return httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+	}
+
+	handler.reviewMutex.Lock()
+	defer handler.reviewMutex.Unlock()
+
+	err := handler.DataStore.PendingOperation().Delete(operation.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the pending operation from the database", err)
+	}
+
+	return response.Empty(w)
+}
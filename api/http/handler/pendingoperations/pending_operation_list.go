@@ -0,0 +1,142 @@
+package pendingoperations
+
+import (
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id PendingOperationList
+// @summary List pending operations
+// @description List the operations requested by the user. Administrators and members of an approver team can see
+// @description every operation, the sensitive values of the request bodies of other users are redacted for non-administrators.
+// @description **Access policy**: authenticated
+// @tags pending_operations
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param status query int false "Only list the operations with this status" Enums(1,2,3,4,5)
+// @success 200 {array} portainer.PendingOperation "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /pending_operations [get]
+func (handler *Handler) pendingOperationList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	status, err := request.RetrieveNumericQueryParameter(r, "status", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: status", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the settings from the database", err)
+	}
+
+	canViewAll := securityContext.IsAdmin || security.IsOperationApprover(&settings.OperationApproval, securityContext)
+
+	operations, err := handler.DataStore.PendingOperation().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve pending operations from the database", err)
+	}
+
+	now := time.Now()
+	filtered := make([]portainer.PendingOperation, 0)
+	for i := range operations {
+		operation := &operations[i]
+
+		if expirePendingOperation(operation, now) {
+			err := handler.DataStore.PendingOperation().Update(operation.ID, operation)
+			if err != nil {
+				return httperror.InternalServerError("Unable to persist the pending operation changes inside the database", err)
+			}
+		}
+
+		if !canViewAll && operation.RequestedBy != securityContext.UserID {
+			continue
+		}
+
+		if status != 0 && int(operation.Status) != status {
+			continue
+		}
+
+		filtered = append(filtered, *redactPendingOperation(operation, securityContext))
+	}
+
+	return response.JSON(w, filtered)
+}
+
+// @id PendingOperationInspect
+// @summary Inspect a pending operation
+// @description Retrieve details about a pending operation, including the stored request.
+// @description The sensitive values of the request body are redacted for other users than the requester and administrators.
+// @description **Access policy**: administrator, member of an approver team or user who requested the operation
+// @tags pending_operations
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Pending operation identifier"
+// @success 200 {object} portainer.PendingOperation "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Pending operation not found"
+// @failure 500 "Server error"
+// @router /pending_operations/{id} [get]
+func (handler *Handler) pendingOperationInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	operation, securityContext, _, httpErr := handler.retrievePendingOperation(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	return response.JSON(w, redactPendingOperation(operation, securityContext))
+}
+
+// retrievePendingOperation retrieves the pending operation of the request, marking it as expired when needed,
+// and reports whether the user is an approver. Users who are neither approvers nor administrators can only retrieve
+// their own operations.
+func (handler *Handler) retrievePendingOperation(r *http.Request) (*portainer.PendingOperation, *security.RestrictedRequestContext, bool, *httperror.HandlerError) {
+	operationID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, nil, false, httperror.BadRequest("Invalid pending operation identifier route variable", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return nil, nil, false, httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	operation, err := handler.DataStore.PendingOperation().Read(portainer.PendingOperationID(operationID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, nil, false, httperror.NotFound("Unable to find a pending operation with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, nil, false, httperror.InternalServerError("Unable to find a pending operation with the specified identifier inside the database", err)
+	}
+
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return nil, nil, false, httperror.InternalServerError("Unable to retrieve the settings from the database", err)
+	}
+
+	isApprover := security.IsOperationApprover(&settings.OperationApproval, securityContext)
+	if !isApprover && !securityContext.IsAdmin && operation.RequestedBy != securityContext.UserID {
+		return nil, nil, false, httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+	}
+
+	if expirePendingOperation(operation, time.Now()) {
+		err := handler.DataStore.PendingOperation().Update(operation.ID, operation)
+		if err != nil {
+			return nil, nil, false, httperror.InternalServerError("Unable to persist the pending operation changes inside the database", err)
+		}
+	}
+
+	return operation, securityContext, isApprover, nil
+}
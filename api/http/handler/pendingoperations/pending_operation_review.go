@@ -0,0 +1,183 @@
+package pendingoperations
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/pkg/errors"
+)
+
+// maxResultSize is the maximum size of the response of an executed operation kept with the operation
+const maxResultSize = 64 * 1024
+
+type pendingOperationReviewPayload struct {
+	// Comment of the reviewer
+	Comment string `example:"Approved during the maintenance window"`
+}
+
+func (payload *pendingOperationReviewPayload) Validate(r *http.Request) error {
+	return nil
+}
+
+// @id PendingOperationApprove
+// @summary Approve a pending operation
+// @description Approve a pending operation and execute the stored request on behalf of the reviewer.
+// @description The outcome of the execution is stored in the status and the result of the operation.
+// @description **Access policy**: member of an approver team, or administrator when no approver team is configured, other than the requester
+// @tags pending_operations
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Pending operation identifier"
+// @param body body pendingOperationReviewPayload false "Review details"
+// @success 200 {object} portainer.PendingOperation "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Pending operation not found"
+// @failure 500 "Server error"
+// @router /pending_operations/{id}/approve [post]
+func (handler *Handler) pendingOperationApprove(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	handler.reviewMutex.Lock()
+	defer handler.reviewMutex.Unlock()
+
+	operation, securityContext, httpErr := handler.reviewPendingOperation(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	handler.executePendingOperation(r, operation)
+
+	err := handler.savePendingOperationReview(operation, securityContext)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the pending operation changes inside the database", err)
+	}
+
+	return response.JSON(w, redactPendingOperation(operation, securityContext))
+}
+
+// @id PendingOperationReject
+// @summary Reject a pending operation
+// @description Reject a pending operation, the stored request is never executed.
+// @description **Access policy**: member of an approver team, or administrator when no approver team is configured, other than the requester
+// @tags pending_operations
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Pending operation identifier"
+// @param body body pendingOperationReviewPayload false "Review details"
+// @success 200 {object} portainer.PendingOperation "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Pending operation not found"
+// @failure 500 "Server error"
+// @router /pending_operations/{id}/reject [post]
+func (handler *Handler) pendingOperationReject(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	handler.reviewMutex.Lock()
+	defer handler.reviewMutex.Unlock()
+
+	operation, securityContext, httpErr := handler.reviewPendingOperation(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	operation.Status = portainer.PendingOperationRejected
+
+	err := handler.savePendingOperationReview(operation, securityContext)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the pending operation changes inside the database", err)
+	}
+
+	return response.JSON(w, redactPendingOperation(operation, securityContext))
+}
+
+// reviewPendingOperation retrieves a pending operation the user is allowed to review and records the review comment
+func (handler *Handler) reviewPendingOperation(r *http.Request) (*portainer.PendingOperation, *security.RestrictedRequestContext, *httperror.HandlerError) {
+	var payload pendingOperationReviewPayload
+	if r.ContentLength != 0 {
+		if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+			return nil, nil, httperror.BadRequest("Invalid request payload", err)
+		}
+	}
+
+	operation, securityContext, isApprover, httpErr := handler.retrievePendingOperation(r)
+	if httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	if !isApprover || operation.RequestedBy == securityContext.UserID {
+		return nil, nil, httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+	}
+
+	switch operation.Status {
+	case portainer.PendingOperationPending:
+	case portainer.PendingOperationExpired:
+		return nil, nil, httperror.BadRequest("The pending operation has expired", errors.New("pending operation expired"))
+	default:
+		return nil, nil, httperror.BadRequest("The pending operation was already reviewed", errors.New("pending operation is not pending"))
+	}
+
+	operation.ReviewComment = payload.Comment
+
+	return operation, securityContext, nil
+}
+
+// executePendingOperation replays the stored request of an operation through the API with the credentials
+// of the reviewer, so that the original handler applies its own access checks, and records the outcome
+func (handler *Handler) executePendingOperation(r *http.Request, operation *portainer.PendingOperation) {
+	req, err := http.NewRequestWithContext(r.Context(), operation.Method, operation.URL, bytes.NewReader(operation.Body))
+	if err != nil {
+		operation.Status = portainer.PendingOperationFailed
+		operation.Result = errors.Wrap(err, "unable to build the request of the operation").Error()
+		return
+	}
+
+	if operation.ContentType != "" {
+		req.Header.Set("Content-Type", operation.ContentType)
+	}
+
+	for _, header := range []string{"Authorization", "X-API-Key"} {
+		if value := r.Header.Get(header); value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+
+	if req.Header.Get("Authorization") == "" {
+		if token := r.URL.Query().Get("token"); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	req = req.WithContext(security.StoreApprovedOperation(req, operation.ID))
+
+	recorder := httptest.NewRecorder()
+	handler.OperationHandler.ServeHTTP(recorder, req)
+
+	operation.ResultStatusCode = recorder.Code
+	operation.Result = recorder.Body.String()
+	if len(operation.Result) > maxResultSize {
+		operation.Result = operation.Result[:maxResultSize]
+	}
+
+	operation.Status = portainer.PendingOperationExecuted
+	if recorder.Code >= http.StatusBadRequest {
+		operation.Status = portainer.PendingOperationFailed
+	}
+}
+
+func (handler *Handler) savePendingOperationReview(operation *portainer.PendingOperation, securityContext *security.RestrictedRequestContext) error {
+	operation.ReviewedBy = securityContext.UserID
+	operation.ReviewDate = time.Now().Unix()
+
+	return handler.DataStore.PendingOperation().Update(operation.ID, operation)
+}
@@ -0,0 +1,89 @@
+package policies
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// Handler is the HTTP handler used to handle policy operations.
+type Handler struct {
+	*mux.Router
+	DataStore dataservices.DataStore
+}
+
+// NewHandler creates a handler to manage policy operations.
+func NewHandler(bouncer security.BouncerService) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+
+	h.Handle("/policies",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.policyList))).Methods(http.MethodGet)
+	h.Handle("/policies",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.policyCreate))).Methods(http.MethodPost)
+	h.Handle("/policies/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.policyInspect))).Methods(http.MethodGet)
+	h.Handle("/policies/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.policyUpdate))).Methods(http.MethodPut)
+	h.Handle("/policies/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.policyDelete))).Methods(http.MethodDelete)
+
+	return h
+}
+
+func (handler *Handler) retrievePolicy(r *http.Request) (*portainer.Policy, *httperror.HandlerError) {
+	policyID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid policy identifier route variable", err)
+	}
+
+	policy, err := handler.DataStore.Policy().Read(portainer.PolicyID(policyID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find a policy with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find a policy with the specified identifier inside the database", err)
+	}
+
+	return policy, nil
+}
+
+// validatePolicy makes sure that the name of the policy is unique and that its environment groups exist
+func (handler *Handler) validatePolicy(policy *portainer.Policy) *httperror.HandlerError {
+	policies, err := handler.DataStore.Policy().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the policies from the database", err)
+	}
+
+	for _, existing := range policies {
+		if existing.ID != policy.ID && existing.Name == policy.Name {
+			return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: "This name is already associated to a policy", Err: errors.New("a policy already exists with this name")}
+		}
+	}
+
+	for _, endpointGroupID := range policy.EndpointGroupIDs {
+		_, err := handler.DataStore.EndpointGroup().Read(endpointGroupID)
+		if handler.DataStore.IsErrObjectNotFound(err) {
+			return httperror.BadRequest("Unable to find an environment group with the specified identifier inside the database", err)
+		} else if err != nil {
+			return httperror.InternalServerError("Unable to find an environment group with the specified identifier inside the database", err)
+		}
+	}
+
+	return nil
+}
+
+func validateMode(mode portainer.PolicyMode) error {
+	if mode != portainer.PolicyModeEnforce && mode != portainer.PolicyModeAudit {
+		return errors.New("invalid mode. must be enforce or audit")
+	}
+
+	return nil
+}
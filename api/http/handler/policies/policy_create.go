@@ -0,0 +1,91 @@
+package policies
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/policy"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/pkg/errors"
+)
+
+type policyCreatePayload struct {
+	// Name of the policy
+	Name string `validate:"required" example:"production"`
+	// Description of the policy
+	Description string `example:"Rules of the production environments"`
+	// Whether the violations refuse the requests or are only logged, defaults to enforce
+	Mode portainer.PolicyMode `example:"enforce" enums:"enforce,audit"`
+	// Environment(Endpoint) groups the policy applies to
+	EndpointGroupIDs []portainer.EndpointGroupID `example:"1"`
+	// Rules of the policy
+	Rules portainer.PolicyRules
+}
+
+func (payload *policyCreatePayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.Name) {
+		return errors.New("invalid name. cannot be empty")
+	}
+
+	if payload.Mode == "" {
+		payload.Mode = portainer.PolicyModeEnforce
+	}
+
+	if err := validateMode(payload.Mode); err != nil {
+		return err
+	}
+
+	return policy.ValidateRules(payload.Rules)
+}
+
+// @id PolicyCreate
+// @summary Create a policy
+// @description Create a policy evaluated against the containers and services created on the Docker environments of its groups,
+// @description either through the Docker API or by deploying a stack. The requests violating a policy in audit mode are only logged.
+// @description **Access policy**: administrator
+// @tags policies
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param body body policyCreatePayload true "Policy details"
+// @success 201 {object} portainer.Policy "Created"
+// @failure 400 "Invalid request"
+// @failure 409 "Policy name exists"
+// @failure 500 "Server error"
+// @router /policies [post]
+func (handler *Handler) policyCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload policyCreatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	policy := &portainer.Policy{
+		Name:             payload.Name,
+		Description:      payload.Description,
+		Mode:             payload.Mode,
+		EndpointGroupIDs: payload.EndpointGroupIDs,
+		Rules:            payload.Rules,
+	}
+
+	if policy.EndpointGroupIDs == nil {
+		policy.EndpointGroupIDs = []portainer.EndpointGroupID{}
+	}
+
+	if httpErr := handler.validatePolicy(policy); httpErr != nil {
+		return httpErr
+	}
+
+	err = handler.DataStore.Policy().Create(policy)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the policy inside the database", err)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	return response.JSON(w, policy)
+}
@@ -0,0 +1,35 @@
+package policies
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id PolicyDelete
+// @summary Remove a policy
+// @description Remove a policy, it no longer applies to the environments of its groups.
+// @description **Access policy**: administrator
+// @tags policies
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Policy identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Policy not found"
+// @failure 500 "Server error"
+// @router /policies/{id} [delete]
+func (handler *Handler) policyDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policy, httpErr := handler.retrievePolicy(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	err := handler.DataStore.Policy().Delete(policy.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the policy from the database", err)
+	}
+
+	return response.Empty(w)
+}
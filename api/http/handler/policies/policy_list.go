@@ -0,0 +1,51 @@
+package policies
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id PolicyList
+// @summary List the policies
+// @description List the policies and the environment groups they apply to.
+// @description **Access policy**: administrator
+// @tags policies
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {array} portainer.Policy "Success"
+// @failure 500 "Server error"
+// @router /policies [get]
+func (handler *Handler) policyList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policies, err := handler.DataStore.Policy().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the policies from the database", err)
+	}
+
+	return response.JSON(w, policies)
+}
+
+// @id PolicyInspect
+// @summary Inspect a policy
+// @description Retrieve the details of a policy.
+// @description **Access policy**: administrator
+// @tags policies
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Policy identifier"
+// @success 200 {object} portainer.Policy "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Policy not found"
+// @failure 500 "Server error"
+// @router /policies/{id} [get]
+func (handler *Handler) policyInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policy, httpErr := handler.retrievePolicy(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	return response.JSON(w, policy)
+}
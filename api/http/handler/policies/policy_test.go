@@ -0,0 +1,100 @@
+package policies
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/apikey"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/jwt"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_policies(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, true)
+
+	adminUser := &portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole}
+	is.NoError(store.User().Create(adminUser))
+
+	is.NoError(store.EndpointGroup().Create(&portainer.EndpointGroup{ID: 2, Name: "production"}))
+
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+
+	h := NewHandler(requestBouncer)
+	h.DataStore = store
+
+	token, _ := jwtService.GenerateToken(&portainer.TokenData{ID: adminUser.ID, Username: adminUser.Username, Role: adminUser.Role})
+
+	doRequest := func(method, url string, payload any) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if payload != nil {
+			is.NoError(json.NewEncoder(&body).Encode(payload))
+		}
+
+		req := httptest.NewRequest(method, url, &body)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	rr := doRequest(http.MethodPost, "/policies", map[string]any{
+		"Name":             "production",
+		"EndpointGroupIDs": []int{2},
+		"Rules":            map[string]any{"RequiredLabels": []string{"owner"}, "ForbiddenPorts": []string{"22"}},
+	})
+	is.Equal(http.StatusCreated, rr.Code)
+
+	var policy portainer.Policy
+	is.NoError(json.NewDecoder(rr.Body).Decode(&policy))
+	is.Equal(portainer.PolicyModeEnforce, policy.Mode, "the policies are enforced by default")
+	is.Equal([]portainer.EndpointGroupID{2}, policy.EndpointGroupIDs)
+
+	rr = doRequest(http.MethodPost, "/policies", map[string]any{"Name": "production"})
+	is.Equal(http.StatusConflict, rr.Code)
+
+	rr = doRequest(http.MethodPost, "/policies", map[string]any{"Name": "staging", "EndpointGroupIDs": []int{3}})
+	is.Equal(http.StatusBadRequest, rr.Code, "the environment groups must exist")
+
+	rr = doRequest(http.MethodPost, "/policies", map[string]any{"Name": "staging", "Mode": "warn"})
+	is.Equal(http.StatusBadRequest, rr.Code)
+
+	rr = doRequest(http.MethodPost, "/policies", map[string]any{"Name": "staging", "Rules": map[string]any{"ForbiddenPorts": []string{"ssh"}}})
+	is.Equal(http.StatusBadRequest, rr.Code)
+
+	rr = doRequest(http.MethodPut, fmt.Sprintf("/policies/%d", policy.ID), map[string]any{"Mode": "audit"})
+	is.Equal(http.StatusOK, rr.Code)
+
+	rr = doRequest(http.MethodGet, fmt.Sprintf("/policies/%d", policy.ID), nil)
+	is.Equal(http.StatusOK, rr.Code)
+	is.NoError(json.NewDecoder(rr.Body).Decode(&policy))
+	is.Equal(portainer.PolicyModeAudit, policy.Mode)
+	is.Equal([]string{"owner"}, policy.Rules.RequiredLabels, "the rules are kept when they are not updated")
+
+	rr = doRequest(http.MethodGet, "/policies", nil)
+	is.Equal(http.StatusOK, rr.Code)
+
+	var policies []portainer.Policy
+	is.NoError(json.NewDecoder(rr.Body).Decode(&policies))
+	is.Len(policies, 1)
+
+	rr = doRequest(http.MethodDelete, fmt.Sprintf("/policies/%d", policy.ID), nil)
+	is.Equal(http.StatusNoContent, rr.Code)
+
+	rr = doRequest(http.MethodGet, fmt.Sprintf("/policies/%d", policy.ID), nil)
+	is.Equal(http.StatusNotFound, rr.Code)
+}
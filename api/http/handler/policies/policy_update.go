@@ -0,0 +1,106 @@
+package policies
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/policy"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/pkg/errors"
+)
+
+type policyUpdatePayload struct {
+	// Name of the policy
+	Name *string `example:"production"`
+	// Description of the policy
+	Description *string `example:"Rules of the production environments"`
+	// Whether the violations refuse the requests or are only logged
+	Mode *portainer.PolicyMode `example:"audit" enums:"enforce,audit"`
+	// Environment(Endpoint) groups the policy applies to
+	EndpointGroupIDs []portainer.EndpointGroupID `example:"1"`
+	// Rules of the policy, they replace the existing rules
+	Rules *portainer.PolicyRules
+}
+
+func (payload *policyUpdatePayload) Validate(r *http.Request) error {
+	if payload.Name != nil && govalidator.IsNull(*payload.Name) {
+		return errors.New("invalid name. cannot be empty")
+	}
+
+	if payload.Mode != nil {
+		if err := validateMode(*payload.Mode); err != nil {
+			return err
+		}
+	}
+
+	if payload.Rules != nil {
+		return policy.ValidateRules(*payload.Rules)
+	}
+
+	return nil
+}
+
+// @id PolicyUpdate
+// @summary Update a policy
+// @description Update a policy, the new rules apply to the next requests.
+// @description **Access policy**: administrator
+// @tags policies
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Policy identifier"
+// @param body body policyUpdatePayload true "Policy details"
+// @success 200 {object} portainer.Policy "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Policy not found"
+// @failure 409 "Policy name exists"
+// @failure 500 "Server error"
+// @router /policies/{id} [put]
+func (handler *Handler) policyUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policy, httpErr := handler.retrievePolicy(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	var payload policyUpdatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	if payload.Name != nil {
+		policy.Name = *payload.Name
+	}
+
+	if payload.Description != nil {
+		policy.Description = *payload.Description
+	}
+
+	if payload.Mode != nil {
+		policy.Mode = *payload.Mode
+	}
+
+	if payload.EndpointGroupIDs != nil {
+		policy.EndpointGroupIDs = payload.EndpointGroupIDs
+	}
+
+	if payload.Rules != nil {
+		policy.Rules = *payload.Rules
+	}
+
+	if httpErr := handler.validatePolicy(policy); httpErr != nil {
+		return httpErr
+	}
+
+	err = handler.DataStore.Policy().Update(policy.ID, policy)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the policy changes inside the database", err)
+	}
+
+	return response.JSON(w, policy)
+}
@@ -25,11 +25,12 @@ func hideFields(registry *portainer.Registry, hideAccesses bool) {
 // Handler is the HTTP handler used to handle registry operations.
 type Handler struct {
 	*mux.Router
-	requestBouncer   security.BouncerService
-	DataStore        dataservices.DataStore
-	FileService      portainer.FileService
-	ProxyManager     *proxy.Manager
-	K8sClientFactory *cli.ClientFactory
+	requestBouncer    security.BouncerService
+	DataStore         dataservices.DataStore
+	FileService       portainer.FileService
+	ProxyManager      *proxy.Manager
+	K8sClientFactory  *cli.ClientFactory
+	SwarmStackManager portainer.SwarmStackManager
 }
 
 // NewHandler creates a handler to manage registry operations.
@@ -56,8 +57,10 @@ func (handler *Handler) initRouter(bouncer accessGuard) {
 
 	adminRouter.Handle("/registries", httperror.LoggerHandler(handler.registryList)).Methods(http.MethodGet)
 	adminRouter.Handle("/registries", httperror.LoggerHandler(handler.registryCreate)).Methods(http.MethodPost)
+	adminRouter.Handle("/registries/expiring", httperror.LoggerHandler(handler.registryExpiring)).Methods(http.MethodGet)
 	adminRouter.Handle("/registries/{id}", httperror.LoggerHandler(handler.registryUpdate)).Methods(http.MethodPut)
 	adminRouter.Handle("/registries/{id}/configure", httperror.LoggerHandler(handler.registryConfigure)).Methods(http.MethodPost)
+	adminRouter.Handle("/registries/{id}/rotate", httperror.LoggerHandler(handler.registryRotate)).Methods(http.MethodPost)
 	adminRouter.Handle("/registries/{id}", httperror.LoggerHandler(handler.registryDelete)).Methods(http.MethodDelete)
 
 	authenticatedRouter.Handle("/registries/{id}", httperror.LoggerHandler(handler.registryInspect)).Methods(http.MethodGet)
@@ -0,0 +1,102 @@
+package registries
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+const defaultExpiryWindow = 7 * 24 * time.Hour
+
+const (
+	// credentialSourcePassword identifies the credentials configured on the registry
+	credentialSourcePassword = "credentials"
+	// credentialSourceAccessToken identifies the temporary access token retrieved for ECR registries
+	credentialSourceAccessToken = "accessToken"
+)
+
+type registryExpiringCredential struct {
+	RegistryID   portainer.RegistryID   `json:"RegistryId" example:"1"`
+	RegistryName string                 `json:"RegistryName" example:"my-registry"`
+	RegistryType portainer.RegistryType `json:"RegistryType" example:"7"`
+	// Kind of credential that expires, either "credentials" or "accessToken"
+	Source string `json:"Source" example:"credentials"`
+	// Unix timestamp of the expiry
+	ExpiresAt int64 `json:"ExpiresAt" example:"1700000000"`
+	Expired   bool  `json:"Expired" example:"false"`
+}
+
+// @id RegistryExpiringCredentials
+// @summary List registry credentials about to expire
+// @description List the registry credentials and access tokens that are expired or will expire within the given window.
+// @description **Access policy**: administrator
+// @tags registries
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param within query int false "Window in seconds, defaults to 7 days"
+// @success 200 {array} registryExpiringCredential "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /registries/expiring [get]
+func (handler *Handler) registryExpiring(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	within, err := request.RetrieveNumericQueryParameter(r, "within", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: within", err)
+	}
+
+	window := defaultExpiryWindow
+	if within > 0 {
+		window = time.Duration(within) * time.Second
+	}
+
+	registries, err := handler.DataStore.Registry().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve registries from the database", err)
+	}
+
+	return response.JSON(w, expiringCredentials(registries, time.Now(), window))
+}
+
+func expiringCredentials(registries []portainer.Registry, now time.Time, window time.Duration) []registryExpiringCredential {
+	deadline := now.Add(window).Unix()
+
+	expiring := make([]registryExpiringCredential, 0)
+	add := func(registry portainer.Registry, source string, expiresAt int64) {
+		if expiresAt == 0 || expiresAt > deadline {
+			return
+		}
+
+		expiring = append(expiring, registryExpiringCredential{
+			RegistryID:   registry.ID,
+			RegistryName: registry.Name,
+			RegistryType: registry.Type,
+			Source:       source,
+			ExpiresAt:    expiresAt,
+			Expired:      expiresAt <= now.Unix(),
+		})
+	}
+
+	for _, registry := range registries {
+		if !registry.Authentication {
+			continue
+		}
+
+		add(registry, credentialSourcePassword, registry.CredentialsExpiry)
+
+		if registry.Type == portainer.EcrRegistry {
+			add(registry, credentialSourceAccessToken, registry.AccessTokenExpiry)
+		}
+	}
+
+	sort.Slice(expiring, func(i, j int) bool {
+		return expiring[i].ExpiresAt < expiring[j].ExpiresAt
+	})
+
+	return expiring
+}
@@ -0,0 +1,39 @@
+package registries
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_expiringCredentials(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+
+	registries := []portainer.Registry{
+		{ID: 1, Name: "no-auth", Authentication: false, CredentialsExpiry: now.Unix() - 10},
+		{ID: 2, Name: "never-expires", Authentication: true},
+		{ID: 3, Name: "expired", Authentication: true, CredentialsExpiry: now.Unix() - 10},
+		{ID: 4, Name: "soon", Authentication: true, CredentialsExpiry: now.Add(time.Hour).Unix()},
+		{ID: 5, Name: "later", Authentication: true, CredentialsExpiry: now.Add(48 * time.Hour).Unix()},
+		{ID: 6, Name: "ecr", Type: portainer.EcrRegistry, Authentication: true, AccessTokenExpiry: now.Add(30 * time.Minute).Unix()},
+		{ID: 7, Name: "custom-token", Type: portainer.CustomRegistry, Authentication: true, AccessTokenExpiry: now.Add(30 * time.Minute).Unix()},
+	}
+
+	result := expiringCredentials(registries, now, 24*time.Hour)
+
+	assert.Len(t, result, 3)
+
+	assert.Equal(t, portainer.RegistryID(3), result[0].RegistryID)
+	assert.Equal(t, credentialSourcePassword, result[0].Source)
+	assert.True(t, result[0].Expired)
+
+	assert.Equal(t, portainer.RegistryID(6), result[1].RegistryID)
+	assert.Equal(t, credentialSourceAccessToken, result[1].Source)
+	assert.False(t, result[1].Expired)
+
+	assert.Equal(t, portainer.RegistryID(4), result[2].RegistryID)
+	assert.False(t, result[2].Expired)
+}
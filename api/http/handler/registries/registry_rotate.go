@@ -0,0 +1,174 @@
+package registries
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/endpointutils"
+	"github.com/portainer/portainer/api/internal/registryutils"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/rs/zerolog/log"
+)
+
+type registryRotatePayload struct {
+	// Username or AccessKeyID used to authenticate against this registry. Keeps the current value when empty
+	Username string `example:"registry_user"`
+	// New password or SecretAccessKey used to authenticate against this registry
+	Password string `example:"registry_password" validate:"required"`
+	// Unix timestamp after which the new credentials are no longer valid, 0 if they never expire
+	CredentialsExpiry int64 `example:"1700000000"`
+}
+
+func (payload *registryRotatePayload) Validate(_ *http.Request) error {
+	if govalidator.IsNull(payload.Password) {
+		return errors.New("Invalid password")
+	}
+
+	if payload.CredentialsExpiry < 0 {
+		return errors.New("Invalid credentials expiry")
+	}
+
+	return nil
+}
+
+type registryRotateEndpointResult struct {
+	EndpointID portainer.EndpointID `json:"EndpointId" example:"1"`
+	// Kubernetes namespaces in which the registry secret was re-created
+	Namespaces []string `json:"Namespaces,omitempty"`
+	// True when the Swarm login of the environment was refreshed
+	SwarmLogin bool `json:"SwarmLogin,omitempty"`
+	// Error encountered while propagating the new credentials to the environment
+	Error string `json:"Error,omitempty"`
+}
+
+type registryRotateResponse struct {
+	Registry  *portainer.Registry            `json:"Registry"`
+	Endpoints []registryRotateEndpointResult `json:"Endpoints"`
+}
+
+// @id RegistryRotateCredentials
+// @summary Rotate the credentials of a registry
+// @description Replace the credentials of a registry and propagate them to every environment using it.
+// @description Kubernetes registry secrets are re-created in every namespace the registry is assigned to
+// @description and Swarm environments are logged in again with the new credentials.
+// @description **Access policy**: administrator
+// @tags registries
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Registry identifier"
+// @param body body registryRotatePayload true "New registry credentials"
+// @success 200 {object} registryRotateResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Registry not found"
+// @failure 500 "Server error"
+// @router /registries/{id}/rotate [post]
+func (handler *Handler) registryRotate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	registryID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid registry identifier route variable", err)
+	}
+
+	var payload registryRotatePayload
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	registry, err := handler.DataStore.Registry().Read(portainer.RegistryID(registryID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a registry with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a registry with the specified identifier inside the database", err)
+	}
+
+	if !registry.Authentication {
+		return httperror.BadRequest("Unable to rotate the credentials of a registry without authentication", errors.New("registry authentication is disabled"))
+	}
+
+	if payload.Username != "" {
+		registry.Username = payload.Username
+	}
+	registry.Password = payload.Password
+	registry.CredentialsExpiry = payload.CredentialsExpiry
+	registry.CredentialsRotatedAt = time.Now().Unix()
+
+	registry.AccessToken = ""
+	registry.AccessTokenExpiry = 0
+	registry.ManagementConfiguration = syncConfig(registry)
+
+	// the new ECR credentials are checked before they replace the stored ones
+	if registry.Type == portainer.EcrRegistry {
+		err = registryutils.RefreshRegToken(registry)
+		if err != nil {
+			return httperror.BadRequest("Unable to retrieve an access token with the new credentials", err)
+		}
+	}
+
+	err = handler.DataStore.Registry().Update(registry.ID, registry)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist registry changes inside the database", err)
+	}
+
+	results := handler.propagateRegistryCredentials(registry)
+
+	hideFields(registry, false)
+
+	return response.JSON(w, registryRotateResponse{Registry: registry, Endpoints: results})
+}
+
+// propagateRegistryCredentials pushes the current credentials of the registry to every environment it is assigned to.
+// Failures are reported per environment so that one unreachable environment does not prevent the others from being updated.
+func (handler *Handler) propagateRegistryCredentials(registry *portainer.Registry) []registryRotateEndpointResult {
+	results := make([]registryRotateEndpointResult, 0, len(registry.RegistryAccesses))
+
+	for endpointID, endpointAccess := range registry.RegistryAccesses {
+		result := registryRotateEndpointResult{EndpointID: endpointID}
+
+		endpoint, err := handler.DataStore.Endpoint().Endpoint(endpointID)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+
+			continue
+		}
+
+		switch {
+		case endpointutils.IsKubernetesEndpoint(endpoint):
+			err = handler.updateEndpointRegistryAccess(endpoint, registry, endpointAccess)
+			if err == nil {
+				result.Namespaces = endpointAccess.Namespaces
+			}
+		case isSwarmEndpoint(endpoint) && handler.SwarmStackManager != nil:
+			err = handler.SwarmStackManager.Login([]portainer.Registry{*registry}, endpoint)
+			if err == nil {
+				result.SwarmLogin = true
+			}
+		}
+
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Int("registry_id", int(registry.ID)).
+				Int("endpoint_id", int(endpointID)).
+				Msg("unable to propagate the registry credentials")
+
+			result.Error = err.Error()
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func isSwarmEndpoint(endpoint *portainer.Endpoint) bool {
+	return endpointutils.IsDockerEndpoint(endpoint) && len(endpoint.Snapshots) > 0 && endpoint.Snapshots[0].Swarm
+}
@@ -0,0 +1,104 @@
+package registries
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+	"github.com/portainer/portainer/api/kubernetes/cli"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+type testSwarmStackManager struct {
+	portainer.SwarmStackManager
+	logins map[portainer.EndpointID][]portainer.Registry
+}
+
+func (manager *testSwarmStackManager) Login(registries []portainer.Registry, endpoint *portainer.Endpoint) error {
+	manager.logins[endpoint.ID] = registries
+	return nil
+}
+
+func Test_registryRotate(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	kubeEndpoint := &portainer.Endpoint{ID: 1, Name: "kube", Type: portainer.KubernetesLocalEnvironment}
+	swarmEndpoint := &portainer.Endpoint{ID: 2, Name: "swarm", Type: portainer.DockerEnvironment, Snapshots: []portainer.DockerSnapshot{{Swarm: true}}}
+	for _, endpoint := range []*portainer.Endpoint{kubeEndpoint, swarmEndpoint} {
+		is.NoError(store.Endpoint().Create(endpoint))
+	}
+
+	registry := &portainer.Registry{
+		ID:             1,
+		Name:           "private",
+		Type:           portainer.CustomRegistry,
+		URL:            "registry.local",
+		Authentication: true,
+		Username:       "user",
+		Password:       "old",
+		RegistryAccesses: portainer.RegistryAccesses{
+			kubeEndpoint.ID:  {Namespaces: []string{"default", "apps"}},
+			swarmEndpoint.ID: {},
+		},
+	}
+	is.NoError(store.Registry().Create(registry))
+
+	kubeClientFactory, err := cli.NewClientFactory(nil, nil, store, "", "", "")
+	is.NoError(err)
+
+	kubeClientset := kfake.NewSimpleClientset()
+	kubeClientFactory.SetKubeClient(kubeEndpoint.ID, cli.NewKubeClient(kubeClientset, ""))
+
+	swarmStackManager := &testSwarmStackManager{logins: map[portainer.EndpointID][]portainer.Registry{}}
+
+	handler := NewHandler(testhelpers.NewTestRequestBouncer())
+	handler.DataStore = store
+	handler.K8sClientFactory = kubeClientFactory
+	handler.SwarmStackManager = swarmStackManager
+
+	req := httptest.NewRequest(http.MethodPost, "/registries/1/rotate", strings.NewReader(`{"Password": "new"}`))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	is.Equal(http.StatusOK, rr.Code, rr.Body.String())
+
+	var resp registryRotateResponse
+	is.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+	is.Empty(resp.Registry.Password, "the password is not returned")
+	is.ElementsMatch([]registryRotateEndpointResult{
+		{EndpointID: kubeEndpoint.ID, Namespaces: []string{"default", "apps"}},
+		{EndpointID: swarmEndpoint.ID, SwarmLogin: true},
+	}, resp.Endpoints)
+
+	stored, err := store.Registry().Read(registry.ID)
+	is.NoError(err)
+	is.Equal("new", stored.Password)
+	is.Equal("user", stored.Username, "the username is kept when it is not sent")
+
+	for _, namespace := range []string{"default", "apps"} {
+		secrets, err := kubeClientset.CoreV1().Secrets(namespace).List(context.Background(), metav1.ListOptions{})
+		is.NoError(err)
+		if is.Len(secrets.Items, 1, "the secret is created in the namespace %s", namespace) {
+			is.Contains(string(secrets.Items[0].Data[".dockerconfigjson"]), `"password":"new"`)
+		}
+	}
+
+	if is.Len(swarmStackManager.logins[swarmEndpoint.ID], 1) {
+		is.Equal("new", swarmStackManager.logins[swarmEndpoint.ID][0].Password, "the swarm environment is logged in with the new password")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/registries/1/rotate", strings.NewReader(`{"Password": ""}`))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	is.Equal(http.StatusBadRequest, rr.Code)
+}
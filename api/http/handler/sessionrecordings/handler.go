@@ -0,0 +1,55 @@
+package sessionrecordings
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/sessionrecording"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+
+	"github.com/gorilla/mux"
+)
+
+// Handler is the HTTP handler used to handle session recording operations.
+type Handler struct {
+	*mux.Router
+	DataStore               dataservices.DataStore
+	SessionRecordingService *sessionrecording.Service
+}
+
+// NewHandler creates a handler to manage session recording operations.
+func NewHandler(bouncer security.BouncerService) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+
+	h.Handle("/session_recordings",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.sessionRecordingList))).Methods(http.MethodGet)
+	h.Handle("/session_recordings/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.sessionRecordingInspect))).Methods(http.MethodGet)
+	h.Handle("/session_recordings/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.sessionRecordingDelete))).Methods(http.MethodDelete)
+	h.Handle("/session_recordings/{id}/file",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.sessionRecordingFile))).Methods(http.MethodGet)
+
+	return h
+}
+
+func (handler *Handler) retrieveSessionRecording(r *http.Request) (*portainer.SessionRecording, *httperror.HandlerError) {
+	recordingID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid session recording identifier route variable", err)
+	}
+
+	recording, err := handler.DataStore.SessionRecording().Read(portainer.SessionRecordingID(recordingID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find a session recording with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find a session recording with the specified identifier inside the database", err)
+	}
+
+	return recording, nil
+}
@@ -0,0 +1,35 @@
+package sessionrecordings
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id SessionRecordingDelete
+// @summary Remove a terminal session recording
+// @description Remove a terminal session recording and its file.
+// @description **Access policy**: administrator
+// @tags session_recordings
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Session recording identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Session recording not found"
+// @failure 500 "Server error"
+// @router /session_recordings/{id} [delete]
+func (handler *Handler) sessionRecordingDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	recording, httpErr := handler.retrieveSessionRecording(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	err := handler.SessionRecordingService.Delete(recording.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the session recording", err)
+	}
+
+	return response.Empty(w)
+}
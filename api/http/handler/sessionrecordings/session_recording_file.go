@@ -0,0 +1,45 @@
+package sessionrecordings
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+)
+
+// @id SessionRecordingFile
+// @summary Download a terminal session recording
+// @description Download the asciicast v2 file of a terminal session recording.
+// @description **Access policy**: administrator
+// @tags session_recordings
+// @security ApiKeyAuth
+// @security jwt
+// @produce octet-stream
+// @param id path int true "Session recording identifier"
+// @success 200 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Session recording not found"
+// @failure 500 "Server error"
+// @router /session_recordings/{id}/file [get]
+func (handler *Handler) sessionRecordingFile(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	recording, httpErr := handler.retrieveSessionRecording(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	path := handler.SessionRecordingService.FilePath(recording.ID)
+
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return httperror.NotFound("Unable to find the session recording file", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to read the session recording file", err)
+	}
+
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=session-%d.cast", recording.ID))
+	http.ServeFile(w, r, path)
+
+	return nil
+}
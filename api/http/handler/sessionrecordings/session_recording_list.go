@@ -0,0 +1,79 @@
+package sessionrecordings
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id SessionRecordingList
+// @summary List terminal session recordings
+// @description List the recordings of the console, attach and pod exec sessions.
+// @description **Access policy**: administrator
+// @tags session_recordings
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param endpointId query int false "Only list the recordings of this environment"
+// @param userId query int false "Only list the recordings of this user"
+// @success 200 {array} portainer.SessionRecording "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /session_recordings [get]
+func (handler *Handler) sessionRecordingList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpointID, err := request.RetrieveNumericQueryParameter(r, "endpointId", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: endpointId", err)
+	}
+
+	userID, err := request.RetrieveNumericQueryParameter(r, "userId", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: userId", err)
+	}
+
+	recordings, err := handler.DataStore.SessionRecording().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve session recordings from the database", err)
+	}
+
+	filtered := make([]portainer.SessionRecording, 0)
+	for _, recording := range recordings {
+		if endpointID != 0 && int(recording.EndpointID) != endpointID {
+			continue
+		}
+
+		if userID != 0 && int(recording.UserID) != userID {
+			continue
+		}
+
+		filtered = append(filtered, recording)
+	}
+
+	return response.JSON(w, filtered)
+}
+
+// @id SessionRecordingInspect
+// @summary Inspect a terminal session recording
+// @description Retrieve the details of a terminal session recording.
+// @description **Access policy**: administrator
+// @tags session_recordings
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Session recording identifier"
+// @success 200 {object} portainer.SessionRecording "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Session recording not found"
+// @failure 500 "Server error"
+// @router /session_recordings/{id} [get]
+func (handler *Handler) sessionRecordingInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	recording, httpErr := handler.retrieveSessionRecording(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	return response.JSON(w, recording)
+}
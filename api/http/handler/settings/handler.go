@@ -38,7 +38,7 @@ func NewHandler(bouncer security.BouncerService, demoService *demo.Service) *Han
 	h.Handle("/settings",
 		bouncer.AdminAccess(httperror.LoggerHandler(h.settingsInspect))).Methods(http.MethodGet)
 	h.Handle("/settings",
-		bouncer.AdminAccess(httperror.LoggerHandler(h.settingsUpdate))).Methods(http.MethodPut)
+		bouncer.AuthenticatedAccess(bouncer.OperationApproval(portainer.OperationPortainerSettingsUpdate, security.AdminOperationAuthorizer, bouncer.AdminAccess(httperror.LoggerHandler(h.settingsUpdate))))).Methods(http.MethodPut)
 	h.Handle("/settings/public",
 		bouncer.PublicAccess(httperror.LoggerHandler(h.settingsPublic))).Methods(http.MethodGet)
 
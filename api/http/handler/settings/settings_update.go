@@ -2,12 +2,14 @@ package settings
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/edge"
 	"github.com/portainer/portainer/pkg/libhelm"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
@@ -54,6 +56,14 @@ type settingsUpdatePayload struct {
 	EnforceEdgeID *bool `example:"false"`
 	// EdgePortainerURL is the URL that is exposed to edge agents
 	EdgePortainerURL *string `json:"EdgePortainerURL"`
+	// Operations requiring an approval when requested by users who are not approvers
+	OperationApproval *portainer.OperationApprovalSettings
+	// Duration after which terminal session recordings are removed, recordings are kept forever when empty
+	SessionRecordingRetention *string `example:"720h"`
+	// Container image vulnerability scanning and the deployment policy based on its results
+	VulnerabilityScanning *portainer.VulnerabilityScanningSettings
+	// Duration after which the container resource usage samples are removed, defaults to 24h when empty
+	ResourceUsageRetention *string `example:"168h"`
 }
 
 func (payload *settingsUpdatePayload) Validate(r *http.Request) error {
@@ -87,6 +97,46 @@ func (payload *settingsUpdatePayload) Validate(r *http.Request) error {
 		}
 	}
 
+	if payload.OperationApproval != nil {
+		for _, authorization := range payload.OperationApproval.Authorizations {
+			if !slices.Contains(security.ApprovalGatedAuthorizations, authorization) {
+				return errors.Errorf("Invalid operation approval authorization: %s cannot require an approval", authorization)
+			}
+		}
+
+		if payload.OperationApproval.Expiry != "" {
+			expiry, err := time.ParseDuration(payload.OperationApproval.Expiry)
+			if err != nil || expiry <= 0 {
+				return errors.New("Invalid operation approval expiry")
+			}
+		}
+	}
+
+	if payload.SessionRecordingRetention != nil && *payload.SessionRecordingRetention != "" {
+		retention, err := time.ParseDuration(*payload.SessionRecordingRetention)
+		if err != nil || retention <= 0 {
+			return errors.New("Invalid session recording retention")
+		}
+	}
+
+	if payload.ResourceUsageRetention != nil && *payload.ResourceUsageRetention != "" {
+		retention, err := time.ParseDuration(*payload.ResourceUsageRetention)
+		if err != nil || retention <= 0 {
+			return errors.New("Invalid resource usage retention")
+		}
+	}
+
+	if payload.VulnerabilityScanning != nil {
+		scanner := payload.VulnerabilityScanning.Scanner
+		if scanner != "" && scanner != portainer.VulnerabilityScannerTrivy && scanner != portainer.VulnerabilityScannerGrype {
+			return errors.New("Invalid vulnerability scanner. Value must be one of: trivy or grype")
+		}
+
+		if payload.VulnerabilityScanning.ServerURL != "" && !govalidator.IsURL(payload.VulnerabilityScanning.ServerURL) {
+			return errors.New("Invalid vulnerability scanner server URL. Must correspond to a valid URL format")
+		}
+	}
+
 	if payload.EdgePortainerURL != nil && *payload.EdgePortainerURL != "" {
 		_, err := edge.ParseHostForEdge(*payload.EdgePortainerURL)
 		if err != nil {
@@ -108,6 +158,7 @@ func (payload *settingsUpdatePayload) Validate(r *http.Request) error {
 // @produce json
 // @param body body settingsUpdatePayload true "New settings"
 // @success 200 {object} portainer.Settings "Success"
+// @success 202 {object} portainer.PendingOperation "Operation stored until approved"
 // @failure 400 "Invalid request"
 // @failure 500 "Server error"
 // @router /settings [put]
@@ -237,6 +288,31 @@ func (handler *Handler) updateSettings(tx dataservices.DataStoreTx, payload sett
 		settings.EdgePortainerURL = *payload.EdgePortainerURL
 	}
 
+	if payload.OperationApproval != nil {
+		for _, teamID := range payload.OperationApproval.ApproverTeamIDs {
+			_, err := tx.Team().Read(teamID)
+			if tx.IsErrObjectNotFound(err) {
+				return nil, httperror.BadRequest("Unable to find an approver team with the specified identifier inside the database", err)
+			} else if err != nil {
+				return nil, httperror.InternalServerError("Unable to find an approver team with the specified identifier inside the database", err)
+			}
+		}
+
+		settings.OperationApproval = *payload.OperationApproval
+	}
+
+	if payload.SessionRecordingRetention != nil {
+		settings.SessionRecordingRetention = *payload.SessionRecordingRetention
+	}
+
+	if payload.VulnerabilityScanning != nil {
+		settings.VulnerabilityScanning = *payload.VulnerabilityScanning
+	}
+
+	if payload.ResourceUsageRetention != nil {
+		settings.ResourceUsageRetention = *payload.ResourceUsageRetention
+	}
+
 	if payload.SnapshotInterval != nil && *payload.SnapshotInterval != settings.SnapshotInterval {
 		err := handler.updateSnapshotInterval(settings, *payload.SnapshotInterval)
 		if err != nil {
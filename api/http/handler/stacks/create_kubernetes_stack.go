@@ -5,7 +5,10 @@ import (
 	"net/http"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
+	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/git/update"
+	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/endpointutils"
 	"github.com/portainer/portainer/api/internal/registryutils"
 	k "github.com/portainer/portainer/api/kubernetes"
@@ -27,6 +30,10 @@ type kubernetesStringDeploymentPayload struct {
 	StackFileContent string
 	// Whether the stack is from a app template
 	FromAppTemplate bool `example:"false"`
+	// Identifier of the custom template the stack file is rendered from. StackFileContent is ignored when specified
+	CustomTemplateID portainer.CustomTemplateID `example:"1"`
+	// Values of the custom template variables
+	CustomTemplateVariables []portainer.Pair
 }
 
 func createStackPayloadFromK8sFileContentPayload(name, namespace, fileContent string, composeFormat, fromAppTemplate bool) stackbuilders.StackPayload {
@@ -51,26 +58,41 @@ type kubernetesGitDeploymentPayload struct {
 	ManifestFile             string
 	AdditionalFiles          []string
 	AutoUpdate               *portainer.AutoUpdateSettings
+	// Identifier of the SSH key used to authenticate, used instead of the password when not 0
+	RepositorySSHKeyID int `example:"0"`
+	// Identifier of the shared git credential used to authenticate. Used instead of RepositoryUsername and RepositoryPassword when not 0
+	RepositoryGitCredentialID int `example:"0"`
 	// TLSSkipVerify skips SSL verification when cloning the Git repository
 	TLSSkipVerify bool `example:"false"`
+	// Path of a kustomization directory inside the repository. When set, the manifests are rendered with kustomize
+	// and ManifestFile is ignored
+	KustomizationPath string `example:"deploy"`
+	// Overlay to render, relative to the kustomization directory
+	KustomizationOverlay string `example:"overlays/production"`
+	// Submodules and LFS objects fetched with the repository, only the files of the repository are fetched when not specified
+	RepositoryContent *gittypes.ContentOptions
 }
 
-func createStackPayloadFromK8sGitPayload(name, repoUrl, repoReference, repoUsername, repoPassword string, repoAuthentication, composeFormat bool, namespace, manifest string, additionalFiles []string, autoUpdate *portainer.AutoUpdateSettings, repoSkipSSLVerify bool) stackbuilders.StackPayload {
+func createStackPayloadFromK8sGitPayload(name, repoUrl, repoReference, repoUsername, repoPassword string, repoSSHKeyID, repoGitCredentialID int, repoAuthentication, composeFormat bool, namespace, manifest string, additionalFiles []string, autoUpdate *portainer.AutoUpdateSettings, repoSkipSSLVerify bool, kustomization *portainer.KubernetesKustomization, repoContent *gittypes.ContentOptions) stackbuilders.StackPayload {
 	return stackbuilders.StackPayload{
 		StackName: name,
 		RepositoryConfigPayload: stackbuilders.RepositoryConfigPayload{
-			URL:            repoUrl,
-			ReferenceName:  repoReference,
-			Authentication: repoAuthentication,
-			Username:       repoUsername,
-			Password:       repoPassword,
-			TLSSkipVerify:  repoSkipSSLVerify,
+			URL:             repoUrl,
+			ReferenceName:   repoReference,
+			Authentication:  repoAuthentication,
+			Username:        repoUsername,
+			Password:        repoPassword,
+			SSHKeyID:        repoSSHKeyID,
+			GitCredentialID: repoGitCredentialID,
+			TLSSkipVerify:   repoSkipSSLVerify,
+			Content:         repoContent,
 		},
 		Namespace:       namespace,
 		ComposeFormat:   composeFormat,
 		ManifestFile:    manifest,
 		AdditionalFiles: additionalFiles,
 		AutoUpdate:      autoUpdate,
+		Kustomization:   kustomization,
 	}
 }
 
@@ -91,7 +113,7 @@ func createStackPayloadFromK8sUrlPayload(name, namespace, manifestUrl string, co
 }
 
 func (payload *kubernetesStringDeploymentPayload) Validate(r *http.Request) error {
-	if govalidator.IsNull(payload.StackFileContent) {
+	if payload.CustomTemplateID == 0 && govalidator.IsNull(payload.StackFileContent) {
 		return errors.New("Invalid stack file content")
 	}
 	if govalidator.IsNull(payload.StackName) {
@@ -101,13 +123,20 @@ func (payload *kubernetesStringDeploymentPayload) Validate(r *http.Request) erro
 }
 
 func (payload *kubernetesGitDeploymentPayload) Validate(r *http.Request) error {
-	if govalidator.IsNull(payload.RepositoryURL) || !govalidator.IsURL(payload.RepositoryURL) {
+	if !git.IsValidRepositoryURL(payload.RepositoryURL) {
 		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
 	}
-	if payload.RepositoryAuthentication && govalidator.IsNull(payload.RepositoryPassword) {
-		return errors.New("Invalid repository credentials. Password must be specified when authentication is enabled")
+	if payload.RepositoryAuthentication && govalidator.IsNull(payload.RepositoryPassword) && payload.RepositorySSHKeyID == 0 && payload.RepositoryGitCredentialID == 0 {
+		return errors.New("Invalid repository credentials. Password, SSH key or git credential must be specified when authentication is enabled")
 	}
-	if govalidator.IsNull(payload.ManifestFile) {
+	if err := git.ValidateContentOptions(payload.RepositoryContent); err != nil {
+		return err
+	}
+	if payload.isKustomization() {
+		if payload.ComposeFormat || len(payload.AdditionalFiles) > 0 {
+			return errors.New("Invalid kustomization. Compose format and additional files are not supported with kustomize")
+		}
+	} else if govalidator.IsNull(payload.ManifestFile) {
 		return errors.New("Invalid manifest file in repository")
 	}
 	if err := update.ValidateAutoUpdateSettings(payload.AutoUpdate); err != nil {
@@ -119,6 +148,22 @@ func (payload *kubernetesGitDeploymentPayload) Validate(r *http.Request) error {
 	return nil
 }
 
+func (payload *kubernetesGitDeploymentPayload) isKustomization() bool {
+	return payload.KustomizationPath != "" || payload.KustomizationOverlay != ""
+}
+
+// kustomization returns the kustomization the stack is rendered from, nil when the stack is deployed from manifest files
+func (payload *kubernetesGitDeploymentPayload) kustomization() *portainer.KubernetesKustomization {
+	if !payload.isKustomization() {
+		return nil
+	}
+
+	return &portainer.KubernetesKustomization{
+		Path:    payload.KustomizationPath,
+		Overlay: payload.KustomizationOverlay,
+	}
+}
+
 func (payload *kubernetesManifestURLDeploymentPayload) Validate(r *http.Request) error {
 	if govalidator.IsNull(payload.ManifestURL) || !govalidator.IsURL(payload.ManifestURL) {
 		return errors.New("Invalid manifest URL")
@@ -170,6 +215,14 @@ func (handler *Handler) createKubernetesStackFromFileContent(w http.ResponseWrit
 	}
 
 	stackPayload := createStackPayloadFromK8sFileContentPayload(payload.StackName, payload.Namespace, payload.StackFileContent, payload.ComposeFormat, payload.FromAppTemplate)
+	if payload.CustomTemplateID != 0 {
+		if httpErr := handler.checkCustomTemplateAccess(r, payload.CustomTemplateID); httpErr != nil {
+			return httpErr
+		}
+
+		stackPayload.CustomTemplateID = payload.CustomTemplateID
+		stackPayload.CustomTemplateVariables = payload.CustomTemplateVariables
+	}
 
 	k8sStackBuilder := stackbuilders.CreateK8sStackFileContentBuilder(handler.DataStore,
 		handler.FileService,
@@ -245,11 +298,38 @@ func (handler *Handler) createKubernetesStackFromGitRepository(w http.ResponseWr
 		}
 	}
 
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	err = git.ValidateSSHKeyAccess(handler.DataStore.GitSSHKey(), payload.RepositorySSHKeyID, userID)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the SSH key", err)
+	}
+
+	err = git.ValidateContentSSHKeyAccess(handler.DataStore.GitSSHKey(), payload.RepositoryContent, userID)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the SSH key of the submodules", err)
+	}
+
+	err = git.ValidateCredentialAccess(handler.DataStore.GitCredential(), payload.RepositoryGitCredentialID, userID, securityContext.UserMemberships, securityContext.IsAdmin)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the git credential", err)
+	}
+
+	err = git.ValidateContentCredentialAccess(handler.DataStore.GitCredential(), payload.RepositoryContent, userID, securityContext.UserMemberships, securityContext.IsAdmin)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the git credential of the submodules", err)
+	}
+
 	stackPayload := createStackPayloadFromK8sGitPayload(payload.StackName,
 		payload.RepositoryURL,
 		payload.RepositoryReferenceName,
 		payload.RepositoryUsername,
 		payload.RepositoryPassword,
+		payload.RepositorySSHKeyID,
+		payload.RepositoryGitCredentialID,
 		payload.RepositoryAuthentication,
 		payload.ComposeFormat,
 		payload.Namespace,
@@ -257,6 +337,8 @@ func (handler *Handler) createKubernetesStackFromGitRepository(w http.ResponseWr
 		payload.AdditionalFiles,
 		payload.AutoUpdate,
 		payload.TLSSkipVerify,
+		payload.kustomization(),
+		payload.RepositoryContent,
 	)
 
 	k8sStackBuilder := stackbuilders.CreateKubernetesStackGitBuilder(handler.DataStore,
@@ -343,7 +425,7 @@ func (handler *Handler) deployKubernetesStack(userID portainer.UserID, endpoint
 	user := &portainer.User{
 		ID: userID,
 	}
-	k8sDeploymentConfig, err := deployments.CreateKubernetesStackDeploymentConfig(stack, handler.KubernetesDeployer, appLabels, user, endpoint)
+	k8sDeploymentConfig, err := deployments.CreateKubernetesStackDeploymentConfig(stack, handler.KubernetesDeployer, handler.StackDeployer, appLabels, user, endpoint)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create temp kub deployment files")
 	}
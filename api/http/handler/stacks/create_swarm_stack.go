@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
+	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/git/update"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/stacks/stackbuilders"
@@ -27,6 +29,10 @@ type swarmStackFromFileContentPayload struct {
 	Env []portainer.Pair
 	// Whether the stack is from a app template
 	FromAppTemplate bool `example:"false"`
+	// Identifier of the custom template the stack file is rendered from. StackFileContent is ignored when specified
+	CustomTemplateID portainer.CustomTemplateID `example:"1"`
+	// Values of the custom template variables
+	CustomTemplateVariables []portainer.Pair
 }
 
 func (payload *swarmStackFromFileContentPayload) Validate(r *http.Request) error {
@@ -36,7 +42,7 @@ func (payload *swarmStackFromFileContentPayload) Validate(r *http.Request) error
 	if govalidator.IsNull(payload.SwarmID) {
 		return errors.New("Invalid Swarm ID")
 	}
-	if govalidator.IsNull(payload.StackFileContent) {
+	if payload.CustomTemplateID == 0 && govalidator.IsNull(payload.StackFileContent) {
 		return errors.New("Invalid stack file content")
 	}
 	return nil
@@ -91,6 +97,14 @@ func (handler *Handler) createSwarmStackFromFileContent(w http.ResponseWriter, r
 	}
 
 	stackPayload := createStackPayloadFromSwarmFileContentPayload(payload.Name, payload.SwarmID, payload.StackFileContent, payload.Env, payload.FromAppTemplate)
+	if payload.CustomTemplateID != 0 {
+		if httpErr := handler.checkCustomTemplateAccess(r, payload.CustomTemplateID); httpErr != nil {
+			return httpErr
+		}
+
+		stackPayload.CustomTemplateID = payload.CustomTemplateID
+		stackPayload.CustomTemplateVariables = payload.CustomTemplateVariables
+	}
 
 	swarmStackBuilder := stackbuilders.CreateSwarmStackFileContentBuilder(securityContext,
 		handler.DataStore,
@@ -124,6 +138,10 @@ type swarmStackFromGitRepositoryPayload struct {
 	RepositoryUsername string `example:"myGitUsername"`
 	// Password used in basic authentication. Required when RepositoryAuthentication is true.
 	RepositoryPassword string `example:"myGitPassword"`
+	// Identifier of the SSH key used to authenticate. Used instead of RepositoryPassword when not 0
+	RepositorySSHKeyID int `example:"0"`
+	// Identifier of the shared git credential used to authenticate. Used instead of RepositoryUsername and RepositoryPassword when not 0
+	RepositoryGitCredentialID int `example:"0"`
 	// Whether the stack is from a app template
 	FromAppTemplate bool `example:"false"`
 	// Path to the Stack file inside the Git repository
@@ -134,6 +152,8 @@ type swarmStackFromGitRepositoryPayload struct {
 	AutoUpdate *portainer.AutoUpdateSettings
 	// TLSSkipVerify skips SSL verification when cloning the Git repository
 	TLSSkipVerify bool `example:"false"`
+	// Submodules and LFS objects fetched with the repository, only the files of the repository are fetched when not specified
+	RepositoryContent *gittypes.ContentOptions
 }
 
 func (payload *swarmStackFromGitRepositoryPayload) Validate(r *http.Request) error {
@@ -143,11 +163,14 @@ func (payload *swarmStackFromGitRepositoryPayload) Validate(r *http.Request) err
 	if govalidator.IsNull(payload.SwarmID) {
 		return errors.New("Invalid Swarm ID")
 	}
-	if govalidator.IsNull(payload.RepositoryURL) || !govalidator.IsURL(payload.RepositoryURL) {
+	if !git.IsValidRepositoryURL(payload.RepositoryURL) {
 		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
 	}
-	if payload.RepositoryAuthentication && govalidator.IsNull(payload.RepositoryPassword) {
-		return errors.New("Invalid repository credentials. Password must be specified when authentication is enabled")
+	if payload.RepositoryAuthentication && govalidator.IsNull(payload.RepositoryPassword) && payload.RepositorySSHKeyID == 0 && payload.RepositoryGitCredentialID == 0 {
+		return errors.New("Invalid repository credentials. Password, SSH key or git credential must be specified when authentication is enabled")
+	}
+	if err := git.ValidateContentOptions(payload.RepositoryContent); err != nil {
+		return err
 	}
 	if err := update.ValidateAutoUpdateSettings(payload.AutoUpdate); err != nil {
 		return err
@@ -155,17 +178,20 @@ func (payload *swarmStackFromGitRepositoryPayload) Validate(r *http.Request) err
 	return nil
 }
 
-func createStackPayloadFromSwarmGitPayload(name, swarmID, repoUrl, repoReference, repoUsername, repoPassword string, repoAuthentication bool, composeFile string, additionalFiles []string, autoUpdate *portainer.AutoUpdateSettings, env []portainer.Pair, fromAppTemplate bool, repoSkipSSLVerify bool) stackbuilders.StackPayload {
+func createStackPayloadFromSwarmGitPayload(name, swarmID, repoUrl, repoReference, repoUsername, repoPassword string, repoSSHKeyID, repoGitCredentialID int, repoAuthentication bool, composeFile string, additionalFiles []string, autoUpdate *portainer.AutoUpdateSettings, env []portainer.Pair, fromAppTemplate bool, repoSkipSSLVerify bool, repoContent *gittypes.ContentOptions) stackbuilders.StackPayload {
 	return stackbuilders.StackPayload{
 		Name:    name,
 		SwarmID: swarmID,
 		RepositoryConfigPayload: stackbuilders.RepositoryConfigPayload{
-			URL:            repoUrl,
-			ReferenceName:  repoReference,
-			Authentication: repoAuthentication,
-			Username:       repoUsername,
-			Password:       repoPassword,
-			TLSSkipVerify:  repoSkipSSLVerify,
+			URL:             repoUrl,
+			ReferenceName:   repoReference,
+			Authentication:  repoAuthentication,
+			Username:        repoUsername,
+			Password:        repoPassword,
+			SSHKeyID:        repoSSHKeyID,
+			GitCredentialID: repoGitCredentialID,
+			TLSSkipVerify:   repoSkipSSLVerify,
+			Content:         repoContent,
 		},
 		ComposeFile:     composeFile,
 		AdditionalFiles: additionalFiles,
@@ -223,12 +249,34 @@ func (handler *Handler) createSwarmStackFromGitRepository(w http.ResponseWriter,
 		return httperror.InternalServerError("Unable to retrieve info from request context", err)
 	}
 
+	err = git.ValidateSSHKeyAccess(handler.DataStore.GitSSHKey(), payload.RepositorySSHKeyID, userID)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the SSH key", err)
+	}
+
+	err = git.ValidateContentSSHKeyAccess(handler.DataStore.GitSSHKey(), payload.RepositoryContent, userID)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the SSH key of the submodules", err)
+	}
+
+	err = git.ValidateCredentialAccess(handler.DataStore.GitCredential(), payload.RepositoryGitCredentialID, userID, securityContext.UserMemberships, securityContext.IsAdmin)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the git credential", err)
+	}
+
+	err = git.ValidateContentCredentialAccess(handler.DataStore.GitCredential(), payload.RepositoryContent, userID, securityContext.UserMemberships, securityContext.IsAdmin)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the git credential of the submodules", err)
+	}
+
 	stackPayload := createStackPayloadFromSwarmGitPayload(payload.Name,
 		payload.SwarmID,
 		payload.RepositoryURL,
 		payload.RepositoryReferenceName,
 		payload.RepositoryUsername,
 		payload.RepositoryPassword,
+		payload.RepositorySSHKeyID,
+		payload.RepositoryGitCredentialID,
 		payload.RepositoryAuthentication,
 		payload.ComposeFile,
 		payload.AdditionalFiles,
@@ -236,6 +284,7 @@ func (handler *Handler) createSwarmStackFromGitRepository(w http.ResponseWriter,
 		payload.Env,
 		payload.FromAppTemplate,
 		payload.TLSSkipVerify,
+		payload.RepositoryContent,
 	)
 
 	swarmStackBuilder := stackbuilders.CreateSwarmStackGitBuilder(securityContext,
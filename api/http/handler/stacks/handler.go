@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
 	dockerclient "github.com/portainer/portainer/api/docker/client"
+	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
 	"github.com/portainer/portainer/api/internal/endpointutils"
@@ -18,6 +20,7 @@ import (
 	"github.com/portainer/portainer/api/stacks/deployments"
 	"github.com/portainer/portainer/api/stacks/stackutils"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
 
 	"github.com/docker/docker/api/types"
 	"github.com/gorilla/mux"
@@ -28,6 +31,7 @@ import (
 type Handler struct {
 	stackCreationMutex *sync.Mutex
 	stackDeletionMutex *sync.Mutex
+	promotionMutex     *sync.Mutex
 	requestBouncer     security.BouncerService
 	*mux.Router
 	DataStore               dataservices.DataStore
@@ -54,6 +58,7 @@ func NewHandler(bouncer security.BouncerService) *Handler {
 		Router:             mux.NewRouter(),
 		stackCreationMutex: &sync.Mutex{},
 		stackDeletionMutex: &sync.Mutex{},
+		promotionMutex:     &sync.Mutex{},
 		requestBouncer:     bouncer,
 	}
 
@@ -61,22 +66,40 @@ func NewHandler(bouncer security.BouncerService) *Handler {
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackCreate))).Methods(http.MethodPost)
 	h.Handle("/stacks",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackList))).Methods(http.MethodGet)
+	h.Handle("/stacks/promotions",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackPromotionList))).Methods(http.MethodGet)
+	h.Handle("/stacks/promotions/{id}",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackPromotionInspect))).Methods(http.MethodGet)
+	h.Handle("/stacks/promotions/{id}",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackPromotionDelete))).Methods(http.MethodDelete)
+	h.Handle("/stacks/promotions/{id}/approve",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackPromotionApprove))).Methods(http.MethodPost)
+	h.Handle("/stacks/promotions/{id}/reject",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackPromotionReject))).Methods(http.MethodPost)
 	h.Handle("/stacks/{id}",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackInspect))).Methods(http.MethodGet)
 	h.Handle("/stacks/{id}",
-		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackDelete))).Methods(http.MethodDelete)
+		bouncer.AuthenticatedAccess(bouncer.OperationApproval(portainer.OperationPortainerStackDelete, h.authorizeStackOperation, httperror.LoggerHandler(h.stackDelete)))).Methods(http.MethodDelete)
 	h.Handle("/stacks/{id}/associate",
 		bouncer.AdminAccess(httperror.LoggerHandler(h.stackAssociate))).Methods(http.MethodPut)
 	h.Handle("/stacks/{id}",
-		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackUpdate))).Methods(http.MethodPut)
+		bouncer.AuthenticatedAccess(bouncer.OperationApproval(portainer.OperationPortainerStackUpdate, h.authorizeStackOperation, httperror.LoggerHandler(h.stackUpdate)))).Methods(http.MethodPut)
 	h.Handle("/stacks/{id}/git",
-		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackUpdateGit))).Methods(http.MethodPost)
+		bouncer.AuthenticatedAccess(bouncer.OperationApproval(portainer.OperationPortainerStackUpdate, h.authorizeStackOperation, httperror.LoggerHandler(h.stackUpdateGit)))).Methods(http.MethodPost)
 	h.Handle("/stacks/{id}/git/redeploy",
-		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackGitRedeploy))).Methods(http.MethodPut)
+		bouncer.AuthenticatedAccess(bouncer.OperationApproval(portainer.OperationPortainerStackUpdate, h.authorizeStackOperation, httperror.LoggerHandler(h.stackGitRedeploy)))).Methods(http.MethodPut)
+	h.Handle("/stacks/{id}/git/plan",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackGitPlan))).Methods(http.MethodPost)
+	h.Handle("/stacks/{id}/promote",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackPromotionCreate))).Methods(http.MethodPost)
+	h.Handle("/stacks/{id}/revisions",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackRevisions))).Methods(http.MethodGet)
+	h.Handle("/stacks/{id}/template/upgrade",
+		bouncer.AuthenticatedAccess(bouncer.OperationApproval(portainer.OperationPortainerStackUpdate, h.authorizeStackOperation, httperror.LoggerHandler(h.stackTemplateUpgrade)))).Methods(http.MethodPost)
 	h.Handle("/stacks/{id}/file",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackFile))).Methods(http.MethodGet)
 	h.Handle("/stacks/{id}/migrate",
-		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackMigrate))).Methods(http.MethodPost)
+		bouncer.AuthenticatedAccess(bouncer.OperationApproval(portainer.OperationPortainerStackMigrate, h.authorizeStackOperation, httperror.LoggerHandler(h.stackMigrate)))).Methods(http.MethodPost)
 	h.Handle("/stacks/{id}/start",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackStart))).Methods(http.MethodPost)
 	h.Handle("/stacks/{id}/stop",
@@ -105,6 +128,41 @@ func (handler *Handler) userCanAccessStack(securityContext *security.RestrictedR
 	return stackutils.UserIsAdminOrEndpointAdmin(user, endpointID)
 }
 
+// checkCustomTemplateAccess ensures the user can use the custom template a stack is created from
+func (handler *Handler) checkCustomTemplateAccess(r *http.Request, customTemplateID portainer.CustomTemplateID) *httperror.HandlerError {
+	customTemplate, err := handler.DataStore.CustomTemplate().Read(customTemplateID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a custom template with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a custom template with the specified identifier inside the database", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	if securityContext.IsAdmin || customTemplate.CreatedByUserID == securityContext.UserID {
+		return nil
+	}
+
+	resourceControl, err := handler.DataStore.ResourceControl().ResourceControlByResourceIDAndType(strconv.Itoa(int(customTemplate.ID)), portainer.CustomTemplateResourceControl)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve a resource control associated to the custom template", err)
+	}
+
+	userTeamIDs := make([]portainer.TeamID, 0)
+	for _, membership := range securityContext.UserMemberships {
+		userTeamIDs = append(userTeamIDs, membership.TeamID)
+	}
+
+	if resourceControl == nil || !authorization.UserCanAccessResource(securityContext.UserID, userTeamIDs, resourceControl) {
+		return httperror.Forbidden("Access denied to custom template", httperrors.ErrResourceAccessDenied)
+	}
+
+	return nil
+}
+
 func (handler *Handler) userIsAdmin(userID portainer.UserID) (bool, error) {
 	user, err := handler.DataStore.User().Read(userID)
 	if err != nil {
@@ -145,6 +203,77 @@ func (handler *Handler) userCanManageStacks(securityContext *security.Restricted
 	return true, nil
 }
 
+// authorizeStackOperation verifies that the user can manage the stack targeted by an operation requiring an approval,
+// it mirrors the checks of the stack handlers so that the operations the user cannot perform are not queued
+func (handler *Handler) authorizeStackOperation(r *http.Request) *httperror.HandlerError {
+	stackID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid stack identifier route variable", err)
+	}
+
+	stack, err := handler.DataStore.Stack().Read(portainer.StackID(stackID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a stack with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a stack with the specified identifier inside the database", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	endpointID, err := request.RetrieveNumericQueryParameter(r, "endpointId", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: endpointId", err)
+	}
+	if endpointID == 0 {
+		endpointID = int(stack.EndpointID)
+	}
+
+	if portainer.EndpointID(endpointID) != stack.EndpointID && !securityContext.IsAdmin {
+		return httperror.Forbidden("Permission denied to manage orphaned stack", errors.New("permission denied to manage orphaned stack"))
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find an environment with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find an environment with the specified identifier inside the database", err)
+	}
+
+	err = handler.requestBouncer.AuthorizedEndpointOperation(r, endpoint)
+	if err != nil {
+		return httperror.Forbidden("Permission denied to access environment", err)
+	}
+
+	if stack.Type == portainer.DockerSwarmStack || stack.Type == portainer.DockerComposeStack {
+		resourceControl, err := handler.DataStore.ResourceControl().ResourceControlByResourceIDAndType(stackutils.ResourceControlID(stack.EndpointID, stack.Name), portainer.StackResourceControl)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve a resource control associated to the stack", err)
+		}
+
+		access, err := handler.userCanAccessStack(securityContext, endpoint.ID, resourceControl)
+		if err != nil {
+			return httperror.InternalServerError("Unable to verify user authorizations to validate stack access", err)
+		}
+		if !access {
+			return httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+		}
+	}
+
+	canManage, err := handler.userCanManageStacks(securityContext, endpoint)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations to validate stack management", err)
+	}
+	if !canManage {
+		errMsg := "stack management is disabled for non-admin users"
+		return httperror.Forbidden(errMsg, errors.New(errMsg))
+	}
+
+	return nil
+}
+
 func (handler *Handler) checkUniqueStackName(endpoint *portainer.Endpoint, name string, stackID portainer.StackID) (bool, error) {
 	stacks, err := handler.DataStore.Stack().ReadAll()
 	if err != nil {
@@ -233,3 +362,22 @@ func (handler *Handler) checkUniqueWebhookID(webhookID string) (bool, error) {
 	}
 	return false, err
 }
+
+// hideStackSecrets removes the secret variables and the webhook secret of a stack from the http responses
+func hideStackSecrets(stack *portainer.Stack) {
+	stack.SecretEnv = ""
+
+	if stack.AutoUpdate != nil {
+		stack.AutoUpdate.WebhookSecret = ""
+	}
+}
+
+// keepWebhookSecret keeps the current webhook secret when the auto update settings of a stack are updated without one,
+// as the secret is never returned by the http responses. The secret is removed when the webhook changes.
+func keepWebhookSecret(current, updated *portainer.AutoUpdateSettings) {
+	if current == nil || updated == nil || updated.WebhookSecret != "" || updated.Webhook != current.Webhook {
+		return
+	}
+
+	updated.WebhookSecret = current.WebhookSecret
+}
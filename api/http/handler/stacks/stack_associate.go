@@ -124,5 +124,7 @@ func (handler *Handler) stackAssociate(w http.ResponseWriter, r *http.Request) *
 		stack.GitConfig.Authentication.Password = ""
 	}
 
+	hideStackSecrets(stack)
+
 	return response.JSON(w, stack)
 }
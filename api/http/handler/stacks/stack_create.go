@@ -113,6 +113,23 @@ func (handler *Handler) createKubernetesStack(w http.ResponseWriter, r *http.Req
 }
 
 func (handler *Handler) decorateStackResponse(w http.ResponseWriter, stack *portainer.Stack, userID portainer.UserID) *httperror.HandlerError {
+	if httpErr := handler.createStackResourceControl(stack, userID); httpErr != nil {
+		return httpErr
+	}
+
+	if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.Password != "" {
+		// sanitize password in the http response to minimise possible security leaks
+		stack.GitConfig.Authentication.Password = ""
+	}
+
+	hideStackSecrets(stack)
+
+	return response.JSON(w, stack)
+}
+
+// createStackResourceControl restricts the access of a new stack to its creator, or to administrators
+// when the creator is an administrator
+func (handler *Handler) createStackResourceControl(stack *portainer.Stack, userID portainer.UserID) *httperror.HandlerError {
 	var resourceControl *portainer.ResourceControl
 
 	isAdmin, err := handler.userIsAdmin(userID)
@@ -133,10 +150,5 @@ func (handler *Handler) decorateStackResponse(w http.ResponseWriter, stack *port
 
 	stack.ResourceControl = resourceControl
 
-	if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.Password != "" {
-		// sanitize password in the http response to minimise possible security leaks
-		stack.GitConfig.Authentication.Password = ""
-	}
-
-	return response.JSON(w, stack)
+	return nil
 }
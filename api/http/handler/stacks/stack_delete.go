@@ -11,6 +11,7 @@ import (
 	"github.com/portainer/portainer/api/filesystem"
 	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/kubernetes/kustomize"
 	"github.com/portainer/portainer/api/stacks/deployments"
 	"github.com/portainer/portainer/api/stacks/stackutils"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
@@ -32,6 +33,7 @@ import (
 // @param external query boolean false "Set to true to delete an external stack. Only external Swarm stacks are supported"
 // @param endpointId query int true "Environment identifier"
 // @success 204 "Success"
+// @success 202 {object} portainer.PendingOperation "Operation stored until approved"
 // @failure 400 "Invalid request"
 // @failure 403 "Permission denied"
 // @failure 404 "Not found"
@@ -240,6 +242,25 @@ func (handler *Handler) deleteStack(userID portainer.UserID, stack *portainer.St
 				}
 				manifestFiles = append(manifestFiles, manifestFilePath)
 			}
+		} else if stack.Kustomization != nil {
+			//a kustomization stack is removed from its rendered manifest
+			tmpDir, err := os.MkdirTemp("", "kube_delete")
+			if err != nil {
+				return errors.Wrap(err, "failed to create temp directory for deleting kub stack")
+			}
+
+			defer os.RemoveAll(tmpDir)
+
+			manifestContent, err := kustomize.Render(stack.ProjectPath, *stack.Kustomization)
+			if err != nil {
+				return err
+			}
+
+			manifestFilePath := filesystem.JoinPaths(tmpDir, stack.EntryPoint)
+			if err := filesystem.WriteToFile(manifestFilePath, manifestContent); err != nil {
+				return errors.Wrap(err, "failed to create temp manifest file")
+			}
+			manifestFiles = append(manifestFiles, manifestFilePath)
 		} else {
 			manifestFiles = stackutils.GetStackFilePaths(stack, true)
 		}
@@ -0,0 +1,307 @@
+package stacks
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/git"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	k "github.com/portainer/portainer/api/kubernetes"
+	"github.com/portainer/portainer/api/stacks/deployments"
+	"github.com/portainer/portainer/api/stacks/stackplan"
+	"github.com/portainer/portainer/api/stacks/stackutils"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+)
+
+type stackGitPlanPayload struct {
+	// Reference of the git repository to compute the plan for, the reference of the stack is used when empty
+	RepositoryReferenceName string `example:"refs/heads/feature"`
+	// Environment variables of the redeployment, the variables of the stack are used when not specified
+	Env []portainer.Pair
+}
+
+func (payload *stackGitPlanPayload) Validate(r *http.Request) error {
+	return nil
+}
+
+// @id StackGitPlan
+// @summary Preview the redeployment of a git stack
+// @description Fetch a reference of the git repository of a stack and compute the changes a redeployment would apply,
+// @description without touching the deployed stack. The stack files of the compose and swarm stacks are validated and their
+// @description services are compared with the running containers or services. The manifests of the Kubernetes stacks are
+// @description submitted to a server-side dry-run apply and compared with the live objects.
+// @description **Access policy**: authenticated
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Stack identifier"
+// @param endpointId query int false "Stacks created before version 1.18.0 might not have an associated environment(endpoint) identifier. Use this optional parameter to set the environment(endpoint) identifier used by the stack."
+// @param body body stackGitPlanPayload true "Reference to compute the plan for"
+// @success 200 {object} stackplan.Plan "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/git/plan [post]
+func (handler *Handler) stackGitPlan(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stackID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid stack identifier route variable", err)
+	}
+
+	var payload stackGitPlanPayload
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	stack, err := handler.DataStore.Stack().Read(portainer.StackID(stackID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a stack with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a stack with the specified identifier inside the database", err)
+	}
+
+	if stack.GitConfig == nil {
+		return httperror.BadRequest("Stack is not created from git", errors.New("stack is not created from git"))
+	}
+
+	// TODO: this is a work-around for stacks created with Portainer version >= 1.17.1
+	// The EndpointID property is not available for these stacks, this API environment(endpoint)
+	// can use the optional EndpointID query parameter to associate a valid environment(endpoint) identifier to the stack.
+	endpointID, err := request.RetrieveNumericQueryParameter(r, "endpointId", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: endpointId", err)
+	}
+	if endpointID != int(stack.EndpointID) {
+		stack.EndpointID = portainer.EndpointID(endpointID)
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(stack.EndpointID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find the environment associated to the stack inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find the environment associated to the stack inside the database", err)
+	}
+
+	err = handler.requestBouncer.AuthorizedEndpointOperation(r, endpoint)
+	if err != nil {
+		return httperror.Forbidden("Permission denied to access environment", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	//only check resource control when it is a DockerSwarmStack or a DockerComposeStack
+	if stack.Type == portainer.DockerSwarmStack || stack.Type == portainer.DockerComposeStack {
+		resourceControl, err := handler.DataStore.ResourceControl().ResourceControlByResourceIDAndType(stackutils.ResourceControlID(stack.EndpointID, stack.Name), portainer.StackResourceControl)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve a resource control associated to the stack", err)
+		}
+
+		access, err := handler.userCanAccessStack(securityContext, endpoint.ID, resourceControl)
+		if err != nil {
+			return httperror.InternalServerError("Unable to verify user authorizations to validate stack access", err)
+		}
+		if !access {
+			return httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+		}
+	}
+
+	canManage, err := handler.userCanManageStacks(securityContext, endpoint)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations to validate stack management", err)
+	}
+	if !canManage {
+		errMsg := "Stack management is disabled for non-admin users"
+		return httperror.Forbidden(errMsg, errors.New(errMsg))
+	}
+
+	// the plan can fetch any reference of the repository, only the owner of the SSH keys of the stack can use them
+	if stack.GitConfig.Authentication != nil {
+		err = git.ValidateSSHKeyAccess(handler.DataStore.GitSSHKey(), stack.GitConfig.Authentication.SSHKeyID, securityContext.UserID)
+		if err != nil {
+			return httperror.Forbidden("Unable to use the SSH key", err)
+		}
+	}
+
+	err = git.ValidateContentSSHKeyAccess(handler.DataStore.GitSSHKey(), stack.GitConfig.ContentOptions(), securityContext.UserID)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the SSH key of the submodules", err)
+	}
+
+	referenceName := payload.RepositoryReferenceName
+	if referenceName == "" {
+		referenceName = stack.GitConfig.ReferenceName
+	}
+
+	projectPath, err := os.MkdirTemp("", "stack_plan")
+	if err != nil {
+		return httperror.InternalServerError("Unable to create the plan directory", err)
+	}
+	defer os.RemoveAll(projectPath)
+
+	commitHash, err := handler.GitService.CloneRepositoryCommit(projectPath, stack.GitConfig.URL, referenceName, stack.GitConfig.Authentication, stack.GitConfig.TLSSkipVerify, stack.GitConfig.ContentOptions())
+	if err != nil {
+		return httperror.InternalServerError("Unable to clone git repository", err)
+	}
+
+	// the plan is computed from a copy of the stack pointing to the fetched reference, the deployed project is left untouched
+	plannedStack := *stack
+	plannedStack.ProjectPath = projectPath
+	if payload.Env != nil {
+		plannedStack.Env = payload.Env
+	}
+
+	var plan *stackplan.Plan
+	var httpErr *httperror.HandlerError
+
+	switch stack.Type {
+	case portainer.DockerComposeStack, portainer.DockerSwarmStack:
+		plan, httpErr = handler.planDockerStack(r.Context(), &plannedStack, endpoint)
+	case portainer.KubernetesStack:
+		plan, httpErr = handler.planKubernetesStack(r, stack, &plannedStack, endpoint)
+	default:
+		return httperror.BadRequest("Unsupported stack", errors.Errorf("unsupported stack type: %v", stack.Type))
+	}
+
+	if httpErr != nil {
+		return httpErr
+	}
+
+	plan.ReferenceName = referenceName
+	plan.CommitHash = commitHash
+
+	return response.JSON(w, plan)
+}
+
+// planDockerStack validates the stack files and compares their services with the containers of a compose stack
+// or the services of a swarm stack
+func (handler *Handler) planDockerStack(ctx context.Context, stack *portainer.Stack, endpoint *portainer.Endpoint) (*stackplan.Plan, *httperror.HandlerError) {
+	err := handler.ComposeStackManager.Validate(ctx, stack)
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid stack files", err)
+	}
+
+	config, err := handler.ComposeStackManager.Config(ctx, stack)
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid stack files", err)
+	}
+
+	desired, err := stackplan.ParseComposeConfig(config, stack.Name)
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to parse the services of the stack", err)
+	}
+
+	dockerClient, err := handler.DockerClientFactory.CreateClient(endpoint, "", nil)
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to create a Docker client", err)
+	}
+	defer dockerClient.Close()
+
+	var running []stackplan.Service
+	if stack.Type == portainer.DockerSwarmStack {
+		services, err := dockerClient.ServiceList(ctx, types.ServiceListOptions{
+			Filters: filters.NewArgs(filters.Arg("label", "com.docker.stack.namespace="+stack.Name)),
+		})
+		if err != nil {
+			return nil, httperror.InternalServerError("Unable to retrieve the services of the stack", err)
+		}
+
+		running = stackplan.SwarmServices(services, stack.Name)
+	} else {
+		containers, err := dockerClient.ContainerList(ctx, types.ContainerListOptions{
+			All:     true,
+			Filters: filters.NewArgs(filters.Arg("label", "com.docker.compose.project="+stack.Name)),
+		})
+		if err != nil {
+			return nil, httperror.InternalServerError("Unable to retrieve the containers of the stack", err)
+		}
+
+		running = stackplan.ComposeServices(containers, stack.Name)
+	}
+
+	return stackplan.DiffServices(desired, running), nil
+}
+
+// planKubernetesStack submits the manifests of the planned stack to a server-side dry-run apply and compares the result
+// with the live objects of the planned and of the deployed manifests
+func (handler *Handler) planKubernetesStack(r *http.Request, stack, plannedStack *portainer.Stack, endpoint *portainer.Endpoint) (*stackplan.Plan, *httperror.HandlerError) {
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return nil, httperror.BadRequest("Failed to retrieve user token data", err)
+	}
+
+	appLabels := k.KubeAppLabels{
+		StackID:   int(stack.ID),
+		StackName: stack.Name,
+		Owner:     tokenData.Username,
+		Kind:      "git",
+	}
+
+	manifestsPath, err := os.MkdirTemp("", "kub_plan")
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to create the plan directory", err)
+	}
+	defer os.RemoveAll(manifestsPath)
+
+	desiredManifests, err := deployments.RenderKubernetesManifests(plannedStack, handler.KubernetesDeployer, appLabels, filesystem.JoinPaths(manifestsPath, "planned"))
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid stack files", err)
+	}
+
+	currentManifests, err := deployments.RenderKubernetesManifests(stack, handler.KubernetesDeployer, appLabels, filesystem.JoinPaths(manifestsPath, "deployed"))
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to read the deployed stack files", err)
+	}
+
+	output, err := handler.KubernetesDeployer.DryRun(tokenData.ID, endpoint, desiredManifests, stack.Namespace)
+	if err != nil {
+		return nil, httperror.BadRequest("The manifests were refused by the dry-run", err)
+	}
+
+	desired, err := stackplan.ParseKubernetesObjects(output)
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to parse the dry-run result", err)
+	}
+
+	live, httpErr := handler.inspectKubernetesObjects(tokenData.ID, endpoint, desiredManifests, stack.Namespace)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	current, httpErr := handler.inspectKubernetesObjects(tokenData.ID, endpoint, currentManifests, stack.Namespace)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	return stackplan.DiffKubernetesObjects(desired, append(live, current...), current), nil
+}
+
+func (handler *Handler) inspectKubernetesObjects(userID portainer.UserID, endpoint *portainer.Endpoint, manifests []string, namespace string) ([]stackplan.Object, *httperror.HandlerError) {
+	output, err := handler.KubernetesDeployer.Inspect(userID, endpoint, manifests, namespace)
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to retrieve the live objects of the stack", err)
+	}
+
+	objects, err := stackplan.ParseKubernetesObjects(output)
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to parse the live objects of the stack", err)
+	}
+
+	return objects, nil
+}
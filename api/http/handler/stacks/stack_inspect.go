@@ -96,5 +96,7 @@ func (handler *Handler) stackInspect(w http.ResponseWriter, r *http.Request) *ht
 		stack.GitConfig.Authentication.Password = ""
 	}
 
+	hideStackSecrets(stack)
+
 	return response.JSON(w, stack)
 }
@@ -81,11 +81,14 @@ func (handler *Handler) stackList(w http.ResponseWriter, r *http.Request) *httpe
 		stacks = authorization.FilterAuthorizedStacks(stacks, user, userTeamIDs)
 	}
 
-	for _, stack := range stacks {
+	for i := range stacks {
+		stack := &stacks[i]
 		if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.Password != "" {
 			// sanitize password in the http response to minimise possible security leaks
 			stack.GitConfig.Authentication.Password = ""
 		}
+
+		hideStackSecrets(stack)
 	}
 
 	return response.JSON(w, stacks)
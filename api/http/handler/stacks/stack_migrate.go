@@ -43,6 +43,7 @@ func (payload *stackMigratePayload) Validate(r *http.Request) error {
 // @param endpointId query int false "Stacks created before version 1.18.0 might not have an associated environment(endpoint) identifier. Use this optional parameter to set the environment(endpoint) identifier used by the stack."
 // @param body body stackMigratePayload true "Stack migration details"
 // @success 200 {object} portainer.Stack "Success"
+// @success 202 {object} portainer.PendingOperation "Operation stored until approved"
 // @failure 400 "Invalid request"
 // @failure 403 "Permission denied"
 // @failure 404 "Stack not found"
@@ -179,6 +180,8 @@ func (handler *Handler) stackMigrate(w http.ResponseWriter, r *http.Request) *ht
 		stack.GitConfig.Authentication.Password = ""
 	}
 
+	hideStackSecrets(stack)
+
 	return response.JSON(w, stack)
 }
 
@@ -0,0 +1,320 @@
+package stacks
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/filesystem"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/authorization"
+	k "github.com/portainer/portainer/api/kubernetes"
+	"github.com/portainer/portainer/api/stacks/deployments"
+	"github.com/portainer/portainer/api/stacks/stackutils"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// checkStackManagementAccess ensures the user can access the environment and the resource control of a stack
+// and is allowed to manage stacks on this environment
+func (handler *Handler) checkStackManagementAccess(r *http.Request, securityContext *security.RestrictedRequestContext, stack *portainer.Stack, endpoint *portainer.Endpoint) *httperror.HandlerError {
+	err := handler.requestBouncer.AuthorizedEndpointOperation(r, endpoint)
+	if err != nil {
+		return httperror.Forbidden("Permission denied to access environment", err)
+	}
+
+	if stack.Type == portainer.DockerSwarmStack || stack.Type == portainer.DockerComposeStack {
+		resourceControl, err := handler.DataStore.ResourceControl().ResourceControlByResourceIDAndType(stackutils.ResourceControlID(stack.EndpointID, stack.Name), portainer.StackResourceControl)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve a resource control associated to the stack", err)
+		}
+
+		access, err := handler.userCanAccessStack(securityContext, endpoint.ID, resourceControl)
+		if err != nil {
+			return httperror.InternalServerError("Unable to verify user authorizations to validate stack access", err)
+		}
+		if !access {
+			return httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+		}
+	}
+
+	canManage, err := handler.userCanManageStacks(securityContext, endpoint)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations to validate stack management", err)
+	}
+	if !canManage {
+		errMsg := "Stack management is disabled for non-admin users"
+		return httperror.Forbidden(errMsg, errors.New(errMsg))
+	}
+
+	return nil
+}
+
+// userCanReviewPromotion returns true when the user is an administrator, or holds on the target environment
+// a role at least as privileged as the approver role of the promotion. Users cannot review their own promotions.
+func (handler *Handler) userCanReviewPromotion(securityContext *security.RestrictedRequestContext, promotion *portainer.StackPromotion) (bool, error) {
+	if securityContext.IsAdmin {
+		return true, nil
+	}
+
+	if promotion.ApproverRoleID == 0 || promotion.RequestedBy == securityContext.UserID {
+		return false, nil
+	}
+
+	approverRole, err := handler.DataStore.Role().Read(promotion.ApproverRoleID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(promotion.TargetEndpointID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	endpointGroup, err := handler.DataStore.EndpointGroup().Read(endpoint.GroupID)
+	if err != nil && !handler.DataStore.IsErrObjectNotFound(err) {
+		return false, err
+	}
+
+	user, err := handler.DataStore.User().Read(securityContext.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, roleID := range authorization.UserEndpointRoleIDs(user, endpoint, endpointGroup, securityContext.UserMemberships) {
+		role, err := handler.DataStore.Role().Read(roleID)
+		if handler.DataStore.IsErrObjectNotFound(err) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+
+		// a lower priority value means a more privileged role
+		if role.Priority <= approverRole.Priority {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// findPromotionTarget returns the stack running on the target environment of a promotion, nil when there is none yet
+func (handler *Handler) findPromotionTarget(promotion *portainer.StackPromotion) (*portainer.Stack, error) {
+	if promotion.TargetStackID != 0 {
+		stack, err := handler.DataStore.Stack().Read(promotion.TargetStackID)
+		if err == nil {
+			return stack, nil
+		} else if !handler.DataStore.IsErrObjectNotFound(err) {
+			return nil, err
+		}
+	}
+
+	stacks, err := handler.DataStore.Stack().ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stacks {
+		stack := &stacks[i]
+		if stack.EndpointID != promotion.TargetEndpointID || !strings.EqualFold(stack.Name, promotion.TargetName) {
+			continue
+		}
+
+		if stack.Type == portainer.KubernetesStack && stack.Namespace != promotion.TargetNamespace {
+			continue
+		}
+
+		return stack, nil
+	}
+
+	return nil, nil
+}
+
+// validatePromotionTarget ensures the revision of a promotion can replace the files of the target stack
+func validatePromotionTarget(promotion *portainer.StackPromotion, target *portainer.Stack) error {
+	if target.Type != promotion.Type {
+		return errors.New("the target stack is not of the same type as the promoted stack")
+	}
+
+	if stackutils.IsGitStack(target) {
+		return errors.New("the target stack is git based and would be overwritten by its next git update")
+	}
+
+	if target.ID == promotion.SourceStackID {
+		return errors.New("a stack cannot be promoted onto itself")
+	}
+
+	return nil
+}
+
+// deployPromotion deploys the files captured by a promotion on its target environment, creating the target stack
+// when it does not exist yet. The previous files of the target stack are restored when the deployment fails.
+func (handler *Handler) deployPromotion(r *http.Request, promotion *portainer.StackPromotion, endpoint *portainer.Endpoint) (*portainer.Stack, error) {
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve info from request context")
+	}
+
+	user, err := handler.DataStore.User().Read(securityContext.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to find the context user")
+	}
+
+	stack, err := handler.findPromotionTarget(promotion)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to find the target stack")
+	}
+
+	isNewStack := stack == nil
+	if isNewStack {
+		createdBy := user.Username
+		if requester, err := handler.DataStore.User().Read(promotion.RequestedBy); err == nil {
+			createdBy = requester.Username
+		}
+
+		stack = &portainer.Stack{
+			ID:           portainer.StackID(handler.DataStore.Stack().GetNextIdentifier()),
+			Name:         promotion.TargetName,
+			Type:         promotion.Type,
+			EndpointID:   promotion.TargetEndpointID,
+			SwarmID:      promotion.TargetSwarmID,
+			Namespace:    promotion.TargetNamespace,
+			CreationDate: time.Now().Unix(),
+			CreatedBy:    createdBy,
+		}
+
+		isUnique, err := handler.checkUniquePromotionTargetName(endpoint, stack)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to check for name collision")
+		}
+		if !isUnique {
+			return nil, errors.Errorf("a stack named %s is already running on the target environment", stack.Name)
+		}
+	} else if err := validatePromotionTarget(promotion, stack); err != nil {
+		return nil, err
+	}
+
+	stack.EntryPoint = promotion.EntryPoint
+	stack.AdditionalFiles = promotion.AdditionalFiles
+	stack.IsComposeFormat = promotion.IsComposeFormat
+	stack.Env = promotion.Env
+	stack.Status = portainer.StackStatusActive
+
+	projectPath := handler.FileService.GetStackProjectPath(strconv.Itoa(int(stack.ID)))
+	backupPath := projectPath + "-promotion-backup"
+
+	os.RemoveAll(backupPath)
+	hasBackup := false
+	if _, err := os.Stat(projectPath); err == nil {
+		if err := os.Rename(projectPath, backupPath); err != nil {
+			return nil, errors.Wrap(err, "unable to backup the target stack files")
+		}
+		hasBackup = true
+	}
+
+	restore := func() {
+		os.RemoveAll(projectPath)
+		if hasBackup {
+			if err := os.Rename(backupPath, projectPath); err != nil {
+				log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to restore the stack files after a failed promotion")
+			}
+		}
+	}
+
+	err = filesystem.CopyDir(handler.FileService.GetStackPromotionPath(strconv.Itoa(int(promotion.ID))), projectPath, false)
+	if err != nil {
+		restore()
+		return nil, errors.Wrap(err, "unable to copy the promoted files")
+	}
+	stack.ProjectPath = projectPath
+
+	err = handler.deployPromotedStack(securityContext, stack, endpoint)
+	if err != nil {
+		restore()
+		return nil, err
+	}
+	os.RemoveAll(backupPath)
+
+	revision := promotion.Revision
+	revision.PromotionID = promotion.ID
+	revision.DeploymentDate = time.Now().Unix()
+	stack.PromotedRevision = &revision
+
+	if !isNewStack {
+		stack.UpdatedBy = user.Username
+		stack.UpdateDate = time.Now().Unix()
+
+		return stack, errors.Wrap(handler.DataStore.Stack().Update(stack.ID, stack), "unable to persist the stack changes inside the database")
+	}
+
+	err = handler.DataStore.Stack().Create(stack)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to persist the stack inside the database")
+	}
+
+	if stack.Type != portainer.KubernetesStack {
+		if httpErr := handler.createStackResourceControl(stack, promotion.RequestedBy); httpErr != nil {
+			return stack, errors.Wrap(httpErr.Err, httpErr.Message)
+		}
+	}
+
+	return stack, nil
+}
+
+func (handler *Handler) checkUniquePromotionTargetName(endpoint *portainer.Endpoint, stack *portainer.Stack) (bool, error) {
+	if stack.Type == portainer.KubernetesStack {
+		return handler.checkUniqueStackNameInKubernetes(endpoint, stack.Name, 0, stack.Namespace)
+	}
+
+	return handler.checkUniqueStackNameInDocker(endpoint, stack.Name, 0, stack.Type == portainer.DockerSwarmStack)
+}
+
+func (handler *Handler) deployPromotedStack(securityContext *security.RestrictedRequestContext, stack *portainer.Stack, endpoint *portainer.Endpoint) error {
+	switch stack.Type {
+	case portainer.DockerComposeStack:
+		config, err := deployments.CreateComposeStackDeploymentConfig(securityContext, stack, endpoint, handler.DataStore, handler.FileService, handler.StackDeployer, false, false)
+		if err != nil {
+			return err
+		}
+
+		return config.Deploy()
+	case portainer.DockerSwarmStack:
+		prune := stack.Option != nil && stack.Option.Prune
+
+		config, err := deployments.CreateSwarmStackDeploymentConfig(securityContext, stack, endpoint, handler.DataStore, handler.FileService, handler.StackDeployer, prune, false)
+		if err != nil {
+			return err
+		}
+
+		return config.Deploy()
+	case portainer.KubernetesStack:
+		_, err := handler.deployKubernetesStack(securityContext.UserID, endpoint, stack, k.KubeAppLabels{
+			StackID:   int(stack.ID),
+			StackName: stack.Name,
+			Owner:     stack.CreatedBy,
+			Kind:      "content",
+		})
+
+		return err
+	}
+
+	return errors.Errorf("unsupported stack type: %v", stack.Type)
+}
+
+// removePromotionFiles removes the files captured by a promotion once it cannot be deployed anymore
+func (handler *Handler) removePromotionFiles(promotion *portainer.StackPromotion) {
+	err := handler.FileService.RemoveDirectory(handler.FileService.GetStackPromotionPath(strconv.Itoa(int(promotion.ID))))
+	if err != nil {
+		log.Warn().Err(err).Int("promotion_id", int(promotion.ID)).Msg("unable to remove the promoted files from disk")
+	}
+}
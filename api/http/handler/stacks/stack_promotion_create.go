@@ -0,0 +1,215 @@
+package stacks
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/endpointutils"
+	"github.com/portainer/portainer/api/stacks/stackutils"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/pkg/errors"
+)
+
+type stackPromotionCreatePayload struct {
+	// Environment identifier where the stack revision will be deployed
+	EndpointID portainer.EndpointID `example:"2" validate:"required"`
+	// Name of the stack on the target environment, defaults to the name of the promoted stack
+	Name string `example:"myStack"`
+	// Kubernetes namespace on the target environment, defaults to the namespace of the promoted stack
+	Namespace string `example:"default"`
+	// Cluster identifier of the Swarm cluster of the target environment, required for Swarm stacks
+	SwarmID string `example:"jpofkc0i9uo9wtx1zesuk649w"`
+	// Environment variables overridden for the target environment
+	EnvOverrides []portainer.Pair
+	// Role a user must hold on the target environment to approve the promotion, only administrators can approve when omitted
+	ApproverRoleID portainer.RoleID `example:"1"`
+}
+
+func (payload *stackPromotionCreatePayload) Validate(r *http.Request) error {
+	if payload.EndpointID == 0 {
+		return errors.New("Invalid environment identifier. Must be a positive number")
+	}
+	if payload.ApproverRoleID < 0 {
+		return errors.New("Invalid approver role identifier")
+	}
+	for _, pair := range payload.EnvOverrides {
+		if pair.Name == "" {
+			return errors.New("Invalid environment variable override. Name is required")
+		}
+	}
+	return nil
+}
+
+// @id StackPromotionCreate
+// @summary Request the promotion of a stack to another environment
+// @description Capture the current files, environment variables and git commit of a stack and request their deployment
+// @description on another environment. The promotion is deployed once approved.
+// @description **Access policy**: authenticated
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Stack identifier"
+// @param body body stackPromotionCreatePayload true "Promotion details"
+// @success 200 {object} portainer.StackPromotion "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/promote [post]
+func (handler *Handler) stackPromotionCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stackID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid stack identifier route variable", err)
+	}
+
+	var payload stackPromotionCreatePayload
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	stack, err := handler.DataStore.Stack().Read(portainer.StackID(stackID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a stack with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a stack with the specified identifier inside the database", err)
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(stack.EndpointID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find the environment associated to the stack inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find the environment associated to the stack inside the database", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	if httpErr := handler.checkStackManagementAccess(r, securityContext, stack, endpoint); httpErr != nil {
+		return httpErr
+	}
+
+	targetEndpoint, err := handler.DataStore.Endpoint().Endpoint(payload.EndpointID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find the target environment inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find the target environment inside the database", err)
+	}
+
+	err = handler.requestBouncer.AuthorizedEndpointOperation(r, targetEndpoint)
+	if err != nil {
+		return httperror.Forbidden("Permission denied to access the target environment", err)
+	}
+
+	if stack.Type == portainer.KubernetesStack && !endpointutils.IsKubernetesEndpoint(targetEndpoint) {
+		return httperror.BadRequest("Kubernetes stacks can only be promoted to Kubernetes environments", errors.New("invalid target environment"))
+	}
+	if stack.Type != portainer.KubernetesStack && !endpointutils.IsDockerEndpoint(targetEndpoint) {
+		return httperror.BadRequest("Docker stacks can only be promoted to Docker environments", errors.New("invalid target environment"))
+	}
+
+	if payload.ApproverRoleID != 0 {
+		_, err := handler.DataStore.Role().Read(payload.ApproverRoleID)
+		if handler.DataStore.IsErrObjectNotFound(err) {
+			return httperror.BadRequest("Unable to find the approver role inside the database", err)
+		} else if err != nil {
+			return httperror.InternalServerError("Unable to find the approver role inside the database", err)
+		}
+	}
+
+	promotion := &portainer.StackPromotion{
+		SourceStackID:    stack.ID,
+		SourceEndpointID: stack.EndpointID,
+		TargetEndpointID: targetEndpoint.ID,
+		TargetName:       stack.Name,
+		TargetNamespace:  stack.Namespace,
+		TargetSwarmID:    payload.SwarmID,
+		Type:             stack.Type,
+		EntryPoint:       stack.EntryPoint,
+		AdditionalFiles:  stack.AdditionalFiles,
+		IsComposeFormat:  stack.IsComposeFormat,
+		EnvOverrides:     payload.EnvOverrides,
+		Env:              stackutils.MergeEnv(stack.Env, payload.EnvOverrides),
+		ApproverRoleID:   payload.ApproverRoleID,
+		Status:           portainer.StackPromotionPending,
+		RequestedBy:      securityContext.UserID,
+		CreationDate:     time.Now().Unix(),
+	}
+
+	if payload.Name != "" {
+		promotion.TargetName = payload.Name
+	}
+	if payload.Namespace != "" {
+		promotion.TargetNamespace = payload.Namespace
+	}
+
+	switch stack.Type {
+	case portainer.DockerSwarmStack:
+		promotion.TargetName = handler.SwarmStackManager.NormalizeStackName(promotion.TargetName)
+	case portainer.DockerComposeStack:
+		promotion.TargetName = handler.ComposeStackManager.NormalizeStackName(promotion.TargetName)
+	}
+
+	target, err := handler.findPromotionTarget(promotion)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the target stack from the database", err)
+	}
+
+	if target != nil {
+		if err := validatePromotionTarget(promotion, target); err != nil {
+			return httperror.BadRequest("Invalid target stack", err)
+		}
+
+		promotion.TargetStackID = target.ID
+		if promotion.TargetSwarmID == "" {
+			promotion.TargetSwarmID = target.SwarmID
+		}
+	}
+
+	if stack.Type == portainer.DockerSwarmStack && promotion.TargetSwarmID == "" {
+		return httperror.BadRequest("Invalid Swarm identifier. Required to promote a Swarm stack", errors.New("missing swarm identifier"))
+	}
+
+	if stack.GitConfig != nil {
+		promotion.Revision.CommitHash = stack.GitConfig.ConfigHash
+	}
+	promotion.Revision.SourceStackID = stack.ID
+
+	err = handler.DataStore.StackPromotion().Create(promotion)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the stack promotion inside the database", err)
+	}
+
+	promotionPath := handler.FileService.GetStackPromotionPath(strconv.Itoa(int(promotion.ID)))
+
+	err = filesystem.CopyDir(stack.ProjectPath, promotionPath, false)
+	if err == nil {
+		promotion.Revision.FileHash, err = stackutils.ProjectHash(promotionPath)
+	}
+
+	if err == nil {
+		err = handler.DataStore.StackPromotion().Update(promotion.ID, promotion)
+	}
+
+	if err != nil {
+		handler.removePromotionFiles(promotion)
+		if deleteErr := handler.DataStore.StackPromotion().Delete(promotion.ID); deleteErr != nil {
+			return httperror.InternalServerError("Unable to remove the stack promotion from the database", deleteErr)
+		}
+
+		return httperror.InternalServerError("Unable to capture the stack files", err)
+	}
+
+	return response.JSON(w, promotion)
+}
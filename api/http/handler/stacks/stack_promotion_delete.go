@@ -0,0 +1,45 @@
+package stacks
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id StackPromotionDelete
+// @summary Remove a stack promotion
+// @description Cancel a pending stack promotion or remove a reviewed one from the history.
+// @description **Access policy**: administrator or user who requested a pending promotion
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Stack promotion identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Stack promotion not found"
+// @failure 500 "Server error"
+// @router /stacks/promotions/{id} [delete]
+func (handler *Handler) stackPromotionDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	promotion, securityContext, httpErr := handler.retrieveStackPromotion(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	canCancel := promotion.RequestedBy == securityContext.UserID && promotion.Status == portainer.StackPromotionPending
+	if !securityContext.IsAdmin && !canCancel {
+		return httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+	}
+
+	err := handler.DataStore.StackPromotion().Delete(promotion.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the stack promotion from the database", err)
+	}
+
+	handler.removePromotionFiles(promotion)
+
+	return response.Empty(w)
+}
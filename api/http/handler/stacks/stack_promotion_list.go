@@ -0,0 +1,131 @@
+package stacks
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id StackPromotionList
+// @summary List stack promotions
+// @description List the stack promotions requested by the user or that the user can review.
+// @description Administrators can see every promotion.
+// @description **Access policy**: authenticated
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param stackId query int false "Only list the promotions of this stack"
+// @param status query int false "Only list the promotions with this status" Enums(1,2,3,4)
+// @success 200 {array} portainer.StackPromotion "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /stacks/promotions [get]
+func (handler *Handler) stackPromotionList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stackID, err := request.RetrieveNumericQueryParameter(r, "stackId", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: stackId", err)
+	}
+
+	status, err := request.RetrieveNumericQueryParameter(r, "status", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: status", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	promotions, err := handler.DataStore.StackPromotion().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve stack promotions from the database", err)
+	}
+
+	filtered := make([]portainer.StackPromotion, 0)
+	for _, promotion := range promotions {
+		if stackID != 0 && int(promotion.SourceStackID) != stackID && int(promotion.TargetStackID) != stackID {
+			continue
+		}
+
+		if status != 0 && int(promotion.Status) != status {
+			continue
+		}
+
+		if promotion.RequestedBy != securityContext.UserID {
+			canReview, err := handler.userCanReviewPromotion(securityContext, &promotion)
+			if err != nil {
+				return httperror.InternalServerError("Unable to verify user authorizations to review the stack promotion", err)
+			}
+
+			if !canReview {
+				continue
+			}
+		}
+
+		filtered = append(filtered, promotion)
+	}
+
+	return response.JSON(w, filtered)
+}
+
+// @id StackPromotionInspect
+// @summary Inspect a stack promotion
+// @description Retrieve details about a stack promotion.
+// @description **Access policy**: authenticated
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Stack promotion identifier"
+// @success 200 {object} portainer.StackPromotion "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Stack promotion not found"
+// @failure 500 "Server error"
+// @router /stacks/promotions/{id} [get]
+func (handler *Handler) stackPromotionInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	promotion, securityContext, httpErr := handler.retrieveStackPromotion(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if promotion.RequestedBy != securityContext.UserID {
+		canReview, err := handler.userCanReviewPromotion(securityContext, promotion)
+		if err != nil {
+			return httperror.InternalServerError("Unable to verify user authorizations to review the stack promotion", err)
+		}
+
+		if !canReview {
+			return httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+		}
+	}
+
+	return response.JSON(w, promotion)
+}
+
+func (handler *Handler) retrieveStackPromotion(r *http.Request) (*portainer.StackPromotion, *security.RestrictedRequestContext, *httperror.HandlerError) {
+	promotionID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, nil, httperror.BadRequest("Invalid stack promotion identifier route variable", err)
+	}
+
+	promotion, err := handler.DataStore.StackPromotion().Read(portainer.StackPromotionID(promotionID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, nil, httperror.NotFound("Unable to find a stack promotion with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to find a stack promotion with the specified identifier inside the database", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	return promotion, securityContext, nil
+}
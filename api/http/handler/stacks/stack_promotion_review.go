@@ -0,0 +1,157 @@
+package stacks
+
+import (
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/pkg/errors"
+)
+
+type stackPromotionReviewPayload struct {
+	// Comment of the reviewer
+	Comment string `example:"LGTM"`
+}
+
+func (payload *stackPromotionReviewPayload) Validate(r *http.Request) error {
+	return nil
+}
+
+// @id StackPromotionApprove
+// @summary Approve a stack promotion
+// @description Approve a pending stack promotion and deploy its revision on the target environment.
+// @description **Access policy**: administrator or user holding the approver role on the target environment
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Stack promotion identifier"
+// @param body body stackPromotionReviewPayload false "Review details"
+// @success 200 {object} portainer.StackPromotion "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Stack promotion not found"
+// @failure 500 "Server error"
+// @router /stacks/promotions/{id}/approve [post]
+func (handler *Handler) stackPromotionApprove(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	handler.promotionMutex.Lock()
+	defer handler.promotionMutex.Unlock()
+
+	promotion, securityContext, httpErr := handler.reviewStackPromotion(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(promotion.TargetEndpointID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find the target environment inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find the target environment inside the database", err)
+	}
+
+	stack, deployErr := handler.deployPromotion(r, promotion, endpoint)
+	if stack != nil {
+		promotion.TargetStackID = stack.ID
+	}
+
+	promotion.Status = portainer.StackPromotionDeployed
+	if deployErr != nil {
+		promotion.Status = portainer.StackPromotionFailed
+		promotion.Error = deployErr.Error()
+	}
+
+	err = handler.saveStackPromotionReview(promotion, securityContext)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the stack promotion changes inside the database", err)
+	}
+
+	if deployErr != nil {
+		return httperror.InternalServerError("Unable to deploy the promoted stack", deployErr)
+	}
+
+	return response.JSON(w, promotion)
+}
+
+// @id StackPromotionReject
+// @summary Reject a stack promotion
+// @description Reject a pending stack promotion.
+// @description **Access policy**: administrator or user holding the approver role on the target environment
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Stack promotion identifier"
+// @param body body stackPromotionReviewPayload false "Review details"
+// @success 200 {object} portainer.StackPromotion "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Stack promotion not found"
+// @failure 500 "Server error"
+// @router /stacks/promotions/{id}/reject [post]
+func (handler *Handler) stackPromotionReject(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	handler.promotionMutex.Lock()
+	defer handler.promotionMutex.Unlock()
+
+	promotion, securityContext, httpErr := handler.reviewStackPromotion(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	promotion.Status = portainer.StackPromotionRejected
+
+	err := handler.saveStackPromotionReview(promotion, securityContext)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the stack promotion changes inside the database", err)
+	}
+
+	return response.JSON(w, promotion)
+}
+
+// reviewStackPromotion retrieves a pending promotion the user is allowed to review and records the review comment,
+// the caller must hold promotionMutex so that the status check and the review are not interleaved with another review
+func (handler *Handler) reviewStackPromotion(r *http.Request) (*portainer.StackPromotion, *security.RestrictedRequestContext, *httperror.HandlerError) {
+	var payload stackPromotionReviewPayload
+	if r.ContentLength != 0 {
+		if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+			return nil, nil, httperror.BadRequest("Invalid request payload", err)
+		}
+	}
+
+	promotion, securityContext, httpErr := handler.retrieveStackPromotion(r)
+	if httpErr != nil {
+		return nil, nil, httpErr
+	}
+
+	canReview, err := handler.userCanReviewPromotion(securityContext, promotion)
+	if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to verify user authorizations to review the stack promotion", err)
+	}
+	if !canReview {
+		return nil, nil, httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+	}
+
+	if promotion.Status != portainer.StackPromotionPending {
+		return nil, nil, httperror.BadRequest("The stack promotion was already reviewed", errors.New("stack promotion is not pending"))
+	}
+
+	promotion.ReviewComment = payload.Comment
+
+	return promotion, securityContext, nil
+}
+
+func (handler *Handler) saveStackPromotionReview(promotion *portainer.StackPromotion, securityContext *security.RestrictedRequestContext) error {
+	promotion.ReviewedBy = securityContext.UserID
+	promotion.ReviewDate = time.Now().Unix()
+
+	handler.removePromotionFiles(promotion)
+
+	return handler.DataStore.StackPromotion().Update(promotion.ID, promotion)
+}
@@ -0,0 +1,108 @@
+package stacks
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/stacks/stackutils"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+type stackRevisionResponse struct {
+	StackID    portainer.StackID    `json:"StackId" example:"2"`
+	StackName  string               `json:"StackName" example:"myStack"`
+	EndpointID portainer.EndpointID `json:"EndpointId" example:"2"`
+	Namespace  string               `json:"Namespace,omitempty" example:"default"`
+	Revision   portainer.StackRevision
+	// Whether the environment runs the current revision of the promoted stack
+	UpToDate bool `example:"true"`
+}
+
+// @id StackRevisions
+// @summary List where the revisions of a stack are live
+// @description List the current revision of a stack and the revisions deployed by its promotions on other environments.
+// @description **Access policy**: authenticated
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Stack identifier"
+// @success 200 {array} stackRevisionResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/revisions [get]
+func (handler *Handler) stackRevisions(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stackID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid stack identifier route variable", err)
+	}
+
+	stack, err := handler.DataStore.Stack().Read(portainer.StackID(stackID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a stack with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a stack with the specified identifier inside the database", err)
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(stack.EndpointID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find the environment associated to the stack inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find the environment associated to the stack inside the database", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	if httpErr := handler.checkStackManagementAccess(r, securityContext, stack, endpoint); httpErr != nil {
+		return httpErr
+	}
+
+	current := portainer.StackRevision{SourceStackID: stack.ID}
+	if stack.GitConfig != nil {
+		current.CommitHash = stack.GitConfig.ConfigHash
+	}
+
+	current.FileHash, err = stackutils.ProjectHash(stack.ProjectPath)
+	if err != nil {
+		return httperror.InternalServerError("Unable to compute the revision of the stack files", err)
+	}
+
+	revisions := []stackRevisionResponse{{
+		StackID:    stack.ID,
+		StackName:  stack.Name,
+		EndpointID: stack.EndpointID,
+		Namespace:  stack.Namespace,
+		Revision:   current,
+		UpToDate:   true,
+	}}
+
+	stacks, err := handler.DataStore.Stack().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve stacks from the database", err)
+	}
+
+	for _, target := range stacks {
+		if target.PromotedRevision == nil || target.PromotedRevision.SourceStackID != stack.ID {
+			continue
+		}
+
+		revisions = append(revisions, stackRevisionResponse{
+			StackID:    target.ID,
+			StackName:  target.Name,
+			EndpointID: target.EndpointID,
+			Namespace:  target.Namespace,
+			Revision:   *target.PromotedRevision,
+			UpToDate:   target.PromotedRevision.FileHash == current.FileHash,
+		})
+	}
+
+	return response.JSON(w, revisions)
+}
@@ -132,6 +132,8 @@ func (handler *Handler) stackStart(w http.ResponseWriter, r *http.Request) *http
 		stack.GitConfig.Authentication.Password = ""
 	}
 
+	hideStackSecrets(stack)
+
 	return response.JSON(w, stack)
 }
 
@@ -160,7 +162,13 @@ func (handler *Handler) startStack(
 			return handler.StackDeployer.StartRemoteComposeStack(stack, endpoint, filteredRegistries)
 		}
 
-		return handler.ComposeStackManager.Up(context.TODO(), stack, endpoint, false)
+		return handler.StackDeployer.WithSecretEnv(stack, func() error {
+			if err := handler.StackDeployer.CheckPolicies(stack, endpoint); err != nil {
+				return err
+			}
+
+			return handler.ComposeStackManager.Up(context.TODO(), stack, endpoint, false)
+		})
 	case portainer.DockerSwarmStack:
 		stack.Name = handler.SwarmStackManager.NormalizeStackName(stack.Name)
 
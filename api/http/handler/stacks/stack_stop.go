@@ -117,6 +117,8 @@ func (handler *Handler) stackStop(w http.ResponseWriter, r *http.Request) *httpe
 		stack.GitConfig.Authentication.Password = ""
 	}
 
+	hideStackSecrets(stack)
+
 	return response.JSON(w, stack)
 }
 
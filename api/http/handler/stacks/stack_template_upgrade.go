@@ -0,0 +1,195 @@
+package stacks
+
+import (
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/customtemplates/variables"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/stacks/stackutils"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/pkg/errors"
+)
+
+type stackTemplateUpgradePayload struct {
+	// Values of the custom template variables. Values of non secret variables default to the stack environment
+	Variables []portainer.Pair
+	// A list of environment variables used during stack deployment, the current environment is kept when omitted
+	Env []portainer.Pair
+	// Prune services that are no longer referenced (only available for Swarm stacks)
+	Prune bool `example:"true"`
+	// Force a pulling to current image with the original tag though the image is already the latest
+	PullImage bool `example:"false"`
+}
+
+func (payload *stackTemplateUpgradePayload) Validate(r *http.Request) error {
+	return nil
+}
+
+// @id StackTemplateUpgrade
+// @summary Upgrade a stack to the latest version of its custom template
+// @description Render the current version of the custom template the stack was created from and redeploy the stack.
+// @description **Access policy**: authenticated
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Stack identifier"
+// @param body body stackTemplateUpgradePayload true "Template variables"
+// @success 200 {object} portainer.Stack "Success"
+// @success 202 {object} portainer.PendingOperation "Operation stored until approved"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/template/upgrade [post]
+func (handler *Handler) stackTemplateUpgrade(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stackID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid stack identifier route variable", err)
+	}
+
+	var payload stackTemplateUpgradePayload
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	stack, err := handler.DataStore.Stack().Read(portainer.StackID(stackID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a stack with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a stack with the specified identifier inside the database", err)
+	}
+
+	if stack.CustomTemplateID == 0 {
+		return httperror.BadRequest("The stack was not created from a custom template", errors.New("stack has no custom template"))
+	}
+
+	if stack.GitConfig != nil {
+		return httperror.BadRequest("Git based stacks cannot be upgraded from a custom template", errors.New("stack is git based"))
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(stack.EndpointID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find the environment associated to the stack inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find the environment associated to the stack inside the database", err)
+	}
+
+	err = handler.requestBouncer.AuthorizedEndpointOperation(r, endpoint)
+	if err != nil {
+		return httperror.Forbidden("Permission denied to access environment", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	if stack.Type == portainer.DockerSwarmStack || stack.Type == portainer.DockerComposeStack {
+		resourceControl, err := handler.DataStore.ResourceControl().ResourceControlByResourceIDAndType(stackutils.ResourceControlID(stack.EndpointID, stack.Name), portainer.StackResourceControl)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve a resource control associated to the stack", err)
+		}
+
+		access, err := handler.userCanAccessStack(securityContext, endpoint.ID, resourceControl)
+		if err != nil {
+			return httperror.InternalServerError("Unable to verify user authorizations to validate stack access", err)
+		}
+		if !access {
+			return httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+		}
+	}
+
+	canManage, err := handler.userCanManageStacks(securityContext, endpoint)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations to validate stack upgrade", err)
+	}
+	if !canManage {
+		errMsg := "Stack editing is disabled for non-admin users"
+		return httperror.Forbidden(errMsg, errors.New(errMsg))
+	}
+
+	if httpErr := handler.checkCustomTemplateAccess(r, stack.CustomTemplateID); httpErr != nil {
+		return httpErr
+	}
+
+	customTemplate, err := handler.DataStore.CustomTemplate().Read(stack.CustomTemplateID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the custom template from the database", err)
+	}
+
+	env := stack.Env
+	if payload.Env != nil {
+		env = payload.Env
+	}
+
+	// the stored secret values are kept unless new ones are provided
+	secretEnv, err := handler.StackDeployer.SecretEnv(stack)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the secret template variables of the stack", err)
+	}
+
+	provided := append(secretEnv, variables.FilterSecrets(customTemplate.Variables, env)...)
+	values, err := variables.ResolveValues(customTemplate.Variables, append(provided, payload.Variables...))
+	if err != nil {
+		return httperror.BadRequest("Invalid custom template variables", err)
+	}
+
+	fileContent, err := handler.FileService.GetFileContent(customTemplate.ProjectPath, customTemplate.EntryPoint)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve custom template file from disk", err)
+	}
+
+	// the secret values are stored encrypted with the stack, neither the stack file nor the stack environment store them
+	stackFileContent, secretEnv := variables.RenderStackFile(string(fileContent), customTemplate.Variables, values)
+	env = variables.FilterSecrets(customTemplate.Variables, env)
+
+	if err := handler.StackDeployer.SetSecretEnv(stack, secretEnv); err != nil {
+		return httperror.InternalServerError("Unable to store the secret template variables", err)
+	}
+
+	var deployErr *httperror.HandlerError
+	switch stack.Type {
+	case portainer.DockerSwarmStack:
+		stack.Name = handler.SwarmStackManager.NormalizeStackName(stack.Name)
+		deployErr = handler.deploySwarmStackFileContent(r, stack, endpoint, stackFileContent, env, payload.Prune, payload.PullImage)
+	case portainer.DockerComposeStack:
+		stack.Name = handler.ComposeStackManager.NormalizeStackName(stack.Name)
+		deployErr = handler.deployComposeStackFileContent(r, stack, endpoint, stackFileContent, env, payload.PullImage)
+	case portainer.KubernetesStack:
+		stack.Env = env
+		deployErr = handler.deployKubernetesStackFileContent(r, stack, endpoint, stackFileContent)
+	default:
+		return httperror.InternalServerError("Unsupported stack", errors.Errorf("unsupported stack type: %v", stack.Type))
+	}
+
+	if deployErr != nil {
+		return deployErr
+	}
+
+	user, err := handler.DataStore.User().Read(securityContext.UserID)
+	if err != nil {
+		return httperror.BadRequest("Cannot find context user", errors.Wrap(err, "failed to fetch the user"))
+	}
+	stack.UpdatedBy = user.Username
+	stack.UpdateDate = time.Now().Unix()
+	stack.Status = portainer.StackStatusActive
+	stack.CustomTemplateVersion = customTemplate.Version
+
+	err = handler.DataStore.Stack().Update(stack.ID, stack)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the stack changes inside the database", err)
+	}
+
+	hideStackSecrets(stack)
+
+	return response.JSON(w, stack)
+}
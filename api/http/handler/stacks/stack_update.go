@@ -66,6 +66,7 @@ func (payload *updateSwarmStackPayload) Validate(r *http.Request) error {
 // @param endpointId query int true "Environment identifier"
 // @param body body updateSwarmStackPayload true "Stack details"
 // @success 200 {object} portainer.Stack "Success"
+// @success 202 {object} portainer.PendingOperation "Operation stored until approved"
 // @failure 400 "Invalid request"
 // @failure 403 "Permission denied"
 // @failure 404 "Not found"
@@ -161,6 +162,8 @@ func (handler *Handler) stackUpdate(w http.ResponseWriter, r *http.Request) *htt
 		stack.GitConfig.Authentication.Password = ""
 	}
 
+	hideStackSecrets(stack)
+
 	return response.JSON(w, stack)
 }
 
@@ -181,6 +184,18 @@ func (handler *Handler) updateAndDeployStack(r *http.Request, stack *portainer.S
 }
 
 func (handler *Handler) updateComposeStack(r *http.Request, stack *portainer.Stack, endpoint *portainer.Endpoint) *httperror.HandlerError {
+	var payload updateComposeStackPayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	return handler.deployComposeStackFileContent(r, stack, endpoint, payload.StackFileContent, payload.Env, payload.PullImage)
+}
+
+// deployComposeStackFileContent replaces the stack file and environment of a compose stack and redeploys it,
+// detaching the stack from git when needed. The previous stack file is restored when the deployment fails.
+func (handler *Handler) deployComposeStackFileContent(r *http.Request, stack *portainer.Stack, endpoint *portainer.Endpoint, stackFileContent string, env []portainer.Pair, pullImage bool) *httperror.HandlerError {
 	// Must not be git based stack. stop the auto update job if there is any
 	if stack.AutoUpdate != nil {
 		deployments.StopAutoupdate(stack.ID, stack.AutoUpdate.JobID, handler.Scheduler)
@@ -190,13 +205,8 @@ func (handler *Handler) updateComposeStack(r *http.Request, stack *portainer.Sta
 		stack.FromAppTemplate = true
 	}
 
-	var payload updateComposeStackPayload
-	err := request.DecodeAndValidateJSONPayload(r, &payload)
-	if err != nil {
-		return httperror.BadRequest("Invalid request payload", err)
-	}
-
-	stack.Env = payload.Env
+	stack.Env = env
+	stack.PromotedRevision = nil
 
 	if stack.GitConfig != nil {
 		// detach from git
@@ -204,7 +214,7 @@ func (handler *Handler) updateComposeStack(r *http.Request, stack *portainer.Sta
 	}
 
 	stackFolder := strconv.Itoa(int(stack.ID))
-	_, err = handler.FileService.UpdateStoreStackFileFromBytes(stackFolder, stack.EntryPoint, []byte(payload.StackFileContent))
+	_, err := handler.FileService.UpdateStoreStackFileFromBytes(stackFolder, stack.EntryPoint, []byte(stackFileContent))
 	if err != nil {
 		if rollbackErr := handler.FileService.RollbackStackFile(stackFolder, stack.EntryPoint); rollbackErr != nil {
 			log.Warn().Err(rollbackErr).Msg("rollback stack file error")
@@ -225,7 +235,7 @@ func (handler *Handler) updateComposeStack(r *http.Request, stack *portainer.Sta
 		handler.DataStore,
 		handler.FileService,
 		handler.StackDeployer,
-		payload.PullImage,
+		pullImage,
 		false)
 	if err != nil {
 		if rollbackErr := handler.FileService.RollbackStackFile(stackFolder, stack.EntryPoint); rollbackErr != nil {
@@ -251,6 +261,18 @@ func (handler *Handler) updateComposeStack(r *http.Request, stack *portainer.Sta
 }
 
 func (handler *Handler) updateSwarmStack(r *http.Request, stack *portainer.Stack, endpoint *portainer.Endpoint) *httperror.HandlerError {
+	var payload updateSwarmStackPayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	return handler.deploySwarmStackFileContent(r, stack, endpoint, payload.StackFileContent, payload.Env, payload.Prune, payload.PullImage)
+}
+
+// deploySwarmStackFileContent replaces the stack file and environment of a swarm stack and redeploys it,
+// detaching the stack from git when needed. The previous stack file is restored when the deployment fails.
+func (handler *Handler) deploySwarmStackFileContent(r *http.Request, stack *portainer.Stack, endpoint *portainer.Endpoint, stackFileContent string, env []portainer.Pair, prune, pullImage bool) *httperror.HandlerError {
 	// Must not be git based stack. stop the auto update job if there is any
 	if stack.AutoUpdate != nil {
 		deployments.StopAutoupdate(stack.ID, stack.AutoUpdate.JobID, handler.Scheduler)
@@ -260,13 +282,8 @@ func (handler *Handler) updateSwarmStack(r *http.Request, stack *portainer.Stack
 		stack.FromAppTemplate = true
 	}
 
-	var payload updateSwarmStackPayload
-	err := request.DecodeAndValidateJSONPayload(r, &payload)
-	if err != nil {
-		return httperror.BadRequest("Invalid request payload", err)
-	}
-
-	stack.Env = payload.Env
+	stack.Env = env
+	stack.PromotedRevision = nil
 
 	if stack.GitConfig != nil {
 		// detach from git
@@ -274,7 +291,7 @@ func (handler *Handler) updateSwarmStack(r *http.Request, stack *portainer.Stack
 	}
 
 	stackFolder := strconv.Itoa(int(stack.ID))
-	_, err = handler.FileService.UpdateStoreStackFileFromBytes(stackFolder, stack.EntryPoint, []byte(payload.StackFileContent))
+	_, err := handler.FileService.UpdateStoreStackFileFromBytes(stackFolder, stack.EntryPoint, []byte(stackFileContent))
 	if err != nil {
 		if rollbackErr := handler.FileService.RollbackStackFile(stackFolder, stack.EntryPoint); rollbackErr != nil {
 			log.Warn().Err(rollbackErr).Msg("rollback stack file error")
@@ -295,8 +312,8 @@ func (handler *Handler) updateSwarmStack(r *http.Request, stack *portainer.Stack
 		handler.DataStore,
 		handler.FileService,
 		handler.StackDeployer,
-		payload.Prune,
-		payload.PullImage)
+		prune,
+		pullImage)
 	if err != nil {
 		if rollbackErr := handler.FileService.RollbackStackFile(stackFolder, stack.EntryPoint); rollbackErr != nil {
 			log.Warn().Err(rollbackErr).Msg("rollback stack file error")
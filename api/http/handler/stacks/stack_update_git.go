@@ -5,6 +5,7 @@ import (
 	"time"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/git/update"
 	httperrors "github.com/portainer/portainer/api/http/errors"
@@ -27,12 +28,30 @@ type stackGitUpdatePayload struct {
 	RepositoryUsername       string
 	RepositoryPassword       string
 	TLSSkipVerify            bool
+	// Identifier of the SSH key used to authenticate, used instead of the username and password when not 0
+	RepositorySSHKeyID int `example:"0"`
+	// Identifier of the shared git credential used to authenticate, used instead of the username and password when not 0
+	RepositoryGitCredentialID int `example:"0"`
+	// Signature policy verified before the automatic redeployments, the current policy is kept when not specified
+	SignaturePolicy *stackGitSignaturePolicyPayload
+	// Submodules and LFS objects fetched with the repository, the current options are kept when not specified
+	RepositoryContent *gittypes.ContentOptions
+}
+
+type stackGitSignaturePolicyPayload struct {
+	// The commits must be signed by a trusted key when enabled, the policy is removed otherwise
+	Enabled bool `example:"true"`
+	// Identifiers of the trusted signing keys, all the keys of the keyring are trusted when empty
+	TrustedKeyIDs []int `example:"1"`
 }
 
 func (payload *stackGitUpdatePayload) Validate(r *http.Request) error {
 	if err := update.ValidateAutoUpdateSettings(payload.AutoUpdate); err != nil {
 		return err
 	}
+	if err := git.ValidateContentOptions(payload.RepositoryContent); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -49,6 +68,7 @@ func (payload *stackGitUpdatePayload) Validate(r *http.Request) error {
 // @param endpointId query int false "Stacks created before version 1.18.0 might not have an associated environment(endpoint) identifier. Use this optional parameter to set the environment(endpoint) identifier used by the stack."
 // @param body body stackGitUpdatePayload true "Git configs for pull and redeploy a stack"
 // @success 200 {object} portainer.Stack "Success"
+// @success 202 {object} portainer.PendingOperation "Operation stored until approved"
 // @failure 400 "Invalid request"
 // @failure 403 "Permission denied"
 // @failure 404 "Not found"
@@ -141,6 +161,7 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 	//update retrieved stack data based on the payload
 	stack.GitConfig.ReferenceName = payload.RepositoryReferenceName
 	stack.GitConfig.TLSSkipVerify = payload.TLSSkipVerify
+	keepWebhookSecret(stack.AutoUpdate, payload.AutoUpdate)
 	stack.AutoUpdate = payload.AutoUpdate
 	stack.Env = payload.Env
 	stack.UpdatedBy = user.Username
@@ -153,6 +174,14 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 	}
 
 	if payload.RepositoryAuthentication {
+		if httpErr := handler.checkStackSSHKeyAccess(stack, payload.RepositorySSHKeyID, securityContext.UserID); httpErr != nil {
+			return httpErr
+		}
+
+		if httpErr := handler.checkStackCredentialAccess(stack, payload.RepositoryGitCredentialID, securityContext); httpErr != nil {
+			return httpErr
+		}
+
 		password := payload.RepositoryPassword
 
 		// When the existing stack is using the custom username/password and the password is not updated,
@@ -161,10 +190,12 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 			password = stack.GitConfig.Authentication.Password
 		}
 		stack.GitConfig.Authentication = &gittypes.GitAuthentication{
-			Username: payload.RepositoryUsername,
-			Password: password,
+			Username:        payload.RepositoryUsername,
+			Password:        password,
+			SSHKeyID:        payload.RepositorySSHKeyID,
+			GitCredentialID: payload.RepositoryGitCredentialID,
 		}
-		_, err = handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, stack.GitConfig.Authentication.Username, stack.GitConfig.Authentication.Password, stack.GitConfig.TLSSkipVerify)
+		_, err = handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, stack.GitConfig.Authentication, stack.GitConfig.TLSSkipVerify)
 		if err != nil {
 			return httperror.InternalServerError("Unable to fetch git repository", err)
 		}
@@ -172,6 +203,34 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 		stack.GitConfig.Authentication = nil
 	}
 
+	if payload.RepositoryContent != nil {
+		if httpErr := handler.checkStackSubmoduleSSHKeyAccess(stack, payload.RepositoryContent, securityContext.UserID); httpErr != nil {
+			return httpErr
+		}
+
+		if httpErr := handler.checkStackSubmoduleCredentialAccess(stack, payload.RepositoryContent, securityContext); httpErr != nil {
+			return httpErr
+		}
+
+		stack.GitConfig.SetContentOptions(payload.RepositoryContent)
+	}
+
+	if payload.SignaturePolicy != nil {
+		stack.GitConfig.SignaturePolicy = nil
+		if payload.SignaturePolicy.Enabled {
+			policy := &gittypes.SignaturePolicy{TrustedKeyIDs: payload.SignaturePolicy.TrustedKeyIDs}
+
+			trustedKeys, err := git.ResolveTrustedKeys(handler.DataStore.GitSigningKey(), policy)
+			if err != nil {
+				return httperror.BadRequest("Invalid signature policy", err)
+			} else if len(trustedKeys) == 0 {
+				return httperror.BadRequest("Invalid signature policy", errors.New("the signing keyring is empty"))
+			}
+
+			stack.GitConfig.SignaturePolicy = policy
+		}
+	}
+
 	if payload.AutoUpdate != nil && payload.AutoUpdate.Interval != "" {
 		jobID, e := deployments.StartAutoupdate(stack.ID, stack.AutoUpdate.Interval, handler.Scheduler, handler.StackDeployer, handler.DataStore, handler.GitService)
 		if e != nil {
@@ -192,5 +251,75 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 		stack.GitConfig.Authentication.Password = ""
 	}
 
+	hideStackSecrets(stack)
+
 	return response.JSON(w, stack)
 }
+
+// checkStackSSHKeyAccess makes sure that the user can authenticate the git repository of the stack with the SSH key,
+// the SSH key already saved with the stack can be kept by all the users allowed to edit the stack
+func (handler *Handler) checkStackSSHKeyAccess(stack *portainer.Stack, sshKeyID int, userID portainer.UserID) *httperror.HandlerError {
+	if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.SSHKeyID == sshKeyID {
+		return nil
+	}
+
+	err := git.ValidateSSHKeyAccess(handler.DataStore.GitSSHKey(), sshKeyID, userID)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the SSH key", err)
+	}
+
+	return nil
+}
+
+// checkStackSubmoduleSSHKeyAccess makes sure that the user can use the SSH key of the submodules, the key already used by the stack is kept
+func (handler *Handler) checkStackSubmoduleSSHKeyAccess(stack *portainer.Stack, content *gittypes.ContentOptions, userID portainer.UserID) *httperror.HandlerError {
+	if content.SubmoduleAuthentication == nil {
+		return nil
+	}
+
+	current := stack.GitConfig.SubmoduleAuthentication
+	if current != nil && current.SSHKeyID == content.SubmoduleAuthentication.SSHKeyID {
+		return nil
+	}
+
+	err := git.ValidateContentSSHKeyAccess(handler.DataStore.GitSSHKey(), content, userID)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the SSH key of the submodules", err)
+	}
+
+	return nil
+}
+
+// checkStackCredentialAccess makes sure that the user can authenticate the git repository of the stack with the shared git credential,
+// the credential already saved with the stack can be kept by all the users allowed to edit the stack
+func (handler *Handler) checkStackCredentialAccess(stack *portainer.Stack, credentialID int, securityContext *security.RestrictedRequestContext) *httperror.HandlerError {
+	if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.GitCredentialID == credentialID {
+		return nil
+	}
+
+	err := git.ValidateCredentialAccess(handler.DataStore.GitCredential(), credentialID, securityContext.UserID, securityContext.UserMemberships, securityContext.IsAdmin)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the git credential", err)
+	}
+
+	return nil
+}
+
+// checkStackSubmoduleCredentialAccess makes sure that the user can use the git credential of the submodules, the credential already used by the stack is kept
+func (handler *Handler) checkStackSubmoduleCredentialAccess(stack *portainer.Stack, content *gittypes.ContentOptions, securityContext *security.RestrictedRequestContext) *httperror.HandlerError {
+	if content.SubmoduleAuthentication == nil {
+		return nil
+	}
+
+	current := stack.GitConfig.SubmoduleAuthentication
+	if current != nil && current.GitCredentialID == content.SubmoduleAuthentication.GitCredentialID {
+		return nil
+	}
+
+	err := git.ValidateContentCredentialAccess(handler.DataStore.GitCredential(), content, securityContext.UserID, securityContext.UserMemberships, securityContext.IsAdmin)
+	if err != nil {
+		return httperror.Forbidden("Unable to use the git credential of the submodules", err)
+	}
+
+	return nil
+}
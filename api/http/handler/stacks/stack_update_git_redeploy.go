@@ -6,6 +6,8 @@ import (
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/git"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/portainer/portainer/api/git/update"
 	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/api/http/security"
 	k "github.com/portainer/portainer/api/kubernetes"
@@ -25,6 +27,10 @@ type stackGitRedployPayload struct {
 	RepositoryPassword       string
 	Env                      []portainer.Pair
 	Prune                    bool
+	// Identifier of the SSH key used to authenticate, used instead of the username and password when not 0
+	RepositorySSHKeyID int `example:"0"`
+	// Identifier of the shared git credential used to authenticate, used instead of the username and password when not 0
+	RepositoryGitCredentialID int `example:"0"`
 	// Force a pulling to current image with the original tag though the image is already the latest
 	PullImage bool `example:"false"`
 }
@@ -46,6 +52,7 @@ func (payload *stackGitRedployPayload) Validate(r *http.Request) error {
 // @param endpointId query int false "Stacks created before version 1.18.0 might not have an associated environment(endpoint) identifier. Use this optional parameter to set the environment(endpoint) identifier used by the stack."
 // @param body body stackGitRedployPayload true "Git configs for pull and redeploy a stack"
 // @success 200 {object} portainer.Stack "Success"
+// @success 202 {object} portainer.PendingOperation "Operation stored until approved"
 // @failure 400 "Invalid request"
 // @failure 403 "Permission denied"
 // @failure 404 "Not found"
@@ -136,26 +143,54 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	repositoryUsername := ""
-	repositoryPassword := ""
+	var repositoryAuth *gittypes.GitAuthentication
 	if payload.RepositoryAuthentication {
-		repositoryPassword = payload.RepositoryPassword
+		repositoryAuth = &gittypes.GitAuthentication{
+			Username:        payload.RepositoryUsername,
+			Password:        payload.RepositoryPassword,
+			SSHKeyID:        payload.RepositorySSHKeyID,
+			GitCredentialID: payload.RepositoryGitCredentialID,
+		}
 
 		// When the existing stack is using the custom username/password and the password is not updated,
 		// the stack should keep using the saved username/password
-		if repositoryPassword == "" && stack.GitConfig != nil && stack.GitConfig.Authentication != nil {
-			repositoryPassword = stack.GitConfig.Authentication.Password
+		if repositoryAuth.Password == "" && stack.GitConfig != nil && stack.GitConfig.Authentication != nil {
+			repositoryAuth.Password = stack.GitConfig.Authentication.Password
+		}
+
+		if httpErr := handler.checkStackSSHKeyAccess(stack, payload.RepositorySSHKeyID, securityContext.UserID); httpErr != nil {
+			return httpErr
+		}
+
+		if httpErr := handler.checkStackCredentialAccess(stack, payload.RepositoryGitCredentialID, securityContext); httpErr != nil {
+			return httpErr
 		}
-		repositoryUsername = payload.RepositoryUsername
 	}
 
 	cloneOptions := git.CloneOptions{
-		ProjectPath:   stack.ProjectPath,
-		URL:           stack.GitConfig.URL,
-		ReferenceName: stack.GitConfig.ReferenceName,
-		Username:      repositoryUsername,
-		Password:      repositoryPassword,
-		TLSSkipVerify: stack.GitConfig.TLSSkipVerify,
+		ProjectPath:    stack.ProjectPath,
+		URL:            stack.GitConfig.URL,
+		ReferenceName:  stack.GitConfig.ReferenceName,
+		Authentication: repositoryAuth,
+		TLSSkipVerify:  stack.GitConfig.TLSSkipVerify,
+		Content:        stack.GitConfig.ContentOptions(),
+	}
+
+	if stack.GitConfig.SignaturePolicy != nil {
+		commitID, err := handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, repositoryAuth, stack.GitConfig.TLSSkipVerify)
+		if err != nil {
+			return httperror.InternalServerError("Unable get latest commit id", errors.WithMessagef(err, "failed to fetch latest commit id of the stack %v", stack.ID))
+		}
+
+		err = update.VerifyCommitSignature(handler.GitService, handler.DataStore.GitSigningKey(), stack.GitConfig, repositoryAuth, commitID)
+		if errors.Is(err, git.ErrCommitSignatureVerification) {
+			return httperror.BadRequest("The commit does not satisfy the signature policy of the stack", err)
+		} else if err != nil {
+			return httperror.InternalServerError("Unable to verify the commit signature", err)
+		}
+
+		// the clone is refused when the reference moves away from the verified commit
+		cloneOptions.CommitID = commitID
 	}
 
 	clean, err := git.CloneWithBackup(handler.GitService, handler.FileService, cloneOptions)
@@ -170,9 +205,12 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 		return httpErr
 	}
 
-	newHash, err := handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, repositoryUsername, repositoryPassword, stack.GitConfig.TLSSkipVerify)
-	if err != nil {
-		return httperror.InternalServerError("Unable get latest commit id", errors.WithMessagef(err, "failed to fetch latest commit id of the stack %v", stack.ID))
+	newHash := cloneOptions.CommitID
+	if newHash == "" {
+		newHash, err = handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, repositoryAuth, stack.GitConfig.TLSSkipVerify)
+		if err != nil {
+			return httperror.InternalServerError("Unable get latest commit id", errors.WithMessagef(err, "failed to fetch latest commit id of the stack %v", stack.ID))
+		}
 	}
 	stack.GitConfig.ConfigHash = newHash
 
@@ -194,6 +232,8 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 		stack.GitConfig.Authentication.Password = ""
 	}
 
+	hideStackSecrets(stack)
+
 	return response.JSON(w, stack)
 }
 
@@ -253,7 +293,7 @@ func (handler *Handler) deployStack(r *http.Request, stack *portainer.Stack, pul
 			Kind:      "git",
 		}
 
-		deploymentConfiger, err = deployments.CreateKubernetesStackDeploymentConfig(stack, handler.KubernetesDeployer, appLabel, user, endpoint)
+		deploymentConfiger, err = deployments.CreateKubernetesStackDeploymentConfig(stack, handler.KubernetesDeployer, handler.StackDeployer, appLabel, user, endpoint)
 		if err != nil {
 			return httperror.InternalServerError(err.Error(), err)
 		}
@@ -33,6 +33,10 @@ type kubernetesGitStackUpdatePayload struct {
 	RepositoryPassword       string
 	AutoUpdate               *portainer.AutoUpdateSettings
 	TLSSkipVerify            bool
+	// Identifier of the SSH key used to authenticate, used instead of the username and password when not 0
+	RepositorySSHKeyID int `example:"0"`
+	// Identifier of the shared git credential used to authenticate, used instead of the username and password when not 0
+	RepositoryGitCredentialID int `example:"0"`
 }
 
 func (payload *kubernetesFileStackUpdatePayload) Validate(r *http.Request) error {
@@ -65,18 +69,34 @@ func (handler *Handler) updateKubernetesStack(r *http.Request, stack *portainer.
 
 		stack.GitConfig.ReferenceName = payload.RepositoryReferenceName
 		stack.GitConfig.TLSSkipVerify = payload.TLSSkipVerify
+		keepWebhookSecret(stack.AutoUpdate, payload.AutoUpdate)
 		stack.AutoUpdate = payload.AutoUpdate
 
 		if payload.RepositoryAuthentication {
+			securityContext, err := security.RetrieveRestrictedRequestContext(r)
+			if err != nil {
+				return httperror.InternalServerError("Unable to retrieve info from request context", err)
+			}
+
+			if httpErr := handler.checkStackSSHKeyAccess(stack, payload.RepositorySSHKeyID, securityContext.UserID); httpErr != nil {
+				return httpErr
+			}
+
+			if httpErr := handler.checkStackCredentialAccess(stack, payload.RepositoryGitCredentialID, securityContext); httpErr != nil {
+				return httpErr
+			}
+
 			password := payload.RepositoryPassword
 			if password == "" && stack.GitConfig != nil && stack.GitConfig.Authentication != nil {
 				password = stack.GitConfig.Authentication.Password
 			}
 			stack.GitConfig.Authentication = &gittypes.GitAuthentication{
-				Username: payload.RepositoryUsername,
-				Password: password,
+				Username:        payload.RepositoryUsername,
+				Password:        password,
+				SSHKeyID:        payload.RepositorySSHKeyID,
+				GitCredentialID: payload.RepositoryGitCredentialID,
 			}
-			_, err := handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, stack.GitConfig.Authentication.Username, stack.GitConfig.Authentication.Password, stack.GitConfig.TLSSkipVerify)
+			_, err = handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, stack.GitConfig.Authentication, stack.GitConfig.TLSSkipVerify)
 			if err != nil {
 				return httperror.InternalServerError("Unable to fetch git repository", err)
 			}
@@ -102,6 +122,12 @@ func (handler *Handler) updateKubernetesStack(r *http.Request, stack *portainer.
 		return httperror.BadRequest("Invalid request payload", err)
 	}
 
+	return handler.deployKubernetesStackFileContent(r, stack, endpoint, payload.StackFileContent)
+}
+
+// deployKubernetesStackFileContent deploys the new manifest of a file based kubernetes stack and stores it on disk
+// once the deployment succeeded
+func (handler *Handler) deployKubernetesStackFileContent(r *http.Request, stack *portainer.Stack, endpoint *portainer.Endpoint, stackFileContent string) *httperror.HandlerError {
 	tokenData, err := security.RetrieveTokenData(r)
 	if err != nil {
 		return httperror.BadRequest("Failed to retrieve user token data", err)
@@ -110,7 +136,7 @@ func (handler *Handler) updateKubernetesStack(r *http.Request, stack *portainer.
 	tempFileDir, _ := os.MkdirTemp("", "kub_file_content")
 	defer os.RemoveAll(tempFileDir)
 
-	if err := filesystem.WriteToFile(filesystem.JoinPaths(tempFileDir, stack.EntryPoint), []byte(payload.StackFileContent)); err != nil {
+	if err := filesystem.WriteToFile(filesystem.JoinPaths(tempFileDir, stack.EntryPoint), []byte(stackFileContent)); err != nil {
 		return httperror.InternalServerError("Failed to persist deployment file in a temp directory", err)
 	}
 
@@ -138,7 +164,7 @@ func (handler *Handler) updateKubernetesStack(r *http.Request, stack *portainer.
 	}
 
 	stackFolder := strconv.Itoa(int(stack.ID))
-	projectPath, err := handler.FileService.UpdateStoreStackFileFromBytes(stackFolder, stack.EntryPoint, []byte(payload.StackFileContent))
+	projectPath, err := handler.FileService.UpdateStoreStackFileFromBytes(stackFolder, stack.EntryPoint, []byte(stackFileContent))
 	if err != nil {
 		if rollbackErr := handler.FileService.RollbackStackFile(stackFolder, stack.EntryPoint); rollbackErr != nil {
 			log.Warn().Err(rollbackErr).Msg("rollback stack file error")
@@ -152,6 +178,7 @@ func (handler *Handler) updateKubernetesStack(r *http.Request, stack *portainer.
 		return httperror.InternalServerError(errMsg, err)
 	}
 	stack.ProjectPath = projectPath
+	stack.PromotedRevision = nil
 
 	handler.FileService.RemoveStackFileBackup(stackFolder, stack.EntryPoint)
 
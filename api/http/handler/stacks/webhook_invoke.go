@@ -2,23 +2,36 @@ package stacks
 
 import (
 	"errors"
+	"io"
 	"net/http"
+	"path"
+	"strings"
 
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
+	"github.com/portainer/portainer/api/git/pushevent"
 	"github.com/portainer/portainer/api/stacks/deployments"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
 
 	"github.com/gofrs/uuid"
+	"github.com/rs/zerolog/log"
 )
 
+// maxWebhookPayloadSize is the maximum size of the push event payloads read from the git providers
+const maxWebhookPayloadSize = 25 << 20
+
 // @id WebhookInvoke
 // @summary Webhook for triggering stack updates from git
+// @description The push events of GitHub, GitLab and Gitea are understood: the pushes to another reference than the one of the stack,
+// @description or which do not change the stack files, are ignored. When a webhook secret is set, only the push events signed with the secret are accepted.
 // @description **Access policy**: public
 // @tags stacks
 // @param webhookID path string true "Stack identifier"
 // @success 200 "Success"
 // @failure 400 "Invalid request"
+// @failure 401 "Invalid webhook signature"
 // @failure 409 "Conflict"
 // @failure 500 "Server error"
 // @router /stacks/webhooks/{webhookID} [post]
@@ -38,6 +51,31 @@ func (handler *Handler) webhookInvoke(w http.ResponseWriter, r *http.Request) *h
 		return &httperror.HandlerError{StatusCode: statusCode, Message: "Unable to find the stack by webhook ID", Err: err}
 	}
 
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadSize))
+	if err != nil {
+		return httperror.BadRequest("Unable to read the request body", err)
+	}
+
+	secret := ""
+	if stack.AutoUpdate != nil {
+		secret = stack.AutoUpdate.WebhookSecret
+	}
+
+	event, err := pushevent.Parse(r.Header, body, secret)
+	if errors.Is(err, pushevent.ErrInvalidSignature) {
+		return httperror.Unauthorized("Invalid webhook signature", err)
+	} else if errors.Is(err, pushevent.ErrUnsupportedEvent) {
+		log.Debug().Err(err).Int("stack_id", int(stack.ID)).Msg("ignoring the webhook event")
+
+		return response.Empty(w)
+	} else if err != nil {
+		return httperror.BadRequest("Invalid push event", err)
+	}
+
+	if event != nil && !pushEventTriggersStack(event, stack) {
+		return response.Empty(w)
+	}
+
 	if err = deployments.RedeployWhenChanged(stack.ID, handler.StackDeployer, handler.DataStore, handler.GitService); err != nil {
 		var StackAuthorMissingErr *deployments.StackAuthorMissingErr
 		if errors.As(err, &StackAuthorMissingErr) {
@@ -50,6 +88,67 @@ func (handler *Handler) webhookInvoke(w http.ResponseWriter, r *http.Request) *h
 	return response.Empty(w)
 }
 
+// pushEventTriggersStack returns true when the push targets the reference of the stack and changes one of the stack files.
+// Every push to the reference triggers the kustomize stacks, their bases and components can be anywhere in the repository.
+func pushEventTriggersStack(event *pushevent.PushEvent, stack *portainer.Stack) bool {
+	if stack.GitConfig == nil {
+		return true
+	}
+
+	if event.Deleted || !event.MatchesReference(stack.GitConfig.ReferenceName) {
+		log.Debug().
+			Int("stack_id", int(stack.ID)).
+			Str("ref", event.Ref).
+			Msg("ignoring the push event of another reference")
+
+		return false
+	}
+
+	if stack.Kustomization != nil {
+		return true
+	}
+
+	configFilePath := stack.GitConfig.ConfigFilePath
+	if configFilePath == "" {
+		configFilePath = stack.EntryPoint
+	}
+
+	files := append([]string{configFilePath}, stack.AdditionalFiles...)
+
+	// a push which only moves the commit of a submodule changes the path of the submodule, not the files inside it
+	var submodules []string
+	if stack.GitConfig.Submodules {
+		paths, err := git.SubmodulePaths(stack.ProjectPath)
+		if err != nil {
+			log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to read the submodules of the stack repository")
+
+			return true
+		}
+
+		for _, submodulePath := range paths {
+			for _, file := range files {
+				if strings.HasPrefix(path.Clean(strings.TrimPrefix(file, "/")), path.Clean(submodulePath)+"/") {
+					submodules = append(submodules, submodulePath)
+					break
+				}
+			}
+		}
+
+		files = append(files, ".gitmodules")
+	}
+
+	if !event.Touches(files, submodules) {
+		log.Debug().
+			Int("stack_id", int(stack.ID)).
+			Str("ref", event.Ref).
+			Msg("ignoring the push event, the stack files were not changed")
+
+		return false
+	}
+
+	return true
+}
+
 func retrieveUUIDRouteVariableValue(r *http.Request, name string) (uuid.UUID, error) {
 	webhookID, err := request.RetrieveRouteVariableValue(r, name)
 	if err != nil {
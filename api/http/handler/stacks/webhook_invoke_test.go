@@ -1,12 +1,21 @@
 package stacks
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/git/pushevent"
+	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/internal/testhelpers"
 
 	"github.com/gofrs/uuid"
@@ -49,6 +58,146 @@ func TestHandler_webhookInvoke(t *testing.T) {
 	})
 }
 
+func TestHandler_webhookInvoke_PushEvent(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, true)
+
+	err := store.Endpoint().Create(&portainer.Endpoint{ID: 1})
+	assert.NoError(t, err, "error creating environment")
+
+	webhookID := newGuidString(t)
+	store.StackService.Create(&portainer.Stack{
+		ID:         1,
+		EndpointID: 1,
+		CreatedBy:  "missing-author",
+		AutoUpdate: &portainer.AutoUpdateSettings{
+			Webhook:       webhookID,
+			WebhookSecret: "secret",
+		},
+		GitConfig: &gittypes.RepoConfig{
+			URL:            "https://github.com/portainer/portainer.git",
+			ReferenceName:  "refs/heads/main",
+			ConfigFilePath: "docker-compose.yml",
+		},
+	})
+
+	h := NewHandler(testhelpers.NewTestRequestBouncer())
+	h.DataStore = store
+
+	push := func(ref, changedPath, secret string) *http.Request {
+		body := fmt.Sprintf(`{"ref": %q, "commits": [{"modified": [%q]}]}`, ref, changedPath)
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(body))
+
+		req := httptest.NewRequest(http.MethodPost, "/stacks/webhooks/"+webhookID, strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "push")
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+		return req
+	}
+
+	t.Run("unsigned request results in http.StatusUnauthorized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Router.ServeHTTP(w, newRequest(webhookID))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("invalid signature results in http.StatusUnauthorized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Router.ServeHTTP(w, push("refs/heads/main", "docker-compose.yml", "other"))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("push to another branch is ignored", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Router.ServeHTTP(w, push("refs/heads/develop", "docker-compose.yml", "secret"))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("push without changes to the stack files is ignored", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Router.ServeHTTP(w, push("refs/heads/main", "README.md", "secret"))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("push changing the stack file redeploys the stack", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Router.ServeHTTP(w, push("refs/heads/main", "docker-compose.yml", "secret"))
+		assert.Equal(t, http.StatusConflict, w.Code, "the redeployment should fail on the missing author")
+	})
+}
+
+func Test_pushEventTriggersStack_Kustomization(t *testing.T) {
+	is := assert.New(t)
+
+	stack := &portainer.Stack{
+		GitConfig: &gittypes.RepoConfig{
+			ReferenceName:  "refs/heads/main",
+			ConfigFilePath: "overlays/prod",
+		},
+		Kustomization: &portainer.KubernetesKustomization{Path: "overlays/prod"},
+	}
+
+	event := &pushevent.PushEvent{Ref: "refs/heads/main", ChangedPaths: []string{"base/deployment.yaml"}, Complete: true}
+	is.True(pushEventTriggersStack(event, stack), "the bases of the kustomization are outside of its directory")
+
+	event.Ref = "refs/heads/develop"
+	is.False(pushEventTriggersStack(event, stack))
+}
+
+func Test_pushEventTriggersStack_Submodules(t *testing.T) {
+	is := assert.New(t)
+
+	projectPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(projectPath, ".gitmodules"), []byte("[submodule \"stacks\"]\n\tpath = stacks\n\turl = https://github.com/portainer/stacks.git\n"), 0600)
+	is.NoError(err)
+
+	stack := &portainer.Stack{
+		ProjectPath: projectPath,
+		GitConfig: &gittypes.RepoConfig{
+			ReferenceName:  "refs/heads/main",
+			ConfigFilePath: "stacks/docker-compose.yml",
+			Submodules:     true,
+		},
+	}
+
+	event := &pushevent.PushEvent{Ref: "refs/heads/main", ChangedPaths: []string{"stacks"}, Complete: true}
+	is.True(pushEventTriggersStack(event, stack), "moving the commit of the submodule hosting the stack file should trigger the stack")
+
+	event.ChangedPaths = []string{"README.md"}
+	is.False(pushEventTriggersStack(event, stack))
+
+	event.ChangedPaths = []string{".gitmodules"}
+	is.True(pushEventTriggersStack(event, stack), "changing the submodules should trigger the stack")
+
+	stack.GitConfig.Submodules = false
+	event.ChangedPaths = []string{"stacks"}
+	is.False(pushEventTriggersStack(event, stack), "the submodules are not fetched")
+}
+
+func Test_webhookSecretIsHidden(t *testing.T) {
+	is := assert.New(t)
+
+	stack := &portainer.Stack{AutoUpdate: &portainer.AutoUpdateSettings{Webhook: "webhook", WebhookSecret: "secret"}, SecretEnv: "encrypted"}
+	hideStackSecrets(stack)
+	is.Empty(stack.AutoUpdate.WebhookSecret)
+	is.Empty(stack.SecretEnv)
+
+	current := &portainer.AutoUpdateSettings{Webhook: "webhook", WebhookSecret: "secret"}
+
+	updated := &portainer.AutoUpdateSettings{Webhook: "webhook"}
+	keepWebhookSecret(current, updated)
+	is.Equal("secret", updated.WebhookSecret, "the secret should be kept when none is provided")
+
+	updated = &portainer.AutoUpdateSettings{Webhook: "webhook", WebhookSecret: "new-secret"}
+	keepWebhookSecret(current, updated)
+	is.Equal("new-secret", updated.WebhookSecret)
+
+	updated = &portainer.AutoUpdateSettings{Webhook: "new-webhook"}
+	keepWebhookSecret(current, updated)
+	is.Empty(updated.WebhookSecret, "the secret should be removed with the webhook")
+}
+
 func newGuidString(t *testing.T) string {
 	uuid, err := uuid.NewV4()
 	assert.NoError(t, err)
@@ -100,7 +100,7 @@ func (handler *Handler) templateFile(w http.ResponseWriter, r *http.Request) *ht
 
 	defer handler.cleanUp(projectPath)
 
-	err = handler.GitService.CloneRepository(projectPath, payload.RepositoryURL, "", "", "", false)
+	err = handler.GitService.CloneRepository(projectPath, payload.RepositoryURL, "", nil, false, nil)
 	if err != nil {
 		return httperror.InternalServerError("Unable to clone git repository", err)
 	}
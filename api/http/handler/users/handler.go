@@ -27,6 +27,11 @@ func hideFields(user *portainer.User) {
 	user.Password = ""
 }
 
+func hideGitSSHKeyFields(key *portainer.GitSSHKey) {
+	key.PrivateKey = ""
+	key.Passphrase = ""
+}
+
 // Handler is the HTTP handler used to handle user operations.
 type Handler struct {
 	*mux.Router
@@ -72,6 +77,12 @@ func NewHandler(bouncer security.BouncerService, rateLimiter *security.RateLimit
 	restrictedRouter.Handle("/users/{id}/tokens", httperror.LoggerHandler(h.userGetAccessTokens)).Methods(http.MethodGet)
 	restrictedRouter.Handle("/users/{id}/tokens", rateLimiter.LimitAccess(httperror.LoggerHandler(h.userCreateAccessToken))).Methods(http.MethodPost)
 	restrictedRouter.Handle("/users/{id}/tokens/{keyID}", httperror.LoggerHandler(h.userRemoveAccessToken)).Methods(http.MethodDelete)
+	restrictedRouter.Handle("/users/{id}/kubeconfig_tokens", httperror.LoggerHandler(h.userGetKubeconfigTokens)).Methods(http.MethodGet)
+	restrictedRouter.Handle("/users/{id}/kubeconfig_tokens/{tokenID}", httperror.LoggerHandler(h.userRevokeKubeconfigToken)).Methods(http.MethodDelete)
+	restrictedRouter.Handle("/users/{id}/git_ssh_keys", httperror.LoggerHandler(h.userGetGitSSHKeys)).Methods(http.MethodGet)
+	restrictedRouter.Handle("/users/{id}/git_ssh_keys", httperror.LoggerHandler(h.userCreateGitSSHKey)).Methods(http.MethodPost)
+	restrictedRouter.Handle("/users/{id}/git_ssh_keys/{keyID}", httperror.LoggerHandler(h.userUpdateGitSSHKey)).Methods(http.MethodPut)
+	restrictedRouter.Handle("/users/{id}/git_ssh_keys/{keyID}", httperror.LoggerHandler(h.userDeleteGitSSHKey)).Methods(http.MethodDelete)
 	restrictedRouter.Handle("/users/{id}/memberships", httperror.LoggerHandler(h.userMemberships)).Methods(http.MethodGet)
 	authenticatedRouter.Handle("/users/{id}/passwd", rateLimiter.LimitAccess(httperror.LoggerHandler(h.userUpdatePassword))).Methods(http.MethodPut)
 	publicRouter.Handle("/users/admin/check", httperror.LoggerHandler(h.adminCheck)).Methods(http.MethodGet)
@@ -106,5 +106,37 @@ func (handler *Handler) deleteUser(w http.ResponseWriter, user *portainer.User)
 		}
 	}
 
+	// Revoke all of the users kubeconfig tokens
+	kubeconfigTokens, err := handler.DataStore.KubeconfigToken().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve kubeconfig tokens from the database", err)
+	}
+	for _, token := range kubeconfigTokens {
+		if token.UserID != user.ID {
+			continue
+		}
+
+		err = handler.DataStore.KubeconfigToken().Delete(token.ID)
+		if err != nil {
+			return httperror.InternalServerError("Unable to remove user kubeconfig token from the database", err)
+		}
+	}
+
+	// Remove all of the users git SSH keys
+	sshKeys, err := handler.DataStore.GitSSHKey().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve git SSH keys from the database", err)
+	}
+	for _, key := range sshKeys {
+		if key.UserID != user.ID {
+			continue
+		}
+
+		err = handler.DataStore.GitSSHKey().Delete(key.ID)
+		if err != nil {
+			return httperror.InternalServerError("Unable to remove user git SSH key from the database", err)
+		}
+	}
+
 	return response.Empty(w)
 }
@@ -0,0 +1,56 @@
+package users
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id UserDeleteGitSSHKey
+// @summary Remove a git SSH key of a user
+// @description Remove a git SSH key, the git repositories authenticated with the key cannot be pulled anymore.
+// @description Only the calling user or admin can remove git SSH keys.
+// @description **Access policy**: restricted
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "User identifier"
+// @param keyID path int true "Git SSH key identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 500 "Server error"
+// @router /users/{id}/git_ssh_keys/{keyID} [delete]
+func (handler *Handler) userDeleteGitSSHKey(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if tokenData.Role != portainer.AdministratorRole && tokenData.ID != portainer.UserID(userID) {
+		return httperror.Forbidden("Permission denied to remove user git SSH key", httperrors.ErrUnauthorized)
+	}
+
+	key, httpErr := handler.readUserGitSSHKey(r, portainer.UserID(userID))
+	if httpErr != nil {
+		return httpErr
+	}
+
+	err = handler.DataStore.GitSSHKey().Delete(key.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the git SSH key from the database", err)
+	}
+
+	return response.Empty(w)
+}
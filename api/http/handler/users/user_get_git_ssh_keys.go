@@ -0,0 +1,89 @@
+package users
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id UserGetGitSSHKeys
+// @summary Get all git SSH keys for a user
+// @description Gets the SSH keys used by a user to authenticate against git repositories, the private keys are never returned.
+// @description Only the calling user or admin can retrieve git SSH keys.
+// @description **Access policy**: restricted
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "User identifier"
+// @success 200 {array} portainer.GitSSHKey "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User not found"
+// @failure 500 "Server error"
+// @router /users/{id}/git_ssh_keys [get]
+func (handler *Handler) userGetGitSSHKeys(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if tokenData.Role != portainer.AdministratorRole && tokenData.ID != portainer.UserID(userID) {
+		return httperror.Forbidden("Permission denied to get user git SSH keys", httperrors.ErrUnauthorized)
+	}
+
+	_, err = handler.DataStore.User().Read(portainer.UserID(userID))
+	if err != nil {
+		if handler.DataStore.IsErrObjectNotFound(err) {
+			return httperror.NotFound("Unable to find a user with the specified identifier inside the database", err)
+		}
+		return httperror.InternalServerError("Unable to find a user with the specified identifier inside the database", err)
+	}
+
+	keys, err := handler.DataStore.GitSSHKey().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve git SSH keys from the database", err)
+	}
+
+	userKeys := []portainer.GitSSHKey{}
+	for _, key := range keys {
+		if key.UserID == portainer.UserID(userID) {
+			hideGitSSHKeyFields(&key)
+			userKeys = append(userKeys, key)
+		}
+	}
+
+	return response.JSON(w, userKeys)
+}
+
+// readUserGitSSHKey reads the git SSH key of the request route and makes sure that it belongs to the user of the route
+func (handler *Handler) readUserGitSSHKey(r *http.Request, userID portainer.UserID) (*portainer.GitSSHKey, *httperror.HandlerError) {
+	keyID, err := request.RetrieveNumericRouteVariableValue(r, "keyID")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid git SSH key identifier route variable", err)
+	}
+
+	key, err := handler.DataStore.GitSSHKey().Read(portainer.GitSSHKeyID(keyID))
+	if err != nil {
+		if handler.DataStore.IsErrObjectNotFound(err) {
+			return nil, httperror.NotFound("Unable to find a git SSH key with the specified identifier inside the database", err)
+		}
+		return nil, httperror.InternalServerError("Unable to find a git SSH key with the specified identifier inside the database", err)
+	}
+
+	if key.UserID != userID {
+		return nil, httperror.Forbidden("Permission denied to access git SSH key", httperrors.ErrUnauthorized)
+	}
+
+	return key, nil
+}
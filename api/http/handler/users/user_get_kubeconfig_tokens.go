@@ -0,0 +1,67 @@
+package users
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id UserGetKubeconfigTokens
+// @summary Get all kubeconfig tokens for a user
+// @description Gets the tokens of the kubeconfig files generated by a user.
+// @description Only the calling user or admin can retrieve kubeconfig tokens.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "User identifier"
+// @success 200 {array} portainer.KubeconfigToken "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User not found"
+// @failure 500 "Server error"
+// @router /users/{id}/kubeconfig_tokens [get]
+func (handler *Handler) userGetKubeconfigTokens(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if tokenData.Role != portainer.AdministratorRole && tokenData.ID != portainer.UserID(userID) {
+		return httperror.Forbidden("Permission denied to get user kubeconfig tokens", httperrors.ErrUnauthorized)
+	}
+
+	_, err = handler.DataStore.User().Read(portainer.UserID(userID))
+	if err != nil {
+		if handler.DataStore.IsErrObjectNotFound(err) {
+			return httperror.NotFound("Unable to find a user with the specified identifier inside the database", err)
+		}
+		return httperror.InternalServerError("Unable to find a user with the specified identifier inside the database", err)
+	}
+
+	tokens, err := handler.DataStore.KubeconfigToken().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve kubeconfig tokens from the database", err)
+	}
+
+	userTokens := []portainer.KubeconfigToken{}
+	for _, token := range tokens {
+		if token.UserID == portainer.UserID(userID) {
+			token.Digest = nil
+			userTokens = append(userTokens, token)
+		}
+	}
+
+	return response.JSON(w, userTokens)
+}
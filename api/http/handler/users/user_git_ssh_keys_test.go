@@ -0,0 +1,139 @@
+package users
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/apikey"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_userGitSSHKeys(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, true)
+
+	// create admin and standard user(s)
+	adminUser := &portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole}
+	err := store.User().Create(adminUser)
+	is.NoError(err, "error creating admin user")
+
+	user := &portainer.User{ID: 2, Username: "standard", Role: portainer.StandardUserRole}
+	err = store.User().Create(user)
+	is.NoError(err, "error creating user")
+
+	// setup services
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
+	passwordChecker := security.NewPasswordStrengthChecker(store.SettingsService)
+
+	h := NewHandler(requestBouncer, rateLimiter, apiKeyService, nil, passwordChecker)
+	h.DataStore = store
+
+	userJWT, _ := jwtService.GenerateToken(&portainer.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+	adminJWT, _ := jwtService.GenerateToken(&portainer.TokenData{ID: adminUser.ID, Username: adminUser.Username, Role: adminUser.Role})
+
+	var created portainer.GitSSHKey
+
+	t.Run("standard user can generate a git SSH key", func(t *testing.T) {
+		body, _ := json.Marshal(userGitSSHKeyCreatePayload{Name: "deploy-key"})
+		req := httptest.NewRequest(http.MethodPost, "/users/2/git_ssh_keys", bytes.NewReader(body))
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", userJWT))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusCreated, rr.Code)
+		is.NotContains(rr.Body.String(), "PrivateKey")
+
+		is.NoError(json.NewDecoder(rr.Body).Decode(&created))
+		is.Equal(user.ID, created.UserID)
+		is.Contains(created.PublicKey, "ssh-ed25519 ")
+		is.NotEmpty(created.Fingerprint)
+
+		stored, err := store.GitSSHKey().Read(created.ID)
+		is.NoError(err)
+		is.NotEmpty(stored.PrivateKey)
+	})
+
+	t.Run("admin cannot create a git SSH key for another user", func(t *testing.T) {
+		body, _ := json.Marshal(userGitSSHKeyCreatePayload{Name: "admin-key"})
+		req := httptest.NewRequest(http.MethodPost, "/users/2/git_ssh_keys", bytes.NewReader(body))
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", adminJWT))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("invalid known hosts are rejected", func(t *testing.T) {
+		body, _ := json.Marshal(userGitSSHKeyCreatePayload{Name: "deploy-key", KnownHosts: "github.com not-a-key"})
+		req := httptest.NewRequest(http.MethodPost, "/users/2/git_ssh_keys", bytes.NewReader(body))
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", userJWT))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("standard user can pin the known hosts of their key", func(t *testing.T) {
+		knownHosts := "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+		body, _ := json.Marshal(userGitSSHKeyUpdatePayload{KnownHosts: &knownHosts})
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/users/2/git_ssh_keys/%d", created.ID), bytes.NewReader(body))
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", userJWT))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusOK, rr.Code)
+
+		stored, err := store.GitSSHKey().Read(created.ID)
+		is.NoError(err)
+		is.Equal(knownHosts, stored.KnownHosts)
+		is.Equal("deploy-key", stored.Name)
+	})
+
+	t.Run("admin can list the git SSH keys of a user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/2/git_ssh_keys", nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", adminJWT))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusOK, rr.Code)
+
+		var resp []portainer.GitSSHKey
+		is.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+		if is.Len(resp, 1) {
+			is.Equal(created.ID, resp[0].ID)
+			is.Empty(resp[0].PrivateKey)
+		}
+	})
+
+	t.Run("standard user can remove their git SSH key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/users/2/git_ssh_keys/%d", created.ID), nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", userJWT))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusNoContent, rr.Code)
+
+		_, err := store.GitSSHKey().Read(created.ID)
+		is.True(store.IsErrObjectNotFound(err))
+	})
+}
@@ -0,0 +1,66 @@
+package users
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id UserRevokeKubeconfigToken
+// @summary Revoke a kubeconfig token of a user
+// @description Revoke a kubeconfig token, the kubeconfig file bound to the token cannot be used anymore.
+// @description Only the calling user or admin can revoke kubeconfig tokens.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "User identifier"
+// @param tokenID path int true "Kubeconfig token identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 500 "Server error"
+// @router /users/{id}/kubeconfig_tokens/{tokenID} [delete]
+func (handler *Handler) userRevokeKubeconfigToken(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	tokenID, err := request.RetrieveNumericRouteVariableValue(r, "tokenID")
+	if err != nil {
+		return httperror.BadRequest("Invalid kubeconfig token identifier route variable", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+	if tokenData.Role != portainer.AdministratorRole && tokenData.ID != portainer.UserID(userID) {
+		return httperror.Forbidden("Permission denied to revoke user kubeconfig tokens", httperrors.ErrUnauthorized)
+	}
+
+	token, err := handler.DataStore.KubeconfigToken().Read(portainer.KubeconfigTokenID(tokenID))
+	if err != nil {
+		if handler.DataStore.IsErrObjectNotFound(err) {
+			return httperror.NotFound("Unable to find a kubeconfig token with the specified identifier inside the database", err)
+		}
+		return httperror.InternalServerError("Unable to find a kubeconfig token with the specified identifier inside the database", err)
+	}
+	if token.UserID != portainer.UserID(userID) {
+		return httperror.Forbidden("Permission denied to revoke kubeconfig token", httperrors.ErrUnauthorized)
+	}
+
+	err = handler.DataStore.KubeconfigToken().Delete(token.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the kubeconfig token from the database", err)
+	}
+
+	return response.Empty(w)
+}
@@ -0,0 +1,94 @@
+package users
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/apikey"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_userRevokeKubeconfigToken(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, true)
+
+	// create admin and standard user(s)
+	adminUser := &portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole}
+	err := store.User().Create(adminUser)
+	is.NoError(err, "error creating admin user")
+
+	user := &portainer.User{ID: 2, Username: "standard", Role: portainer.StandardUserRole}
+	err = store.User().Create(user)
+	is.NoError(err, "error creating user")
+
+	// setup services
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
+	passwordChecker := security.NewPasswordStrengthChecker(store.SettingsService)
+
+	h := NewHandler(requestBouncer, rateLimiter, apiKeyService, nil, passwordChecker)
+	h.DataStore = store
+
+	jwt, _ := jwtService.GenerateToken(&portainer.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+
+	userToken := &portainer.KubeconfigToken{UserID: user.ID, Mode: portainer.KubeconfigTokenModeExec, Digest: []byte("digest")}
+	is.NoError(store.KubeconfigToken().Create(userToken))
+
+	adminToken := &portainer.KubeconfigToken{UserID: adminUser.ID, Mode: portainer.KubeconfigTokenModeBearer}
+	is.NoError(store.KubeconfigToken().Create(adminToken))
+
+	t.Run("standard user can list their kubeconfig tokens", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/2/kubeconfig_tokens", nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusOK, rr.Code)
+
+		var resp []portainer.KubeconfigToken
+		is.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+		if is.Len(resp, 1) {
+			is.Equal(userToken.ID, resp[0].ID)
+			is.Nil(resp[0].Digest)
+		}
+	})
+
+	t.Run("standard user cannot revoke the kubeconfig token of another user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/users/2/kubeconfig_tokens/%d", adminToken.ID), nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusForbidden, rr.Code)
+
+		_, err := store.KubeconfigToken().Read(adminToken.ID)
+		is.NoError(err)
+	})
+
+	t.Run("standard user can revoke their kubeconfig token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/users/2/kubeconfig_tokens/%d", userToken.ID), nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusNoContent, rr.Code)
+
+		_, err := store.KubeconfigToken().Read(userToken.ID)
+		is.True(store.IsErrObjectNotFound(err))
+	})
+}
@@ -0,0 +1,96 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/asaskevich/govalidator"
+)
+
+type userGitSSHKeyUpdatePayload struct {
+	// Name of the key
+	Name *string `example:"github-deploy-key"`
+	// Host keys of the git servers in the known_hosts format
+	KnownHosts *string `example:"github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"`
+}
+
+func (payload *userGitSSHKeyUpdatePayload) Validate(r *http.Request) error {
+	if payload.Name != nil && govalidator.IsNull(*payload.Name) {
+		return errors.New("invalid name. cannot be empty")
+	}
+	if payload.KnownHosts != nil && *payload.KnownHosts != "" {
+		return git.ValidateKnownHosts(*payload.KnownHosts)
+	}
+	return nil
+}
+
+// @id UserUpdateGitSSHKey
+// @summary Update a git SSH key of a user
+// @description Updates the name and the known hosts of a git SSH key, the key pair itself cannot be changed.
+// @description Only the calling user can update their keys.
+// @description **Access policy**: restricted
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "User identifier"
+// @param keyID path int true "Git SSH key identifier"
+// @param body body userGitSSHKeyUpdatePayload true "details"
+// @success 200 {object} portainer.GitSSHKey "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 500 "Server error"
+// @router /users/{id}/git_ssh_keys/{keyID} [put]
+func (handler *Handler) userUpdateGitSSHKey(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	var payload userGitSSHKeyUpdatePayload
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if tokenData.ID != portainer.UserID(userID) {
+		return httperror.Forbidden("Permission denied to update user git SSH key", httperrors.ErrUnauthorized)
+	}
+
+	key, httpErr := handler.readUserGitSSHKey(r, portainer.UserID(userID))
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if payload.Name != nil {
+		key.Name = *payload.Name
+	}
+
+	if payload.KnownHosts != nil {
+		key.KnownHosts = *payload.KnownHosts
+	}
+
+	err = handler.DataStore.GitSSHKey().Update(key.ID, key)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the git SSH key changes inside the database", err)
+	}
+
+	hideGitSSHKeyFields(key)
+
+	return response.JSON(w, key)
+}
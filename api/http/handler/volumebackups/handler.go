@@ -0,0 +1,157 @@
+package volumebackups
+
+import (
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/endpointutils"
+	"github.com/portainer/portainer/api/volumebackup"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// Handler is the HTTP handler used to handle volume backup operations.
+type Handler struct {
+	*mux.Router
+	DataStore           dataservices.DataStore
+	VolumeBackupService *volumebackup.Service
+}
+
+// NewHandler creates a handler to manage volume backup operations.
+func NewHandler(bouncer security.BouncerService) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+
+	h.Handle("/volume_backups",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.volumeBackupList))).Methods(http.MethodGet)
+	h.Handle("/volume_backups",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.volumeBackupCreate))).Methods(http.MethodPost)
+	h.Handle("/volume_backups/schedules",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.scheduleList))).Methods(http.MethodGet)
+	h.Handle("/volume_backups/schedules",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.scheduleCreate))).Methods(http.MethodPost)
+	h.Handle("/volume_backups/schedules/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.scheduleInspect))).Methods(http.MethodGet)
+	h.Handle("/volume_backups/schedules/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.scheduleUpdate))).Methods(http.MethodPut)
+	h.Handle("/volume_backups/schedules/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.scheduleDelete))).Methods(http.MethodDelete)
+	h.Handle("/volume_backups/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.volumeBackupInspect))).Methods(http.MethodGet)
+	h.Handle("/volume_backups/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.volumeBackupDelete))).Methods(http.MethodDelete)
+	h.Handle("/volume_backups/{id}/archive",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.volumeBackupArchive))).Methods(http.MethodGet)
+	h.Handle("/volume_backups/{id}/restore",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.volumeBackupRestore))).Methods(http.MethodPost)
+
+	return h
+}
+
+func (handler *Handler) retrieveVolumeBackup(r *http.Request) (*portainer.VolumeBackup, *httperror.HandlerError) {
+	backupID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid volume backup identifier route variable", err)
+	}
+
+	backup, err := handler.DataStore.VolumeBackup().Read(portainer.VolumeBackupID(backupID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find a volume backup with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find a volume backup with the specified identifier inside the database", err)
+	}
+
+	return backup, nil
+}
+
+func (handler *Handler) retrieveSchedule(r *http.Request) (*portainer.VolumeBackupSchedule, *httperror.HandlerError) {
+	scheduleID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid volume backup schedule identifier route variable", err)
+	}
+
+	schedule, err := handler.DataStore.VolumeBackupSchedule().Read(portainer.VolumeBackupScheduleID(scheduleID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find a volume backup schedule with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find a volume backup schedule with the specified identifier inside the database", err)
+	}
+
+	return schedule, nil
+}
+
+// retrieveEndpoint returns the environment hosting the volumes, only the Docker environments are supported
+func (handler *Handler) retrieveEndpoint(endpointID portainer.EndpointID) (*portainer.Endpoint, *httperror.HandlerError) {
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(endpointID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find an environment with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find an environment with the specified identifier inside the database", err)
+	}
+
+	if !endpointutils.IsDockerEndpoint(endpoint) {
+		return nil, httperror.BadRequest("Volume backups are only supported on Docker environments", errors.New("volume backups are only supported on Docker environments"))
+	}
+
+	return endpoint, nil
+}
+
+// validateVolumes makes sure that either volumes or a stack are provided
+func validateVolumes(volumeNames []string, stackName string) error {
+	if len(volumeNames) == 0 && stackName == "" {
+		return errors.New("invalid volumes. either volume names or a stack name must be provided")
+	}
+
+	if len(volumeNames) > 0 && stackName != "" {
+		return errors.New("invalid volumes. volume names and stack name cannot be used together")
+	}
+
+	for _, volumeName := range volumeNames {
+		if volumeName == "" {
+			return errors.New("invalid volume name. cannot be empty")
+		}
+	}
+
+	return nil
+}
+
+func validateSchedule(interval string, retention int) error {
+	d, err := time.ParseDuration(interval)
+	if err != nil || d < time.Minute {
+		return errors.New("invalid interval. must be a duration of at least one minute")
+	}
+
+	if retention < 0 {
+		return errors.New("invalid retention. cannot be negative")
+	}
+
+	return nil
+}
+
+// scheduleVolumeBackups (re)schedules the runs of a volume backup schedule and persists its job identifier
+func (handler *Handler) scheduleVolumeBackups(schedule *portainer.VolumeBackupSchedule) *httperror.HandlerError {
+	err := handler.VolumeBackupService.Schedule(schedule)
+	if err != nil {
+		return httperror.InternalServerError("Unable to schedule the volume backups", err)
+	}
+
+	err = handler.DataStore.VolumeBackupSchedule().Update(schedule.ID, schedule)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the volume backup schedule changes inside the database", err)
+	}
+
+	return nil
+}
+
+func hideSchedulePassword(schedule *portainer.VolumeBackupSchedule) *portainer.VolumeBackupSchedule {
+	schedule.Password = ""
+
+	return schedule
+}
@@ -0,0 +1,106 @@
+package volumebackups
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/pkg/errors"
+)
+
+type scheduleCreatePayload struct {
+	// Name of the schedule
+	Name string `validate:"required" example:"nightly"`
+	// Environment(Endpoint) identifier of the volumes
+	EndpointID portainer.EndpointID `validate:"required" example:"1"`
+	// Node hosting the volumes, only required on Swarm environments
+	NodeName string `example:"node-1"`
+	// Names of the volumes to back up
+	VolumeNames []string `example:"app_data"`
+	// Name of a compose or swarm stack whose volumes are backed up, the volumes are resolved on every run
+	StackName string `example:"app"`
+	// Interval between two backups, at least one minute
+	Interval string `validate:"required" example:"24h"`
+	// Number of backups kept per volume, every backup is kept when 0
+	Retention int `example:"7"`
+	// Password encrypting the archives, the archives are not encrypted when empty
+	Password string `example:"passwd"`
+}
+
+func (payload *scheduleCreatePayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.Name) {
+		return errors.New("invalid name. cannot be empty")
+	}
+
+	if payload.EndpointID == 0 {
+		return errors.New("invalid environment identifier. cannot be empty")
+	}
+
+	if err := validateVolumes(payload.VolumeNames, payload.StackName); err != nil {
+		return err
+	}
+
+	return validateSchedule(payload.Interval, payload.Retention)
+}
+
+// @id VolumeBackupScheduleCreate
+// @summary Create a volume backup schedule
+// @description Create a schedule backing up volumes of a Docker environment, or all the volumes of a stack, at a regular interval.
+// @description The oldest backups of each volume are removed beyond the retention.
+// @description **Access policy**: administrator
+// @tags volume_backups
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param body body scheduleCreatePayload true "Schedule details"
+// @success 201 {object} portainer.VolumeBackupSchedule "Created"
+// @failure 400 "Invalid request"
+// @failure 404 "Environment(Endpoint) not found"
+// @failure 500 "Server error"
+// @router /volume_backups/schedules [post]
+func (handler *Handler) scheduleCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload scheduleCreatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	if _, httpErr := handler.retrieveEndpoint(payload.EndpointID); httpErr != nil {
+		return httpErr
+	}
+
+	schedule := &portainer.VolumeBackupSchedule{
+		Name:        payload.Name,
+		EndpointID:  payload.EndpointID,
+		NodeName:    payload.NodeName,
+		VolumeNames: payload.VolumeNames,
+		StackName:   payload.StackName,
+		Interval:    payload.Interval,
+		Retention:   payload.Retention,
+	}
+
+	if err := handler.VolumeBackupService.SetSchedulePassword(schedule, payload.Password); err != nil {
+		return httperror.InternalServerError("Unable to store the password of the volume backup schedule", err)
+	}
+
+	if schedule.VolumeNames == nil {
+		schedule.VolumeNames = []string{}
+	}
+
+	err = handler.DataStore.VolumeBackupSchedule().Create(schedule)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the volume backup schedule inside the database", err)
+	}
+
+	if httpErr := handler.scheduleVolumeBackups(schedule); httpErr != nil {
+		return httpErr
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	return response.JSON(w, hideSchedulePassword(schedule))
+}
@@ -0,0 +1,37 @@
+package volumebackups
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id VolumeBackupScheduleDelete
+// @summary Remove a volume backup schedule
+// @description Remove a volume backup schedule, the backups it created are kept.
+// @description **Access policy**: administrator
+// @tags volume_backups
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Volume backup schedule identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Volume backup schedule not found"
+// @failure 500 "Server error"
+// @router /volume_backups/schedules/{id} [delete]
+func (handler *Handler) scheduleDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	schedule, httpErr := handler.retrieveSchedule(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	handler.VolumeBackupService.Unschedule(schedule)
+
+	err := handler.DataStore.VolumeBackupSchedule().Delete(schedule.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the volume backup schedule from the database", err)
+	}
+
+	return response.Empty(w)
+}
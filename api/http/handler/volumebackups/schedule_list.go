@@ -0,0 +1,55 @@
+package volumebackups
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id VolumeBackupScheduleList
+// @summary List the volume backup schedules
+// @description List the volume backup schedules and the result of their last run.
+// @description **Access policy**: administrator
+// @tags volume_backups
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {array} portainer.VolumeBackupSchedule "Success"
+// @failure 500 "Server error"
+// @router /volume_backups/schedules [get]
+func (handler *Handler) scheduleList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	schedules, err := handler.DataStore.VolumeBackupSchedule().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the volume backup schedules from the database", err)
+	}
+
+	for i := range schedules {
+		hideSchedulePassword(&schedules[i])
+	}
+
+	return response.JSON(w, schedules)
+}
+
+// @id VolumeBackupScheduleInspect
+// @summary Inspect a volume backup schedule
+// @description Retrieve the details of a volume backup schedule.
+// @description **Access policy**: administrator
+// @tags volume_backups
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Volume backup schedule identifier"
+// @success 200 {object} portainer.VolumeBackupSchedule "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Volume backup schedule not found"
+// @failure 500 "Server error"
+// @router /volume_backups/schedules/{id} [get]
+func (handler *Handler) scheduleInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	schedule, httpErr := handler.retrieveSchedule(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	return response.JSON(w, hideSchedulePassword(schedule))
+}
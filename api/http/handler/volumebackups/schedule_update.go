@@ -0,0 +1,118 @@
+package volumebackups
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/pkg/errors"
+)
+
+type scheduleUpdatePayload struct {
+	// Name of the schedule
+	Name *string `example:"nightly"`
+	// Node hosting the volumes, only required on Swarm environments
+	NodeName *string `example:"node-1"`
+	// Names of the volumes to back up, they replace the stack
+	VolumeNames []string `example:"app_data"`
+	// Name of a compose or swarm stack whose volumes are backed up, it replaces the volume names
+	StackName *string `example:"app"`
+	// Interval between two backups, at least one minute
+	Interval *string `example:"24h"`
+	// Number of backups kept per volume, every backup is kept when 0
+	Retention *int `example:"7"`
+	// Password encrypting the next archives, the next archives are not encrypted when empty
+	Password *string `example:"passwd"`
+}
+
+func (payload *scheduleUpdatePayload) Validate(r *http.Request) error {
+	if payload.Name != nil && govalidator.IsNull(*payload.Name) {
+		return errors.New("invalid name. cannot be empty")
+	}
+
+	if payload.VolumeNames != nil && payload.StackName != nil && *payload.StackName != "" {
+		return errors.New("invalid volumes. volume names and stack name cannot be used together")
+	}
+
+	return nil
+}
+
+// @id VolumeBackupScheduleUpdate
+// @summary Update a volume backup schedule
+// @description Update a volume backup schedule, the schedule is restarted with the new interval.
+// @description **Access policy**: administrator
+// @tags volume_backups
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Volume backup schedule identifier"
+// @param body body scheduleUpdatePayload true "Schedule details"
+// @success 200 {object} portainer.VolumeBackupSchedule "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Volume backup schedule not found"
+// @failure 500 "Server error"
+// @router /volume_backups/schedules/{id} [put]
+func (handler *Handler) scheduleUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	schedule, httpErr := handler.retrieveSchedule(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	var payload scheduleUpdatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	if payload.Name != nil {
+		schedule.Name = *payload.Name
+	}
+
+	if payload.NodeName != nil {
+		schedule.NodeName = *payload.NodeName
+	}
+
+	if payload.VolumeNames != nil {
+		schedule.VolumeNames = payload.VolumeNames
+		schedule.StackName = ""
+	}
+
+	if payload.StackName != nil {
+		schedule.StackName = *payload.StackName
+		if schedule.StackName != "" {
+			schedule.VolumeNames = []string{}
+		}
+	}
+
+	if payload.Interval != nil {
+		schedule.Interval = *payload.Interval
+	}
+
+	if payload.Retention != nil {
+		schedule.Retention = *payload.Retention
+	}
+
+	if payload.Password != nil {
+		if err := handler.VolumeBackupService.SetSchedulePassword(schedule, *payload.Password); err != nil {
+			return httperror.InternalServerError("Unable to store the password of the volume backup schedule", err)
+		}
+	}
+
+	if err := validateVolumes(schedule.VolumeNames, schedule.StackName); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	if err := validateSchedule(schedule.Interval, schedule.Retention); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	if httpErr := handler.scheduleVolumeBackups(schedule); httpErr != nil {
+		return httpErr
+	}
+
+	return response.JSON(w, hideSchedulePassword(schedule))
+}
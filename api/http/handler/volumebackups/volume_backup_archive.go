@@ -0,0 +1,46 @@
+package volumebackups
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/portainer/portainer/api/volumebackup"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+)
+
+// @id VolumeBackupArchive
+// @summary Download the archive of a volume backup
+// @description Download the tar.gz archive of a volume backup, encrypted archives are downloaded as they are stored.
+// @description **Access policy**: administrator
+// @tags volume_backups
+// @security ApiKeyAuth
+// @security jwt
+// @produce octet-stream
+// @param id path int true "Volume backup identifier"
+// @success 200 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Volume backup not found"
+// @failure 500 "Server error"
+// @router /volume_backups/{id}/archive [get]
+func (handler *Handler) volumeBackupArchive(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	backup, httpErr := handler.retrieveVolumeBackup(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	path := handler.VolumeBackupService.ArchivePath(backup)
+
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return httperror.NotFound("Unable to find the archive of the volume backup", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to read the archive of the volume backup", err)
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s_%s", backup.VolumeName, volumebackup.ArchiveName(backup)))
+	http.ServeFile(w, r, path)
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package volumebackups
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/pkg/errors"
+)
+
+type volumeBackupCreatePayload struct {
+	// Environment(Endpoint) identifier of the volumes
+	EndpointID portainer.EndpointID `validate:"required" example:"1"`
+	// Node hosting the volumes, only required on Swarm environments
+	NodeName string `example:"node-1"`
+	// Names of the volumes to back up
+	VolumeNames []string `example:"app_data"`
+	// Name of a compose or swarm stack whose volumes are backed up
+	StackName string `example:"app"`
+	// Password encrypting the archives, the archives are not encrypted when empty
+	Password string `example:"passwd"`
+}
+
+func (payload *volumeBackupCreatePayload) Validate(r *http.Request) error {
+	if payload.EndpointID == 0 {
+		return errors.New("invalid environment identifier. cannot be empty")
+	}
+
+	return validateVolumes(payload.VolumeNames, payload.StackName)
+}
+
+// @id VolumeBackupCreate
+// @summary Back up Docker volumes
+// @description Back up volumes of a Docker environment, or all the volumes of a stack, into compressed archives stored by Portainer.
+// @description The content of each volume is copied by a short-lived helper container. The archives are encrypted when a password is provided.
+// @description **Access policy**: administrator
+// @tags volume_backups
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param body body volumeBackupCreatePayload true "Volume backup details"
+// @success 201 {array} portainer.VolumeBackup "Created"
+// @failure 400 "Invalid request"
+// @failure 404 "Environment(Endpoint) not found"
+// @failure 500 "Server error"
+// @router /volume_backups [post]
+func (handler *Handler) volumeBackupCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload volumeBackupCreatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	endpoint, httpErr := handler.retrieveEndpoint(payload.EndpointID)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	volumeNames, err := handler.VolumeBackupService.Volumes(endpoint, payload.NodeName, payload.VolumeNames, payload.StackName)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the volumes of the stack", err)
+	}
+
+	backups := []portainer.VolumeBackup{}
+	for _, volumeName := range volumeNames {
+		backup, err := handler.VolumeBackupService.Create(endpoint, payload.NodeName, volumeName, 0, payload.Password)
+		if err != nil {
+			return httperror.InternalServerError("Unable to back up the volume", err)
+		}
+
+		backups = append(backups, *backup)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	return response.JSON(w, backups)
+}
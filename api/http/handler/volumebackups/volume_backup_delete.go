@@ -0,0 +1,35 @@
+package volumebackups
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id VolumeBackupDelete
+// @summary Remove a volume backup
+// @description Remove a volume backup and its archive.
+// @description **Access policy**: administrator
+// @tags volume_backups
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Volume backup identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Volume backup not found"
+// @failure 500 "Server error"
+// @router /volume_backups/{id} [delete]
+func (handler *Handler) volumeBackupDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	backup, httpErr := handler.retrieveVolumeBackup(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	err := handler.VolumeBackupService.Delete(backup)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the volume backup", err)
+	}
+
+	return response.Empty(w)
+}
@@ -0,0 +1,86 @@
+package volumebackups
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id VolumeBackupList
+// @summary List the volume backups
+// @description List the backups of the Docker volumes, optionally filtered by environment, volume or schedule.
+// @description **Access policy**: administrator
+// @tags volume_backups
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param endpointId query int false "Only return the backups of this environment(endpoint)"
+// @param volumeName query string false "Only return the backups of this volume"
+// @param scheduleId query int false "Only return the backups created by this schedule"
+// @success 200 {array} portainer.VolumeBackup "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /volume_backups [get]
+func (handler *Handler) volumeBackupList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpointID, err := request.RetrieveNumericQueryParameter(r, "endpointId", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: endpointId", err)
+	}
+
+	scheduleID, err := request.RetrieveNumericQueryParameter(r, "scheduleId", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: scheduleId", err)
+	}
+
+	volumeName, _ := request.RetrieveQueryParameter(r, "volumeName", true)
+
+	backups, err := handler.DataStore.VolumeBackup().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the volume backups from the database", err)
+	}
+
+	filtered := []portainer.VolumeBackup{}
+	for _, backup := range backups {
+		if endpointID != 0 && backup.EndpointID != portainer.EndpointID(endpointID) {
+			continue
+		}
+
+		if scheduleID != 0 && backup.ScheduleID != portainer.VolumeBackupScheduleID(scheduleID) {
+			continue
+		}
+
+		if volumeName != "" && backup.VolumeName != volumeName {
+			continue
+		}
+
+		filtered = append(filtered, backup)
+	}
+
+	return response.JSON(w, filtered)
+}
+
+// @id VolumeBackupInspect
+// @summary Inspect a volume backup
+// @description Retrieve the details of a volume backup.
+// @description **Access policy**: administrator
+// @tags volume_backups
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Volume backup identifier"
+// @success 200 {object} portainer.VolumeBackup "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Volume backup not found"
+// @failure 500 "Server error"
+// @router /volume_backups/{id} [get]
+func (handler *Handler) volumeBackupInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	backup, httpErr := handler.retrieveVolumeBackup(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	return response.JSON(w, backup)
+}
@@ -0,0 +1,82 @@
+package volumebackups
+
+import (
+	"errors"
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/volumebackup"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+type volumeBackupRestorePayload struct {
+	// Environment(Endpoint) identifier of the target volume, defaults to the environment of the backup
+	EndpointID portainer.EndpointID `example:"1"`
+	// Node hosting the target volume, defaults to the node of the backup when restoring on the same environment
+	NodeName string `example:"node-1"`
+	// Name of the target volume, defaults to the backed up volume. The volume is created when it does not exist
+	VolumeName string `example:"app_data_restored"`
+	// Password of an encrypted backup
+	Password string `example:"passwd"`
+}
+
+func (payload *volumeBackupRestorePayload) Validate(r *http.Request) error {
+	return nil
+}
+
+// @id VolumeBackupRestore
+// @summary Restore a volume backup
+// @description Restore the content of a volume backup into an existing or a new volume, an existing volume is emptied first
+// @description by the files of the archive.
+// @description **Access policy**: administrator
+// @tags volume_backups
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @param id path int true "Volume backup identifier"
+// @param body body volumeBackupRestorePayload true "Restore details"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Volume backup or environment(endpoint) not found"
+// @failure 500 "Server error"
+// @router /volume_backups/{id}/restore [post]
+func (handler *Handler) volumeBackupRestore(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	backup, httpErr := handler.retrieveVolumeBackup(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	var payload volumeBackupRestorePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	if payload.EndpointID == 0 {
+		payload.EndpointID = backup.EndpointID
+	}
+
+	if payload.NodeName == "" && payload.EndpointID == backup.EndpointID {
+		payload.NodeName = backup.NodeName
+	}
+
+	if payload.VolumeName == "" {
+		payload.VolumeName = backup.VolumeName
+	}
+
+	endpoint, httpErr := handler.retrieveEndpoint(payload.EndpointID)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	err = handler.VolumeBackupService.Restore(backup, endpoint, payload.NodeName, payload.VolumeName, payload.Password)
+	if errors.Is(err, volumebackup.ErrPasswordRequired) || errors.Is(err, volumebackup.ErrInvalidArchive) {
+		return httperror.BadRequest("Unable to restore the volume backup", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to restore the volume backup", err)
+	}
+
+	return response.Empty(w)
+}
@@ -0,0 +1,170 @@
+package volumebackups
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/apikey"
+	"github.com/portainer/portainer/api/archive"
+	"github.com/portainer/portainer/api/crypto"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/jwt"
+	"github.com/portainer/portainer/api/scheduler"
+	"github.com/portainer/portainer/api/volumebackup"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testArchiver struct {
+	volumes map[string][]byte
+}
+
+func (archiver *testArchiver) ExportVolume(endpoint *portainer.Endpoint, nodeName, volumeName string, output io.Writer) error {
+	_, err := output.Write(archiver.volumes[volumeName])
+	return err
+}
+
+func (archiver *testArchiver) ImportVolume(endpoint *portainer.Endpoint, nodeName, volumeName string, input io.Reader) error {
+	content, err := io.ReadAll(input)
+	archiver.volumes[volumeName] = content
+
+	return err
+}
+
+func (archiver *testArchiver) StackVolumes(endpoint *portainer.Endpoint, nodeName, stackName string) ([]string, error) {
+	return nil, nil
+}
+
+func Test_volumeBackups(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, true)
+
+	adminUser := &portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole}
+	is.NoError(store.User().Create(adminUser))
+
+	is.NoError(store.Endpoint().Create(&portainer.Endpoint{ID: 1, Name: "local", Type: portainer.DockerEnvironment}))
+	is.NoError(store.Endpoint().Create(&portainer.Endpoint{ID: 2, Name: "cluster", Type: portainer.KubernetesLocalEnvironment}))
+
+	fileService, err := filesystem.NewService(t.TempDir(), "")
+	is.NoError(err)
+
+	tarball, err := archive.TarFileInBuffer([]byte("content"), "volume/data", 0o600)
+	is.NoError(err)
+	archiver := &testArchiver{volumes: map[string][]byte{"app_data": tarball}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+
+	h := NewHandler(requestBouncer)
+	h.DataStore = store
+	secretService, err := crypto.NewSecretService(make([]byte, crypto.SecretKeySize))
+	is.NoError(err)
+
+	h.VolumeBackupService = volumebackup.NewService(store, fileService, archiver, scheduler.NewScheduler(ctx), secretService)
+
+	token, _ := jwtService.GenerateToken(&portainer.TokenData{ID: adminUser.ID, Username: adminUser.Username, Role: adminUser.Role})
+
+	doRequest := func(method, url string, payload any) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if payload != nil {
+			is.NoError(json.NewEncoder(&body).Encode(payload))
+		}
+
+		req := httptest.NewRequest(method, url, &body)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	rr := doRequest(http.MethodPost, "/volume_backups", map[string]any{"EndpointID": 2, "VolumeNames": []string{"app_data"}})
+	is.Equal(http.StatusBadRequest, rr.Code, "only the Docker environments are supported")
+
+	rr = doRequest(http.MethodPost, "/volume_backups", map[string]any{"EndpointID": 1})
+	is.Equal(http.StatusBadRequest, rr.Code, "the volumes are required")
+
+	rr = doRequest(http.MethodPost, "/volume_backups", map[string]any{"EndpointID": 1, "VolumeNames": []string{"app_data"}, "Password": "secret"})
+	is.Equal(http.StatusCreated, rr.Code)
+
+	var backups []portainer.VolumeBackup
+	is.NoError(json.NewDecoder(rr.Body).Decode(&backups))
+	is.Len(backups, 1)
+	is.True(backups[0].Encrypted)
+
+	rr = doRequest(http.MethodGet, "/volume_backups?volumeName=app_data", nil)
+	is.Equal(http.StatusOK, rr.Code)
+	is.NoError(json.NewDecoder(rr.Body).Decode(&backups))
+	is.Len(backups, 1)
+
+	rr = doRequest(http.MethodGet, fmt.Sprintf("/volume_backups/%d/archive", backups[0].ID), nil)
+	is.Equal(http.StatusOK, rr.Code)
+	is.Contains(rr.Header().Get("Content-Disposition"), "app_data_1.tar.gz.encrypted")
+
+	rr = doRequest(http.MethodPost, fmt.Sprintf("/volume_backups/%d/restore", backups[0].ID), map[string]any{"VolumeName": "restored", "Password": "wrong"})
+	is.Equal(http.StatusBadRequest, rr.Code)
+
+	rr = doRequest(http.MethodPost, fmt.Sprintf("/volume_backups/%d/restore", backups[0].ID), map[string]any{"VolumeName": "restored", "Password": "secret"})
+	is.Equal(http.StatusNoContent, rr.Code)
+	is.Equal(tarball, archiver.volumes["restored"])
+
+	rr = doRequest(http.MethodPost, "/volume_backups/schedules", map[string]any{
+		"Name":        "nightly",
+		"EndpointID":  1,
+		"VolumeNames": []string{"app_data"},
+		"Interval":    "24h",
+		"Retention":   3,
+		"Password":    "secret",
+	})
+	is.Equal(http.StatusCreated, rr.Code)
+
+	var schedule portainer.VolumeBackupSchedule
+	is.NoError(json.NewDecoder(rr.Body).Decode(&schedule))
+	is.Empty(schedule.Password, "the password is not returned")
+	is.NotEmpty(schedule.JobID)
+
+	stored, err := store.VolumeBackupSchedule().Read(schedule.ID)
+	is.NoError(err)
+	is.NotEmpty(stored.Password)
+	is.NotEqual("secret", stored.Password, "the password is stored encrypted")
+
+	password, err := secretService.Decrypt(stored.Password)
+	is.NoError(err)
+	is.Equal("secret", password)
+
+	rr = doRequest(http.MethodPut, fmt.Sprintf("/volume_backups/schedules/%d", schedule.ID), map[string]any{"Interval": "1s"})
+	is.Equal(http.StatusBadRequest, rr.Code, "the interval is at least one minute")
+
+	rr = doRequest(http.MethodPut, fmt.Sprintf("/volume_backups/schedules/%d", schedule.ID), map[string]any{"StackName": "app"})
+	is.Equal(http.StatusOK, rr.Code)
+	is.NoError(json.NewDecoder(rr.Body).Decode(&schedule))
+	is.Equal("app", schedule.StackName)
+	is.Empty(schedule.VolumeNames)
+
+	rr = doRequest(http.MethodDelete, fmt.Sprintf("/volume_backups/schedules/%d", schedule.ID), nil)
+	is.Equal(http.StatusNoContent, rr.Code)
+
+	rr = doRequest(http.MethodDelete, fmt.Sprintf("/volume_backups/%d", backups[0].ID), nil)
+	is.Equal(http.StatusNoContent, rr.Code)
+
+	rr = doRequest(http.MethodGet, "/volume_backups", nil)
+	is.Equal(http.StatusOK, rr.Code)
+	is.NoError(json.NewDecoder(rr.Body).Decode(&backups))
+	is.Empty(backups)
+}
@@ -0,0 +1,65 @@
+package vulnerabilities
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id VulnerabilityEndpointSummary
+// @summary Summarize the vulnerabilities of an environment
+// @description Retrieve the number of vulnerabilities per severity of the images of the running containers and of the stacks
+// @description of an environment, from the stored image scans.
+// @description **Access policy**: administrator
+// @tags vulnerabilities
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment(Endpoint) identifier"
+// @success 200 {object} vulnerability.EndpointSummary "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Environment not found"
+// @failure 500 "Server error"
+// @router /vulnerabilities/endpoints/{id} [get]
+func (handler *Handler) endpointSummary(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, httpErr := handler.retrieveEndpoint(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	summary, err := handler.VulnerabilityService.EndpointSummary(r.Context(), endpoint, false)
+	if err != nil {
+		return httperror.InternalServerError("Unable to summarize the vulnerabilities of the environment", err)
+	}
+
+	return response.JSON(w, summary)
+}
+
+// @id VulnerabilityEndpointScan
+// @summary Scan the images of an environment
+// @description Scan the images of the running containers and of the stacks of an environment, then summarize their vulnerabilities.
+// @description **Access policy**: administrator
+// @tags vulnerabilities
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment(Endpoint) identifier"
+// @success 200 {object} vulnerability.EndpointSummary "Success"
+// @failure 400 "Invalid request or vulnerability scanning disabled"
+// @failure 404 "Environment not found"
+// @failure 500 "Server error"
+// @router /vulnerabilities/endpoints/{id}/scan [post]
+func (handler *Handler) endpointScan(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, httpErr := handler.retrieveEndpoint(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	summary, err := handler.VulnerabilityService.EndpointSummary(r.Context(), endpoint, true)
+	if err != nil {
+		return scanError("Unable to scan the images of the environment", err)
+	}
+
+	return response.JSON(w, summary)
+}
@@ -0,0 +1,66 @@
+package vulnerabilities
+
+import (
+	"errors"
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/vulnerability"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+
+	"github.com/gorilla/mux"
+)
+
+// Handler is the HTTP handler used to handle vulnerability scanning operations.
+type Handler struct {
+	*mux.Router
+	DataStore            dataservices.DataStore
+	VulnerabilityService *vulnerability.Service
+}
+
+// NewHandler creates a handler to manage vulnerability scanning operations.
+func NewHandler(bouncer security.BouncerService) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+
+	h.Handle("/vulnerabilities/scans",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.imageScanList))).Methods(http.MethodGet)
+	h.Handle("/vulnerabilities/scans",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.imageScanCreate))).Methods(http.MethodPost)
+	h.Handle("/vulnerabilities/scans/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.imageScanInspect))).Methods(http.MethodGet)
+	h.Handle("/vulnerabilities/endpoints/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointSummary))).Methods(http.MethodGet)
+	h.Handle("/vulnerabilities/endpoints/{id}/scan",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointScan))).Methods(http.MethodPost)
+
+	return h
+}
+
+func (handler *Handler) retrieveEndpoint(r *http.Request) (*portainer.Endpoint, *httperror.HandlerError) {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid environment identifier route variable", err)
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find an environment with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find an environment with the specified identifier inside the database", err)
+	}
+
+	return endpoint, nil
+}
+
+func scanError(message string, err error) *httperror.HandlerError {
+	if errors.Is(err, vulnerability.ErrScanningDisabled) {
+		return httperror.BadRequest("Vulnerability scanning is disabled in the settings", err)
+	}
+
+	return httperror.InternalServerError(message, err)
+}
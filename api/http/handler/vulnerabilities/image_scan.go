@@ -0,0 +1,107 @@
+package vulnerabilities
+
+import (
+	"errors"
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+type imageScanCreatePayload struct {
+	// Reference of the image to scan
+	Image string `validate:"required" example:"nginx:1.25"`
+}
+
+func (payload *imageScanCreatePayload) Validate(r *http.Request) error {
+	if payload.Image == "" {
+		return errors.New("Invalid image reference")
+	}
+
+	return nil
+}
+
+// @id ImageScanCreate
+// @summary Scan an image
+// @description Scan an image for known vulnerabilities, the result is stored under the digest of the image.
+// @description **Access policy**: administrator
+// @tags vulnerabilities
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param body body imageScanCreatePayload true "Image to scan"
+// @success 200 {object} portainer.ImageScan "Success"
+// @failure 400 "Invalid request or vulnerability scanning disabled"
+// @failure 500 "Server error"
+// @router /vulnerabilities/scans [post]
+func (handler *Handler) imageScanCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload imageScanCreatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	imageScan, err := handler.VulnerabilityService.ScanImage(r.Context(), payload.Image)
+	if err != nil {
+		return scanError("Unable to scan the image", err)
+	}
+
+	return response.JSON(w, imageScan)
+}
+
+// @id ImageScanList
+// @summary List image scans
+// @description List the stored image scans, the vulnerabilities of a scan are only returned when it is inspected.
+// @description **Access policy**: administrator
+// @tags vulnerabilities
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {array} portainer.ImageScan "Success"
+// @failure 500 "Server error"
+// @router /vulnerabilities/scans [get]
+func (handler *Handler) imageScanList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	imageScans, err := handler.DataStore.ImageScan().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve image scans from the database", err)
+	}
+
+	for i := range imageScans {
+		imageScans[i].Vulnerabilities = nil
+	}
+
+	return response.JSON(w, imageScans)
+}
+
+// @id ImageScanInspect
+// @summary Inspect an image scan
+// @description Retrieve the vulnerabilities found in an image.
+// @description **Access policy**: administrator
+// @tags vulnerabilities
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Image scan identifier"
+// @success 200 {object} portainer.ImageScan "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Image scan not found"
+// @failure 500 "Server error"
+// @router /vulnerabilities/scans/{id} [get]
+func (handler *Handler) imageScanInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	imageScanID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid image scan identifier route variable", err)
+	}
+
+	imageScan, err := handler.DataStore.ImageScan().Read(portainer.ImageScanID(imageScanID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find an image scan with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find an image scan with the specified identifier inside the database", err)
+	}
+
+	return response.JSON(w, imageScan)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sessionrecording"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 
@@ -59,10 +60,20 @@ func (handler *Handler) websocketAttach(w http.ResponseWriter, r *http.Request)
 		return httperror.Forbidden("Permission denied to access environment", err)
 	}
 
+	recorder, err := handler.startSessionRecording(r, endpoint, &portainer.SessionRecording{
+		Type:        portainer.SessionRecordingAttach,
+		ContainerID: attachID,
+	})
+	if err != nil {
+		return httperror.InternalServerError("Unable to start the session recording", err)
+	}
+	defer handler.stopSessionRecording(recorder)
+
 	params := &webSocketRequestParams{
 		endpoint: endpoint,
 		ID:       attachID,
 		nodeName: r.FormValue("nodeName"),
+		recorder: recorder,
 	}
 
 	err = handler.handleAttachRequest(w, r, params)
@@ -89,10 +100,10 @@ func (handler *Handler) handleAttachRequest(w http.ResponseWriter, r *http.Reque
 	}
 	defer websocketConn.Close()
 
-	return hijackAttachStartOperation(websocketConn, params.endpoint, params.ID)
+	return hijackAttachStartOperation(websocketConn, params.endpoint, params.ID, params.recorder)
 }
 
-func hijackAttachStartOperation(websocketConn *websocket.Conn, endpoint *portainer.Endpoint, attachID string) error {
+func hijackAttachStartOperation(websocketConn *websocket.Conn, endpoint *portainer.Endpoint, attachID string, recorder *sessionrecording.Recorder) error {
 	dial, err := initDial(endpoint)
 	if err != nil {
 		return err
@@ -116,7 +127,7 @@ func hijackAttachStartOperation(websocketConn *websocket.Conn, endpoint *portain
 		return err
 	}
 
-	return hijackRequest(websocketConn, httpConn, attachStartRequest)
+	return hijackRequest(websocketConn, httpConn, attachStartRequest, recorder)
 }
 
 func createAttachStartRequest(attachID string) (*http.Request, error) {
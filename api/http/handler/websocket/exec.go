@@ -9,6 +9,7 @@ import (
 	"time"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sessionrecording"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 
@@ -65,10 +66,20 @@ func (handler *Handler) websocketExec(w http.ResponseWriter, r *http.Request) *h
 		return httperror.Forbidden("Permission denied to access environment", err)
 	}
 
+	recorder, err := handler.startSessionRecording(r, endpoint, &portainer.SessionRecording{
+		Type:   portainer.SessionRecordingExec,
+		ExecID: execID,
+	})
+	if err != nil {
+		return httperror.InternalServerError("Unable to start the session recording", err)
+	}
+	defer handler.stopSessionRecording(recorder)
+
 	params := &webSocketRequestParams{
 		endpoint: endpoint,
 		ID:       execID,
 		nodeName: r.FormValue("nodeName"),
+		recorder: recorder,
 	}
 
 	err = handler.handleExecRequest(w, r, params)
@@ -94,10 +105,10 @@ func (handler *Handler) handleExecRequest(w http.ResponseWriter, r *http.Request
 	}
 	defer websocketConn.Close()
 
-	return hijackExecStartOperation(websocketConn, params.endpoint, params.ID)
+	return hijackExecStartOperation(websocketConn, params.endpoint, params.ID, params.recorder)
 }
 
-func hijackExecStartOperation(websocketConn *websocket.Conn, endpoint *portainer.Endpoint, execID string) error {
+func hijackExecStartOperation(websocketConn *websocket.Conn, endpoint *portainer.Endpoint, execID string, recorder *sessionrecording.Recorder) error {
 	dial, err := initDial(endpoint)
 	if err != nil {
 		return err
@@ -121,7 +132,7 @@ func hijackExecStartOperation(websocketConn *websocket.Conn, endpoint *portainer
 		return err
 	}
 
-	return hijackRequest(websocketConn, httpConn, execStartRequest)
+	return hijackRequest(websocketConn, httpConn, execStartRequest, recorder)
 }
 
 func createExecStartRequest(execID string) (*http.Request, error) {
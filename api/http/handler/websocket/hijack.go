@@ -3,13 +3,16 @@ package websocket
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httputil"
 
+	"github.com/portainer/portainer/api/sessionrecording"
+
 	"github.com/gorilla/websocket"
 )
 
-func hijackRequest(websocketConn *websocket.Conn, httpConn *httputil.ClientConn, request *http.Request) error {
+func hijackRequest(websocketConn *websocket.Conn, httpConn *httputil.ClientConn, request *http.Request, recorder *sessionrecording.Recorder) error {
 	// Server hijacks the connection, error 'connection closed' expected
 	resp, err := httpConn.Do(request)
 	if !errors.Is(err, httputil.ErrPersistEOF) {
@@ -25,9 +28,16 @@ func hijackRequest(websocketConn *websocket.Conn, httpConn *httputil.ClientConn,
 	tcpConn, brw := httpConn.Hijack()
 	defer tcpConn.Close()
 
+	var reader io.Reader = brw
+	var writer io.Writer = tcpConn
+	if recorder != nil {
+		reader = io.TeeReader(brw, recorder.Output())
+		writer = io.MultiWriter(tcpConn, recorder.Input())
+	}
+
 	errorChan := make(chan error, 1)
-	go streamFromReaderToWebsocket(websocketConn, brw, errorChan)
-	go streamFromWebsocketToWriter(websocketConn, tcpConn, errorChan)
+	go streamFromReaderToWebsocket(websocketConn, reader, errorChan)
+	go streamFromWebsocketToWriter(websocketConn, writer, errorChan)
 
 	err = <-errorChan
 	if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
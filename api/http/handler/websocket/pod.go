@@ -9,6 +9,7 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/http/proxy/factory/kubernetes"
 	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/sessionrecording"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 
@@ -79,9 +80,22 @@ func (handler *Handler) websocketPodExec(w http.ResponseWriter, r *http.Request)
 		return httperror.InternalServerError("Unable to get user service account token", err)
 	}
 
+	recorder, err := handler.startSessionRecording(r, endpoint, &portainer.SessionRecording{
+		Type:          portainer.SessionRecordingPod,
+		Namespace:     namespace,
+		PodName:       podName,
+		ContainerName: containerName,
+		Command:       command,
+	})
+	if err != nil {
+		return httperror.InternalServerError("Unable to start the session recording", err)
+	}
+	defer handler.stopSessionRecording(recorder)
+
 	params := &webSocketRequestParams{
 		endpoint: endpoint,
 		token:    serviceAccountToken,
+		recorder: recorder,
 	}
 
 	r.Header.Del("Origin")
@@ -107,7 +121,7 @@ func (handler *Handler) websocketPodExec(w http.ResponseWriter, r *http.Request)
 		return httperror.InternalServerError("Unable to create Kubernetes client", err)
 	}
 
-	handlerErr := handler.hijackPodExecStartOperation(w, r, cli, serviceAccountToken, isAdminToken, endpoint, namespace, podName, containerName, command)
+	handlerErr := handler.hijackPodExecStartOperation(w, r, cli, serviceAccountToken, isAdminToken, endpoint, namespace, podName, containerName, command, recorder)
 	if handlerErr != nil {
 		return handlerErr
 	}
@@ -123,6 +137,7 @@ func (handler *Handler) hijackPodExecStartOperation(
 	isAdminToken bool,
 	endpoint *portainer.Endpoint,
 	namespace, podName, containerName, command string,
+	recorder *sessionrecording.Recorder,
 ) *httperror.HandlerError {
 	commandArray := strings.Split(command, " ")
 
@@ -138,9 +153,16 @@ func (handler *Handler) hijackPodExecStartOperation(
 	defer stdoutWriter.Close()
 
 	// errorChan is used to propagate errors from the go routines to the caller.
+	var stdin io.Writer = stdinWriter
+	var stdout io.Reader = stdoutReader
+	if recorder != nil {
+		stdin = io.MultiWriter(stdinWriter, recorder.Input())
+		stdout = io.TeeReader(stdoutReader, recorder.Output())
+	}
+
 	errorChan := make(chan error, 1)
-	go streamFromWebsocketToWriter(websocketConn, stdinWriter, errorChan)
-	go streamFromReaderToWebsocket(websocketConn, stdoutReader, errorChan)
+	go streamFromWebsocketToWriter(websocketConn, stdin, errorChan)
+	go streamFromReaderToWebsocket(websocketConn, stdout, errorChan)
 
 	// StartExecProcess is a blocking operation which streams IO to/from pod;
 	// this must execute in asynchronously, since the websocketConn could return errors (e.g. client disconnects) before
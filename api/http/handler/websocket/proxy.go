@@ -42,9 +42,7 @@ func (handler *Handler) proxyEdgeAgentWebsocketRequest(w http.ResponseWriter, r
 
 	handler.ReverseTunnelService.KeepTunnelAlive(params.endpoint.ID, r.Context(), portainer.WebSocketKeepAlive)
 
-	proxy.ServeHTTP(w, r)
-
-	return nil
+	return handler.serveWebsocketProxy(w, r, proxy, params.recorder)
 }
 
 func (handler *Handler) proxyAgentWebsocketRequest(w http.ResponseWriter, r *http.Request, params *webSocketRequestParams) error {
@@ -84,7 +82,5 @@ func (handler *Handler) proxyAgentWebsocketRequest(w http.ResponseWriter, r *htt
 		out.Set(portainer.PortainerAgentKubernetesSATokenHeader, params.token)
 	}
 
-	proxy.ServeHTTP(w, r)
-
-	return nil
+	return handler.serveWebsocketProxy(w, r, proxy, params.recorder)
 }
@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/sessionrecording"
+
+	"github.com/gorilla/websocket"
+	"github.com/koding/websocketproxy"
+	"github.com/rs/zerolog/log"
+)
+
+// startSessionRecording starts the recording of a terminal session when the environment group of the environment
+// records sessions. A nil recorder is returned when the session is not recorded.
+func (handler *Handler) startSessionRecording(r *http.Request, endpoint *portainer.Endpoint, recording *portainer.SessionRecording) (*sessionrecording.Recorder, error) {
+	if handler.SessionRecordingService == nil {
+		return nil, nil
+	}
+
+	enabled, err := handler.SessionRecordingService.IsEnabled(endpoint)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return nil, err
+	}
+
+	recording.UserID = tokenData.ID
+	recording.Username = tokenData.Username
+	recording.EndpointID = endpoint.ID
+
+	title := fmt.Sprintf("%s session of %s on %s", recording.Type, tokenData.Username, endpoint.Name)
+
+	return handler.SessionRecordingService.Start(recording, title)
+}
+
+func (handler *Handler) stopSessionRecording(recorder *sessionrecording.Recorder) {
+	if recorder == nil {
+		return
+	}
+
+	if err := recorder.Close(); err != nil {
+		log.Warn().Err(err).Msg("unable to close the session recording")
+	}
+}
+
+// serveWebsocketProxy proxies the websocket connection to the agent. When the session is recorded, the messages
+// are replicated by the handler instead of the proxy so that they can be recorded.
+func (handler *Handler) serveWebsocketProxy(w http.ResponseWriter, r *http.Request, proxy *websocketproxy.WebsocketProxy, recorder *sessionrecording.Recorder) error {
+	if recorder == nil {
+		proxy.ServeHTTP(w, r)
+
+		return nil
+	}
+
+	dialer := proxy.Dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+
+	requestHeader := http.Header{}
+	proxy.Director(r, requestHeader)
+
+	backendConn, resp, err := dialer.Dial(proxy.Backend(r).String(), requestHeader)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	defer backendConn.Close()
+
+	websocketConn, err := handler.connectionUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+	defer websocketConn.Close()
+
+	errorChan := make(chan error, 2)
+	go replicateWebsocketMessages(backendConn, websocketConn, recorder.Input(), errorChan)
+	go replicateWebsocketMessages(websocketConn, backendConn, recorder.Output(), errorChan)
+
+	err = <-errorChan
+	if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+		return err
+	}
+
+	return nil
+}
+
+func replicateWebsocketMessages(dst, src *websocket.Conn, recording io.Writer, errorChan chan error) {
+	for {
+		messageType, message, err := src.ReadMessage()
+		if err != nil {
+			dst.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			errorChan <- err
+
+			break
+		}
+
+		if messageType == websocket.TextMessage || messageType == websocket.BinaryMessage {
+			recording.Write(message)
+		}
+
+		err = dst.WriteMessage(messageType, message)
+		if err != nil {
+			errorChan <- err
+
+			break
+		}
+	}
+}
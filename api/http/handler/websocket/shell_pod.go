@@ -78,8 +78,21 @@ func (handler *Handler) websocketShellPodExec(w http.ResponseWriter, r *http.Req
 	/*
 		Note: The following websocket proxying logic is duplicated from `api/http/handler/websocket/pod.go`
 	*/
+	recorder, err := handler.startSessionRecording(r, endpoint, &portainer.SessionRecording{
+		Type:          portainer.SessionRecordingKubernetesShell,
+		Namespace:     shellPod.Namespace,
+		PodName:       shellPod.PodName,
+		ContainerName: shellPod.ContainerName,
+		Command:       shellPod.ShellExecCommand,
+	})
+	if err != nil {
+		return httperror.InternalServerError("Unable to start the session recording", err)
+	}
+	defer handler.stopSessionRecording(recorder)
+
 	params := &webSocketRequestParams{
 		endpoint: endpoint,
+		recorder: recorder,
 	}
 
 	r.Header.Del("Origin")
@@ -109,6 +122,7 @@ func (handler *Handler) websocketShellPodExec(w http.ResponseWriter, r *http.Req
 		shellPod.PodName,
 		shellPod.ContainerName,
 		shellPod.ShellExecCommand,
+		recorder,
 	)
 	if handlerErr != nil {
 		return handlerErr
@@ -1,10 +1,14 @@
 package websocket
 
-import portainer "github.com/portainer/portainer/api"
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sessionrecording"
+)
 
 type webSocketRequestParams struct {
 	ID       string
 	nodeName string
 	endpoint *portainer.Endpoint
 	token    string
+	recorder *sessionrecording.Recorder
 }
@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+type (
+	K8sCustomResourceDefinition struct {
+		// Name of the definition, <plural>.<group>
+		Name    string `json:"Name" example:"certificates.cert-manager.io"`
+		Group   string `json:"Group" example:"cert-manager.io"`
+		Version string `json:"Version" example:"v1"`
+		Kind    string `json:"Kind" example:"Certificate"`
+		Plural  string `json:"Plural" example:"certificates"`
+		// Namespaced is false for the cluster scoped resources
+		Namespaced   bool      `json:"Namespaced" example:"true"`
+		CreationDate time.Time `json:"CreationDate"`
+	}
+
+	K8sCustomResource struct {
+		Name         string            `json:"Name"`
+		Namespace    string            `json:"Namespace,omitempty"`
+		UID          string            `json:"UID"`
+		APIVersion   string            `json:"APIVersion" example:"cert-manager.io/v1"`
+		Kind         string            `json:"Kind" example:"Certificate"`
+		Labels       map[string]string `json:"Labels,omitempty"`
+		Conditions   []K8sCondition    `json:"Conditions"`
+		CreationDate time.Time         `json:"CreationDate"`
+	}
+
+	K8sCustomResourceDetails struct {
+		K8sCustomResource
+		// YAML is the manifest of the resource, without the managed fields
+		YAML string `json:"YAML"`
+	}
+
+	// K8sCondition is a status condition of a resource, most operators follow the conventions of the core types
+	K8sCondition struct {
+		Type               string `json:"Type" example:"Ready"`
+		Status             string `json:"Status" example:"True"`
+		Reason             string `json:"Reason,omitempty"`
+		Message            string `json:"Message,omitempty"`
+		LastTransitionTime string `json:"LastTransitionTime,omitempty"`
+	}
+
+	K8sCustomResourceUpdatePayload struct {
+		// YAML is the new manifest of the resource, its kind, name and namespace cannot be changed
+		YAML string `json:"YAML"`
+	}
+)
+
+func (r *K8sCustomResourceUpdatePayload) Validate(request *http.Request) error {
+	if r.YAML == "" {
+		return errors.New("missing YAML manifest")
+	}
+
+	return nil
+}
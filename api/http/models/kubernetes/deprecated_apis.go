@@ -0,0 +1,48 @@
+package kubernetes
+
+type (
+	// K8sDeprecatedAPIFinding is a resource using an API which is deprecated or removed in the target version
+	K8sDeprecatedAPIFinding struct {
+		Kind       string `json:"Kind" example:"Ingress"`
+		Name       string `json:"Name"`
+		Namespace  string `json:"Namespace,omitempty"`
+		APIVersion string `json:"APIVersion" example:"networking.k8s.io/v1beta1"`
+		// Replacement is the API version to migrate to, empty when the API has no replacement
+		Replacement  string `json:"Replacement,omitempty" example:"networking.k8s.io/v1"`
+		DeprecatedIn string `json:"DeprecatedIn" example:"1.19"`
+		RemovedIn    string `json:"RemovedIn,omitempty" example:"1.22"`
+		// Status in the target version, deprecated or removed
+		Status string `json:"Status" example:"removed"`
+		// Source of the finding, a live object of the cluster (live) or the manifest of a stack (manifest)
+		Source    string `json:"Source" example:"live"`
+		StackID   int    `json:"StackId,omitempty"`
+		StackName string `json:"StackName,omitempty"`
+		// File of the stack declaring the resource, only for the manifest findings
+		File string `json:"File,omitempty"`
+	}
+
+	K8sDeprecatedAPINamespaceReport struct {
+		// Namespace is empty for the cluster scoped resources
+		Namespace string                    `json:"Namespace"`
+		Findings  []K8sDeprecatedAPIFinding `json:"Findings"`
+	}
+
+	K8sDeprecatedAPIStackReport struct {
+		StackID   int                       `json:"StackId"`
+		StackName string                    `json:"StackName"`
+		Findings  []K8sDeprecatedAPIFinding `json:"Findings"`
+	}
+
+	K8sDeprecatedAPIReport struct {
+		KubernetesVersion string `json:"KubernetesVersion" example:"v1.27.4"`
+		// TargetVersion is the version the APIs are checked against
+		TargetVersion string                            `json:"TargetVersion" example:"1.29"`
+		Namespaces    []K8sDeprecatedAPINamespaceReport `json:"Namespaces"`
+		Stacks        []K8sDeprecatedAPIStackReport     `json:"Stacks"`
+	}
+)
+
+const (
+	K8sDeprecatedAPISourceLive     = "live"
+	K8sDeprecatedAPISourceManifest = "manifest"
+)
@@ -0,0 +1,46 @@
+package kubernetes
+
+import "time"
+
+type (
+	// K8sEvent is a Kubernetes event related to an object of a namespace
+	K8sEvent struct {
+		Namespace string `json:"Namespace"`
+		Type      string `json:"Type" example:"Warning"`
+		Reason    string `json:"Reason" example:"BackOff"`
+		Message   string `json:"Message"`
+		// Kind and Name of the object involved in the event
+		Kind           string    `json:"Kind" example:"Pod"`
+		Name           string    `json:"Name"`
+		Source         string    `json:"Source"`
+		Count          int32     `json:"Count"`
+		FirstTimestamp time.Time `json:"FirstTimestamp"`
+		LastTimestamp  time.Time `json:"LastTimestamp"`
+	}
+
+	// K8sLogLine is a line of the logs of a container
+	K8sLogLine struct {
+		Timestamp time.Time `json:"Timestamp"`
+		Namespace string    `json:"Namespace"`
+		Pod       string    `json:"Pod"`
+		Container string    `json:"Container"`
+		Line      string    `json:"Line"`
+	}
+
+	// K8sObservabilityFilter selects the events and the logs of an application or of namespaces
+	K8sObservabilityFilter struct {
+		// Namespaces to look into, all the namespaces are used when empty
+		Namespaces []string
+		// Application is the name of a deployment, its pods and replicasets are selected. It requires a single namespace.
+		Application string
+		// LabelSelector restricts the pods to the ones matching the selector
+		LabelSelector string
+		// Since and Until define the time range of the events and of the log lines, they are not limited when zero
+		Since time.Time
+		Until time.Time
+		// TailLines is the number of lines returned from the end of the logs of each container, all the lines are returned when zero
+		TailLines int64
+		// Follow keeps streaming the new log lines
+		Follow bool
+	}
+)
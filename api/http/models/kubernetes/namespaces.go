@@ -1,12 +1,142 @@
 package kubernetes
 
-import "net/http"
+import (
+	"errors"
+	"fmt"
+	"net/http"
 
-type K8sNamespaceDetails struct {
-	Name        string            `json:"Name"`
-	Annotations map[string]string `json:"Annotations"`
-}
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type (
+	K8sNamespaceDetails struct {
+		Name        string            `json:"Name"`
+		Annotations map[string]string `json:"Annotations"`
+		// ResourceQuota is the hard budget of the namespace, the current quota is kept when it is not set
+		// and removed when it sets no limit
+		ResourceQuota *K8sNamespaceResourceQuota `json:"ResourceQuota,omitempty"`
+		// LimitRange holds the default resources of the containers created in the namespace, the current limit range
+		// is kept when it is not set and removed when it sets no default
+		LimitRange *K8sNamespaceLimitRange `json:"LimitRange,omitempty"`
+	}
+
+	// K8sNamespaceResourceQuota describes the ResourceQuota managed by Portainer for a namespace.
+	// Quantities use the Kubernetes format (e.g. 500m, 2Gi), empty quantities and zero counts are not limited.
+	K8sNamespaceResourceQuota struct {
+		// CPU is applied to both the requests and the limits of the namespace
+		CPU string `json:"CPU" example:"2"`
+		// Memory is applied to both the requests and the limits of the namespace
+		Memory                 string `json:"Memory" example:"4Gi"`
+		Storage                string `json:"Storage" example:"20Gi"`
+		Pods                   int64  `json:"Pods" example:"20"`
+		Services               int64  `json:"Services"`
+		ConfigMaps             int64  `json:"ConfigMaps"`
+		Secrets                int64  `json:"Secrets"`
+		PersistentVolumeClaims int64  `json:"PersistentVolumeClaims"`
+	}
+
+	// K8sNamespaceLimitRange describes the default container resources managed by Portainer for a namespace
+	K8sNamespaceLimitRange struct {
+		DefaultCPU           string `json:"DefaultCPU" example:"500m"`
+		DefaultMemory        string `json:"DefaultMemory" example:"512Mi"`
+		DefaultRequestCPU    string `json:"DefaultRequestCPU" example:"100m"`
+		DefaultRequestMemory string `json:"DefaultRequestMemory" example:"128Mi"`
+	}
+
+	// K8sResourceQuotaUsage reports the current usage of a ResourceQuota of a namespace compared with its hard limits
+	K8sResourceQuotaUsage struct {
+		Name string            `json:"Name"`
+		Hard map[string]string `json:"Hard"`
+		Used map[string]string `json:"Used"`
+	}
+)
 
 func (r *K8sNamespaceDetails) Validate(request *http.Request) error {
+	if r.ResourceQuota != nil {
+		if err := r.ResourceQuota.validate(); err != nil {
+			return err
+		}
+	}
+
+	if r.LimitRange != nil {
+		if err := r.LimitRange.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *K8sNamespaceResourceQuota) validate() error {
+	quantities := map[string]string{
+		"CPU":     q.CPU,
+		"Memory":  q.Memory,
+		"Storage": q.Storage,
+	}
+	for name, value := range quantities {
+		if err := validateQuantity(name, value); err != nil {
+			return err
+		}
+	}
+
+	for _, count := range []int64{q.Pods, q.Services, q.ConfigMaps, q.Secrets, q.PersistentVolumeClaims} {
+		if count < 0 {
+			return errors.New("resource quota object counts cannot be negative")
+		}
+	}
+
+	return nil
+}
+
+func (l *K8sNamespaceLimitRange) validate() error {
+	quantities := map[string]string{
+		"DefaultCPU":           l.DefaultCPU,
+		"DefaultMemory":        l.DefaultMemory,
+		"DefaultRequestCPU":    l.DefaultRequestCPU,
+		"DefaultRequestMemory": l.DefaultRequestMemory,
+	}
+	for name, value := range quantities {
+		if err := validateQuantity(name, value); err != nil {
+			return err
+		}
+	}
+
+	if isGreaterQuantity(l.DefaultRequestCPU, l.DefaultCPU) {
+		return errors.New("the default CPU request cannot be greater than the default CPU limit")
+	}
+
+	if isGreaterQuantity(l.DefaultRequestMemory, l.DefaultMemory) {
+		return errors.New("the default memory request cannot be greater than the default memory limit")
+	}
+
+	return nil
+}
+
+func validateQuantity(name, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s quantity: %w", name, err)
+	}
+
+	if quantity.Sign() < 0 {
+		return fmt.Errorf("%s quantity cannot be negative", name)
+	}
+
 	return nil
 }
+
+// isGreaterQuantity returns true when both quantities are set and the first one is greater than the second one,
+// the quantities must have been validated
+func isGreaterQuantity(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+
+	quantity := resource.MustParse(a)
+
+	return quantity.Cmp(resource.MustParse(b)) > 0
+}
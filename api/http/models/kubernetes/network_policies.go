@@ -0,0 +1,54 @@
+package kubernetes
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+type (
+	// K8sNamespaceIsolation describes the isolation of a namespace, an isolated namespace only
+	// accepts traffic from its own pods and from the sources of the allow rules
+	K8sNamespaceIsolation struct {
+		Isolated   bool                        `json:"Isolated" example:"true"`
+		AllowRules []K8sNetworkPolicyAllowRule `json:"AllowRules"`
+	}
+
+	// K8sNetworkPolicyAllowRule allows the traffic coming from the pods of another namespace
+	K8sNetworkPolicyAllowRule struct {
+		// Namespace of the allowed pods
+		Namespace string `json:"Namespace" example:"ingress-nginx"`
+		// PodLabels restricts the allowed pods to the ones matching the labels, e.g. the pods of an ingress controller
+		PodLabels map[string]string `json:"PodLabels,omitempty"`
+	}
+
+	K8sNetworkPolicy struct {
+		Name        string                 `json:"Name"`
+		Namespace   string                 `json:"Namespace"`
+		UID         string                 `json:"UID"`
+		Labels      map[string]string      `json:"Labels,omitempty"`
+		PodSelector string                 `json:"PodSelector"`
+		PolicyTypes []string               `json:"PolicyTypes"`
+		Ingress     []K8sNetworkPolicyRule `json:"Ingress"`
+		Egress      []K8sNetworkPolicyRule `json:"Egress"`
+		// IsManaged is true for the policies created by Portainer
+		IsManaged    bool      `json:"IsManaged"`
+		CreationDate time.Time `json:"CreationDate"`
+	}
+
+	// K8sNetworkPolicyRule is a readable description of the peers and ports of an ingress or egress rule
+	K8sNetworkPolicyRule struct {
+		Peers []string `json:"Peers" example:"namespace: kubernetes.io/metadata.name=ingress-nginx"`
+		Ports []string `json:"Ports" example:"TCP/80"`
+	}
+)
+
+func (r *K8sNamespaceIsolation) Validate(request *http.Request) error {
+	for _, rule := range r.AllowRules {
+		if rule.Namespace == "" {
+			return errors.New("missing namespace in allow rule")
+		}
+	}
+
+	return nil
+}
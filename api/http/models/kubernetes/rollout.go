@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+type (
+	// K8sRolloutRevision is a revision of the rollout history of a workload
+	K8sRolloutRevision struct {
+		Revision int64 `json:"Revision" example:"3"`
+		// Name of the ReplicaSet or ControllerRevision holding the revision
+		Name         string    `json:"Name"`
+		ChangeCause  string    `json:"ChangeCause"`
+		Images       []string  `json:"Images"`
+		Current      bool      `json:"Current"`
+		CreationDate time.Time `json:"CreationDate"`
+	}
+
+	K8sWorkloadScalePayload struct {
+		Replicas int32 `json:"Replicas" example:"3"`
+	}
+
+	K8sWorkloadRollbackPayload struct {
+		Revision int64 `json:"Revision" example:"2"`
+	}
+)
+
+func (r *K8sWorkloadScalePayload) Validate(request *http.Request) error {
+	if r.Replicas < 0 {
+		return errors.New("replicas cannot be negative")
+	}
+
+	return nil
+}
+
+func (r *K8sWorkloadRollbackPayload) Validate(request *http.Request) error {
+	if r.Revision <= 0 {
+		return errors.New("invalid revision")
+	}
+
+	return nil
+}
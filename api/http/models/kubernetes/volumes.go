@@ -0,0 +1,133 @@
+package kubernetes
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type (
+	K8sPersistentVolumeClaim struct {
+		Name         string   `json:"Name"`
+		Namespace    string   `json:"Namespace"`
+		UID          string   `json:"UID"`
+		StorageClass string   `json:"StorageClass"`
+		VolumeName   string   `json:"VolumeName"`
+		AccessModes  []string `json:"AccessModes"`
+		Phase        string   `json:"Phase"`
+		// Requested is the storage requested by the claim
+		Requested string `json:"Requested" example:"10Gi"`
+		// Capacity is the actual storage of the bound volume
+		Capacity string `json:"Capacity" example:"10Gi"`
+		// IsExpandable is true when the storage class of the claim allows volume expansion
+		IsExpandable bool `json:"IsExpandable"`
+		// Applications are the applications whose pods mount the claim
+		Applications []K8sApplication `json:"Applications"`
+		// Usage is reported by the kubelet of the nodes running the pods mounting the claim
+		Usage        *K8sVolumeUsage `json:"Usage,omitempty"`
+		CreationDate time.Time       `json:"CreationDate"`
+	}
+
+	K8sVolumeUsage struct {
+		CapacityBytes  int64 `json:"CapacityBytes"`
+		UsedBytes      int64 `json:"UsedBytes"`
+		AvailableBytes int64 `json:"AvailableBytes"`
+	}
+
+	K8sPersistentVolume struct {
+		Name          string   `json:"Name"`
+		StorageClass  string   `json:"StorageClass"`
+		Capacity      string   `json:"Capacity" example:"10Gi"`
+		AccessModes   []string `json:"AccessModes"`
+		ReclaimPolicy string   `json:"ReclaimPolicy" example:"Delete"`
+		Phase         string   `json:"Phase" example:"Bound"`
+		// Claim is the namespaced name of the claim bound to the volume, e.g. default/data
+		Claim        string    `json:"Claim,omitempty"`
+		CreationDate time.Time `json:"CreationDate"`
+	}
+
+	K8sStorageClass struct {
+		Name                 string `json:"Name"`
+		Provisioner          string `json:"Provisioner"`
+		ReclaimPolicy        string `json:"ReclaimPolicy"`
+		VolumeBindingMode    string `json:"VolumeBindingMode"`
+		AllowVolumeExpansion bool   `json:"AllowVolumeExpansion"`
+		IsDefault            bool   `json:"IsDefault"`
+	}
+
+	K8sVolumeExpansionPayload struct {
+		// Size is the new storage request of the claim, it must be greater than the current one
+		Size string `json:"Size" example:"20Gi"`
+	}
+
+	K8sVolumeSnapshot struct {
+		Name          string `json:"Name"`
+		Namespace     string `json:"Namespace"`
+		UID           string `json:"UID"`
+		SnapshotClass string `json:"SnapshotClass"`
+		// SourceClaim is the name of the claim the snapshot was taken from
+		SourceClaim  string    `json:"SourceClaim"`
+		ReadyToUse   bool      `json:"ReadyToUse"`
+		RestoreSize  string    `json:"RestoreSize,omitempty" example:"10Gi"`
+		Error        string    `json:"Error,omitempty"`
+		CreationDate time.Time `json:"CreationDate"`
+	}
+
+	K8sVolumeSnapshotPayload struct {
+		Name string `json:"Name" example:"data-snapshot"`
+		// SnapshotClass is optional, the default volume snapshot class of the cluster is used when empty
+		SnapshotClass string `json:"SnapshotClass,omitempty"`
+	}
+
+	K8sVolumeSnapshotRestorePayload struct {
+		// Name of the claim created from the snapshot
+		Name string `json:"Name" example:"data-restored"`
+		// StorageClass is optional, the storage class of the source claim is used when empty
+		StorageClass string `json:"StorageClass,omitempty"`
+		// Size is optional, the restore size of the snapshot is used when empty
+		Size string `json:"Size,omitempty" example:"10Gi"`
+	}
+)
+
+func (r *K8sVolumeExpansionPayload) Validate(request *http.Request) error {
+	if r.Size == "" {
+		return errors.New("missing size")
+	}
+
+	return validateStorageSize(r.Size)
+}
+
+func (r *K8sVolumeSnapshotPayload) Validate(request *http.Request) error {
+	if r.Name == "" {
+		return errors.New("missing snapshot name")
+	}
+
+	return nil
+}
+
+func (r *K8sVolumeSnapshotRestorePayload) Validate(request *http.Request) error {
+	if r.Name == "" {
+		return errors.New("missing claim name")
+	}
+
+	if r.Size != "" {
+		return validateStorageSize(r.Size)
+	}
+
+	return nil
+}
+
+func validateStorageSize(size string) error {
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return errors.New("invalid size")
+	}
+
+	if quantity.Sign() <= 0 {
+		return errors.New("size must be positive")
+	}
+
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/portainer/portainer/api/http/proxy/factory/utils"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/policy"
 )
 
 const (
@@ -232,6 +233,13 @@ func (transport *Transport) decorateContainerCreationOperation(request *http.Req
 		request.Body = io.NopCloser(bytes.NewBuffer(body))
 	}
 
+	policyResponse, err := transport.checkWorkloadPolicies(request, "creation", func(body []byte) (policy.Workload, error) {
+		return policy.ContainerWorkload(request.URL.Query().Get("name"), body)
+	})
+	if policyResponse != nil || err != nil {
+		return policyResponse, err
+	}
+
 	response, err := transport.executeDockerRequest(request)
 	if err != nil {
 		return response, err
@@ -243,3 +251,33 @@ func (transport *Transport) decorateContainerCreationOperation(request *http.Req
 
 	return response, err
 }
+
+// decorateContainerUpdateOperation evaluates the container with the resources of the update against the policies of
+// the group of the environment, the container is inspected since the update only carries the resources. The access to
+// the container is checked first so that the policies do not reveal the configuration of an inaccessible container.
+func (transport *Transport) decorateContainerUpdateOperation(request *http.Request, containerID string) (*http.Response, error) {
+	deniedResponse, err := transport.checkResourceAccess(request, containerID, containerID, portainer.ContainerResourceControl, false)
+	if deniedResponse != nil || err != nil {
+		return deniedResponse, err
+	}
+
+	policyResponse, err := transport.checkWorkloadPolicies(request, "update", func(body []byte) (policy.Workload, error) {
+		cli, err := transport.dockerClientFactory.CreateClient(transport.endpoint, request.Header.Get(portainer.PortainerAgentTargetHeader), nil)
+		if err != nil {
+			return policy.Workload{}, err
+		}
+		defer cli.Close()
+
+		_, container, err := cli.ContainerInspectWithRaw(context.Background(), containerID, false)
+		if err != nil {
+			return policy.Workload{}, err
+		}
+
+		return policy.ContainerUpdateWorkload(container, body)
+	})
+	if policyResponse != nil || err != nil {
+		return policyResponse, err
+	}
+
+	return transport.executeDockerRequest(request)
+}
@@ -0,0 +1,39 @@
+package docker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/http/security"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport_decorateContainerUpdateOperation_AccessDenied(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	containerID := "container-id"
+	is.NoError(store.ResourceControl().Create(&portainer.ResourceControl{
+		ResourceID:   containerID,
+		Type:         portainer.ContainerResourceControl,
+		UserAccesses: []portainer.UserResourceAccess{{UserID: 2, AccessLevel: portainer.ReadWriteAccessLevel}},
+	}))
+
+	// the Docker client factory is not set, the container cannot be inspected to evaluate the policies
+	transport := &Transport{dataStore: store, endpoint: &portainer.Endpoint{ID: 1}}
+
+	request := httptest.NewRequest(http.MethodPost, "/containers/"+containerID+"/update", strings.NewReader(`{"Memory": 1024}`))
+	request = request.WithContext(security.StoreTokenData(request, &portainer.TokenData{ID: 1, Username: "user", Role: portainer.StandardUserRole}))
+
+	response, err := transport.decorateContainerUpdateOperation(request, containerID)
+	is.NoError(err)
+	if is.NotNil(response) {
+		is.Equal(http.StatusForbidden, response.StatusCode, "the access is checked before the policies")
+	}
+}
@@ -0,0 +1,46 @@
+package docker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/portainer/portainer/api/http/proxy/factory/utils"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/policy"
+)
+
+// checkWorkloadPolicies evaluates the workload of a creation or update request against the policies of the group of the
+// environment. A response is returned when the request is refused, the request body is restored otherwise
+func (transport *Transport) checkWorkloadPolicies(request *http.Request, operation string, parseWorkload func(body []byte) (policy.Workload, error)) (*http.Response, error) {
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	workload, err := parseWorkload(body)
+	if err != nil {
+		return utils.WriteErrorResponse(err.Error(), http.StatusBadRequest)
+	}
+
+	tokenData, err := security.RetrieveTokenData(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// the group of the environment may have changed since the proxy was created
+	endpoint, err := transport.dataStore.Endpoint().Endpoint(transport.endpoint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Check(transport.dataStore, endpoint, fmt.Sprintf("%s %s by %s", workload.Kind, operation, tokenData.Username), workload)
+	if errors.Is(err, policy.ErrPolicyViolation) {
+		return utils.WriteErrorResponse(err.Error(), http.StatusForbidden)
+	}
+
+	return nil, err
+}
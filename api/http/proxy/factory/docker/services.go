@@ -14,6 +14,7 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/http/proxy/factory/utils"
 	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/policy"
 )
 
 const (
@@ -138,5 +139,10 @@ func (transport *Transport) decorateServiceCreationOperation(request *http.Reque
 		request.Body = io.NopCloser(bytes.NewBuffer(body))
 	}
 
+	policyResponse, err := transport.checkWorkloadPolicies(request, "creation", policy.ServiceWorkload)
+	if policyResponse != nil || err != nil {
+		return policyResponse, err
+	}
+
 	return transport.replaceRegistryAuthenticationHeader(request)
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/portainer/portainer/api/http/proxy/factory/utils"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/policy"
 
 	"github.com/rs/zerolog/log"
 )
@@ -246,6 +247,10 @@ func (transport *Transport) proxyContainerRequest(request *http.Request) (*http.
 			if action == "json" {
 				return transport.rewriteOperation(request, transport.containerInspectOperation)
 			}
+
+			if action == "update" && request.Method == http.MethodPost {
+				return transport.decorateContainerUpdateOperation(request, containerID)
+			}
 			return transport.restrictedResourceOperation(request, containerID, containerID, portainer.ContainerResourceControl, false)
 		} else if match, _ := path.Match("/containers/*", requestPath); match {
 			// Handle /containers/{id} requests
@@ -275,6 +280,14 @@ func (transport *Transport) proxyServiceRequest(request *http.Request) (*http.Re
 			// Handle /services/{id}/{action} requests
 			serviceID := path.Base(path.Dir(requestPath))
 			transport.decorateRegistryAuthenticationHeader(request)
+
+			if path.Base(requestPath) == "update" && request.Method == http.MethodPost {
+				policyResponse, err := transport.checkWorkloadPolicies(request, "update", policy.ServiceWorkload)
+				if policyResponse != nil || err != nil {
+					return policyResponse, err
+				}
+			}
+
 			return transport.restrictedResourceOperation(request, serviceID, serviceID, portainer.ServiceResourceControl, false)
 		} else if match, _ := path.Match("/services/*", requestPath); match {
 			// Handle /services/{id} requests
@@ -394,7 +407,7 @@ func (transport *Transport) updateDefaultGitBranch(request *http.Request) error
 	remote := request.URL.Query().Get("remote")
 	if strings.HasSuffix(remote, ".git") {
 		repositoryURL := remote[:len(remote)-4]
-		latestCommitID, err := transport.gitService.LatestCommitID(repositoryURL, "", "", "", false)
+		latestCommitID, err := transport.gitService.LatestCommitID(repositoryURL, "", nil, false)
 		if err != nil {
 			return err
 		}
@@ -479,7 +492,17 @@ func (transport *Transport) decorateRegistryAuthenticationHeader(request *http.R
 }
 
 func (transport *Transport) restrictedResourceOperation(request *http.Request, resourceID string, dockerResourceID string, resourceType portainer.ResourceControlType, volumeBrowseRestrictionCheck bool) (*http.Response, error) {
-	var err error
+	deniedResponse, err := transport.checkResourceAccess(request, resourceID, dockerResourceID, resourceType, volumeBrowseRestrictionCheck)
+	if deniedResponse != nil || err != nil {
+		return deniedResponse, err
+	}
+
+	return transport.executeDockerRequest(request)
+}
+
+// checkResourceAccess returns the access denied response when the user of the request cannot access the resource,
+// nil when the access is granted
+func (transport *Transport) checkResourceAccess(request *http.Request, resourceID string, dockerResourceID string, resourceType portainer.ResourceControlType, volumeBrowseRestrictionCheck bool) (*http.Response, error) {
 	tokenData, err := security.RetrieveTokenData(request)
 	if err != nil {
 		return nil, err
@@ -536,7 +559,8 @@ func (transport *Transport) restrictedResourceOperation(request *http.Request, r
 			return utils.WriteAccessDeniedResponse()
 		}
 	}
-	return transport.executeDockerRequest(request)
+
+	return nil, nil
 }
 
 // rewriteOperationWithLabelFiltering will create a new operation context with data that will be used
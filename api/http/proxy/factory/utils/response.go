@@ -63,12 +63,28 @@ type errorResponse struct {
 
 // WriteAccessDeniedResponse will create a new access denied response
 func WriteAccessDeniedResponse() (*http.Response, error) {
-	response := &http.Response{}
+	response := newJSONResponse()
 	err := RewriteResponse(response, errorResponse{Message: "access denied to resource"}, http.StatusForbidden)
 
 	return response, err
 }
 
+// WriteErrorResponse will create a new response with the specified status code and error message
+func WriteErrorResponse(message string, statusCode int) (*http.Response, error) {
+	response := newJSONResponse()
+	err := RewriteResponse(response, errorResponse{Message: message}, statusCode)
+
+	return response, err
+}
+
+// newJSONResponse creates an empty response which is written as JSON
+func newJSONResponse() *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+
+	return &http.Response{Header: header}
+}
+
 // RewriteAccessDeniedResponse will overwrite the existing response with an access denied response
 func RewriteAccessDeniedResponse(response *http.Response) error {
 	return RewriteResponse(response, errorResponse{Message: "access denied to resource"}, http.StatusForbidden)
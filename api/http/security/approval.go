@@ -0,0 +1,173 @@
+package security
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+
+	"github.com/pkg/errors"
+)
+
+// maxPendingOperationBodySize is the maximum size of the request body stored with a pending operation
+const maxPendingOperationBodySize = 10 * 1024 * 1024
+
+// ApprovalGatedAuthorizations lists the authorizations of the operations which can be configured to require an approval
+var ApprovalGatedAuthorizations = []portainer.Authorization{
+	portainer.OperationPortainerStackDelete,
+	portainer.OperationPortainerStackMigrate,
+	portainer.OperationPortainerStackUpdate,
+	portainer.OperationPortainerSettingsUpdate,
+}
+
+// OperationAuthorizer verifies that the requester is allowed to perform an operation on its target resource
+type OperationAuthorizer func(r *http.Request) *httperror.HandlerError
+
+// AdminOperationAuthorizer only allows administrators to perform the operation
+func AdminOperationAuthorizer(r *http.Request) *httperror.HandlerError {
+	securityContext, err := RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	if !securityContext.IsAdmin {
+		return httperror.Forbidden("Access denied to resource", errors.New("access denied to resource"))
+	}
+
+	return nil
+}
+
+// IsOperationApprover returns true when the user can perform gated operations directly and review the pending
+// operations of other users. Members of an approver team are approvers. Administrators are only approvers when
+// no approver team is configured, otherwise their gated operations require an approval as well.
+func IsOperationApprover(settings *portainer.OperationApprovalSettings, context *RestrictedRequestContext) bool {
+	if len(settings.ApproverTeamIDs) == 0 {
+		return context.IsAdmin
+	}
+
+	for _, membership := range context.UserMemberships {
+		if slices.Contains(settings.ApproverTeamIDs, membership.TeamID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PendingOperationExpiry returns the duration after which a pending operation expires
+func PendingOperationExpiry(settings *portainer.OperationApprovalSettings) time.Duration {
+	expiry, err := time.ParseDuration(settings.Expiry)
+	if err != nil || expiry <= 0 {
+		expiry, _ = time.ParseDuration(portainer.DefaultPendingOperationExpiry)
+	}
+
+	return expiry
+}
+
+// OperationApproval defines a security check for operations which can require an approval.
+// It must be used inside an authenticated access.
+// When the authorization is gated in the settings and the user is not an approver, the request is stored
+// as a pending operation and a 202 response is returned instead of executing the operation.
+// The requester authorization on the target resource is verified with authorize before the request is stored,
+// so that the operations the requester is not allowed to perform are refused instead of queued.
+// The operation is executed later, with the stored request, when an approver approves it.
+func (bouncer *RequestBouncer) OperationApproval(authorization portainer.Authorization, authorize OperationAuthorizer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := RetrieveApprovedOperation(r); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		settings, err := bouncer.dataStore.Settings().Settings()
+		if err != nil {
+			httperror.WriteError(w, http.StatusInternalServerError, "Unable to retrieve settings", err)
+			return
+		}
+
+		if !slices.Contains(settings.OperationApproval.Authorizations, authorization) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		securityContext, err := RetrieveRestrictedRequestContext(r)
+		if err != nil {
+			httperror.WriteError(w, http.StatusInternalServerError, "Unable to retrieve info from request context", err)
+			return
+		}
+
+		if IsOperationApprover(&settings.OperationApproval, securityContext) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if authorize != nil {
+			if httpErr := authorize(r); httpErr != nil {
+				httperror.WriteError(w, httpErr.StatusCode, httpErr.Message, httpErr.Err)
+				return
+			}
+		}
+
+		operation, httpErr := bouncer.createPendingOperation(r, authorization, securityContext.UserID, PendingOperationExpiry(&settings.OperationApproval))
+		if httpErr != nil {
+			httperror.WriteError(w, httpErr.StatusCode, httpErr.Message, httpErr.Err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(operation)
+	})
+}
+
+func (bouncer *RequestBouncer) createPendingOperation(r *http.Request, authorization portainer.Authorization, userID portainer.UserID, expiry time.Duration) (*portainer.PendingOperation, *httperror.HandlerError) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(r.Body, maxPendingOperationBodySize+1))
+		if err != nil {
+			return nil, httperror.BadRequest("Unable to read the request body", err)
+		}
+
+		if len(body) > maxPendingOperationBodySize {
+			return nil, &httperror.HandlerError{
+				StatusCode: http.StatusRequestEntityTooLarge,
+				Message:    "The request body is too large to be stored for approval",
+				Err:        errors.New("request body too large"),
+			}
+		}
+	}
+
+	// the request URI is used rather than the URL as the API prefix is stripped from the URL by the router
+	url := r.RequestURI
+	if url == "" {
+		url = r.URL.RequestURI()
+	}
+
+	endpointID, _ := request.RetrieveNumericQueryParameter(r, "endpointId", true)
+
+	now := time.Now()
+	operation := &portainer.PendingOperation{
+		Authorization: authorization,
+		Method:        r.Method,
+		URL:           url,
+		ContentType:   r.Header.Get("Content-Type"),
+		Body:          body,
+		EndpointID:    portainer.EndpointID(endpointID),
+		Status:        portainer.PendingOperationPending,
+		RequestedBy:   userID,
+		CreationDate:  now.Unix(),
+		ExpiryDate:    now.Add(expiry).Unix(),
+	}
+
+	err := bouncer.dataStore.PendingOperation().Create(operation)
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to persist the pending operation inside the database", err)
+	}
+
+	return operation, nil
+}
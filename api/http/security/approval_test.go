@@ -0,0 +1,132 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_OperationApproval(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	settings, err := store.Settings().Settings()
+	assert.NoError(t, err)
+
+	settings.OperationApproval = portainer.OperationApprovalSettings{
+		Authorizations:  []portainer.Authorization{portainer.OperationPortainerStackDelete},
+		ApproverTeamIDs: []portainer.TeamID{2},
+		Expiry:          "1h",
+	}
+	assert.NoError(t, store.Settings().UpdateSettings(settings))
+
+	bouncer := NewRequestBouncer(store, nil, nil)
+
+	newRequest := func(context *RestrictedRequestContext) *http.Request {
+		r := httptest.NewRequest(http.MethodDelete, "/api/stacks/1?endpointId=3", strings.NewReader(`{"name":"test"}`))
+		r.Header.Set("Content-Type", "application/json")
+		return r.WithContext(StoreRestrictedRequestContext(r, context))
+	}
+
+	t.Run("operations which are not gated are executed", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		bouncer.OperationApproval(portainer.OperationPortainerStackUpdate, nil, testHandler200).ServeHTTP(rr, newRequest(&RestrictedRequestContext{UserID: 2}))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("approver team members execute gated operations", func(t *testing.T) {
+		context := &RestrictedRequestContext{UserID: 2, UserMemberships: []portainer.TeamMembership{{UserID: 2, TeamID: 2}}}
+
+		rr := httptest.NewRecorder()
+		bouncer.OperationApproval(portainer.OperationPortainerStackDelete, nil, testHandler200).ServeHTTP(rr, newRequest(context))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("other users get a pending operation", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		bouncer.OperationApproval(portainer.OperationPortainerStackDelete, nil, testHandler200).ServeHTTP(rr, newRequest(&RestrictedRequestContext{UserID: 3}))
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+
+		operations, err := store.PendingOperation().ReadAll()
+		assert.NoError(t, err)
+		if !assert.Len(t, operations, 1) {
+			return
+		}
+
+		operation := operations[0]
+		assert.Equal(t, portainer.OperationPortainerStackDelete, operation.Authorization)
+		assert.Equal(t, http.MethodDelete, operation.Method)
+		assert.Equal(t, "/api/stacks/1?endpointId=3", operation.URL)
+		assert.Equal(t, `{"name":"test"}`, string(operation.Body))
+		assert.Equal(t, "application/json", operation.ContentType)
+		assert.Equal(t, portainer.EndpointID(3), operation.EndpointID)
+		assert.Equal(t, portainer.UserID(3), operation.RequestedBy)
+		assert.Equal(t, portainer.PendingOperationPending, operation.Status)
+		assert.Equal(t, int64(3600), operation.ExpiryDate-operation.CreationDate)
+	})
+
+	t.Run("unauthorized operations are refused instead of queued", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		bouncer.OperationApproval(portainer.OperationPortainerStackDelete, AdminOperationAuthorizer, testHandler200).ServeHTTP(rr, newRequest(&RestrictedRequestContext{UserID: 4}))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		operations, err := store.PendingOperation().ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, operations, 1)
+	})
+
+	t.Run("administrators outside of the approver teams get a pending operation", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		bouncer.OperationApproval(portainer.OperationPortainerStackDelete, nil, testHandler200).ServeHTTP(rr, newRequest(&RestrictedRequestContext{IsAdmin: true, UserID: 1}))
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+
+		operations, err := store.PendingOperation().ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, operations, 2)
+	})
+
+	t.Run("approved operations are executed", func(t *testing.T) {
+		r := newRequest(&RestrictedRequestContext{UserID: 3})
+		r = r.WithContext(StoreApprovedOperation(r, 1))
+
+		rr := httptest.NewRecorder()
+		bouncer.OperationApproval(portainer.OperationPortainerStackDelete, nil, testHandler200).ServeHTTP(rr, r)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func Test_IsOperationApprover(t *testing.T) {
+	is := assert.New(t)
+
+	admin := &RestrictedRequestContext{IsAdmin: true, UserID: 1}
+	approver := &RestrictedRequestContext{UserID: 2, UserMemberships: []portainer.TeamMembership{{UserID: 2, TeamID: 2}}}
+
+	is.True(IsOperationApprover(&portainer.OperationApprovalSettings{}, admin), "administrators approve when no approver team is configured")
+	is.False(IsOperationApprover(&portainer.OperationApprovalSettings{}, approver))
+
+	settings := &portainer.OperationApprovalSettings{ApproverTeamIDs: []portainer.TeamID{2}}
+	is.False(IsOperationApprover(settings, admin), "administrators outside of the approver teams are not approvers")
+	is.True(IsOperationApprover(settings, approver))
+
+	adminApprover := &RestrictedRequestContext{IsAdmin: true, UserID: 1, UserMemberships: []portainer.TeamMembership{{UserID: 1, TeamID: 2}}}
+	is.True(IsOperationApprover(settings, adminApprover))
+}
+
+func Test_PendingOperationExpiry(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal("24h0m0s", PendingOperationExpiry(&portainer.OperationApprovalSettings{}).String())
+	is.Equal("24h0m0s", PendingOperationExpiry(&portainer.OperationApprovalSettings{Expiry: "-1h"}).String())
+	is.Equal("30m0s", PendingOperationExpiry(&portainer.OperationApprovalSettings{Expiry: "30m"}).String())
+}
@@ -22,6 +22,7 @@ type (
 		TeamLeaderAccess(http.Handler) http.Handler
 		AuthenticatedAccess(http.Handler) http.Handler
 		EdgeComputeOperation(http.Handler) http.Handler
+		OperationApproval(portainer.Authorization, OperationAuthorizer, http.Handler) http.Handler
 
 		AuthorizedEndpointOperation(*http.Request, *portainer.Endpoint) error
 		AuthorizedEdgeEndpointOperation(*http.Request, *portainer.Endpoint) error
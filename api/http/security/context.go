@@ -15,6 +15,7 @@ type (
 const (
 	contextAuthenticationKey contextKey = iota
 	contextRestrictedRequest
+	contextApprovedOperation
 )
 
 // StoreTokenData stores a TokenData object inside the request context and returns the enhanced context.
@@ -49,3 +50,15 @@ func RetrieveRestrictedRequestContext(request *http.Request) (*RestrictedRequest
 	requestContext := contextData.(*RestrictedRequestContext)
 	return requestContext, nil
 }
+
+// StoreApprovedOperation marks the request as the execution of an approved pending operation
+// and returns the enhanced context.
+func StoreApprovedOperation(request *http.Request, operationID portainer.PendingOperationID) context.Context {
+	return context.WithValue(request.Context(), contextApprovedOperation, operationID)
+}
+
+// RetrieveApprovedOperation returns the identifier of the approved pending operation executed by the request, if any.
+func RetrieveApprovedOperation(request *http.Request) (portainer.PendingOperationID, bool) {
+	operationID, ok := request.Context().Value(contextApprovedOperation).(portainer.PendingOperationID)
+	return operationID, ok
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/portainer/portainer/api/adminmonitor"
 	"github.com/portainer/portainer/api/apikey"
 	"github.com/portainer/portainer/api/crypto"
+	"github.com/portainer/portainer/api/customtemplates/repository"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/demo"
 	"github.com/portainer/portainer/api/docker"
@@ -36,9 +37,12 @@ import (
 	kubehandler "github.com/portainer/portainer/api/http/handler/kubernetes"
 	"github.com/portainer/portainer/api/http/handler/ldap"
 	"github.com/portainer/portainer/api/http/handler/motd"
+	"github.com/portainer/portainer/api/http/handler/pendingoperations"
+	"github.com/portainer/portainer/api/http/handler/policies"
 	"github.com/portainer/portainer/api/http/handler/registries"
 	"github.com/portainer/portainer/api/http/handler/resourcecontrols"
 	"github.com/portainer/portainer/api/http/handler/roles"
+	"github.com/portainer/portainer/api/http/handler/sessionrecordings"
 	"github.com/portainer/portainer/api/http/handler/settings"
 	sslhandler "github.com/portainer/portainer/api/http/handler/ssl"
 	"github.com/portainer/portainer/api/http/handler/stacks"
@@ -50,6 +54,8 @@ import (
 	"github.com/portainer/portainer/api/http/handler/templates"
 	"github.com/portainer/portainer/api/http/handler/upload"
 	"github.com/portainer/portainer/api/http/handler/users"
+	"github.com/portainer/portainer/api/http/handler/volumebackups"
+	"github.com/portainer/portainer/api/http/handler/vulnerabilities"
 	"github.com/portainer/portainer/api/http/handler/webhooks"
 	"github.com/portainer/portainer/api/http/handler/websocket"
 	"github.com/portainer/portainer/api/http/middlewares"
@@ -64,8 +70,12 @@ import (
 	"github.com/portainer/portainer/api/internal/upgrade"
 	k8s "github.com/portainer/portainer/api/kubernetes"
 	"github.com/portainer/portainer/api/kubernetes/cli"
+	"github.com/portainer/portainer/api/resourceusage"
 	"github.com/portainer/portainer/api/scheduler"
+	"github.com/portainer/portainer/api/sessionrecording"
 	"github.com/portainer/portainer/api/stacks/deployments"
+	"github.com/portainer/portainer/api/volumebackup"
+	"github.com/portainer/portainer/api/vulnerability"
 	"github.com/portainer/portainer/pkg/libhelm"
 
 	"github.com/rs/zerolog/log"
@@ -107,6 +117,11 @@ type Server struct {
 	ShutdownCtx                 context.Context
 	ShutdownTrigger             context.CancelFunc
 	StackDeployer               deployments.StackDeployer
+	TemplateRepositoryService   *repository.Service
+	SessionRecordingService     *sessionrecording.Service
+	ResourceUsageService        *resourceusage.Service
+	VolumeBackupService         *volumebackup.Service
+	VulnerabilityService        *vulnerability.Service
 	DemoService                 *demo.Service
 	UpgradeService              upgrade.Service
 	AdminCreationDone           chan struct{}
@@ -149,6 +164,7 @@ func (server *Server) Start() error {
 	roleHandler.DataStore = server.DataStore
 
 	var customTemplatesHandler = customtemplates.NewHandler(requestBouncer, server.DataStore, server.FileService, server.GitService)
+	customTemplatesHandler.TemplateRepositoryService = server.TemplateRepositoryService
 
 	var edgeGroupsHandler = edgegroups.NewHandler(requestBouncer)
 	edgeGroupsHandler.DataStore = server.DataStore
@@ -172,6 +188,7 @@ func (server *Server) Start() error {
 	endpointHandler.FileService = server.FileService
 	endpointHandler.ProxyManager = server.ProxyManager
 	endpointHandler.SnapshotService = server.SnapshotService
+	endpointHandler.ResourceUsageService = server.ResourceUsageService
 	endpointHandler.K8sClientFactory = server.KubernetesClientFactory
 	endpointHandler.ReverseTunnelService = server.ReverseTunnelService
 	endpointHandler.ComposeStackManager = server.ComposeStackManager
@@ -191,6 +208,7 @@ func (server *Server) Start() error {
 	endpointProxyHandler.ReverseTunnelService = server.ReverseTunnelService
 
 	var kubernetesHandler = kubehandler.NewHandler(requestBouncer, server.AuthorizationService, server.DataStore, server.JWTService, server.KubeClusterAccessService, server.KubernetesClientFactory, nil)
+	kubernetesHandler.FileService = server.FileService
 
 	containerService := docker.NewContainerService(server.DockerClientFactory, server.DataStore)
 
@@ -216,6 +234,7 @@ func (server *Server) Start() error {
 	registryHandler.FileService = server.FileService
 	registryHandler.ProxyManager = server.ProxyManager
 	registryHandler.K8sClientFactory = server.KubernetesClientFactory
+	registryHandler.SwarmStackManager = server.SwarmStackManager
 
 	var resourceControlHandler = resourcecontrols.NewHandler(requestBouncer)
 	resourceControlHandler.DataStore = server.DataStore
@@ -281,54 +300,81 @@ func (server *Server) Start() error {
 
 	var websocketHandler = websocket.NewHandler(server.KubernetesTokenCacheManager, requestBouncer)
 	websocketHandler.DataStore = server.DataStore
+	websocketHandler.SessionRecordingService = server.SessionRecordingService
 	websocketHandler.SignatureService = server.SignatureService
 	websocketHandler.ReverseTunnelService = server.ReverseTunnelService
 	websocketHandler.KubernetesClientFactory = server.KubernetesClientFactory
 
+	var pendingOperationHandler = pendingoperations.NewHandler(requestBouncer)
+	pendingOperationHandler.DataStore = server.DataStore
+
+	var policyHandler = policies.NewHandler(requestBouncer)
+	policyHandler.DataStore = server.DataStore
+
+	var sessionRecordingHandler = sessionrecordings.NewHandler(requestBouncer)
+	sessionRecordingHandler.DataStore = server.DataStore
+	sessionRecordingHandler.SessionRecordingService = server.SessionRecordingService
+
+	var volumeBackupHandler = volumebackups.NewHandler(requestBouncer)
+	volumeBackupHandler.DataStore = server.DataStore
+	volumeBackupHandler.VolumeBackupService = server.VolumeBackupService
+
+	var vulnerabilityHandler = vulnerabilities.NewHandler(requestBouncer)
+	vulnerabilityHandler.DataStore = server.DataStore
+	vulnerabilityHandler.VulnerabilityService = server.VulnerabilityService
+
 	var webhookHandler = webhooks.NewHandler(requestBouncer)
 	webhookHandler.DataStore = server.DataStore
 	webhookHandler.DockerClientFactory = server.DockerClientFactory
 
 	server.Handler = &handler.Handler{
-		RoleHandler:            roleHandler,
-		AuthHandler:            authHandler,
-		BackupHandler:          backupHandler,
-		CustomTemplatesHandler: customTemplatesHandler,
-		DockerHandler:          dockerHandler,
-		EdgeGroupsHandler:      edgeGroupsHandler,
-		EdgeJobsHandler:        edgeJobsHandler,
-		EdgeStacksHandler:      edgeStacksHandler,
-		EdgeTemplatesHandler:   edgeTemplatesHandler,
-		EndpointGroupHandler:   endpointGroupHandler,
-		EndpointHandler:        endpointHandler,
-		EndpointHelmHandler:    endpointHelmHandler,
-		EndpointEdgeHandler:    endpointEdgeHandler,
-		EndpointProxyHandler:   endpointProxyHandler,
-		GitOperationHandler:    gitOperationHandler,
-		FileHandler:            fileHandler,
-		LDAPHandler:            ldapHandler,
-		HelmTemplatesHandler:   helmTemplatesHandler,
-		KubernetesHandler:      kubernetesHandler,
-		MOTDHandler:            motdHandler,
-		OpenAMTHandler:         openAMTHandler,
-		FDOHandler:             fdoHandler,
-		RegistryHandler:        registryHandler,
-		ResourceControlHandler: resourceControlHandler,
-		SettingsHandler:        settingsHandler,
-		SSLHandler:             sslHandler,
-		StackHandler:           stackHandler,
-		StorybookHandler:       storybookHandler,
-		SystemHandler:          systemHandler,
-		TagHandler:             tagHandler,
-		TeamHandler:            teamHandler,
-		TeamMembershipHandler:  teamMembershipHandler,
-		TemplatesHandler:       templatesHandler,
-		UploadHandler:          uploadHandler,
-		UserHandler:            userHandler,
-		WebSocketHandler:       websocketHandler,
-		WebhookHandler:         webhookHandler,
+		RoleHandler:             roleHandler,
+		AuthHandler:             authHandler,
+		BackupHandler:           backupHandler,
+		CustomTemplatesHandler:  customTemplatesHandler,
+		DockerHandler:           dockerHandler,
+		EdgeGroupsHandler:       edgeGroupsHandler,
+		EdgeJobsHandler:         edgeJobsHandler,
+		EdgeStacksHandler:       edgeStacksHandler,
+		EdgeTemplatesHandler:    edgeTemplatesHandler,
+		EndpointGroupHandler:    endpointGroupHandler,
+		EndpointHandler:         endpointHandler,
+		EndpointHelmHandler:     endpointHelmHandler,
+		EndpointEdgeHandler:     endpointEdgeHandler,
+		EndpointProxyHandler:    endpointProxyHandler,
+		GitOperationHandler:     gitOperationHandler,
+		FileHandler:             fileHandler,
+		LDAPHandler:             ldapHandler,
+		HelmTemplatesHandler:    helmTemplatesHandler,
+		KubernetesHandler:       kubernetesHandler,
+		MOTDHandler:             motdHandler,
+		OpenAMTHandler:          openAMTHandler,
+		PendingOperationHandler: pendingOperationHandler,
+		PolicyHandler:           policyHandler,
+		FDOHandler:              fdoHandler,
+		RegistryHandler:         registryHandler,
+		ResourceControlHandler:  resourceControlHandler,
+		SessionRecordingHandler: sessionRecordingHandler,
+		SettingsHandler:         settingsHandler,
+		SSLHandler:              sslHandler,
+		StackHandler:            stackHandler,
+		StorybookHandler:        storybookHandler,
+		SystemHandler:           systemHandler,
+		TagHandler:              tagHandler,
+		TeamHandler:             teamHandler,
+		TeamMembershipHandler:   teamMembershipHandler,
+		TemplatesHandler:        templatesHandler,
+		UploadHandler:           uploadHandler,
+		UserHandler:             userHandler,
+		VolumeBackupHandler:     volumeBackupHandler,
+		VulnerabilityHandler:    vulnerabilityHandler,
+		WebSocketHandler:        websocketHandler,
+		WebhookHandler:          webhookHandler,
 	}
 
+	// approved operations are executed through the API handler so that their original handler applies its checks
+	pendingOperationHandler.OperationHandler = server.Handler
+
 	errorLogger := NewHTTPLogger()
 
 	handler := adminMonitor.WithRedirect(offlineGate.WaitingMiddleware(time.Minute, server.Handler))
@@ -153,6 +153,7 @@ func DefaultEndpointAuthorizationsForEndpointAdministratorRole() portainer.Autho
 		portainer.OperationPortainerWebhookList:               true,
 		portainer.OperationPortainerWebhookCreate:             true,
 		portainer.OperationPortainerWebhookDelete:             true,
+		portainer.OperationK8sCustomResourcesW:                true,
 		portainer.EndpointResourcesAccess:                     true,
 	}
 }
@@ -539,6 +540,36 @@ func getUserEndpointAuthorizations(user *portainer.User, endpoints []portainer.E
 	return endpointAuthorizations
 }
 
+// UserEndpointRoleIDs returns the identifiers of the roles granted to a user on an environment,
+// directly or through the environment group and the teams of the user
+func UserEndpointRoleIDs(user *portainer.User, endpoint *portainer.Endpoint, endpointGroup *portainer.EndpointGroup, memberships []portainer.TeamMembership) []portainer.RoleID {
+	roleIDs := make([]portainer.RoleID, 0)
+
+	if policy, ok := endpoint.UserAccessPolicies[user.ID]; ok {
+		roleIDs = append(roleIDs, policy.RoleID)
+	}
+
+	if endpointGroup != nil {
+		if policy, ok := endpointGroup.UserAccessPolicies[user.ID]; ok {
+			roleIDs = append(roleIDs, policy.RoleID)
+		}
+	}
+
+	for _, membership := range memberships {
+		if policy, ok := endpoint.TeamAccessPolicies[membership.TeamID]; ok {
+			roleIDs = append(roleIDs, policy.RoleID)
+		}
+
+		if endpointGroup != nil {
+			if policy, ok := endpointGroup.TeamAccessPolicies[membership.TeamID]; ok {
+				roleIDs = append(roleIDs, policy.RoleID)
+			}
+		}
+	}
+
+	return roleIDs
+}
+
 func getAuthorizationsFromUserEndpointPolicy(user *portainer.User, endpoint *portainer.Endpoint, roles []portainer.Role) portainer.Authorizations {
 	policyRoles := make([]portainer.RoleID, 0)
 
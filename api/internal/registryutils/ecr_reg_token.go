@@ -15,18 +15,28 @@ func isRegTokenValid(registry *portainer.Registry) (valid bool) {
 }
 
 func doGetRegToken(dataStore dataservices.DataStore, registry *portainer.Registry) (err error) {
+	err = RefreshRegToken(registry)
+	if err != nil {
+		return
+	}
+
+	err = dataStore.Registry().Update(registry.ID, registry)
+
+	return
+}
+
+// RefreshRegToken retrieves a new ECR access token with the credentials of the registry, the registry is not persisted
+func RefreshRegToken(registry *portainer.Registry) error {
 	ecrClient := ecr.NewService(registry.Username, registry.Password, registry.Ecr.Region)
 	accessToken, expiryAt, err := ecrClient.GetAuthorizationToken()
 	if err != nil {
-		return
+		return err
 	}
 
 	registry.AccessToken = *accessToken
 	registry.AccessTokenExpiry = expiryAt.Unix()
 
-	err = dataStore.Registry().Update(registry.ID, registry)
-
-	return
+	return nil
 }
 
 func parseRegToken(registry *portainer.Registry) (username, password string, err error) {
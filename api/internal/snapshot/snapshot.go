@@ -26,11 +26,12 @@ type Service struct {
 	snapshotIntervalInSeconds float64
 	dockerSnapshotter         portainer.DockerSnapshotter
 	kubernetesSnapshotter     portainer.KubernetesSnapshotter
+	resourceUsageRecorder     portainer.ResourceUsageRecorder
 	shutdownCtx               context.Context
 }
 
 // NewService creates a new instance of a service
-func NewService(snapshotIntervalFromFlag string, dataStore dataservices.DataStore, dockerSnapshotter portainer.DockerSnapshotter, kubernetesSnapshotter portainer.KubernetesSnapshotter, shutdownCtx context.Context) (*Service, error) {
+func NewService(snapshotIntervalFromFlag string, dataStore dataservices.DataStore, dockerSnapshotter portainer.DockerSnapshotter, kubernetesSnapshotter portainer.KubernetesSnapshotter, resourceUsageRecorder portainer.ResourceUsageRecorder, shutdownCtx context.Context) (*Service, error) {
 	interval, err := parseSnapshotFrequency(snapshotIntervalFromFlag, dataStore)
 	if err != nil {
 		return nil, err
@@ -42,6 +43,7 @@ func NewService(snapshotIntervalFromFlag string, dataStore dataservices.DataStor
 		snapshotIntervalInSeconds: interval,
 		dockerSnapshotter:         dockerSnapshotter,
 		kubernetesSnapshotter:     kubernetesSnapshotter,
+		resourceUsageRecorder:     resourceUsageRecorder,
 		shutdownCtx:               shutdownCtx,
 	}, nil
 }
@@ -215,10 +217,19 @@ func (service *Service) snapshotDockerEndpoint(endpoint *portainer.Endpoint) err
 		return err
 	}
 
-	if dockerSnapshot != nil {
-		snapshot := &portainer.Snapshot{EndpointID: endpoint.ID, Docker: dockerSnapshot}
+	if dockerSnapshot == nil {
+		return nil
+	}
 
-		return service.dataStore.Snapshot().Create(snapshot)
+	snapshot := &portainer.Snapshot{EndpointID: endpoint.ID, Docker: dockerSnapshot}
+	if err := service.dataStore.Snapshot().Create(snapshot); err != nil {
+		return err
+	}
+
+	if service.resourceUsageRecorder != nil {
+		if err := service.resourceUsageRecorder.Record(endpoint); err != nil {
+			log.Warn().Err(err).Int("endpoint_id", int(endpoint.ID)).Msg("unable to record the resource usage of the environment")
+		}
 	}
 
 	return nil
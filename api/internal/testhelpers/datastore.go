@@ -10,30 +10,43 @@ import (
 )
 
 type testDatastore struct {
-	customTemplate          dataservices.CustomTemplateService
-	edgeGroup               dataservices.EdgeGroupService
-	edgeJob                 dataservices.EdgeJobService
-	edgeStack               dataservices.EdgeStackService
-	endpoint                dataservices.EndpointService
-	endpointGroup           dataservices.EndpointGroupService
-	endpointRelation        dataservices.EndpointRelationService
-	fdoProfile              dataservices.FDOProfileService
-	helmUserRepository      dataservices.HelmUserRepositoryService
-	registry                dataservices.RegistryService
-	resourceControl         dataservices.ResourceControlService
-	apiKeyRepositoryService dataservices.APIKeyRepository
-	role                    dataservices.RoleService
-	sslSettings             dataservices.SSLSettingsService
-	settings                dataservices.SettingsService
-	snapshot                dataservices.SnapshotService
-	stack                   dataservices.StackService
-	tag                     dataservices.TagService
-	teamMembership          dataservices.TeamMembershipService
-	team                    dataservices.TeamService
-	tunnelServer            dataservices.TunnelServerService
-	user                    dataservices.UserService
-	version                 dataservices.VersionService
-	webhook                 dataservices.WebhookService
+	customTemplate           dataservices.CustomTemplateService
+	customTemplateRepository dataservices.CustomTemplateRepositoryService
+	edgeGroup                dataservices.EdgeGroupService
+	edgeJob                  dataservices.EdgeJobService
+	edgeStack                dataservices.EdgeStackService
+	endpoint                 dataservices.EndpointService
+	endpointGroup            dataservices.EndpointGroupService
+	endpointRelation         dataservices.EndpointRelationService
+	fdoProfile               dataservices.FDOProfileService
+	gitCredential            dataservices.GitCredentialService
+	gitSigningKey            dataservices.GitSigningKeyService
+	gitSSHKey                dataservices.GitSSHKeyService
+	helmUserRepository       dataservices.HelmUserRepositoryService
+	imageScan                dataservices.ImageScanService
+	kubeconfigToken          dataservices.KubeconfigTokenService
+	pendingOperation         dataservices.PendingOperationService
+	policy                   dataservices.PolicyService
+	registry                 dataservices.RegistryService
+	resourceControl          dataservices.ResourceControlService
+	resourceUsageSample      dataservices.ResourceUsageSampleService
+	apiKeyRepositoryService  dataservices.APIKeyRepository
+	role                     dataservices.RoleService
+	sslSettings              dataservices.SSLSettingsService
+	sessionRecording         dataservices.SessionRecordingService
+	settings                 dataservices.SettingsService
+	snapshot                 dataservices.SnapshotService
+	stack                    dataservices.StackService
+	stackPromotion           dataservices.StackPromotionService
+	tag                      dataservices.TagService
+	teamMembership           dataservices.TeamMembershipService
+	team                     dataservices.TeamService
+	tunnelServer             dataservices.TunnelServerService
+	user                     dataservices.UserService
+	version                  dataservices.VersionService
+	volumeBackup             dataservices.VolumeBackupService
+	volumeBackupSchedule     dataservices.VolumeBackupScheduleService
+	webhook                  dataservices.WebhookService
 }
 
 func (d *testDatastore) BackupTo(io.Writer) error                            { return nil }
@@ -47,11 +60,14 @@ func (d *testDatastore) CheckCurrentEdition() error                         { re
 func (d *testDatastore) MigrateData() error                                 { return nil }
 func (d *testDatastore) Rollback(force bool) error                          { return nil }
 func (d *testDatastore) CustomTemplate() dataservices.CustomTemplateService { return d.customTemplate }
-func (d *testDatastore) EdgeGroup() dataservices.EdgeGroupService           { return d.edgeGroup }
-func (d *testDatastore) EdgeJob() dataservices.EdgeJobService               { return d.edgeJob }
-func (d *testDatastore) EdgeStack() dataservices.EdgeStackService           { return d.edgeStack }
-func (d *testDatastore) Endpoint() dataservices.EndpointService             { return d.endpoint }
-func (d *testDatastore) EndpointGroup() dataservices.EndpointGroupService   { return d.endpointGroup }
+func (d *testDatastore) CustomTemplateRepository() dataservices.CustomTemplateRepositoryService {
+	return d.customTemplateRepository
+}
+func (d *testDatastore) EdgeGroup() dataservices.EdgeGroupService         { return d.edgeGroup }
+func (d *testDatastore) EdgeJob() dataservices.EdgeJobService             { return d.edgeJob }
+func (d *testDatastore) EdgeStack() dataservices.EdgeStackService         { return d.edgeStack }
+func (d *testDatastore) Endpoint() dataservices.EndpointService           { return d.endpoint }
+func (d *testDatastore) EndpointGroup() dataservices.EndpointGroupService { return d.endpointGroup }
 
 func (d *testDatastore) FDOProfile() dataservices.FDOProfileService {
 	return d.fdoProfile
@@ -62,25 +78,61 @@ func (d *testDatastore) EndpointRelation() dataservices.EndpointRelationService
 func (d *testDatastore) HelmUserRepository() dataservices.HelmUserRepositoryService {
 	return d.helmUserRepository
 }
+func (d *testDatastore) ImageScan() dataservices.ImageScanService {
+	return d.imageScan
+}
+func (d *testDatastore) GitCredential() dataservices.GitCredentialService {
+	return d.gitCredential
+}
+func (d *testDatastore) GitSigningKey() dataservices.GitSigningKeyService {
+	return d.gitSigningKey
+}
+func (d *testDatastore) GitSSHKey() dataservices.GitSSHKeyService {
+	return d.gitSSHKey
+}
+func (d *testDatastore) KubeconfigToken() dataservices.KubeconfigTokenService {
+	return d.kubeconfigToken
+}
+func (d *testDatastore) PendingOperation() dataservices.PendingOperationService {
+	return d.pendingOperation
+}
+func (d *testDatastore) Policy() dataservices.PolicyService {
+	return d.policy
+}
 func (d *testDatastore) Registry() dataservices.RegistryService { return d.registry }
 func (d *testDatastore) ResourceControl() dataservices.ResourceControlService {
 	return d.resourceControl
 }
+func (d *testDatastore) ResourceUsageSample() dataservices.ResourceUsageSampleService {
+	return d.resourceUsageSample
+}
 func (d *testDatastore) Role() dataservices.RoleService { return d.role }
 func (d *testDatastore) APIKeyRepository() dataservices.APIKeyRepository {
 	return d.apiKeyRepositoryService
 }
-func (d *testDatastore) Settings() dataservices.SettingsService             { return d.settings }
-func (d *testDatastore) Snapshot() dataservices.SnapshotService             { return d.snapshot }
-func (d *testDatastore) SSLSettings() dataservices.SSLSettingsService       { return d.sslSettings }
-func (d *testDatastore) Stack() dataservices.StackService                   { return d.stack }
+func (d *testDatastore) SessionRecording() dataservices.SessionRecordingService {
+	return d.sessionRecording
+}
+func (d *testDatastore) Settings() dataservices.SettingsService       { return d.settings }
+func (d *testDatastore) Snapshot() dataservices.SnapshotService       { return d.snapshot }
+func (d *testDatastore) SSLSettings() dataservices.SSLSettingsService { return d.sslSettings }
+func (d *testDatastore) Stack() dataservices.StackService             { return d.stack }
+func (d *testDatastore) StackPromotion() dataservices.StackPromotionService {
+	return d.stackPromotion
+}
 func (d *testDatastore) Tag() dataservices.TagService                       { return d.tag }
 func (d *testDatastore) TeamMembership() dataservices.TeamMembershipService { return d.teamMembership }
 func (d *testDatastore) Team() dataservices.TeamService                     { return d.team }
 func (d *testDatastore) TunnelServer() dataservices.TunnelServerService     { return d.tunnelServer }
 func (d *testDatastore) User() dataservices.UserService                     { return d.user }
 func (d *testDatastore) Version() dataservices.VersionService               { return d.version }
-func (d *testDatastore) Webhook() dataservices.WebhookService               { return d.webhook }
+func (d *testDatastore) VolumeBackup() dataservices.VolumeBackupService {
+	return d.volumeBackup
+}
+func (d *testDatastore) VolumeBackupSchedule() dataservices.VolumeBackupScheduleService {
+	return d.volumeBackupSchedule
+}
+func (d *testDatastore) Webhook() dataservices.WebhookService { return d.webhook }
 
 func (d *testDatastore) IsErrObjectNotFound(e error) bool {
 	return false
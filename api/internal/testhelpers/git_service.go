@@ -1,6 +1,9 @@
 package testhelpers
 
-import portainer "github.com/portainer/portainer/api"
+import (
+	portainer "github.com/portainer/portainer/api"
+	gittypes "github.com/portainer/portainer/api/git/types"
+)
 
 type gitService struct {
 	cloneErr error
@@ -15,18 +18,26 @@ func NewGitService(cloneErr error, id string) portainer.GitService {
 	}
 }
 
-func (g *gitService) CloneRepository(destination, repositoryURL, referenceName, username, password string, tlsSkipVerify bool) error {
+func (g *gitService) CloneRepository(destination, repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool, content *gittypes.ContentOptions) error {
 	return g.cloneErr
 }
 
-func (g *gitService) LatestCommitID(repositoryURL, referenceName, username, password string, tlsSkipVerify bool) (string, error) {
+func (g *gitService) CloneRepositoryCommit(destination, repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool, content *gittypes.ContentOptions) (string, error) {
+	return g.id, g.cloneErr
+}
+
+func (g *gitService) LatestCommitID(repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool) (string, error) {
 	return g.id, nil
 }
 
-func (g *gitService) ListRefs(repositoryURL, username, password string, hardRefresh bool, tlsSkipVerify bool) ([]string, error) {
+func (g *gitService) ListRefs(repositoryURL string, auth *gittypes.GitAuthentication, hardRefresh bool, tlsSkipVerify bool) ([]string, error) {
 	return nil, nil
 }
 
-func (g *gitService) ListFiles(repositoryURL, referenceName, username, password string, dirOnly, hardRefresh bool, includedExts []string, tlsSkipVerify bool) ([]string, error) {
+func (g *gitService) ListFiles(repositoryURL, referenceName string, auth *gittypes.GitAuthentication, dirOnly, hardRefresh bool, includedExts []string, tlsSkipVerify bool, content *gittypes.ContentOptions) ([]string, error) {
 	return nil, nil
 }
+
+func (g *gitService) CommitSignature(repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool) (*gittypes.CommitSignature, error) {
+	return &gittypes.CommitSignature{CommitID: g.id}, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/http/security"
 )
 
 type testRequestBouncer struct{}
@@ -38,6 +39,10 @@ func (testRequestBouncer) EdgeComputeOperation(h http.Handler) http.Handler {
 	return h
 }
 
+func (testRequestBouncer) OperationApproval(authorization portainer.Authorization, authorize security.OperationAuthorizer, h http.Handler) http.Handler {
+	return h
+}
+
 func (testRequestBouncer) AuthorizedEndpointOperation(r *http.Request, endpoint *portainer.Endpoint) error {
 	return nil
 }
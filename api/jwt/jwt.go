@@ -133,6 +133,12 @@ func (service *Service) ParseAndVerifyToken(token string) (*portainer.TokenData,
 				return nil, errInvalidJWTToken
 			}
 
+			if cl.Scope == kubeConfigScope {
+				if err := service.verifyKubeconfigToken(cl); err != nil {
+					return nil, err
+				}
+			}
+
 			return &portainer.TokenData{
 				ID:       portainer.UserID(cl.UserID),
 				Username: cl.Username,
@@ -163,6 +169,10 @@ func (service *Service) SetUserSessionDuration(userSessionDuration time.Duration
 }
 
 func (service *Service) generateSignedToken(data *portainer.TokenData, expiresAt int64, scope scope) (string, error) {
+	return service.generateSignedTokenWithID(data, expiresAt, scope, "")
+}
+
+func (service *Service) generateSignedTokenWithID(data *portainer.TokenData, expiresAt int64, scope scope, tokenID string) (string, error) {
 	secret, found := service.secrets[scope]
 	if !found {
 		return "", fmt.Errorf("invalid scope: %v", scope)
@@ -186,6 +196,7 @@ func (service *Service) generateSignedToken(data *portainer.TokenData, expiresAt
 		Scope:               scope,
 		ForceChangePassword: data.ForceChangePassword,
 		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
 			ExpiresAt: expiresAt,
 			IssuedAt:  time.Now().Unix(),
 		},
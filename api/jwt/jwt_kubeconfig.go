@@ -1,27 +1,50 @@
 package jwt
 
 import (
+	"strconv"
 	"time"
 
 	portainer "github.com/portainer/portainer/api"
 )
 
-// GenerateTokenForKubeconfig generates a new JWT token for Kubeconfig
-func (service *Service) GenerateTokenForKubeconfig(data *portainer.TokenData) (string, error) {
-	settings, err := service.dataStore.Settings().Settings()
-	if err != nil {
-		return "", err
+// kubeconfigLastUsedInterval is the minimum interval between two updates of the last used date of a kubeconfig token
+const kubeconfigLastUsedInterval = time.Minute
+
+// GenerateTokenForKubeconfig generates a new JWT token for Kubeconfig.
+// The token is bound to a kubeconfig token and becomes invalid as soon as the kubeconfig token is revoked.
+// A token ID of 0 creates a token which is not bound to any kubeconfig token and an expiry date of 0 creates a token which never expires.
+func (service *Service) GenerateTokenForKubeconfig(data *portainer.TokenData, tokenID portainer.KubeconfigTokenID, expiresAt int64) (string, error) {
+	jti := ""
+	if tokenID != 0 {
+		jti = strconv.Itoa(int(tokenID))
+	}
+
+	return service.generateSignedTokenWithID(data, expiresAt, kubeConfigScope, jti)
+}
+
+// verifyKubeconfigToken verifies that the kubeconfig token a JWT token is bound to is not revoked and records its usage.
+// Tokens generated before kubeconfig tokens were introduced are not bound to any kubeconfig token.
+func (service *Service) verifyKubeconfigToken(cl *claims) error {
+	if cl.Id == "" {
+		return nil
 	}
 
-	expiryDuration, err := time.ParseDuration(settings.KubeconfigExpiry)
+	tokenID, err := strconv.Atoi(cl.Id)
 	if err != nil {
-		return "", err
+		return errInvalidJWTToken
+	}
+
+	token, err := service.dataStore.KubeconfigToken().Read(portainer.KubeconfigTokenID(tokenID))
+	if err != nil || token.UserID != portainer.UserID(cl.UserID) {
+		return errInvalidJWTToken
 	}
 
-	expiryAt := time.Now().Add(expiryDuration).Unix()
-	if expiryDuration == time.Duration(0) {
-		expiryAt = 0
+	now := time.Now()
+	if now.Sub(time.Unix(token.LastUsed, 0)) > kubeconfigLastUsedInterval {
+		token.LastUsed = now.Unix()
+		// failing to record the usage must not prevent the token from being used
+		_ = service.dataStore.KubeconfigToken().Update(token.ID, token)
 	}
 
-	return service.generateSignedToken(data, expiryAt, kubeConfigScope)
+	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/datastore"
 	i "github.com/portainer/portainer/api/internal/testhelpers"
 	"github.com/stretchr/testify/assert"
 )
@@ -60,7 +61,7 @@ func TestService_GenerateTokenForKubeconfig(t *testing.T) {
 			service, err := NewService(tt.fields.userSessionTimeout, tt.fields.dataStore)
 			assert.NoError(t, err, "failed to create a copy of service")
 
-			got, err := service.GenerateTokenForKubeconfig(tt.args.data)
+			got, err := service.GenerateTokenForKubeconfig(tt.args.data, 0, 0)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GenerateTokenForKubeconfig() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -81,3 +82,49 @@ func TestService_GenerateTokenForKubeconfig(t *testing.T) {
 		})
 	}
 }
+
+func TestService_KubeconfigTokenRevocation(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	service, err := NewService("24h", store)
+	is.NoError(err)
+
+	is.NoError(store.User().Create(&portainer.User{ID: 2, Username: "standard", Role: portainer.StandardUserRole}))
+	is.NoError(store.User().Create(&portainer.User{ID: 3, Username: "other", Role: portainer.StandardUserRole}))
+
+	tokenData := &portainer.TokenData{ID: 2, Username: "standard", Role: portainer.StandardUserRole}
+
+	kubeconfigToken := &portainer.KubeconfigToken{UserID: tokenData.ID, Mode: portainer.KubeconfigTokenModeBearer}
+	is.NoError(store.KubeconfigToken().Create(kubeconfigToken))
+
+	token, err := service.GenerateTokenForKubeconfig(tokenData, kubeconfigToken.ID, 0)
+	is.NoError(err)
+
+	parsed, err := service.ParseAndVerifyToken(token)
+	if is.NoError(err) {
+		is.Equal(tokenData.ID, parsed.ID)
+	}
+
+	kubeconfigToken, err = store.KubeconfigToken().Read(kubeconfigToken.ID)
+	is.NoError(err)
+	is.NotZero(kubeconfigToken.LastUsed)
+
+	// a token bound to the kubeconfig token of another user is rejected
+	otherToken, err := service.GenerateTokenForKubeconfig(&portainer.TokenData{ID: 3, Username: "other"}, kubeconfigToken.ID, 0)
+	is.NoError(err)
+	_, err = service.ParseAndVerifyToken(otherToken)
+	is.Error(err)
+
+	is.NoError(store.KubeconfigToken().Delete(kubeconfigToken.ID))
+
+	_, err = service.ParseAndVerifyToken(token)
+	is.Error(err)
+
+	// tokens which are not bound to a kubeconfig token are still accepted
+	legacyToken, err := service.GenerateTokenForKubeconfig(tokenData, 0, 0)
+	is.NoError(err)
+	_, err = service.ParseAndVerifyToken(legacyToken)
+	is.NoError(err)
+}
@@ -100,6 +100,64 @@ func hasUserAccessToNamespace(userID int, teamIDs []int, policies portainer.K8sN
 	return false
 }
 
+// HasNamespaceAccess returns true when the namespace access policies grant the user, or one of its teams, access to the namespace.
+// The default namespace is accessible to everyone unless it is restricted.
+func (kcl *KubeClient) HasNamespaceAccess(namespace string, userID int, teamIDs []int, restrictDefaultNamespace bool) (bool, error) {
+	if namespace == defaultNamespace && !restrictDefaultNamespace {
+		return true, nil
+	}
+
+	accessPolicies, err := kcl.GetNamespaceAccessPolicies()
+	if err != nil {
+		return false, err
+	}
+
+	policies, ok := accessPolicies[namespace]
+	if !ok {
+		return false, nil
+	}
+
+	return hasUserAccessToNamespace(userID, teamIDs, policies), nil
+}
+
+// GetUserNamespaces returns the names of the namespaces the user, or one of its teams, is granted access to
+func (kcl *KubeClient) GetUserNamespaces(userID int, teamIDs []int, restrictDefaultNamespace bool) ([]string, error) {
+	accessPolicies, err := kcl.GetNamespaceAccessPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := kcl.cli.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := []string{}
+	for _, namespace := range namespaces.Items {
+		if namespace.Name == defaultNamespace && !restrictDefaultNamespace {
+			results = append(results, namespace.Name)
+			continue
+		}
+
+		policies, ok := accessPolicies[namespace.Name]
+		if ok && hasUserAccessToNamespace(userID, teamIDs, policies) {
+			results = append(results, namespace.Name)
+		}
+	}
+
+	return results, nil
+}
+
+// CanEditCustomResources returns true when the user is allowed to edit and delete the custom resources of an environment,
+// i.e. administrators and the users whose role grants the custom resources authorization on the environment
+func CanEditCustomResources(user *portainer.User, endpointID portainer.EndpointID) bool {
+	if user.Role == portainer.AdministratorRole {
+		return true
+	}
+
+	return user.EndpointAuthorizations[endpointID][portainer.OperationK8sCustomResourcesW]
+}
+
 // UpdateNamespaceAccessPolicies updates the namespace access policies
 func (kcl *KubeClient) UpdateNamespaceAccessPolicies(accessPolicies map[string]portainer.K8sNamespaceAccessPolicy) error {
 	data, err := json.Marshal(accessPolicies)
@@ -12,6 +12,7 @@ import (
 
 	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -39,6 +40,7 @@ type (
 	// KubeClient represent a service used to execute Kubernetes operations
 	KubeClient struct {
 		cli        kubernetes.Interface
+		dynamicCli dynamic.Interface
 		instanceID string
 		mu         sync.Mutex
 	}
@@ -98,6 +100,18 @@ func (factory *ClientFactory) GetKubeClient(endpoint *portainer.Endpoint) (*Kube
 	return client, nil
 }
 
+// NewKubeClient returns a KubeClient executing the operations with the provided Kubernetes client
+func NewKubeClient(cli kubernetes.Interface, instanceID string) *KubeClient {
+	return &KubeClient{cli: cli, instanceID: instanceID}
+}
+
+// SetKubeClient registers the client returned by GetKubeClient for the environment, replacing the cached client
+func (factory *ClientFactory) SetKubeClient(endpointID portainer.EndpointID, client *KubeClient) {
+	factory.mu.Lock()
+	factory.endpointClients[strconv.Itoa(int(endpointID))] = client
+	factory.mu.Unlock()
+}
+
 // GetProxyKubeClient retrieves a KubeClient from the cache. You should be
 // calling SetProxyKubeClient before first. It is normally, called the
 // kubernetes middleware.
@@ -136,33 +150,59 @@ func (factory *ClientFactory) CreateKubeClientFromKubeConfig(clusterID string, k
 		return nil, err
 	}
 
+	dynamicCli, err := dynamic.NewForConfig(cliConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &KubeClient{
 		cli:        cli,
+		dynamicCli: dynamicCli,
 		instanceID: factory.instanceID,
 	}, nil
 }
 
 func (factory *ClientFactory) createCachedAdminKubeClient(endpoint *portainer.Endpoint) (*KubeClient, error) {
-	cli, err := factory.CreateClient(endpoint)
+	config, err := factory.createConfig(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicCli, err := dynamic.NewForConfig(config)
 	if err != nil {
 		return nil, err
 	}
 
 	return &KubeClient{
 		cli:        cli,
+		dynamicCli: dynamicCli,
 		instanceID: factory.instanceID,
 	}, nil
 }
 
 // CreateClient returns a pointer to a new Clientset instance
 func (factory *ClientFactory) CreateClient(endpoint *portainer.Endpoint) (*kubernetes.Clientset, error) {
+	config, err := factory.createConfig(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes.NewForConfig(config)
+}
+
+func (factory *ClientFactory) createConfig(endpoint *portainer.Endpoint) (*rest.Config, error) {
 	switch endpoint.Type {
 	case portainer.KubernetesLocalEnvironment:
-		return buildLocalClient()
+		return buildLocalConfig()
 	case portainer.AgentOnKubernetesEnvironment:
-		return factory.buildAgentClient(endpoint)
+		return factory.buildAgentConfig(endpoint)
 	case portainer.EdgeAgentOnKubernetesEnvironment:
-		return factory.buildEdgeClient(endpoint)
+		return factory.buildEdgeConfig(endpoint)
 	}
 
 	return nil, errors.New("unsupported environment type")
@@ -184,23 +224,23 @@ func (rt *agentHeaderRoundTripper) RoundTrip(req *http.Request) (*http.Response,
 	return rt.roundTripper.RoundTrip(req)
 }
 
-func (factory *ClientFactory) buildAgentClient(endpoint *portainer.Endpoint) (*kubernetes.Clientset, error) {
+func (factory *ClientFactory) buildAgentConfig(endpoint *portainer.Endpoint) (*rest.Config, error) {
 	endpointURL := fmt.Sprintf("https://%s/kubernetes", endpoint.URL)
 
-	return factory.createRemoteClient(endpointURL)
+	return factory.createRemoteConfig(endpointURL)
 }
 
-func (factory *ClientFactory) buildEdgeClient(endpoint *portainer.Endpoint) (*kubernetes.Clientset, error) {
+func (factory *ClientFactory) buildEdgeConfig(endpoint *portainer.Endpoint) (*rest.Config, error) {
 	tunnel, err := factory.reverseTunnelService.GetActiveTunnel(endpoint)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed activating tunnel")
 	}
 	endpointURL := fmt.Sprintf("http://127.0.0.1:%d/kubernetes", tunnel.Port)
 
-	return factory.createRemoteClient(endpointURL)
+	return factory.createRemoteConfig(endpointURL)
 }
 
-func (factory *ClientFactory) createRemoteClient(endpointURL string) (*kubernetes.Clientset, error) {
+func (factory *ClientFactory) createRemoteConfig(endpointURL string) (*rest.Config, error) {
 	signature, err := factory.signatureService.CreateSignature(portainer.PortainerAgentSignatureMessage)
 	if err != nil {
 		return nil, err
@@ -223,7 +263,7 @@ func (factory *ClientFactory) createRemoteClient(endpointURL string) (*kubernete
 		}
 	})
 
-	return kubernetes.NewForConfig(config)
+	return config, nil
 }
 
 func (factory *ClientFactory) CreateRemoteMetricsClient(endpoint *portainer.Endpoint) (*metricsv.Clientset, error) {
@@ -254,7 +294,7 @@ func (factory *ClientFactory) CreateRemoteMetricsClient(endpoint *portainer.Endp
 	return metricsv.NewForConfig(config)
 }
 
-func buildLocalClient() (*kubernetes.Clientset, error) {
+func buildLocalConfig() (*rest.Config, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		return nil, err
@@ -263,7 +303,7 @@ func buildLocalClient() (*kubernetes.Clientset, error) {
 	config.QPS = DefaultKubeClientQPS
 	config.Burst = DefaultKubeClientBurst
 
-	return kubernetes.NewForConfig(config)
+	return config, nil
 }
 
 func (factory *ClientFactory) MigrateEndpointIngresses(e *portainer.Endpoint) error {
@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+var customResourceDefinitionResource = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+var (
+	// ErrCustomResourceMismatch is returned when an update of a custom resource changes its kind, name or namespace
+	ErrCustomResourceMismatch = errors.New("the manifest does not match the custom resource, its kind, name and namespace cannot be changed")
+	// ErrCustomResourceScope is returned when namespaced resources are requested without namespace or the other way around
+	ErrCustomResourceScope = errors.New("the scope of the request does not match the scope of the custom resources")
+)
+
+// GetCustomResourceDefinitions gets the custom resource definitions installed in the cluster
+func (kcl *KubeClient) GetCustomResourceDefinitions() ([]models.K8sCustomResourceDefinition, error) {
+	list, err := kcl.dynamicCli.Resource(customResourceDefinitionResource).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.K8sCustomResourceDefinition, 0, len(list.Items))
+	for _, item := range list.Items {
+		definition, ok := parseCustomResourceDefinition(item)
+		if !ok {
+			continue
+		}
+
+		results = append(results, definition)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	return results, nil
+}
+
+// GetCustomResources gets the custom resources of a definition in a namespace, namespace must be empty for the cluster scoped resources
+func (kcl *KubeClient) GetCustomResources(definitionName, namespace string) ([]models.K8sCustomResource, error) {
+	client, err := kcl.customResourceClient(definitionName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := client.List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.K8sCustomResource, 0, len(list.Items))
+	for _, item := range list.Items {
+		results = append(results, parseCustomResource(item))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	return results, nil
+}
+
+// GetCustomResource gets a custom resource with its YAML manifest
+func (kcl *KubeClient) GetCustomResource(definitionName, namespace, name string) (models.K8sCustomResourceDetails, error) {
+	client, err := kcl.customResourceClient(definitionName, namespace)
+	if err != nil {
+		return models.K8sCustomResourceDetails{}, err
+	}
+
+	resource, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return models.K8sCustomResourceDetails{}, err
+	}
+
+	return parseCustomResourceDetails(*resource)
+}
+
+// UpdateCustomResource replaces a custom resource with a YAML manifest, the kind, name and namespace of the resource
+// cannot be changed
+func (kcl *KubeClient) UpdateCustomResource(definitionName, namespace, name, manifest string) (models.K8sCustomResourceDetails, error) {
+	client, err := kcl.customResourceClient(definitionName, namespace)
+	if err != nil {
+		return models.K8sCustomResourceDetails{}, err
+	}
+
+	existing, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return models.K8sCustomResourceDetails{}, err
+	}
+
+	data, err := yaml.YAMLToJSON([]byte(manifest))
+	if err != nil {
+		return models.K8sCustomResourceDetails{}, errors.Wrap(err, "invalid YAML manifest")
+	}
+
+	resource := &unstructured.Unstructured{}
+	if err := resource.UnmarshalJSON(data); err != nil {
+		return models.K8sCustomResourceDetails{}, errors.Wrap(err, "invalid manifest")
+	}
+
+	if resource.GetAPIVersion() != existing.GetAPIVersion() ||
+		resource.GetKind() != existing.GetKind() ||
+		resource.GetName() != existing.GetName() ||
+		resource.GetNamespace() != existing.GetNamespace() {
+		return models.K8sCustomResourceDetails{}, ErrCustomResourceMismatch
+	}
+
+	// the update is rejected by the API server when the resource was modified since it was read by the user
+	if resource.GetResourceVersion() == "" {
+		resource.SetResourceVersion(existing.GetResourceVersion())
+	}
+
+	updated, err := client.Update(context.TODO(), resource, metav1.UpdateOptions{})
+	if err != nil {
+		return models.K8sCustomResourceDetails{}, err
+	}
+
+	return parseCustomResourceDetails(*updated)
+}
+
+// DeleteCustomResource deletes a custom resource
+func (kcl *KubeClient) DeleteCustomResource(definitionName, namespace, name string) error {
+	client, err := kcl.customResourceClient(definitionName, namespace)
+	if err != nil {
+		return err
+	}
+
+	return client.Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+// customResourceClient returns the dynamic client of the resources of a custom resource definition,
+// namespace must be empty for the cluster scoped resources and only for them
+func (kcl *KubeClient) customResourceClient(definitionName, namespace string) (dynamic.ResourceInterface, error) {
+	definition, err := kcl.getCustomResourceDefinition(definitionName)
+	if err != nil {
+		return nil, err
+	}
+
+	client := kcl.dynamicCli.Resource(schema.GroupVersionResource{
+		Group:    definition.Group,
+		Version:  definition.Version,
+		Resource: definition.Plural,
+	})
+
+	if definition.Namespaced != (namespace != "") {
+		return nil, ErrCustomResourceScope
+	}
+
+	if !definition.Namespaced {
+		return client, nil
+	}
+
+	return client.Namespace(namespace), nil
+}
+
+func (kcl *KubeClient) getCustomResourceDefinition(definitionName string) (models.K8sCustomResourceDefinition, error) {
+	item, err := kcl.dynamicCli.Resource(customResourceDefinitionResource).Get(context.TODO(), definitionName, metav1.GetOptions{})
+	if err != nil {
+		return models.K8sCustomResourceDefinition{}, err
+	}
+
+	definition, ok := parseCustomResourceDefinition(*item)
+	if !ok {
+		return models.K8sCustomResourceDefinition{}, k8serrors.NewNotFound(customResourceDefinitionResource.GroupResource(), definitionName)
+	}
+
+	return definition, nil
+}
+
+// parseCustomResourceDefinition parses a definition, the storage version is used when it is served.
+// Definitions which do not serve any version are ignored.
+func parseCustomResourceDefinition(item unstructured.Unstructured) (models.K8sCustomResourceDefinition, bool) {
+	group, _, _ := unstructured.NestedString(item.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(item.Object, "spec", "names", "kind")
+	plural, _, _ := unstructured.NestedString(item.Object, "spec", "names", "plural")
+	scope, _, _ := unstructured.NestedString(item.Object, "spec", "scope")
+	versions, _, _ := unstructured.NestedSlice(item.Object, "spec", "versions")
+
+	version := ""
+	for _, v := range versions {
+		v, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(v, "name")
+		served, _, _ := unstructured.NestedBool(v, "served")
+		storage, _, _ := unstructured.NestedBool(v, "storage")
+		if !served {
+			continue
+		}
+
+		if version == "" || storage {
+			version = name
+		}
+	}
+
+	if version == "" {
+		return models.K8sCustomResourceDefinition{}, false
+	}
+
+	return models.K8sCustomResourceDefinition{
+		Name:         item.GetName(),
+		Group:        group,
+		Version:      version,
+		Kind:         kind,
+		Plural:       plural,
+		Namespaced:   scope == "Namespaced",
+		CreationDate: item.GetCreationTimestamp().Time,
+	}, true
+}
+
+func parseCustomResource(item unstructured.Unstructured) models.K8sCustomResource {
+	return models.K8sCustomResource{
+		Name:         item.GetName(),
+		Namespace:    item.GetNamespace(),
+		UID:          string(item.GetUID()),
+		APIVersion:   item.GetAPIVersion(),
+		Kind:         item.GetKind(),
+		Labels:       item.GetLabels(),
+		Conditions:   parseConditions(item),
+		CreationDate: item.GetCreationTimestamp().Time,
+	}
+}
+
+func parseCustomResourceDetails(item unstructured.Unstructured) (models.K8sCustomResourceDetails, error) {
+	details := models.K8sCustomResourceDetails{K8sCustomResource: parseCustomResource(item)}
+
+	manifest := item.DeepCopy()
+	manifest.SetManagedFields(nil)
+
+	data, err := json.Marshal(manifest.Object)
+	if err != nil {
+		return details, err
+	}
+
+	content, err := yaml.JSONToYAML(data)
+	if err != nil {
+		return details, err
+	}
+	details.YAML = string(content)
+
+	return details, nil
+}
+
+// parseConditions returns the status conditions of a resource, following the conventions of the core types
+func parseConditions(item unstructured.Unstructured) []models.K8sCondition {
+	conditions := []models.K8sCondition{}
+
+	items, _, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
+	for _, c := range items {
+		c, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		condition := models.K8sCondition{}
+		condition.Type, _, _ = unstructured.NestedString(c, "type")
+		condition.Status, _, _ = unstructured.NestedString(c, "status")
+		condition.Reason, _, _ = unstructured.NestedString(c, "reason")
+		condition.Message, _, _ = unstructured.NestedString(c, "message")
+		condition.LastTransitionTime, _, _ = unstructured.NestedString(c, "lastTransitionTime")
+
+		if condition.Type != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+
+	return conditions
+}
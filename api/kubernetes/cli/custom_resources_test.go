@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newTestCustomResourceClient() *KubeClient {
+	definition := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "certificates.cert-manager.io"},
+		"spec": map[string]interface{}{
+			"group": "cert-manager.io",
+			"scope": "Namespaced",
+			"names": map[string]interface{}{"kind": "Certificate", "plural": "certificates"},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1alpha1", "served": true, "storage": false},
+				map[string]interface{}{"name": "v1", "served": true, "storage": true},
+			},
+		},
+	}}
+
+	unserved := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "legacies.example.io"},
+		"spec": map[string]interface{}{
+			"group":    "example.io",
+			"scope":    "Cluster",
+			"names":    map[string]interface{}{"kind": "Legacy", "plural": "legacies"},
+			"versions": []interface{}{map[string]interface{}{"name": "v1", "served": false, "storage": true}},
+		},
+	}}
+
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec":       map[string]interface{}{"secretName": "web-tls"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "Pending", "message": "Issuing certificate"},
+			},
+		},
+	}}
+
+	certificates := schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	legacies := schema.GroupVersionResource{Group: "example.io", Version: "v1", Resource: "legacies"}
+
+	return &KubeClient{
+		dynamicCli: dfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			customResourceDefinitionResource: "CustomResourceDefinitionList",
+			certificates:                     "CertificateList",
+			legacies:                         "LegacyList",
+		}, definition, unserved, certificate),
+		instanceID: "instance",
+	}
+}
+
+func Test_GetCustomResourceDefinitions(t *testing.T) {
+	is := assert.New(t)
+
+	kcl := newTestCustomResourceClient()
+
+	definitions, err := kcl.GetCustomResourceDefinitions()
+	is.NoError(err)
+	if is.Len(definitions, 1, "definitions which do not serve any version should be ignored") {
+		is.Equal("certificates.cert-manager.io", definitions[0].Name)
+		is.Equal("v1", definitions[0].Version, "the storage version should be used")
+		is.Equal("Certificate", definitions[0].Kind)
+		is.True(definitions[0].Namespaced)
+	}
+}
+
+func Test_GetCustomResources(t *testing.T) {
+	is := assert.New(t)
+
+	kcl := newTestCustomResourceClient()
+
+	_, err := kcl.GetCustomResources("certificates.cert-manager.io", "")
+	is.ErrorIs(err, ErrCustomResourceScope)
+
+	resources, err := kcl.GetCustomResources("certificates.cert-manager.io", "default")
+	is.NoError(err)
+	if is.Len(resources, 1) {
+		is.Equal("web", resources[0].Name)
+		is.Equal([]models.K8sCondition{{Type: "Ready", Status: "False", Reason: "Pending", Message: "Issuing certificate"}}, resources[0].Conditions)
+	}
+
+	details, err := kcl.GetCustomResource("certificates.cert-manager.io", "default", "web")
+	is.NoError(err)
+	is.Contains(details.YAML, "secretName: web-tls")
+}
+
+func Test_UpdateCustomResource(t *testing.T) {
+	is := assert.New(t)
+
+	kcl := newTestCustomResourceClient()
+
+	renamed := `apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: api
+  namespace: default
+spec:
+  secretName: web-tls
+`
+	_, err := kcl.UpdateCustomResource("certificates.cert-manager.io", "default", "web", renamed)
+	is.ErrorIs(err, ErrCustomResourceMismatch)
+
+	updated := `apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+  namespace: default
+spec:
+  secretName: web-tls-renewed
+`
+	details, err := kcl.UpdateCustomResource("certificates.cert-manager.io", "default", "web", updated)
+	is.NoError(err)
+	is.Contains(details.YAML, "secretName: web-tls-renewed")
+
+	is.NoError(kcl.DeleteCustomResource("certificates.cert-manager.io", "default", "web"))
+
+	resources, err := kcl.GetCustomResources("certificates.cert-manager.io", "default")
+	is.NoError(err)
+	is.Empty(resources)
+}
+
+func Test_CanEditCustomResources(t *testing.T) {
+	is := assert.New(t)
+
+	is.True(CanEditCustomResources(&portainer.User{Role: portainer.AdministratorRole}, 1))
+
+	user := &portainer.User{
+		Role: portainer.StandardUserRole,
+		EndpointAuthorizations: portainer.EndpointAuthorizations{
+			1: portainer.Authorizations{portainer.OperationK8sCustomResourcesW: true},
+		},
+	}
+	is.True(CanEditCustomResources(user, 1))
+	is.False(CanEditCustomResources(user, 2))
+}
@@ -7,9 +7,16 @@ import (
 	labels "k8s.io/apimachinery/pkg/labels"
 )
 
+const (
+	// StackNameLabel is the label holding the name of the stack which deployed a resource
+	StackNameLabel = "io.portainer.kubernetes.application.stack"
+	// StackIDLabel is the label holding the identifier of the stack which deployed a resource
+	StackIDLabel = "io.portainer.kubernetes.application.stackid"
+)
+
 // HasStackName checks whether the given name is used in the given namespace.
 func (kcl *KubeClient) HasStackName(namespace string, stackName string) (bool, error) {
-	querySet := labels.Set{"io.portainer.kubernetes.application.stack": stackName}
+	querySet := labels.Set{StackNameLabel: stackName}
 	listOpts := metav1.ListOptions{LabelSelector: labels.SelectorFromSet(querySet).String()}
 	list, err := kcl.cli.AppsV1().Deployments(namespace).List(context.TODO(), listOpts)
 	if err != nil {
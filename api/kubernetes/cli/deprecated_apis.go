@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/portainer/portainer/api/kubernetes/deprecation"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+const lastAppliedConfigurationAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// GetKubernetesVersion returns the version of the Kubernetes API server
+func (kcl *KubeClient) GetKubernetesVersion() (string, error) {
+	version, err := kcl.cli.Discovery().ServerVersion()
+	if err != nil {
+		return "", err
+	}
+
+	return version.GitVersion, nil
+}
+
+// GetDeprecatedAPIFindings returns the live objects which were applied with an API that is deprecated or removed
+// in the target version. The objects are listed through a served version of their resource and the API version
+// they were applied with is read from their last applied configuration.
+func (kcl *KubeClient) GetDeprecatedAPIFindings(target deprecation.Version) ([]models.K8sDeprecatedAPIFinding, error) {
+	_, resourceLists, err := kcl.cli.Discovery().ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	served := map[string]bool{}
+	for _, list := range resourceLists {
+		for _, resource := range list.APIResources {
+			served[list.GroupVersion+"/"+resource.Name] = true
+		}
+	}
+
+	// the objects are the same whatever the version they are listed through
+	objects := map[schema.GroupResource][]unstructured.Unstructured{}
+
+	findings := []models.K8sDeprecatedAPIFinding{}
+	for _, api := range deprecation.APIs() {
+		status, ok := api.StatusAt(target)
+		if !ok {
+			continue
+		}
+
+		resource, ok := servedResource(served, api)
+		if !ok {
+			continue
+		}
+
+		items, ok := objects[resource.GroupResource()]
+		if !ok {
+			list, err := kcl.dynamicCli.Resource(resource).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+
+			items = list.Items
+			objects[resource.GroupResource()] = items
+		}
+
+		for _, item := range items {
+			if appliedAPIVersion(item) != api.APIVersion {
+				continue
+			}
+
+			finding := newDeprecatedAPIFinding(api, status, models.K8sDeprecatedAPISourceLive)
+			finding.Name = item.GetName()
+			finding.Namespace = item.GetNamespace()
+			finding.StackID, _ = strconv.Atoi(item.GetLabels()[StackIDLabel])
+			finding.StackName = item.GetLabels()[StackNameLabel]
+
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings, nil
+}
+
+// newDeprecatedAPIFinding returns a finding describing a deprecated API, without the resource details
+func newDeprecatedAPIFinding(api deprecation.API, status deprecation.Status, source string) models.K8sDeprecatedAPIFinding {
+	return models.K8sDeprecatedAPIFinding{
+		Kind:         api.Kind,
+		APIVersion:   api.APIVersion,
+		Replacement:  api.Replacement,
+		DeprecatedIn: api.DeprecatedIn,
+		RemovedIn:    api.RemovedIn,
+		Status:       string(status),
+		Source:       source,
+	}
+}
+
+// NewDeprecatedAPIFinding returns the finding of a resource declared in a manifest, false is returned when the API
+// of the resource is neither deprecated nor removed in the target version
+func NewDeprecatedAPIFinding(object deprecation.Object, target deprecation.Version) (models.K8sDeprecatedAPIFinding, bool) {
+	api, ok := deprecation.Lookup(object.APIVersion, object.Kind)
+	if !ok {
+		return models.K8sDeprecatedAPIFinding{}, false
+	}
+
+	status, ok := api.StatusAt(target)
+	if !ok {
+		return models.K8sDeprecatedAPIFinding{}, false
+	}
+
+	finding := newDeprecatedAPIFinding(api, status, models.K8sDeprecatedAPISourceManifest)
+	finding.Name = object.Name
+	finding.Namespace = object.Namespace
+
+	return finding, true
+}
+
+// servedResource returns the resource of a deprecated API through a version served by the cluster,
+// the deprecated version is preferred over its replacement
+func servedResource(served map[string]bool, api deprecation.API) (schema.GroupVersionResource, bool) {
+	for _, apiVersion := range []string{api.APIVersion, api.Replacement} {
+		if apiVersion == "" || !served[apiVersion+"/"+api.Resource] {
+			continue
+		}
+
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			continue
+		}
+
+		return gv.WithResource(api.Resource), true
+	}
+
+	return schema.GroupVersionResource{}, false
+}
+
+// appliedAPIVersion returns the API version an object was applied with, empty when the object was not created by kubectl apply
+func appliedAPIVersion(item unstructured.Unstructured) string {
+	configuration := item.GetAnnotations()[lastAppliedConfigurationAnnotation]
+	if strings.TrimSpace(configuration) == "" {
+		return ""
+	}
+
+	var applied struct {
+		APIVersion string `json:"apiVersion"`
+	}
+	if err := json.Unmarshal([]byte(configuration), &applied); err != nil {
+		return ""
+	}
+
+	return applied.APIVersion
+}
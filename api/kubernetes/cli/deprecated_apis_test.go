@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"testing"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/portainer/portainer/api/kubernetes/deprecation"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dfake "k8s.io/client-go/dynamic/fake"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestAppliedObject(name, apiVersion string, labels map[string]interface{}) *unstructured.Unstructured {
+	metadata := map[string]interface{}{
+		"name":      name,
+		"namespace": "default",
+		"annotations": map[string]interface{}{
+			lastAppliedConfigurationAnnotation: `{"apiVersion":"` + apiVersion + `","kind":"HorizontalPodAutoscaler"}`,
+		},
+	}
+	if labels != nil {
+		metadata["labels"] = labels
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling/v2",
+		"kind":       "HorizontalPodAutoscaler",
+		"metadata":   metadata,
+	}}
+}
+
+func Test_GetDeprecatedAPIFindings(t *testing.T) {
+	is := assert.New(t)
+
+	cli := kfake.NewSimpleClientset()
+	cli.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "autoscaling/v2",
+		APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler", Namespaced: true}},
+	}}
+
+	kcl := &KubeClient{
+		cli: cli,
+		dynamicCli: dfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}: "HorizontalPodAutoscalerList",
+		},
+			newTestAppliedObject("web", "autoscaling/v2beta2", map[string]interface{}{StackIDLabel: "3", StackNameLabel: "shop"}),
+			newTestAppliedObject("api", "autoscaling/v2", nil),
+		),
+		instanceID: "instance",
+	}
+
+	findings, err := kcl.GetDeprecatedAPIFindings(deprecation.Version{Major: 1, Minor: 22})
+	is.NoError(err)
+	is.Empty(findings, "autoscaling/v2beta2 is not deprecated before 1.23")
+
+	findings, err = kcl.GetDeprecatedAPIFindings(deprecation.Version{Major: 1, Minor: 26})
+	is.NoError(err)
+	is.Equal([]models.K8sDeprecatedAPIFinding{{
+		Kind:         "HorizontalPodAutoscaler",
+		Name:         "web",
+		Namespace:    "default",
+		APIVersion:   "autoscaling/v2beta2",
+		Replacement:  "autoscaling/v2",
+		DeprecatedIn: "1.23",
+		RemovedIn:    "1.26",
+		Status:       string(deprecation.StatusRemoved),
+		Source:       models.K8sDeprecatedAPISourceLive,
+		StackID:      3,
+		StackName:    "shop",
+	}}, findings)
+}
+
+func Test_NewDeprecatedAPIFinding(t *testing.T) {
+	is := assert.New(t)
+
+	target := deprecation.Version{Major: 1, Minor: 21}
+
+	_, ok := NewDeprecatedAPIFinding(deprecation.Object{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}, target)
+	is.False(ok)
+
+	finding, ok := NewDeprecatedAPIFinding(deprecation.Object{APIVersion: "policy/v1beta1", Kind: "PodDisruptionBudget", Name: "web", Namespace: "default"}, target)
+	is.True(ok)
+	is.Equal(string(deprecation.StatusDeprecated), finding.Status)
+	is.Equal(models.K8sDeprecatedAPISourceManifest, finding.Source)
+	is.Equal("policy/v1", finding.Replacement)
+	is.Equal("web", finding.Name)
+}
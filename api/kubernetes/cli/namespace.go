@@ -3,12 +3,14 @@ package cli
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/pkg/errors"
 	portainer "github.com/portainer/portainer/api"
 	models "github.com/portainer/portainer/api/http/models/kubernetes"
 	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -56,6 +58,15 @@ func (kcl *KubeClient) GetNamespace(name string) (portainer.K8sNamespaceInfo, er
 		IsDefault: namespace.Name == defaultNamespace,
 	}
 
+	// the managed network policies are only reported when the user is allowed to list them
+	policies, err := kcl.getManagedNetworkPolicies(name)
+	if err != nil && !k8serrors.IsForbidden(err) {
+		return portainer.K8sNamespaceInfo{}, err
+	}
+
+	result.NetworkPolicies = policies
+	result.IsIsolated = slices.Contains(policies, namespaceIsolationPolicyName)
+
 	return result, nil
 }
 
@@ -68,7 +79,20 @@ func (kcl *KubeClient) CreateNamespace(info models.K8sNamespaceDetails) error {
 	ns.Annotations = info.Annotations
 
 	_, err := client.Create(context.Background(), &ns, metav1.CreateOptions{})
-	return err
+	if err != nil {
+		return err
+	}
+
+	return kcl.applyNamespaceResources(info)
+}
+
+// applyNamespaceResources applies the resource quota and the limit range of the namespace details
+func (kcl *KubeClient) applyNamespaceResources(info models.K8sNamespaceDetails) error {
+	if err := kcl.applyNamespaceResourceQuota(info.Name, info.ResourceQuota); err != nil {
+		return err
+	}
+
+	return kcl.applyNamespaceLimitRange(info.Name, info.LimitRange)
 }
 
 func isSystemNamespace(namespace v1.Namespace) bool {
@@ -131,7 +155,11 @@ func (kcl *KubeClient) UpdateNamespace(info models.K8sNamespaceDetails) error {
 	ns.Annotations = info.Annotations
 
 	_, err := client.Update(context.Background(), &ns, metav1.UpdateOptions{})
-	return err
+	if err != nil {
+		return err
+	}
+
+	return kcl.applyNamespaceResources(info)
 }
 
 func (kcl *KubeClient) DeleteNamespace(namespace string) error {
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/pkg/errors"
+	netv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	namespaceIsolationPolicyName = "portainer-namespace-isolation"
+	networkPolicyManagedLabel    = "io.portainer.kubernetes.networkpolicy.managed"
+	namespaceNameLabel           = "kubernetes.io/metadata.name"
+)
+
+// ToggleNamespaceIsolation isolates a namespace with a default-deny ingress policy which only allows the traffic between
+// the pods of the namespace and the traffic coming from the sources of the allow rules.
+// The policy is removed when the namespace is not isolated anymore.
+func (kcl *KubeClient) ToggleNamespaceIsolation(namespace string, isolation models.K8sNamespaceIsolation) error {
+	client := kcl.cli.NetworkingV1().NetworkPolicies(namespace)
+
+	existing, err := client.Get(context.TODO(), namespaceIsolationPolicyName, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed fetching namespace isolation policy")
+	}
+	exists := err == nil
+
+	if !isolation.Isolated {
+		if !exists {
+			return nil
+		}
+
+		return client.Delete(context.TODO(), namespaceIsolationPolicyName, metav1.DeleteOptions{})
+	}
+
+	peers := []netv1.NetworkPolicyPeer{
+		{PodSelector: &metav1.LabelSelector{}},
+	}
+	for _, rule := range isolation.AllowRules {
+		peer := netv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{namespaceNameLabel: rule.Namespace},
+			},
+		}
+
+		if len(rule.PodLabels) > 0 {
+			peer.PodSelector = &metav1.LabelSelector{MatchLabels: rule.PodLabels}
+		}
+
+		peers = append(peers, peer)
+	}
+
+	spec := netv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{},
+		PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeIngress},
+		Ingress:     []netv1.NetworkPolicyIngressRule{{From: peers}},
+	}
+
+	if exists {
+		existing.Spec = spec
+		_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+		return errors.Wrap(err, "failed updating namespace isolation policy")
+	}
+
+	_, err = client.Create(context.TODO(), &netv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespaceIsolationPolicyName,
+			Namespace: namespace,
+			Labels:    map[string]string{networkPolicyManagedLabel: "true"},
+		},
+		Spec: spec,
+	}, metav1.CreateOptions{})
+
+	return errors.Wrap(err, "failed creating namespace isolation policy")
+}
+
+// GetNetworkPolicies gets the network policies of a namespace
+func (kcl *KubeClient) GetNetworkPolicies(namespace string) ([]models.K8sNetworkPolicy, error) {
+	policies, err := kcl.cli.NetworkingV1().NetworkPolicies(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.K8sNetworkPolicy, 0, len(policies.Items))
+	for _, policy := range policies.Items {
+		results = append(results, parseNetworkPolicy(policy))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	return results, nil
+}
+
+// GetNetworkPolicy gets a network policy of a namespace
+func (kcl *KubeClient) GetNetworkPolicy(namespace, name string) (models.K8sNetworkPolicy, error) {
+	policy, err := kcl.cli.NetworkingV1().NetworkPolicies(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return models.K8sNetworkPolicy{}, err
+	}
+
+	return parseNetworkPolicy(*policy), nil
+}
+
+// DeleteNetworkPolicy deletes a network policy of a namespace
+func (kcl *KubeClient) DeleteNetworkPolicy(namespace, name string) error {
+	return kcl.cli.NetworkingV1().NetworkPolicies(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+// getManagedNetworkPolicies returns the names of the network policies created by Portainer in a namespace
+func (kcl *KubeClient) getManagedNetworkPolicies(namespace string) ([]string, error) {
+	policies, err := kcl.cli.NetworkingV1().NetworkPolicies(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: networkPolicyManagedLabel + "=true",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, policy := range policies.Items {
+		names = append(names, policy.Name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func parseNetworkPolicy(policy netv1.NetworkPolicy) models.K8sNetworkPolicy {
+	result := models.K8sNetworkPolicy{
+		Name:         policy.Name,
+		Namespace:    policy.Namespace,
+		UID:          string(policy.UID),
+		Labels:       policy.Labels,
+		PodSelector:  formatLabelSelector(&policy.Spec.PodSelector),
+		PolicyTypes:  []string{},
+		Ingress:      []models.K8sNetworkPolicyRule{},
+		Egress:       []models.K8sNetworkPolicyRule{},
+		IsManaged:    policy.Labels[networkPolicyManagedLabel] == "true",
+		CreationDate: policy.CreationTimestamp.Time,
+	}
+
+	for _, policyType := range policy.Spec.PolicyTypes {
+		result.PolicyTypes = append(result.PolicyTypes, string(policyType))
+	}
+
+	for _, rule := range policy.Spec.Ingress {
+		result.Ingress = append(result.Ingress, parseNetworkPolicyRule(rule.From, rule.Ports))
+	}
+
+	for _, rule := range policy.Spec.Egress {
+		result.Egress = append(result.Egress, parseNetworkPolicyRule(rule.To, rule.Ports))
+	}
+
+	return result
+}
+
+func parseNetworkPolicyRule(peers []netv1.NetworkPolicyPeer, ports []netv1.NetworkPolicyPort) models.K8sNetworkPolicyRule {
+	rule := models.K8sNetworkPolicyRule{
+		Peers: []string{},
+		Ports: []string{},
+	}
+
+	for _, peer := range peers {
+		switch {
+		case peer.IPBlock != nil:
+			rule.Peers = append(rule.Peers, "ipBlock: "+peer.IPBlock.CIDR)
+		case peer.NamespaceSelector != nil && peer.PodSelector != nil:
+			rule.Peers = append(rule.Peers, fmt.Sprintf("namespace: %s, pod: %s", formatLabelSelector(peer.NamespaceSelector), formatLabelSelector(peer.PodSelector)))
+		case peer.NamespaceSelector != nil:
+			rule.Peers = append(rule.Peers, "namespace: "+formatLabelSelector(peer.NamespaceSelector))
+		case peer.PodSelector != nil:
+			rule.Peers = append(rule.Peers, "pod: "+formatLabelSelector(peer.PodSelector))
+		}
+	}
+
+	for _, port := range ports {
+		protocol := "TCP"
+		if port.Protocol != nil {
+			protocol = string(*port.Protocol)
+		}
+
+		if port.Port == nil {
+			rule.Ports = append(rule.Ports, protocol)
+			continue
+		}
+
+		rule.Ports = append(rule.Ports, fmt.Sprintf("%s/%s", protocol, port.Port.String()))
+	}
+
+	return rule
+}
+
+func formatLabelSelector(selector *metav1.LabelSelector) string {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil || labelSelector.Empty() {
+		return "all"
+	}
+
+	return labelSelector.String()
+}
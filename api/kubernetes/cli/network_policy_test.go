@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_ToggleNamespaceIsolation(t *testing.T) {
+	is := assert.New(t)
+
+	kcl := &KubeClient{
+		cli: kfake.NewSimpleClientset(
+			&core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}},
+			&netv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "custom", Namespace: "tenant"}},
+		),
+		instanceID: "instance",
+	}
+
+	err := kcl.ToggleNamespaceIsolation("tenant", models.K8sNamespaceIsolation{
+		Isolated: true,
+		AllowRules: []models.K8sNetworkPolicyAllowRule{
+			{Namespace: "monitoring"},
+			{Namespace: "ingress-nginx", PodLabels: map[string]string{"app.kubernetes.io/name": "ingress-nginx"}},
+		},
+	})
+	is.NoError(err)
+
+	policy, err := kcl.GetNetworkPolicy("tenant", namespaceIsolationPolicyName)
+	is.NoError(err)
+	is.True(policy.IsManaged)
+	is.Equal("all", policy.PodSelector)
+	is.Equal([]string{"Ingress"}, policy.PolicyTypes)
+	if is.Len(policy.Ingress, 1) {
+		is.Equal([]string{
+			"pod: all",
+			"namespace: kubernetes.io/metadata.name=monitoring",
+			"namespace: kubernetes.io/metadata.name=ingress-nginx, pod: app.kubernetes.io/name=ingress-nginx",
+		}, policy.Ingress[0].Peers)
+	}
+
+	namespace, err := kcl.GetNamespace("tenant")
+	is.NoError(err)
+	is.True(namespace.IsIsolated)
+	is.Equal([]string{namespaceIsolationPolicyName}, namespace.NetworkPolicies)
+
+	policies, err := kcl.GetNetworkPolicies("tenant")
+	is.NoError(err)
+	is.Len(policies, 2)
+
+	err = kcl.ToggleNamespaceIsolation("tenant", models.K8sNamespaceIsolation{Isolated: false})
+	is.NoError(err)
+
+	list, err := kcl.cli.NetworkingV1().NetworkPolicies("tenant").List(context.Background(), metav1.ListOptions{})
+	is.NoError(err)
+	if is.Len(list.Items, 1) {
+		is.Equal("custom", list.Items[0].Name)
+	}
+
+	namespace, err = kcl.GetNamespace("tenant")
+	is.NoError(err)
+	is.False(namespace.IsIsolated)
+}
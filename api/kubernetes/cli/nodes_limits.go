@@ -4,6 +4,9 @@ import (
 	"context"
 
 	portainer "github.com/portainer/portainer/api"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -42,3 +45,63 @@ func (kcl *KubeClient) GetNodesLimits() (portainer.K8sNodesLimits, error) {
 
 	return nodesLimits, nil
 }
+
+// GetNamespaceNodesLimits gets the CPU and Memory limits(unused resources) of all nodes in the current k8s environment(endpoint)
+// connection, capped by the CPU and Memory requests still available within the resource quotas of the namespace
+func (kcl *KubeClient) GetNamespaceNodesLimits(namespace string) (portainer.K8sNodesLimits, error) {
+	nodesLimits, err := kcl.GetNodesLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := kcl.cli.CoreV1().ResourceQuotas(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, quota := range quotas.Items {
+		cpu, hasCPU := remainingQuota(quota, v1.ResourceRequestsCPU, v1.ResourceCPU)
+		memory, hasMemory := remainingQuota(quota, v1.ResourceRequestsMemory, v1.ResourceMemory)
+
+		for _, nodeLimits := range nodesLimits {
+			if hasCPU && nodeLimits.CPU > cpu.MilliValue() {
+				nodeLimits.CPU = cpu.MilliValue()
+			}
+
+			if hasMemory && nodeLimits.Memory > memory.Value() {
+				nodeLimits.Memory = memory.Value()
+			}
+		}
+	}
+
+	return nodesLimits, nil
+}
+
+// remainingQuota returns the quantity of a resource still available within a resource quota,
+// the first of the given resource names limited by the quota is used
+func remainingQuota(quota v1.ResourceQuota, names ...v1.ResourceName) (resource.Quantity, bool) {
+	hardLimits := quota.Status.Hard
+	if len(hardLimits) == 0 {
+		hardLimits = quota.Spec.Hard
+	}
+
+	for _, name := range names {
+		hard, ok := hardLimits[name]
+		if !ok {
+			continue
+		}
+
+		remaining := hard.DeepCopy()
+		if used, ok := quota.Status.Used[name]; ok {
+			remaining.Sub(used)
+		}
+
+		if remaining.Sign() < 0 {
+			remaining = resource.Quantity{}
+		}
+
+		return remaining, true
+	}
+
+	return resource.Quantity{}, false
+}
@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxLogStreams is the maximum number of containers whose logs can be aggregated by a single request
+const maxLogStreams = 50
+
+var ErrTooManyLogStreams = errors.New("too many containers match the filter, use a more specific selector")
+
+// observedPods holds the pods matching an observability filter and the names of the objects related to them
+type observedPods struct {
+	pods []corev1.Pod
+	// objects are the names of the objects whose events are returned, keyed by namespace, nil when all the events are returned
+	objects map[string]map[string]struct{}
+}
+
+func (kcl *KubeClient) findObservedPods(ctx context.Context, filter models.K8sObservabilityFilter) (*observedPods, error) {
+	namespaces := filter.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	selectors := []string{}
+	if filter.LabelSelector != "" {
+		selectors = append(selectors, filter.LabelSelector)
+	}
+
+	result := &observedPods{}
+	if filter.Application != "" || filter.LabelSelector != "" {
+		result.objects = make(map[string]map[string]struct{})
+	}
+
+	if filter.Application != "" {
+		if len(namespaces) != 1 || namespaces[0] == metav1.NamespaceAll {
+			return nil, errors.New("an application can only be observed within a single namespace")
+		}
+
+		deployment, err := kcl.cli.AppsV1().Deployments(namespaces[0]).Get(ctx, filter.Application, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid deployment selector")
+		}
+		selectors = append(selectors, selector.String())
+
+		result.addObject(deployment.Namespace, deployment.Name)
+
+		replicaSets, err := kcl.cli.AppsV1().ReplicaSets(deployment.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range replicaSets.Items {
+			if metav1.IsControlledBy(&replicaSets.Items[i], deployment) {
+				result.addObject(deployment.Namespace, replicaSets.Items[i].Name)
+			}
+		}
+	}
+
+	for _, namespace := range namespaces {
+		pods, err := kcl.cli.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: strings.Join(selectors, ",")})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pod := range pods.Items {
+			result.pods = append(result.pods, pod)
+			result.addObject(pod.Namespace, pod.Name)
+		}
+	}
+
+	return result, nil
+}
+
+func (o *observedPods) addObject(namespace, name string) {
+	if o.objects == nil {
+		return
+	}
+
+	if o.objects[namespace] == nil {
+		o.objects[namespace] = make(map[string]struct{})
+	}
+
+	o.objects[namespace][name] = struct{}{}
+}
+
+func inTimeRange(t time.Time, filter models.K8sObservabilityFilter) bool {
+	if !filter.Since.IsZero() && t.Before(filter.Since) {
+		return false
+	}
+
+	return filter.Until.IsZero() || !t.After(filter.Until)
+}
+
+// GetEvents returns the events of an application or of namespaces, merged and ordered by their last occurrence
+func (kcl *KubeClient) GetEvents(ctx context.Context, filter models.K8sObservabilityFilter) ([]models.K8sEvent, error) {
+	observed, err := kcl.findObservedPods(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := filter.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	results := []models.K8sEvent{}
+	for _, namespace := range namespaces {
+		events, err := kcl.cli.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range events.Items {
+			if observed.objects != nil {
+				if _, ok := observed.objects[event.Namespace][event.InvolvedObject.Name]; !ok {
+					continue
+				}
+			}
+
+			result := newK8sEvent(event)
+			if !inTimeRange(result.LastTimestamp, filter) {
+				continue
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].LastTimestamp.Before(results[j].LastTimestamp)
+	})
+
+	return results, nil
+}
+
+func newK8sEvent(event corev1.Event) models.K8sEvent {
+	lastTimestamp := event.LastTimestamp.Time
+	if lastTimestamp.IsZero() {
+		lastTimestamp = event.EventTime.Time
+	}
+	if lastTimestamp.IsZero() {
+		lastTimestamp = event.CreationTimestamp.Time
+	}
+
+	firstTimestamp := event.FirstTimestamp.Time
+	if firstTimestamp.IsZero() {
+		firstTimestamp = lastTimestamp
+	}
+
+	source := event.Source.Component
+	if source == "" {
+		source = event.ReportingController
+	}
+
+	return models.K8sEvent{
+		Namespace:      event.Namespace,
+		Type:           event.Type,
+		Reason:         event.Reason,
+		Message:        event.Message,
+		Kind:           event.InvolvedObject.Kind,
+		Name:           event.InvolvedObject.Name,
+		Source:         source,
+		Count:          event.Count,
+		FirstTimestamp: firstTimestamp,
+		LastTimestamp:  lastTimestamp,
+	}
+}
+
+// StreamLogs sends the log lines of all the containers of an application or of namespaces.
+// When the logs are not followed, the lines are interleaved according to their timestamp, otherwise
+// they are sent as they are received until the context is cancelled or all the streams are closed.
+func (kcl *KubeClient) StreamLogs(ctx context.Context, filter models.K8sObservabilityFilter, send func(models.K8sLogLine) error) error {
+	observed, err := kcl.findObservedPods(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	type container struct {
+		pod  corev1.Pod
+		name string
+	}
+
+	containers := []container{}
+	for _, pod := range observed.pods {
+		for _, c := range pod.Spec.Containers {
+			containers = append(containers, container{pod: pod, name: c.Name})
+		}
+	}
+
+	if len(containers) > maxLogStreams {
+		return ErrTooManyLogStreams
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lines := make(chan models.K8sLogLine)
+	wg := sync.WaitGroup{}
+
+	for _, c := range containers {
+		wg.Add(1)
+
+		go func(pod corev1.Pod, containerName string) {
+			defer wg.Done()
+
+			err := kcl.readContainerLogs(ctx, pod, containerName, filter, lines)
+			if err != nil && ctx.Err() == nil {
+				log.Warn().Err(err).Str("namespace", pod.Namespace).Str("pod", pod.Name).Str("container", containerName).Msg("unable to read the container logs")
+			}
+		}(c.pod, c.name)
+	}
+
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	if filter.Follow {
+		for line := range lines {
+			if err := send(line); err != nil {
+				return err
+			}
+		}
+
+		return ctx.Err()
+	}
+
+	collected := []models.K8sLogLine{}
+	for line := range lines {
+		collected = append(collected, line)
+	}
+
+	sort.SliceStable(collected, func(i, j int) bool {
+		return collected[i].Timestamp.Before(collected[j].Timestamp)
+	})
+
+	for _, line := range collected {
+		if err := send(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (kcl *KubeClient) readContainerLogs(ctx context.Context, pod corev1.Pod, containerName string, filter models.K8sObservabilityFilter, lines chan<- models.K8sLogLine) error {
+	options := &corev1.PodLogOptions{
+		Container:  containerName,
+		Follow:     filter.Follow,
+		Timestamps: true,
+	}
+
+	if !filter.Since.IsZero() {
+		options.SinceTime = &metav1.Time{Time: filter.Since}
+	}
+
+	if filter.TailLines > 0 {
+		options.TailLines = &filter.TailLines
+	}
+
+	stream, err := kcl.cli.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, options).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := parseLogLine(scanner.Text())
+		line.Namespace = pod.Namespace
+		line.Pod = pod.Name
+		line.Container = containerName
+
+		if !line.Timestamp.IsZero() && !filter.Until.IsZero() && line.Timestamp.After(filter.Until) {
+			if filter.Follow {
+				return nil
+			}
+
+			continue
+		}
+
+		select {
+		case lines <- line:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseLogLine splits the timestamp added by Kubernetes from the content of a log line
+func parseLogLine(text string) models.K8sLogLine {
+	timestamp, content, found := strings.Cut(text, " ")
+	if found {
+		if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+			return models.K8sLogLine{Timestamp: t, Line: content}
+		}
+	}
+
+	return models.K8sLogLine{Line: text}
+}
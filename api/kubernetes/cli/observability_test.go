@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newObservabilityTestClient(now time.Time) *KubeClient {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps", UID: types.UID("deployment-uid")},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+	}
+
+	pod := func(name, namespace, app string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": app}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main"}}},
+		}
+	}
+
+	event := func(name, namespace, object, reason string, at time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: namespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: object, Namespace: namespace},
+			Reason:         reason,
+			LastTimestamp:  metav1.Time{Time: at},
+		}
+	}
+
+	return &KubeClient{
+		cli: kfake.NewSimpleClientset(
+			deployment,
+			pod("web-1", "apps", "web"),
+			pod("db-1", "apps", "db"),
+			pod("other-1", "other", "web"),
+			event("e1", "apps", "web-1", "BackOff", now.Add(-time.Minute)),
+			event("e2", "apps", "db-1", "Pulled", now.Add(-2*time.Minute)),
+			event("e3", "apps", "web-1", "Scheduled", now.Add(-time.Hour)),
+			event("e4", "other", "other-1", "Started", now),
+		),
+	}
+}
+
+func Test_GetEvents(t *testing.T) {
+	is := assert.New(t)
+
+	now := time.Now().Truncate(time.Second)
+	kcl := newObservabilityTestClient(now)
+
+	events, err := kcl.GetEvents(context.Background(), models.K8sObservabilityFilter{})
+	is.NoError(err)
+	is.Len(events, 4)
+
+	events, err = kcl.GetEvents(context.Background(), models.K8sObservabilityFilter{
+		Namespaces:  []string{"apps"},
+		Application: "web",
+	})
+	is.NoError(err)
+	if is.Len(events, 2) {
+		is.Equal("Scheduled", events[0].Reason)
+		is.Equal("BackOff", events[1].Reason)
+	}
+
+	events, err = kcl.GetEvents(context.Background(), models.K8sObservabilityFilter{
+		Namespaces: []string{"apps"},
+		Since:      now.Add(-5 * time.Minute),
+	})
+	is.NoError(err)
+	if is.Len(events, 2) {
+		is.Equal("Pulled", events[0].Reason)
+		is.Equal("BackOff", events[1].Reason)
+	}
+
+	events, err = kcl.GetEvents(context.Background(), models.K8sObservabilityFilter{LabelSelector: "app=web"})
+	is.NoError(err)
+	is.Len(events, 3)
+
+	_, err = kcl.GetEvents(context.Background(), models.K8sObservabilityFilter{Application: "web"})
+	is.Error(err)
+}
+
+func Test_StreamLogs(t *testing.T) {
+	is := assert.New(t)
+
+	kcl := newObservabilityTestClient(time.Now())
+
+	lines := []models.K8sLogLine{}
+	err := kcl.StreamLogs(context.Background(), models.K8sObservabilityFilter{LabelSelector: "app=web"}, func(line models.K8sLogLine) error {
+		lines = append(lines, line)
+		return nil
+	})
+	is.NoError(err)
+
+	pods := []string{}
+	for _, line := range lines {
+		pods = append(pods, line.Pod)
+		is.Equal("main", line.Container)
+	}
+	is.ElementsMatch([]string{"web-1", "other-1"}, pods)
+}
+
+func Test_parseLogLine(t *testing.T) {
+	is := assert.New(t)
+
+	line := parseLogLine("2024-01-02T03:04:05.123456789Z hello world")
+	is.Equal("hello world", line.Line)
+	is.Equal(time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC), line.Timestamp)
+
+	line = parseLogLine("hello world")
+	is.Equal("hello world", line.Line)
+	is.True(line.Timestamp.IsZero())
+}
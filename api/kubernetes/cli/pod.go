@@ -3,6 +3,8 @@ package cli
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	portainer "github.com/portainer/portainer/api"
@@ -110,3 +112,28 @@ func (kcl *KubeClient) waitForPodStatus(ctx context.Context, phase v1.PodPhase,
 		}
 	}
 }
+
+// GetRunningContainerImages returns the images of the containers of the running pods of all the namespaces
+func (kcl *KubeClient) GetRunningContainerImages(ctx context.Context) ([]string, error) {
+	pods, err := kcl.cli.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list the running pods")
+	}
+
+	images := []string{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+
+		for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			if !slices.Contains(images, container.Image) {
+				images = append(images, container.Image)
+			}
+		}
+	}
+
+	sort.Strings(images)
+
+	return images, nil
+}
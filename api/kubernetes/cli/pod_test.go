@@ -66,3 +66,38 @@ func Test_waitForPodStatus(t *testing.T) {
 	})
 
 }
+
+func Test_GetRunningContainerImages(t *testing.T) {
+	k := &KubeClient{
+		cli: kfake.NewSimpleClientset(
+			&v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{{Name: "init", Image: "busybox:1.36"}},
+					Containers:     []v1.Container{{Name: "nginx", Image: "nginx:1.25"}},
+				},
+				Status: v1.PodStatus{Phase: v1.PodRunning},
+			},
+			&v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "apps"},
+				Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "nginx", Image: "nginx:1.25"}}},
+				Status:     v1.PodStatus{Phase: v1.PodRunning},
+			},
+			&v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "default"},
+				Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "migrate", Image: "migrate:4"}}},
+				Status:     v1.PodStatus{Phase: v1.PodSucceeded},
+			},
+		),
+		instanceID: "test",
+	}
+
+	images, err := k.GetRunningContainerImages(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(images) != 2 || images[0] != "busybox:1.36" || images[1] != "nginx:1.25" {
+		t.Errorf("unexpected images: %v", images)
+	}
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"context"
+	"sort"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func resourceQuotaName(namespace string) string {
+	return "portainer-rq-" + namespace
+}
+
+func limitRangeName(namespace string) string {
+	return "portainer-lr-" + namespace
+}
+
+// applyNamespaceResourceQuota creates, updates or removes the ResourceQuota managed by Portainer in a namespace.
+// The existing quota is left unchanged when quota is nil, it is removed when quota sets no limit.
+func (kcl *KubeClient) applyNamespaceResourceQuota(namespace string, quota *models.K8sNamespaceResourceQuota) error {
+	if quota == nil {
+		return nil
+	}
+
+	client := kcl.cli.CoreV1().ResourceQuotas(namespace)
+	name := resourceQuotaName(namespace)
+
+	hard := buildResourceQuotaHardLimits(quota)
+
+	existing, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed fetching resource quota")
+	}
+
+	if len(hard) == 0 {
+		if err != nil {
+			return nil
+		}
+
+		return client.Delete(context.TODO(), name, metav1.DeleteOptions{})
+	}
+
+	if err != nil {
+		_, err = client.Create(context.TODO(), &v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "portainer"},
+			},
+			Spec: v1.ResourceQuotaSpec{Hard: hard},
+		}, metav1.CreateOptions{})
+		return errors.Wrap(err, "failed creating resource quota")
+	}
+
+	existing.Spec.Hard = hard
+	_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+	return errors.Wrap(err, "failed updating resource quota")
+}
+
+func buildResourceQuotaHardLimits(quota *models.K8sNamespaceResourceQuota) v1.ResourceList {
+	hard := v1.ResourceList{}
+
+	if quota.CPU != "" {
+		cpu := resource.MustParse(quota.CPU)
+		hard[v1.ResourceRequestsCPU] = cpu
+		hard[v1.ResourceLimitsCPU] = cpu
+	}
+
+	if quota.Memory != "" {
+		memory := resource.MustParse(quota.Memory)
+		hard[v1.ResourceRequestsMemory] = memory
+		hard[v1.ResourceLimitsMemory] = memory
+	}
+
+	if quota.Storage != "" {
+		hard[v1.ResourceRequestsStorage] = resource.MustParse(quota.Storage)
+	}
+
+	counts := map[v1.ResourceName]int64{
+		v1.ResourcePods:                   quota.Pods,
+		v1.ResourceServices:               quota.Services,
+		v1.ResourceConfigMaps:             quota.ConfigMaps,
+		v1.ResourceSecrets:                quota.Secrets,
+		v1.ResourcePersistentVolumeClaims: quota.PersistentVolumeClaims,
+	}
+	for name, count := range counts {
+		if count > 0 {
+			hard[name] = *resource.NewQuantity(count, resource.DecimalSI)
+		}
+	}
+
+	return hard
+}
+
+// applyNamespaceLimitRange creates, updates or removes the LimitRange managed by Portainer in a namespace.
+// The existing limit range is left unchanged when limitRange is nil, it is removed when limitRange sets no default.
+func (kcl *KubeClient) applyNamespaceLimitRange(namespace string, limitRange *models.K8sNamespaceLimitRange) error {
+	if limitRange == nil {
+		return nil
+	}
+
+	client := kcl.cli.CoreV1().LimitRanges(namespace)
+	name := limitRangeName(namespace)
+
+	item := v1.LimitRangeItem{
+		Type:           v1.LimitTypeContainer,
+		Default:        v1.ResourceList{},
+		DefaultRequest: v1.ResourceList{},
+	}
+	setQuantity(item.Default, v1.ResourceCPU, limitRange.DefaultCPU)
+	setQuantity(item.Default, v1.ResourceMemory, limitRange.DefaultMemory)
+	setQuantity(item.DefaultRequest, v1.ResourceCPU, limitRange.DefaultRequestCPU)
+	setQuantity(item.DefaultRequest, v1.ResourceMemory, limitRange.DefaultRequestMemory)
+
+	existing, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed fetching limit range")
+	}
+
+	if len(item.Default) == 0 && len(item.DefaultRequest) == 0 {
+		if err != nil {
+			return nil
+		}
+
+		return client.Delete(context.TODO(), name, metav1.DeleteOptions{})
+	}
+
+	if err != nil {
+		_, err = client.Create(context.TODO(), &v1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "portainer"},
+			},
+			Spec: v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{item}},
+		}, metav1.CreateOptions{})
+		return errors.Wrap(err, "failed creating limit range")
+	}
+
+	existing.Spec.Limits = []v1.LimitRangeItem{item}
+	_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+	return errors.Wrap(err, "failed updating limit range")
+}
+
+func setQuantity(list v1.ResourceList, name v1.ResourceName, value string) {
+	if value != "" {
+		list[name] = resource.MustParse(value)
+	}
+}
+
+// GetNamespaceResourceQuotas returns the usage of all the resource quotas of a namespace compared with their hard limits
+func (kcl *KubeClient) GetNamespaceResourceQuotas(namespace string) ([]models.K8sResourceQuotaUsage, error) {
+	quotas, err := kcl.cli.CoreV1().ResourceQuotas(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.K8sResourceQuotaUsage, 0, len(quotas.Items))
+	for _, quota := range quotas.Items {
+		usage := models.K8sResourceQuotaUsage{
+			Name: quota.Name,
+			Hard: make(map[string]string),
+			Used: make(map[string]string),
+		}
+
+		for name, quantity := range quota.Status.Hard {
+			usage.Hard[string(name)] = quantity.String()
+		}
+
+		// the status is only filled by the quota controller, fall back to the spec until it has been reconciled
+		if len(quota.Status.Hard) == 0 {
+			for name, quantity := range quota.Spec.Hard {
+				usage.Hard[string(name)] = quantity.String()
+			}
+		}
+
+		for name, quantity := range quota.Status.Used {
+			usage.Used[string(name)] = quantity.String()
+		}
+
+		results = append(results, usage)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	return results, nil
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_NamespaceResourceQuota(t *testing.T) {
+	is := assert.New(t)
+
+	kcl := &KubeClient{
+		cli:        kfake.NewSimpleClientset(),
+		instanceID: "instance",
+	}
+
+	err := kcl.CreateNamespace(models.K8sNamespaceDetails{
+		Name: "tenant",
+		ResourceQuota: &models.K8sNamespaceResourceQuota{
+			CPU:    "2",
+			Memory: "4Gi",
+			Pods:   10,
+		},
+		LimitRange: &models.K8sNamespaceLimitRange{
+			DefaultCPU:        "500m",
+			DefaultRequestCPU: "100m",
+		},
+	})
+	is.NoError(err)
+
+	quota, err := kcl.cli.CoreV1().ResourceQuotas("tenant").Get(context.Background(), resourceQuotaName("tenant"), metav1.GetOptions{})
+	is.NoError(err)
+	is.Equal("2", quota.Spec.Hard.Name(v1.ResourceRequestsCPU, resource.DecimalSI).String())
+	is.Equal("2", quota.Spec.Hard.Name(v1.ResourceLimitsCPU, resource.DecimalSI).String())
+	is.Equal("4Gi", quota.Spec.Hard.Name(v1.ResourceRequestsMemory, resource.BinarySI).String())
+	is.Equal("10", quota.Spec.Hard.Name(v1.ResourcePods, resource.DecimalSI).String())
+	is.NotContains(quota.Spec.Hard, v1.ResourceRequestsStorage)
+
+	limitRange, err := kcl.cli.CoreV1().LimitRanges("tenant").Get(context.Background(), limitRangeName("tenant"), metav1.GetOptions{})
+	is.NoError(err)
+	if is.Len(limitRange.Spec.Limits, 1) {
+		is.Equal("500m", limitRange.Spec.Limits[0].Default.Cpu().String())
+		is.Equal("100m", limitRange.Spec.Limits[0].DefaultRequest.Cpu().String())
+	}
+
+	quotas, err := kcl.GetNamespaceResourceQuotas("tenant")
+	is.NoError(err)
+	if is.Len(quotas, 1) {
+		is.Equal("4Gi", quotas[0].Hard[string(v1.ResourceLimitsMemory)])
+	}
+
+	// namespace details without a quota and a limit range leave them unchanged
+	err = kcl.UpdateNamespace(models.K8sNamespaceDetails{Name: "tenant"})
+	is.NoError(err)
+
+	quotaList, err := kcl.cli.CoreV1().ResourceQuotas("tenant").List(context.Background(), metav1.ListOptions{})
+	is.NoError(err)
+	is.Len(quotaList.Items, 1)
+
+	limitRangeList, err := kcl.cli.CoreV1().LimitRanges("tenant").List(context.Background(), metav1.ListOptions{})
+	is.NoError(err)
+	is.Len(limitRangeList.Items, 1)
+
+	// an empty quota and an empty limit range delete them
+	err = kcl.UpdateNamespace(models.K8sNamespaceDetails{
+		Name:          "tenant",
+		ResourceQuota: &models.K8sNamespaceResourceQuota{},
+		LimitRange:    &models.K8sNamespaceLimitRange{},
+	})
+	is.NoError(err)
+
+	quotaList, err = kcl.cli.CoreV1().ResourceQuotas("tenant").List(context.Background(), metav1.ListOptions{})
+	is.NoError(err)
+	is.Empty(quotaList.Items)
+
+	limitRangeList, err = kcl.cli.CoreV1().LimitRanges("tenant").List(context.Background(), metav1.ListOptions{})
+	is.NoError(err)
+	is.Empty(limitRangeList.Items)
+}
+
+func Test_GetNamespaceNodesLimits(t *testing.T) {
+	quota := &v1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "test-namespace-0"},
+		Spec: v1.ResourceQuotaSpec{
+			Hard: v1.ResourceList{
+				v1.ResourceRequestsCPU: resource.MustParse("2"),
+			},
+		},
+		Status: v1.ResourceQuotaStatus{
+			Hard: v1.ResourceList{
+				v1.ResourceRequestsCPU: resource.MustParse("2"),
+			},
+			Used: v1.ResourceList{
+				v1.ResourceRequestsCPU: resource.MustParse("1500m"),
+			},
+		},
+	}
+
+	kcl := &KubeClient{
+		cli: kfake.NewSimpleClientset(newNodes(), newPods(), quota),
+	}
+
+	got, err := kcl.GetNamespaceNodesLimits("test-namespace-0")
+	assert.NoError(t, err)
+	assert.Equal(t, portainer.K8sNodesLimits{
+		"test-node-0": &portainer.K8sNodeLimits{CPU: 500, Memory: 2000000},
+		"test-node-1": &portainer.K8sNodeLimits{CPU: 500, Memory: 3000000},
+	}, got)
+
+	got, err = kcl.GetNamespaceNodesLimits("test-namespace-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), got["test-node-0"].CPU)
+}
@@ -0,0 +1,476 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	WorkloadKindDeployment  = "deployments"
+	WorkloadKindStatefulSet = "statefulsets"
+	WorkloadKindDaemonSet   = "daemonsets"
+
+	changeCauseAnnotation    = "kubernetes.io/change-cause"
+	rolloutUserAnnotation    = "io.portainer.kubernetes.rollout.user"
+	pausedStrategyAnnotation = "io.portainer.kubernetes.rollout.paused-strategy"
+	restartedAtAnnotation    = "kubectl.kubernetes.io/restartedAt"
+	deploymentRevisionKey    = "deployment.kubernetes.io/revision"
+	podTemplateHashLabel     = "pod-template-hash"
+)
+
+var (
+	ErrUnsupportedWorkloadKind = errors.New("unsupported workload kind")
+	ErrWorkloadNotScalable     = errors.New("daemonsets cannot be scaled")
+	ErrWorkloadNotPausable     = errors.New("statefulsets using the OnDelete update strategy cannot be paused")
+	ErrRevisionNotFound        = errors.New("revision not found")
+)
+
+// IsValidWorkloadKind returns true when rollout operations are supported for the kind of workload
+func IsValidWorkloadKind(kind string) bool {
+	return kind == WorkloadKindDeployment || kind == WorkloadKindStatefulSet || kind == WorkloadKindDaemonSet
+}
+
+// workload gives a common access to the fields of deployments, statefulsets and daemonsets used by the rollout operations
+type workload interface {
+	meta() *metav1.ObjectMeta
+	template() *corev1.PodTemplateSpec
+	scale(replicas int32) error
+	pause(paused bool) error
+}
+
+type deploymentWorkload struct{ *appsv1.Deployment }
+
+func (w deploymentWorkload) meta() *metav1.ObjectMeta          { return &w.ObjectMeta }
+func (w deploymentWorkload) template() *corev1.PodTemplateSpec { return &w.Spec.Template }
+
+func (w deploymentWorkload) scale(replicas int32) error {
+	w.Spec.Replicas = &replicas
+	return nil
+}
+
+func (w deploymentWorkload) pause(paused bool) error {
+	w.Spec.Paused = paused
+	return nil
+}
+
+type statefulSetWorkload struct{ *appsv1.StatefulSet }
+
+func (w statefulSetWorkload) meta() *metav1.ObjectMeta          { return &w.ObjectMeta }
+func (w statefulSetWorkload) template() *corev1.PodTemplateSpec { return &w.Spec.Template }
+
+func (w statefulSetWorkload) scale(replicas int32) error {
+	w.Spec.Replicas = &replicas
+	return nil
+}
+
+// pause stops the rolling update of a statefulset by moving its partition above the number of replicas,
+// the previous partition is restored when the rollout is resumed
+func (w statefulSetWorkload) pause(paused bool) error {
+	if w.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return ErrWorkloadNotPausable
+	}
+
+	previous, isPaused := w.Annotations[pausedStrategyAnnotation]
+	if paused == isPaused {
+		return nil
+	}
+
+	if w.Spec.UpdateStrategy.RollingUpdate == nil {
+		w.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{}
+	}
+	rollingUpdate := w.Spec.UpdateStrategy.RollingUpdate
+
+	if !paused {
+		partition, err := strconv.ParseInt(previous, 10, 32)
+		if err != nil {
+			partition = 0
+		}
+		rollingUpdate.Partition = int32Ptr(int32(partition))
+		delete(w.Annotations, pausedStrategyAnnotation)
+
+		return nil
+	}
+
+	partition := int32(0)
+	if rollingUpdate.Partition != nil {
+		partition = *rollingUpdate.Partition
+	}
+
+	replicas := int32(1)
+	if w.Spec.Replicas != nil {
+		replicas = *w.Spec.Replicas
+	}
+
+	setAnnotation(&w.ObjectMeta, pausedStrategyAnnotation, strconv.Itoa(int(partition)))
+	rollingUpdate.Partition = int32Ptr(replicas)
+
+	return nil
+}
+
+type daemonSetWorkload struct{ *appsv1.DaemonSet }
+
+func (w daemonSetWorkload) meta() *metav1.ObjectMeta          { return &w.ObjectMeta }
+func (w daemonSetWorkload) template() *corev1.PodTemplateSpec { return &w.Spec.Template }
+
+func (w daemonSetWorkload) scale(replicas int32) error {
+	return ErrWorkloadNotScalable
+}
+
+// pause stops the rolling update of a daemonset by switching it to the OnDelete update strategy,
+// the previous update strategy is restored when the rollout is resumed
+func (w daemonSetWorkload) pause(paused bool) error {
+	previous, isPaused := w.Annotations[pausedStrategyAnnotation]
+	if paused == isPaused {
+		return nil
+	}
+
+	if !paused {
+		var strategy appsv1.DaemonSetUpdateStrategy
+		if err := json.Unmarshal([]byte(previous), &strategy); err != nil {
+			strategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType}
+		}
+		w.Spec.UpdateStrategy = strategy
+		delete(w.Annotations, pausedStrategyAnnotation)
+
+		return nil
+	}
+
+	data, err := json.Marshal(w.Spec.UpdateStrategy)
+	if err != nil {
+		return err
+	}
+
+	setAnnotation(&w.ObjectMeta, pausedStrategyAnnotation, string(data))
+	w.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+
+	return nil
+}
+
+// updateWorkload fetches a workload, applies the update function to it and persists it
+func (kcl *KubeClient) updateWorkload(namespace, kind, name string, update func(w workload) error) error {
+	ctx := context.TODO()
+
+	switch kind {
+	case WorkloadKindDeployment:
+		client := kcl.cli.AppsV1().Deployments(namespace)
+		deployment, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if err := update(deploymentWorkload{deployment}); err != nil {
+			return err
+		}
+
+		_, err = client.Update(ctx, deployment, metav1.UpdateOptions{})
+		return err
+	case WorkloadKindStatefulSet:
+		client := kcl.cli.AppsV1().StatefulSets(namespace)
+		statefulSet, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if err := update(statefulSetWorkload{statefulSet}); err != nil {
+			return err
+		}
+
+		_, err = client.Update(ctx, statefulSet, metav1.UpdateOptions{})
+		return err
+	case WorkloadKindDaemonSet:
+		client := kcl.cli.AppsV1().DaemonSets(namespace)
+		daemonSet, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if err := update(daemonSetWorkload{daemonSet}); err != nil {
+			return err
+		}
+
+		_, err = client.Update(ctx, daemonSet, metav1.UpdateOptions{})
+		return err
+	}
+
+	return ErrUnsupportedWorkloadKind
+}
+
+// recordRolloutChange stores the user who performed a rollout operation and the cause of the change on the workload.
+// The change cause is copied by Kubernetes to the revisions created by the operation.
+func recordRolloutChange(w workload, username, cause string) {
+	setAnnotation(w.meta(), rolloutUserAnnotation, username)
+	setAnnotation(w.meta(), changeCauseAnnotation, fmt.Sprintf("%s by %s", cause, username))
+}
+
+// RestartWorkload triggers a rolling restart of the pods of a workload
+func (kcl *KubeClient) RestartWorkload(namespace, kind, name, username string) error {
+	return kcl.updateWorkload(namespace, kind, name, func(w workload) error {
+		if w.template().Annotations == nil {
+			w.template().Annotations = map[string]string{}
+		}
+		w.template().Annotations[restartedAtAnnotation] = time.Now().Format(time.RFC3339)
+
+		recordRolloutChange(w, username, "restart")
+		return nil
+	})
+}
+
+// ScaleWorkload updates the number of replicas of a deployment or a statefulset
+func (kcl *KubeClient) ScaleWorkload(namespace, kind, name string, replicas int32, username string) error {
+	return kcl.updateWorkload(namespace, kind, name, func(w workload) error {
+		if err := w.scale(replicas); err != nil {
+			return err
+		}
+
+		recordRolloutChange(w, username, fmt.Sprintf("scale to %d replicas", replicas))
+		return nil
+	})
+}
+
+// PauseWorkloadRollout pauses or resumes the rollout of a workload
+func (kcl *KubeClient) PauseWorkloadRollout(namespace, kind, name string, paused bool, username string) error {
+	return kcl.updateWorkload(namespace, kind, name, func(w workload) error {
+		if err := w.pause(paused); err != nil {
+			return err
+		}
+
+		cause := "resume"
+		if paused {
+			cause = "pause"
+		}
+
+		recordRolloutChange(w, username, cause)
+		return nil
+	})
+}
+
+// RollbackWorkload rolls a workload back to the pod template of one of its revisions
+func (kcl *KubeClient) RollbackWorkload(namespace, kind, name string, revision int64, username string) error {
+	revisions, err := kcl.workloadRevisions(namespace, kind, name)
+	if err != nil {
+		return err
+	}
+
+	var template *corev1.PodTemplateSpec
+	for _, r := range revisions {
+		if r.Revision == revision {
+			template = r.template
+			break
+		}
+	}
+
+	if template == nil {
+		return ErrRevisionNotFound
+	}
+
+	return kcl.updateWorkload(namespace, kind, name, func(w workload) error {
+		*w.template() = *template
+
+		recordRolloutChange(w, username, fmt.Sprintf("rollback to revision %d", revision))
+		return nil
+	})
+}
+
+// GetWorkloadRolloutHistory returns the revisions of a workload, ordered from the oldest to the newest one
+func (kcl *KubeClient) GetWorkloadRolloutHistory(namespace, kind, name string) ([]models.K8sRolloutRevision, error) {
+	revisions, err := kcl.workloadRevisions(namespace, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]models.K8sRolloutRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		history = append(history, revision.K8sRolloutRevision)
+	}
+
+	return history, nil
+}
+
+type workloadRevision struct {
+	models.K8sRolloutRevision
+	template *corev1.PodTemplateSpec
+}
+
+// workloadRevisions collects the ReplicaSets of a deployment or the ControllerRevisions of a statefulset or a daemonset
+func (kcl *KubeClient) workloadRevisions(namespace, kind, name string) ([]workloadRevision, error) {
+	var revisions []workloadRevision
+	var err error
+
+	switch kind {
+	case WorkloadKindDeployment:
+		revisions, err = kcl.deploymentRevisions(namespace, name)
+	case WorkloadKindStatefulSet:
+		revisions, err = kcl.statefulSetRevisions(namespace, name)
+	case WorkloadKindDaemonSet:
+		revisions, err = kcl.daemonSetRevisions(namespace, name)
+	default:
+		return nil, ErrUnsupportedWorkloadKind
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+
+	return revisions, nil
+}
+
+func (kcl *KubeClient) deploymentRevisions(namespace, name string) ([]workloadRevision, error) {
+	deployment, err := kcl.cli.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid deployment selector")
+	}
+
+	replicaSets, err := kcl.cli.AppsV1().ReplicaSets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	currentRevision := deployment.Annotations[deploymentRevisionKey]
+
+	revisions := []workloadRevision{}
+	for i := range replicaSets.Items {
+		replicaSet := &replicaSets.Items[i]
+		if !metav1.IsControlledBy(replicaSet, deployment) {
+			continue
+		}
+
+		revision, err := strconv.ParseInt(replicaSet.Annotations[deploymentRevisionKey], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		template := replicaSet.Spec.Template.DeepCopy()
+		delete(template.Labels, podTemplateHashLabel)
+
+		revisions = append(revisions, newWorkloadRevision(replicaSet.ObjectMeta, revision, template, replicaSet.Annotations[deploymentRevisionKey] == currentRevision))
+	}
+
+	return revisions, nil
+}
+
+func (kcl *KubeClient) statefulSetRevisions(namespace, name string) ([]workloadRevision, error) {
+	statefulSet, err := kcl.cli.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := kcl.controllerRevisions(namespace, statefulSet, statefulSet.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range revisions {
+		revisions[i].Current = revisions[i].Name == statefulSet.Status.UpdateRevision
+	}
+
+	return revisions, nil
+}
+
+func (kcl *KubeClient) daemonSetRevisions(namespace, name string) ([]workloadRevision, error) {
+	daemonSet, err := kcl.cli.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := kcl.controllerRevisions(namespace, daemonSet, daemonSet.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	// the daemonset controller always runs the latest revision
+	var latest *workloadRevision
+	for i := range revisions {
+		if latest == nil || revisions[i].Revision > latest.Revision {
+			latest = &revisions[i]
+		}
+	}
+	if latest != nil {
+		latest.Current = true
+	}
+
+	return revisions, nil
+}
+
+func (kcl *KubeClient) controllerRevisions(namespace string, owner metav1.Object, labelSelector *metav1.LabelSelector) ([]workloadRevision, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid workload selector")
+	}
+
+	controllerRevisions, err := kcl.cli.AppsV1().ControllerRevisions(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []workloadRevision{}
+	for i := range controllerRevisions.Items {
+		controllerRevision := &controllerRevisions.Items[i]
+		if !metav1.IsControlledBy(controllerRevision, owner) {
+			continue
+		}
+
+		// controller revisions store the pod template of the workload as a patch of its spec
+		var data struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(controllerRevision.Data.Raw, &data); err != nil {
+			continue
+		}
+
+		revisions = append(revisions, newWorkloadRevision(controllerRevision.ObjectMeta, controllerRevision.Revision, &data.Spec.Template, false))
+	}
+
+	return revisions, nil
+}
+
+func newWorkloadRevision(meta metav1.ObjectMeta, revision int64, template *corev1.PodTemplateSpec, current bool) workloadRevision {
+	images := []string{}
+	for _, container := range template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+
+	return workloadRevision{
+		K8sRolloutRevision: models.K8sRolloutRevision{
+			Revision:     revision,
+			Name:         meta.Name,
+			ChangeCause:  meta.Annotations[changeCauseAnnotation],
+			Images:       images,
+			Current:      current,
+			CreationDate: meta.CreationTimestamp.Time,
+		},
+		template: template,
+	}
+}
+
+func setAnnotation(meta *metav1.ObjectMeta, key, value string) {
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+
+	meta.Annotations[key] = value
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func podTemplate(image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "web", Image: image}},
+		},
+	}
+}
+
+func newRolloutTestClient() *KubeClient {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			UID:         types.UID("deployment-uid"),
+			Annotations: map[string]string{deploymentRevisionKey: "2"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(1),
+			Selector: selector,
+			Template: podTemplate("nginx:1.25"),
+		},
+	}
+
+	replicaSet := func(name, revision, image string) *appsv1.ReplicaSet {
+		template := podTemplate(image)
+		template.Labels[podTemplateHashLabel] = name
+
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "default",
+				Labels:          map[string]string{"app": "web"},
+				Annotations:     map[string]string{deploymentRevisionKey: revision},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+			},
+			Spec: appsv1.ReplicaSetSpec{Selector: selector, Template: template},
+		}
+	}
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: types.UID("statefulset-uid")},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: int32Ptr(3),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.RollingUpdateStatefulSetStrategyType,
+			},
+		},
+		Status: appsv1.StatefulSetStatus{UpdateRevision: "db-2"},
+	}
+
+	controllerRevision := func(name string, revision int64, image string) *appsv1.ControllerRevision {
+		var data struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}
+		data.Spec.Template = podTemplate(image)
+		raw, _ := json.Marshal(data)
+
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "default",
+				Labels:          map[string]string{"app": "db"},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(statefulSet, appsv1.SchemeGroupVersion.WithKind("StatefulSet"))},
+			},
+			Data:     runtime.RawExtension{Raw: raw},
+			Revision: revision,
+		}
+	}
+
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"},
+		Spec: appsv1.DaemonSetSpec{
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType},
+		},
+	}
+
+	return &KubeClient{
+		cli: kfake.NewSimpleClientset(
+			deployment,
+			replicaSet("web-1", "1", "nginx:1.24"),
+			replicaSet("web-2", "2", "nginx:1.25"),
+			statefulSet,
+			controllerRevision("db-1", 1, "postgres:15"),
+			controllerRevision("db-2", 2, "postgres:16"),
+			daemonSet,
+		),
+	}
+}
+
+func Test_RolloutHistoryAndRollback(t *testing.T) {
+	is := assert.New(t)
+	kcl := newRolloutTestClient()
+
+	history, err := kcl.GetWorkloadRolloutHistory("default", WorkloadKindDeployment, "web")
+	is.NoError(err)
+	if is.Len(history, 2) {
+		is.Equal(int64(1), history[0].Revision)
+		is.Equal([]string{"nginx:1.24"}, history[0].Images)
+		is.False(history[0].Current)
+		is.True(history[1].Current)
+	}
+
+	is.NoError(kcl.RollbackWorkload("default", WorkloadKindDeployment, "web", 1, "alice"))
+
+	deployment, err := kcl.cli.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	is.NoError(err)
+	is.Equal("nginx:1.24", deployment.Spec.Template.Spec.Containers[0].Image)
+	is.NotContains(deployment.Spec.Template.Labels, podTemplateHashLabel)
+	is.Equal("alice", deployment.Annotations[rolloutUserAnnotation])
+	is.Equal("rollback to revision 1 by alice", deployment.Annotations[changeCauseAnnotation])
+
+	is.ErrorIs(kcl.RollbackWorkload("default", WorkloadKindDeployment, "web", 5, "alice"), ErrRevisionNotFound)
+
+	history, err = kcl.GetWorkloadRolloutHistory("default", WorkloadKindStatefulSet, "db")
+	is.NoError(err)
+	if is.Len(history, 2) {
+		is.True(history[1].Current)
+		is.Equal([]string{"postgres:15"}, history[0].Images)
+	}
+
+	is.NoError(kcl.RollbackWorkload("default", WorkloadKindStatefulSet, "db", 1, "alice"))
+
+	statefulSet, err := kcl.cli.AppsV1().StatefulSets("default").Get(context.Background(), "db", metav1.GetOptions{})
+	is.NoError(err)
+	is.Equal("postgres:15", statefulSet.Spec.Template.Spec.Containers[0].Image)
+}
+
+func Test_RolloutOperations(t *testing.T) {
+	is := assert.New(t)
+	kcl := newRolloutTestClient()
+
+	is.NoError(kcl.RestartWorkload("default", WorkloadKindDeployment, "web", "bob"))
+	is.NoError(kcl.ScaleWorkload("default", WorkloadKindDeployment, "web", 4, "bob"))
+
+	deployment, err := kcl.cli.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	is.NoError(err)
+	is.Contains(deployment.Spec.Template.Annotations, restartedAtAnnotation)
+	is.Equal(int32(4), *deployment.Spec.Replicas)
+	is.Equal("scale to 4 replicas by bob", deployment.Annotations[changeCauseAnnotation])
+
+	is.ErrorIs(kcl.ScaleWorkload("default", WorkloadKindDaemonSet, "agent", 2, "bob"), ErrWorkloadNotScalable)
+	is.ErrorIs(kcl.RestartWorkload("default", "jobs", "web", "bob"), ErrUnsupportedWorkloadKind)
+
+	t.Run("pause and resume a statefulset", func(t *testing.T) {
+		is.NoError(kcl.PauseWorkloadRollout("default", WorkloadKindStatefulSet, "db", true, "bob"))
+
+		statefulSet, err := kcl.cli.AppsV1().StatefulSets("default").Get(context.Background(), "db", metav1.GetOptions{})
+		is.NoError(err)
+		is.Equal(int32(3), *statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition)
+
+		is.NoError(kcl.PauseWorkloadRollout("default", WorkloadKindStatefulSet, "db", false, "bob"))
+
+		statefulSet, err = kcl.cli.AppsV1().StatefulSets("default").Get(context.Background(), "db", metav1.GetOptions{})
+		is.NoError(err)
+		is.Equal(int32(0), *statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition)
+		is.NotContains(statefulSet.Annotations, pausedStrategyAnnotation)
+	})
+
+	t.Run("pause and resume a daemonset", func(t *testing.T) {
+		is.NoError(kcl.PauseWorkloadRollout("default", WorkloadKindDaemonSet, "agent", true, "bob"))
+
+		daemonSet, err := kcl.cli.AppsV1().DaemonSets("default").Get(context.Background(), "agent", metav1.GetOptions{})
+		is.NoError(err)
+		is.Equal(appsv1.OnDeleteDaemonSetStrategyType, daemonSet.Spec.UpdateStrategy.Type)
+
+		is.NoError(kcl.PauseWorkloadRollout("default", WorkloadKindDaemonSet, "agent", false, "bob"))
+
+		daemonSet, err = kcl.cli.AppsV1().DaemonSets("default").Get(context.Background(), "agent", metav1.GetOptions{})
+		is.NoError(err)
+		is.Equal(appsv1.RollingUpdateDaemonSetStrategyType, daemonSet.Spec.UpdateStrategy.Type)
+	})
+}
@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	volumeSnapshotGroup        = "snapshot.storage.k8s.io"
+	volumeSnapshotGroupVersion = volumeSnapshotGroup + "/v1"
+	volumeSnapshotKind         = "VolumeSnapshot"
+)
+
+// ErrVolumeSnapshotsUnavailable is returned when the VolumeSnapshot CRDs are not installed in the cluster
+var ErrVolumeSnapshotsUnavailable = errors.New("volume snapshots are not available, the VolumeSnapshot CRDs are not installed in the cluster")
+
+type (
+	// volumeSnapshot is a snapshot.storage.k8s.io/v1 VolumeSnapshot, the CRDs are optional
+	// so the snapshots are managed through raw requests rather than a generated clientset
+	volumeSnapshot struct {
+		metav1.TypeMeta   `json:",inline"`
+		metav1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              volumeSnapshotSpec    `json:"spec"`
+		Status            *volumeSnapshotStatus `json:"status,omitempty"`
+	}
+
+	volumeSnapshotSpec struct {
+		Source struct {
+			PersistentVolumeClaimName *string `json:"persistentVolumeClaimName,omitempty"`
+			VolumeSnapshotContentName *string `json:"volumeSnapshotContentName,omitempty"`
+		} `json:"source"`
+		VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+	}
+
+	volumeSnapshotStatus struct {
+		ReadyToUse  *bool              `json:"readyToUse,omitempty"`
+		RestoreSize *resource.Quantity `json:"restoreSize,omitempty"`
+		Error       *struct {
+			Message *string `json:"message,omitempty"`
+		} `json:"error,omitempty"`
+	}
+
+	volumeSnapshotList struct {
+		Items []volumeSnapshot `json:"items"`
+	}
+)
+
+// GetVolumeSnapshots gets the volume snapshots of a namespace
+func (kcl *KubeClient) GetVolumeSnapshots(namespace string) ([]models.K8sVolumeSnapshot, error) {
+	if err := kcl.checkVolumeSnapshotsAvailable(); err != nil {
+		return nil, err
+	}
+
+	data, err := kcl.cli.CoreV1().RESTClient().Get().AbsPath(volumeSnapshotsPath(namespace)...).DoRaw(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots volumeSnapshotList
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, errors.Wrap(err, "failed parsing volume snapshots")
+	}
+
+	results := make([]models.K8sVolumeSnapshot, 0, len(snapshots.Items))
+	for _, snapshot := range snapshots.Items {
+		results = append(results, parseVolumeSnapshot(snapshot))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	return results, nil
+}
+
+// CreateVolumeSnapshot creates a snapshot of a persistent volume claim
+func (kcl *KubeClient) CreateVolumeSnapshot(namespace, claimName string, payload models.K8sVolumeSnapshotPayload) (models.K8sVolumeSnapshot, error) {
+	if err := kcl.checkVolumeSnapshotsAvailable(); err != nil {
+		return models.K8sVolumeSnapshot{}, err
+	}
+
+	if _, err := kcl.cli.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), claimName, metav1.GetOptions{}); err != nil {
+		return models.K8sVolumeSnapshot{}, err
+	}
+
+	snapshot := volumeSnapshot{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: volumeSnapshotGroupVersion,
+			Kind:       volumeSnapshotKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      payload.Name,
+			Namespace: namespace,
+		},
+	}
+	snapshot.Spec.Source.PersistentVolumeClaimName = &claimName
+	if payload.SnapshotClass != "" {
+		snapshot.Spec.VolumeSnapshotClassName = &payload.SnapshotClass
+	}
+
+	body, err := json.Marshal(snapshot)
+	if err != nil {
+		return models.K8sVolumeSnapshot{}, err
+	}
+
+	data, err := kcl.cli.CoreV1().RESTClient().Post().
+		AbsPath(volumeSnapshotsPath(namespace)...).
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		DoRaw(context.TODO())
+	if err != nil {
+		return models.K8sVolumeSnapshot{}, err
+	}
+
+	var created volumeSnapshot
+	if err := json.Unmarshal(data, &created); err != nil {
+		return models.K8sVolumeSnapshot{}, errors.Wrap(err, "failed parsing volume snapshot")
+	}
+
+	return parseVolumeSnapshot(created), nil
+}
+
+// DeleteVolumeSnapshot deletes a volume snapshot
+func (kcl *KubeClient) DeleteVolumeSnapshot(namespace, name string) error {
+	if err := kcl.checkVolumeSnapshotsAvailable(); err != nil {
+		return err
+	}
+
+	_, err := kcl.cli.CoreV1().RESTClient().Delete().AbsPath(append(volumeSnapshotsPath(namespace), name)...).DoRaw(context.TODO())
+
+	return err
+}
+
+// RestoreVolumeSnapshot creates a new persistent volume claim populated with the content of a volume snapshot
+func (kcl *KubeClient) RestoreVolumeSnapshot(namespace, name string, payload models.K8sVolumeSnapshotRestorePayload) (models.K8sPersistentVolumeClaim, error) {
+	if err := kcl.checkVolumeSnapshotsAvailable(); err != nil {
+		return models.K8sPersistentVolumeClaim{}, err
+	}
+
+	data, err := kcl.cli.CoreV1().RESTClient().Get().AbsPath(append(volumeSnapshotsPath(namespace), name)...).DoRaw(context.TODO())
+	if err != nil {
+		return models.K8sPersistentVolumeClaim{}, err
+	}
+
+	var snapshot volumeSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return models.K8sPersistentVolumeClaim{}, errors.Wrap(err, "failed parsing volume snapshot")
+	}
+
+	return kcl.createClaimFromSnapshot(snapshot, payload)
+}
+
+// createClaimFromSnapshot creates a persistent volume claim using a volume snapshot as data source.
+// The storage class, access modes and size default to the ones of the claim the snapshot was taken from.
+func (kcl *KubeClient) createClaimFromSnapshot(snapshot volumeSnapshot, payload models.K8sVolumeSnapshotRestorePayload) (models.K8sPersistentVolumeClaim, error) {
+	if snapshot.Status == nil || snapshot.Status.ReadyToUse == nil || !*snapshot.Status.ReadyToUse {
+		return models.K8sPersistentVolumeClaim{}, errors.New("the volume snapshot is not ready to use")
+	}
+
+	claimClient := kcl.cli.CoreV1().PersistentVolumeClaims(snapshot.Namespace)
+
+	var source *core.PersistentVolumeClaim
+	if snapshot.Spec.Source.PersistentVolumeClaimName != nil {
+		claim, err := claimClient.Get(context.TODO(), *snapshot.Spec.Source.PersistentVolumeClaimName, metav1.GetOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return models.K8sPersistentVolumeClaim{}, errors.Wrap(err, "failed fetching the source volume of the snapshot")
+		}
+
+		if err == nil {
+			source = claim
+		}
+	}
+
+	apiGroup := volumeSnapshotGroup
+	claim := &core.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      payload.Name,
+			Namespace: snapshot.Namespace,
+		},
+		Spec: core.PersistentVolumeClaimSpec{
+			AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteOnce},
+			DataSource: &core.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     volumeSnapshotKind,
+				Name:     snapshot.Name,
+			},
+		},
+	}
+
+	var size resource.Quantity
+	if source != nil {
+		claim.Spec.AccessModes = source.Spec.AccessModes
+		claim.Spec.StorageClassName = source.Spec.StorageClassName
+		size = source.Spec.Resources.Requests[core.ResourceStorage]
+	}
+
+	if snapshot.Status.RestoreSize != nil && snapshot.Status.RestoreSize.Cmp(size) > 0 {
+		size = *snapshot.Status.RestoreSize
+	}
+
+	if payload.Size != "" {
+		requested, err := resource.ParseQuantity(payload.Size)
+		if err != nil {
+			return models.K8sPersistentVolumeClaim{}, errors.Wrap(err, "invalid volume size")
+		}
+
+		if requested.Cmp(size) < 0 {
+			return models.K8sPersistentVolumeClaim{}, ErrVolumeShrink
+		}
+
+		size = requested
+	}
+
+	if size.IsZero() {
+		return models.K8sPersistentVolumeClaim{}, errors.New("unable to determine the size of the restored volume")
+	}
+	claim.Spec.Resources.Requests = core.ResourceList{core.ResourceStorage: size}
+
+	if payload.StorageClass != "" {
+		claim.Spec.StorageClassName = &payload.StorageClass
+	}
+
+	created, err := claimClient.Create(context.TODO(), claim, metav1.CreateOptions{})
+	if err != nil {
+		return models.K8sPersistentVolumeClaim{}, err
+	}
+
+	return parsePersistentVolumeClaim(*created, nil), nil
+}
+
+// checkVolumeSnapshotsAvailable verifies that the VolumeSnapshot CRDs are installed in the cluster
+func (kcl *KubeClient) checkVolumeSnapshotsAvailable() error {
+	resources, err := kcl.cli.Discovery().ServerResourcesForGroupVersion(volumeSnapshotGroupVersion)
+	if k8serrors.IsNotFound(err) {
+		return ErrVolumeSnapshotsUnavailable
+	} else if err != nil {
+		return errors.Wrap(err, "failed fetching the volume snapshot resources")
+	}
+
+	for _, r := range resources.APIResources {
+		if r.Kind == volumeSnapshotKind {
+			return nil
+		}
+	}
+
+	return ErrVolumeSnapshotsUnavailable
+}
+
+func volumeSnapshotsPath(namespace string) []string {
+	return []string{"apis", volumeSnapshotGroupVersion, "namespaces", namespace, "volumesnapshots"}
+}
+
+func parseVolumeSnapshot(snapshot volumeSnapshot) models.K8sVolumeSnapshot {
+	result := models.K8sVolumeSnapshot{
+		Name:         snapshot.Name,
+		Namespace:    snapshot.Namespace,
+		UID:          string(snapshot.UID),
+		CreationDate: snapshot.CreationTimestamp.Time,
+	}
+
+	if snapshot.Spec.VolumeSnapshotClassName != nil {
+		result.SnapshotClass = *snapshot.Spec.VolumeSnapshotClassName
+	}
+
+	if snapshot.Spec.Source.PersistentVolumeClaimName != nil {
+		result.SourceClaim = *snapshot.Spec.Source.PersistentVolumeClaimName
+	}
+
+	if snapshot.Status != nil {
+		result.ReadyToUse = snapshot.Status.ReadyToUse != nil && *snapshot.Status.ReadyToUse
+
+		if snapshot.Status.RestoreSize != nil {
+			result.RestoreSize = snapshot.Status.RestoreSize.String()
+		}
+
+		if snapshot.Status.Error != nil && snapshot.Status.Error.Message != nil {
+			result.Error = *snapshot.Status.Error.Message
+		}
+	}
+
+	return result
+}
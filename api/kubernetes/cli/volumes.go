@@ -0,0 +1,390 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	core "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+var (
+	ErrVolumeNotExpandable = errors.New("the storage class of the volume does not allow volume expansion")
+	ErrVolumeShrink        = errors.New("the new size of the volume must be greater than its current size")
+)
+
+type (
+	// kubeletStatsSummary is the subset of the kubelet stats summary API describing the volumes of the pods
+	kubeletStatsSummary struct {
+		Pods []struct {
+			Volumes []kubeletVolumeStats `json:"volume"`
+		} `json:"pods"`
+	}
+
+	kubeletVolumeStats struct {
+		Name   string `json:"name"`
+		PVCRef *struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"pvcRef"`
+		CapacityBytes  *int64 `json:"capacityBytes"`
+		UsedBytes      *int64 `json:"usedBytes"`
+		AvailableBytes *int64 `json:"availableBytes"`
+	}
+)
+
+// GetStorageClasses gets all the storage classes of the cluster
+func (kcl *KubeClient) GetStorageClasses() ([]models.K8sStorageClass, error) {
+	storageClasses, err := kcl.cli.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.K8sStorageClass, 0, len(storageClasses.Items))
+	for _, storageClass := range storageClasses.Items {
+		result := models.K8sStorageClass{
+			Name:        storageClass.Name,
+			Provisioner: storageClass.Provisioner,
+			IsDefault:   isDefaultStorageClass(storageClass),
+		}
+
+		if storageClass.ReclaimPolicy != nil {
+			result.ReclaimPolicy = string(*storageClass.ReclaimPolicy)
+		}
+
+		if storageClass.VolumeBindingMode != nil {
+			result.VolumeBindingMode = string(*storageClass.VolumeBindingMode)
+		}
+
+		if storageClass.AllowVolumeExpansion != nil {
+			result.AllowVolumeExpansion = *storageClass.AllowVolumeExpansion
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// GetPersistentVolumes gets all the persistent volumes of the cluster
+func (kcl *KubeClient) GetPersistentVolumes() ([]models.K8sPersistentVolume, error) {
+	volumes, err := kcl.cli.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.K8sPersistentVolume, 0, len(volumes.Items))
+	for _, volume := range volumes.Items {
+		result := models.K8sPersistentVolume{
+			Name:          volume.Name,
+			StorageClass:  volume.Spec.StorageClassName,
+			AccessModes:   parseAccessModes(volume.Spec.AccessModes),
+			ReclaimPolicy: string(volume.Spec.PersistentVolumeReclaimPolicy),
+			Phase:         string(volume.Status.Phase),
+			CreationDate:  volume.CreationTimestamp.Time,
+		}
+
+		if capacity, ok := volume.Spec.Capacity[core.ResourceStorage]; ok {
+			result.Capacity = capacity.String()
+		}
+
+		if volume.Spec.ClaimRef != nil {
+			result.Claim = volume.Spec.ClaimRef.Namespace + "/" + volume.Spec.ClaimRef.Name
+		}
+
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	return results, nil
+}
+
+// GetPersistentVolumeClaims gets the persistent volume claims of a namespace with the applications mounting them.
+// The usage of the volumes is retrieved from the kubelet of the nodes running the pods when withUsage is true.
+func (kcl *KubeClient) GetPersistentVolumeClaims(namespace string, withUsage bool) ([]models.K8sPersistentVolumeClaim, error) {
+	claims, err := kcl.cli.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	storageClasses, err := kcl.cli.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := kcl.cli.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	applications, err := kcl.getClaimApplications(namespace, pods.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	var usage map[string]models.K8sVolumeUsage
+	if withUsage {
+		usage = kcl.getVolumesUsage(pods.Items)
+	}
+
+	results := make([]models.K8sPersistentVolumeClaim, 0, len(claims.Items))
+	for _, claim := range claims.Items {
+		result := parsePersistentVolumeClaim(claim, storageClasses.Items)
+
+		key := claim.Namespace + "/" + claim.Name
+		if apps, ok := applications[key]; ok {
+			result.Applications = apps
+		}
+
+		if volumeUsage, ok := usage[key]; ok {
+			result.Usage = &volumeUsage
+		}
+
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Namespace != results[j].Namespace {
+			return results[i].Namespace < results[j].Namespace
+		}
+
+		return results[i].Name < results[j].Name
+	})
+
+	return results, nil
+}
+
+// ExpandPersistentVolumeClaim increases the storage request of a persistent volume claim, the storage class
+// of the claim must allow volume expansion
+func (kcl *KubeClient) ExpandPersistentVolumeClaim(namespace, name, size string) error {
+	newSize, err := resource.ParseQuantity(size)
+	if err != nil {
+		return errors.Wrap(err, "invalid volume size")
+	}
+
+	claim, err := kcl.cli.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName == "" {
+		return ErrVolumeNotExpandable
+	}
+
+	storageClass, err := kcl.cli.StorageV1().StorageClasses().Get(context.TODO(), *claim.Spec.StorageClassName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed fetching the storage class of the volume")
+	}
+
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return ErrVolumeNotExpandable
+	}
+
+	currentSize := claim.Spec.Resources.Requests[core.ResourceStorage]
+	if newSize.Cmp(currentSize) <= 0 {
+		return ErrVolumeShrink
+	}
+
+	if claim.Spec.Resources.Requests == nil {
+		claim.Spec.Resources.Requests = core.ResourceList{}
+	}
+	claim.Spec.Resources.Requests[core.ResourceStorage] = newSize
+
+	_, err = kcl.cli.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), claim, metav1.UpdateOptions{})
+
+	return err
+}
+
+// getClaimApplications returns the applications mounting each persistent volume claim, indexed by namespace/name
+func (kcl *KubeClient) getClaimApplications(namespace string, pods []core.Pod) (map[string][]models.K8sApplication, error) {
+	replicaSets, err := kcl.cli.AppsV1().ReplicaSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// deployments own their pods through replicasets
+	replicaSetOwners := make(map[string]metav1.OwnerReference)
+	for _, replicaSet := range replicaSets.Items {
+		if len(replicaSet.OwnerReferences) > 0 {
+			replicaSetOwners[replicaSet.Namespace+"/"+replicaSet.Name] = replicaSet.OwnerReferences[0]
+		}
+	}
+
+	applications := make(map[string][]models.K8sApplication)
+	for _, pod := range pods {
+		application := podApplication(pod, replicaSetOwners)
+
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+
+			key := pod.Namespace + "/" + volume.PersistentVolumeClaim.ClaimName
+			if !containsApplication(applications[key], application) {
+				applications[key] = append(applications[key], application)
+			}
+		}
+	}
+
+	for _, apps := range applications {
+		sort.Slice(apps, func(i, j int) bool {
+			return apps[i].Name < apps[j].Name
+		})
+	}
+
+	return applications, nil
+}
+
+// getVolumesUsage returns the usage of the persistent volume claims mounted by the pods, indexed by namespace/name.
+// Nodes whose kubelet cannot be reached are skipped.
+func (kcl *KubeClient) getVolumesUsage(pods []core.Pod) map[string]models.K8sVolumeUsage {
+	nodes := make(map[string]struct{})
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase != core.PodRunning {
+			continue
+		}
+
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				nodes[pod.Spec.NodeName] = struct{}{}
+				break
+			}
+		}
+	}
+
+	usage := make(map[string]models.K8sVolumeUsage)
+	for node := range nodes {
+		data, err := kcl.cli.CoreV1().RESTClient().Get().AbsPath("api/v1/nodes", node, "proxy/stats/summary").DoRaw(context.TODO())
+		if err != nil {
+			log.Debug().Err(err).Str("node", node).Msg("unable to retrieve the kubelet stats summary")
+			continue
+		}
+
+		if err := parseVolumesUsage(data, usage); err != nil {
+			log.Debug().Err(err).Str("node", node).Msg("unable to parse the kubelet stats summary")
+		}
+	}
+
+	return usage
+}
+
+// parseVolumesUsage adds the usage of the persistent volume claims reported by a kubelet stats summary to usage
+func parseVolumesUsage(data []byte, usage map[string]models.K8sVolumeUsage) error {
+	var summary kubeletStatsSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return err
+	}
+
+	for _, pod := range summary.Pods {
+		for _, volume := range pod.Volumes {
+			if volume.PVCRef == nil {
+				continue
+			}
+
+			var volumeUsage models.K8sVolumeUsage
+			if volume.CapacityBytes != nil {
+				volumeUsage.CapacityBytes = *volume.CapacityBytes
+			}
+
+			if volume.UsedBytes != nil {
+				volumeUsage.UsedBytes = *volume.UsedBytes
+			}
+
+			if volume.AvailableBytes != nil {
+				volumeUsage.AvailableBytes = *volume.AvailableBytes
+			}
+
+			usage[volume.PVCRef.Namespace+"/"+volume.PVCRef.Name] = volumeUsage
+		}
+	}
+
+	return nil
+}
+
+func parsePersistentVolumeClaim(claim core.PersistentVolumeClaim, storageClasses []storagev1.StorageClass) models.K8sPersistentVolumeClaim {
+	result := models.K8sPersistentVolumeClaim{
+		Name:         claim.Name,
+		Namespace:    claim.Namespace,
+		UID:          string(claim.UID),
+		VolumeName:   claim.Spec.VolumeName,
+		AccessModes:  parseAccessModes(claim.Spec.AccessModes),
+		Phase:        string(claim.Status.Phase),
+		Applications: []models.K8sApplication{},
+		CreationDate: claim.CreationTimestamp.Time,
+	}
+
+	if claim.Spec.StorageClassName != nil {
+		result.StorageClass = *claim.Spec.StorageClassName
+	}
+
+	if requested, ok := claim.Spec.Resources.Requests[core.ResourceStorage]; ok {
+		result.Requested = requested.String()
+	}
+
+	if capacity, ok := claim.Status.Capacity[core.ResourceStorage]; ok {
+		result.Capacity = capacity.String()
+	}
+
+	for _, storageClass := range storageClasses {
+		if storageClass.Name == result.StorageClass {
+			result.IsExpandable = storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion
+			break
+		}
+	}
+
+	return result
+}
+
+func podApplication(pod core.Pod, replicaSetOwners map[string]metav1.OwnerReference) models.K8sApplication {
+	if len(pod.OwnerReferences) == 0 {
+		return models.K8sApplication{UID: string(pod.UID), Name: pod.Name, Namespace: pod.Namespace, Kind: "Pod"}
+	}
+
+	owner := pod.OwnerReferences[0]
+	if owner.Kind == "ReplicaSet" {
+		if replicaSetOwner, ok := replicaSetOwners[pod.Namespace+"/"+owner.Name]; ok {
+			owner = replicaSetOwner
+		}
+	}
+
+	return models.K8sApplication{UID: string(owner.UID), Name: owner.Name, Namespace: pod.Namespace, Kind: owner.Kind}
+}
+
+func containsApplication(applications []models.K8sApplication, application models.K8sApplication) bool {
+	for _, a := range applications {
+		if a.Kind == application.Kind && a.Name == application.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseAccessModes(accessModes []core.PersistentVolumeAccessMode) []string {
+	results := make([]string, 0, len(accessModes))
+	for _, accessMode := range accessModes {
+		results = append(results, string(accessMode))
+	}
+
+	return results
+}
+
+func isDefaultStorageClass(storageClass storagev1.StorageClass) bool {
+	return storageClass.Annotations[defaultStorageClassAnnotation] == "true" ||
+		storageClass.Annotations[betaDefaultStorageClassAnnotation] == "true"
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestClaim(name, storageClass, size string) *core.PersistentVolumeClaim {
+	return &core.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: core.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			AccessModes:      []core.PersistentVolumeAccessMode{core.ReadWriteOnce},
+			Resources: core.ResourceRequirements{
+				Requests: core.ResourceList{core.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+}
+
+func newTestStorageClass(name string, allowExpansion bool) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: name},
+		Provisioner:          "csi.test",
+		AllowVolumeExpansion: &allowExpansion,
+	}
+}
+
+func Test_GetPersistentVolumeClaims(t *testing.T) {
+	is := assert.New(t)
+
+	claimVolume := func(claim string) []core.Volume {
+		return []core.Volume{{
+			Name:         "data",
+			VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+		}}
+	}
+
+	kcl := &KubeClient{
+		cli: kfake.NewSimpleClientset(
+			newTestStorageClass("expandable", true),
+			newTestClaim("data", "expandable", "1Gi"),
+			newTestClaim("unused", "expandable", "1Gi"),
+			&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+				Name:            "web-abc",
+				Namespace:       "default",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web"}},
+			}},
+			&core.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-abc-1", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc"}}},
+				Spec:       core.PodSpec{Volumes: claimVolume("data")},
+			},
+			&core.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-abc-2", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc"}}},
+				Spec:       core.PodSpec{Volumes: claimVolume("data")},
+			},
+			&core.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db"}}},
+				Spec:       core.PodSpec{Volumes: claimVolume("data")},
+			},
+		),
+		instanceID: "instance",
+	}
+
+	claims, err := kcl.GetPersistentVolumeClaims("default", false)
+	is.NoError(err)
+	if is.Len(claims, 2) {
+		is.Equal("data", claims[0].Name)
+		is.True(claims[0].IsExpandable)
+		is.Equal("1Gi", claims[0].Requested)
+		is.Equal([]models.K8sApplication{
+			{Name: "db", Namespace: "default", Kind: "StatefulSet"},
+			{Name: "web", Namespace: "default", Kind: "Deployment"},
+		}, claims[0].Applications)
+
+		is.Equal("unused", claims[1].Name)
+		is.Empty(claims[1].Applications)
+	}
+}
+
+func Test_ExpandPersistentVolumeClaim(t *testing.T) {
+	is := assert.New(t)
+
+	kcl := &KubeClient{
+		cli: kfake.NewSimpleClientset(
+			newTestStorageClass("expandable", true),
+			newTestStorageClass("fixed", false),
+			newTestClaim("data", "expandable", "1Gi"),
+			newTestClaim("logs", "fixed", "1Gi"),
+		),
+		instanceID: "instance",
+	}
+
+	is.ErrorIs(kcl.ExpandPersistentVolumeClaim("default", "logs", "2Gi"), ErrVolumeNotExpandable)
+	is.ErrorIs(kcl.ExpandPersistentVolumeClaim("default", "data", "512Mi"), ErrVolumeShrink)
+	is.NoError(kcl.ExpandPersistentVolumeClaim("default", "data", "2Gi"))
+
+	claim, err := kcl.cli.CoreV1().PersistentVolumeClaims("default").Get(context.Background(), "data", metav1.GetOptions{})
+	is.NoError(err)
+	size := claim.Spec.Resources.Requests[core.ResourceStorage]
+	is.Equal("2Gi", size.String())
+}
+
+func Test_parseVolumesUsage(t *testing.T) {
+	is := assert.New(t)
+
+	summary := `{
+		"node": {"nodeName": "node-1"},
+		"pods": [{
+			"podRef": {"name": "db-0", "namespace": "default"},
+			"volume": [
+				{"name": "kube-api-access", "capacityBytes": 100, "usedBytes": 10},
+				{"name": "data", "capacityBytes": 1000, "usedBytes": 250, "availableBytes": 750, "pvcRef": {"name": "data", "namespace": "default"}}
+			]
+		}]
+	}`
+
+	usage := map[string]models.K8sVolumeUsage{}
+	is.NoError(parseVolumesUsage([]byte(summary), usage))
+	is.Equal(map[string]models.K8sVolumeUsage{
+		"default/data": {CapacityBytes: 1000, UsedBytes: 250, AvailableBytes: 750},
+	}, usage)
+}
+
+func Test_createClaimFromSnapshot(t *testing.T) {
+	is := assert.New(t)
+
+	kcl := &KubeClient{
+		cli:        kfake.NewSimpleClientset(newTestClaim("data", "expandable", "1Gi")),
+		instanceID: "instance",
+	}
+
+	source := "data"
+	ready := true
+	restoreSize := resource.MustParse("1Gi")
+
+	snapshot := volumeSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "data-snapshot", Namespace: "default"}}
+	snapshot.Spec.Source.PersistentVolumeClaimName = &source
+
+	_, err := kcl.createClaimFromSnapshot(snapshot, models.K8sVolumeSnapshotRestorePayload{Name: "data-restored"})
+	is.Error(err, "a snapshot which is not ready cannot be restored")
+
+	snapshot.Status = &volumeSnapshotStatus{ReadyToUse: &ready, RestoreSize: &restoreSize}
+
+	_, err = kcl.createClaimFromSnapshot(snapshot, models.K8sVolumeSnapshotRestorePayload{Name: "data-restored", Size: "512Mi"})
+	is.ErrorIs(err, ErrVolumeShrink)
+
+	claim, err := kcl.createClaimFromSnapshot(snapshot, models.K8sVolumeSnapshotRestorePayload{Name: "data-restored"})
+	is.NoError(err)
+	is.Equal("data-restored", claim.Name)
+	is.Equal("expandable", claim.StorageClass)
+	is.Equal("1Gi", claim.Requested)
+
+	created, err := kcl.cli.CoreV1().PersistentVolumeClaims("default").Get(context.Background(), "data-restored", metav1.GetOptions{})
+	is.NoError(err)
+	if is.NotNil(created.Spec.DataSource) {
+		is.Equal(volumeSnapshotKind, created.Spec.DataSource.Kind)
+		is.Equal("data-snapshot", created.Spec.DataSource.Name)
+	}
+}
+
+func Test_checkVolumeSnapshotsAvailable(t *testing.T) {
+	is := assert.New(t)
+
+	cli := kfake.NewSimpleClientset()
+	kcl := &KubeClient{cli: cli, instanceID: "instance"}
+
+	is.ErrorIs(kcl.checkVolumeSnapshotsAvailable(), ErrVolumeSnapshotsUnavailable)
+
+	cli.Resources = []*metav1.APIResourceList{{
+		GroupVersion: volumeSnapshotGroupVersion,
+		APIResources: []metav1.APIResource{{Name: "volumesnapshots", Kind: volumeSnapshotKind, Namespaced: true}},
+	}}
+
+	is.NoError(kcl.checkVolumeSnapshotsAvailable())
+}
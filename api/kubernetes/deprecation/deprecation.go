@@ -0,0 +1,199 @@
+package deprecation
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed apis.yaml
+var apisFile []byte
+
+var apis = mustLoadAPIs(apisFile)
+
+// Status is the status of a deprecated API for a Kubernetes version
+type Status string
+
+const (
+	// StatusDeprecated is the status of an API which is deprecated but still served
+	StatusDeprecated Status = "deprecated"
+	// StatusRemoved is the status of an API which is not served anymore
+	StatusRemoved Status = "removed"
+)
+
+// API is a deprecated Kubernetes API
+type API struct {
+	APIVersion   string `yaml:"apiVersion"`
+	Kind         string `yaml:"kind"`
+	Resource     string `yaml:"resource"`
+	DeprecatedIn string `yaml:"deprecatedIn"`
+	// RemovedIn is empty when the removal of the API is not scheduled yet
+	RemovedIn string `yaml:"removedIn"`
+	// Replacement is empty when the API has no replacement
+	Replacement string `yaml:"replacement"`
+
+	deprecatedIn Version
+	removedIn    *Version
+}
+
+// Version is a Kubernetes minor release
+type Version struct {
+	Major int
+	Minor int
+}
+
+// Object is a resource declared in a manifest
+type Object struct {
+	APIVersion string
+	Kind       string
+	Name       string
+	Namespace  string
+}
+
+var versionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)`)
+
+// ParseVersion parses a Kubernetes version such as v1.27.4 or 1.27, the patch version is ignored
+func ParseVersion(version string) (Version, error) {
+	matches := versionPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return Version{}, fmt.Errorf("invalid Kubernetes version %q", version)
+	}
+
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+
+	return Version{Major: major, Minor: minor}, nil
+}
+
+// AddMinor returns the version which is the given number of minor releases after v
+func (v Version) AddMinor(releases int) Version {
+	return Version{Major: v.Major, Minor: v.Minor + releases}
+}
+
+// AtMost returns true when v is older than or equal to other
+func (v Version) AtMost(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+
+	return v.Minor <= other.Minor
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// APIs returns the deprecated Kubernetes APIs
+func APIs() []API {
+	return apis
+}
+
+// Lookup returns the deprecated API matching an API version and a kind
+func Lookup(apiVersion, kind string) (API, bool) {
+	for _, api := range apis {
+		if api.APIVersion == apiVersion && api.Kind == kind {
+			return api, true
+		}
+	}
+
+	return API{}, false
+}
+
+// StatusAt returns the status of the API in the target version, false is returned when the API is neither
+// deprecated nor removed in this version
+func (api API) StatusAt(target Version) (Status, bool) {
+	if api.removedIn != nil && api.removedIn.AtMost(target) {
+		return StatusRemoved, true
+	}
+
+	if api.deprecatedIn.AtMost(target) {
+		return StatusDeprecated, true
+	}
+
+	return "", false
+}
+
+// ParseManifest returns the resources declared in a multi-document manifest, the items of the lists are included
+func ParseManifest(manifest []byte) ([]Object, error) {
+	objects := []Object{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(manifest))
+	for {
+		var document map[string]interface{}
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse yaml manifest")
+		}
+
+		objects = appendObjects(objects, document)
+	}
+
+	return objects, nil
+}
+
+func appendObjects(objects []Object, document map[string]interface{}) []Object {
+	if document == nil {
+		return objects
+	}
+
+	if items, ok := document["items"].([]interface{}); ok {
+		for _, item := range items {
+			if item, ok := item.(map[string]interface{}); ok {
+				objects = appendObjects(objects, item)
+			}
+		}
+
+		return objects
+	}
+
+	object := Object{}
+	object.APIVersion, _ = document["apiVersion"].(string)
+	object.Kind, _ = document["kind"].(string)
+	if metadata, ok := document["metadata"].(map[string]interface{}); ok {
+		object.Name, _ = metadata["name"].(string)
+		object.Namespace, _ = metadata["namespace"].(string)
+	}
+
+	if object.APIVersion == "" || object.Kind == "" {
+		return objects
+	}
+
+	return append(objects, object)
+}
+
+func mustLoadAPIs(data []byte) []API {
+	apis := []API{}
+	if err := yaml.Unmarshal(data, &apis); err != nil {
+		panic(errors.Wrap(err, "invalid deprecated APIs table"))
+	}
+
+	for i := range apis {
+		deprecatedIn, err := ParseVersion(apis[i].DeprecatedIn)
+		if err != nil {
+			panic(errors.Wrapf(err, "invalid deprecated APIs table entry %s %s", apis[i].APIVersion, apis[i].Kind))
+		}
+		apis[i].deprecatedIn = deprecatedIn
+
+		if apis[i].RemovedIn == "" {
+			continue
+		}
+
+		removedIn, err := ParseVersion(apis[i].RemovedIn)
+		if err != nil {
+			panic(errors.Wrapf(err, "invalid deprecated APIs table entry %s %s", apis[i].APIVersion, apis[i].Kind))
+		}
+		apis[i].removedIn = &removedIn
+	}
+
+	return apis
+}
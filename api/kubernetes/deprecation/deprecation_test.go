@@ -0,0 +1,88 @@
+package deprecation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseVersion(t *testing.T) {
+	is := assert.New(t)
+
+	for version, expected := range map[string]Version{
+		"v1.27.4":          {Major: 1, Minor: 27},
+		"1.25":             {Major: 1, Minor: 25},
+		"v1.24.8+k3s1":     {Major: 1, Minor: 24},
+		"v1.26.5-eks-a123": {Major: 1, Minor: 26},
+	} {
+		parsed, err := ParseVersion(version)
+		is.NoError(err, version)
+		is.Equal(expected, parsed, version)
+	}
+
+	_, err := ParseVersion("latest")
+	is.Error(err)
+}
+
+func Test_StatusAt(t *testing.T) {
+	is := assert.New(t)
+
+	api, ok := Lookup("batch/v1beta1", "CronJob")
+	if !is.True(ok) {
+		return
+	}
+
+	_, ok = api.StatusAt(Version{Major: 1, Minor: 20})
+	is.False(ok, "the API is not deprecated before 1.21")
+
+	status, ok := api.StatusAt(Version{Major: 1, Minor: 21})
+	is.True(ok)
+	is.Equal(StatusDeprecated, status)
+
+	status, ok = api.StatusAt(Version{Major: 1, Minor: 24}.AddMinor(1))
+	is.True(ok)
+	is.Equal(StatusRemoved, status)
+
+	_, ok = Lookup("batch/v1", "CronJob")
+	is.False(ok)
+}
+
+func Test_APIs(t *testing.T) {
+	is := assert.New(t)
+
+	for _, api := range APIs() {
+		is.NotEmpty(api.Kind, api.APIVersion)
+		is.NotEmpty(api.Resource, api.APIVersion+" "+api.Kind)
+	}
+}
+
+func Test_ParseManifest(t *testing.T) {
+	is := assert.New(t)
+
+	manifest := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: web
+  namespace: default
+---
+# empty document
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: batch/v1beta1
+    kind: CronJob
+    metadata:
+      name: backup
+`
+
+	objects, err := ParseManifest([]byte(manifest))
+	is.NoError(err)
+	is.Equal([]Object{
+		{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", Name: "web", Namespace: "default"},
+		{APIVersion: "batch/v1beta1", Kind: "CronJob", Name: "backup"},
+	}, objects)
+
+	_, err = ParseManifest([]byte("kind: [Ingress"))
+	is.Error(err)
+}
@@ -17,6 +17,7 @@ import (
 // KubeClusterAccessService represents a service that is responsible for centralizing kube cluster access data
 type KubeClusterAccessService interface {
 	IsSecure() bool
+	IsSelfSigned() bool
 	GetClusterDetails(hostURL string, endpointId portainer.EndpointID, isInternal bool) kubernetesClusterAccessData
 }
 
@@ -89,6 +90,28 @@ func (service *kubeClusterAccessService) IsSecure() bool {
 	return service.certificateAuthorityData != ""
 }
 
+// IsSelfSigned specifies whether the certificate at `tlsCertPath` is self-signed or otherwise not trusted by the system,
+// the clients using a generated KubeConfig cannot verify it without being given the certificate
+func (service *kubeClusterAccessService) IsSelfSigned() bool {
+	if service.certificateAuthorityData == "" {
+		return false
+	}
+
+	data, err := base64.StdEncoding.DecodeString(service.certificateAuthorityData)
+	if err != nil {
+		return false
+	}
+
+	certificate, err := x509.ParseCertificate(data)
+	if err != nil {
+		return false
+	}
+
+	_, err = certificate.Verify(x509.VerifyOptions{})
+
+	return err != nil
+}
+
 // GetClusterDetails returns K8s cluster access details for the specified environment(endpoint).
 // The struct can be used to:
 // - generate a kubeconfig file
@@ -88,6 +88,17 @@ func TestKubeClusterAccessService_IsSecure(t *testing.T) {
 	})
 }
 
+func TestKubeClusterAccessService_IsSelfSigned(t *testing.T) {
+	is := assert.New(t)
+
+	kcs := NewKubeClusterAccessService("", "", "")
+	is.False(kcs.IsSelfSigned(), "should be false if TLS cert not provided")
+
+	filePath := createTempFile("valid-cert.crt", certData, t)
+	kcs = NewKubeClusterAccessService("", "", filePath)
+	is.True(kcs.IsSelfSigned(), "should be true if the TLS cert is self-signed")
+}
+
 func TestKubeClusterAccessService_GetKubeConfigInternal(t *testing.T) {
 	is := assert.New(t)
 
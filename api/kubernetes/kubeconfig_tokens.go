@@ -0,0 +1,41 @@
+package kubernetes
+
+import (
+	"time"
+
+	"github.com/portainer/portainer/api/dataservices"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// PurgeKubeconfigTokens removes the kubeconfig tokens whose embedded bearer token expired, as well as the tokens
+// of the users who no longer exist. Revoked tokens are removed when they are revoked.
+func PurgeKubeconfigTokens(dataStore dataservices.DataStore) error {
+	tokens, err := dataStore.KubeconfigToken().ReadAll()
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve the kubeconfig tokens")
+	}
+
+	now := time.Now().Unix()
+	for _, token := range tokens {
+		expired := token.ExpiryDate != 0 && token.ExpiryDate <= now
+
+		if !expired {
+			_, err := dataStore.User().Read(token.UserID)
+			if err == nil {
+				continue
+			}
+
+			if !dataStore.IsErrObjectNotFound(err) {
+				return errors.Wrap(err, "unable to retrieve the owner of a kubeconfig token")
+			}
+		}
+
+		if err := dataStore.KubeconfigToken().Delete(token.ID); err != nil {
+			log.Warn().Err(err).Int("token_id", int(token.ID)).Msg("unable to remove an expired kubeconfig token")
+		}
+	}
+
+	return nil
+}
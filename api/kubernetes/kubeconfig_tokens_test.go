@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PurgeKubeconfigTokens(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	user := &portainer.User{Username: "user"}
+	is.NoError(store.User().Create(user))
+
+	now := time.Now()
+	tokens := []*portainer.KubeconfigToken{
+		{UserID: user.ID, Mode: portainer.KubeconfigTokenModeBearer, ExpiryDate: now.Add(-time.Hour).Unix()},
+		{UserID: user.ID, Mode: portainer.KubeconfigTokenModeBearer, ExpiryDate: now.Add(time.Hour).Unix()},
+		{UserID: user.ID, Mode: portainer.KubeconfigTokenModeBearer},
+		{UserID: user.ID, Mode: portainer.KubeconfigTokenModeExec},
+		{UserID: user.ID + 1, Mode: portainer.KubeconfigTokenModeExec},
+	}
+	for _, token := range tokens {
+		is.NoError(store.KubeconfigToken().Create(token))
+	}
+
+	is.NoError(PurgeKubeconfigTokens(store))
+
+	remaining, err := store.KubeconfigToken().ReadAll()
+	is.NoError(err)
+
+	ids := make([]portainer.KubeconfigTokenID, 0, len(remaining))
+	for _, token := range remaining {
+		ids = append(ids, token.ID)
+	}
+
+	is.ElementsMatch([]portainer.KubeconfigTokenID{tokens[1].ID, tokens[2].ID, tokens[3].ID}, ids, "the expired tokens and the tokens of removed users should be purged")
+}
@@ -0,0 +1,87 @@
+package kustomize
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/filesystem"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// ErrKustomizationNotFound is returned when the rendered directory does not contain a kustomization file
+var ErrKustomizationNotFound = errors.New("no kustomization file found in the kustomization directory")
+
+// Directory returns the directory rendered for a kustomization, relative to the project
+func Directory(kustomization portainer.KubernetesKustomization) string {
+	return filesystem.JoinPaths("/", kustomization.Path, kustomization.Overlay)[1:]
+}
+
+// FindKustomizationFile returns the path of the kustomization file of the rendered directory, relative to the project
+func FindKustomizationFile(projectPath string, kustomization portainer.KubernetesKustomization) (string, error) {
+	directory := Directory(kustomization)
+
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		info, err := os.Stat(filesystem.JoinPaths(projectPath, directory, name))
+		if err == nil && !info.IsDir() {
+			return filepath.ToSlash(filepath.Join(directory, name)), nil
+		}
+	}
+
+	return "", ErrKustomizationNotFound
+}
+
+// Render builds the kustomization of a project and returns the rendered manifest.
+// The project is copied into an in-memory filesystem before being built so that the kustomization
+// cannot reference files outside of the project, remote resources are not supported.
+func Render(projectPath string, kustomization portainer.KubernetesKustomization) ([]byte, error) {
+	fSys, err := loadProject(projectPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the kustomization files")
+	}
+
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, filesystem.JoinPaths("/", Directory(kustomization)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render the kustomization")
+	}
+
+	return resources.AsYaml()
+}
+
+func loadProject(projectPath string) (filesys.FileSystem, error) {
+	fSys := filesys.MakeFsInMemory()
+
+	err := filepath.WalkDir(projectPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		// symbolic links are ignored, they could point outside of the project
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(projectPath, path)
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return fSys.WriteFile(filesystem.JoinPaths("/", filepath.ToSlash(relativePath)), content)
+	})
+
+	return fSys, err
+}
@@ -0,0 +1,90 @@
+package kustomize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestProject(t *testing.T) string {
+	projectPath := t.TempDir()
+
+	writeTestFile(t, filepath.Join(projectPath, "deploy", "base", "kustomization.yaml"), "resources:\n  - deployment.yaml\n")
+	writeTestFile(t, filepath.Join(projectPath, "deploy", "base", "deployment.yaml"), `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	writeTestFile(t, filepath.Join(projectPath, "deploy", "overlays", "production", "kustomization.yml"), `resources:
+  - ../../base
+namePrefix: prod-
+replicas:
+  - name: web
+    count: 3
+`)
+
+	return projectPath
+}
+
+func Test_Render(t *testing.T) {
+	is := assert.New(t)
+
+	projectPath := newTestProject(t)
+
+	manifest, err := Render(projectPath, portainer.KubernetesKustomization{Path: "deploy", Overlay: "overlays/production"})
+	is.NoError(err)
+	is.Contains(string(manifest), "name: prod-web")
+	is.Contains(string(manifest), "replicas: 3")
+
+	manifest, err = Render(projectPath, portainer.KubernetesKustomization{Path: "deploy/base"})
+	is.NoError(err)
+	is.Contains(string(manifest), "name: web")
+	is.Contains(string(manifest), "replicas: 1")
+}
+
+func Test_Render_OutsideOfProject(t *testing.T) {
+	is := assert.New(t)
+
+	outside := t.TempDir()
+	writeTestFile(t, filepath.Join(outside, "secret.yaml"), "apiVersion: v1\nkind: Secret\nmetadata:\n  name: secret\n")
+
+	projectPath := t.TempDir()
+	writeTestFile(t, filepath.Join(projectPath, "kustomization.yaml"), "resources:\n  - "+filepath.Join(outside, "secret.yaml")+"\n")
+
+	_, err := Render(projectPath, portainer.KubernetesKustomization{})
+	is.Error(err, "the files outside of the project should not be readable")
+}
+
+func Test_FindKustomizationFile(t *testing.T) {
+	is := assert.New(t)
+
+	projectPath := newTestProject(t)
+
+	file, err := FindKustomizationFile(projectPath, portainer.KubernetesKustomization{Path: "deploy", Overlay: "overlays/production"})
+	is.NoError(err)
+	is.Equal("deploy/overlays/production/kustomization.yml", file)
+
+	_, err = FindKustomizationFile(projectPath, portainer.KubernetesKustomization{Path: "deploy"})
+	is.ErrorIs(err, ErrKustomizationNotFound)
+
+	_, err = FindKustomizationFile(projectPath, portainer.KubernetesKustomization{Path: "../.."})
+	is.ErrorIs(err, ErrKustomizationNotFound)
+}
@@ -8,13 +8,15 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/portainer/portainer/api/kubernetes/cli"
+
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 )
 
 const (
-	labelPortainerAppStack   = "io.portainer.kubernetes.application.stack"
-	labelPortainerAppStackID = "io.portainer.kubernetes.application.stackid"
+	labelPortainerAppStack   = cli.StackNameLabel
+	labelPortainerAppStackID = cli.StackIDLabel
 	labelPortainerAppName    = "io.portainer.kubernetes.application.name"
 	labelPortainerAppOwner   = "io.portainer.kubernetes.application.owner"
 	labelPortainerAppKind    = "io.portainer.kubernetes.application.kind"
@@ -0,0 +1,280 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/docker/images"
+
+	"github.com/pkg/errors"
+)
+
+// Workload represents the part of a container, a service or a compose service definition the policies are evaluated against
+type Workload struct {
+	// Kind of workload, used in the violation messages
+	Kind string
+	// Name of the container or of the service, empty when it is generated by Docker
+	Name   string
+	Image  string
+	Labels map[string]string
+	// Memory limit in bytes, 0 when there is no limit
+	MemoryLimit int64
+	// CPU limit in billionths of CPU, 0 when there is no limit
+	NanoCPUs int64
+	// Published host ports, either a single port or a range of ports
+	PublishedPorts         []string
+	ReadOnlyRootFilesystem bool
+	// Host paths of the bind mounts
+	BindSources []string
+}
+
+// Violation represents a rule of a policy broken by a workload
+type Violation struct {
+	PolicyID   portainer.PolicyID   `json:"PolicyId" example:"1"`
+	PolicyName string               `json:"PolicyName" example:"production"`
+	Mode       portainer.PolicyMode `json:"Mode" example:"enforce"`
+	Workload   string               `json:"Workload" example:"container web"`
+	Message    string               `json:"Message" example:"label \"owner\" is required"`
+}
+
+func (violation Violation) String() string {
+	if violation.Workload == "" {
+		return fmt.Sprintf("policy %q: %s", violation.PolicyName, violation.Message)
+	}
+
+	return fmt.Sprintf("policy %q: %s: %s", violation.PolicyName, violation.Workload, violation.Message)
+}
+
+// Evaluate returns the violations of the policies by the workloads
+func Evaluate(policies []portainer.Policy, workloads ...Workload) []Violation {
+	violations := []Violation{}
+
+	for _, policy := range policies {
+		for _, workload := range workloads {
+			for _, message := range evaluateRules(policy.Rules, workload) {
+				violations = append(violations, Violation{
+					PolicyID:   policy.ID,
+					PolicyName: policy.Name,
+					Mode:       policy.Mode,
+					Workload:   workload.description(),
+					Message:    message,
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+// ValidateRules checks that the rules of a policy can be evaluated
+func ValidateRules(rules portainer.PolicyRules) error {
+	if rules.MaxMemory < 0 {
+		return errors.New("the maximum memory cannot be negative")
+	}
+
+	if rules.MaxCPUs < 0 {
+		return errors.New("the maximum number of CPUs cannot be negative")
+	}
+
+	for _, port := range rules.ForbiddenPorts {
+		if _, err := parsePortRange(port); err != nil {
+			return errors.Wrapf(err, "invalid forbidden port %q", port)
+		}
+	}
+
+	for _, bindPath := range rules.AllowedBindPaths {
+		if !path.IsAbs(bindPath) {
+			return errors.Errorf("invalid allowed bind path %q, the path must be absolute", bindPath)
+		}
+	}
+
+	for _, registry := range rules.AllowedRegistries {
+		if registry == "" || strings.Contains(registry, "://") {
+			return errors.Errorf("invalid allowed registry %q, expected a registry host optionally followed by a namespace", registry)
+		}
+	}
+
+	for _, label := range rules.RequiredLabels {
+		if label == "" {
+			return errors.New("the required labels cannot be empty")
+		}
+	}
+
+	return nil
+}
+
+func (workload Workload) description() string {
+	if workload.Name == "" {
+		return workload.Kind
+	}
+
+	if workload.Kind == "" {
+		return workload.Name
+	}
+
+	return workload.Kind + " " + workload.Name
+}
+
+func evaluateRules(rules portainer.PolicyRules, workload Workload) []string {
+	messages := []string{}
+
+	if len(rules.AllowedRegistries) > 0 && workload.Image != "" {
+		if message := checkRegistry(rules.AllowedRegistries, workload.Image); message != "" {
+			messages = append(messages, message)
+		}
+	}
+
+	for _, label := range rules.RequiredLabels {
+		if _, ok := workload.Labels[label]; !ok {
+			messages = append(messages, fmt.Sprintf("label %q is required", label))
+		}
+	}
+
+	if rules.MaxMemory > 0 {
+		switch {
+		case workload.MemoryLimit <= 0:
+			messages = append(messages, fmt.Sprintf("a memory limit is required, the maximum is %s", formatBytes(rules.MaxMemory)))
+		case workload.MemoryLimit > rules.MaxMemory:
+			messages = append(messages, fmt.Sprintf("the memory limit %s exceeds the maximum of %s", formatBytes(workload.MemoryLimit), formatBytes(rules.MaxMemory)))
+		}
+	}
+
+	if rules.MaxCPUs > 0 {
+		maxNanoCPUs := int64(rules.MaxCPUs * 1e9)
+		switch {
+		case workload.NanoCPUs <= 0:
+			messages = append(messages, fmt.Sprintf("a CPU limit is required, the maximum is %s", formatCPUs(maxNanoCPUs)))
+		case workload.NanoCPUs > maxNanoCPUs:
+			messages = append(messages, fmt.Sprintf("the CPU limit %s exceeds the maximum of %s", formatCPUs(workload.NanoCPUs), formatCPUs(maxNanoCPUs)))
+		}
+	}
+
+	messages = append(messages, checkPorts(rules.ForbiddenPorts, workload.PublishedPorts)...)
+
+	if rules.ReadOnlyRootFilesystem && !workload.ReadOnlyRootFilesystem {
+		messages = append(messages, "the root filesystem must be read-only")
+	}
+
+	if len(rules.AllowedBindPaths) > 0 {
+		for _, source := range workload.BindSources {
+			if !isAllowedBindPath(rules.AllowedBindPaths, source) {
+				messages = append(messages, fmt.Sprintf("the bind mount of %s is not allowed, the allowed paths are %s", source, strings.Join(rules.AllowedBindPaths, ", ")))
+			}
+		}
+	}
+
+	return messages
+}
+
+// checkRegistry verifies that the image is pulled from one of the allowed registries. An allowed registry can be
+// followed by a namespace to only allow the images of this namespace
+func checkRegistry(allowedRegistries []string, image string) string {
+	parsed, err := images.ParseImage(images.ParseImageOptions{Name: image})
+	if err != nil {
+		return fmt.Sprintf("the image %q cannot be parsed", image)
+	}
+
+	name := parsed.Name()
+	for _, registry := range allowedRegistries {
+		registry = strings.TrimSuffix(strings.ToLower(registry), "/")
+		if strings.EqualFold(parsed.Domain, registry) || strings.HasPrefix(name, registry+"/") {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("the image %s is pulled from %s, the allowed registries are %s", image, parsed.Domain, strings.Join(allowedRegistries, ", "))
+}
+
+func checkPorts(forbiddenPorts []string, publishedPorts []string) []string {
+	messages := []string{}
+
+	for _, published := range publishedPorts {
+		publishedRange, err := parsePortRange(published)
+		if err != nil {
+			continue
+		}
+
+		for _, forbidden := range forbiddenPorts {
+			forbiddenRange, err := parsePortRange(forbidden)
+			if err != nil {
+				continue
+			}
+
+			if publishedRange.overlaps(forbiddenRange) {
+				messages = append(messages, fmt.Sprintf("the host port %s is forbidden", published))
+				break
+			}
+		}
+	}
+
+	return messages
+}
+
+func isAllowedBindPath(allowedPaths []string, source string) bool {
+	source = path.Clean(source)
+
+	for _, allowed := range allowedPaths {
+		allowed = path.Clean(allowed)
+		if allowed == "/" || source == allowed || strings.HasPrefix(source, allowed+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+type portRange struct {
+	start, end int
+}
+
+func (r portRange) overlaps(other portRange) bool {
+	return r.start <= other.end && other.start <= r.end
+}
+
+// parsePortRange parses a port or a range of ports such as 8000-8010, the protocol suffix is ignored
+func parsePortRange(value string) (portRange, error) {
+	value, _, _ = strings.Cut(strings.TrimSpace(value), "/")
+
+	startValue, endValue, isRange := strings.Cut(value, "-")
+	if !isRange {
+		endValue = startValue
+	}
+
+	start, err := strconv.Atoi(startValue)
+	if err != nil {
+		return portRange{}, errors.New("expected a port or a range of ports")
+	}
+
+	end, err := strconv.Atoi(endValue)
+	if err != nil {
+		return portRange{}, errors.New("expected a port or a range of ports")
+	}
+
+	if start < 1 || end > 65535 || start > end {
+		return portRange{}, errors.New("the ports must be between 1 and 65535")
+	}
+
+	return portRange{start: start, end: end}, nil
+}
+
+func formatBytes(value int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+
+	size := float64(value)
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+
+	formatted := strings.TrimRight(strings.TrimRight(strconv.FormatFloat(size, 'f', 2, 64), "0"), ".")
+
+	return formatted + units[unit]
+}
+
+func formatCPUs(nanoCPUs int64) string {
+	return strconv.FormatFloat(float64(nanoCPUs)/1e9, 'f', -1, 64) + " CPUs"
+}
@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Evaluate(t *testing.T) {
+	is := assert.New(t)
+
+	policies := []portainer.Policy{{
+		ID:   1,
+		Name: "production",
+		Mode: portainer.PolicyModeEnforce,
+		Rules: portainer.PolicyRules{
+			AllowedRegistries:      []string{"registry.local", "docker.io/library"},
+			RequiredLabels:         []string{"owner"},
+			MaxMemory:              512 * 1024 * 1024,
+			MaxCPUs:                1.5,
+			ForbiddenPorts:         []string{"22", "1-1023"},
+			ReadOnlyRootFilesystem: true,
+			AllowedBindPaths:       []string{"/srv/data"},
+		},
+	}}
+
+	compliant := Workload{
+		Kind:                   "container",
+		Name:                   "web",
+		Image:                  "registry.local/team/web:1.0",
+		Labels:                 map[string]string{"owner": "team"},
+		MemoryLimit:            256 * 1024 * 1024,
+		NanoCPUs:               1e9,
+		PublishedPorts:         []string{"8080"},
+		ReadOnlyRootFilesystem: true,
+		BindSources:            []string{"/srv/data/web", "/srv/data"},
+	}
+	is.Empty(Evaluate(policies, compliant))
+
+	official := compliant
+	official.Image = "nginx:1.25"
+	is.Empty(Evaluate(policies, official), "the official images are allowed by the docker.io/library namespace")
+
+	violations := Evaluate(policies, Workload{
+		Kind:           "container",
+		Name:           "web",
+		Image:          "ghcr.io/acme/web:1.0",
+		MemoryLimit:    1024 * 1024 * 1024,
+		PublishedPorts: []string{"8080", "80", "1000-2000"},
+		BindSources:    []string{"/srv/database", "/etc"},
+	})
+
+	messages := []string{}
+	for _, violation := range violations {
+		is.Equal(portainer.PolicyID(1), violation.PolicyID)
+		is.Equal("container web", violation.Workload)
+		messages = append(messages, violation.Message)
+	}
+
+	is.Equal([]string{
+		"the image ghcr.io/acme/web:1.0 is pulled from ghcr.io, the allowed registries are registry.local, docker.io/library",
+		`label "owner" is required`,
+		"the memory limit 1GiB exceeds the maximum of 512MiB",
+		"a CPU limit is required, the maximum is 1.5 CPUs",
+		"the host port 80 is forbidden",
+		"the host port 1000-2000 is forbidden",
+		"the root filesystem must be read-only",
+		"the bind mount of /srv/database is not allowed, the allowed paths are /srv/data",
+		"the bind mount of /etc is not allowed, the allowed paths are /srv/data",
+	}, messages)
+
+	is.Equal(`policy "production": container web: label "owner" is required`, violations[1].String())
+}
+
+func Test_ValidateRules(t *testing.T) {
+	is := assert.New(t)
+
+	is.NoError(ValidateRules(portainer.PolicyRules{}))
+	is.NoError(ValidateRules(portainer.PolicyRules{ForbiddenPorts: []string{"22", "1-1023", "53/udp"}, AllowedBindPaths: []string{"/srv"}}))
+
+	is.Error(ValidateRules(portainer.PolicyRules{ForbiddenPorts: []string{"ssh"}}))
+	is.Error(ValidateRules(portainer.PolicyRules{ForbiddenPorts: []string{"1023-1"}}))
+	is.Error(ValidateRules(portainer.PolicyRules{ForbiddenPorts: []string{"70000"}}))
+	is.Error(ValidateRules(portainer.PolicyRules{AllowedBindPaths: []string{"srv/data"}}))
+	is.Error(ValidateRules(portainer.PolicyRules{AllowedRegistries: []string{"https://registry.local"}}))
+	is.Error(ValidateRules(portainer.PolicyRules{RequiredLabels: []string{""}}))
+	is.Error(ValidateRules(portainer.PolicyRules{MaxMemory: -1}))
+}
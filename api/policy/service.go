@@ -0,0 +1,110 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrPolicyViolation is returned when a request violates a policy in enforce mode
+var ErrPolicyViolation = errors.New("the request violates the policies of the environment group")
+
+// Service evaluates the stacks deployed on the Docker environments against the policies of their groups
+type Service struct {
+	dataStore           dataservices.DataStore
+	composeStackManager portainer.ComposeStackManager
+}
+
+// NewService creates a new policy service
+func NewService(dataStore dataservices.DataStore, composeStackManager portainer.ComposeStackManager) *Service {
+	return &Service{
+		dataStore:           dataStore,
+		composeStackManager: composeStackManager,
+	}
+}
+
+// CheckStack evaluates the services of a compose or swarm stack against the policies of the group of the environment.
+// The Kubernetes stacks are not evaluated
+func (service *Service) CheckStack(ctx context.Context, stack *portainer.Stack, endpoint *portainer.Endpoint) error {
+	if stack.Type != portainer.DockerComposeStack && stack.Type != portainer.DockerSwarmStack {
+		return nil
+	}
+
+	policies, err := EndpointPolicies(service.dataStore, endpoint)
+	if err != nil || len(policies) == 0 {
+		return err
+	}
+
+	config, err := service.composeStackManager.Config(ctx, stack)
+	if err != nil {
+		return errors.Wrap(err, "unable to resolve the services of the stack to evaluate the policies")
+	}
+
+	workloads, err := ComposeWorkloads(config)
+	if err != nil {
+		return err
+	}
+
+	return check(policies, endpoint, "stack "+stack.Name, workloads...)
+}
+
+// EndpointPolicies returns the policies attached to the group of the environment
+func EndpointPolicies(dataStore dataservices.DataStore, endpoint *portainer.Endpoint) ([]portainer.Policy, error) {
+	policies, err := dataStore.Policy().ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve the policies")
+	}
+
+	endpointPolicies := []portainer.Policy{}
+	for _, policy := range policies {
+		if slices.Contains(policy.EndpointGroupIDs, endpoint.GroupID) {
+			endpointPolicies = append(endpointPolicies, policy)
+		}
+	}
+
+	return endpointPolicies, nil
+}
+
+// Check evaluates the workloads of a request against the policies of the group of the environment. The violations of
+// the policies in audit mode are logged, an error wrapping ErrPolicyViolation is returned when a policy in enforce mode
+// is violated
+func Check(dataStore dataservices.DataStore, endpoint *portainer.Endpoint, request string, workloads ...Workload) error {
+	policies, err := EndpointPolicies(dataStore, endpoint)
+	if err != nil {
+		return err
+	}
+
+	return check(policies, endpoint, request, workloads...)
+}
+
+func check(policies []portainer.Policy, endpoint *portainer.Endpoint, request string, workloads ...Workload) error {
+	enforced := []string{}
+
+	for _, violation := range Evaluate(policies, workloads...) {
+		if violation.Mode == portainer.PolicyModeAudit {
+			log.Warn().
+				Int("endpoint_id", int(endpoint.ID)).
+				Int("policy_id", int(violation.PolicyID)).
+				Str("request", request).
+				Str("violation", violation.String()).
+				Msg("policy violation allowed by the audit mode")
+
+			continue
+		}
+
+		enforced = append(enforced, violation.String())
+	}
+
+	if len(enforced) > 0 {
+		return fmt.Errorf("%w: %s", ErrPolicyViolation, strings.Join(enforced, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testComposeStackManager struct {
+	portainer.ComposeStackManager
+	config string
+	calls  int
+}
+
+func (manager *testComposeStackManager) Config(ctx context.Context, stack *portainer.Stack) ([]byte, error) {
+	manager.calls++
+
+	return []byte(manager.config), nil
+}
+
+func Test_Check(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	is.NoError(store.Policy().Create(&portainer.Policy{
+		Name:             "labels",
+		Mode:             portainer.PolicyModeAudit,
+		EndpointGroupIDs: []portainer.EndpointGroupID{1},
+		Rules:            portainer.PolicyRules{RequiredLabels: []string{"owner"}},
+	}))
+
+	is.NoError(store.Policy().Create(&portainer.Policy{
+		Name:             "registries",
+		Mode:             portainer.PolicyModeEnforce,
+		EndpointGroupIDs: []portainer.EndpointGroupID{2},
+		Rules:            portainer.PolicyRules{AllowedRegistries: []string{"registry.local"}},
+	}))
+
+	workload := Workload{Kind: "container", Name: "web", Image: "nginx:1.25"}
+
+	err := Check(store, &portainer.Endpoint{ID: 1, GroupID: 1}, "container creation", workload)
+	is.NoError(err, "the violations of the policies in audit mode are only logged")
+
+	err = Check(store, &portainer.Endpoint{ID: 2, GroupID: 2}, "container creation", workload)
+	is.ErrorIs(err, ErrPolicyViolation)
+	is.ErrorContains(err, `policy "registries": container web: the image nginx:1.25 is pulled from docker.io`)
+
+	err = Check(store, &portainer.Endpoint{ID: 3, GroupID: 3}, "container creation", workload)
+	is.NoError(err, "the policies only apply to the environments of their groups")
+}
+
+func Test_CheckStack(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	manager := &testComposeStackManager{
+		ComposeStackManager: testhelpers.NewComposeStackManager(),
+		config:              `{"services": {"web": {"image": "nginx:1.25", "ports": [{"target": 22, "published": "2222"}]}}}`,
+	}
+	service := NewService(store, manager)
+
+	endpoint := &portainer.Endpoint{ID: 1, GroupID: 1}
+	stack := &portainer.Stack{ID: 1, Name: "app", Type: portainer.DockerComposeStack}
+
+	is.NoError(service.CheckStack(context.Background(), stack, endpoint))
+	is.Equal(0, manager.calls, "the stack is not resolved when no policy applies to the environment")
+
+	is.NoError(store.Policy().Create(&portainer.Policy{
+		Name:             "ports",
+		Mode:             portainer.PolicyModeEnforce,
+		EndpointGroupIDs: []portainer.EndpointGroupID{1},
+		Rules:            portainer.PolicyRules{ForbiddenPorts: []string{"2000-3000"}},
+	}))
+
+	err := service.CheckStack(context.Background(), stack, endpoint)
+	is.ErrorIs(err, ErrPolicyViolation)
+	is.ErrorContains(err, "service web: the host port 2222 is forbidden")
+
+	kubernetesStack := &portainer.Stack{ID: 2, Name: "kube", Type: portainer.KubernetesStack}
+	is.NoError(service.CheckStack(context.Background(), kubernetesStack, endpoint))
+}
@@ -0,0 +1,348 @@
+package policy
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const defaultCPUPeriod = 100000
+
+// ContainerWorkload returns the workload of a container creation request of the Docker API
+func ContainerWorkload(name string, body []byte) (Workload, error) {
+	var container struct {
+		Image      string            `json:"Image"`
+		Labels     map[string]string `json:"Labels"`
+		HostConfig struct {
+			Memory       int64 `json:"Memory"`
+			NanoCPUs     int64 `json:"NanoCpus"`
+			CPUQuota     int64 `json:"CpuQuota"`
+			CPUPeriod    int64 `json:"CpuPeriod"`
+			PortBindings map[string][]struct {
+				HostPort string `json:"HostPort"`
+			} `json:"PortBindings"`
+			ReadonlyRootfs bool     `json:"ReadonlyRootfs"`
+			Binds          []string `json:"Binds"`
+			Mounts         []struct {
+				Type   string `json:"Type"`
+				Source string `json:"Source"`
+			} `json:"Mounts"`
+		} `json:"HostConfig"`
+	}
+
+	if err := json.Unmarshal(body, &container); err != nil {
+		return Workload{}, errors.Wrap(err, "unable to parse the container definition")
+	}
+
+	workload := Workload{
+		Kind:                   "container",
+		Name:                   name,
+		Image:                  container.Image,
+		Labels:                 container.Labels,
+		MemoryLimit:            container.HostConfig.Memory,
+		NanoCPUs:               container.HostConfig.NanoCPUs,
+		ReadOnlyRootFilesystem: container.HostConfig.ReadonlyRootfs,
+	}
+
+	if workload.NanoCPUs == 0 && container.HostConfig.CPUQuota > 0 {
+		period := container.HostConfig.CPUPeriod
+		if period == 0 {
+			period = defaultCPUPeriod
+		}
+
+		workload.NanoCPUs = container.HostConfig.CPUQuota * 1e9 / period
+	}
+
+	for _, bindings := range container.HostConfig.PortBindings {
+		for _, binding := range bindings {
+			if binding.HostPort != "" {
+				workload.PublishedPorts = append(workload.PublishedPorts, binding.HostPort)
+			}
+		}
+	}
+	sort.Strings(workload.PublishedPorts)
+
+	for _, bind := range container.HostConfig.Binds {
+		// named volumes are also declared in the binds, only the host paths are bind mounts
+		source, _, _ := strings.Cut(bind, ":")
+		if strings.HasPrefix(source, "/") {
+			workload.BindSources = append(workload.BindSources, source)
+		}
+	}
+
+	for _, mount := range container.HostConfig.Mounts {
+		if mount.Type == "bind" {
+			workload.BindSources = append(workload.BindSources, mount.Source)
+		}
+	}
+
+	return workload, nil
+}
+
+// ContainerUpdateWorkload returns the workload of a container update request of the Docker API, the resources of the
+// update are applied to the container as returned by its inspection
+func ContainerUpdateWorkload(container []byte, body []byte) (Workload, error) {
+	var inspect struct {
+		Name   string `json:"Name"`
+		Config struct {
+			Image  string            `json:"Image"`
+			Labels map[string]string `json:"Labels"`
+		} `json:"Config"`
+		HostConfig json.RawMessage `json:"HostConfig"`
+	}
+
+	if err := json.Unmarshal(container, &inspect); err != nil {
+		return Workload{}, errors.Wrap(err, "unable to parse the container")
+	}
+
+	var resources struct {
+		Memory    int64 `json:"Memory"`
+		NanoCPUs  int64 `json:"NanoCpus"`
+		CPUQuota  int64 `json:"CpuQuota"`
+		CPUPeriod int64 `json:"CpuPeriod"`
+	}
+
+	if len(inspect.HostConfig) > 0 {
+		if err := json.Unmarshal(inspect.HostConfig, &resources); err != nil {
+			return Workload{}, errors.Wrap(err, "unable to parse the container")
+		}
+	}
+
+	definition, err := json.Marshal(map[string]interface{}{
+		"Image":      inspect.Config.Image,
+		"Labels":     inspect.Config.Labels,
+		"HostConfig": inspect.HostConfig,
+	})
+	if err != nil {
+		return Workload{}, err
+	}
+
+	workload, err := ContainerWorkload(strings.TrimPrefix(inspect.Name, "/"), definition)
+	if err != nil {
+		return Workload{}, err
+	}
+
+	// the values left to 0 by the update are not changed
+	var update struct {
+		Memory    int64 `json:"Memory"`
+		NanoCPUs  int64 `json:"NanoCpus"`
+		CPUQuota  int64 `json:"CpuQuota"`
+		CPUPeriod int64 `json:"CpuPeriod"`
+	}
+
+	if err := json.Unmarshal(body, &update); err != nil {
+		return Workload{}, errors.Wrap(err, "unable to parse the container update")
+	}
+
+	if update.Memory != 0 {
+		workload.MemoryLimit = update.Memory
+	}
+
+	period := update.CPUPeriod
+	if period == 0 {
+		period = resources.CPUPeriod
+	}
+	if period == 0 {
+		period = defaultCPUPeriod
+	}
+
+	switch {
+	case update.NanoCPUs != 0:
+		workload.NanoCPUs = update.NanoCPUs
+	case update.CPUQuota > 0:
+		workload.NanoCPUs = update.CPUQuota * 1e9 / period
+	case update.CPUPeriod != 0 && resources.NanoCPUs == 0 && resources.CPUQuota > 0:
+		workload.NanoCPUs = resources.CPUQuota * 1e9 / period
+	}
+
+	return workload, nil
+}
+
+// ServiceWorkload returns the workload of a service creation request of the Docker API
+func ServiceWorkload(body []byte) (Workload, error) {
+	var service struct {
+		Name         string            `json:"Name"`
+		Labels       map[string]string `json:"Labels"`
+		TaskTemplate struct {
+			ContainerSpec struct {
+				Image    string            `json:"Image"`
+				Labels   map[string]string `json:"Labels"`
+				ReadOnly bool              `json:"ReadOnly"`
+				Mounts   []struct {
+					Type   string `json:"Type"`
+					Source string `json:"Source"`
+				} `json:"Mounts"`
+			} `json:"ContainerSpec"`
+			Resources struct {
+				Limits struct {
+					NanoCPUs    int64 `json:"NanoCPUs"`
+					MemoryBytes int64 `json:"MemoryBytes"`
+				} `json:"Limits"`
+			} `json:"Resources"`
+		} `json:"TaskTemplate"`
+		EndpointSpec struct {
+			Ports []struct {
+				PublishedPort int `json:"PublishedPort"`
+			} `json:"Ports"`
+		} `json:"EndpointSpec"`
+	}
+
+	if err := json.Unmarshal(body, &service); err != nil {
+		return Workload{}, errors.Wrap(err, "unable to parse the service definition")
+	}
+
+	containerSpec := service.TaskTemplate.ContainerSpec
+
+	workload := Workload{
+		Kind:                   "service",
+		Name:                   service.Name,
+		Image:                  containerSpec.Image,
+		Labels:                 mergeLabels(service.Labels, containerSpec.Labels),
+		MemoryLimit:            service.TaskTemplate.Resources.Limits.MemoryBytes,
+		NanoCPUs:               service.TaskTemplate.Resources.Limits.NanoCPUs,
+		ReadOnlyRootFilesystem: containerSpec.ReadOnly,
+	}
+
+	for _, port := range service.EndpointSpec.Ports {
+		if port.PublishedPort > 0 {
+			workload.PublishedPorts = append(workload.PublishedPorts, strconv.Itoa(port.PublishedPort))
+		}
+	}
+
+	for _, mount := range containerSpec.Mounts {
+		if mount.Type == "bind" {
+			workload.BindSources = append(workload.BindSources, mount.Source)
+		}
+	}
+
+	return workload, nil
+}
+
+// ComposeWorkloads returns the workloads of the services of a compose project in the JSON format, as returned
+// by `docker compose config`. The services are sorted by name
+func ComposeWorkloads(config []byte) ([]Workload, error) {
+	var project struct {
+		Services map[string]struct {
+			Image    string            `json:"image"`
+			Labels   map[string]string `json:"labels"`
+			MemLimit composeNumber     `json:"mem_limit"`
+			CPUs     composeNumber     `json:"cpus"`
+			ReadOnly bool              `json:"read_only"`
+			Ports    []struct {
+				Published composeString `json:"published"`
+			} `json:"ports"`
+			Volumes []struct {
+				Type   string `json:"type"`
+				Source string `json:"source"`
+			} `json:"volumes"`
+			Deploy *struct {
+				Labels    map[string]string `json:"labels"`
+				Resources struct {
+					Limits *struct {
+						CPUs   composeNumber `json:"cpus"`
+						Memory composeNumber `json:"memory"`
+					} `json:"limits"`
+				} `json:"resources"`
+			} `json:"deploy"`
+		} `json:"services"`
+	}
+
+	if err := json.Unmarshal(config, &project); err != nil {
+		return nil, errors.Wrap(err, "unable to parse the compose project")
+	}
+
+	workloads := make([]Workload, 0, len(project.Services))
+	for name, service := range project.Services {
+		workload := Workload{
+			Kind:                   "service",
+			Name:                   name,
+			Image:                  service.Image,
+			Labels:                 service.Labels,
+			MemoryLimit:            int64(service.MemLimit),
+			NanoCPUs:               int64(float64(service.CPUs) * 1e9),
+			ReadOnlyRootFilesystem: service.ReadOnly,
+		}
+
+		if service.Deploy != nil {
+			workload.Labels = mergeLabels(service.Labels, service.Deploy.Labels)
+
+			if limits := service.Deploy.Resources.Limits; limits != nil {
+				if limits.Memory > 0 {
+					workload.MemoryLimit = int64(limits.Memory)
+				}
+
+				if limits.CPUs > 0 {
+					workload.NanoCPUs = int64(float64(limits.CPUs) * 1e9)
+				}
+			}
+		}
+
+		for _, port := range service.Ports {
+			if port.Published != "" {
+				workload.PublishedPorts = append(workload.PublishedPorts, string(port.Published))
+			}
+		}
+
+		for _, volume := range service.Volumes {
+			if volume.Type == "bind" {
+				workload.BindSources = append(workload.BindSources, volume.Source)
+			}
+		}
+
+		workloads = append(workloads, workload)
+	}
+
+	sort.Slice(workloads, func(i, j int) bool {
+		return workloads[i].Name < workloads[j].Name
+	})
+
+	return workloads, nil
+}
+
+func mergeLabels(labels ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, set := range labels {
+		for key, value := range set {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+// composeNumber decodes the numbers that docker compose writes either as JSON numbers or as strings
+type composeNumber float64
+
+func (n *composeNumber) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		*n = 0
+		return nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid number %s", value)
+	}
+
+	*n = composeNumber(number)
+
+	return nil
+}
+
+// composeString decodes the values that docker compose writes either as JSON numbers or as strings
+type composeString string
+
+func (s *composeString) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" {
+		value = ""
+	}
+
+	*s = composeString(value)
+
+	return nil
+}
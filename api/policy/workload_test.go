@@ -0,0 +1,152 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ContainerWorkload(t *testing.T) {
+	is := assert.New(t)
+
+	workload, err := ContainerWorkload("web", []byte(`{
+		"Image": "nginx:1.25",
+		"Labels": {"owner": "team"},
+		"HostConfig": {
+			"Memory": 268435456,
+			"CpuQuota": 50000,
+			"PortBindings": {"80/tcp": [{"HostIp": "", "HostPort": "8080"}], "443/tcp": [{"HostPort": ""}]},
+			"ReadonlyRootfs": true,
+			"Binds": ["/srv/data:/data:ro", "named:/cache"],
+			"Mounts": [{"Type": "bind", "Source": "/srv/config"}, {"Type": "volume", "Source": "logs"}]
+		}
+	}`))
+	is.NoError(err)
+	is.Equal(Workload{
+		Kind:                   "container",
+		Name:                   "web",
+		Image:                  "nginx:1.25",
+		Labels:                 map[string]string{"owner": "team"},
+		MemoryLimit:            268435456,
+		NanoCPUs:               5e8,
+		PublishedPorts:         []string{"8080"},
+		ReadOnlyRootFilesystem: true,
+		BindSources:            []string{"/srv/data", "/srv/config"},
+	}, workload)
+
+	_, err = ContainerWorkload("", []byte(`{"Image": 1}`))
+	is.Error(err)
+}
+
+func Test_ContainerUpdateWorkload(t *testing.T) {
+	is := assert.New(t)
+
+	container := []byte(`{
+		"Name": "/web",
+		"Config": {"Image": "nginx:1.25", "Labels": {"owner": "team"}},
+		"HostConfig": {
+			"Memory": 268435456,
+			"CpuQuota": 50000,
+			"PortBindings": {"80/tcp": [{"HostPort": "8080"}]},
+			"Binds": ["/srv/data:/data:ro"]
+		}
+	}`)
+
+	workload, err := ContainerUpdateWorkload(container, []byte(`{"Memory": 1073741824, "NanoCpus": 2000000000}`))
+	is.NoError(err)
+	is.Equal(Workload{
+		Kind:           "container",
+		Name:           "web",
+		Image:          "nginx:1.25",
+		Labels:         map[string]string{"owner": "team"},
+		MemoryLimit:    1073741824,
+		NanoCPUs:       2e9,
+		PublishedPorts: []string{"8080"},
+		BindSources:    []string{"/srv/data"},
+	}, workload)
+
+	workload, err = ContainerUpdateWorkload(container, []byte(`{"RestartPolicy": {"Name": "always"}}`))
+	is.NoError(err)
+	is.Equal(int64(268435456), workload.MemoryLimit, "the limits which are not updated are kept")
+	is.Equal(int64(5e8), workload.NanoCPUs)
+
+	workload, err = ContainerUpdateWorkload(container, []byte(`{"CpuPeriod": 25000}`))
+	is.NoError(err)
+	is.Equal(int64(2e9), workload.NanoCPUs, "the quota of the container is applied to the new period")
+
+	_, err = ContainerUpdateWorkload(container, []byte(`{"Memory": "1g"}`))
+	is.Error(err)
+}
+
+func Test_ServiceWorkload(t *testing.T) {
+	is := assert.New(t)
+
+	workload, err := ServiceWorkload([]byte(`{
+		"Name": "web",
+		"Labels": {"owner": "team"},
+		"TaskTemplate": {
+			"ContainerSpec": {"Image": "nginx:1.25", "Labels": {"tier": "front"}, "ReadOnly": true, "Mounts": [{"Type": "bind", "Source": "/srv/data"}]},
+			"Resources": {"Limits": {"NanoCPUs": 1000000000, "MemoryBytes": 268435456}}
+		},
+		"EndpointSpec": {"Ports": [{"TargetPort": 80, "PublishedPort": 8080}, {"TargetPort": 443}]}
+	}`))
+	is.NoError(err)
+	is.Equal(Workload{
+		Kind:                   "service",
+		Name:                   "web",
+		Image:                  "nginx:1.25",
+		Labels:                 map[string]string{"owner": "team", "tier": "front"},
+		MemoryLimit:            268435456,
+		NanoCPUs:               1e9,
+		PublishedPorts:         []string{"8080"},
+		ReadOnlyRootFilesystem: true,
+		BindSources:            []string{"/srv/data"},
+	}, workload)
+}
+
+func Test_ComposeWorkloads(t *testing.T) {
+	is := assert.New(t)
+
+	workloads, err := ComposeWorkloads([]byte(`{
+		"name": "app",
+		"services": {
+			"web": {
+				"image": "nginx:1.25",
+				"labels": {"owner": "team"},
+				"mem_limit": "268435456",
+				"cpus": 0.5,
+				"read_only": true,
+				"ports": [{"mode": "ingress", "target": 80, "published": "8080", "protocol": "tcp"}, {"target": 443}],
+				"volumes": [{"type": "bind", "source": "/srv/data", "target": "/data"}, {"type": "volume", "source": "cache", "target": "/cache"}]
+			},
+			"api": {
+				"image": "registry.local/api:1",
+				"ports": [{"target": 8000, "published": 8000}],
+				"deploy": {"labels": {"tier": "back"}, "resources": {"limits": {"cpus": "1.5", "memory": 536870912}}}
+			}
+		}
+	}`))
+	is.NoError(err)
+	is.Equal([]Workload{
+		{
+			Kind:           "service",
+			Name:           "api",
+			Image:          "registry.local/api:1",
+			Labels:         map[string]string{"tier": "back"},
+			MemoryLimit:    536870912,
+			NanoCPUs:       15e8,
+			PublishedPorts: []string{"8000"},
+		},
+		{
+			Kind:                   "service",
+			Name:                   "web",
+			Image:                  "nginx:1.25",
+			Labels:                 map[string]string{"owner": "team"},
+			MemoryLimit:            268435456,
+			NanoCPUs:               5e8,
+			PublishedPorts:         []string{"8080"},
+			ReadOnlyRootFilesystem: true,
+			BindSources:            []string{"/srv/data"},
+		},
+	}, workloads)
+}
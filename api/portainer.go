@@ -9,6 +9,7 @@ import (
 	"github.com/docker/docker/api/types/volume"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/portainer/portainer/api/kubernetes/deprecation"
 	"github.com/portainer/portainer/pkg/featureflags"
 	v1 "k8s.io/api/core/v1"
 )
@@ -38,6 +39,9 @@ type (
 		Interval string `example:"1m30s"`
 		// A UUID generated from client
 		Webhook string `example:"05de31a2-79fa-4644-9c12-faa67e5c49f0"`
+		// Secret shared with the git provider, the webhook then only accepts the push events signed by GitHub, GitLab or Gitea.
+		// It is never returned by the API, the current secret is kept by the updates which do not provide one
+		WebhookSecret string `json:",omitempty" example:"my-webhook-secret"`
 		// Autoupdate job id
 		JobID string `example:"15"`
 		// Force update ignores repo changes
@@ -154,8 +158,29 @@ type (
 		Label        string `json:"label" example:"My Variable"`
 		DefaultValue string `json:"defaultValue" example:"default value"`
 		Description  string `json:"description" example:"Description"`
+		// Type of the variable, defaults to string when empty
+		Type CustomTemplateVariableType `json:"type,omitempty" example:"string" enums:"string,number,boolean,select,port,multiline,secret"`
+		// Whether a value must be provided when no default value is defined
+		Required bool `json:"required,omitempty" example:"false"`
+		// Regular expression the value must match
+		Pattern string `json:"pattern,omitempty" example:"^[a-z]+$"`
+		// Minimum value for number and port variables, minimum length for text variables
+		Min *float64 `json:"min,omitempty" example:"1"`
+		// Maximum value for number and port variables, maximum length for text variables
+		Max *float64 `json:"max,omitempty" example:"10"`
+		// Allowed values of a select variable
+		Options []CustomTemplateVariableOption `json:"options,omitempty"`
 	}
 
+	// CustomTemplateVariableOption represents one of the allowed values of a select variable
+	CustomTemplateVariableOption struct {
+		Label string `json:"label" example:"Production"`
+		Value string `json:"value" example:"prod"`
+	}
+
+	// CustomTemplateVariableType represents the type of a custom template variable
+	CustomTemplateVariableType string
+
 	// CustomTemplate represents a custom template
 	CustomTemplate struct {
 		// CustomTemplate Identifier
@@ -187,11 +212,41 @@ type (
 		GitConfig       *gittypes.RepoConfig `json:"GitConfig"`
 		// IsComposeFormat indicates if the Kubernetes template is created from a Docker Compose file
 		IsComposeFormat bool `example:"false"`
+		// Identifier of the template repository managing this template, 0 when the template is managed by hand
+		RepositoryID CustomTemplateRepositoryID `json:"RepositoryId,omitempty" example:"1"`
+		// Identifier of the template inside the manifest of its template repository
+		RepositoryKey string `json:"RepositoryKey,omitempty" example:"nginx"`
+		// Commit hash of the template repository in which the template last changed
+		Version string `json:"Version,omitempty" example:"bd54b8b7a2d5df8d2e2ae1a7f1d1e0fb50cea3ae"`
 	}
 
 	// CustomTemplateID represents a custom template identifier
 	CustomTemplateID int
 
+	// CustomTemplateRepository represents a git repository hosting many custom templates described by a manifest
+	CustomTemplateRepository struct {
+		// CustomTemplateRepository Identifier
+		ID CustomTemplateRepositoryID `json:"Id" example:"1"`
+		// Name of the repository
+		Name string `json:"Name" example:"company-templates"`
+		// Git configuration of the repository, ConfigFilePath is the path to the manifest
+		// and ConfigHash the commit of the last successful synchronization
+		GitConfig *gittypes.RepoConfig `json:"GitConfig"`
+		// Interval between two synchronizations (e.g. 1h), scheduled synchronizations are disabled when empty
+		SyncInterval string `json:"SyncInterval" example:"1h"`
+		// Identifier of the scheduler job running the synchronizations
+		JobID string `json:"JobID"`
+		// The date in unix time of the last synchronization
+		LastSyncDate int64 `json:"LastSyncDate" example:"1587399600"`
+		// Error of the last synchronization, empty when it succeeded
+		LastSyncError string `json:"LastSyncError,omitempty"`
+		// User identifier who created this repository
+		CreatedByUserID UserID `json:"CreatedByUserId" example:"1"`
+	}
+
+	// CustomTemplateRepositoryID represents a custom template repository identifier
+	CustomTemplateRepositoryID int
+
 	// CustomTemplatePlatform represents a custom template platform
 	CustomTemplatePlatform int
 
@@ -489,6 +544,8 @@ type (
 		TeamAccessPolicies TeamAccessPolicies `json:"TeamAccessPolicies"`
 		// List of tags associated to this environment(endpoint) group
 		TagIDs []TagID `json:"TagIds"`
+		// Whether the terminal sessions opened on the environments of this group are recorded
+		RecordSessions bool `json:"RecordSessions" example:"false"`
 
 		// Deprecated fields
 		Labels []Pair `json:"Labels"`
@@ -594,6 +651,124 @@ type (
 		URL string `json:"URL" example:"https://charts.bitnami.com/bitnami"`
 	}
 
+	// ImageScanID represents an image scan identifier
+	ImageScanID int
+
+	// ImageScan represents the vulnerabilities found in an image, scans are stored per image digest
+	ImageScan struct {
+		// Image scan identifier
+		ID ImageScanID `json:"Id" example:"1"`
+		// Digest of the scanned image, the repository digest when the image was pulled from a registry
+		Digest string `json:"Digest" example:"sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"`
+		// Image references resolved to the digest when they were scanned
+		References []string `json:"References" example:"nginx:1.25"`
+		// Scanner which produced the report
+		Scanner VulnerabilityScannerType `json:"Scanner" example:"trivy"`
+		// Unix timestamp (UTC) of the last scan of the image
+		ScanDate int64 `json:"ScanDate" example:"1587399600"`
+		// Number of vulnerabilities per severity
+		Summary VulnerabilitySummary `json:"Summary"`
+		// Vulnerabilities found in the packages of the image
+		Vulnerabilities []Vulnerability `json:"Vulnerabilities"`
+	}
+
+	// VolumeBackup represents an archive of the content of a Docker volume stored by Portainer
+	VolumeBackup struct {
+		// Volume backup identifier
+		ID VolumeBackupID `json:"Id" example:"1"`
+		// Environment(Endpoint) identifier of the volume
+		EndpointID EndpointID `json:"EndpointId" example:"1"`
+		// Node hosting the volume, empty for standalone environments
+		NodeName string `json:"NodeName,omitempty" example:"node-1"`
+		// Name of the backed up volume
+		VolumeName string `json:"VolumeName" example:"app_data"`
+		// Schedule which created the backup, 0 when it was created manually
+		ScheduleID VolumeBackupScheduleID `json:"ScheduleId" example:"1"`
+		// The date in unix time of the backup
+		CreationDate int64 `json:"CreationDate" example:"1587399600"`
+		// Size of the archive in bytes
+		Size int64 `json:"Size" example:"1048576"`
+		// Whether the archive is encrypted with a password
+		Encrypted bool `json:"Encrypted" example:"false"`
+	}
+
+	// VolumeBackupID represents a volume backup identifier
+	VolumeBackupID int
+
+	// VolumeBackupSchedule represents the periodic backup of volumes of a Docker environment
+	VolumeBackupSchedule struct {
+		// Volume backup schedule identifier
+		ID VolumeBackupScheduleID `json:"Id" example:"1"`
+		// Name of the schedule
+		Name string `json:"Name" example:"nightly"`
+		// Environment(Endpoint) identifier of the volumes
+		EndpointID EndpointID `json:"EndpointId" example:"1"`
+		// Node hosting the volumes, empty for standalone environments
+		NodeName string `json:"NodeName,omitempty" example:"node-1"`
+		// Names of the volumes to back up
+		VolumeNames []string `json:"VolumeNames" example:"app_data"`
+		// Name of a compose or swarm stack whose volumes are backed up, resolved on every run
+		StackName string `json:"StackName,omitempty" example:"app"`
+		// Interval between two backups (e.g. 24h)
+		Interval string `json:"Interval" example:"24h"`
+		// Number of backups kept per volume, every backup is kept when 0
+		Retention int `json:"Retention" example:"7"`
+		// Password encrypting the archives, the archives are not encrypted when empty. It is stored encrypted with the
+		// server key
+		Password string `json:"Password,omitempty" example:"passwd"`
+		// Identifier of the scheduler job running the backups
+		JobID string `json:"JobID"`
+		// The date in unix time of the last run
+		LastRunDate int64 `json:"LastRunDate" example:"1587399600"`
+		// Error of the last run, empty when it succeeded
+		LastRunError string `json:"LastRunError,omitempty"`
+	}
+
+	// VolumeBackupScheduleID represents a volume backup schedule identifier
+	VolumeBackupScheduleID int
+
+	// Vulnerability represents a known vulnerability affecting a package of an image
+	Vulnerability struct {
+		// Vulnerability identifier
+		ID string `json:"Id" example:"CVE-2023-44487"`
+		// Name of the affected package
+		Package string `json:"Package" example:"libnghttp2-14"`
+		// Version of the package installed in the image
+		InstalledVersion string `json:"InstalledVersion" example:"1.52.0-1"`
+		// Version of the package fixing the vulnerability, empty when there is no fix
+		FixedVersion string `json:"FixedVersion,omitempty" example:"1.52.0-1+deb12u1"`
+		// Severity of the vulnerability
+		Severity VulnerabilitySeverity `json:"Severity" example:"HIGH" enums:"CRITICAL,HIGH,MEDIUM,LOW,UNKNOWN"`
+		Title    string                `json:"Title,omitempty"`
+	}
+
+	// VulnerabilitySeverity represents the severity of a vulnerability
+	VulnerabilitySeverity string
+
+	// VulnerabilitySummary represents the number of vulnerabilities per severity
+	VulnerabilitySummary struct {
+		Critical int `json:"Critical" example:"0"`
+		High     int `json:"High" example:"2"`
+		Medium   int `json:"Medium" example:"5"`
+		Low      int `json:"Low" example:"12"`
+		Unknown  int `json:"Unknown" example:"0"`
+	}
+
+	// VulnerabilityScannerType represents the scanner producing the vulnerability reports
+	VulnerabilityScannerType string
+
+	// VulnerabilityScanningSettings represents the configuration of the container image vulnerability scanner
+	VulnerabilityScanningSettings struct {
+		// Whether the images of the stacks and of the running containers can be scanned
+		Enabled bool `json:"Enabled" example:"false"`
+		// Scanner producing the reports, either trivy or grype
+		Scanner VulnerabilityScannerType `json:"Scanner" example:"trivy" enums:"trivy,grype"`
+		// URL of a scanner server returning the reports, the scanner binary of the binary folder is run when empty
+		ServerURL string `json:"ServerURL" example:"http://scanner:8080"`
+		// Whether the deployment of the stacks referencing an image with a critical vulnerability, or an image which cannot be scanned, is refused
+		BlockCriticalDeployments bool `json:"BlockCriticalDeployments" example:"false"`
+	}
+
 	// QuayRegistryData represents data required for Quay registry to work
 	QuayRegistryData struct {
 		UseOrganisation  bool   `json:"UseOrganisation"`
@@ -611,6 +786,10 @@ type (
 	K8sNamespaceInfo struct {
 		IsSystem  bool `json:"IsSystem"`
 		IsDefault bool `json:"IsDefault"`
+		// IsIsolated is true when the namespace is isolated by a network policy managed by Portainer
+		IsIsolated bool `json:"IsIsolated"`
+		// NetworkPolicies are the names of the network policies managed by Portainer in the namespace
+		NetworkPolicies []string `json:"NetworkPolicies,omitempty"`
 	}
 
 	K8sNodeLimits struct {
@@ -764,12 +943,106 @@ type (
 		KubeSecretKey        []byte `json:"KubeSecretKey"`
 	}
 
+	// by users who are not approvers, administrators included once an approver team is configured
+	// by users who are neither administrators nor members of an approver team
+	OperationApprovalSettings struct {
+		// Authorizations of the operations requiring an approval
+		Authorizations []Authorization `json:"Authorizations"`
+		// Teams whose members can perform gated operations and review pending operations, administrators are only
+		// approvers when no team is configured
+		ApproverTeamIDs []TeamID `json:"ApproverTeamIds"`
+		// Duration after which a pending operation expires, defaults to 24h
+		Expiry string `json:"Expiry" example:"24h"`
+	}
+
 	// Pair defines a key/value string pair
 	Pair struct {
 		Name  string `json:"name" example:"name"`
 		Value string `json:"value" example:"value"`
 	}
 
+	// PendingOperation represents a gated API request waiting for an approval before being executed
+	PendingOperation struct {
+		// Pending operation identifier
+		ID PendingOperationID `json:"Id" example:"1"`
+		// Authorization of the requested operation
+		Authorization Authorization `json:"Authorization" example:"PortainerStackDelete"`
+		// HTTP method of the request
+		Method string `json:"Method" example:"DELETE"`
+		// Request URI of the request
+		URL string `json:"URL" example:"/api/stacks/1?endpointId=1"`
+		// Content type of the request body
+		ContentType string `json:"ContentType,omitempty" example:"application/json"`
+		// Body of the request
+		Body []byte `json:"Body,omitempty"`
+		// Environment identifier targeted by the request, when known
+		EndpointID EndpointID `json:"EndpointId,omitempty" example:"1"`
+		// Status of the operation (1 - pending, 2 - rejected, 3 - expired, 4 - executed, 5 - failed)
+		Status PendingOperationStatus `json:"Status" example:"1"`
+		// Identifier of the user who requested the operation
+		RequestedBy UserID `json:"RequestedBy" example:"2"`
+		// The date in unix time when the operation was requested
+		CreationDate int64 `json:"CreationDate" example:"1587399600"`
+		// The date in unix time after which the operation cannot be approved anymore
+		ExpiryDate int64 `json:"ExpiryDate" example:"1587486000"`
+		// Identifier of the user who approved or rejected the operation
+		ReviewedBy UserID `json:"ReviewedBy,omitempty" example:"1"`
+		// The date in unix time when the operation was approved or rejected
+		ReviewDate int64 `json:"ReviewDate,omitempty" example:"1587399600"`
+		// Comment left by the reviewer
+		ReviewComment string `json:"ReviewComment,omitempty"`
+		// HTTP status code returned by the operation once executed
+		ResultStatusCode int `json:"ResultStatusCode,omitempty" example:"204"`
+		// Response returned by the operation once executed
+		Result string `json:"Result,omitempty"`
+	}
+
+	// PendingOperationID represents a pending operation identifier
+	PendingOperationID int
+
+	// PendingOperationStatus represents the status of a pending operation
+	PendingOperationStatus int
+
+	// Policy represents a set of rules the containers and services created on the environments of its groups must follow
+	Policy struct {
+		// Policy identifier
+		ID PolicyID `json:"Id" example:"1"`
+		// Policy name
+		Name string `json:"Name" example:"production"`
+		// Policy description
+		Description string `json:"Description" example:"Rules of the production environments"`
+		// Whether the violations refuse the requests or are only logged
+		Mode PolicyMode `json:"Mode" example:"enforce" enums:"enforce,audit"`
+		// Environment(Endpoint) groups the policy applies to
+		EndpointGroupIDs []EndpointGroupID `json:"EndpointGroupIds"`
+		// Rules of the policy
+		Rules PolicyRules `json:"Rules"`
+	}
+
+	// PolicyID represents a policy identifier
+	PolicyID int
+
+	// PolicyMode represents how the violations of a policy are handled
+	PolicyMode string
+
+	// PolicyRules represents the rules of a policy, a rule left empty is not checked
+	PolicyRules struct {
+		// Registries the images can be pulled from, the images without registry are pulled from docker.io
+		AllowedRegistries []string `json:"AllowedRegistries" example:"registry.mydomain.tld"`
+		// Labels every container and service must have
+		RequiredLabels []string `json:"RequiredLabels" example:"owner"`
+		// Maximum memory limit in bytes, a limit must be set when defined
+		MaxMemory int64 `json:"MaxMemory" example:"536870912"`
+		// Maximum number of CPUs, a limit must be set when defined
+		MaxCPUs float64 `json:"MaxCPUs" example:"1.5"`
+		// Host ports or port ranges that cannot be published
+		ForbiddenPorts []string `json:"ForbiddenPorts" example:"22,1-1023"`
+		// Whether the root filesystem of the containers must be read-only
+		ReadOnlyRootFilesystem bool `json:"ReadOnlyRootFilesystem" example:"false"`
+		// Host paths under which the bind mounts are allowed, any path is allowed when empty
+		AllowedBindPaths []string `json:"AllowedBindPaths" example:"/srv/data"`
+	}
+
 	// Registry represents a Docker registry with all the info required
 	// to connect to it
 	Registry struct {
@@ -809,6 +1082,11 @@ type (
 		// Stores temporary access token
 		AccessToken       string `json:"AccessToken,omitempty"`
 		AccessTokenExpiry int64  `json:"AccessTokenExpiry,omitempty"`
+
+		// Unix timestamp after which the configured credentials are no longer valid, 0 if they never expire
+		CredentialsExpiry int64 `json:"CredentialsExpiry,omitempty" example:"1700000000"`
+		// Unix timestamp of the last credentials rotation
+		CredentialsRotatedAt int64 `json:"CredentialsRotatedAt,omitempty" example:"1690000000"`
 	}
 
 	RegistryAccesses map[EndpointID]RegistryAccessPolicies
@@ -873,6 +1151,61 @@ type (
 	// ResourceControlType represents the type of resource associated to the resource control (volume, container, service...)
 	ResourceControlType int
 
+	// ResourceUsage represents the CPU, memory and network use of one or several containers
+	ResourceUsage struct {
+		// CPU use in percent of a single CPU, computed since the previous sample
+		CPUPercent float64 `json:"CPUPercent" example:"12.5"`
+		// Memory use in bytes, excluding the page cache
+		MemoryUsage uint64 `json:"MemoryUsage" example:"104857600"`
+		// Bytes received per second, computed since the previous sample
+		NetworkRxRate float64 `json:"NetworkRxRate" example:"2048"`
+		// Bytes transmitted per second, computed since the previous sample
+		NetworkTxRate float64 `json:"NetworkTxRate" example:"1024"`
+	}
+
+	// ResourceUsageSampleID represents a resource usage sample identifier
+	ResourceUsageSampleID int
+
+	// ResourceUsageSample represents the resource usage of the running containers of a Docker environment at a point in time,
+	// aggregated per stack and for the whole environment
+	ResourceUsageSample struct {
+		// Resource usage sample identifier
+		ID         ResourceUsageSampleID `json:"Id" example:"1"`
+		EndpointID EndpointID            `json:"EndpointId" example:"1"`
+		// Unix timestamp (UTC) when the sample was collected
+		Time int64 `json:"Time" example:"1587399600"`
+		// Resource usage of all the running containers of the environment
+		Total      ResourceUsage            `json:"Total"`
+		Stacks     []StackResourceUsage     `json:"Stacks"`
+		Containers []ContainerResourceUsage `json:"Containers"`
+	}
+
+	// StackResourceUsage represents the resource usage of the running containers of a compose or Swarm stack
+	StackResourceUsage struct {
+		ResourceUsage
+		// Name of the compose project or of the Swarm stack
+		Name           string `json:"Name" example:"myStack"`
+		ContainerCount int    `json:"ContainerCount" example:"3"`
+	}
+
+	// ContainerResourceUsage represents the resource usage of a container, along with the counters
+	// used to compute the rates of the next sample
+	ContainerResourceUsage struct {
+		ResourceUsage
+		ID   string `json:"Id" example:"8a1b3a5f0c8e"`
+		Name string `json:"Name" example:"myStack-web-1"`
+		// Name of the compose project or of the Swarm stack of the container
+		Stack string `json:"Stack,omitempty" example:"myStack"`
+		// Cumulated CPU time of the container in nanoseconds
+		CPUTotalUsage uint64 `json:"CPUTotalUsage"`
+		// Cumulated CPU time of the host in nanoseconds
+		SystemCPUUsage uint64 `json:"SystemCPUUsage"`
+		// Number of CPUs available to the container
+		OnlineCPUs     uint32 `json:"OnlineCPUs"`
+		NetworkRxBytes uint64 `json:"NetworkRxBytes"`
+		NetworkTxBytes uint64 `json:"NetworkTxBytes"`
+	}
+
 	// Role represents a set of authorizations that can be associated to a user or
 	// to a team.
 	Role struct {
@@ -904,6 +1237,113 @@ type (
 		Digest      []byte   `json:"digest,omitempty"` // Digest represents SHA256 hash of the raw API key
 	}
 
+	// KubeconfigTokenID represents a kubeconfig token identifier
+	KubeconfigTokenID int
+
+	// KubeconfigTokenMode represents the way a kubeconfig file authenticates against Portainer
+	KubeconfigTokenMode string
+
+	// KubeconfigToken represents a token issued for a kubeconfig file, it can be revoked at any time
+	KubeconfigToken struct {
+		// Kubeconfig token identifier
+		ID KubeconfigTokenID `json:"Id" example:"1"`
+		// Identifier of the user owning the kubeconfig file
+		UserID UserID `json:"UserId" example:"1"`
+		// Mode of the kubeconfig file, either an embedded bearer token or an exec-credential plugin
+		Mode KubeconfigTokenMode `json:"Mode" example:"token"`
+		// Unix timestamp (UTC) when the kubeconfig file was generated
+		CreationDate int64 `json:"CreationDate"`
+		// Unix timestamp (UTC) when the embedded bearer token expires, 0 when it never expires
+		ExpiryDate int64 `json:"ExpiryDate"`
+		// Unix timestamp (UTC) when the kubeconfig file was last used
+		LastUsed int64 `json:"LastUsed"`
+		// Digest represents the SHA256 hash of the secret used by the exec-credential plugin to request tokens
+		Digest []byte `json:"Digest,omitempty"`
+	}
+
+	// GitSSHKeyID represents a git SSH key identifier
+	GitSSHKeyID int
+
+	// GitSSHKey represents an SSH key pair used to authenticate against git repositories
+	GitSSHKey struct {
+		// Git SSH key identifier
+		ID GitSSHKeyID `json:"Id" example:"1"`
+		// Identifier of the user owning the key
+		UserID UserID `json:"UserId" example:"1"`
+		Name   string `json:"Name" example:"github-deploy-key"`
+		// Public key in the authorized_keys format, to be added to the repository as a deploy key
+		PublicKey string `json:"PublicKey" example:"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIPw..."`
+		// SHA256 fingerprint of the public key
+		Fingerprint string `json:"Fingerprint" example:"SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8"`
+		// PEM encoded private key, never returned by the API
+		PrivateKey string `json:"PrivateKey,omitempty"`
+		// Passphrase of the private key, never returned by the API
+		Passphrase string `json:"Passphrase,omitempty"`
+		// Host keys of the git servers in the known_hosts format, the connection is refused when the server key is not pinned
+		KnownHosts string `json:"KnownHosts" example:"github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"`
+		// Unix timestamp (UTC) when the key was created
+		CreationDate int64 `json:"CreationDate"`
+	}
+
+	// GitCredentialID represents a git credential identifier
+	GitCredentialID int
+
+	// GitCredentialType represents the type of a git credential
+	GitCredentialType string
+
+	// GitCredential represents credentials shared by the git repositories of the stacks, edge stacks and custom templates.
+	// The repositories reference the credential so that rotating its secret updates all of them.
+	GitCredential struct {
+		// Git credential identifier
+		ID   GitCredentialID `json:"Id" example:"1"`
+		Name string          `json:"Name" example:"github-ci"`
+		// Type of the credential, basic, token or ssh
+		Type GitCredentialType `json:"Type" example:"basic" enums:"basic,token,ssh"`
+		// Identifier of the user owning the credential, only the owner and the administrators can update it
+		UserID UserID `json:"UserId" example:"1"`
+		// Identifiers of the teams whose members can use the credential in their repositories
+		TeamIDs []TeamID `json:"TeamIds"`
+		// Username of the basic and token credentials
+		Username string `json:"Username" example:"myGitUsername"`
+		// Password or access token, never returned by the API
+		Password string `json:"Password,omitempty"`
+		// PEM encoded private key of the ssh credentials, never returned by the API
+		PrivateKey string `json:"PrivateKey,omitempty"`
+		// Passphrase of the private key, never returned by the API
+		Passphrase string `json:"Passphrase,omitempty"`
+		// Public key of the ssh credentials in the authorized_keys format
+		PublicKey string `json:"PublicKey,omitempty" example:"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIPw..."`
+		// SHA256 fingerprint of the public key of the ssh credentials
+		Fingerprint string `json:"Fingerprint,omitempty" example:"SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8"`
+		// Host keys of the git servers in the known_hosts format, required by the ssh credentials
+		KnownHosts string `json:"KnownHosts,omitempty"`
+		// Unix timestamp (UTC) when the credential was created
+		CreationDate int64 `json:"CreationDate"`
+		// Unix timestamp (UTC) when the secret of the credential was last rotated
+		RotationDate int64 `json:"RotationDate"`
+	}
+
+	// GitSigningKeyID represents a git signing key identifier
+	GitSigningKeyID int
+
+	// GitSigningKeyType represents the type of a git signing key
+	GitSigningKeyType string
+
+	// GitSigningKey represents a public key of the keyring trusted to sign the commits deployed by GitOps
+	GitSigningKey struct {
+		// Git signing key identifier
+		ID   GitSigningKeyID `json:"Id" example:"1"`
+		Name string          `json:"Name" example:"release-team"`
+		// Type of the key, gpg or ssh
+		Type GitSigningKeyType `json:"Type" example:"ssh"`
+		// Armored GPG public key or SSH public key in the authorized_keys format
+		PublicKey string `json:"PublicKey" example:"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIPw..."`
+		// Fingerprint of the GPG primary key or SHA256 fingerprint of the SSH key
+		Fingerprint string `json:"Fingerprint" example:"SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8"`
+		// Unix timestamp (UTC) when the key was added
+		CreationDate int64 `json:"CreationDate"`
+	}
+
 	// Schedule represents a scheduled job.
 	// It only contains a pointer to one of the JobRunner implementations
 	// based on the JobType.
@@ -975,6 +1415,14 @@ type (
 		AgentSecret string `json:"AgentSecret"`
 		// EdgePortainerURL is the URL that is exposed to edge agents
 		EdgePortainerURL string `json:"EdgePortainerUrl"`
+		// Operations requiring an approval
+		OperationApproval OperationApprovalSettings `json:"OperationApproval"`
+		// Duration after which terminal session recordings are removed, recordings are kept forever when empty
+		SessionRecordingRetention string `json:"SessionRecordingRetention" example:"720h"`
+		// Container image vulnerability scanning and the deployment policy based on its results
+		VulnerabilityScanning VulnerabilityScanningSettings `json:"VulnerabilityScanning"`
+		// Duration after which the container resource usage samples are removed, defaults to 24h
+		ResourceUsageRetention string `json:"ResourceUsageRetention" example:"24h"`
 
 		Edge struct {
 			// The command list interval for edge agent - used in edge async mode (in seconds)
@@ -1005,6 +1453,46 @@ type (
 		IsDockerDesktopExtension bool `json:"IsDockerDesktopExtension"`
 	}
 
+	// SessionRecording represents the recording of an interactive terminal session, stored in asciicast v2 format
+	SessionRecording struct {
+		// Session recording identifier
+		ID SessionRecordingID `json:"Id" example:"1"`
+		// Type of the session (exec, attach, pod or kubernetes-shell)
+		Type SessionRecordingType `json:"Type" example:"exec"`
+		// Identifier of the user who opened the session
+		UserID UserID `json:"UserId" example:"1"`
+		// Name of the user who opened the session
+		Username string `json:"Username" example:"admin"`
+		// Environment identifier where the session was opened
+		EndpointID EndpointID `json:"EndpointId" example:"1"`
+		// Identifier of the Docker container the session is attached to
+		ContainerID string `json:"ContainerId,omitempty" example:"e6e1f8a9c2f4"`
+		// Identifier of the Docker exec instance of the session
+		ExecID string `json:"ExecId,omitempty" example:"8d6e9b1bd5f3"`
+		// Kubernetes namespace of the pod
+		Namespace string `json:"Namespace,omitempty" example:"default"`
+		// Name of the Kubernetes pod
+		PodName string `json:"PodName,omitempty" example:"nginx-5d6b4c6f7-kq2x8"`
+		// Name of the container inside the Kubernetes pod
+		ContainerName string `json:"ContainerName,omitempty" example:"nginx"`
+		// Command executed inside the Kubernetes pod
+		Command string `json:"Command,omitempty" example:"sh"`
+		// The date in unix time when the session started
+		StartDate int64 `json:"StartDate" example:"1587399600"`
+		// The date in unix time when the session ended, 0 while the session is running
+		EndDate int64 `json:"EndDate" example:"1587399900"`
+		// Duration of the session in seconds
+		Duration float64 `json:"Duration" example:"300.5"`
+		// Size of the recording in bytes
+		Size int64 `json:"Size" example:"4096"`
+	}
+
+	// SessionRecordingID represents a session recording identifier
+	SessionRecordingID int
+
+	// SessionRecordingType represents the type of a recorded terminal session
+	SessionRecordingType string
+
 	// SnapshotJob represents a scheduled job that can create environment(endpoint) snapshots
 	SnapshotJob struct{}
 
@@ -1035,6 +1523,8 @@ type (
 		EntryPoint string `json:"EntryPoint" example:"docker-compose.yml"`
 		// A list of environment(endpoint) variables used during stack deployment
 		Env []Pair `json:"Env"`
+		// Secret variables used during stack deployment, encrypted with the server key
+		SecretEnv string `json:"SecretEnv,omitempty"`
 		//
 		ResourceControl *ResourceControl `json:"ResourceControl"`
 		// Stack status (1 - active, 2 - inactive)
@@ -1063,6 +1553,22 @@ type (
 		Namespace string `example:"default"`
 		// IsComposeFormat indicates if the Kubernetes stack is created from a Docker Compose file
 		IsComposeFormat bool `example:"false"`
+		// Identifier of the custom template the stack was created from
+		CustomTemplateID CustomTemplateID `json:"CustomTemplateId,omitempty" example:"1"`
+		// Version of the custom template the stack is running
+		CustomTemplateVersion string `json:"CustomTemplateVersion,omitempty" example:"bd54b8b7a2d5df8d2e2ae1a7f1d1e0fb50cea3ae"`
+		// Revision deployed by the last promotion targeting this stack
+		PromotedRevision *StackRevision `json:"PromotedRevision,omitempty"`
+		// Kustomization the manifests of the Kubernetes git stack are rendered from, the entry point is then its kustomization file
+		Kustomization *KubernetesKustomization `json:"Kustomization,omitempty"`
+	}
+
+	// KubernetesKustomization represents the kustomization a Kubernetes git stack is rendered from
+	KubernetesKustomization struct {
+		// Path of the kustomization directory inside the git repository
+		Path string `example:"deploy"`
+		// Overlay rendered, relative to the kustomization directory. The kustomization directory itself is rendered when empty
+		Overlay string `example:"overlays/production"`
 	}
 
 	// StackOption represents the options for stack deployment
@@ -1074,6 +1580,76 @@ type (
 	// StackID represents a stack identifier (it must be composed of Name + "_" + SwarmID to create a unique identifier)
 	StackID int
 
+	// StackPromotion represents the request to redeploy the current revision of a stack on another environment
+	StackPromotion struct {
+		// StackPromotion Identifier
+		ID StackPromotionID `json:"Id" example:"1"`
+		// Identifier of the promoted stack
+		SourceStackID StackID `json:"SourceStackId" example:"1"`
+		// Environment identifier of the promoted stack
+		SourceEndpointID EndpointID `json:"SourceEndpointId" example:"1"`
+		// Environment identifier where the revision is deployed
+		TargetEndpointID EndpointID `json:"TargetEndpointId" example:"2"`
+		// Identifier of the stack running the revision on the target environment, 0 until the first deployment
+		TargetStackID StackID `json:"TargetStackId" example:"2"`
+		// Name of the stack on the target environment
+		TargetName string `json:"TargetName" example:"myStack"`
+		// Kubernetes namespace of the stack on the target environment
+		TargetNamespace string `json:"TargetNamespace,omitempty" example:"default"`
+		// Cluster identifier of the Swarm cluster of the target environment
+		TargetSwarmID string `json:"TargetSwarmId,omitempty" example:"jpofkc0i9uo9wtx1zesuk649w"`
+		// Stack type. 1 for a Swarm stack, 2 for a Compose stack, 3 for a Kubernetes stack
+		Type StackType `json:"Type" example:"2"`
+		// Path to the stack file inside the captured files
+		EntryPoint string `json:"EntryPoint" example:"docker-compose.yml"`
+		// Additional stack files inside the captured files
+		AdditionalFiles []string `json:"AdditionalFiles"`
+		// IsComposeFormat indicates if the Kubernetes stack is created from a Docker Compose file
+		IsComposeFormat bool `json:"IsComposeFormat" example:"false"`
+		// Revision of the stack files captured when the promotion was requested
+		Revision StackRevision `json:"Revision"`
+		// Environment variables overridden for the target environment
+		EnvOverrides []Pair `json:"EnvOverrides"`
+		// Environment variables used on the target environment, the source values merged with the overrides
+		Env []Pair `json:"Env"`
+		// Role a user must hold on the target environment to approve the promotion, only administrators can approve when 0
+		ApproverRoleID RoleID `json:"ApproverRoleId" example:"1"`
+		// Promotion status (1 - pending, 2 - rejected, 3 - deployed, 4 - failed)
+		Status StackPromotionStatus `json:"Status" example:"1"`
+		// Identifier of the user who requested the promotion
+		RequestedBy UserID `json:"RequestedBy" example:"2"`
+		// The date in unix time when the promotion was requested
+		CreationDate int64 `json:"CreationDate" example:"1587399600"`
+		// Identifier of the user who approved or rejected the promotion
+		ReviewedBy UserID `json:"ReviewedBy,omitempty" example:"1"`
+		// The date in unix time when the promotion was approved or rejected
+		ReviewDate int64 `json:"ReviewDate,omitempty" example:"1587399600"`
+		// Comment left by the reviewer
+		ReviewComment string `json:"ReviewComment,omitempty" example:"LGTM"`
+		// Error of the deployment when it failed
+		Error string `json:"Error,omitempty"`
+	}
+
+	// StackPromotionID represents a stack promotion identifier
+	StackPromotionID int
+
+	// StackPromotionStatus represents the status of a stack promotion
+	StackPromotionStatus int
+
+	// StackRevision identifies the exact files and environment of a stack deployment
+	StackRevision struct {
+		// Identifier of the stack the revision comes from
+		SourceStackID StackID `json:"SourceStackId" example:"1"`
+		// Identifier of the promotion which deployed the revision
+		PromotionID StackPromotionID `json:"PromotionId,omitempty" example:"1"`
+		// SHA-256 of the stack files
+		FileHash string `json:"FileHash" example:"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"`
+		// Git commit of the stack files, empty when the stack is not git based
+		CommitHash string `json:"CommitHash,omitempty" example:"bd54b8b7a2d5df8d2e2ae1a7f1d1e0fb50cea3ae"`
+		// The date in unix time when the revision was deployed
+		DeploymentDate int64 `json:"DeploymentDate,omitempty" example:"1587399600"`
+	}
+
 	// StackStatus represent a status for a stack
 	StackStatus int
 
@@ -1374,6 +1950,8 @@ type (
 		Up(ctx context.Context, stack *Stack, endpoint *Endpoint, forceRecreate bool) error
 		Down(ctx context.Context, stack *Stack, endpoint *Endpoint) error
 		Pull(ctx context.Context, stack *Stack, endpoint *Endpoint) error
+		Validate(ctx context.Context, stack *Stack) error
+		Config(ctx context.Context, stack *Stack) ([]byte, error)
 	}
 
 	// CryptoService represents a service for encrypting/hashing data
@@ -1382,6 +1960,12 @@ type (
 		CompareHashAndData(hash string, data string) error
 	}
 
+	// SecretService represents a service to encrypt the secrets stored in the database
+	SecretService interface {
+		Encrypt(secret string) (string, error)
+		Decrypt(encrypted string) (string, error)
+	}
+
 	// DigitalSignatureService represents a service to manage digital signatures
 	DigitalSignatureService interface {
 		ParseKeyPair(private, public []byte) error
@@ -1396,6 +1980,11 @@ type (
 		CreateSnapshot(endpoint *Endpoint) (*DockerSnapshot, error)
 	}
 
+	// ResourceUsageRecorder represents a service used to record the resource usage of the containers of a Docker environment(endpoint)
+	ResourceUsageRecorder interface {
+		Record(endpoint *Endpoint) error
+	}
+
 	// FileService represents a service for managing files
 	FileService interface {
 		GetDockerConfigPath() string
@@ -1426,6 +2015,7 @@ type (
 		KeyPairFilesExist() (bool, error)
 		StoreKeyPair(private, public []byte, privatePEMHeader, publicPEMHeader string) error
 		LoadKeyPair() ([]byte, []byte, error)
+		LoadOrCreateSecretKey() ([]byte, error)
 		WriteJSONToFile(path string, content interface{}) error
 		FileExists(path string) (bool, error)
 		StoreEdgeJobFileFromBytes(identifier string, data []byte) (string, error)
@@ -1436,6 +2026,9 @@ type (
 		GetBinaryFolder() string
 		StoreCustomTemplateFileFromBytes(identifier, fileName string, data []byte) (string, error)
 		GetCustomTemplateProjectPath(identifier string) string
+		GetStackPromotionPath(identifier string) string
+		GetSessionRecordingPath(identifier string) string
+		GetVolumeBackupPath(fileName string) string
 		GetTemporaryPath() (string, error)
 		GetDatastorePath() string
 		GetDefaultSSLCertsPath() (string, string)
@@ -1450,10 +2043,12 @@ type (
 
 	// GitService represents a service for managing Git
 	GitService interface {
-		CloneRepository(destination string, repositoryURL, referenceName, username, password string, tlsSkipVerify bool) error
-		LatestCommitID(repositoryURL, referenceName, username, password string, tlsSkipVerify bool) (string, error)
-		ListRefs(repositoryURL, username, password string, hardRefresh bool, tlsSkipVerify bool) ([]string, error)
-		ListFiles(repositoryURL, referenceName, username, password string, dirOnly, hardRefresh bool, includeExts []string, tlsSkipVerify bool) ([]string, error)
+		CloneRepository(destination string, repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool, content *gittypes.ContentOptions) error
+		CloneRepositoryCommit(destination string, repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool, content *gittypes.ContentOptions) (string, error)
+		LatestCommitID(repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool) (string, error)
+		ListRefs(repositoryURL string, auth *gittypes.GitAuthentication, hardRefresh bool, tlsSkipVerify bool) ([]string, error)
+		ListFiles(repositoryURL, referenceName string, auth *gittypes.GitAuthentication, dirOnly, hardRefresh bool, includeExts []string, tlsSkipVerify bool, content *gittypes.ContentOptions) ([]string, error)
+		CommitSignature(repositoryURL, referenceName string, auth *gittypes.GitAuthentication, tlsSkipVerify bool) (*gittypes.CommitSignature, error)
 	}
 
 	// OpenAMTService represents a service for managing OpenAMT
@@ -1493,18 +2088,50 @@ type (
 		GetServices(namespace string, lookupApplications bool) ([]models.K8sServiceInfo, error)
 		DeleteServices(reqs models.K8sServiceDeleteRequests) error
 		GetNodesLimits() (K8sNodesLimits, error)
+		GetNamespaceNodesLimits(namespace string) (K8sNodesLimits, error)
+		GetNamespaceResourceQuotas(namespace string) ([]models.K8sResourceQuotaUsage, error)
 		GetNamespaceAccessPolicies() (map[string]K8sNamespaceAccessPolicy, error)
 		UpdateNamespaceAccessPolicies(accessPolicies map[string]K8sNamespaceAccessPolicy) error
 		DeleteRegistrySecret(registry *Registry, namespace string) error
 		CreateRegistrySecret(registry *Registry, namespace string) error
 		IsRegistrySecret(namespace, secretName string) (bool, error)
 		ToggleSystemState(namespace string, isSystem bool) error
+		ToggleNamespaceIsolation(namespace string, isolation models.K8sNamespaceIsolation) error
+		GetNetworkPolicies(namespace string) ([]models.K8sNetworkPolicy, error)
+		GetNetworkPolicy(namespace, name string) (models.K8sNetworkPolicy, error)
+		DeleteNetworkPolicy(namespace, name string) error
+		HasNamespaceAccess(namespace string, userID int, teamIDs []int, restrictDefaultNamespace bool) (bool, error)
+		GetUserNamespaces(userID int, teamIDs []int, restrictDefaultNamespace bool) ([]string, error)
+		RestartWorkload(namespace, kind, name, username string) error
+		ScaleWorkload(namespace, kind, name string, replicas int32, username string) error
+		PauseWorkloadRollout(namespace, kind, name string, paused bool, username string) error
+		RollbackWorkload(namespace, kind, name string, revision int64, username string) error
+		GetWorkloadRolloutHistory(namespace, kind, name string) ([]models.K8sRolloutRevision, error)
+		GetEvents(ctx context.Context, filter models.K8sObservabilityFilter) ([]models.K8sEvent, error)
+		StreamLogs(ctx context.Context, filter models.K8sObservabilityFilter, send func(models.K8sLogLine) error) error
+		GetStorageClasses() ([]models.K8sStorageClass, error)
+		GetPersistentVolumes() ([]models.K8sPersistentVolume, error)
+		GetPersistentVolumeClaims(namespace string, withUsage bool) ([]models.K8sPersistentVolumeClaim, error)
+		ExpandPersistentVolumeClaim(namespace, name, size string) error
+		GetVolumeSnapshots(namespace string) ([]models.K8sVolumeSnapshot, error)
+		CreateVolumeSnapshot(namespace, claimName string, payload models.K8sVolumeSnapshotPayload) (models.K8sVolumeSnapshot, error)
+		DeleteVolumeSnapshot(namespace, name string) error
+		RestoreVolumeSnapshot(namespace, name string, payload models.K8sVolumeSnapshotRestorePayload) (models.K8sPersistentVolumeClaim, error)
+		GetCustomResourceDefinitions() ([]models.K8sCustomResourceDefinition, error)
+		GetCustomResources(definitionName, namespace string) ([]models.K8sCustomResource, error)
+		GetCustomResource(definitionName, namespace, name string) (models.K8sCustomResourceDetails, error)
+		UpdateCustomResource(definitionName, namespace, name, manifest string) (models.K8sCustomResourceDetails, error)
+		DeleteCustomResource(definitionName, namespace, name string) error
+		GetKubernetesVersion() (string, error)
+		GetDeprecatedAPIFindings(target deprecation.Version) ([]models.K8sDeprecatedAPIFinding, error)
 	}
 
 	// KubernetesDeployer represents a service to deploy a manifest inside a Kubernetes environment(endpoint)
 	KubernetesDeployer interface {
 		Deploy(userID UserID, endpoint *Endpoint, manifestFiles []string, namespace string) (string, error)
 		Remove(userID UserID, endpoint *Endpoint, manifestFiles []string, namespace string) (string, error)
+		DryRun(userID UserID, endpoint *Endpoint, manifestFiles []string, namespace string) (string, error)
+		Inspect(userID UserID, endpoint *Endpoint, manifestFiles []string, namespace string) (string, error)
 		ConvertCompose(data []byte) ([]byte, error)
 	}
 
@@ -1608,6 +2235,12 @@ const (
 	DefaultUserSessionTimeout = "8h"
 	// DefaultUserSessionTimeout represents the default timeout after which the user session is cleared
 	DefaultKubeconfigExpiry = "0"
+	// DefaultPendingOperationExpiry represents the default duration after which a pending operation expires
+	DefaultPendingOperationExpiry = "24h"
+	// DefaultResourceUsageRetention represents the default duration after which a resource usage sample is removed
+	DefaultResourceUsageRetention = "24h"
+	// KubeconfigExecTokenExpiry represents the lifetime of the tokens requested by the kubeconfig exec-credential plugin
+	KubeconfigExecTokenExpiry = 15 * time.Minute
 	// DefaultKubectlShellImage represents the default image and tag for the kubectl shell
 	DefaultKubectlShellImage = "portainer/kubectl-shell"
 	// WebSocketKeepAlive web socket keep alive for edge environments
@@ -1623,6 +2256,44 @@ var SupportedFeatureFlags = []featureflags.Feature{
 	FeatureFdo,
 }
 
+const (
+	// GitCredentialTypeBasic represents a username and a password
+	GitCredentialTypeBasic GitCredentialType = "basic"
+	// GitCredentialTypeToken represents a personal or project access token, the username is optional
+	GitCredentialTypeToken GitCredentialType = "token"
+	// GitCredentialTypeSSH represents an SSH private key
+	GitCredentialTypeSSH GitCredentialType = "ssh"
+)
+
+const (
+	// GitSigningKeyTypeGPG represents an armored GPG public key
+	GitSigningKeyTypeGPG GitSigningKeyType = "gpg"
+	// GitSigningKeyTypeSSH represents an SSH public key used with the git ssh signature format
+	GitSigningKeyTypeSSH GitSigningKeyType = "ssh"
+)
+
+const (
+	// VulnerabilityScannerTrivy represents the Trivy scanner and its JSON report format
+	VulnerabilityScannerTrivy VulnerabilityScannerType = "trivy"
+	// VulnerabilityScannerGrype represents the Grype scanner and its JSON report format
+	VulnerabilityScannerGrype VulnerabilityScannerType = "grype"
+)
+
+const (
+	// PolicyModeEnforce refuses the requests violating the policy
+	PolicyModeEnforce PolicyMode = "enforce"
+	// PolicyModeAudit only logs the violations of the policy
+	PolicyModeAudit PolicyMode = "audit"
+)
+
+const (
+	VulnerabilitySeverityCritical VulnerabilitySeverity = "CRITICAL"
+	VulnerabilitySeverityHigh     VulnerabilitySeverity = "HIGH"
+	VulnerabilitySeverityMedium   VulnerabilitySeverity = "MEDIUM"
+	VulnerabilitySeverityLow      VulnerabilitySeverity = "LOW"
+	VulnerabilitySeverityUnknown  VulnerabilitySeverity = "UNKNOWN"
+)
+
 const (
 	_ AuthenticationMethod = iota
 	// AuthenticationInternal represents the internal authentication method (authentication against Portainer API)
@@ -1659,6 +2330,23 @@ const (
 	CustomTemplatePlatformWindows
 )
 
+const (
+	// CustomTemplateVariableTypeString represents a single line text variable
+	CustomTemplateVariableTypeString CustomTemplateVariableType = "string"
+	// CustomTemplateVariableTypeNumber represents a numeric variable
+	CustomTemplateVariableTypeNumber CustomTemplateVariableType = "number"
+	// CustomTemplateVariableTypeBoolean represents a true/false variable
+	CustomTemplateVariableTypeBoolean CustomTemplateVariableType = "boolean"
+	// CustomTemplateVariableTypeSelect represents a variable restricted to a list of options
+	CustomTemplateVariableTypeSelect CustomTemplateVariableType = "select"
+	// CustomTemplateVariableTypePort represents a network port variable
+	CustomTemplateVariableTypePort CustomTemplateVariableType = "port"
+	// CustomTemplateVariableTypeMultiline represents a multiline text variable
+	CustomTemplateVariableTypeMultiline CustomTemplateVariableType = "multiline"
+	// CustomTemplateVariableTypeSecret represents a sensitive variable that is stored encrypted instead of in the stack environment
+	CustomTemplateVariableTypeSecret CustomTemplateVariableType = "secret"
+)
+
 const (
 	// EdgeStackDeploymentCompose represent an edge stack deployed using a compose file
 	EdgeStackDeploymentCompose EdgeStackDeploymentType = iota
@@ -1802,6 +2490,50 @@ const (
 	StackStatusInactive
 )
 
+const (
+	_ StackPromotionStatus = iota
+	// StackPromotionPending represents a promotion waiting for an approval
+	StackPromotionPending
+	// StackPromotionRejected represents a promotion rejected by a reviewer
+	StackPromotionRejected
+	// StackPromotionDeployed represents a promotion approved and deployed on the target environment
+	StackPromotionDeployed
+	// StackPromotionFailed represents a promotion approved but which failed to deploy
+	StackPromotionFailed
+)
+
+const (
+	// SessionRecordingExec represents a session opened by executing a command inside a Docker container
+	SessionRecordingExec SessionRecordingType = "exec"
+	// SessionRecordingAttach represents a session attached to a Docker container
+	SessionRecordingAttach SessionRecordingType = "attach"
+	// SessionRecordingPod represents a session opened by executing a command inside a Kubernetes pod
+	SessionRecordingPod SessionRecordingType = "pod"
+	// SessionRecordingKubernetesShell represents a kubectl shell session
+	SessionRecordingKubernetesShell SessionRecordingType = "kubernetes-shell"
+)
+
+const (
+	// KubeconfigTokenModeBearer represents a kubeconfig file embedding a bearer token
+	KubeconfigTokenModeBearer KubeconfigTokenMode = "token"
+	// KubeconfigTokenModeExec represents a kubeconfig file using an exec-credential plugin to request short-lived tokens
+	KubeconfigTokenModeExec KubeconfigTokenMode = "exec"
+)
+
+const (
+	_ PendingOperationStatus = iota
+	// PendingOperationPending represents an operation waiting for an approval
+	PendingOperationPending
+	// PendingOperationRejected represents an operation rejected by a reviewer
+	PendingOperationRejected
+	// PendingOperationExpired represents an operation which was not reviewed before its expiry date
+	PendingOperationExpired
+	// PendingOperationExecuted represents an operation approved and executed successfully
+	PendingOperationExecuted
+	// PendingOperationFailed represents an operation approved but which failed once executed
+	PendingOperationFailed
+)
+
 const (
 	_ TemplateType = iota
 	// ContainerTemplate represents a container template
@@ -2052,6 +2784,9 @@ const (
 	OperationPortainerWebhookCreate         Authorization = "PortainerWebhookCreate"
 	OperationPortainerWebhookDelete         Authorization = "PortainerWebhookDelete"
 
+	// OperationK8sCustomResourcesW allows editing and deleting the custom resources of a Kubernetes environment
+	OperationK8sCustomResourcesW Authorization = "K8sCustomResourcesW"
+
 	OperationDockerUndefined      Authorization = "DockerUndefined"
 	OperationDockerAgentUndefined Authorization = "DockerAgentUndefined"
 	OperationPortainerUndefined   Authorization = "PortainerUndefined"
@@ -0,0 +1,182 @@
+package resourceusage
+
+import (
+	"sort"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ConsumerTypeStack aggregates the consumers per stack
+	ConsumerTypeStack = "stack"
+	// ConsumerTypeContainer aggregates the consumers per container
+	ConsumerTypeContainer = "container"
+
+	// MetricCPU sorts the consumers by CPU use
+	MetricCPU = "cpu"
+	// MetricMemory sorts the consumers by memory use
+	MetricMemory = "memory"
+	// MetricNetwork sorts the consumers by network rate
+	MetricNetwork = "network"
+)
+
+var (
+	// ErrInvalidConsumerType is returned when the consumer type is neither stack nor container
+	ErrInvalidConsumerType = errors.New("invalid consumer type, expected stack or container")
+	// ErrInvalidMetric is returned when the metric is not cpu, memory or network
+	ErrInvalidMetric = errors.New("invalid metric, expected cpu, memory or network")
+)
+
+type (
+	// TimeSeriesFilter restricts a time series to a stack or a container, the environment total is used when both are empty
+	TimeSeriesFilter struct {
+		Stack     string
+		Container string
+		Since     time.Time
+		Until     time.Time
+	}
+
+	// Point is the resource usage at a given time
+	Point struct {
+		// Unix timestamp of the sample
+		Time int64 `json:"Time" example:"1587399600"`
+		portainer.ResourceUsage
+	}
+
+	// Consumer is the average resource usage of a stack or a container over a time window
+	Consumer struct {
+		// Name of the stack or of the container
+		Name string `json:"Name" example:"web"`
+		// Stack of the container, empty for the stack consumers
+		Stack string `json:"Stack,omitempty" example:"web"`
+		// Number of samples in which the consumer appears
+		Samples int `json:"Samples" example:"60"`
+		portainer.ResourceUsage
+	}
+)
+
+// TimeSeries returns the resource usage of the environment, of one of its stacks or of one of its containers
+// over the time window of the filter
+func (service *Service) TimeSeries(endpointID portainer.EndpointID, filter TimeSeriesFilter) ([]Point, error) {
+	samples, err := service.samples(endpointID, filter.Since, filter.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]Point, 0, len(samples))
+	for _, sample := range samples {
+		usage, ok := sampleUsage(sample, filter)
+		if !ok {
+			continue
+		}
+
+		points = append(points, Point{Time: sample.Time, ResourceUsage: usage})
+	}
+
+	return points, nil
+}
+
+// TopConsumers returns the stacks or the containers of the environment with the highest average use of the metric
+// since the given time. Containers are identified by name so that recreated containers are merged
+func (service *Service) TopConsumers(endpointID portainer.EndpointID, consumerType, metric string, limit int, since time.Time) ([]Consumer, error) {
+	if consumerType != ConsumerTypeStack && consumerType != ConsumerTypeContainer {
+		return nil, ErrInvalidConsumerType
+	}
+
+	value, ok := metricValues[metric]
+	if !ok {
+		return nil, ErrInvalidMetric
+	}
+
+	samples, err := service.samples(endpointID, since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]*Consumer)
+	add := func(name, stack string, usage portainer.ResourceUsage) {
+		consumer, ok := totals[name]
+		if !ok {
+			consumer = &Consumer{Name: name, Stack: stack}
+			totals[name] = consumer
+		}
+
+		consumer.Samples++
+		addUsage(&consumer.ResourceUsage, usage)
+	}
+
+	for _, sample := range samples {
+		if consumerType == ConsumerTypeStack {
+			for _, stack := range sample.Stacks {
+				add(stack.Name, "", stack.ResourceUsage)
+			}
+
+			continue
+		}
+
+		for _, container := range sample.Containers {
+			add(container.Name, container.Stack, container.ResourceUsage)
+		}
+	}
+
+	consumers := make([]Consumer, 0, len(totals))
+	for _, consumer := range totals {
+		count := float64(consumer.Samples)
+		consumer.CPUPercent /= count
+		consumer.MemoryUsage /= uint64(consumer.Samples)
+		consumer.NetworkRxRate /= count
+		consumer.NetworkTxRate /= count
+
+		consumers = append(consumers, *consumer)
+	}
+
+	sort.Slice(consumers, func(i, j int) bool {
+		vi, vj := value(consumers[i].ResourceUsage), value(consumers[j].ResourceUsage)
+		if vi != vj {
+			return vi > vj
+		}
+
+		return consumers[i].Name < consumers[j].Name
+	})
+
+	if limit > 0 && len(consumers) > limit {
+		consumers = consumers[:limit]
+	}
+
+	return consumers, nil
+}
+
+var metricValues = map[string]func(usage portainer.ResourceUsage) float64{
+	MetricCPU:    func(usage portainer.ResourceUsage) float64 { return usage.CPUPercent },
+	MetricMemory: func(usage portainer.ResourceUsage) float64 { return float64(usage.MemoryUsage) },
+	MetricNetwork: func(usage portainer.ResourceUsage) float64 {
+		return usage.NetworkRxRate + usage.NetworkTxRate
+	},
+}
+
+func sampleUsage(sample portainer.ResourceUsageSample, filter TimeSeriesFilter) (portainer.ResourceUsage, bool) {
+	if filter.Container != "" {
+		for _, container := range sample.Containers {
+			if container.Name == filter.Container || container.ID == filter.Container {
+				return container.ResourceUsage, true
+			}
+		}
+
+		return portainer.ResourceUsage{}, false
+	}
+
+	if filter.Stack != "" {
+		for _, stack := range sample.Stacks {
+			if stack.Name == filter.Stack {
+				return stack.ResourceUsage, true
+			}
+		}
+
+		return portainer.ResourceUsage{}, false
+	}
+
+	return sample.Total, true
+}
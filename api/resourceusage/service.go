@@ -0,0 +1,178 @@
+package resourceusage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Collector collects the resource usage counters of the running containers of a Docker environment
+type Collector interface {
+	Collect(endpoint *portainer.Endpoint) ([]portainer.ContainerResourceUsage, error)
+}
+
+// Service records the resource usage samples of the Docker environments and applies their retention window
+type Service struct {
+	dataStore dataservices.DataStore
+	collector Collector
+	previous  map[portainer.EndpointID]*portainer.ResourceUsageSample
+	mu        sync.Mutex
+}
+
+// NewService creates a new resource usage service
+func NewService(dataStore dataservices.DataStore, collector Collector) *Service {
+	return &Service{
+		dataStore: dataStore,
+		collector: collector,
+		previous:  make(map[portainer.EndpointID]*portainer.ResourceUsageSample),
+	}
+}
+
+// Record collects the resource usage of the running containers of the environment and stores it as a new sample.
+// The rates of the first sample recorded after a restart of the server are not computed
+func (service *Service) Record(endpoint *portainer.Endpoint) error {
+	containers, err := service.collector.Collect(endpoint)
+	if err != nil {
+		return errors.Wrap(err, "unable to collect the resource usage of the containers")
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	sample := newSample(endpoint.ID, time.Now(), containers, service.previous[endpoint.ID])
+
+	err = service.dataStore.ResourceUsageSample().Create(sample)
+	if err != nil {
+		return errors.Wrap(err, "unable to persist the resource usage sample")
+	}
+
+	service.previous[endpoint.ID] = sample
+
+	return nil
+}
+
+// Purge removes the samples older than the retention window, the samples of the removed environments are removed with
+// their environment
+func (service *Service) Purge() error {
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve the settings")
+	}
+
+	retentionSetting := settings.ResourceUsageRetention
+	if retentionSetting == "" {
+		retentionSetting = portainer.DefaultResourceUsageRetention
+	}
+
+	retention, err := time.ParseDuration(retentionSetting)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse the resource usage retention")
+	}
+
+	endpoints, err := service.dataStore.Endpoint().Endpoints()
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve the environments")
+	}
+
+	limit := time.Now().Add(-retention).Unix()
+	for _, endpoint := range endpoints {
+		if err := service.dataStore.ResourceUsageSample().DeleteBefore(endpoint.ID, limit); err != nil {
+			log.Warn().Err(err).Int("endpoint_id", int(endpoint.ID)).Msg("unable to remove the expired resource usage samples")
+		}
+	}
+
+	return nil
+}
+
+// samples returns the samples of the environment collected between since and until, sorted by time
+func (service *Service) samples(endpointID portainer.EndpointID, since, until time.Time) ([]portainer.ResourceUsageSample, error) {
+	samples, err := service.dataStore.ResourceUsageSample().ReadRange(endpointID, since.Unix(), until.Unix())
+
+	return samples, errors.Wrap(err, "unable to retrieve the resource usage samples")
+}
+
+// newSample computes the rates of the containers against the previous sample, then aggregates the usage of the
+// containers per stack and for the whole environment
+func newSample(endpointID portainer.EndpointID, at time.Time, containers []portainer.ContainerResourceUsage, previous *portainer.ResourceUsageSample) *portainer.ResourceUsageSample {
+	sample := &portainer.ResourceUsageSample{
+		EndpointID: endpointID,
+		Time:       at.Unix(),
+		Stacks:     []portainer.StackResourceUsage{},
+		Containers: containers,
+	}
+
+	previousContainers := make(map[string]portainer.ContainerResourceUsage)
+	var elapsed float64
+	if previous != nil {
+		elapsed = at.Sub(time.Unix(previous.Time, 0)).Seconds()
+		for _, container := range previous.Containers {
+			previousContainers[container.ID] = container
+		}
+	}
+
+	stacks := make(map[string]*portainer.StackResourceUsage)
+	for i := range sample.Containers {
+		container := &sample.Containers[i]
+
+		if previousContainer, ok := previousContainers[container.ID]; ok && elapsed > 0 {
+			computeRates(container, previousContainer, elapsed)
+		}
+
+		addUsage(&sample.Total, container.ResourceUsage)
+
+		if container.Stack == "" {
+			continue
+		}
+
+		stack, ok := stacks[container.Stack]
+		if !ok {
+			stack = &portainer.StackResourceUsage{Name: container.Stack}
+			stacks[container.Stack] = stack
+		}
+
+		stack.ContainerCount++
+		addUsage(&stack.ResourceUsage, container.ResourceUsage)
+	}
+
+	for _, stack := range stacks {
+		sample.Stacks = append(sample.Stacks, *stack)
+	}
+
+	sort.Slice(sample.Stacks, func(i, j int) bool {
+		return sample.Stacks[i].Name < sample.Stacks[j].Name
+	})
+
+	return sample
+}
+
+// computeRates sets the CPU use and the network rates of the container from the counters of its previous sample,
+// the counters are reset when the container restarts
+func computeRates(container *portainer.ContainerResourceUsage, previous portainer.ContainerResourceUsage, elapsed float64) {
+	if container.CPUTotalUsage >= previous.CPUTotalUsage && container.SystemCPUUsage > previous.SystemCPUUsage {
+		cpuDelta := float64(container.CPUTotalUsage - previous.CPUTotalUsage)
+		systemDelta := float64(container.SystemCPUUsage - previous.SystemCPUUsage)
+
+		container.CPUPercent = cpuDelta / systemDelta * float64(container.OnlineCPUs) * 100
+	}
+
+	if container.NetworkRxBytes >= previous.NetworkRxBytes {
+		container.NetworkRxRate = float64(container.NetworkRxBytes-previous.NetworkRxBytes) / elapsed
+	}
+
+	if container.NetworkTxBytes >= previous.NetworkTxBytes {
+		container.NetworkTxRate = float64(container.NetworkTxBytes-previous.NetworkTxBytes) / elapsed
+	}
+}
+
+func addUsage(usage *portainer.ResourceUsage, other portainer.ResourceUsage) {
+	usage.CPUPercent += other.CPUPercent
+	usage.MemoryUsage += other.MemoryUsage
+	usage.NetworkRxRate += other.NetworkRxRate
+	usage.NetworkTxRate += other.NetworkTxRate
+}
@@ -0,0 +1,169 @@
+package resourceusage
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCollector struct {
+	containers []portainer.ContainerResourceUsage
+}
+
+func (collector *testCollector) Collect(endpoint *portainer.Endpoint) ([]portainer.ContainerResourceUsage, error) {
+	return collector.containers, nil
+}
+
+func Test_newSample(t *testing.T) {
+	is := assert.New(t)
+
+	now := time.Unix(1700000000, 0)
+
+	previous := newSample(1, now.Add(-10*time.Second), []portainer.ContainerResourceUsage{
+		{ID: "a", Name: "web", Stack: "app", CPUTotalUsage: 100, SystemCPUUsage: 1000, OnlineCPUs: 2, NetworkRxBytes: 1000, NetworkTxBytes: 500},
+		{ID: "b", Name: "db", Stack: "app", CPUTotalUsage: 500, SystemCPUUsage: 1000, OnlineCPUs: 2, NetworkRxBytes: 5000},
+	}, nil)
+	is.Equal(portainer.ResourceUsage{}, previous.Total, "the rates of the first sample are not computed")
+
+	sample := newSample(1, now, []portainer.ContainerResourceUsage{
+		{ResourceUsage: portainer.ResourceUsage{MemoryUsage: 100}, ID: "a", Name: "web", Stack: "app", CPUTotalUsage: 200, SystemCPUUsage: 2000, OnlineCPUs: 2, NetworkRxBytes: 2000, NetworkTxBytes: 1500},
+		// the container restarted, its counters were reset
+		{ResourceUsage: portainer.ResourceUsage{MemoryUsage: 200}, ID: "b", Name: "db", Stack: "app", CPUTotalUsage: 10, SystemCPUUsage: 2000, OnlineCPUs: 2, NetworkRxBytes: 10},
+		{ResourceUsage: portainer.ResourceUsage{MemoryUsage: 50}, ID: "c", Name: "standalone", CPUTotalUsage: 10, SystemCPUUsage: 2000, OnlineCPUs: 2},
+	}, previous)
+
+	is.Equal(now.Unix(), sample.Time)
+	is.InDelta(20.0, sample.Containers[0].CPUPercent, 0.001)
+	is.InDelta(100.0, sample.Containers[0].NetworkRxRate, 0.001)
+	is.InDelta(100.0, sample.Containers[0].NetworkTxRate, 0.001)
+	is.Zero(sample.Containers[1].CPUPercent)
+	is.Zero(sample.Containers[1].NetworkRxRate)
+
+	is.Equal([]portainer.StackResourceUsage{{
+		ResourceUsage:  portainer.ResourceUsage{CPUPercent: sample.Containers[0].CPUPercent, MemoryUsage: 300, NetworkRxRate: 100, NetworkTxRate: 100},
+		Name:           "app",
+		ContainerCount: 2,
+	}}, sample.Stacks)
+	is.Equal(uint64(350), sample.Total.MemoryUsage)
+}
+
+func Test_RecordAndQuery(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	collector := &testCollector{containers: []portainer.ContainerResourceUsage{
+		{ResourceUsage: portainer.ResourceUsage{MemoryUsage: 100}, ID: "a", Name: "web", Stack: "app"},
+		{ResourceUsage: portainer.ResourceUsage{MemoryUsage: 300}, ID: "b", Name: "db", Stack: "data"},
+		{ResourceUsage: portainer.ResourceUsage{MemoryUsage: 200}, ID: "c", Name: "cache", Stack: "data"},
+	}}
+	service := NewService(store, collector)
+
+	endpoint := &portainer.Endpoint{ID: 1}
+	is.NoError(service.Record(endpoint))
+
+	points, err := service.TimeSeries(1, TimeSeriesFilter{Since: time.Now().Add(-time.Minute), Until: time.Now().Add(time.Minute)})
+	is.NoError(err)
+	is.Len(points, 1)
+	is.Equal(uint64(600), points[0].MemoryUsage)
+
+	points, err = service.TimeSeries(1, TimeSeriesFilter{Stack: "data", Since: time.Now().Add(-time.Minute), Until: time.Now().Add(time.Minute)})
+	is.NoError(err)
+	is.Len(points, 1)
+	is.Equal(uint64(500), points[0].MemoryUsage)
+
+	points, err = service.TimeSeries(2, TimeSeriesFilter{Since: time.Now().Add(-time.Minute), Until: time.Now().Add(time.Minute)})
+	is.NoError(err)
+	is.Empty(points)
+
+	consumers, err := service.TopConsumers(1, ConsumerTypeContainer, MetricMemory, 2, time.Now().Add(-time.Minute))
+	is.NoError(err)
+	is.Len(consumers, 2)
+	is.Equal("db", consumers[0].Name)
+	is.Equal("data", consumers[0].Stack)
+	is.Equal("cache", consumers[1].Name)
+
+	consumers, err = service.TopConsumers(1, ConsumerTypeStack, MetricMemory, 0, time.Now().Add(-time.Minute))
+	is.NoError(err)
+	is.Len(consumers, 2)
+	is.Equal("data", consumers[0].Name)
+
+	_, err = service.TopConsumers(1, "volume", MetricMemory, 0, time.Now())
+	is.ErrorIs(err, ErrInvalidConsumerType)
+
+	_, err = service.TopConsumers(1, ConsumerTypeStack, "disk", 0, time.Now())
+	is.ErrorIs(err, ErrInvalidMetric)
+}
+
+func Test_Purge(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+	service := NewService(store, &testCollector{})
+
+	is.NoError(store.Endpoint().Create(&portainer.Endpoint{ID: 1, Name: "local"}))
+
+	is.NoError(store.ResourceUsageSample().Create(&portainer.ResourceUsageSample{EndpointID: 1, Time: time.Now().Add(-48 * time.Hour).Unix()}))
+	is.NoError(store.ResourceUsageSample().Create(&portainer.ResourceUsageSample{EndpointID: 1, Time: time.Now().Add(-2 * time.Hour).Unix()}))
+	is.NoError(store.ResourceUsageSample().Create(&portainer.ResourceUsageSample{EndpointID: 2, Time: time.Now().Add(-48 * time.Hour).Unix()}))
+
+	is.NoError(service.Purge())
+
+	samples, err := store.ResourceUsageSample().ReadRange(1, 0, time.Now().Unix())
+	is.NoError(err)
+	is.Len(samples, 1, "the default retention keeps the samples of the last 24 hours")
+
+	samples, err = store.ResourceUsageSample().ReadRange(2, 0, time.Now().Unix())
+	is.NoError(err)
+	is.Len(samples, 1, "the samples of the other environments are not removed")
+
+	settings, err := store.Settings().Settings()
+	is.NoError(err)
+	settings.ResourceUsageRetention = "1h"
+	is.NoError(store.Settings().UpdateSettings(settings))
+
+	is.NoError(service.Purge())
+
+	samples, err = store.ResourceUsageSample().ReadRange(1, 0, time.Now().Unix())
+	is.NoError(err)
+	is.Empty(samples)
+}
+
+func Test_samples(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+	service := NewService(store, &testCollector{})
+
+	now := time.Unix(1700000000, 0)
+	for _, sample := range []portainer.ResourceUsageSample{
+		{EndpointID: 1, Time: now.Unix()},
+		{EndpointID: 1, Time: now.Add(-time.Hour).Unix()},
+		{EndpointID: 1, Time: now.Add(-2 * time.Hour).Unix()},
+		{EndpointID: 2, Time: now.Add(-time.Hour).Unix()},
+		{EndpointID: 256, Time: now.Add(-time.Hour).Unix()},
+	} {
+		is.NoError(store.ResourceUsageSample().Create(&sample))
+	}
+
+	samples, err := service.samples(1, now.Add(-time.Hour), now)
+	is.NoError(err)
+	if is.Len(samples, 2, "only the samples of the environment in the window are read") {
+		is.Equal(now.Add(-time.Hour).Unix(), samples[0].Time, "the samples are sorted by time")
+		is.Equal(now.Unix(), samples[1].Time)
+	}
+
+	is.NoError(store.ResourceUsageSample().DeleteByEndpointID(1))
+
+	samples, err = service.samples(1, time.Unix(0, 0), now)
+	is.NoError(err)
+	is.Empty(samples)
+
+	samples, err = service.samples(256, time.Unix(0, 0), now)
+	is.NoError(err)
+	is.Len(samples, 1)
+}
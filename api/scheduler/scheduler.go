@@ -127,3 +127,28 @@ func (s *Scheduler) StartJobEvery(duration time.Duration, job func() error) stri
 
 	return strconv.Itoa(int(entryID))
 }
+
+// RescheduleJob stops the job identified by jobID, when it is set, and schedules job every interval, a duration such as "1h".
+// The job is stopped for good once isPermanent returns true for one of its errors, such as the errors returned when the
+// object of the job was removed. Returns the identifier of the new job, which is empty when the interval is invalid
+func (s *Scheduler) RescheduleJob(jobID, interval string, job func() error, isPermanent func(err error) bool) (string, error) {
+	if jobID != "" {
+		if err := s.StopJob(jobID); err != nil {
+			log.Warn().Err(err).Str("job_id", jobID).Msg("could not stop the job before rescheduling it")
+		}
+	}
+
+	duration, err := time.ParseDuration(interval)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid job interval %q", interval)
+	}
+
+	return s.StartJobEvery(duration, func() error {
+		err := job()
+		if err != nil && isPermanent(err) {
+			return NewPermanentError(err)
+		}
+
+		return err
+	}), nil
+}
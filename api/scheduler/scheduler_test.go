@@ -140,3 +140,40 @@ func Test_StartJobEvery_Concurrently(t *testing.T) {
 
 	<-ctx.Done()
 }
+
+func Test_RescheduleJob(t *testing.T) {
+	s := NewScheduler(context.Background())
+	defer s.Shutdown()
+
+	errRemoved := errors.New("removed")
+
+	var oldRuns, newRuns int
+	ch := make(chan struct{})
+
+	jobID := s.StartJobEvery(jobInterval, func() error {
+		oldRuns++
+		return nil
+	})
+
+	newJobID, err := s.RescheduleJob(jobID, "1s", func() error {
+		newRuns++
+		if newRuns == 1 {
+			close(ch)
+		}
+
+		return errRemoved
+	}, func(err error) bool {
+		return errors.Is(err, errRemoved)
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, jobID, newJobID)
+
+	<-time.After(3 * jobInterval)
+	<-ch
+	assert.Equal(t, 0, oldRuns, "the previous job is stopped")
+	assert.Equal(t, 1, newRuns, "the job stops after a permanent error")
+
+	newJobID, err = s.RescheduleJob(newJobID, "daily", func() error { return nil }, func(err error) bool { return false })
+	assert.Error(t, err)
+	assert.Empty(t, newJobID)
+}
@@ -0,0 +1,141 @@
+package sessionrecording
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+type header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder writes the input and output of a terminal session as asciicast v2 events
+type Recorder struct {
+	service   *Service
+	recording *portainer.SessionRecording
+	file      *os.File
+	writer    *bufio.Writer
+	start     time.Time
+	size      int64
+	err       error
+	mu        sync.Mutex
+}
+
+func newRecorder(service *Service, recording *portainer.SessionRecording, file *os.File, title string) (*Recorder, error) {
+	recorder := &Recorder{
+		service:   service,
+		recording: recording,
+		file:      file,
+		writer:    bufio.NewWriter(file),
+		start:     time.Unix(recording.StartDate, 0),
+	}
+
+	line, err := json.Marshal(header{
+		Version:   2,
+		Width:     defaultWidth,
+		Height:    defaultHeight,
+		Timestamp: recording.StartDate,
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm"},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to encode the recording header")
+	}
+
+	recorder.write(line)
+
+	return recorder, recorder.err
+}
+
+// Input returns a writer recording the data it receives as input events
+func (recorder *Recorder) Input() io.Writer {
+	return eventWriter{recorder: recorder, code: "i"}
+}
+
+// Output returns a writer recording the data it receives as output events
+func (recorder *Recorder) Output() io.Writer {
+	return eventWriter{recorder: recorder, code: "o"}
+}
+
+// Close ends the recording and persists its duration and size
+func (recorder *Recorder) Close() error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	err := recorder.writer.Flush()
+	if closeErr := recorder.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	end := time.Now()
+	recorder.recording.EndDate = end.Unix()
+	recorder.recording.Duration = end.Sub(recorder.start).Seconds()
+	recorder.recording.Size = recorder.size
+
+	if updateErr := recorder.service.dataStore.SessionRecording().Update(recorder.recording.ID, recorder.recording); updateErr != nil {
+		return errors.Wrap(updateErr, "unable to persist the session recording")
+	}
+
+	return errors.Wrap(err, "unable to write the session recording")
+}
+
+func (recorder *Recorder) event(code string, data []byte) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if recorder.err != nil {
+		return
+	}
+
+	// invalid UTF-8 sequences are replaced by the JSON encoder
+	line, err := json.Marshal([]interface{}{time.Since(recorder.start).Seconds(), code, string(data)})
+	if err != nil {
+		return
+	}
+
+	recorder.write(line)
+}
+
+func (recorder *Recorder) write(line []byte) {
+	n, err := recorder.writer.Write(append(line, '\n'))
+	recorder.size += int64(n)
+
+	if err != nil {
+		recorder.err = err
+		log.Warn().Err(err).Int("recording_id", int(recorder.recording.ID)).Msg("unable to write the session recording, recording stopped")
+	}
+}
+
+// eventWriter records the data written to it without ever failing, so that a recording error never
+// interrupts the recorded session
+type eventWriter struct {
+	recorder *Recorder
+	code     string
+}
+
+func (w eventWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		w.recorder.event(w.code, p)
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,131 @@
+package sessionrecording
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Service records terminal sessions and applies the retention policy of the recordings
+type Service struct {
+	dataStore   dataservices.DataStore
+	fileService portainer.FileService
+}
+
+// NewService creates a new session recording service
+func NewService(dataStore dataservices.DataStore, fileService portainer.FileService) *Service {
+	return &Service{
+		dataStore:   dataStore,
+		fileService: fileService,
+	}
+}
+
+// IsEnabled returns true when the sessions opened on the environment must be recorded
+func (service *Service) IsEnabled(endpoint *portainer.Endpoint) (bool, error) {
+	endpointGroup, err := service.dataStore.EndpointGroup().Read(endpoint.GroupID)
+	if service.dataStore.IsErrObjectNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "unable to retrieve the environment group")
+	}
+
+	return endpointGroup.RecordSessions, nil
+}
+
+// Start creates the session recording and returns the recorder of the session,
+// the recorder must be closed once the session ended
+func (service *Service) Start(recording *portainer.SessionRecording, title string) (*Recorder, error) {
+	recording.StartDate = time.Now().Unix()
+
+	err := service.dataStore.SessionRecording().Create(recording)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to persist the session recording")
+	}
+
+	path := service.FilePath(recording.ID)
+
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		service.dataStore.SessionRecording().Delete(recording.ID)
+		return nil, errors.Wrap(err, "unable to create the session recording directory")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		service.dataStore.SessionRecording().Delete(recording.ID)
+		return nil, errors.Wrap(err, "unable to create the session recording file")
+	}
+
+	recorder, err := newRecorder(service, recording, file, title)
+	if err != nil {
+		file.Close()
+		service.Delete(recording.ID)
+		return nil, err
+	}
+
+	return recorder, nil
+}
+
+// FilePath returns the path of the asciicast file of a session recording
+func (service *Service) FilePath(recordingID portainer.SessionRecordingID) string {
+	return service.fileService.GetSessionRecordingPath(strconv.Itoa(int(recordingID)))
+}
+
+// Delete removes a session recording and its file
+func (service *Service) Delete(recordingID portainer.SessionRecordingID) error {
+	err := os.Remove(service.FilePath(recordingID))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "unable to remove the session recording file")
+	}
+
+	return errors.Wrap(service.dataStore.SessionRecording().Delete(recordingID), "unable to remove the session recording")
+}
+
+// Purge removes the recordings of the sessions which ended before the retention duration of the settings,
+// it does nothing when no retention is set
+func (service *Service) Purge() error {
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve the settings")
+	}
+
+	if settings.SessionRecordingRetention == "" {
+		return nil
+	}
+
+	retention, err := time.ParseDuration(settings.SessionRecordingRetention)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse the session recording retention")
+	}
+
+	recordings, err := service.dataStore.SessionRecording().ReadAll()
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve the session recordings")
+	}
+
+	limit := time.Now().Add(-retention).Unix()
+	for _, recording := range recordings {
+		// recordings interrupted by a restart of the server are never ended
+		date := recording.EndDate
+		if date == 0 {
+			date = recording.StartDate
+		}
+
+		if date > limit {
+			continue
+		}
+
+		if err := service.Delete(recording.ID); err != nil {
+			log.Warn().Err(err).Int("recording_id", int(recording.ID)).Msg("unable to remove an expired session recording")
+		}
+	}
+
+	return nil
+}
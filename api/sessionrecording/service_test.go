@@ -0,0 +1,107 @@
+package sessionrecording
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/filesystem"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestService(t *testing.T) (*Service, *datastore.Store) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	fileService, err := filesystem.NewService(t.TempDir(), "")
+	assert.NoError(t, err)
+
+	return NewService(store, fileService), store
+}
+
+func Test_Recorder(t *testing.T) {
+	is := assert.New(t)
+
+	service, store := newTestService(t)
+
+	recorder, err := service.Start(&portainer.SessionRecording{
+		Type:       portainer.SessionRecordingExec,
+		UserID:     1,
+		EndpointID: 2,
+		ExecID:     "abcdef",
+	}, "exec session")
+	is.NoError(err)
+
+	recorder.Input().Write([]byte("ls\r"))
+	recorder.Output().Write([]byte("file.txt\r\n"))
+	is.NoError(recorder.Close())
+
+	recording, err := store.SessionRecording().Read(recorder.recording.ID)
+	is.NoError(err)
+	is.NotZero(recording.EndDate)
+	is.NotZero(recording.Size)
+
+	file, err := os.Open(service.FilePath(recording.ID))
+	is.NoError(err)
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lines := []string{}
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if !is.Len(lines, 3) {
+		return
+	}
+
+	var h header
+	is.NoError(json.Unmarshal([]byte(lines[0]), &h))
+	is.Equal(2, h.Version)
+	is.Equal(recording.StartDate, h.Timestamp)
+	is.Equal("exec session", h.Title)
+
+	var event []interface{}
+	is.NoError(json.Unmarshal([]byte(lines[1]), &event))
+	is.Equal([]interface{}{"i", "ls\r"}, event[1:])
+
+	is.NoError(json.Unmarshal([]byte(lines[2]), &event))
+	is.Equal([]interface{}{"o", "file.txt\r\n"}, event[1:])
+}
+
+func Test_Purge(t *testing.T) {
+	is := assert.New(t)
+
+	service, store := newTestService(t)
+
+	now := time.Now()
+	recordings := []portainer.SessionRecording{
+		{StartDate: now.Add(-50 * time.Hour).Unix(), EndDate: now.Add(-49 * time.Hour).Unix()},
+		{StartDate: now.Add(-50 * time.Hour).Unix()},
+		{StartDate: now.Add(-2 * time.Hour).Unix(), EndDate: now.Add(-time.Hour).Unix()},
+	}
+	for i := range recordings {
+		is.NoError(store.SessionRecording().Create(&recordings[i]))
+	}
+
+	// recordings are kept forever without retention
+	is.NoError(service.Purge())
+	remaining, err := store.SessionRecording().ReadAll()
+	is.NoError(err)
+	is.Len(remaining, 3)
+
+	settings, err := store.Settings().Settings()
+	is.NoError(err)
+	settings.SessionRecordingRetention = "24h"
+	is.NoError(store.Settings().UpdateSettings(settings))
+
+	is.NoError(service.Purge())
+	remaining, err = store.SessionRecording().ReadAll()
+	is.NoError(err)
+	if is.Len(remaining, 1) {
+		is.Equal(recordings[2].ID, remaining[0].ID)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/git"
 	"github.com/portainer/portainer/api/git/update"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/scheduler"
@@ -72,8 +73,17 @@ func RedeployWhenChanged(stackID portainer.StackID, deployer StackDeployer, data
 
 	var gitCommitChangedOrForceUpdate bool
 	if !stack.FromAppTemplate {
-		updated, newHash, err := update.UpdateGitObject(gitService, fmt.Sprintf("stack:%d", stackID), stack.GitConfig, false, false, stack.ProjectPath)
-		if err != nil {
+		updated, newHash, err := update.UpdateGitObject(gitService, datastore.GitSigningKey(), fmt.Sprintf("stack:%d", stackID), stack.GitConfig, false, false, stack.ProjectPath)
+		if errors.Is(err, git.ErrCommitSignatureVerification) {
+			log.Warn().
+				Err(err).
+				Int("stack_id", int(stackID)).
+				Str("stack", stack.Name).
+				Str("deployed_hash", stack.GitConfig.ConfigHash).
+				Msg("redeployment refused, the commit does not satisfy the signature policy of the stack")
+
+			return err
+		} else if err != nil {
 			return err
 		}
 
@@ -7,6 +7,7 @@ import (
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/git"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/internal/testhelpers"
 
@@ -28,6 +29,22 @@ func (s *noopDeployer) DeployKubernetesStack(stack *portainer.Stack, endpoint *p
 	return nil
 }
 
+func (s *noopDeployer) CheckPolicies(stack *portainer.Stack, endpoint *portainer.Endpoint) error {
+	return nil
+}
+
+func (s *noopDeployer) SetSecretEnv(stack *portainer.Stack, env []portainer.Pair) error {
+	return nil
+}
+
+func (s *noopDeployer) SecretEnv(stack *portainer.Stack) ([]portainer.Pair, error) {
+	return nil, nil
+}
+
+func (s *noopDeployer) WithSecretEnv(stack *portainer.Stack, call func() error) error {
+	return call()
+}
+
 // with unpacker
 func (s *noopDeployer) DeployRemoteComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, forcePullImage bool, forceRecreate bool) error {
 	return nil
@@ -133,6 +150,40 @@ func Test_redeployWhenChanged_FailsWhenCannotClone(t *testing.T) {
 	assert.ErrorIs(t, err, cloneErr, "should failed to clone but didn't, check test setup")
 }
 
+func Test_redeployWhenChanged_RefusedWhenCommitIsNotSigned(t *testing.T) {
+	cloneErr := errors.New("should not clone")
+	_, store := datastore.MustNewTestStore(t, true, true)
+
+	err := store.User().Create(&portainer.User{ID: 1, Username: "admin"})
+	assert.NoError(t, err, "error creating an admin")
+
+	err = store.Endpoint().Create(&portainer.Endpoint{ID: 1})
+	assert.NoError(t, err, "error creating environment")
+
+	err = store.GitSigningKey().Create(&portainer.GitSigningKey{Name: "release", Type: portainer.GitSigningKeyTypeSSH})
+	assert.NoError(t, err, "error creating a signing key")
+
+	err = store.Stack().Create(&portainer.Stack{
+		ID:         1,
+		EndpointID: 1,
+		CreatedBy:  "admin",
+		Type:       portainer.DockerComposeStack,
+		GitConfig: &gittypes.RepoConfig{
+			URL:             "url",
+			ReferenceName:   "ref",
+			ConfigHash:      "oldHash",
+			SignaturePolicy: &gittypes.SignaturePolicy{},
+		}})
+	assert.NoError(t, err, "failed to create a test stack")
+
+	err = RedeployWhenChanged(1, &noopDeployer{}, store, testhelpers.NewGitService(cloneErr, "newHash"))
+	assert.ErrorIs(t, err, git.ErrCommitNotSigned)
+
+	stack, err := store.Stack().Read(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "oldHash", stack.GitConfig.ConfigHash, "the stack should keep the deployed commit")
+}
+
 func Test_redeployWhenChanged(t *testing.T) {
 	_, store := datastore.MustNewTestStore(t, true, true)
 
@@ -16,6 +16,10 @@ type BaseStackDeployer interface {
 	DeploySwarmStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, prune bool, pullImage bool) error
 	DeployComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, forcePullImage bool, forceRecreate bool) error
 	DeployKubernetesStack(stack *portainer.Stack, endpoint *portainer.Endpoint, user *portainer.User) error
+	CheckPolicies(stack *portainer.Stack, endpoint *portainer.Endpoint) error
+	SetSecretEnv(stack *portainer.Stack, env []portainer.Pair) error
+	SecretEnv(stack *portainer.Stack) ([]portainer.Pair, error)
+	WithSecretEnv(stack *portainer.Stack, call func() error) error
 }
 
 type StackDeployer interface {
@@ -30,11 +34,15 @@ type stackDeployer struct {
 	kubernetesDeployer  portainer.KubernetesDeployer
 	ClientFactory       *dockerclient.ClientFactory
 	dataStore           dataservices.DataStore
+	secretService       portainer.SecretService
+	policies            []StackPolicy
 }
 
-// NewStackDeployer inits a stackDeployer struct with a SwarmStackManager, a ComposeStackManager and a KubernetesDeployer
+// NewStackDeployer inits a stackDeployer struct with a SwarmStackManager, a ComposeStackManager and a KubernetesDeployer.
+// The stacks are validated against the policies before they are deployed, their secret variables are decrypted with the secret service
 func NewStackDeployer(swarmStackManager portainer.SwarmStackManager, composeStackManager portainer.ComposeStackManager,
-	kubernetesDeployer portainer.KubernetesDeployer, clientFactory *dockerclient.ClientFactory, dataStore dataservices.DataStore) *stackDeployer {
+	kubernetesDeployer portainer.KubernetesDeployer, clientFactory *dockerclient.ClientFactory, dataStore dataservices.DataStore,
+	secretService portainer.SecretService, policies ...StackPolicy) *stackDeployer {
 	return &stackDeployer{
 		lock:                &sync.Mutex{},
 		swarmStackManager:   swarmStackManager,
@@ -42,9 +50,21 @@ func NewStackDeployer(swarmStackManager portainer.SwarmStackManager, composeStac
 		kubernetesDeployer:  kubernetesDeployer,
 		ClientFactory:       clientFactory,
 		dataStore:           dataStore,
+		secretService:       secretService,
+		policies:            policies,
 	}
 }
 func (d *stackDeployer) DeploySwarmStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, prune bool, pullImage bool) error {
+	return d.WithSecretEnv(stack, func() error {
+		return d.deploySwarmStack(stack, endpoint, registries, prune, pullImage)
+	})
+}
+
+func (d *stackDeployer) deploySwarmStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, prune bool, pullImage bool) error {
+	if err := d.CheckPolicies(stack, endpoint); err != nil {
+		return err
+	}
+
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
@@ -55,6 +75,16 @@ func (d *stackDeployer) DeploySwarmStack(stack *portainer.Stack, endpoint *porta
 }
 
 func (d *stackDeployer) DeployComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, forcePullImage bool, forceRecreate bool) error {
+	return d.WithSecretEnv(stack, func() error {
+		return d.deployComposeStack(stack, endpoint, registries, forcePullImage, forceRecreate)
+	})
+}
+
+func (d *stackDeployer) deployComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, forcePullImage bool, forceRecreate bool) error {
+	if err := d.CheckPolicies(stack, endpoint); err != nil {
+		return err
+	}
+
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
@@ -76,6 +106,7 @@ func (d *stackDeployer) DeployComposeStack(stack *portainer.Stack, endpoint *por
 	return err
 }
 
+// DeployKubernetesStack deploys the stack, the policies are checked and the secret variables supplied by the deployment config
 func (d *stackDeployer) DeployKubernetesStack(stack *portainer.Stack, endpoint *portainer.Endpoint, user *portainer.User) error {
 	d.lock.Lock()
 	defer d.lock.Unlock()
@@ -92,7 +123,7 @@ func (d *stackDeployer) DeployKubernetesStack(stack *portainer.Stack, endpoint *
 		appLabels.Kind = "git"
 	}
 
-	k8sDeploymentConfig, err := CreateKubernetesStackDeploymentConfig(stack, d.kubernetesDeployer, appLabels, user, endpoint)
+	k8sDeploymentConfig, err := CreateKubernetesStackDeploymentConfig(stack, d.kubernetesDeployer, d, appLabels, user, endpoint)
 	if err != nil {
 		return errors.Wrap(err, "failed to create temp kub deployment files")
 	}
@@ -50,6 +50,22 @@ func (d *stackDeployer) DeployRemoteComposeStack(
 	forcePullImage bool,
 	forceRecreate bool,
 ) error {
+	return d.WithSecretEnv(stack, func() error {
+		return d.deployRemoteComposeStack(stack, endpoint, registries, forcePullImage, forceRecreate)
+	})
+}
+
+func (d *stackDeployer) deployRemoteComposeStack(
+	stack *portainer.Stack,
+	endpoint *portainer.Endpoint,
+	registries []portainer.Registry,
+	forcePullImage bool,
+	forceRecreate bool,
+) error {
+	if err := d.CheckPolicies(stack, endpoint); err != nil {
+		return err
+	}
+
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
@@ -88,6 +104,20 @@ func (d *stackDeployer) StartRemoteComposeStack(
 	endpoint *portainer.Endpoint,
 	registries []portainer.Registry,
 ) error {
+	return d.WithSecretEnv(stack, func() error {
+		return d.startRemoteComposeStack(stack, endpoint, registries)
+	})
+}
+
+func (d *stackDeployer) startRemoteComposeStack(
+	stack *portainer.Stack,
+	endpoint *portainer.Endpoint,
+	registries []portainer.Registry,
+) error {
+	if err := d.CheckPolicies(stack, endpoint); err != nil {
+		return err
+	}
+
 	return d.remoteStack(
 		stack,
 		endpoint,
@@ -111,6 +141,22 @@ func (d *stackDeployer) DeployRemoteSwarmStack(
 	prune bool,
 	pullImage bool,
 ) error {
+	return d.WithSecretEnv(stack, func() error {
+		return d.deployRemoteSwarmStack(stack, endpoint, registries, prune, pullImage)
+	})
+}
+
+func (d *stackDeployer) deployRemoteSwarmStack(
+	stack *portainer.Stack,
+	endpoint *portainer.Endpoint,
+	registries []portainer.Registry,
+	prune bool,
+	pullImage bool,
+) error {
+	if err := d.CheckPolicies(stack, endpoint); err != nil {
+		return err
+	}
+
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
@@ -139,6 +185,20 @@ func (d *stackDeployer) StartRemoteSwarmStack(
 	endpoint *portainer.Endpoint,
 	registries []portainer.Registry,
 ) error {
+	return d.WithSecretEnv(stack, func() error {
+		return d.startRemoteSwarmStack(stack, endpoint, registries)
+	})
+}
+
+func (d *stackDeployer) startRemoteSwarmStack(
+	stack *portainer.Stack,
+	endpoint *portainer.Endpoint,
+	registries []portainer.Registry,
+) error {
+	if err := d.CheckPolicies(stack, endpoint); err != nil {
+		return err
+	}
+
 	return d.remoteStack(
 		stack,
 		endpoint,
@@ -6,25 +6,29 @@ import (
 
 	"github.com/pkg/errors"
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/customtemplates/variables"
 	"github.com/portainer/portainer/api/filesystem"
 	k "github.com/portainer/portainer/api/kubernetes"
+	"github.com/portainer/portainer/api/kubernetes/kustomize"
 	"github.com/portainer/portainer/api/stacks/stackutils"
 )
 
 type KubernetesStackDeploymentConfig struct {
 	stack              *portainer.Stack
 	kubernetesDeployer portainer.KubernetesDeployer
+	stackDeployer      StackDeployer
 	appLabels          k.KubeAppLabels
 	user               *portainer.User
 	endpoint           *portainer.Endpoint
 	output             string
 }
 
-func CreateKubernetesStackDeploymentConfig(stack *portainer.Stack, kubeDeployer portainer.KubernetesDeployer, appLabels k.KubeAppLabels, user *portainer.User, endpoint *portainer.Endpoint) (*KubernetesStackDeploymentConfig, error) {
+func CreateKubernetesStackDeploymentConfig(stack *portainer.Stack, kubeDeployer portainer.KubernetesDeployer, stackDeployer StackDeployer, appLabels k.KubeAppLabels, user *portainer.User, endpoint *portainer.Endpoint) (*KubernetesStackDeploymentConfig, error) {
 
 	return &KubernetesStackDeploymentConfig{
 		stack:              stack,
 		kubernetesDeployer: kubeDeployer,
+		stackDeployer:      stackDeployer,
 		appLabels:          appLabels,
 		user:               user,
 		endpoint:           endpoint,
@@ -35,10 +39,20 @@ func (config *KubernetesStackDeploymentConfig) GetUsername() string {
 	return config.user.Username
 }
 
+// Deploy validates the stack against the policies of the stack deployer and applies its manifests,
+// the secret variables of the stack are supplied by the stack deployer
 func (config *KubernetesStackDeploymentConfig) Deploy() error {
-	fileNames := stackutils.GetStackFilePaths(config.stack, false)
+	if config.stackDeployer == nil {
+		return errors.New("stack deployer cannot be nil")
+	}
 
-	manifestFilePaths := make([]string, 0, len(fileNames))
+	return config.stackDeployer.WithSecretEnv(config.stack, config.deploy)
+}
+
+func (config *KubernetesStackDeploymentConfig) deploy() error {
+	if err := config.stackDeployer.CheckPolicies(config.stack, config.endpoint); err != nil {
+		return err
+	}
 
 	tmpDir, err := os.MkdirTemp("", "kub_deployment")
 	if err != nil {
@@ -47,40 +61,80 @@ func (config *KubernetesStackDeploymentConfig) Deploy() error {
 
 	defer os.RemoveAll(tmpDir)
 
+	manifestFilePaths, err := RenderKubernetesManifests(config.stack, config.kubernetesDeployer, config.appLabels, tmpDir)
+	if err != nil {
+		return err
+	}
+
+	output, err := config.kubernetesDeployer.Deploy(config.user.ID, config.endpoint, manifestFilePaths, config.stack.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to deploy kubernete stack: %w", err)
+	}
+
+	config.output = output
+	return nil
+}
+
+// RenderKubernetesManifests writes the manifests of the stack into the directory and returns their paths,
+// the compose files are converted and the application labels are added to the resources
+func RenderKubernetesManifests(stack *portainer.Stack, kubeDeployer portainer.KubernetesDeployer, appLabels k.KubeAppLabels, dir string) ([]string, error) {
+	fileNames := stackutils.GetStackFilePaths(stack, false)
+
+	// a kustomization is rendered into a single manifest
+	if stack.Kustomization != nil {
+		fileNames = []string{stack.EntryPoint}
+	}
+
+	manifestFilePaths := make([]string, 0, len(fileNames))
+
 	for _, fileName := range fileNames {
-		manifestFilePath := filesystem.JoinPaths(tmpDir, fileName)
-		manifestContent, err := os.ReadFile(filesystem.JoinPaths(config.stack.ProjectPath, fileName))
+		manifestFilePath := filesystem.JoinPaths(dir, fileName)
+		manifestContent, err := readManifest(stack, fileName)
 		if err != nil {
-			return errors.Wrap(err, "failed to read manifest file")
+			return nil, err
+		}
+
+		// the manifests are not interpolated by kubectl, the references to the secret variables of a custom
+		// template are resolved here so that their values are never written to the stack files
+		if len(stack.Env) > 0 {
+			manifestContent = []byte(variables.ResolveReferences(string(manifestContent), stack.Env))
 		}
 
-		if config.stack.IsComposeFormat {
-			manifestContent, err = config.kubernetesDeployer.ConvertCompose(manifestContent)
+		if stack.IsComposeFormat {
+			manifestContent, err = kubeDeployer.ConvertCompose(manifestContent)
 			if err != nil {
-				return errors.Wrap(err, "failed to convert docker compose file to a kube manifest")
+				return nil, errors.Wrap(err, "failed to convert docker compose file to a kube manifest")
 			}
 		}
 
-		manifestContent, err = k.AddAppLabels(manifestContent, config.appLabels.ToMap())
+		manifestContent, err = k.AddAppLabels(manifestContent, appLabels.ToMap())
 		if err != nil {
-			return errors.Wrap(err, "failed to add application labels")
+			return nil, errors.Wrap(err, "failed to add application labels")
 		}
 
 		err = filesystem.WriteToFile(manifestFilePath, []byte(manifestContent))
 		if err != nil {
-			return errors.Wrap(err, "failed to create temp manifest file")
+			return nil, errors.Wrap(err, "failed to create temp manifest file")
 		}
 
 		manifestFilePaths = append(manifestFilePaths, manifestFilePath)
 	}
 
-	output, err := config.kubernetesDeployer.Deploy(config.user.ID, config.endpoint, manifestFilePaths, config.stack.Namespace)
+	return manifestFilePaths, nil
+}
+
+// readManifest reads a manifest file of the stack, the kustomization of the stack is rendered instead of its kustomization file
+func readManifest(stack *portainer.Stack, fileName string) ([]byte, error) {
+	if stack.Kustomization != nil {
+		return kustomize.Render(stack.ProjectPath, *stack.Kustomization)
+	}
+
+	manifestContent, err := os.ReadFile(filesystem.JoinPaths(stack.ProjectPath, fileName))
 	if err != nil {
-		return fmt.Errorf("failed to deploy kubernete stack: %w", err)
+		return nil, errors.Wrap(err, "failed to read manifest file")
 	}
 
-	config.output = output
-	return nil
+	return manifestContent, nil
 }
 
 func (config *KubernetesStackDeploymentConfig) GetResponse() string {
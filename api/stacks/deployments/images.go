@@ -0,0 +1,106 @@
+package deployments
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sort"
+
+	portainer "github.com/portainer/portainer/api"
+	k "github.com/portainer/portainer/api/kubernetes"
+	"github.com/portainer/portainer/api/stacks/stackplan"
+
+	"github.com/pkg/errors"
+)
+
+// StackPolicy validates a stack before it is deployed
+type StackPolicy interface {
+	CheckStack(ctx context.Context, stack *portainer.Stack, endpoint *portainer.Endpoint) error
+}
+
+// StackImages returns the images referenced by the files of the stack. The compose files are resolved by docker compose
+// and the Kubernetes manifests are rendered the same way they are on deployment
+func StackImages(ctx context.Context, stack *portainer.Stack, composeStackManager portainer.ComposeStackManager, kubeDeployer portainer.KubernetesDeployer) ([]string, error) {
+	images := []string{}
+
+	switch stack.Type {
+	case portainer.DockerComposeStack, portainer.DockerSwarmStack:
+		config, err := composeStackManager.Config(ctx, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		services, err := stackplan.ParseComposeConfig(config, stack.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, service := range services {
+			// the images built by the stack do not exist yet and cannot be scanned
+			if service.Built {
+				continue
+			}
+
+			images = append(images, service.Image)
+		}
+
+	case portainer.KubernetesStack:
+		kubernetesImages, err := kubernetesStackImages(stack, kubeDeployer)
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, kubernetesImages...)
+	}
+
+	sort.Strings(images)
+
+	return slices.Compact(images), nil
+}
+
+func kubernetesStackImages(stack *portainer.Stack, kubeDeployer portainer.KubernetesDeployer) ([]string, error) {
+	tmpDir, err := os.MkdirTemp("", "stack_images")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temp dir for the stack manifests")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	appLabels := k.KubeAppLabels{StackID: int(stack.ID), StackName: stack.Name}
+
+	manifestFilePaths, err := RenderKubernetesManifests(stack, kubeDeployer, appLabels, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	images := []string{}
+	for _, manifestFilePath := range manifestFilePaths {
+		manifest, err := os.ReadFile(manifestFilePath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the rendered manifest")
+		}
+
+		_, err = k.ExtractDocuments(manifest, func(document interface{}) error {
+			if object, ok := document.(map[string]interface{}); ok {
+				images = append(images, stackplan.Object(object).Images()...)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return images, nil
+}
+
+// CheckPolicies validates the stack against the policies of the deployer, the first refusal is returned
+func (d *stackDeployer) CheckPolicies(stack *portainer.Stack, endpoint *portainer.Endpoint) error {
+	for _, policy := range d.policies {
+		if err := policy.CheckStack(context.TODO(), stack, endpoint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package deployments
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/pkg/errors"
+	portainer "github.com/portainer/portainer/api"
+)
+
+// SetSecretEnv stores the secret variables of the stack encrypted with the server key,
+// they are supplied again through the stack environment on every deployment
+func (d *stackDeployer) SetSecretEnv(stack *portainer.Stack, env []portainer.Pair) error {
+	if len(env) == 0 {
+		stack.SecretEnv = ""
+		return nil
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the secret variables")
+	}
+
+	stack.SecretEnv, err = d.secretService.Encrypt(string(data))
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt the secret variables")
+	}
+
+	return nil
+}
+
+// SecretEnv returns the decrypted secret variables of the stack
+func (d *stackDeployer) SecretEnv(stack *portainer.Stack) ([]portainer.Pair, error) {
+	if stack.SecretEnv == "" {
+		return nil, nil
+	}
+
+	data, err := d.secretService.Decrypt(stack.SecretEnv)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt the secret variables")
+	}
+
+	var env []portainer.Pair
+	if err := json.Unmarshal([]byte(data), &env); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the secret variables")
+	}
+
+	return env, nil
+}
+
+// WithSecretEnv adds the secret variables to the stack environment for the duration of the call,
+// the environment is restored afterwards so that the values are never saved in clear text
+func (d *stackDeployer) WithSecretEnv(stack *portainer.Stack, call func() error) error {
+	secretEnv, err := d.SecretEnv(stack)
+	if err != nil {
+		return err
+	}
+
+	if len(secretEnv) == 0 {
+		return call()
+	}
+
+	env := stack.Env
+	stack.Env = append(slices.Clone(env), secretEnv...)
+	defer func() { stack.Env = env }()
+
+	return call()
+}
@@ -0,0 +1,42 @@
+package deployments
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_stackDeployer_SecretEnv(t *testing.T) {
+	is := assert.New(t)
+
+	secretService, err := crypto.NewSecretService(make([]byte, crypto.SecretKeySize))
+	is.NoError(err)
+
+	deployer := NewStackDeployer(nil, nil, nil, nil, nil, secretService)
+
+	stack := &portainer.Stack{Env: []portainer.Pair{{Name: "PUBLIC", Value: "value"}}}
+	secretEnv := []portainer.Pair{{Name: "SECRET", Value: "password"}}
+
+	is.NoError(deployer.SetSecretEnv(stack, secretEnv))
+	is.NotEmpty(stack.SecretEnv)
+	is.NotContains(stack.SecretEnv, "password", "the secret values should be stored encrypted")
+
+	env, err := deployer.SecretEnv(stack)
+	is.NoError(err)
+	is.Equal(secretEnv, env)
+
+	var deployEnv []portainer.Pair
+	err = deployer.WithSecretEnv(stack, func() error {
+		deployEnv = stack.Env
+		return nil
+	})
+	is.NoError(err)
+	is.Equal(append([]portainer.Pair{{Name: "PUBLIC", Value: "value"}}, secretEnv...), deployEnv)
+	is.Equal([]portainer.Pair{{Name: "PUBLIC", Value: "value"}}, stack.Env, "the environment should be restored after the deployment")
+
+	is.NoError(deployer.SetSecretEnv(stack, nil))
+	is.Empty(stack.SecretEnv)
+}
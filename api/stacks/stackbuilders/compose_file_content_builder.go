@@ -35,6 +35,11 @@ func (b *ComposeStackFileContentBuilder) SetGeneralInfo(payload *StackPayload, e
 	return b
 }
 
+func (b *ComposeStackFileContentBuilder) SetCustomTemplate(payload *StackPayload) FileContentMethodStackBuildProcess {
+	b.FileContentMethodStackBuilder.SetCustomTemplate(payload)
+	return b
+}
+
 func (b *ComposeStackFileContentBuilder) SetUniqueInfo(payload *StackPayload) FileContentMethodStackBuildProcess {
 	if b.hasError() {
 		return b
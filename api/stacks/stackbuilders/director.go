@@ -38,6 +38,7 @@ func (d *StackBuilderDirector) Build(payload *StackPayload, endpoint *portainer.
 
 	case FileContentMethodStackBuildProcess:
 		return builder.SetGeneralInfo(payload, endpoint).
+			SetCustomTemplate(payload).
 			SetUniqueInfo(payload).
 			SetFileContent(payload).
 			Deploy(payload, endpoint).
@@ -43,6 +43,11 @@ func (b *K8sStackFileContentBuilder) SetGeneralInfo(payload *StackPayload, endpo
 	return b
 }
 
+func (b *K8sStackFileContentBuilder) SetCustomTemplate(payload *StackPayload) FileContentMethodStackBuildProcess {
+	b.FileContentMethodStackBuilder.SetCustomTemplate(payload)
+	return b
+}
+
 func (b *K8sStackFileContentBuilder) SetUniqueInfo(payload *StackPayload) FileContentMethodStackBuildProcess {
 	if b.hasError() {
 		return b
@@ -93,7 +98,7 @@ func (b *K8sStackFileContentBuilder) Deploy(payload *StackPayload, endpoint *por
 		Kind:      "content",
 	}
 
-	k8sDeploymentConfig, err := deployments.CreateKubernetesStackDeploymentConfig(b.stack, b.KuberneteDeployer, k8sAppLabel, b.User, endpoint)
+	k8sDeploymentConfig, err := deployments.CreateKubernetesStackDeploymentConfig(b.stack, b.KuberneteDeployer, b.stackDeployer, k8sAppLabel, b.User, endpoint)
 	if err != nil {
 		b.err = httperror.InternalServerError("failed to create temp kub deployment files", err)
 		return b
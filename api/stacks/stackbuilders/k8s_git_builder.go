@@ -6,6 +6,7 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
 	k "github.com/portainer/portainer/api/kubernetes"
+	"github.com/portainer/portainer/api/kubernetes/kustomize"
 	"github.com/portainer/portainer/api/scheduler"
 	"github.com/portainer/portainer/api/stacks/deployments"
 	"github.com/portainer/portainer/api/stacks/stackutils"
@@ -56,6 +57,7 @@ func (b *KubernetesStackGitBuilder) SetUniqueInfo(payload *StackPayload) GitMeth
 	b.stack.EntryPoint = payload.ManifestFile
 	b.stack.CreatedBy = b.user.Username
 	b.stack.IsComposeFormat = payload.ComposeFormat
+	b.stack.Kustomization = payload.Kustomization
 	return b
 }
 
@@ -72,6 +74,18 @@ func (b *KubernetesStackGitBuilder) Deploy(payload *StackPayload, endpoint *port
 	b.stackCreateMut.Lock()
 	defer b.stackCreateMut.Unlock()
 
+	// the entry point of a kustomization stack is its kustomization file, it is known once the repository is cloned
+	if b.stack.Kustomization != nil {
+		entryPoint, err := kustomize.FindKustomizationFile(b.stack.ProjectPath, *b.stack.Kustomization)
+		if err != nil {
+			b.err = httperror.BadRequest("Invalid kustomization directory", err)
+			return b
+		}
+
+		b.stack.EntryPoint = entryPoint
+		b.stack.GitConfig.ConfigFilePath = entryPoint
+	}
+
 	k8sAppLabel := k.KubeAppLabels{
 		StackID:   int(b.stack.ID),
 		StackName: b.stack.Name,
@@ -79,7 +93,7 @@ func (b *KubernetesStackGitBuilder) Deploy(payload *StackPayload, endpoint *port
 		Kind:      "git",
 	}
 
-	k8sDeploymentConfig, err := deployments.CreateKubernetesStackDeploymentConfig(b.stack, b.KuberneteDeployer, k8sAppLabel, b.user, endpoint)
+	k8sDeploymentConfig, err := deployments.CreateKubernetesStackDeploymentConfig(b.stack, b.KuberneteDeployer, b.stackDeployer, k8sAppLabel, b.user, endpoint)
 	if err != nil {
 		b.err = httperror.InternalServerError("failed to create temp kub deployment files", err)
 		return b
@@ -4,12 +4,15 @@ import (
 	"time"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/customtemplates/variables"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 )
 
 type FileContentMethodStackBuildProcess interface {
 	// Set general stack information
 	SetGeneralInfo(payload *StackPayload, endpoint *portainer.Endpoint) FileContentMethodStackBuildProcess
+	// Render the stack file from a custom template, if any
+	SetCustomTemplate(payload *StackPayload) FileContentMethodStackBuildProcess
 	// Set unique stack information, e.g. swarm stack has swarmID, kubernetes stack has namespace
 	SetUniqueInfo(payload *StackPayload) FileContentMethodStackBuildProcess
 	// Deploy stack based on the configuration
@@ -35,6 +38,45 @@ func (b *FileContentMethodStackBuilder) SetGeneralInfo(payload *StackPayload, en
 	return b
 }
 
+func (b *FileContentMethodStackBuilder) SetCustomTemplate(payload *StackPayload) FileContentMethodStackBuildProcess {
+	if b.hasError() || payload.CustomTemplateID == 0 {
+		return b
+	}
+
+	customTemplate, err := b.dataStore.CustomTemplate().Read(payload.CustomTemplateID)
+	if err != nil {
+		b.err = httperror.InternalServerError("Unable to retrieve the custom template from the database", err)
+		return b
+	}
+
+	values, err := variables.ResolveValues(customTemplate.Variables, payload.CustomTemplateVariables)
+	if err != nil {
+		b.err = httperror.BadRequest("Invalid custom template variables", err)
+		return b
+	}
+
+	fileContent, err := b.fileService.GetFileContent(customTemplate.ProjectPath, customTemplate.EntryPoint)
+	if err != nil {
+		b.err = httperror.InternalServerError("Unable to retrieve custom template file from disk", err)
+		return b
+	}
+
+	// the secret values are stored encrypted with the stack, they are supplied by the stack deployer on every deployment
+	var secretEnv []portainer.Pair
+	payload.StackFileContent, secretEnv = variables.RenderStackFile(string(fileContent), customTemplate.Variables, values)
+	payload.Env = variables.FilterSecrets(customTemplate.Variables, payload.Env)
+
+	if err := b.stackDeployer.SetSecretEnv(b.stack, secretEnv); err != nil {
+		b.err = httperror.InternalServerError("Unable to store the secret template variables", err)
+		return b
+	}
+
+	b.stack.CustomTemplateID = customTemplate.ID
+	b.stack.CustomTemplateVersion = customTemplate.Version
+
+	return b
+}
+
 func (b *FileContentMethodStackBuilder) SetUniqueInfo(payload *StackPayload) FileContentMethodStackBuildProcess {
 
 	return b
@@ -61,14 +61,17 @@ func (b *GitMethodStackBuilder) SetGitRepository(payload *StackPayload) GitMetho
 	var repoConfig gittypes.RepoConfig
 	if payload.Authentication {
 		repoConfig.Authentication = &gittypes.GitAuthentication{
-			Username: payload.RepositoryConfigPayload.Username,
-			Password: payload.RepositoryConfigPayload.Password,
+			Username:        payload.RepositoryConfigPayload.Username,
+			Password:        payload.RepositoryConfigPayload.Password,
+			SSHKeyID:        payload.RepositoryConfigPayload.SSHKeyID,
+			GitCredentialID: payload.RepositoryConfigPayload.GitCredentialID,
 		}
 	}
 
 	repoConfig.URL = payload.URL
 	repoConfig.ReferenceName = payload.ReferenceName
 	repoConfig.TLSSkipVerify = payload.TLSSkipVerify
+	repoConfig.SetContentOptions(payload.Content)
 
 	repoConfig.ConfigFilePath = payload.ComposeFile
 	if payload.ComposeFile == "" {
@@ -2,6 +2,7 @@ package stackbuilders
 
 import (
 	portainer "github.com/portainer/portainer/api"
+	gittypes "github.com/portainer/portainer/api/git/types"
 )
 
 // StackPayload contains all the fields for creating a stack with all kinds of methods
@@ -35,6 +36,12 @@ type StackPayload struct {
 	ComposeFile string `example:"docker-compose.yml" default:"docker-compose.yml"`
 	// Applicable when deploying with multiple stack files
 	AdditionalFiles []string `example:"[nz.compose.yml, uat.compose.yml]"`
+	// Kustomization the k8s Stack is rendered from. Used by k8s git repository method
+	Kustomization *portainer.KubernetesKustomization
+	// Identifier of the custom template used to render the stack file. Used by file content method
+	CustomTemplateID portainer.CustomTemplateID `example:"1"`
+	// Values of the custom template variables
+	CustomTemplateVariables []portainer.Pair
 	// Git repository configuration of a stack
 	RepositoryConfigPayload
 }
@@ -52,6 +59,12 @@ type RepositoryConfigPayload struct {
 	// Password used in basic authentication. Required when RepositoryAuthentication is true
 	// and RepositoryGitCredentialID is 0
 	Password string `example:"myGitPassword"`
+	// Identifier of the SSH key used to authenticate when Authentication is true, used instead of Username and Password when not 0
+	SSHKeyID int `example:"0"`
+	// Identifier of the shared git credential used to authenticate when Authentication is true, used instead of Username and Password when not 0
+	GitCredentialID int `example:"0"`
 	// TLSSkipVerify skips SSL verification when cloning the Git repository
 	TLSSkipVerify bool `example:"false"`
+	// Submodules and LFS objects fetched with the repository, only the files of the repository are fetched when nil
+	Content *gittypes.ContentOptions
 }
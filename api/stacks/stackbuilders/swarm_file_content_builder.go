@@ -35,6 +35,11 @@ func (b *SwarmStackFileContentBuilder) SetGeneralInfo(payload *StackPayload, end
 	return b
 }
 
+func (b *SwarmStackFileContentBuilder) SetCustomTemplate(payload *StackPayload) FileContentMethodStackBuildProcess {
+	b.FileContentMethodStackBuilder.SetCustomTemplate(payload)
+	return b
+}
+
 func (b *SwarmStackFileContentBuilder) SetUniqueInfo(payload *StackPayload) FileContentMethodStackBuildProcess {
 	if b.hasError() {
 		return b
@@ -0,0 +1,164 @@
+package stackplan
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/pkg/errors"
+)
+
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+	swarmNamespaceLabel = "com.docker.stack.namespace"
+)
+
+// Service represents a service of a compose or swarm stack
+type Service struct {
+	Name  string
+	Image string
+	// Number of containers of the service, 0 when the service is global
+	Replicas int
+	// Built is true when the image of the service is built by docker compose
+	Built bool
+}
+
+type composeConfig struct {
+	Services map[string]struct {
+		Image  string          `json:"image"`
+		Build  json.RawMessage `json:"build"`
+		Scale  *int            `json:"scale"`
+		Deploy *struct {
+			Mode     string `json:"mode"`
+			Replicas *int   `json:"replicas"`
+		} `json:"deploy"`
+	} `json:"services"`
+}
+
+// ParseComposeConfig returns the services of a compose project in the JSON format, as returned by `docker compose config`.
+// The services that are only built are given the image name generated by docker compose
+func ParseComposeConfig(config []byte, projectName string) ([]Service, error) {
+	var project composeConfig
+	err := json.Unmarshal(config, &project)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse the compose project")
+	}
+
+	services := make([]Service, 0, len(project.Services))
+	for name, definition := range project.Services {
+		service := Service{Name: name, Image: definition.Image, Replicas: 1, Built: len(definition.Build) > 0}
+		if service.Image == "" {
+			service.Image = projectName + "-" + name
+		}
+
+		switch {
+		case definition.Deploy != nil && definition.Deploy.Mode == "global":
+			service.Replicas = 0
+		case definition.Deploy != nil && definition.Deploy.Replicas != nil:
+			service.Replicas = *definition.Deploy.Replicas
+		case definition.Scale != nil:
+			service.Replicas = *definition.Scale
+		}
+
+		services = append(services, service)
+	}
+
+	return services, nil
+}
+
+// ComposeServices returns the services of a compose project from its containers
+func ComposeServices(containers []types.Container, projectName string) []Service {
+	services := make(map[string]*Service)
+	names := []string{}
+
+	for _, container := range containers {
+		if container.Labels[composeProjectLabel] != projectName {
+			continue
+		}
+
+		name := container.Labels[composeServiceLabel]
+		service, ok := services[name]
+		if !ok {
+			service = &Service{Name: name, Image: container.Image}
+			services[name] = service
+			names = append(names, name)
+		}
+		service.Replicas++
+	}
+
+	result := make([]Service, 0, len(names))
+	for _, name := range names {
+		result = append(result, *services[name])
+	}
+
+	return result
+}
+
+// SwarmServices returns the services of a swarm stack, the services are named after the stack
+func SwarmServices(swarmServices []swarm.Service, namespace string) []Service {
+	services := []Service{}
+
+	for _, swarmService := range swarmServices {
+		if swarmService.Spec.Labels[swarmNamespaceLabel] != namespace {
+			continue
+		}
+
+		service := Service{Name: strings.TrimPrefix(swarmService.Spec.Name, namespace+"_")}
+		if swarmService.Spec.TaskTemplate.ContainerSpec != nil {
+			service.Image = swarmService.Spec.TaskTemplate.ContainerSpec.Image
+		}
+		if swarmService.Spec.Mode.Replicated != nil && swarmService.Spec.Mode.Replicated.Replicas != nil {
+			service.Replicas = int(*swarmService.Spec.Mode.Replicated.Replicas)
+		}
+
+		services = append(services, service)
+	}
+
+	return services
+}
+
+// DiffServices compares the services defined by the stack files with the services currently running.
+// A service is changed when its image or its number of replicas is updated
+func DiffServices(desired, running []Service) *Plan {
+	plan := newPlan()
+
+	current := make(map[string]Service, len(running))
+	for _, service := range running {
+		current[service.Name] = service
+	}
+
+	for _, service := range desired {
+		resource := Resource{Kind: ServiceKind, Name: service.Name}
+
+		runningService, ok := current[service.Name]
+		if !ok {
+			plan.Added = append(plan.Added, resource)
+			plan.ImageChanges = append(plan.ImageChanges, ImageChange{Kind: ServiceKind, Name: service.Name, Container: service.Name, DesiredImage: service.Image})
+			continue
+		}
+		delete(current, service.Name)
+
+		if !sameImage(runningService.Image, service.Image) {
+			resource.Fields = append(resource.Fields, "image")
+			plan.ImageChanges = append(plan.ImageChanges, ImageChange{Kind: ServiceKind, Name: service.Name, Container: service.Name, CurrentImage: runningService.Image, DesiredImage: service.Image})
+		}
+
+		if service.Replicas != runningService.Replicas {
+			resource.Fields = append(resource.Fields, "replicas")
+		}
+
+		if len(resource.Fields) > 0 {
+			plan.Changed = append(plan.Changed, resource)
+		}
+	}
+
+	for name := range current {
+		plan.Removed = append(plan.Removed, Resource{Kind: ServiceKind, Name: name})
+	}
+
+	plan.sort()
+
+	return plan
+}
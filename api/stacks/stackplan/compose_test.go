@@ -0,0 +1,112 @@
+package stackplan
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseComposeConfig(t *testing.T) {
+	is := assert.New(t)
+
+	services, err := ParseComposeConfig([]byte(`{
+		"name": "web",
+		"services": {
+			"nginx": {"image": "nginx:1.25", "deploy": {"replicas": 3}},
+			"api": {"build": {"context": "."}},
+			"agent": {"image": "portainer/agent", "deploy": {"mode": "global"}},
+			"worker": {"image": "worker", "scale": 2}
+		}
+	}`), "web")
+	is.NoError(err)
+
+	is.ElementsMatch([]Service{
+		{Name: "nginx", Image: "nginx:1.25", Replicas: 3},
+		{Name: "api", Image: "web-api", Replicas: 1, Built: true},
+		{Name: "agent", Image: "portainer/agent", Replicas: 0},
+		{Name: "worker", Image: "worker", Replicas: 2},
+	}, services)
+
+	_, err = ParseComposeConfig([]byte("services:"), "web")
+	is.Error(err)
+}
+
+func Test_ComposeServices(t *testing.T) {
+	is := assert.New(t)
+
+	containers := []types.Container{
+		{Image: "nginx:1.24", Labels: map[string]string{composeProjectLabel: "web", composeServiceLabel: "nginx"}},
+		{Image: "nginx:1.24", Labels: map[string]string{composeProjectLabel: "web", composeServiceLabel: "nginx"}},
+		{Image: "redis", Labels: map[string]string{composeProjectLabel: "web", composeServiceLabel: "redis"}},
+		{Image: "redis", Labels: map[string]string{composeProjectLabel: "other", composeServiceLabel: "redis"}},
+	}
+
+	is.Equal([]Service{
+		{Name: "nginx", Image: "nginx:1.24", Replicas: 2},
+		{Name: "redis", Image: "redis", Replicas: 1},
+	}, ComposeServices(containers, "web"))
+}
+
+func Test_SwarmServices(t *testing.T) {
+	is := assert.New(t)
+
+	replicas := uint64(2)
+	services := []swarm.Service{
+		{Spec: swarm.ServiceSpec{
+			Annotations:  swarm.Annotations{Name: "web_nginx", Labels: map[string]string{swarmNamespaceLabel: "web"}},
+			TaskTemplate: swarm.TaskSpec{ContainerSpec: &swarm.ContainerSpec{Image: "nginx:1.24@sha256:2e8b8f5b6f3c4f5a0c0a3b7a8c1f4d0e7b0c4a1e9b4f7c4e2f1b8e7a9c2d4e6f"}},
+			Mode:         swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+		}},
+		{Spec: swarm.ServiceSpec{
+			Annotations:  swarm.Annotations{Name: "web_agent", Labels: map[string]string{swarmNamespaceLabel: "web"}},
+			TaskTemplate: swarm.TaskSpec{ContainerSpec: &swarm.ContainerSpec{Image: "portainer/agent"}},
+			Mode:         swarm.ServiceMode{Global: &swarm.GlobalService{}},
+		}},
+		{Spec: swarm.ServiceSpec{
+			Annotations: swarm.Annotations{Name: "other_nginx", Labels: map[string]string{swarmNamespaceLabel: "other"}},
+		}},
+	}
+
+	is.Equal([]Service{
+		{Name: "nginx", Image: "nginx:1.24@sha256:2e8b8f5b6f3c4f5a0c0a3b7a8c1f4d0e7b0c4a1e9b4f7c4e2f1b8e7a9c2d4e6f", Replicas: 2},
+		{Name: "agent", Image: "portainer/agent"},
+	}, SwarmServices(services, "web"))
+}
+
+func Test_DiffServices(t *testing.T) {
+	is := assert.New(t)
+
+	plan := DiffServices(
+		[]Service{
+			{Name: "nginx", Image: "nginx:1.25", Replicas: 2},
+			{Name: "redis", Image: "docker.io/library/redis:latest", Replicas: 1},
+			{Name: "api", Image: "api:2", Replicas: 1},
+			{Name: "worker", Image: "worker", Replicas: 3},
+		},
+		[]Service{
+			{Name: "nginx", Image: "nginx:1.24@sha256:2e8b8f5b6f3c4f5a0c0a3b7a8c1f4d0e7b0c4a1e9b4f7c4e2f1b8e7a9c2d4e6f", Replicas: 2},
+			{Name: "redis", Image: "redis", Replicas: 1},
+			{Name: "worker", Image: "worker", Replicas: 1},
+			{Name: "legacy", Image: "legacy", Replicas: 1},
+		},
+	)
+
+	is.Equal([]Resource{{Kind: ServiceKind, Name: "api"}}, plan.Added)
+	is.Equal([]Resource{
+		{Kind: ServiceKind, Name: "nginx", Fields: []string{"image"}},
+		{Kind: ServiceKind, Name: "worker", Fields: []string{"replicas"}},
+	}, plan.Changed)
+	is.Equal([]Resource{{Kind: ServiceKind, Name: "legacy"}}, plan.Removed)
+	is.Equal([]ImageChange{
+		{Kind: ServiceKind, Name: "api", Container: "api", DesiredImage: "api:2"},
+		{Kind: ServiceKind, Name: "nginx", Container: "nginx", CurrentImage: "nginx:1.24@sha256:2e8b8f5b6f3c4f5a0c0a3b7a8c1f4d0e7b0c4a1e9b4f7c4e2f1b8e7a9c2d4e6f", DesiredImage: "nginx:1.25"},
+	}, plan.ImageChanges)
+
+	plan = DiffServices([]Service{{Name: "nginx", Image: "nginx", Replicas: 1}}, []Service{{Name: "nginx", Image: "nginx:latest", Replicas: 1}})
+	is.Empty(plan.Added)
+	is.Empty(plan.Changed)
+	is.Empty(plan.Removed)
+	is.Empty(plan.ImageChanges)
+}
@@ -0,0 +1,281 @@
+package stackplan
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const lastAppliedConfigurationAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// Object represents a Kubernetes object in the unstructured format
+type Object map[string]any
+
+// ParseKubernetesObjects parses the JSON output of kubectl, which is either a single object, a list
+// or a stream of those
+func ParseKubernetesObjects(output string) ([]Object, error) {
+	objects := []Object{}
+
+	decoder := json.NewDecoder(strings.NewReader(output))
+	for {
+		var object Object
+		err := decoder.Decode(&object)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, "unable to parse the Kubernetes objects")
+		}
+
+		items, isList := object["items"].([]any)
+		if !isList {
+			objects = append(objects, object)
+			continue
+		}
+
+		for _, item := range items {
+			if itemObject, ok := item.(map[string]any); ok {
+				objects = append(objects, itemObject)
+			}
+		}
+	}
+}
+
+// DiffKubernetesObjects compares the objects returned by the server-side dry-run of the new manifests with the live objects.
+// The live objects of the current manifests that are not defined by the new manifests anymore are reported as removed,
+// even if they are left untouched by the redeployment
+func DiffKubernetesObjects(desired, live, current []Object) *Plan {
+	plan := newPlan()
+
+	liveObjects := make(map[string]Object, len(live))
+	for _, object := range live {
+		liveObjects[resourceKey(object.resource())] = object
+	}
+
+	desiredKeys := make(map[string]bool, len(desired))
+	for _, object := range desired {
+		resource := object.resource()
+		key := resourceKey(resource)
+		desiredKeys[key] = true
+
+		liveObject, ok := liveObjects[key]
+		if !ok {
+			plan.Added = append(plan.Added, resource)
+			plan.ImageChanges = append(plan.ImageChanges, diffImages(resource, nil, object)...)
+			continue
+		}
+
+		resource.Fields = diffFields(liveObject, object)
+		if len(resource.Fields) > 0 {
+			plan.Changed = append(plan.Changed, resource)
+		}
+		plan.ImageChanges = append(plan.ImageChanges, diffImages(resource, liveObject, object)...)
+	}
+
+	removed := make(map[string]bool)
+	for _, object := range current {
+		resource := object.resource()
+		key := resourceKey(resource)
+		if desiredKeys[key] || removed[key] {
+			continue
+		}
+
+		removed[key] = true
+		plan.Removed = append(plan.Removed, resource)
+	}
+
+	plan.sort()
+
+	return plan
+}
+
+func (object Object) resource() Resource {
+	resource := Resource{}
+	resource.Kind, _ = object["kind"].(string)
+
+	if metadata, ok := object["metadata"].(map[string]any); ok {
+		resource.Namespace, _ = metadata["namespace"].(string)
+		resource.Name, _ = metadata["name"].(string)
+	}
+
+	return resource
+}
+
+// diffFields returns the paths of the fields updated in the object, the paths are limited to the second level.
+// The status and the metadata managed by the server are ignored, as well as the apiVersion as the live objects
+// are returned in the preferred version of their group
+func diffFields(live, desired Object) []string {
+	fields := []string{}
+
+	for _, key := range unionKeys(live, desired) {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			fields = append(fields, diffMetadata(live[key], desired[key])...)
+			continue
+		}
+
+		if reflect.DeepEqual(live[key], desired[key]) {
+			continue
+		}
+
+		liveValue, liveIsMap := live[key].(map[string]any)
+		desiredValue, desiredIsMap := desired[key].(map[string]any)
+		if !liveIsMap || !desiredIsMap {
+			fields = append(fields, key)
+			continue
+		}
+
+		for _, subKey := range unionKeys(liveValue, desiredValue) {
+			if !reflect.DeepEqual(liveValue[subKey], desiredValue[subKey]) {
+				fields = append(fields, key+"."+subKey)
+			}
+		}
+	}
+
+	return fields
+}
+
+func diffMetadata(live, desired any) []string {
+	liveMetadata, _ := live.(map[string]any)
+	desiredMetadata, _ := desired.(map[string]any)
+
+	fields := []string{}
+	for _, key := range []string{"labels", "annotations"} {
+		liveValue, _ := liveMetadata[key].(map[string]any)
+		desiredValue, _ := desiredMetadata[key].(map[string]any)
+
+		if key == "annotations" {
+			liveValue = withoutKey(liveValue, lastAppliedConfigurationAnnotation)
+			desiredValue = withoutKey(desiredValue, lastAppliedConfigurationAnnotation)
+		}
+
+		if len(liveValue) == 0 && len(desiredValue) == 0 {
+			continue
+		}
+
+		if !reflect.DeepEqual(liveValue, desiredValue) {
+			fields = append(fields, "metadata."+key)
+		}
+	}
+
+	return fields
+}
+
+// diffImages returns the containers of the object whose image is updated, all the containers are returned when
+// the object is added
+func diffImages(resource Resource, live, desired Object) []ImageChange {
+	liveImages := containerImages(live)
+
+	changes := []ImageChange{}
+	for container, image := range containerImages(desired) {
+		currentImage, ok := liveImages[container]
+		if ok && sameImage(currentImage, image) {
+			continue
+		}
+
+		changes = append(changes, ImageChange{
+			Kind:         resource.Kind,
+			Namespace:    resource.Namespace,
+			Name:         resource.Name,
+			Container:    container,
+			CurrentImage: currentImage,
+			DesiredImage: image,
+		})
+	}
+
+	return changes
+}
+
+// containerImages returns the images of the containers and the init containers of the pod template of the object
+func containerImages(object Object) map[string]string {
+	podSpec := podSpec(object)
+
+	images := make(map[string]string)
+	for _, key := range []string{"initContainers", "containers"} {
+		containers, _ := podSpec[key].([]any)
+		for _, container := range containers {
+			containerSpec, _ := container.(map[string]any)
+			name, _ := containerSpec["name"].(string)
+			image, _ := containerSpec["image"].(string)
+			if name != "" {
+				images[name] = image
+			}
+		}
+	}
+
+	return images
+}
+
+// Images returns the sorted images of the containers of the object, an object without pod template has no image
+func (object Object) Images() []string {
+	images := []string{}
+	for _, image := range containerImages(object) {
+		if image != "" && !slices.Contains(images, image) {
+			images = append(images, image)
+		}
+	}
+
+	sort.Strings(images)
+
+	return images
+}
+
+func podSpec(object Object) map[string]any {
+	var path []string
+
+	kind, _ := object["kind"].(string)
+	switch kind {
+	case "Pod":
+		path = []string{"spec"}
+	case "CronJob":
+		path = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	default:
+		path = []string{"spec", "template", "spec"}
+	}
+
+	var value any = map[string]any(object)
+	for _, key := range path {
+		fields, _ := value.(map[string]any)
+		value = fields[key]
+	}
+
+	spec, _ := value.(map[string]any)
+	return spec
+}
+
+func unionKeys(left, right map[string]any) []string {
+	keys := make([]string, 0, len(left)+len(right))
+	for key := range left {
+		keys = append(keys, key)
+	}
+	for key := range right {
+		if _, ok := left[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func withoutKey(values map[string]any, key string) map[string]any {
+	if _, ok := values[key]; !ok {
+		return values
+	}
+
+	result := make(map[string]any, len(values))
+	for k, v := range values {
+		if k != key {
+			result[k] = v
+		}
+	}
+
+	return result
+}
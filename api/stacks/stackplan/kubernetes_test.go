@@ -0,0 +1,106 @@
+package stackplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const liveObjects = `{
+	"apiVersion": "v1",
+	"kind": "List",
+	"items": [
+		{
+			"apiVersion": "apps/v1",
+			"kind": "Deployment",
+			"metadata": {
+				"name": "web",
+				"namespace": "default",
+				"resourceVersion": "42",
+				"labels": {"app": "web"},
+				"annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{}"}
+			},
+			"spec": {
+				"replicas": 2,
+				"template": {"spec": {"initContainers": [{"name": "init", "image": "busybox"}], "containers": [{"name": "nginx", "image": "nginx:1.24"}]}}
+			},
+			"status": {"replicas": 2}
+		},
+		{
+			"apiVersion": "v1",
+			"kind": "ConfigMap",
+			"metadata": {"name": "settings", "namespace": "default", "resourceVersion": "7"},
+			"data": {"mode": "production"}
+		}
+	]
+}`
+
+const desiredObjects = `{
+	"apiVersion": "v1",
+	"kind": "List",
+	"items": [
+		{
+			"apiVersion": "apps/v1",
+			"kind": "Deployment",
+			"metadata": {
+				"name": "web",
+				"namespace": "default",
+				"resourceVersion": "42",
+				"labels": {"app": "web"},
+				"annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{\"changed\": true}"}
+			},
+			"spec": {
+				"replicas": 3,
+				"template": {"spec": {"initContainers": [{"name": "init", "image": "busybox:latest"}], "containers": [{"name": "nginx", "image": "nginx:1.25"}]}}
+			},
+			"status": {"replicas": 2}
+		}
+	]
+}
+{
+	"apiVersion": "batch/v1",
+	"kind": "CronJob",
+	"metadata": {"name": "cleanup", "namespace": "default"},
+	"spec": {"jobTemplate": {"spec": {"template": {"spec": {"containers": [{"name": "cleanup", "image": "alpine:3.19"}]}}}}}
+}`
+
+func Test_ParseKubernetesObjects(t *testing.T) {
+	is := assert.New(t)
+
+	objects, err := ParseKubernetesObjects(desiredObjects)
+	is.NoError(err)
+	is.Len(objects, 2)
+
+	objects, err = ParseKubernetesObjects("")
+	is.NoError(err)
+	is.Empty(objects)
+
+	_, err = ParseKubernetesObjects("error: not json")
+	is.Error(err)
+}
+
+func Test_DiffKubernetesObjects(t *testing.T) {
+	is := assert.New(t)
+
+	live, err := ParseKubernetesObjects(liveObjects)
+	is.NoError(err)
+
+	desired, err := ParseKubernetesObjects(desiredObjects)
+	is.NoError(err)
+
+	plan := DiffKubernetesObjects(desired, live, live)
+
+	is.Equal([]Resource{{Kind: "CronJob", Namespace: "default", Name: "cleanup"}}, plan.Added)
+	is.Equal([]Resource{{Kind: "Deployment", Namespace: "default", Name: "web", Fields: []string{"spec.replicas", "spec.template"}}}, plan.Changed)
+	is.Equal([]Resource{{Kind: "ConfigMap", Namespace: "default", Name: "settings"}}, plan.Removed)
+	is.Equal([]ImageChange{
+		{Kind: "CronJob", Namespace: "default", Name: "cleanup", Container: "cleanup", DesiredImage: "alpine:3.19"},
+		{Kind: "Deployment", Namespace: "default", Name: "web", Container: "nginx", CurrentImage: "nginx:1.24", DesiredImage: "nginx:1.25"},
+	}, plan.ImageChanges)
+
+	plan = DiffKubernetesObjects(live, live, live)
+	is.Empty(plan.Added)
+	is.Empty(plan.Changed)
+	is.Empty(plan.Removed)
+	is.Empty(plan.ImageChanges)
+}
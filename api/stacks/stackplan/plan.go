@@ -0,0 +1,104 @@
+package stackplan
+
+import (
+	"sort"
+
+	"github.com/portainer/portainer/api/docker/images"
+)
+
+const (
+	// ServiceKind is the kind of the resources of the compose and swarm stacks
+	ServiceKind = "service"
+)
+
+// Plan represents the changes that the redeployment of a git stack would apply
+type Plan struct {
+	// Reference of the git repository the plan is computed for
+	ReferenceName string `json:"ReferenceName" example:"refs/heads/main"`
+	// Commit of the reference
+	CommitHash string `json:"CommitHash" example:"bd3fe7e3e4b5ba6bc6e2c1a4bb5b1a3b4a2d1c6e"`
+	// Services or Kubernetes objects created by the redeployment
+	Added []Resource `json:"Added"`
+	// Services or Kubernetes objects updated by the redeployment
+	Changed []Resource `json:"Changed"`
+	// Services or Kubernetes objects that are not defined by the stack files anymore
+	Removed []Resource `json:"Removed"`
+	// Container images replaced by the redeployment
+	ImageChanges []ImageChange `json:"ImageChanges"`
+}
+
+// Resource represents a service of a compose or swarm stack or a Kubernetes object
+type Resource struct {
+	// Kind of the Kubernetes object, service for the compose and swarm stacks
+	Kind string `json:"Kind" example:"Deployment"`
+	// Namespace of the Kubernetes object
+	Namespace string `json:"Namespace,omitempty" example:"default"`
+	Name      string `json:"Name" example:"web"`
+	// Fields updated by the redeployment, only set for the changed resources
+	Fields []string `json:"Fields,omitempty" example:"spec.replicas"`
+}
+
+// ImageChange represents the image of a container replaced by the redeployment
+type ImageChange struct {
+	// Kind of the Kubernetes object, service for the compose and swarm stacks
+	Kind string `json:"Kind" example:"Deployment"`
+	// Namespace of the Kubernetes object
+	Namespace string `json:"Namespace,omitempty" example:"default"`
+	Name      string `json:"Name" example:"web"`
+	// Name of the container inside the Kubernetes object, the service name for the compose and swarm stacks
+	Container string `json:"Container" example:"nginx"`
+	// Image currently deployed, empty when the container is added
+	CurrentImage string `json:"CurrentImage" example:"nginx:1.24"`
+	// Image deployed by the redeployment
+	DesiredImage string `json:"DesiredImage" example:"nginx:1.25"`
+}
+
+// newPlan returns an empty plan, the lists are never nil so that they are serialized as empty arrays
+func newPlan() *Plan {
+	return &Plan{
+		Added:        []Resource{},
+		Changed:      []Resource{},
+		Removed:      []Resource{},
+		ImageChanges: []ImageChange{},
+	}
+}
+
+func (plan *Plan) sort() {
+	for _, resources := range [][]Resource{plan.Added, plan.Changed, plan.Removed} {
+		sort.Slice(resources, func(i, j int) bool {
+			return resourceKey(resources[i]) < resourceKey(resources[j])
+		})
+	}
+
+	sort.Slice(plan.ImageChanges, func(i, j int) bool {
+		return imageChangeKey(plan.ImageChanges[i]) < imageChangeKey(plan.ImageChanges[j])
+	})
+}
+
+func resourceKey(resource Resource) string {
+	return resource.Kind + "/" + resource.Namespace + "/" + resource.Name
+}
+
+func imageChangeKey(change ImageChange) string {
+	return change.Kind + "/" + change.Namespace + "/" + change.Name + "/" + change.Container
+}
+
+// sameImage returns true when both references designate the same image, the default registry and tag are
+// resolved and the digests pinned by swarm are ignored
+func sameImage(current, desired string) bool {
+	return NormalizeImage(current) == NormalizeImage(desired)
+}
+
+// NormalizeImage resolves the default registry and tag of the image reference, the digest pinned along a tag is ignored
+func NormalizeImage(image string) string {
+	parsed, err := images.ParseImage(images.ParseImageOptions{Name: image})
+	if err != nil {
+		return image
+	}
+
+	if parsed.Tag == "" {
+		return parsed.FullName()
+	}
+
+	return parsed.Name() + ":" + parsed.Tag
+}
@@ -0,0 +1,90 @@
+package stackutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// ProjectHash returns the SHA-256 of the files of a stack project, git metadata excluded.
+// Two projects with the same file names and contents share the same hash.
+func ProjectHash(projectPath string) (string, error) {
+	hash := sha256.New()
+
+	err := filepath.WalkDir(projectPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(projectPath, path)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		io.WriteString(hash, filepath.ToSlash(relativePath))
+		hash.Write([]byte{0})
+		if _, err := io.Copy(hash, file); err != nil {
+			return err
+		}
+		hash.Write([]byte{0})
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// MergeEnv returns the environment variables with the overrides applied, overridden variables keep their position
+// and new variables are appended
+func MergeEnv(env []portainer.Pair, overrides []portainer.Pair) []portainer.Pair {
+	merged := make([]portainer.Pair, 0, len(env)+len(overrides))
+	positions := make(map[string]int, len(env))
+
+	for _, pair := range env {
+		if i, ok := positions[pair.Name]; ok {
+			merged[i] = pair
+			continue
+		}
+
+		positions[pair.Name] = len(merged)
+		merged = append(merged, pair)
+	}
+
+	for _, pair := range overrides {
+		if i, ok := positions[pair.Name]; ok {
+			merged[i] = pair
+			continue
+		}
+
+		positions[pair.Name] = len(merged)
+		merged = append(merged, pair)
+	}
+
+	return merged
+}
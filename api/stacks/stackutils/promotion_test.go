@@ -0,0 +1,44 @@
+package stackutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ProjectHash(t *testing.T) {
+	writeProject := func(files map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+			assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		}
+		return dir
+	}
+
+	hash := func(dir string) string {
+		h, err := ProjectHash(dir)
+		assert.NoError(t, err)
+		return h
+	}
+
+	reference := hash(writeProject(map[string]string{"docker-compose.yml": "services: {}", "conf/app.env": "A=1"}))
+
+	assert.Equal(t, reference, hash(writeProject(map[string]string{"docker-compose.yml": "services: {}", "conf/app.env": "A=1", ".git/HEAD": "ref"})), "git metadata should be ignored")
+	assert.NotEqual(t, reference, hash(writeProject(map[string]string{"docker-compose.yml": "services: {}", "conf/app.env": "A=2"})), "content changes should change the hash")
+	assert.NotEqual(t, reference, hash(writeProject(map[string]string{"docker-compose.yml": "services: {}", "conf/other.env": "A=1"})), "renames should change the hash")
+}
+
+func Test_MergeEnv(t *testing.T) {
+	env := []portainer.Pair{{Name: "IMAGE", Value: "nginx"}, {Name: "REPLICAS", Value: "1"}}
+	overrides := []portainer.Pair{{Name: "REPLICAS", Value: "3"}, {Name: "DOMAIN", Value: "prod.example.com"}}
+
+	merged := MergeEnv(env, overrides)
+
+	assert.Equal(t, []portainer.Pair{{Name: "IMAGE", Value: "nginx"}, {Name: "REPLICAS", Value: "3"}, {Name: "DOMAIN", Value: "prod.example.com"}}, merged)
+	assert.Equal(t, "1", env[1].Value, "the source environment should not be modified")
+}
@@ -0,0 +1,394 @@
+package volumebackup
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/archive"
+	"github.com/portainer/portainer/api/crypto"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/scheduler"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrPasswordRequired is returned when an encrypted backup is restored without a password
+	ErrPasswordRequired = errors.New("the backup is encrypted, a password is required")
+	// ErrInvalidArchive is returned when the archive of a backup cannot be read, usually because of a wrong password
+	ErrInvalidArchive = errors.New("unable to read the archive of the backup, the password is probably invalid")
+)
+
+// Archiver copies the content of the Docker volumes as tar streams
+type Archiver interface {
+	ExportVolume(endpoint *portainer.Endpoint, nodeName, volumeName string, output io.Writer) error
+	ImportVolume(endpoint *portainer.Endpoint, nodeName, volumeName string, input io.Reader) error
+	StackVolumes(endpoint *portainer.Endpoint, nodeName, stackName string) ([]string, error)
+}
+
+// Service backs up the Docker volumes into compressed and optionally encrypted archives stored by Portainer,
+// restores them and runs the backup schedules
+type Service struct {
+	dataStore     dataservices.DataStore
+	fileService   portainer.FileService
+	archiver      Archiver
+	scheduler     *scheduler.Scheduler
+	secretService portainer.SecretService
+}
+
+// NewService creates a new volume backup service
+func NewService(dataStore dataservices.DataStore, fileService portainer.FileService, archiver Archiver, scheduler *scheduler.Scheduler, secretService portainer.SecretService) *Service {
+	return &Service{
+		dataStore:     dataStore,
+		fileService:   fileService,
+		archiver:      archiver,
+		scheduler:     scheduler,
+		secretService: secretService,
+	}
+}
+
+// ArchivePath returns the path on disk of the archive of a backup
+func (service *Service) ArchivePath(backup *portainer.VolumeBackup) string {
+	return service.fileService.GetVolumeBackupPath(ArchiveName(backup))
+}
+
+// ArchiveName returns the file name of the archive of a backup
+func ArchiveName(backup *portainer.VolumeBackup) string {
+	name := strconv.Itoa(int(backup.ID)) + ".tar.gz"
+	if backup.Encrypted {
+		name += ".encrypted"
+	}
+
+	return name
+}
+
+// Volumes returns the names of the volumes to back up, the volumes of the stack when a stack name is provided
+func (service *Service) Volumes(endpoint *portainer.Endpoint, nodeName string, volumeNames []string, stackName string) ([]string, error) {
+	if stackName == "" {
+		return volumeNames, nil
+	}
+
+	stackVolumes, err := service.archiver.StackVolumes(endpoint, nodeName, stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stackVolumes) == 0 {
+		return nil, errors.Errorf("no volume found for the stack %s", stackName)
+	}
+
+	return stackVolumes, nil
+}
+
+// Create backs up a volume into an archive, the archive is encrypted when a password is provided
+func (service *Service) Create(endpoint *portainer.Endpoint, nodeName, volumeName string, scheduleID portainer.VolumeBackupScheduleID, password string) (*portainer.VolumeBackup, error) {
+	backup := &portainer.VolumeBackup{
+		EndpointID:   endpoint.ID,
+		NodeName:     nodeName,
+		VolumeName:   volumeName,
+		ScheduleID:   scheduleID,
+		CreationDate: time.Now().Unix(),
+		Encrypted:    password != "",
+	}
+
+	if err := service.dataStore.VolumeBackup().Create(backup); err != nil {
+		return nil, errors.Wrap(err, "unable to persist the volume backup")
+	}
+
+	size, err := service.writeArchive(backup, endpoint, password)
+	if err != nil {
+		service.remove(backup)
+		return nil, err
+	}
+
+	backup.Size = size
+
+	if err := service.dataStore.VolumeBackup().Update(backup.ID, backup); err != nil {
+		return nil, errors.Wrap(err, "unable to persist the volume backup")
+	}
+
+	return backup, nil
+}
+
+// Restore replaces the content of a volume by the archive of a backup, the volume is created when it does not exist.
+// The archive is opened before the volume is emptied so that a wrong password leaves the volume untouched
+func (service *Service) Restore(backup *portainer.VolumeBackup, endpoint *portainer.Endpoint, nodeName, volumeName, password string) error {
+	if backup.Encrypted && password == "" {
+		return ErrPasswordRequired
+	}
+
+	file, err := os.Open(service.ArchivePath(backup))
+	if err != nil {
+		return errors.Wrap(err, "unable to open the archive of the backup")
+	}
+	defer file.Close()
+
+	var input io.Reader = file
+	if backup.Encrypted {
+		input, err = crypto.AesDecrypt(file, []byte(password))
+		if err != nil {
+			return errors.Wrap(err, "unable to decrypt the archive of the backup")
+		}
+	}
+
+	zipReader, err := gzip.NewReader(input)
+	if err != nil {
+		if backup.Encrypted {
+			return ErrInvalidArchive
+		}
+
+		return errors.Wrap(err, "unable to read the archive of the backup")
+	}
+	defer zipReader.Close()
+
+	return service.archiver.ImportVolume(endpoint, nodeName, volumeName, zipReader)
+}
+
+// Delete removes a backup and its archive
+func (service *Service) Delete(backup *portainer.VolumeBackup) error {
+	if err := os.Remove(service.ArchivePath(backup)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "unable to remove the archive of the backup")
+	}
+
+	if err := service.dataStore.VolumeBackup().Delete(backup.ID); err != nil {
+		return errors.Wrap(err, "unable to remove the volume backup")
+	}
+
+	return nil
+}
+
+// writeArchive streams the content of the volume through the compression and the encryption to the archive
+// and returns the size of the archive
+func (service *Service) writeArchive(backup *portainer.VolumeBackup, endpoint *portainer.Endpoint, password string) (int64, error) {
+	path := service.ArchivePath(backup)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return 0, errors.Wrap(err, "unable to create the volume backup directory")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to create the archive of the backup")
+	}
+
+	tarReader, tarWriter := io.Pipe()
+	defer tarReader.Close()
+
+	go func() {
+		tarWriter.CloseWithError(service.archiver.ExportVolume(endpoint, backup.NodeName, backup.VolumeName, tarWriter))
+	}()
+
+	if password == "" {
+		err = archive.TarGzStream(file, tarReader)
+	} else {
+		zipReader, zipWriter := io.Pipe()
+		defer zipReader.Close()
+
+		go func() {
+			zipWriter.CloseWithError(archive.TarGzStream(zipWriter, tarReader))
+		}()
+
+		err = crypto.AesEncrypt(zipReader, file, []byte(password))
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return 0, errors.WithMessagef(err, "unable to back up the volume %s", backup.VolumeName)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to read the size of the archive")
+	}
+
+	return info.Size(), nil
+}
+
+func (service *Service) remove(backup *portainer.VolumeBackup) {
+	if err := service.Delete(backup); err != nil {
+		log.Warn().Err(err).Int("backup_id", int(backup.ID)).Msg("unable to clean up the failed volume backup")
+	}
+}
+
+// StartSchedules restarts the backup jobs of the stored schedules when Portainer starts, the new job identifiers are saved
+func (service *Service) StartSchedules() error {
+	schedules, err := service.dataStore.VolumeBackupSchedule().ReadAll()
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch volume backup schedules")
+	}
+
+	for i := range schedules {
+		schedule := &schedules[i]
+
+		if err := service.Schedule(schedule); err != nil {
+			return err
+		}
+
+		if err := service.dataStore.VolumeBackupSchedule().Update(schedule.ID, schedule); err != nil {
+			return errors.Wrap(err, "failed to update volume backup schedule job id")
+		}
+	}
+
+	return nil
+}
+
+// Schedule replaces the backup job of the schedule by a job running at its interval, the schedule is not saved
+func (service *Service) Schedule(schedule *portainer.VolumeBackupSchedule) error {
+	scheduleID := schedule.ID
+	jobID, err := service.scheduler.RescheduleJob(schedule.JobID, schedule.Interval, func() error {
+		return service.Run(scheduleID)
+	}, func(err error) bool {
+		// the job of a deleted schedule stops by itself
+		return service.dataStore.IsErrObjectNotFound(errors.Cause(err))
+	})
+
+	schedule.JobID = jobID
+
+	return errors.Wrap(err, "unable to parse the volume backup schedule interval")
+}
+
+// Unschedule stops the backup job of the schedule
+func (service *Service) Unschedule(schedule *portainer.VolumeBackupSchedule) {
+	if schedule.JobID == "" {
+		return
+	}
+
+	if err := service.scheduler.StopJob(schedule.JobID); err != nil {
+		log.Warn().Err(err).Int("schedule_id", int(schedule.ID)).Msg("unable to stop the volume backup job")
+	}
+
+	schedule.JobID = ""
+}
+
+// SetSchedulePassword stores the password of the schedule encrypted with the server key, the archives of the
+// schedule are not encrypted when the password is empty
+func (service *Service) SetSchedulePassword(schedule *portainer.VolumeBackupSchedule, password string) error {
+	if password == "" {
+		schedule.Password = ""
+		return nil
+	}
+
+	encrypted, err := service.secretService.Encrypt(password)
+	if err != nil {
+		return errors.Wrap(err, "unable to encrypt the password of the volume backup schedule")
+	}
+
+	schedule.Password = encrypted
+
+	return nil
+}
+
+// schedulePassword returns the decrypted password of the schedule
+func (service *Service) schedulePassword(schedule *portainer.VolumeBackupSchedule) (string, error) {
+	if schedule.Password == "" {
+		return "", nil
+	}
+
+	password, err := service.secretService.Decrypt(schedule.Password)
+
+	return password, errors.Wrap(err, "unable to decrypt the password of the volume backup schedule")
+}
+
+// Run backs up the volumes of a schedule and removes the backups of each volume exceeding the retention
+func (service *Service) Run(scheduleID portainer.VolumeBackupScheduleID) error {
+	schedule, err := service.dataStore.VolumeBackupSchedule().Read(scheduleID)
+	if err != nil {
+		return errors.Wrap(err, "unable to find the volume backup schedule")
+	}
+
+	runErr := service.run(schedule)
+
+	schedule.LastRunDate = time.Now().Unix()
+	schedule.LastRunError = ""
+	if runErr != nil {
+		schedule.LastRunError = runErr.Error()
+	}
+
+	if err := service.dataStore.VolumeBackupSchedule().Update(schedule.ID, schedule); err != nil {
+		return errors.Wrap(err, "unable to persist the volume backup schedule changes")
+	}
+
+	return runErr
+}
+
+func (service *Service) run(schedule *portainer.VolumeBackupSchedule) error {
+	endpoint, err := service.dataStore.Endpoint().Endpoint(schedule.EndpointID)
+	if err != nil {
+		return errors.WithMessage(err, "unable to find the environment of the volume backup schedule")
+	}
+
+	password, err := service.schedulePassword(schedule)
+	if err != nil {
+		return err
+	}
+
+	volumeNames, err := service.Volumes(endpoint, schedule.NodeName, schedule.VolumeNames, schedule.StackName)
+	if err != nil {
+		return err
+	}
+
+	failures := []string{}
+	for _, volumeName := range volumeNames {
+		if _, err := service.Create(endpoint, schedule.NodeName, volumeName, schedule.ID, password); err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+
+		if err := service.prune(schedule, volumeName); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// prune removes the oldest backups of a volume created by the schedule beyond its retention
+func (service *Service) prune(schedule *portainer.VolumeBackupSchedule, volumeName string) error {
+	if schedule.Retention <= 0 {
+		return nil
+	}
+
+	backups, err := service.dataStore.VolumeBackup().ReadAll()
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve the volume backups")
+	}
+
+	scheduled := []portainer.VolumeBackup{}
+	for _, backup := range backups {
+		if backup.ScheduleID == schedule.ID && backup.VolumeName == volumeName {
+			scheduled = append(scheduled, backup)
+		}
+	}
+
+	if len(scheduled) <= schedule.Retention {
+		return nil
+	}
+
+	sort.Slice(scheduled, func(i, j int) bool {
+		return scheduled[i].ID > scheduled[j].ID
+	})
+
+	for i := range scheduled[schedule.Retention:] {
+		backup := &scheduled[schedule.Retention+i]
+		if err := service.Delete(backup); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
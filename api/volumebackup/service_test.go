@@ -0,0 +1,213 @@
+package volumebackup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/archive"
+	"github.com/portainer/portainer/api/crypto"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/scheduler"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type testArchiver struct {
+	volumes map[string][]byte
+	stacks  map[string][]string
+}
+
+func (archiver *testArchiver) ExportVolume(endpoint *portainer.Endpoint, nodeName, volumeName string, output io.Writer) error {
+	content, ok := archiver.volumes[volumeName]
+	if !ok {
+		return errors.Errorf("unable to find the volume %s", volumeName)
+	}
+
+	_, err := output.Write(content)
+
+	return err
+}
+
+func (archiver *testArchiver) ImportVolume(endpoint *portainer.Endpoint, nodeName, volumeName string, input io.Reader) error {
+	content, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+
+	archiver.volumes[volumeName] = content
+
+	return nil
+}
+
+func (archiver *testArchiver) StackVolumes(endpoint *portainer.Endpoint, nodeName, stackName string) ([]string, error) {
+	return archiver.stacks[stackName], nil
+}
+
+func newTestService(t *testing.T) (*Service, *datastore.Store, *testArchiver) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	fileService, err := filesystem.NewService(t.TempDir(), "")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tarball, err := archive.TarFileInBuffer([]byte("content"), "volume/data", 0o600)
+	assert.NoError(t, err)
+
+	archiver := &testArchiver{
+		volumes: map[string][]byte{"app_data": tarball, "app_db": tarball},
+		stacks:  map[string][]string{"app": {"app_data", "app_db"}},
+	}
+
+	secretService, err := crypto.NewSecretService(make([]byte, crypto.SecretKeySize))
+	assert.NoError(t, err)
+
+	return NewService(store, fileService, archiver, scheduler.NewScheduler(ctx), secretService), store, archiver
+}
+
+func Test_CreateAndRestore(t *testing.T) {
+	is := assert.New(t)
+
+	service, store, archiver := newTestService(t)
+	endpoint := &portainer.Endpoint{ID: 1}
+
+	backup, err := service.Create(endpoint, "", "app_data", 0, "")
+	is.NoError(err)
+	is.False(backup.Encrypted)
+	is.Positive(backup.Size)
+
+	stored, err := store.VolumeBackup().Read(backup.ID)
+	is.NoError(err)
+	is.Equal(backup, stored)
+
+	err = service.Restore(backup, endpoint, "", "restored", "")
+	is.NoError(err)
+	is.Equal(archiver.volumes["app_data"], archiver.volumes["restored"])
+
+	err = service.Delete(backup)
+	is.NoError(err)
+
+	_, err = os.Stat(service.ArchivePath(backup))
+	is.True(os.IsNotExist(err))
+}
+
+func Test_CreateAndRestore_Encrypted(t *testing.T) {
+	is := assert.New(t)
+
+	service, _, archiver := newTestService(t)
+	endpoint := &portainer.Endpoint{ID: 1}
+
+	backup, err := service.Create(endpoint, "", "app_data", 0, "secret")
+	is.NoError(err)
+	is.True(backup.Encrypted)
+	is.Equal("1.tar.gz.encrypted", ArchiveName(backup))
+
+	err = service.Restore(backup, endpoint, "", "restored", "")
+	is.ErrorIs(err, ErrPasswordRequired)
+
+	err = service.Restore(backup, endpoint, "", "restored", "wrong")
+	is.ErrorIs(err, ErrInvalidArchive)
+
+	err = service.Restore(backup, endpoint, "", "restored", "secret")
+	is.NoError(err)
+	is.True(bytes.Equal(archiver.volumes["app_data"], archiver.volumes["restored"]))
+}
+
+func Test_Create_Failure(t *testing.T) {
+	is := assert.New(t)
+
+	service, store, _ := newTestService(t)
+
+	_, err := service.Create(&portainer.Endpoint{ID: 1}, "", "missing", 0, "")
+	is.Error(err)
+
+	backups, err := store.VolumeBackup().ReadAll()
+	is.NoError(err)
+	is.Empty(backups, "the failed backup is removed")
+}
+
+func Test_Run(t *testing.T) {
+	is := assert.New(t)
+
+	service, store, _ := newTestService(t)
+
+	err := store.Endpoint().Create(&portainer.Endpoint{ID: 1, Name: "local"})
+	is.NoError(err)
+
+	schedule := &portainer.VolumeBackupSchedule{
+		Name:       "nightly",
+		EndpointID: 1,
+		StackName:  "app",
+		Interval:   "24h",
+		Retention:  2,
+	}
+	err = store.VolumeBackupSchedule().Create(schedule)
+	is.NoError(err)
+
+	for i := 0; i < 3; i++ {
+		err = service.Run(schedule.ID)
+		is.NoError(err)
+	}
+
+	backups, err := store.VolumeBackup().ReadAll()
+	is.NoError(err)
+	is.Len(backups, 4, "two backups are kept for each volume of the stack")
+
+	for _, backup := range backups {
+		is.Greater(int(backup.ID), 2, "the oldest backups are removed")
+	}
+
+	schedule, err = store.VolumeBackupSchedule().Read(schedule.ID)
+	is.NoError(err)
+	is.NotZero(schedule.LastRunDate)
+	is.Empty(schedule.LastRunError)
+
+	schedule.StackName = "unknown"
+	err = store.VolumeBackupSchedule().Update(schedule.ID, schedule)
+	is.NoError(err)
+
+	err = service.Run(schedule.ID)
+	is.Error(err)
+
+	schedule, err = store.VolumeBackupSchedule().Read(schedule.ID)
+	is.NoError(err)
+	is.Equal("no volume found for the stack unknown", schedule.LastRunError)
+}
+
+func Test_Run_Encrypted(t *testing.T) {
+	is := assert.New(t)
+
+	service, store, _ := newTestService(t)
+
+	err := store.Endpoint().Create(&portainer.Endpoint{ID: 1, Name: "local"})
+	is.NoError(err)
+
+	schedule := &portainer.VolumeBackupSchedule{Name: "nightly", EndpointID: 1, VolumeNames: []string{"app_data"}, Interval: "24h"}
+	is.NoError(service.SetSchedulePassword(schedule, "secret"))
+	is.NotEmpty(schedule.Password)
+	is.NotEqual("secret", schedule.Password, "the password is stored encrypted")
+
+	err = store.VolumeBackupSchedule().Create(schedule)
+	is.NoError(err)
+
+	err = service.Run(schedule.ID)
+	is.NoError(err)
+
+	backups, err := store.VolumeBackup().ReadAll()
+	is.NoError(err)
+	if is.Len(backups, 1) {
+		is.True(backups[0].Encrypted)
+		is.ErrorIs(service.Restore(&backups[0], &portainer.Endpoint{ID: 1}, "", "restored", ""), ErrPasswordRequired)
+		is.NoError(service.Restore(&backups[0], &portainer.Endpoint{ID: 1}, "", "restored", "secret"), "the archives are encrypted with the decrypted password")
+	}
+
+	is.NoError(service.SetSchedulePassword(schedule, ""))
+	is.Empty(schedule.Password)
+}
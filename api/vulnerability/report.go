@@ -0,0 +1,189 @@
+package vulnerability
+
+import (
+	"encoding/json"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/pkg/errors"
+)
+
+// Report represents the vulnerabilities found by a scanner in an image
+type Report struct {
+	// Digest of the scanned image
+	Digest          string
+	Vulnerabilities []portainer.Vulnerability
+}
+
+type trivyReport struct {
+	ArtifactName string `json:"ArtifactName"`
+	Metadata     struct {
+		ImageID     string   `json:"ImageID"`
+		RepoDigests []string `json:"RepoDigests"`
+	} `json:"Metadata"`
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+			Title            string `json:"Title"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID          string `json:"id"`
+			Severity    string `json:"severity"`
+			Description string `json:"description"`
+			Fix         struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+	Source struct {
+		Target struct {
+			ImageID        string   `json:"imageID"`
+			ManifestDigest string   `json:"manifestDigest"`
+			RepoDigests    []string `json:"repoDigests"`
+		} `json:"target"`
+	} `json:"source"`
+}
+
+// ParseReport parses the JSON report of a scanner
+func ParseReport(scanner portainer.VulnerabilityScannerType, data []byte) (*Report, error) {
+	switch scanner {
+	case portainer.VulnerabilityScannerTrivy, "":
+		return parseTrivyReport(data)
+	case portainer.VulnerabilityScannerGrype:
+		return parseGrypeReport(data)
+	}
+
+	return nil, errors.Errorf("unsupported vulnerability scanner: %s", scanner)
+}
+
+func parseTrivyReport(data []byte) (*Report, error) {
+	var report trivyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, errors.Wrap(err, "unable to parse the Trivy report")
+	}
+
+	digest := repositoryDigest(report.Metadata.RepoDigests, report.Metadata.ImageID)
+	if digest == "" {
+		return nil, errors.New("the Trivy report does not contain the digest of the image")
+	}
+
+	vulnerabilities := []portainer.Vulnerability{}
+	for _, result := range report.Results {
+		for _, vulnerability := range result.Vulnerabilities {
+			vulnerabilities = append(vulnerabilities, portainer.Vulnerability{
+				ID:               vulnerability.VulnerabilityID,
+				Package:          vulnerability.PkgName,
+				InstalledVersion: vulnerability.InstalledVersion,
+				FixedVersion:     vulnerability.FixedVersion,
+				Severity:         normalizeSeverity(vulnerability.Severity),
+				Title:            vulnerability.Title,
+			})
+		}
+	}
+
+	return &Report{Digest: digest, Vulnerabilities: vulnerabilities}, nil
+}
+
+func parseGrypeReport(data []byte) (*Report, error) {
+	var report grypeReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, errors.Wrap(err, "unable to parse the Grype report")
+	}
+
+	target := report.Source.Target
+
+	digest := repositoryDigest(target.RepoDigests, target.ManifestDigest)
+	if digest == "" {
+		digest = target.ImageID
+	}
+
+	if digest == "" {
+		return nil, errors.New("the Grype report does not contain the digest of the image")
+	}
+
+	vulnerabilities := []portainer.Vulnerability{}
+	for _, match := range report.Matches {
+		vulnerabilities = append(vulnerabilities, portainer.Vulnerability{
+			ID:               match.Vulnerability.ID,
+			Package:          match.Artifact.Name,
+			InstalledVersion: match.Artifact.Version,
+			FixedVersion:     strings.Join(match.Vulnerability.Fix.Versions, ", "),
+			Severity:         normalizeSeverity(match.Vulnerability.Severity),
+			Title:            match.Vulnerability.Description,
+		})
+	}
+
+	return &Report{Digest: digest, Vulnerabilities: vulnerabilities}, nil
+}
+
+// repositoryDigest returns the digest of the first repository digest, name@sha256:..., or the fallback when
+// the image was not pulled from a registry
+func repositoryDigest(repoDigests []string, fallback string) string {
+	for _, repoDigest := range repoDigests {
+		if _, digest, found := strings.Cut(repoDigest, "@"); found && digest != "" {
+			return digest
+		}
+	}
+
+	return fallback
+}
+
+func normalizeSeverity(severity string) portainer.VulnerabilitySeverity {
+	switch strings.ToUpper(severity) {
+	case "CRITICAL":
+		return portainer.VulnerabilitySeverityCritical
+	case "HIGH":
+		return portainer.VulnerabilitySeverityHigh
+	case "MEDIUM":
+		return portainer.VulnerabilitySeverityMedium
+	case "LOW", "NEGLIGIBLE":
+		return portainer.VulnerabilitySeverityLow
+	}
+
+	return portainer.VulnerabilitySeverityUnknown
+}
+
+// Summarize counts the vulnerabilities per severity
+func Summarize(vulnerabilities []portainer.Vulnerability) portainer.VulnerabilitySummary {
+	summary := portainer.VulnerabilitySummary{}
+
+	for _, vulnerability := range vulnerabilities {
+		switch vulnerability.Severity {
+		case portainer.VulnerabilitySeverityCritical:
+			summary.Critical++
+		case portainer.VulnerabilitySeverityHigh:
+			summary.High++
+		case portainer.VulnerabilitySeverityMedium:
+			summary.Medium++
+		case portainer.VulnerabilitySeverityLow:
+			summary.Low++
+		default:
+			summary.Unknown++
+		}
+	}
+
+	return summary
+}
+
+// addSummary adds the counts of a summary to another one
+func addSummary(summary *portainer.VulnerabilitySummary, other portainer.VulnerabilitySummary) {
+	summary.Critical += other.Critical
+	summary.High += other.High
+	summary.Medium += other.Medium
+	summary.Low += other.Low
+	summary.Unknown += other.Unknown
+}
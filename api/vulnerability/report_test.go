@@ -0,0 +1,95 @@
+package vulnerability
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const trivyReportJSON = `{
+	"SchemaVersion": 2,
+	"ArtifactName": "nginx:1.25",
+	"ArtifactType": "container_image",
+	"Metadata": {
+		"ImageID": "sha256:a8758716bb6aa4d90071160d27028fe4eaee7ce8166221a97d30440c8eac2be6",
+		"RepoDigests": ["nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"]
+	},
+	"Results": [
+		{
+			"Target": "nginx:1.25 (debian 12.2)",
+			"Class": "os-pkgs",
+			"Vulnerabilities": [
+				{"VulnerabilityID": "CVE-2023-44487", "PkgName": "libnghttp2-14", "InstalledVersion": "1.52.0-1", "FixedVersion": "1.52.0-1+deb12u1", "Severity": "HIGH", "Title": "HTTP/2 Rapid Reset"},
+				{"VulnerabilityID": "CVE-2023-38545", "PkgName": "curl", "InstalledVersion": "7.88.1-10", "Severity": "CRITICAL"}
+			]
+		},
+		{
+			"Target": "usr/local/bin/app",
+			"Class": "lang-pkgs"
+		}
+	]
+}`
+
+const grypeReportJSON = `{
+	"matches": [
+		{
+			"vulnerability": {"id": "CVE-2023-38545", "severity": "Critical", "description": "SOCKS5 heap buffer overflow", "fix": {"versions": ["7.88.1-10+deb12u4"], "state": "fixed"}},
+			"artifact": {"name": "curl", "version": "7.88.1-10"}
+		},
+		{
+			"vulnerability": {"id": "CVE-2011-3374", "severity": "Negligible", "fix": {"versions": [], "state": "not-fixed"}},
+			"artifact": {"name": "apt", "version": "2.6.1"}
+		}
+	],
+	"source": {
+		"type": "image",
+		"target": {
+			"userInput": "nginx:1.25",
+			"imageID": "sha256:a8758716bb6aa4d90071160d27028fe4eaee7ce8166221a97d30440c8eac2be6",
+			"manifestDigest": "sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31",
+			"repoDigests": []
+		}
+	}
+}`
+
+func Test_ParseReport(t *testing.T) {
+	is := assert.New(t)
+
+	report, err := ParseReport(portainer.VulnerabilityScannerTrivy, []byte(trivyReportJSON))
+	is.NoError(err)
+	is.Equal("sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31", report.Digest)
+	is.Equal([]portainer.Vulnerability{
+		{ID: "CVE-2023-44487", Package: "libnghttp2-14", InstalledVersion: "1.52.0-1", FixedVersion: "1.52.0-1+deb12u1", Severity: portainer.VulnerabilitySeverityHigh, Title: "HTTP/2 Rapid Reset"},
+		{ID: "CVE-2023-38545", Package: "curl", InstalledVersion: "7.88.1-10", Severity: portainer.VulnerabilitySeverityCritical},
+	}, report.Vulnerabilities)
+
+	report, err = ParseReport(portainer.VulnerabilityScannerGrype, []byte(grypeReportJSON))
+	is.NoError(err)
+	is.Equal("sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31", report.Digest)
+	is.Equal([]portainer.Vulnerability{
+		{ID: "CVE-2023-38545", Package: "curl", InstalledVersion: "7.88.1-10", FixedVersion: "7.88.1-10+deb12u4", Severity: portainer.VulnerabilitySeverityCritical, Title: "SOCKS5 heap buffer overflow"},
+		{ID: "CVE-2011-3374", Package: "apt", InstalledVersion: "2.6.1", Severity: portainer.VulnerabilitySeverityLow},
+	}, report.Vulnerabilities)
+
+	_, err = ParseReport(portainer.VulnerabilityScannerTrivy, []byte(`{"Results": []}`))
+	is.Error(err, "a report without digest should be refused")
+
+	_, err = ParseReport("clair", []byte(trivyReportJSON))
+	is.Error(err)
+}
+
+func Test_Summarize(t *testing.T) {
+	is := assert.New(t)
+
+	summary := Summarize([]portainer.Vulnerability{
+		{Severity: portainer.VulnerabilitySeverityCritical},
+		{Severity: portainer.VulnerabilitySeverityHigh},
+		{Severity: portainer.VulnerabilitySeverityHigh},
+		{Severity: portainer.VulnerabilitySeverityLow},
+		{Severity: portainer.VulnerabilitySeverityUnknown},
+	})
+
+	is.Equal(portainer.VulnerabilitySummary{Critical: 1, High: 2, Low: 1, Unknown: 1}, summary)
+}
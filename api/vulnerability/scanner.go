@@ -0,0 +1,186 @@
+package vulnerability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/pkg/errors"
+)
+
+const serverScanTimeout = 10 * time.Minute
+
+// Scanner scans a container image for known vulnerabilities, the credentials authenticate the pull of the image when set
+type Scanner interface {
+	Scan(ctx context.Context, image string, credentials *RegistryCredentials) (*Report, error)
+}
+
+// RegistryCredentials are the credentials of the registry hosting the scanned image
+type RegistryCredentials struct {
+	// Registry is the host of the registry, like docker.io
+	Registry string
+	Username string
+	Password string
+}
+
+// NewScanner creates the scanner matching the settings, a scanner server is called when its URL is set,
+// otherwise the scanner binary of the binary folder is run
+func NewScanner(settings portainer.VulnerabilityScanningSettings, binaryPath string) (Scanner, error) {
+	scannerType := settings.Scanner
+	if scannerType == "" {
+		scannerType = portainer.VulnerabilityScannerTrivy
+	}
+
+	if scannerType != portainer.VulnerabilityScannerTrivy && scannerType != portainer.VulnerabilityScannerGrype {
+		return nil, errors.Errorf("unsupported vulnerability scanner: %s", scannerType)
+	}
+
+	if settings.ServerURL != "" {
+		return NewServerScanner(scannerType, settings.ServerURL), nil
+	}
+
+	return NewBinaryScanner(scannerType, binaryPath)
+}
+
+// BinaryScanner runs a local scanner binary
+type BinaryScanner struct {
+	scanner portainer.VulnerabilityScannerType
+	program string
+}
+
+// NewBinaryScanner creates a scanner running the trivy or grype binary of the binary folder
+func NewBinaryScanner(scanner portainer.VulnerabilityScannerType, binaryPath string) (*BinaryScanner, error) {
+	program := path.Join(binaryPath, string(scanner))
+	if runtime.GOOS == "windows" {
+		program += ".exe"
+	}
+
+	if _, err := os.Stat(program); err != nil {
+		return nil, errors.Wrapf(err, "the %s binary is missing from the binary folder", scanner)
+	}
+
+	return &BinaryScanner{scanner: scanner, program: program}, nil
+}
+
+// Scan runs the scanner binary on the image and parses its JSON report, the credentials are passed through the environment
+// of the scanner so that they do not appear in the arguments of the process
+func (scanner *BinaryScanner) Scan(ctx context.Context, image string, credentials *RegistryCredentials) (*Report, error) {
+	// the image is passed after "--" so that a reference starting with a dash is not parsed as a flag
+	var args []string
+	switch scanner.scanner {
+	case portainer.VulnerabilityScannerTrivy:
+		args = []string{"image", "--quiet", "--format", "json", "--", image}
+	case portainer.VulnerabilityScannerGrype:
+		args = []string{"--quiet", "--output", "json", "--", image}
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, scanner.program, args...)
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), scanner.credentialsEnv(credentials)...)
+
+	output, err := cmd.Output()
+	if err != nil {
+		if stderr.Len() > 0 {
+			return nil, errors.Errorf("unable to scan the image %s: %s", image, strings.TrimSpace(stderr.String()))
+		}
+
+		return nil, errors.Wrapf(err, "unable to scan the image %s", image)
+	}
+
+	return ParseReport(scanner.scanner, output)
+}
+
+func (scanner *BinaryScanner) credentialsEnv(credentials *RegistryCredentials) []string {
+	if credentials == nil {
+		return nil
+	}
+
+	switch scanner.scanner {
+	case portainer.VulnerabilityScannerTrivy:
+		return []string{"TRIVY_USERNAME=" + credentials.Username, "TRIVY_PASSWORD=" + credentials.Password}
+	case portainer.VulnerabilityScannerGrype:
+		return []string{
+			"GRYPE_REGISTRY_AUTH_AUTHORITY=" + credentials.Registry,
+			"GRYPE_REGISTRY_AUTH_USERNAME=" + credentials.Username,
+			"GRYPE_REGISTRY_AUTH_PASSWORD=" + credentials.Password,
+		}
+	}
+
+	return nil
+}
+
+// ServerScanner calls a scanner server, the server scans the image of the request and responds with
+// the JSON report of the scanner
+type ServerScanner struct {
+	scanner portainer.VulnerabilityScannerType
+	url     string
+	client  *http.Client
+}
+
+type serverScanRequest struct {
+	Image string `json:"Image"`
+	// Registry, Username and Password are the credentials of the registry hosting the image
+	Registry string `json:"Registry,omitempty"`
+	Username string `json:"Username,omitempty"`
+	Password string `json:"Password,omitempty"`
+}
+
+// NewServerScanner creates a scanner calling the scan endpoint, POST <url>/scan, of a scanner server
+func NewServerScanner(scanner portainer.VulnerabilityScannerType, url string) *ServerScanner {
+	return &ServerScanner{
+		scanner: scanner,
+		url:     strings.TrimSuffix(url, "/") + "/scan",
+		client:  &http.Client{Timeout: serverScanTimeout},
+	}
+}
+
+// Scan requests the scan of the image to the server and parses the report it returns
+func (scanner *ServerScanner) Scan(ctx context.Context, image string, credentials *RegistryCredentials) (*Report, error) {
+	payload := serverScanRequest{Image: image}
+	if credentials != nil {
+		payload.Registry = credentials.Registry
+		payload.Username = credentials.Username
+		payload.Password = credentials.Password
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, scanner.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the scan request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := scanner.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to reach the scanner server to scan the image %s", image)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the response of the scanner server")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the scanner server failed to scan the image %s with status %d: %s", image, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	return ParseReport(scanner.scanner, data)
+}
@@ -0,0 +1,81 @@
+package vulnerability
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ServerScanner(t *testing.T) {
+	is := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload serverScanRequest
+		if r.Method != http.MethodPost || r.URL.Path != "/scan" || json.NewDecoder(r.Body).Decode(&payload) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if payload.Image != "nginx:1.25" {
+			http.Error(w, "manifest unknown", http.StatusNotFound)
+			return
+		}
+
+		if payload.Registry != "docker.io" || payload.Username != "user" || payload.Password != "password" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte(trivyReportJSON))
+	}))
+	defer server.Close()
+
+	scanner, err := NewScanner(portainer.VulnerabilityScanningSettings{Enabled: true, ServerURL: server.URL + "/"}, t.TempDir())
+	is.NoError(err)
+
+	credentials := &RegistryCredentials{Registry: "docker.io", Username: "user", Password: "password"}
+
+	report, err := scanner.Scan(context.Background(), "nginx:1.25", credentials)
+	is.NoError(err)
+	is.Equal("sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31", report.Digest)
+	is.Len(report.Vulnerabilities, 2)
+
+	_, err = scanner.Scan(context.Background(), "nginx:missing", credentials)
+	is.ErrorContains(err, "manifest unknown")
+
+	_, err = scanner.Scan(context.Background(), "nginx:1.25", nil)
+	is.ErrorContains(err, "unauthorized")
+}
+
+func Test_BinaryScanner_credentialsEnv(t *testing.T) {
+	is := assert.New(t)
+
+	credentials := &RegistryCredentials{Registry: "registry.local", Username: "user", Password: "password"}
+
+	trivy := &BinaryScanner{scanner: portainer.VulnerabilityScannerTrivy}
+	is.Equal([]string{"TRIVY_USERNAME=user", "TRIVY_PASSWORD=password"}, trivy.credentialsEnv(credentials))
+	is.Empty(trivy.credentialsEnv(nil))
+
+	grype := &BinaryScanner{scanner: portainer.VulnerabilityScannerGrype}
+	is.Equal([]string{
+		"GRYPE_REGISTRY_AUTH_AUTHORITY=registry.local",
+		"GRYPE_REGISTRY_AUTH_USERNAME=user",
+		"GRYPE_REGISTRY_AUTH_PASSWORD=password",
+	}, grype.credentialsEnv(credentials))
+}
+
+func Test_NewScanner(t *testing.T) {
+	is := assert.New(t)
+
+	_, err := NewScanner(portainer.VulnerabilityScanningSettings{Scanner: portainer.VulnerabilityScannerGrype}, t.TempDir())
+	is.Error(err, "the scanner binary is missing from the binary folder")
+
+	_, err = NewScanner(portainer.VulnerabilityScanningSettings{Scanner: "clair", ServerURL: "http://scanner"}, t.TempDir())
+	is.Error(err)
+}
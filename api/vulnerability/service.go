@@ -0,0 +1,465 @@
+package vulnerability
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/docker/images"
+	"github.com/portainer/portainer/api/internal/endpointutils"
+	"github.com/portainer/portainer/api/internal/registryutils"
+	"github.com/portainer/portainer/api/kubernetes/cli"
+	"github.com/portainer/portainer/api/stacks/deployments"
+	"github.com/portainer/portainer/api/stacks/stackplan"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// scanMaxAge is the duration during which the scan of an image is reused by the deployment policy
+	scanMaxAge = 24 * time.Hour
+	// policyScanTimeout bounds the scans of the images run by the deployment policy, the deployment waits for them
+	policyScanTimeout = 5 * time.Minute
+)
+
+var (
+	// ErrScanningDisabled is returned when a scan is requested while the vulnerability scanning is disabled
+	ErrScanningDisabled = errors.New("vulnerability scanning is disabled")
+	// ErrCriticalVulnerabilities is returned when the deployment of a stack is refused by the policy
+	ErrCriticalVulnerabilities = errors.New("the stack references images with critical vulnerabilities")
+)
+
+// ImageSummary represents the vulnerability summary of an image, the image was never scanned when its scan identifier is 0
+type ImageSummary struct {
+	Image    string                         `json:"Image" example:"nginx:1.25"`
+	ScanID   portainer.ImageScanID          `json:"ScanId,omitempty" example:"1"`
+	Digest   string                         `json:"Digest,omitempty" example:"sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"`
+	ScanDate int64                          `json:"ScanDate,omitempty" example:"1587399600"`
+	Summary  portainer.VulnerabilitySummary `json:"Summary"`
+	// Error of the scan of the image
+	Error string `json:"Error,omitempty"`
+}
+
+// StackSummary represents the vulnerability summary of the images of a stack
+type StackSummary struct {
+	StackID portainer.StackID              `json:"StackId" example:"1"`
+	Name    string                         `json:"Name" example:"myStack"`
+	Summary portainer.VulnerabilitySummary `json:"Summary"`
+	Images  []ImageSummary                 `json:"Images"`
+	// Error returned when the images of the stack cannot be resolved from its files
+	Error string `json:"Error,omitempty"`
+}
+
+// EndpointSummary represents the vulnerability summary of an environment, the summary of the environment
+// counts the vulnerabilities of each distinct image once
+type EndpointSummary struct {
+	EndpointID portainer.EndpointID           `json:"EndpointId" example:"1"`
+	Summary    portainer.VulnerabilitySummary `json:"Summary"`
+	// Images of the running containers
+	Containers []ImageSummary `json:"Containers"`
+	Stacks     []StackSummary `json:"Stacks"`
+}
+
+// Service scans the images of the stacks and of the running containers and enforces the deployment policy
+type Service struct {
+	dataStore               dataservices.DataStore
+	fileService             portainer.FileService
+	composeStackManager     portainer.ComposeStackManager
+	kubernetesDeployer      portainer.KubernetesDeployer
+	kubernetesClientFactory *cli.ClientFactory
+	newScanner              func(settings portainer.VulnerabilityScanningSettings) (Scanner, error)
+	policyScanTimeout       time.Duration
+	mu                      sync.Mutex
+}
+
+// NewService creates a new vulnerability scanning service
+func NewService(dataStore dataservices.DataStore, fileService portainer.FileService, composeStackManager portainer.ComposeStackManager,
+	kubernetesDeployer portainer.KubernetesDeployer, kubernetesClientFactory *cli.ClientFactory) *Service {
+	service := &Service{
+		dataStore:               dataStore,
+		fileService:             fileService,
+		composeStackManager:     composeStackManager,
+		kubernetesDeployer:      kubernetesDeployer,
+		kubernetesClientFactory: kubernetesClientFactory,
+		policyScanTimeout:       policyScanTimeout,
+	}
+
+	service.newScanner = func(settings portainer.VulnerabilityScanningSettings) (Scanner, error) {
+		return NewScanner(settings, service.fileService.GetBinaryFolder())
+	}
+
+	return service
+}
+
+// ScanImage scans the image and stores the result under the digest of the image, the image is pulled with the credentials
+// of the matching registry
+func (service *Service) ScanImage(ctx context.Context, image string) (*portainer.ImageScan, error) {
+	settings, err := service.settings()
+	if err != nil {
+		return nil, err
+	}
+
+	if !settings.Enabled {
+		return nil, ErrScanningDisabled
+	}
+
+	return service.scanImage(ctx, settings, image, nil)
+}
+
+// EndpointSummary returns the vulnerability summaries of the images of the running containers and of the stacks of the
+// environment. The images are scanned first when scan is true, otherwise the stored scans are used
+func (service *Service) EndpointSummary(ctx context.Context, endpoint *portainer.Endpoint, scan bool) (*EndpointSummary, error) {
+	settings, err := service.settings()
+	if err != nil {
+		return nil, err
+	}
+
+	if scan && !settings.Enabled {
+		return nil, ErrScanningDisabled
+	}
+
+	containerImages, err := service.runningImages(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	stacks, err := service.dataStore.Stack().ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve the stacks")
+	}
+
+	summary := &EndpointSummary{
+		EndpointID: endpoint.ID,
+		Containers: []ImageSummary{},
+		Stacks:     []StackSummary{},
+	}
+
+	stackImages := make(map[portainer.StackID][]string)
+	for _, stack := range stacks {
+		if stack.EndpointID != endpoint.ID {
+			continue
+		}
+
+		stackSummary := StackSummary{StackID: stack.ID, Name: stack.Name, Images: []ImageSummary{}}
+
+		stackImages[stack.ID], err = deployments.StackImages(ctx, &stack, service.composeStackManager, service.kubernetesDeployer)
+		if err != nil {
+			stackSummary.Error = err.Error()
+		}
+
+		summary.Stacks = append(summary.Stacks, stackSummary)
+	}
+
+	scans, err := service.dataStore.ImageScan().ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve the image scans")
+	}
+
+	imageSummaries := make(map[string]ImageSummary)
+	imageSummary := func(image string) ImageSummary {
+		if result, ok := imageSummaries[image]; ok {
+			return result
+		}
+
+		result := ImageSummary{Image: image}
+
+		imageScan := latestScan(scans, image)
+		if scan {
+			var scanErr error
+			imageScan, scanErr = service.scanImage(ctx, settings, image, endpoint)
+			if scanErr != nil {
+				result.Error = scanErr.Error()
+			}
+		}
+
+		if imageScan != nil {
+			result.ScanID = imageScan.ID
+			result.Digest = imageScan.Digest
+			result.ScanDate = imageScan.ScanDate
+			result.Summary = imageScan.Summary
+		}
+
+		imageSummaries[image] = result
+
+		return result
+	}
+
+	for _, image := range containerImages {
+		summary.Containers = append(summary.Containers, imageSummary(image))
+	}
+
+	for i, stackSummary := range summary.Stacks {
+		for _, image := range stackImages[stackSummary.StackID] {
+			result := imageSummary(image)
+			stackSummary.Images = append(stackSummary.Images, result)
+			addSummary(&stackSummary.Summary, result.Summary)
+		}
+
+		summary.Stacks[i] = stackSummary
+	}
+
+	counted := make(map[portainer.ImageScanID]bool)
+	for _, result := range imageSummaries {
+		if result.ScanID == 0 || counted[result.ScanID] {
+			continue
+		}
+
+		counted[result.ScanID] = true
+		addSummary(&summary.Summary, result.Summary)
+	}
+
+	return summary, nil
+}
+
+// CheckStack refuses the deployment of the stack when the policy is enabled and one of its images has a critical
+// vulnerability. The recent scans of the images are reused and the images built by the stack are not scanned, the
+// deployment is also refused when the images cannot be resolved or scanned in time
+func (service *Service) CheckStack(ctx context.Context, stack *portainer.Stack, endpoint *portainer.Endpoint) error {
+	settings, err := service.settings()
+	if err != nil {
+		return err
+	}
+
+	if !settings.Enabled || !settings.BlockCriticalDeployments {
+		return nil
+	}
+
+	stackImages, err := deployments.StackImages(ctx, stack, service.composeStackManager, service.kubernetesDeployer)
+	if err != nil {
+		return errors.Wrap(err, "unable to resolve the images of the stack for the vulnerability policy")
+	}
+
+	scans, err := service.dataStore.ImageScan().ReadAll()
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve the image scans")
+	}
+
+	scanCtx, cancel := context.WithTimeout(ctx, service.policyScanTimeout)
+	defer cancel()
+
+	blocked := []string{}
+	for _, image := range stackImages {
+		imageScan := latestScan(scans, image)
+		if imageScan == nil || time.Since(time.Unix(imageScan.ScanDate, 0)) > scanMaxAge {
+			imageScan, err = service.scanImage(scanCtx, settings, image, endpoint)
+			if errors.Is(scanCtx.Err(), context.DeadlineExceeded) {
+				return errors.Errorf("the scan of the image %s for the vulnerability policy did not complete within %s, scan the images of the environment before deploying the stack", image, service.policyScanTimeout)
+			} else if err != nil {
+				return errors.Wrapf(err, "unable to scan the image %s for the vulnerability policy", image)
+			}
+		}
+
+		if imageScan.Summary.Critical > 0 {
+			blocked = append(blocked, fmt.Sprintf("%s (%d critical)", image, imageScan.Summary.Critical))
+		}
+	}
+
+	if len(blocked) > 0 {
+		return fmt.Errorf("%w: %s", ErrCriticalVulnerabilities, strings.Join(blocked, ", "))
+	}
+
+	return nil
+}
+
+func (service *Service) settings() (portainer.VulnerabilityScanningSettings, error) {
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		return portainer.VulnerabilityScanningSettings{}, errors.Wrap(err, "unable to retrieve the settings")
+	}
+
+	return settings.VulnerabilityScanning, nil
+}
+
+// scanImage scans the image with the credentials of the matching registry, only the registries accessible from the
+// environment are used when an environment is given
+func (service *Service) scanImage(ctx context.Context, settings portainer.VulnerabilityScanningSettings, image string, endpoint *portainer.Endpoint) (*portainer.ImageScan, error) {
+	scanner, err := service.newScanner(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := service.registryCredentials(image, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := scanner.Scan(ctx, image, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	scannerType := settings.Scanner
+	if scannerType == "" {
+		scannerType = portainer.VulnerabilityScannerTrivy
+	}
+
+	return service.saveReport(image, scannerType, report)
+}
+
+// saveReport stores the report under the digest of the image, the reference is moved from the scan of its previous
+// digest when the reference now resolves to another digest
+func (service *Service) saveReport(image string, scannerType portainer.VulnerabilityScannerType, report *Report) (*portainer.ImageScan, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	reference := stackplan.NormalizeImage(image)
+
+	scans, err := service.dataStore.ImageScan().ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve the image scans")
+	}
+
+	var imageScan *portainer.ImageScan
+	for i := range scans {
+		if scans[i].Digest == report.Digest {
+			imageScan = &scans[i]
+			continue
+		}
+
+		if index := slices.Index(scans[i].References, reference); index != -1 {
+			scans[i].References = slices.Delete(scans[i].References, index, index+1)
+
+			if err := service.dataStore.ImageScan().Update(scans[i].ID, &scans[i]); err != nil {
+				return nil, errors.Wrap(err, "unable to update the image scan")
+			}
+		}
+	}
+
+	if imageScan == nil {
+		imageScan = &portainer.ImageScan{Digest: report.Digest, References: []string{}}
+	}
+
+	if !slices.Contains(imageScan.References, reference) {
+		imageScan.References = append(imageScan.References, reference)
+		sort.Strings(imageScan.References)
+	}
+
+	imageScan.Scanner = scannerType
+	imageScan.ScanDate = time.Now().Unix()
+	imageScan.Vulnerabilities = report.Vulnerabilities
+	imageScan.Summary = Summarize(report.Vulnerabilities)
+
+	if imageScan.ID == 0 {
+		err = service.dataStore.ImageScan().Create(imageScan)
+	} else {
+		err = service.dataStore.ImageScan().Update(imageScan.ID, imageScan)
+	}
+
+	return imageScan, errors.Wrap(err, "unable to persist the image scan")
+}
+
+// registryCredentials returns the credentials of the registry hosting the image, nil when no registry with an
+// authentication matches the host of the image
+func (service *Service) registryCredentials(image string, endpoint *portainer.Endpoint) (*RegistryCredentials, error) {
+	parsed, err := images.ParseImage(images.ParseImageOptions{Name: image})
+	if err != nil {
+		return nil, nil
+	}
+
+	registries, err := service.dataStore.Registry().ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve the registries")
+	}
+
+	for i := range registries {
+		registry := &registries[i]
+		if !registry.Authentication || !strings.EqualFold(registryHost(registry), parsed.Domain) {
+			continue
+		}
+
+		if endpoint != nil {
+			if _, ok := registry.RegistryAccesses[endpoint.ID]; !ok {
+				continue
+			}
+		}
+
+		if err := registryutils.EnsureRegTokenValid(service.dataStore, registry); err != nil {
+			return nil, errors.Wrapf(err, "unable to refresh the token of the registry %s", registry.Name)
+		}
+
+		username, password, err := registryutils.GetRegEffectiveCredential(registry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to retrieve the credentials of the registry %s", registry.Name)
+		}
+
+		return &RegistryCredentials{Registry: parsed.Domain, Username: username, Password: password}, nil
+	}
+
+	return nil, nil
+}
+
+// registryHost returns the host of the registry, the URL of a registry can contain a scheme and a path
+func registryHost(registry *portainer.Registry) string {
+	if registry.Type == portainer.DockerHubRegistry {
+		return "docker.io"
+	}
+
+	host := registry.URL
+	if _, after, found := strings.Cut(host, "://"); found {
+		host = after
+	}
+
+	host, _, _ = strings.Cut(host, "/")
+
+	return host
+}
+
+// runningImages returns the images of the running containers of the environment, they are read from the snapshot of
+// the Docker environments and listed from the cluster of the Kubernetes environments
+func (service *Service) runningImages(ctx context.Context, endpoint *portainer.Endpoint) ([]string, error) {
+	if endpointutils.IsKubernetesEndpoint(endpoint) {
+		kubeClient, err := service.kubernetesClientFactory.GetKubeClient(endpoint)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create the Kubernetes client")
+		}
+
+		return kubeClient.GetRunningContainerImages(ctx)
+	}
+
+	snapshot, err := service.dataStore.Snapshot().Read(endpoint.ID)
+	if service.dataStore.IsErrObjectNotFound(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve the environment snapshot")
+	}
+
+	runningImages := []string{}
+	if snapshot.Docker == nil {
+		return runningImages, nil
+	}
+
+	for _, container := range snapshot.Docker.SnapshotRaw.Containers {
+		// the containers of untagged images only reference the image identifier
+		if container.State != "running" || strings.HasPrefix(container.Image, "sha256:") {
+			continue
+		}
+
+		if !slices.Contains(runningImages, container.Image) {
+			runningImages = append(runningImages, container.Image)
+		}
+	}
+
+	sort.Strings(runningImages)
+
+	return runningImages, nil
+}
+
+// latestScan returns the most recent scan of the image reference
+func latestScan(scans []portainer.ImageScan, image string) *portainer.ImageScan {
+	reference := stackplan.NormalizeImage(image)
+
+	var latest *portainer.ImageScan
+	for i := range scans {
+		if slices.Contains(scans[i].References, reference) && (latest == nil || scans[i].ScanDate > latest.ScanDate) {
+			latest = &scans[i]
+		}
+	}
+
+	return latest
+}
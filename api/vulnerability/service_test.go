@@ -0,0 +1,222 @@
+package vulnerability
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testScanner struct {
+	reports     map[string]*Report
+	scans       int
+	credentials map[string]*RegistryCredentials
+	// wait blocks the scans until the context is done
+	wait bool
+}
+
+func (scanner *testScanner) Scan(ctx context.Context, image string, credentials *RegistryCredentials) (*Report, error) {
+	scanner.scans++
+
+	if scanner.credentials == nil {
+		scanner.credentials = make(map[string]*RegistryCredentials)
+	}
+	scanner.credentials[image] = credentials
+
+	if scanner.wait {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	report, ok := scanner.reports[image]
+	if !ok {
+		return nil, errors.New("image not found")
+	}
+
+	return report, nil
+}
+
+func newTestService(t *testing.T, scanner Scanner) (*Service, *datastore.Store) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	service := NewService(store, nil, nil, nil, nil)
+	service.newScanner = func(settings portainer.VulnerabilityScanningSettings) (Scanner, error) {
+		return scanner, nil
+	}
+
+	return service, store
+}
+
+func updateScanningSettings(t *testing.T, store *datastore.Store, scanning portainer.VulnerabilityScanningSettings) {
+	settings, err := store.Settings().Settings()
+	assert.NoError(t, err)
+
+	settings.VulnerabilityScanning = scanning
+	assert.NoError(t, store.Settings().UpdateSettings(settings))
+}
+
+func Test_ScanImage(t *testing.T) {
+	is := assert.New(t)
+
+	scanner := &testScanner{reports: map[string]*Report{
+		"nginx:1.25": {Digest: "sha256:1", Vulnerabilities: []portainer.Vulnerability{{ID: "CVE-1", Severity: portainer.VulnerabilitySeverityHigh}}},
+	}}
+	service, store := newTestService(t, scanner)
+
+	_, err := service.ScanImage(context.Background(), "nginx:1.25")
+	is.ErrorIs(err, ErrScanningDisabled)
+
+	updateScanningSettings(t, store, portainer.VulnerabilityScanningSettings{Enabled: true})
+
+	scan, err := service.ScanImage(context.Background(), "nginx:1.25")
+	is.NoError(err)
+	is.Equal("sha256:1", scan.Digest)
+	is.Equal([]string{"docker.io/library/nginx:1.25"}, scan.References)
+	is.Equal(portainer.VulnerabilityScannerTrivy, scan.Scanner)
+	is.Equal(portainer.VulnerabilitySummary{High: 1}, scan.Summary)
+
+	// the tag now resolves to another digest, the reference moves to the scan of the new digest
+	scanner.reports["docker.io/library/nginx:1.25"] = &Report{Digest: "sha256:2", Vulnerabilities: []portainer.Vulnerability{}}
+
+	scan, err = service.ScanImage(context.Background(), "docker.io/library/nginx:1.25")
+	is.NoError(err)
+	is.Equal("sha256:2", scan.Digest)
+
+	scans, err := store.ImageScan().ReadAll()
+	is.NoError(err)
+	is.Len(scans, 2)
+
+	latest := latestScan(scans, "nginx:1.25")
+	is.Equal("sha256:2", latest.Digest)
+
+	for _, scan := range scans {
+		if scan.Digest == "sha256:1" {
+			is.Empty(scan.References)
+		}
+	}
+}
+
+func Test_CheckStack(t *testing.T) {
+	is := assert.New(t)
+
+	scanner := &testScanner{reports: map[string]*Report{
+		"nginx:1.25": {Digest: "sha256:1", Vulnerabilities: []portainer.Vulnerability{{ID: "CVE-1", Severity: portainer.VulnerabilitySeverityCritical}}},
+	}}
+	service, store := newTestService(t, scanner)
+
+	projectPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(projectPath, "app.yaml"), []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: init
+          image: registry.local/init:1
+      containers:
+        - name: nginx
+          image: nginx:1.25
+`), 0o600)
+	is.NoError(err)
+
+	stack := &portainer.Stack{ID: 1, Name: "web", Type: portainer.KubernetesStack, EntryPoint: "app.yaml", ProjectPath: projectPath}
+	endpoint := &portainer.Endpoint{ID: 1}
+
+	err = service.CheckStack(context.Background(), stack, endpoint)
+	is.NoError(err, "the policy is not enforced while the scanning is disabled")
+	is.Equal(0, scanner.scans)
+
+	updateScanningSettings(t, store, portainer.VulnerabilityScanningSettings{Enabled: true, BlockCriticalDeployments: true})
+
+	err = service.CheckStack(context.Background(), stack, endpoint)
+	is.ErrorContains(err, "unable to scan the image registry.local/init:1", "the deployment is refused when an image cannot be scanned")
+	is.Equal(2, scanner.scans)
+
+	scanner.reports["registry.local/init:1"] = &Report{Digest: "sha256:2"}
+
+	// the recent scan of nginx is reused, the image which could not be scanned is scanned again
+	err = service.CheckStack(context.Background(), stack, endpoint)
+	is.ErrorIs(err, ErrCriticalVulnerabilities)
+	is.ErrorContains(err, "nginx:1.25 (1 critical)")
+	is.NotContains(err.Error(), "registry.local/init:1")
+	is.Equal(3, scanner.scans)
+
+	updateScanningSettings(t, store, portainer.VulnerabilityScanningSettings{Enabled: true})
+
+	err = service.CheckStack(context.Background(), stack, endpoint)
+	is.NoError(err)
+}
+
+func Test_CheckStack_Timeout(t *testing.T) {
+	is := assert.New(t)
+
+	scanner := &testScanner{wait: true}
+	service, store := newTestService(t, scanner)
+	service.policyScanTimeout = 10 * time.Millisecond
+
+	projectPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(projectPath, "app.yaml"), []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+    - name: nginx
+      image: nginx:1.25
+`), 0o600)
+	is.NoError(err)
+
+	updateScanningSettings(t, store, portainer.VulnerabilityScanningSettings{Enabled: true, BlockCriticalDeployments: true})
+
+	stack := &portainer.Stack{ID: 1, Name: "web", Type: portainer.KubernetesStack, EntryPoint: "app.yaml", ProjectPath: projectPath}
+
+	err = service.CheckStack(context.Background(), stack, &portainer.Endpoint{ID: 1})
+	is.ErrorContains(err, "did not complete within", "the deployment does not wait for the scan after the timeout")
+}
+
+func Test_registryCredentials(t *testing.T) {
+	is := assert.New(t)
+
+	scanner := &testScanner{reports: map[string]*Report{
+		"registry.local/app:1": {Digest: "sha256:1"},
+		"nginx:1.25":           {Digest: "sha256:2"},
+	}}
+	service, store := newTestService(t, scanner)
+
+	is.NoError(store.Registry().Create(&portainer.Registry{
+		Name:             "local",
+		Type:             portainer.CustomRegistry,
+		URL:              "https://registry.local/",
+		Authentication:   true,
+		Username:         "user",
+		Password:         "password",
+		RegistryAccesses: portainer.RegistryAccesses{1: {}},
+	}))
+
+	updateScanningSettings(t, store, portainer.VulnerabilityScanningSettings{Enabled: true})
+
+	_, err := service.ScanImage(context.Background(), "registry.local/app:1")
+	is.NoError(err)
+	is.Equal(&RegistryCredentials{Registry: "registry.local", Username: "user", Password: "password"}, scanner.credentials["registry.local/app:1"])
+
+	_, err = service.ScanImage(context.Background(), "nginx:1.25")
+	is.NoError(err)
+	is.Nil(scanner.credentials["nginx:1.25"], "the credentials are only passed to the registry of the image")
+
+	credentials, err := service.registryCredentials("registry.local/app:1", &portainer.Endpoint{ID: 1})
+	is.NoError(err)
+	is.NotNil(credentials)
+
+	credentials, err = service.registryCredentials("registry.local/app:1", &portainer.Endpoint{ID: 2})
+	is.NoError(err)
+	is.Nil(credentials, "the registries which are not accessible from the environment are not used")
+}
@@ -110,6 +110,11 @@ func (wrapper *PluginWrapper) Validate(ctx context.Context, filePaths []string,
 	return err
 }
 
+// Config returns the resolved project in the JSON format
+func (wrapper *PluginWrapper) Config(ctx context.Context, filePaths []string, options libstack.Options) ([]byte, error) {
+	return wrapper.command(newConfigCommand(filePaths), options)
+}
+
 // Command execute a docker-compose command
 func (wrapper *PluginWrapper) command(command composeCommand, options libstack.Options) ([]byte, error) {
 	program := utils.ProgramPath(wrapper.binaryPath, "docker-compose")
@@ -223,6 +228,10 @@ func newValidateCommand(filePaths []string) composeCommand {
 	return newCommand([]string{"config", "--quiet"}, filePaths)
 }
 
+func newConfigCommand(filePaths []string) composeCommand {
+	return newCommand([]string{"config", "--format", "json"}, filePaths)
+}
+
 func (command *composeCommand) WithHost(host string) {
 	// prepend compatibility flags such as this one as they must appear before the
 	// regular global args otherwise docker-compose will throw an error
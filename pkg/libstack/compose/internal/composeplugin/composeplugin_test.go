@@ -59,6 +59,14 @@ func Test_NewCommand_MultiFilePaths_WithSpaces(t *testing.T) {
 	}
 }
 
+func Test_NewConfigCommand(t *testing.T) {
+	cmd := newConfigCommand([]string{"docker-compose.yml"})
+	expected := []string{"-f", "docker-compose.yml", "config", "--format", "json"}
+	if !reflect.DeepEqual(cmd.ToArgs(), expected) {
+		t.Errorf("wrong output args, want: %v, got: %v", expected, cmd.ToArgs())
+	}
+}
+
 func Test_UpAndDown(t *testing.T) {
 	checkPrerequisites(t)
 
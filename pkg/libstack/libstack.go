@@ -13,6 +13,8 @@ type Deployer interface {
 	Remove(ctx context.Context, projectName string, filePaths []string, options Options) error
 	Pull(ctx context.Context, filePaths []string, options Options) error
 	Validate(ctx context.Context, filePaths []string, options Options) error
+	// Config returns the JSON representation of the project, with the files merged and the variables interpolated
+	Config(ctx context.Context, filePaths []string, options Options) ([]byte, error)
 	WaitForStatus(ctx context.Context, name string, status Status) <-chan string
 }
 